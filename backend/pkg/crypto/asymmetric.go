@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// 非对称签名算法（商户请求签名 v3 使用）
+const (
+	AlgorithmEd25519      = "ed25519"        // Ed25519
+	AlgorithmRSAPSSSHA256 = "rsa-pss-sha256" // RSA-PSS + SHA-256（salt长度等于哈希长度）
+)
+
+// IsSupportedSigningAlgorithm 检查签名算法是否支持
+func IsSupportedSigningAlgorithm(algorithm string) bool {
+	return algorithm == AlgorithmEd25519 || algorithm == AlgorithmRSAPSSSHA256
+}
+
+// ParseSigningPublicKey 从PEM（PKIX/SubjectPublicKeyInfo）格式解析签名公钥
+// 并校验公钥类型与算法是否匹配，RSA公钥长度不能小于2048位
+func ParseSigningPublicKey(algorithm, pemStr string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, errors.New("无效的PEM格式")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析公钥失败: %w", err)
+	}
+
+	switch algorithm {
+	case AlgorithmEd25519:
+		edPub, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("不是Ed25519公钥")
+		}
+		return edPub, nil
+	case AlgorithmRSAPSSSHA256:
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("不是RSA公钥")
+		}
+		if rsaPub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA密钥长度不能小于2048位")
+		}
+		return rsaPub, nil
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", algorithm)
+	}
+}
+
+// VerifyAsymmetricSignature 使用公钥验证签名（签名为标准Base64编码）
+func VerifyAsymmetricSignature(algorithm, publicKeyPEM string, message []byte, signature string) error {
+	pub, err := ParseSigningPublicKey(algorithm, publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("签名不是有效的Base64: %w", err)
+	}
+
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, message, sig) {
+			return errors.New("签名验证失败")
+		}
+		return nil
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(message)
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+		if err := rsa.VerifyPSS(key, crypto.SHA256, hash[:], sig, opts); err != nil {
+			return errors.New("签名验证失败")
+		}
+		return nil
+	default:
+		return errors.New("不支持的公钥类型")
+	}
+}
+
+// SignAsymmetric 使用私钥签名（供SDK和测试使用），返回标准Base64编码的签名
+// privateKey 必须是 ed25519.PrivateKey 或 *rsa.PrivateKey，且与算法匹配
+func SignAsymmetric(algorithm string, privateKey crypto.Signer, message []byte) (string, error) {
+	switch algorithm {
+	case AlgorithmEd25519:
+		key, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return "", errors.New("不是Ed25519私钥")
+		}
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(key, message)), nil
+	case AlgorithmRSAPSSSHA256:
+		key, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return "", errors.New("不是RSA私钥")
+		}
+		hash := sha256.Sum256(message)
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+		sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, hash[:], opts)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(sig), nil
+	default:
+		return "", fmt.Errorf("不支持的签名算法: %s", algorithm)
+	}
+}
+
+// PublicKeyFingerprint 计算公钥指纹（SHA-256(DER)的十六进制前16字节）
+func PublicKeyFingerprint(publicKeyPEM string) (string, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return "", errors.New("无效的PEM格式")
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return fmt.Sprintf("%x", sum[:16]), nil
+}
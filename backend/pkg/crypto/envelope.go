@@ -0,0 +1,161 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownKeyID 密文使用的密钥加密密钥（KEK）不在密钥环中
+var ErrUnknownKeyID = errors.New("未知的密钥加密密钥")
+
+// Envelope 信封加密结果
+//
+// 数据由随机生成的数据密钥（DEK）以 AES-256-GCM 加密，DEK 再由 KeyID 标识的密钥加密密钥（KEK）加密。
+// 轮换 KEK 时只需用新 KEK 重新加密 DEK（Rewrap），无需解密和重写数据本身。
+type Envelope struct {
+	KeyID        string `json:"key_id"`        // 加密 DEK 所用的 KEK 标识
+	EncryptedDEK string `json:"encrypted_dek"` // KEK 加密后的 DEK（Base64）
+	Ciphertext   string `json:"ciphertext"`    // DEK 加密后的数据（Base64）
+}
+
+// Keyring 密钥加密密钥环，新数据使用当前 KEK 加密，历史 KEK 仅用于解密
+type Keyring struct {
+	activeKeyID string
+	keys        map[string]*AESCrypto
+}
+
+// NewKeyring 创建密钥环，activeKeyID 必须在 keys 中
+func NewKeyring(activeKeyID string, keys map[string][]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("密钥环不能为空")
+	}
+	k := &Keyring{activeKeyID: activeKeyID, keys: make(map[string]*AESCrypto, len(keys))}
+	for id, key := range keys {
+		if id == "" {
+			return nil, errors.New("密钥标识不能为空")
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("密钥 %s 长度必须是32字节", id)
+		}
+		c, err := NewAESCrypto(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = c
+	}
+	if _, ok := k.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("当前密钥 %s 不在密钥环中", activeKeyID)
+	}
+	return k, nil
+}
+
+// ParseKeyring 解析密钥环配置，格式：key_id:base64密钥,key_id:base64密钥
+func ParseKeyring(activeKeyID, spec string) (*Keyring, error) {
+	keys := make(map[string][]byte)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("密钥配置格式错误: %s", item)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 不是有效的 Base64: %w", parts[0], err)
+		}
+		keys[strings.TrimSpace(parts[0])] = key
+	}
+	return NewKeyring(activeKeyID, keys)
+}
+
+// ActiveKeyID 返回当前用于加密的 KEK 标识
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// Seal 使用新的 DEK 加密数据，并用当前 KEK 加密 DEK
+func (k *Keyring) Seal(plaintext []byte) (*Envelope, error) {
+	dek, err := GenerateKey(32)
+	if err != nil {
+		return nil, err
+	}
+	dataCipher, err := NewAESCrypto(dek)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := dataCipher.EncryptBytes(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	encryptedDEK, err := k.keys[k.activeKeyID].EncryptBytes(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		KeyID:        k.activeKeyID,
+		EncryptedDEK: base64.StdEncoding.EncodeToString(encryptedDEK),
+		Ciphertext:   base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Open 解密信封
+func (k *Keyring) Open(env *Envelope) ([]byte, error) {
+	dek, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	dataCipher, err := NewAESCrypto(dek)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("密文不是有效的 Base64: %w", err)
+	}
+	plaintext, err := dataCipher.DecryptBytes(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("解密数据失败: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap 用当前 KEK 重新加密 DEK，数据密文保持不变；已使用当前 KEK 时原样返回
+func (k *Keyring) Rewrap(env *Envelope) (*Envelope, error) {
+	if env.KeyID == k.activeKeyID {
+		return env, nil
+	}
+	dek, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	encryptedDEK, err := k.keys[k.activeKeyID].EncryptBytes(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		KeyID:        k.activeKeyID,
+		EncryptedDEK: base64.StdEncoding.EncodeToString(encryptedDEK),
+		Ciphertext:   env.Ciphertext,
+	}, nil
+}
+
+// unwrap 用信封对应的 KEK 解密 DEK
+func (k *Keyring) unwrap(env *Envelope) ([]byte, error) {
+	kek, ok := k.keys[env.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, env.KeyID)
+	}
+	encryptedDEK, err := base64.StdEncoding.DecodeString(env.EncryptedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("数据密钥不是有效的 Base64: %w", err)
+	}
+	dek, err := kek.DecryptBytes(encryptedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("解密数据密钥失败: %w", err)
+	}
+	return dek, nil
+}
@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringSealOpenRewrap(t *testing.T) {
+	oldKey, _ := GenerateKey(32)
+	newKey, _ := GenerateKey(32)
+
+	oldRing, err := NewKeyring("k1", map[string][]byte{"k1": oldKey})
+	require.NoError(t, err)
+	env, err := oldRing.Seal([]byte(`{"api_key":"sk_test_123"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", env.KeyID)
+
+	// 轮换 KEK：新密钥环同时持有新旧 KEK，旧信封可解密，Rewrap 后只需新 KEK
+	spec := "k1:" + base64.StdEncoding.EncodeToString(oldKey) + ", k2:" + base64.StdEncoding.EncodeToString(newKey)
+	ring, err := ParseKeyring("k2", spec)
+	require.NoError(t, err)
+	plaintext, err := ring.Open(env)
+	require.NoError(t, err)
+	assert.Equal(t, `{"api_key":"sk_test_123"}`, string(plaintext))
+
+	rewrapped, err := ring.Rewrap(env)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rewrapped.KeyID)
+	assert.Equal(t, env.Ciphertext, rewrapped.Ciphertext)
+
+	newOnly, err := NewKeyring("k2", map[string][]byte{"k2": newKey})
+	require.NoError(t, err)
+	plaintext, err = newOnly.Open(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, `{"api_key":"sk_test_123"}`, string(plaintext))
+
+	_, err = newOnly.Open(env)
+	assert.True(t, errors.Is(err, ErrUnknownKeyID))
+}
+
+func TestNewKeyringValidation(t *testing.T) {
+	key, _ := GenerateKey(32)
+
+	_, err := NewKeyring("k2", map[string][]byte{"k1": key})
+	assert.Error(t, err, "当前密钥必须在密钥环中")
+
+	_, err = NewKeyring("k1", map[string][]byte{"k1": key[:16]})
+	assert.Error(t, err, "KEK 必须是 AES-256 密钥")
+
+	_, err = ParseKeyring("k1", "k1")
+	assert.Error(t, err)
+}
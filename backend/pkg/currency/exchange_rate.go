@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/payment-platform/pkg/money"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -124,16 +125,19 @@ func (s *ExchangeRateService) Convert(ctx context.Context, amount float64, from,
 	return amount * rate, nil
 }
 
-// ConvertAmount 货币转换（金额以分为单位）
+// ConvertAmount 货币转换（金额以最小货币单位计）
 func (s *ExchangeRateService) ConvertAmount(ctx context.Context, amountCents int64, from, to string) (int64, error) {
 	rate, err := s.GetRate(ctx, from, to)
 	if err != nil {
 		return 0, err
 	}
 
-	// 转换为浮点数计算，然后四舍五入
-	amountFloat := float64(amountCents) * rate
-	return int64(amountFloat + 0.5), nil
+	// 按十进制汇率换算并处理两种币种的小数位数差异，银行家舍入
+	converted, err := money.New(amountCents, from).Convert(money.DecimalFromFloat(rate), to, money.RoundHalfEven)
+	if err != nil {
+		return 0, err
+	}
+	return converted.Amount, nil
 }
 
 // ClearCache 清除缓存
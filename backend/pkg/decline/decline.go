@@ -0,0 +1,217 @@
+// Package decline 支付拒绝原因归一化
+//
+// 各渠道返回的错误码格式各不相同（Stripe 的 card_declined/insufficient_funds、
+// PayPal 的 INSTRUMENT_DECLINED、支付宝的 ACQ.* 等），无法统一分析和处理。
+// 本包定义平台统一的拒绝分类：硬拒绝/软拒绝、原因分类、是否可重试以及面向消费者的文案 key。
+// 渠道错误码到分类的映射由 channel-adapter 的各渠道适配器通过 CodeTable 维护，
+// 无法识别错误码时（网络错误、HTTP 状态码等）使用 ClassifyMessage 按错误信息兜底识别。
+package decline
+
+import (
+	"errors"
+	"strings"
+)
+
+// Type 拒绝类型
+type Type string
+
+const (
+	TypeHard Type = "hard" // 硬拒绝：原样重试不会成功，需要更换支付方式或修正请求
+	TypeSoft Type = "soft" // 软拒绝：暂时性失败或可由消费者操作解决
+)
+
+// Category 拒绝原因分类
+type Category string
+
+const (
+	CategoryInsufficientFunds      Category = "insufficient_funds"      // 余额/额度不足
+	CategoryCardExpired            Category = "card_expired"            // 卡已过期
+	CategoryInvalidCard            Category = "invalid_card"            // 卡号、CVC、有效期、PIN 等信息错误
+	CategoryLostOrStolen           Category = "lost_or_stolen"          // 挂失或被盗卡
+	CategoryFraudSuspected         Category = "fraud_suspected"         // 发卡行或渠道怀疑欺诈
+	CategoryRestricted             Category = "restricted"              // 卡或账户不允许此类交易（卡种、币种、地区限制等）
+	CategoryLimitExceeded          Category = "limit_exceeded"          // 超过单笔/频次限额
+	CategoryAuthenticationRequired Category = "authentication_required" // 需要消费者完成身份验证（3DS、PIN 等）
+	CategoryDoNotHonor             Category = "do_not_honor"            // 发卡行未说明原因的拒绝
+	CategoryIssuerUnavailable      Category = "issuer_unavailable"      // 发卡行暂时不可用
+	CategoryProcessingError        Category = "processing_error"        // 渠道或发卡行处理异常
+	CategoryChannelUnavailable     Category = "channel_unavailable"     // 渠道不可用（5xx、限流、熔断、网络错误）
+	CategoryTimeout                Category = "timeout"                 // 超时，渠道侧结果未知
+	CategoryInvalidRequest         Category = "invalid_request"         // 请求参数错误（商户集成问题）
+	CategoryDuplicate              Category = "duplicate_transaction"   // 重复交易
+	CategoryUnknown                Category = "unknown"                 // 无法识别
+)
+
+// profile 分类的默认属性
+type profile struct {
+	typ       Type
+	retryable bool
+}
+
+// profiles 各分类的类型与是否可重试
+//
+// 可重试表示同一笔支付原样重试（包括级联到其他渠道）有较大概率成功，且不会造成重复扣款。
+// 超时的渠道侧结果未知，虽然是暂时性失败但不允许自动重试。
+var profiles = map[Category]profile{
+	CategoryInsufficientFunds:      {TypeHard, false},
+	CategoryCardExpired:            {TypeHard, false},
+	CategoryInvalidCard:            {TypeHard, false},
+	CategoryLostOrStolen:           {TypeHard, false},
+	CategoryFraudSuspected:         {TypeHard, false},
+	CategoryRestricted:             {TypeHard, false},
+	CategoryLimitExceeded:          {TypeSoft, false},
+	CategoryAuthenticationRequired: {TypeSoft, false},
+	CategoryDoNotHonor:             {TypeSoft, true},
+	CategoryIssuerUnavailable:      {TypeSoft, true},
+	CategoryProcessingError:        {TypeSoft, true},
+	CategoryChannelUnavailable:     {TypeSoft, true},
+	CategoryTimeout:                {TypeSoft, false},
+	CategoryInvalidRequest:         {TypeHard, false},
+	CategoryDuplicate:              {TypeHard, false},
+	CategoryUnknown:                {TypeHard, false},
+}
+
+// Decline 归一化的拒绝原因
+type Decline struct {
+	Category       Category `json:"category"`                  // 原因分类
+	Type           Type     `json:"type"`                      // hard, soft
+	Retryable      bool     `json:"retryable"`                 // 是否可原样重试
+	MessageKey     string   `json:"message_key"`               // 面向消费者的文案 key（由前端/商户本地化）
+	Channel        string   `json:"channel,omitempty"`         // 渠道
+	ChannelCode    string   `json:"channel_code,omitempty"`    // 渠道原始错误码
+	ChannelMessage string   `json:"channel_message,omitempty"` // 渠道原始错误信息
+}
+
+// New 按分类创建拒绝原因，未知分类按 CategoryUnknown 处理
+func New(category Category, channel, code, message string) *Decline {
+	p, ok := profiles[category]
+	if !ok {
+		category = CategoryUnknown
+		p = profiles[CategoryUnknown]
+	}
+	return &Decline{
+		Category:       category,
+		Type:           p.typ,
+		Retryable:      p.retryable,
+		MessageKey:     MessageKey(category),
+		Channel:        channel,
+		ChannelCode:    code,
+		ChannelMessage: message,
+	}
+}
+
+// MessageKey 分类对应的消费者文案 key
+func MessageKey(category Category) string {
+	return "payment.decline." + string(category)
+}
+
+// IsValidCategory 判断是否为已定义的分类
+func IsValidCategory(category string) bool {
+	_, ok := profiles[Category(category)]
+	return ok
+}
+
+// CodeTable 渠道错误码到分类的映射表
+type CodeTable map[string]Category
+
+// Classify 按错误码查表，未收录的错误码按错误码和错误信息兜底识别
+func (t CodeTable) Classify(channel, code, message string) *Decline {
+	if category, ok := t[code]; ok {
+		return New(category, channel, code, message)
+	}
+	d := ClassifyMessage(channel, code+" "+message)
+	d.ChannelCode = code
+	d.ChannelMessage = message
+	return d
+}
+
+// marker 错误信息关键字
+type marker struct {
+	keyword  string
+	category Category
+}
+
+// messageMarkers 按顺序匹配：硬拒绝优先，其次是结果未知的超时，最后是软拒绝
+var messageMarkers = []marker{
+	{"insufficient_funds", CategoryInsufficientFunds},
+	{"insufficient funds", CategoryInsufficientFunds},
+	{"stolen", CategoryLostOrStolen},
+	{"lost_card", CategoryLostOrStolen},
+	{"expired_card", CategoryCardExpired},
+	{"invalid_card", CategoryInvalidCard},
+	{"incorrect_number", CategoryInvalidCard},
+	{"incorrect_cvc", CategoryInvalidCard},
+	{"fraud", CategoryFraudSuspected},
+	{"blocked", CategoryRestricted},
+	{"currency_not_supported", CategoryRestricted},
+	{"invalid_amount", CategoryInvalidRequest},
+
+	{"timeout", CategoryTimeout},
+	{"deadline exceeded", CategoryTimeout},
+	{"context canceled", CategoryTimeout},
+
+	{"do_not_honor", CategoryDoNotHonor},
+	{"do not honor", CategoryDoNotHonor},
+	{"try_again", CategoryDoNotHonor},
+	{"processing_error", CategoryProcessingError},
+	{"issuer_unavailable", CategoryIssuerUnavailable},
+	{"rate_limit", CategoryChannelUnavailable},
+	{"too many requests", CategoryChannelUnavailable},
+	{"service unavailable", CategoryChannelUnavailable},
+	{"bad gateway", CategoryChannelUnavailable},
+	{"connection refused", CategoryChannelUnavailable},
+	{"connection reset", CategoryChannelUnavailable},
+	{"no such host", CategoryChannelUnavailable},
+	{"circuit breaker", CategoryChannelUnavailable},
+}
+
+// ClassifyMessage 按错误信息中的关键字识别拒绝原因，无法识别时返回 CategoryUnknown
+func ClassifyMessage(channel, message string) *Decline {
+	lower := strings.ToLower(message)
+	category := CategoryUnknown
+	if lower != "" {
+		for _, m := range messageMarkers {
+			if strings.Contains(lower, m.keyword) {
+				category = m.category
+				break
+			}
+		}
+	}
+	return New(category, channel, "", message)
+}
+
+// Error 携带拒绝原因的错误
+type Error struct {
+	Decline *Decline
+	Err     error
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Decline.ChannelCode != "" {
+		return "支付被拒绝: " + string(e.Decline.Category) + " (" + e.Decline.ChannelCode + ")"
+	}
+	return "支付被拒绝: " + string(e.Decline.Category)
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap 为错误附加拒绝原因
+func Wrap(d *Decline, err error) error {
+	return &Error{Decline: d, Err: err}
+}
+
+// FromError 从错误链中取出拒绝原因
+func FromError(err error) (*Decline, bool) {
+	var declineErr *Error
+	if errors.As(err, &declineErr) && declineErr.Decline != nil {
+		return declineErr.Decline, true
+	}
+	return nil, false
+}
@@ -0,0 +1,71 @@
+package decline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeTableClassify(t *testing.T) {
+	table := CodeTable{
+		"insufficient_funds": CategoryInsufficientFunds,
+		"do_not_honor":       CategoryDoNotHonor,
+	}
+
+	d := table.Classify("stripe", "insufficient_funds", "Your card has insufficient funds.")
+	assert.Equal(t, CategoryInsufficientFunds, d.Category)
+	assert.Equal(t, TypeHard, d.Type)
+	assert.False(t, d.Retryable)
+	assert.Equal(t, "payment.decline.insufficient_funds", d.MessageKey)
+	assert.Equal(t, "insufficient_funds", d.ChannelCode)
+
+	d = table.Classify("stripe", "do_not_honor", "")
+	assert.Equal(t, TypeSoft, d.Type)
+	assert.True(t, d.Retryable)
+
+	// 未收录的错误码按错误信息兜底，保留渠道原始错误码
+	d = table.Classify("stripe", "api_connection_error", "connection refused")
+	assert.Equal(t, CategoryChannelUnavailable, d.Category)
+	assert.Equal(t, "api_connection_error", d.ChannelCode)
+
+	d = table.Classify("stripe", "something_new", "")
+	assert.Equal(t, CategoryUnknown, d.Category)
+	assert.False(t, d.Retryable)
+}
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Category
+	}{
+		{"创建支付失败: do_not_honor", CategoryDoNotHonor},
+		{"调用Channel服务失败: HTTP错误: 503 Service Unavailable", CategoryChannelUnavailable},
+		{"创建支付失败: insufficient_funds", CategoryInsufficientFunds},
+		{"调用Channel服务失败: context deadline exceeded", CategoryTimeout},
+		// 同时出现硬拒绝与软拒绝关键字时按硬拒绝处理
+		{"fraudulent, try_again_later", CategoryFraudSuspected},
+		{"创建支付失败: unknown", CategoryUnknown},
+		{"", CategoryUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyMessage("", tt.message).Category, tt.message)
+	}
+	assert.False(t, ClassifyMessage("", "context deadline exceeded").Retryable)
+}
+
+func TestFromError(t *testing.T) {
+	d := New(CategoryCardExpired, "paypal", "CARD_EXPIRED", "")
+	err := fmt.Errorf("创建支付失败: %w", Wrap(d, fmt.Errorf("PayPal 返回 CARD_EXPIRED")))
+
+	got, ok := FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, d, got)
+	assert.Contains(t, err.Error(), "CARD_EXPIRED")
+
+	_, ok = FromError(fmt.Errorf("其他错误"))
+	assert.False(t, ok)
+
+	assert.Equal(t, CategoryUnknown, New("not_defined", "", "", "").Category)
+}
@@ -0,0 +1,156 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertValidXref 校验 xref 表中的偏移量都指向对应的对象头
+func assertValidXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4")))
+	require.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(pdf[off:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "对象 %d 偏移错误", i+1)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "USD 1,234.56", FormatAmount(123456, "usd"))
+	assert.Equal(t, "USD 0.05", FormatAmount(5, "USD"))
+	assert.Equal(t, "USD -10.00", FormatAmount(-1000, "USD"))
+	assert.Equal(t, "JPY 1,000", FormatAmount(1000, "JPY"))
+	assert.Equal(t, "KWD 1.500", FormatAmount(1500, "KWD"))
+	assert.Equal(t, "8.25%", FormatRate(825))
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	assert.Equal(t, LangZhCN, NormalizeLanguage("zh"))
+	assert.Equal(t, LangZhCN, NormalizeLanguage("zh_CN"))
+	assert.Equal(t, LangZhTW, NormalizeLanguage("zh-Hant"))
+	assert.Equal(t, LangJa, NormalizeLanguage("ja-JP"))
+	assert.Equal(t, LangEn, NormalizeLanguage("fr"))
+}
+
+func TestRenderReceipt(t *testing.T) {
+	for _, lang := range []string{LangEn, LangZhCN, LangJa} {
+		pdf, err := RenderReceipt(&Receipt{
+			Kind:        ReceiptKindPayment,
+			Language:    lang,
+			Branding:    Branding{Name: "Acme 商店", ThemeColor: "#52c41a"},
+			ReceiptNo:   "RCP-PAY001",
+			PaymentNo:   "PAY001",
+			OrderNo:     "ORDER001",
+			Description: "Premium plan (annual)",
+			Currency:    "USD",
+			TaxLines:    []TaxLine{{Name: "VAT", RateBps: 2000, Amount: 1667}},
+			Total:       10000,
+			OccurredAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		})
+		require.NoError(t, err, lang)
+		assertValidXref(t, pdf)
+	}
+
+	_, err := RenderReceipt(&Receipt{Kind: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestRenderInvoice_MultiPage(t *testing.T) {
+	items := make([]LineItem, 80)
+	for i := range items {
+		items[i] = LineItem{Description: fmt.Sprintf("Transaction fee #%d", i+1), Quantity: 1, UnitPrice: 100, Amount: 100}
+	}
+	pdf, err := RenderInvoice(&Invoice{
+		Language:    LangZhCN,
+		Issuer:      Branding{Name: "Payment Platform"},
+		BillTo:      Branding{Name: "Acme"},
+		InvoiceNo:   "INV001",
+		Currency:    "CNY",
+		Items:       items,
+		Subtotal:    8000,
+		TaxLines:    []TaxLine{{RateBps: 600, Amount: 480}},
+		Total:       8480,
+		Outstanding: 8480,
+	})
+	require.NoError(t, err)
+	assertValidXref(t, pdf)
+	assert.Contains(t, string(pdf), "/Count 3", "明细超过一页时应自动分页")
+}
+
+func TestRenderReport(t *testing.T) {
+	rows := make([][]string, 120)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("PAY%06d", i), "amount_diff", "CNY 1.00"}
+	}
+	pdf, err := RenderReport(&Report{
+		Language: LangZhCN,
+		Title:    "对账报告",
+		Fields:   []Field{{"对账日期", "2024-01-02"}},
+		Sections: []ReportSection{
+			{Heading: "差异类型", Chart: &BarChart{Bars: []Bar{{Label: "金额不一致", Value: 3, Display: "3"}, {Label: "仅渠道有记录", Value: 0, Display: "0"}}}},
+			{Heading: "差异明细", Table: &Table{
+				Columns: []Column{{Title: "支付单号", Width: 0.4}, {Title: "差异类型", Width: 0.3}, {Title: "差异金额", Width: 0.3, AlignRight: true}},
+				Rows:    rows,
+			}},
+		},
+		Footer: "Generated by reconciliation-service",
+	})
+	require.NoError(t, err)
+	assertValidXref(t, pdf)
+	assert.Contains(t, string(pdf), "/Count 3", "表格超过一页时应自动分页")
+}
+
+func TestWorkbook(t *testing.T) {
+	wb := NewWorkbook()
+	sheet := wb.AddSheet("Differences: 2024/01/02")
+	sheet.FreezeHeader()
+	sheet.SetColumnWidths(20, 12)
+	sheet.AddRow(HeaderCell("支付单号"), HeaderCell("金额"))
+	sheet.AddRow(TextCell("PAY<001>"), AmountCell(123456, "USD"))
+	sheet.AddRow(TextCell(""), AmountCell(1000, "JPY"), PercentCell(0.5))
+
+	data, err := wb.Bytes()
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "xl/styles.xml")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Differences_ 2024_01_02"`)
+	sheetXML := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheetXML, `state="frozen"`)
+	assert.Contains(t, sheetXML, `<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">PAY&lt;001&gt;</t></is></c>`)
+	assert.Contains(t, sheetXML, `<c r="B2" s="3"><v>1234.56</v></c>`)
+	assert.Contains(t, sheetXML, `<c r="B3" s="2"><v>1000</v></c>`)
+	assert.Contains(t, sheetXML, `<c r="C3" s="5"><v>0.5</v></c>`)
+	assert.Equal(t, "AA", columnName(26))
+}
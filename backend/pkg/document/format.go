@@ -0,0 +1,78 @@
+package document
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payment-platform/pkg/money"
+)
+
+// CurrencyDecimals 货币的小数位数
+func CurrencyDecimals(currency string) int {
+	return money.Decimals(currency)
+}
+
+// FormatAmount 将最小货币单位的金额格式化为带千分位的显示金额，如 123456 USD -> "USD 1,234.56"
+func FormatAmount(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	decimals := CurrencyDecimals(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	divisor := int64(1)
+	for i := 0; i < decimals; i++ {
+		divisor *= 10
+	}
+	intPart := strconv.FormatInt(amount/divisor, 10)
+
+	var b strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+	if decimals > 0 {
+		frac := strconv.FormatInt(amount%divisor, 10)
+		b.WriteByte('.')
+		b.WriteString(strings.Repeat("0", decimals-len(frac)))
+		b.WriteString(frac)
+	}
+
+	if currency == "" {
+		return sign + b.String()
+	}
+	return currency + " " + sign + b.String()
+}
+
+// FormatRate 将基点格式化为百分比，如 1300 -> "13%"，825 -> "8.25%"
+func FormatRate(bps int) string {
+	s := strconv.FormatFloat(float64(bps)/100, 'f', -1, 64)
+	return s + "%"
+}
+
+// formatTime 格式化文档中的时间（按指定时区，未指定时使用 UTC）
+func formatTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02 15:04:05 MST")
+}
+
+func formatDate(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02")
+}
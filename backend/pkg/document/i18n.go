@@ -0,0 +1,246 @@
+package document
+
+import "strings"
+
+// 支持的文档语言
+const (
+	LangEn   = "en"
+	LangZhCN = "zh-CN"
+	LangZhTW = "zh-TW"
+	LangJa   = "ja"
+	LangKo   = "ko"
+)
+
+// NormalizeLanguage 规范化语言代码（与收银台 EnabledLanguages 的取值兼容），不支持的语言返回 en
+func NormalizeLanguage(lang string) string {
+	l := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	switch {
+	case l == "zh-tw" || l == "zh-hk" || l == "zh-mo" || strings.HasPrefix(l, "zh-hant"):
+		return LangZhTW
+	case strings.HasPrefix(l, "zh"):
+		return LangZhCN
+	case strings.HasPrefix(l, "ja"):
+		return LangJa
+	case strings.HasPrefix(l, "ko"):
+		return LangKo
+	default:
+		return LangEn
+	}
+}
+
+// 文档标签
+const (
+	labelReceipt        = "receipt"
+	labelRefundReceipt  = "refund_receipt"
+	labelInvoice        = "invoice"
+	labelReceiptNo      = "receipt_no"
+	labelPaymentNo      = "payment_no"
+	labelRefundNo       = "refund_no"
+	labelOrderNo        = "order_no"
+	labelInvoiceNo      = "invoice_no"
+	labelIssuedAt       = "issued_at"
+	labelPaidAt         = "paid_at"
+	labelRefundedAt     = "refunded_at"
+	labelPayMethod      = "pay_method"
+	labelCustomer       = "customer"
+	labelReason         = "reason"
+	labelBillTo         = "bill_to"
+	labelPeriod         = "period"
+	labelDueDate        = "due_date"
+	labelStatus         = "status"
+	labelItem           = "item"
+	labelQuantity       = "quantity"
+	labelUnitPrice      = "unit_price"
+	labelAmount         = "amount"
+	labelSubtotal       = "subtotal"
+	labelTax            = "tax"
+	labelTotal          = "total"
+	labelAmountPaid     = "amount_paid"
+	labelAmountRefunded = "amount_refunded"
+	labelOutstanding    = "outstanding"
+	labelNotes          = "notes"
+	labelThankYou       = "thank_you"
+	labelFooter         = "footer"
+	labelPage           = "page"
+)
+
+var labels = map[string]map[string]string{
+	LangEn: {
+		labelReceipt:        "Receipt",
+		labelRefundReceipt:  "Refund Receipt",
+		labelInvoice:        "Invoice",
+		labelReceiptNo:      "Receipt No.",
+		labelPaymentNo:      "Payment No.",
+		labelRefundNo:       "Refund No.",
+		labelOrderNo:        "Order No.",
+		labelInvoiceNo:      "Invoice No.",
+		labelIssuedAt:       "Issued",
+		labelPaidAt:         "Paid",
+		labelRefundedAt:     "Refunded",
+		labelPayMethod:      "Payment Method",
+		labelCustomer:       "Customer",
+		labelReason:         "Reason",
+		labelBillTo:         "Bill To",
+		labelPeriod:         "Billing Period",
+		labelDueDate:        "Due Date",
+		labelStatus:         "Status",
+		labelItem:           "Description",
+		labelQuantity:       "Qty",
+		labelUnitPrice:      "Unit Price",
+		labelAmount:         "Amount",
+		labelSubtotal:       "Subtotal",
+		labelTax:            "Tax",
+		labelTotal:          "Total",
+		labelAmountPaid:     "Amount Paid",
+		labelAmountRefunded: "Amount Refunded",
+		labelOutstanding:    "Amount Due",
+		labelNotes:          "Notes",
+		labelThankYou:       "Thank you for your business.",
+		labelFooter:         "This document was generated electronically and is valid without a signature.",
+		labelPage:           "Page",
+	},
+	LangZhCN: {
+		labelReceipt:        "收据",
+		labelRefundReceipt:  "退款凭证",
+		labelInvoice:        "账单",
+		labelReceiptNo:      "收据编号",
+		labelPaymentNo:      "支付流水号",
+		labelRefundNo:       "退款单号",
+		labelOrderNo:        "订单号",
+		labelInvoiceNo:      "账单号",
+		labelIssuedAt:       "开具时间",
+		labelPaidAt:         "支付时间",
+		labelRefundedAt:     "退款时间",
+		labelPayMethod:      "支付方式",
+		labelCustomer:       "客户",
+		labelReason:         "退款原因",
+		labelBillTo:         "付款方",
+		labelPeriod:         "账期",
+		labelDueDate:        "到期日",
+		labelStatus:         "状态",
+		labelItem:           "项目",
+		labelQuantity:       "数量",
+		labelUnitPrice:      "单价",
+		labelAmount:         "金额",
+		labelSubtotal:       "小计",
+		labelTax:            "税费",
+		labelTotal:          "合计",
+		labelAmountPaid:     "实付金额",
+		labelAmountRefunded: "退款金额",
+		labelOutstanding:    "应付金额",
+		labelNotes:          "备注",
+		labelThankYou:       "感谢您的惠顾。",
+		labelFooter:         "本凭证由系统电子生成，无需签章即有效。",
+		labelPage:           "页",
+	},
+	LangZhTW: {
+		labelReceipt:        "收據",
+		labelRefundReceipt:  "退款憑證",
+		labelInvoice:        "帳單",
+		labelReceiptNo:      "收據編號",
+		labelPaymentNo:      "支付流水號",
+		labelRefundNo:       "退款單號",
+		labelOrderNo:        "訂單號",
+		labelInvoiceNo:      "帳單號",
+		labelIssuedAt:       "開立時間",
+		labelPaidAt:         "支付時間",
+		labelRefundedAt:     "退款時間",
+		labelPayMethod:      "支付方式",
+		labelCustomer:       "客戶",
+		labelReason:         "退款原因",
+		labelBillTo:         "付款方",
+		labelPeriod:         "帳期",
+		labelDueDate:        "到期日",
+		labelStatus:         "狀態",
+		labelItem:           "項目",
+		labelQuantity:       "數量",
+		labelUnitPrice:      "單價",
+		labelAmount:         "金額",
+		labelSubtotal:       "小計",
+		labelTax:            "稅費",
+		labelTotal:          "合計",
+		labelAmountPaid:     "實付金額",
+		labelAmountRefunded: "退款金額",
+		labelOutstanding:    "應付金額",
+		labelNotes:          "備註",
+		labelThankYou:       "感謝您的惠顧。",
+		labelFooter:         "本憑證由系統電子產生，無需簽章即有效。",
+		labelPage:           "頁",
+	},
+	LangJa: {
+		labelReceipt:        "領収書",
+		labelRefundReceipt:  "返金明細書",
+		labelInvoice:        "請求書",
+		labelReceiptNo:      "領収書番号",
+		labelPaymentNo:      "決済番号",
+		labelRefundNo:       "返金番号",
+		labelOrderNo:        "注文番号",
+		labelInvoiceNo:      "請求書番号",
+		labelIssuedAt:       "発行日時",
+		labelPaidAt:         "決済日時",
+		labelRefundedAt:     "返金日時",
+		labelPayMethod:      "支払方法",
+		labelCustomer:       "お客様",
+		labelReason:         "返金理由",
+		labelBillTo:         "請求先",
+		labelPeriod:         "請求期間",
+		labelDueDate:        "支払期限",
+		labelStatus:         "ステータス",
+		labelItem:           "品目",
+		labelQuantity:       "数量",
+		labelUnitPrice:      "単価",
+		labelAmount:         "金額",
+		labelSubtotal:       "小計",
+		labelTax:            "税額",
+		labelTotal:          "合計",
+		labelAmountPaid:     "お支払金額",
+		labelAmountRefunded: "返金額",
+		labelOutstanding:    "ご請求額",
+		labelNotes:          "備考",
+		labelThankYou:       "ご利用ありがとうございました。",
+		labelFooter:         "本書は電子的に発行されたものであり、署名がなくても有効です。",
+		labelPage:           "ページ",
+	},
+	LangKo: {
+		labelReceipt:        "영수증",
+		labelRefundReceipt:  "환불 영수증",
+		labelInvoice:        "청구서",
+		labelReceiptNo:      "영수증 번호",
+		labelPaymentNo:      "결제 번호",
+		labelRefundNo:       "환불 번호",
+		labelOrderNo:        "주문 번호",
+		labelInvoiceNo:      "청구서 번호",
+		labelIssuedAt:       "발행일시",
+		labelPaidAt:         "결제일시",
+		labelRefundedAt:     "환불일시",
+		labelPayMethod:      "결제 수단",
+		labelCustomer:       "고객",
+		labelReason:         "환불 사유",
+		labelBillTo:         "청구 대상",
+		labelPeriod:         "청구 기간",
+		labelDueDate:        "납부 기한",
+		labelStatus:         "상태",
+		labelItem:           "항목",
+		labelQuantity:       "수량",
+		labelUnitPrice:      "단가",
+		labelAmount:         "금액",
+		labelSubtotal:       "소계",
+		labelTax:            "세금",
+		labelTotal:          "합계",
+		labelAmountPaid:     "결제 금액",
+		labelAmountRefunded: "환불 금액",
+		labelOutstanding:    "청구 금액",
+		labelNotes:          "비고",
+		labelThankYou:       "이용해 주셔서 감사합니다.",
+		labelFooter:         "본 문서는 전자적으로 발행되었으며 서명 없이 유효합니다.",
+		labelPage:           "페이지",
+	},
+}
+
+// label 获取指定语言的标签（缺失时回退到英文）
+func label(lang, key string) string {
+	if v, ok := labels[NormalizeLanguage(lang)][key]; ok {
+		return v
+	}
+	return labels[LangEn][key]
+}
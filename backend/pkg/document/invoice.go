@@ -0,0 +1,94 @@
+package document
+
+import (
+	"errors"
+	"time"
+)
+
+// Invoice 账单/发票
+type Invoice struct {
+	Language    string
+	Location    *time.Location // 时间展示时区，为空时使用 UTC
+	Issuer      Branding       // 开票方
+	BillTo      Branding       // 付款方
+	InvoiceNo   string
+	Status      string // 账单状态（展示用文本）
+	Currency    string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	IssuedAt    time.Time
+	DueDate     time.Time
+	Items       []LineItem
+	Subtotal    int64
+	TaxLines    []TaxLine // 价外税（Total = Subtotal + 税额）
+	Total       int64
+	Paid        int64
+	Outstanding int64
+	Notes       string
+}
+
+// RenderInvoice 渲染账单 PDF
+func RenderInvoice(inv *Invoice) ([]byte, error) {
+	if inv == nil {
+		return nil, errors.New("账单不能为空")
+	}
+
+	lang := NormalizeLanguage(inv.Language)
+	l := newLayout(lang, inv.Issuer, label(lang, labelInvoice), inv.Location)
+
+	issuedAt := inv.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+	period := "-"
+	if !inv.PeriodStart.IsZero() || !inv.PeriodEnd.IsZero() {
+		period = formatDate(inv.PeriodStart, l.loc) + " ~ " + formatDate(inv.PeriodEnd, l.loc)
+	}
+	fields := []Field{
+		{l.label(labelInvoiceNo), inv.InvoiceNo},
+		{l.label(labelIssuedAt), formatDate(issuedAt, l.loc)},
+		{l.label(labelPeriod), period},
+		{l.label(labelDueDate), formatDate(inv.DueDate, l.loc)},
+	}
+	if inv.Status != "" {
+		fields = append(fields, Field{l.label(labelStatus), inv.Status})
+	}
+	l.fields(fields)
+	l.space(lineHeight / 2)
+
+	// 开票方地址与付款方信息
+	if inv.Issuer.Address != "" {
+		l.paragraph(inv.Issuer.Address, ColorGray)
+	}
+	l.space(lineHeight / 2)
+	l.heading(l.label(labelBillTo))
+	billTo := []string{inv.BillTo.Name, inv.BillTo.Address}
+	if inv.BillTo.TaxID != "" {
+		billTo = append(billTo, "Tax ID: "+inv.BillTo.TaxID)
+	}
+	billTo = append(billTo, inv.BillTo.Email)
+	for _, line := range billTo {
+		if line != "" {
+			l.paragraph(line, ColorBlack)
+		}
+	}
+	l.space(lineHeight)
+
+	l.itemsTable(inv.Items, inv.Currency)
+
+	rows := []totalRow{{label: l.label(labelSubtotal), amount: inv.Subtotal}}
+	rows = append(rows, l.taxRows(inv.TaxLines)...)
+	rows = append(rows, totalRow{label: l.label(labelTotal), amount: inv.Total, emphasis: true})
+	if inv.Paid > 0 {
+		rows = append(rows, totalRow{label: l.label(labelAmountPaid), amount: inv.Paid})
+	}
+	rows = append(rows, totalRow{label: l.label(labelOutstanding), amount: inv.Outstanding})
+	l.totals(rows, inv.Currency)
+
+	if inv.Notes != "" {
+		l.space(lineHeight)
+		l.heading(l.label(labelNotes))
+		l.paragraph(inv.Notes, ColorBlack)
+	}
+	return l.finish()
+}
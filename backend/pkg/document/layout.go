@@ -0,0 +1,282 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Branding 商户品牌信息（来自收银台配置）
+type Branding struct {
+	Name       string // 展示名称
+	ThemeColor string // 主题色，#RRGGBB
+	Address    string // 地址（可多行）
+	TaxID      string // 税号
+	Email      string // 联系邮箱
+}
+
+// LineItem 明细行（金额为最小货币单位）
+type LineItem struct {
+	Description string
+	Quantity    int
+	UnitPrice   int64
+	Amount      int64
+}
+
+// TaxLine 税费行
+type TaxLine struct {
+	Name    string // 税种名称，如 VAT、GST、消费税
+	RateBps int    // 税率（基点），0 表示不展示税率
+	Amount  int64  // 税额（最小货币单位）
+}
+
+// Field 标签/值对
+type Field struct {
+	Label string
+	Value string
+}
+
+// 版式参数
+const (
+	margin       = 48.0
+	contentWidth = PageWidth - 2*margin
+	headerHeight = 88.0
+	footerTop    = PageHeight - 40
+	bodyFontSize = 10.0
+	lineHeight   = 15.0
+)
+
+// layout 带纵向游标和自动分页的版式辅助
+type layout struct {
+	pdf    *PDF
+	lang   string
+	theme  Color
+	brand  Branding
+	title  string
+	footer string
+	loc    *time.Location
+	y      float64
+}
+
+func newLayout(lang string, brand Branding, title string, loc *time.Location) *layout {
+	lang = NormalizeLanguage(lang)
+	theme := DefaultTheme
+	if brand.ThemeColor != "" {
+		if c, err := ParseHexColor(brand.ThemeColor); err == nil {
+			theme = c
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	pdf := NewPDF(lang)
+	pdf.SetTitle(title)
+	l := &layout{pdf: pdf, lang: lang, theme: theme, brand: brand, title: title, footer: label(lang, labelFooter), loc: loc}
+	l.newPage()
+	return l
+}
+
+func (l *layout) label(key string) string {
+	return label(l.lang, key)
+}
+
+// newPage 新增一页并绘制页眉
+func (l *layout) newPage() {
+	l.pdf.AddPage()
+	l.pdf.FillRect(0, 0, PageWidth, headerHeight, l.theme)
+
+	name := l.brand.Name
+	if name == "" {
+		name = l.title
+	}
+	l.pdf.Text(margin, 44, 18, FontBold, ColorWhite, name)
+	l.pdf.TextRight(PageWidth-margin, 44, 16, FontBold, ColorWhite, l.title)
+
+	var sub []string
+	if l.brand.TaxID != "" {
+		sub = append(sub, "Tax ID: "+l.brand.TaxID)
+	}
+	if l.brand.Email != "" {
+		sub = append(sub, l.brand.Email)
+	}
+	if len(sub) > 0 {
+		l.pdf.Text(margin, 66, 9, FontRegular, ColorWhite, joinNonEmpty(sub, "  |  "))
+	}
+	l.y = headerHeight + 32
+}
+
+// ensureSpace 剩余空间不足时分页
+func (l *layout) ensureSpace(h float64) {
+	if l.y+h > footerTop-16 {
+		l.newPage()
+	}
+}
+
+func (l *layout) space(h float64) {
+	l.y += h
+}
+
+// heading 小节标题
+func (l *layout) heading(s string) {
+	l.ensureSpace(lineHeight * 2)
+	l.pdf.Text(margin, l.y, 11, FontBold, l.theme, s)
+	l.y += 6
+	l.pdf.Line(margin, l.y, PageWidth-margin, l.y, 0.5, ColorLightGray)
+	l.y += lineHeight
+}
+
+// paragraph 折行段落
+func (l *layout) paragraph(s string, color Color) {
+	for _, line := range l.pdf.WrapText(s, bodyFontSize, FontRegular, contentWidth) {
+		l.ensureSpace(lineHeight)
+		l.pdf.Text(margin, l.y, bodyFontSize, FontRegular, color, line)
+		l.y += lineHeight
+	}
+}
+
+// fields 两列展示标签/值对
+func (l *layout) fields(fields []Field) {
+	const colWidth = contentWidth / 2
+	const labelWidth = 96.0
+	for i := 0; i < len(fields); i += 2 {
+		l.ensureSpace(lineHeight)
+		rowHeight := lineHeight
+		for col := 0; col < 2 && i+col < len(fields); col++ {
+			f := fields[i+col]
+			x := margin + float64(col)*colWidth
+			l.pdf.Text(x, l.y, 9, FontRegular, ColorGray, f.Label)
+			lines := l.pdf.WrapText(valueOrDash(f.Value), bodyFontSize, FontRegular, colWidth-labelWidth-8)
+			for j, line := range lines {
+				l.pdf.Text(x+labelWidth, l.y+float64(j)*lineHeight, bodyFontSize, FontRegular, ColorBlack, line)
+			}
+			if h := float64(len(lines)) * lineHeight; h > rowHeight {
+				rowHeight = h
+			}
+		}
+		l.y += rowHeight + 2
+	}
+}
+
+// itemsTable 明细表格（超出一页时在新页重复表头）
+func (l *layout) itemsTable(items []LineItem, currency string) {
+	var (
+		colQty    = margin + contentWidth*0.58
+		colUnit   = margin + contentWidth*0.78
+		colAmount = PageWidth - margin
+		descWidth = contentWidth*0.52 - 8
+	)
+
+	drawHeader := func() {
+		l.pdf.FillRect(margin, l.y-12, contentWidth, 20, ColorLightGray)
+		l.pdf.Text(margin+6, l.y+2, 9, FontBold, ColorBlack, l.label(labelItem))
+		l.pdf.TextRight(colQty, l.y+2, 9, FontBold, ColorBlack, l.label(labelQuantity))
+		l.pdf.TextRight(colUnit, l.y+2, 9, FontBold, ColorBlack, l.label(labelUnitPrice))
+		l.pdf.TextRight(colAmount-6, l.y+2, 9, FontBold, ColorBlack, l.label(labelAmount))
+		l.y += 24
+	}
+
+	l.ensureSpace(lineHeight * 3)
+	drawHeader()
+	for _, item := range items {
+		lines := l.pdf.WrapText(valueOrDash(item.Description), bodyFontSize, FontRegular, descWidth)
+		h := float64(len(lines))*lineHeight + 4
+		if l.y+h > footerTop-16 {
+			l.newPage()
+			drawHeader()
+		}
+		for j, line := range lines {
+			l.pdf.Text(margin+6, l.y+float64(j)*lineHeight, bodyFontSize, FontRegular, ColorBlack, line)
+		}
+		qty := item.Quantity
+		if qty <= 0 {
+			qty = 1
+		}
+		unit := item.UnitPrice
+		if unit == 0 {
+			unit = item.Amount / int64(qty)
+		}
+		l.pdf.TextRight(colQty, l.y, bodyFontSize, FontRegular, ColorBlack, strconv.Itoa(qty))
+		l.pdf.TextRight(colUnit, l.y, bodyFontSize, FontRegular, ColorBlack, FormatAmount(unit, currency))
+		l.pdf.TextRight(colAmount-6, l.y, bodyFontSize, FontRegular, ColorBlack, FormatAmount(item.Amount, currency))
+		l.y += h - 4
+		l.pdf.Line(margin, l.y-9, PageWidth-margin, l.y-9, 0.3, ColorLightGray)
+		l.y += 4
+	}
+	l.y += 4
+}
+
+// totalRow 合计区的一行
+type totalRow struct {
+	label    string
+	amount   int64
+	emphasis bool
+}
+
+// totals 右对齐的合计区
+func (l *layout) totals(rows []totalRow, currency string) {
+	labelRight := PageWidth - margin - 150
+	for _, row := range rows {
+		size, style, color := bodyFontSize, FontRegular, ColorBlack
+		if row.emphasis {
+			l.ensureSpace(lineHeight * 2)
+			l.pdf.Line(labelRight-80, l.y-11, PageWidth-margin, l.y-11, 0.8, l.theme)
+			l.y += 4
+			size, style, color = 12, FontBold, l.theme
+		} else {
+			l.ensureSpace(lineHeight)
+		}
+		l.pdf.TextRight(labelRight, l.y, size, style, ColorBlack, row.label)
+		l.pdf.TextRight(PageWidth-margin-6, l.y, size, style, color, FormatAmount(row.amount, currency))
+		l.y += lineHeight + 2
+	}
+}
+
+// taxRows 生成税费行
+func (l *layout) taxRows(taxLines []TaxLine) []totalRow {
+	rows := make([]totalRow, 0, len(taxLines))
+	for _, t := range taxLines {
+		name := t.Name
+		if name == "" {
+			name = l.label(labelTax)
+		}
+		if t.RateBps > 0 {
+			name = fmt.Sprintf("%s (%s)", name, FormatRate(t.RateBps))
+		}
+		rows = append(rows, totalRow{label: name, amount: t.Amount})
+	}
+	return rows
+}
+
+// finish 为每页绘制页脚并输出 PDF
+func (l *layout) finish() ([]byte, error) {
+	total := l.pdf.PageCount()
+	for i := 0; i < total; i++ {
+		l.pdf.setPage(i)
+		l.pdf.Line(margin, footerTop, PageWidth-margin, footerTop, 0.5, ColorLightGray)
+		l.pdf.Text(margin, footerTop+16, 8, FontRegular, ColorGray, l.footer)
+		l.pdf.TextRight(PageWidth-margin, footerTop+16, 8, FontRegular, ColorGray,
+			fmt.Sprintf("%s %d / %d", l.label(labelPage), i+1, total))
+	}
+	return l.pdf.Bytes()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func joinNonEmpty(parts []string, sep string) string {
+	out := ""
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if out != "" {
+			out += sep
+		}
+		out += p
+	}
+	return out
+}
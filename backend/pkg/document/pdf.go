@@ -0,0 +1,363 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 页面尺寸（单位：pt）
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// FontStyle 字体样式
+type FontStyle int
+
+const (
+	FontRegular FontStyle = iota
+	FontBold
+)
+
+// Color RGB 颜色
+type Color struct {
+	R, G, B uint8
+}
+
+// 常用颜色
+var (
+	ColorBlack     = Color{0x26, 0x26, 0x26}
+	ColorGray      = Color{0x8c, 0x8c, 0x8c}
+	ColorLightGray = Color{0xf0, 0xf0, 0xf0}
+	ColorWhite     = Color{0xff, 0xff, 0xff}
+	DefaultTheme   = Color{0x18, 0x90, 0xff} // 与收银台默认主题色一致
+)
+
+// ParseHexColor 解析 #RRGGBB 或 #RGB 格式的颜色
+func ParseHexColor(s string) (Color, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return Color{}, fmt.Errorf("无效的颜色: %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("无效的颜色: %q", s)
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// cjkFont PDF 阅读器内置的 CJK 字体（无需嵌入字体文件）
+type cjkFont struct {
+	baseFont string
+	encoding string
+	ordering string
+	suppl    int
+}
+
+var cjkFonts = map[string]cjkFont{
+	LangZhCN: {"STSong-Light", "UniGB-UTF16-H", "GB1", 4},
+	LangZhTW: {"MSung-Light", "UniCNS-UTF16-H", "CNS1", 4},
+	LangJa:   {"KozMinPro-Regular-Acro", "UniJIS-UTF16-H", "Japan1", 4},
+	LangKo:   {"HYSMyeongJo-Medium", "UniKS-UTF16-H", "Korea1", 2},
+}
+
+// PDF 极简 PDF 写入器
+//
+// 只支持文本、矩形和直线，足以生成收据、账单和报表。
+// 拉丁字符使用内置的 Helvetica 字体；包含非拉丁字符的文本使用 PDF 阅读器内置的 CJK 字体
+// （Adobe 预定义 CMap），因此生成的文件很小且不依赖字体文件。
+// 坐标原点在页面左上角，y 轴向下。
+type PDF struct {
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+	cjk   cjkFont
+	title string
+}
+
+// NewPDF 创建 PDF，lang 决定非拉丁文本使用的 CJK 字体
+func NewPDF(lang string) *PDF {
+	font, ok := cjkFonts[NormalizeLanguage(lang)]
+	if !ok {
+		font = cjkFonts[LangZhCN]
+	}
+	return &PDF{cjk: font}
+}
+
+// SetTitle 设置文档标题（文档属性）
+func (p *PDF) SetTitle(title string) {
+	p.title = title
+}
+
+// AddPage 新增一页，后续绘制都在该页上
+func (p *PDF) AddPage() {
+	p.cur = &bytes.Buffer{}
+	p.pages = append(p.pages, p.cur)
+}
+
+// PageCount 当前页数
+func (p *PDF) PageCount() int {
+	return len(p.pages)
+}
+
+// setPage 切换到已有页面继续绘制（用于补绘页脚）
+func (p *PDF) setPage(i int) {
+	p.cur = p.pages[i]
+}
+
+// Text 在 (x, y) 处绘制文本，y 为文本基线位置
+func (p *PDF) Text(x, y, size float64, style FontStyle, color Color, s string) {
+	if s == "" {
+		return
+	}
+	p.ensurePage()
+
+	font, encoded := p.encodeText(s, style)
+	fmt.Fprintf(p.cur, "BT /%s %s Tf %s rg ", font, fmtNum(size), fmtColor(color))
+	if font == "F3" && style == FontBold {
+		// CJK 字体没有粗体，用描边模拟
+		fmt.Fprintf(p.cur, "%s RG 2 Tr %s w ", fmtColor(color), fmtNum(size/30))
+	}
+	fmt.Fprintf(p.cur, "%s %s Td %s Tj ET\n", fmtNum(x), fmtNum(PageHeight-y), encoded)
+}
+
+// TextRight 右对齐绘制文本，right 为文本右边界
+func (p *PDF) TextRight(right, y, size float64, style FontStyle, color Color, s string) {
+	p.Text(right-p.TextWidth(s, size, style), y, size, style, color, s)
+}
+
+// TextWidth 计算文本宽度
+func (p *PDF) TextWidth(s string, size float64, style FontStyle) float64 {
+	var units int
+	if isLatin(s) {
+		widths := helveticaWidths
+		if style == FontBold {
+			widths = helveticaBoldWidths
+		}
+		for _, r := range s {
+			if r >= 32 && r <= 126 {
+				units += widths[r-32]
+			} else {
+				units += 556
+			}
+		}
+	} else {
+		for _, r := range s {
+			if r < 0x80 {
+				units += 500
+			} else {
+				units += 1000
+			}
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// WrapText 按最大宽度折行（优先在空格处断行）
+func (p *PDF) WrapText(s string, size float64, style FontStyle, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		runes := []rune(paragraph)
+		for len(runes) > 0 {
+			end := len(runes)
+			for end > 1 && p.TextWidth(string(runes[:end]), size, style) > maxWidth {
+				end--
+			}
+			if end < len(runes) {
+				if sp := lastSpace(runes[:end]); sp > 0 {
+					end = sp
+				}
+			}
+			lines = append(lines, strings.TrimSpace(string(runes[:end])))
+			runes = []rune(strings.TrimLeft(string(runes[end:]), " "))
+		}
+		if len(paragraph) == 0 {
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
+
+// FillRect 绘制填充矩形，(x, y) 为左上角
+func (p *PDF) FillRect(x, y, w, h float64, color Color) {
+	p.ensurePage()
+	fmt.Fprintf(p.cur, "%s rg %s %s %s %s re f\n",
+		fmtColor(color), fmtNum(x), fmtNum(PageHeight-y-h), fmtNum(w), fmtNum(h))
+}
+
+// Line 绘制直线
+func (p *PDF) Line(x1, y1, x2, y2, width float64, color Color) {
+	p.ensurePage()
+	fmt.Fprintf(p.cur, "%s RG %s w %s %s m %s %s l S\n",
+		fmtColor(color), fmtNum(width), fmtNum(x1), fmtNum(PageHeight-y1), fmtNum(x2), fmtNum(PageHeight-y2))
+}
+
+// Bytes 输出 PDF 文件内容
+func (p *PDF) Bytes() ([]byte, error) {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	newObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象编号：1 Catalog，2 Pages，3-7 字体，8 Info，之后每页两个对象（Page + Contents）
+	const firstPageObj = 9
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+
+	newObj("<< /Type /Catalog /Pages 2 0 R >>")
+	newObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	newObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	newObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	newObj(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /%s /DescendantFonts [6 0 R] >>",
+		p.cjk.baseFont, p.cjk.encoding))
+	newObj(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (%s) /Supplement %d >> "+
+		"/FontDescriptor 7 0 R /DW 1000 /W [1 95 500] >>",
+		p.cjk.baseFont, p.cjk.ordering, p.cjk.suppl))
+	newObj(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 6 /FontBBox [-25 -254 1000 880] "+
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>", p.cjk.baseFont))
+	newObj(fmt.Sprintf("<< /Producer (payment-platform) /Title %s >>", pdfTextString(p.title)))
+
+	for i, page := range p.pages {
+		newObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			fmtNum(PageWidth), fmtNum(PageHeight), firstPageObj+i*2+1))
+		newObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 8 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+func (p *PDF) ensurePage() {
+	if p.cur == nil {
+		p.AddPage()
+	}
+}
+
+// encodeText 选择字体并编码文本
+func (p *PDF) encodeText(s string, style FontStyle) (string, string) {
+	if isLatin(s) {
+		font := "F1"
+		if style == FontBold {
+			font = "F2"
+		}
+		var b strings.Builder
+		b.WriteByte('(')
+		for _, r := range s {
+			switch r {
+			case '(', ')', '\\':
+				b.WriteByte('\\')
+				b.WriteRune(r)
+			default:
+				b.WriteByte(byte(r))
+			}
+		}
+		b.WriteByte(')')
+		return font, b.String()
+	}
+
+	// CJK 字体使用 UTF-16BE 编码（Uni*-UTF16-H CMap）
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return "F3", b.String()
+}
+
+// isLatin 文本是否可以用 WinAnsi 编码的 Helvetica 显示
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || (r > 0x7e && r < 0xa0) || r > 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// pdfTextString 编码文档属性中的字符串（非 ASCII 使用带 BOM 的 UTF-16BE）
+func pdfTextString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+		return "(" + r.Replace(s) + ")"
+	}
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i > 0; i-- {
+		if runes[i] == ' ' {
+			return i
+		}
+	}
+	return -1
+}
+
+func fmtNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func fmtColor(c Color) string {
+	return fmt.Sprintf("%s %s %s", fmtNum(roundColor(c.R)), fmtNum(roundColor(c.G)), fmtNum(roundColor(c.B)))
+}
+
+func roundColor(v uint8) float64 {
+	return float64(int(float64(v)/255*1000+0.5)) / 1000
+}
+
+// Helvetica / Helvetica-Bold 字宽（ASCII 32-126，单位 1/1000 em，取自 Adobe AFM）
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
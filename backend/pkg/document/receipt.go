@@ -0,0 +1,106 @@
+package document
+
+import (
+	"errors"
+	"time"
+)
+
+// 收据类型
+const (
+	ReceiptKindPayment = "payment"
+	ReceiptKindRefund  = "refund"
+)
+
+// Receipt 支付/退款收据
+type Receipt struct {
+	Kind          string // payment 或 refund
+	Language      string
+	Location      *time.Location // 时间展示时区，为空时使用 UTC
+	Branding      Branding
+	ReceiptNo     string
+	PaymentNo     string
+	RefundNo      string
+	OrderNo       string
+	Description   string
+	PayMethod     string
+	CustomerName  string
+	CustomerEmail string
+	Currency      string
+	Items         []LineItem // 为空时以 Description 和金额生成一行
+	TaxLines      []TaxLine  // 价内税（已包含在 Total 中）
+	Total         int64      // 实付/退款金额（最小货币单位）
+	Reason        string     // 退款原因
+	OccurredAt    time.Time  // 支付/退款完成时间
+	IssuedAt      time.Time
+}
+
+// RenderReceipt 渲染收据 PDF
+func RenderReceipt(r *Receipt) ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("收据不能为空")
+	}
+	if r.Kind != ReceiptKindPayment && r.Kind != ReceiptKindRefund {
+		return nil, errors.New("不支持的收据类型: " + r.Kind)
+	}
+
+	lang := NormalizeLanguage(r.Language)
+	titleKey, timeKey, totalKey := labelReceipt, labelPaidAt, labelAmountPaid
+	if r.Kind == ReceiptKindRefund {
+		titleKey, timeKey, totalKey = labelRefundReceipt, labelRefundedAt, labelAmountRefunded
+	}
+	l := newLayout(lang, r.Branding, label(lang, titleKey), r.Location)
+
+	issuedAt := r.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+	fields := []Field{
+		{l.label(labelReceiptNo), r.ReceiptNo},
+		{l.label(labelIssuedAt), formatTime(issuedAt, l.loc)},
+		{l.label(labelPaymentNo), r.PaymentNo},
+		{l.label(timeKey), formatTime(r.OccurredAt, l.loc)},
+	}
+	if r.Kind == ReceiptKindRefund {
+		fields = append(fields, Field{l.label(labelRefundNo), r.RefundNo})
+	}
+	fields = append(fields, Field{l.label(labelOrderNo), r.OrderNo})
+	if r.PayMethod != "" {
+		fields = append(fields, Field{l.label(labelPayMethod), r.PayMethod})
+	}
+	if customer := joinNonEmpty([]string{r.CustomerName, r.CustomerEmail}, " / "); customer != "" {
+		fields = append(fields, Field{l.label(labelCustomer), customer})
+	}
+	l.fields(fields)
+	l.space(lineHeight)
+
+	items := r.Items
+	if len(items) == 0 {
+		items = []LineItem{{Description: r.Description, Quantity: 1, UnitPrice: r.Total, Amount: r.Total}}
+	}
+	l.itemsTable(items, r.Currency)
+
+	// 税费为价内税，合计等于实付金额
+	var subtotal int64
+	for _, item := range items {
+		subtotal += item.Amount
+	}
+	for _, t := range r.TaxLines {
+		subtotal -= t.Amount
+	}
+	rows := []totalRow{{label: l.label(labelSubtotal), amount: subtotal}}
+	rows = append(rows, l.taxRows(r.TaxLines)...)
+	rows = append(rows, totalRow{label: l.label(totalKey), amount: r.Total, emphasis: true})
+	l.totals(rows, r.Currency)
+
+	if r.Kind == ReceiptKindRefund && r.Reason != "" {
+		l.space(lineHeight)
+		l.heading(l.label(labelReason))
+		l.paragraph(r.Reason, ColorBlack)
+	}
+
+	if r.Kind == ReceiptKindPayment {
+		l.space(lineHeight)
+		l.paragraph(l.label(labelThankYou), ColorGray)
+	}
+	return l.finish()
+}
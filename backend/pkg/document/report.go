@@ -0,0 +1,187 @@
+package document
+
+import (
+	"errors"
+	"time"
+)
+
+// Report 通用报表（如对账报告），由若干小节组成
+//
+// 报表的标签文本由调用方提供，不做多语言翻译；Language 仅决定非拉丁文本使用的字体。
+type Report struct {
+	Language string
+	Location *time.Location // 时间展示时区，为空时使用 UTC
+	Branding Branding
+	Title    string
+	Fields   []Field // 报表头部的概要信息
+	Sections []ReportSection
+	Footer   string // 页脚文本，为空时使用默认文本
+}
+
+// ReportSection 报表小节，按 Fields、Chart、Table、Note 的顺序绘制
+type ReportSection struct {
+	Heading string
+	Fields  []Field
+	Chart   *BarChart
+	Table   *Table
+	Note    string
+}
+
+// BarChart 横向条形图
+type BarChart struct {
+	Bars []Bar
+}
+
+// Bar 条形图中的一项
+type Bar struct {
+	Label   string
+	Value   float64 // 条形长度按 Value 与最大值的比例绘制
+	Display string  // 条形右侧展示的文本，为空时不展示
+	Color   *Color  // 为空时使用主题色
+}
+
+// Table 表格（超出一页时在新页重复表头，单元格超长时截断）
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Column 表格列
+type Column struct {
+	Title      string
+	Width      float64 // 占内容区宽度的比例，所有列之和应为 1
+	AlignRight bool
+}
+
+// RenderReport 渲染报表 PDF
+func RenderReport(r *Report) ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("报表不能为空")
+	}
+
+	l := newLayout(r.Language, r.Branding, r.Title, r.Location)
+	if r.Footer != "" {
+		l.footer = r.Footer
+	}
+
+	if len(r.Fields) > 0 {
+		l.fields(r.Fields)
+		l.space(lineHeight / 2)
+	}
+	for _, section := range r.Sections {
+		if section.Heading != "" {
+			l.space(lineHeight / 2)
+			l.heading(section.Heading)
+		}
+		if len(section.Fields) > 0 {
+			l.fields(section.Fields)
+		}
+		if section.Chart != nil {
+			l.barChart(section.Chart)
+		}
+		if section.Table != nil {
+			l.table(section.Table)
+		}
+		if section.Note != "" {
+			l.paragraph(section.Note, ColorGray)
+		}
+	}
+	return l.finish()
+}
+
+// barChart 横向条形图
+func (l *layout) barChart(chart *BarChart) {
+	const (
+		labelWidth = 130.0
+		valueWidth = 110.0
+		barHeight  = 10.0
+	)
+	maxValue := 0.0
+	for _, bar := range chart.Bars {
+		if bar.Value > maxValue {
+			maxValue = bar.Value
+		}
+	}
+
+	barLeft := margin + labelWidth
+	barWidth := contentWidth - labelWidth - valueWidth
+	for _, bar := range chart.Bars {
+		l.ensureSpace(lineHeight + 4)
+		l.pdf.Text(margin, l.y, 9, FontRegular, ColorBlack, truncateText(l.pdf, bar.Label, 9, FontRegular, labelWidth-8))
+		l.pdf.FillRect(barLeft, l.y-barHeight+1, barWidth, barHeight, ColorLightGray)
+		if maxValue > 0 && bar.Value > 0 {
+			color := l.theme
+			if bar.Color != nil {
+				color = *bar.Color
+			}
+			w := barWidth * bar.Value / maxValue
+			if w < 1 {
+				w = 1
+			}
+			l.pdf.FillRect(barLeft, l.y-barHeight+1, w, barHeight, color)
+		}
+		if bar.Display != "" {
+			l.pdf.TextRight(PageWidth-margin, l.y, 9, FontRegular, ColorBlack, bar.Display)
+		}
+		l.y += lineHeight + 4
+	}
+	l.y += 4
+}
+
+// table 通用表格
+func (l *layout) table(t *Table) {
+	const size = 8.0
+	xs := make([]float64, len(t.Columns))
+	widths := make([]float64, len(t.Columns))
+	x := margin
+	for i, col := range t.Columns {
+		xs[i] = x
+		widths[i] = contentWidth * col.Width
+		x += widths[i]
+	}
+	cellText := func(col int, s string, style FontStyle) {
+		s = truncateText(l.pdf, s, size, style, widths[col]-8)
+		if t.Columns[col].AlignRight {
+			l.pdf.TextRight(xs[col]+widths[col]-4, l.y, size, style, ColorBlack, s)
+		} else {
+			l.pdf.Text(xs[col]+4, l.y, size, style, ColorBlack, s)
+		}
+	}
+
+	drawHeader := func() {
+		l.pdf.FillRect(margin, l.y-11, contentWidth, 16, ColorLightGray)
+		for i, col := range t.Columns {
+			cellText(i, col.Title, FontBold)
+		}
+		l.y += 18
+	}
+
+	l.ensureSpace(lineHeight * 3)
+	drawHeader()
+	for _, row := range t.Rows {
+		if l.y+lineHeight > footerTop-16 {
+			l.newPage()
+			drawHeader()
+		}
+		for i := range t.Columns {
+			if i < len(row) {
+				cellText(i, row[i], FontRegular)
+			}
+		}
+		l.pdf.Line(margin, l.y+4, PageWidth-margin, l.y+4, 0.3, ColorLightGray)
+		l.y += lineHeight - 1
+	}
+	l.y += 6
+}
+
+// truncateText 截断超出宽度的文本
+func truncateText(pdf *PDF, s string, size float64, style FontStyle, maxWidth float64) string {
+	if pdf.TextWidth(s, size, style) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"..", size, style) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
@@ -0,0 +1,259 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Workbook 极简 XLSX 写入器
+//
+// 只支持文本、数字、金额和百分比单元格，以及表头加粗和冻结首行，
+// 字符串使用内联字符串（inlineStr），不依赖第三方库。
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// Sheet 工作表
+type Sheet struct {
+	name         string
+	widths       []float64
+	freezeHeader bool
+	rows         [][]Cell
+}
+
+type cellKind int
+
+const (
+	cellText cellKind = iota
+	cellNumber
+)
+
+// 单元格样式，对应 styles.xml 中 cellXfs 的下标
+const (
+	styleDefault = iota
+	styleHeader
+	styleAmount0 // 整数金额
+	styleAmount2 // 两位小数金额
+	styleAmount3 // 三位小数金额
+	stylePercent
+)
+
+// Cell 单元格
+type Cell struct {
+	kind   cellKind
+	text   string
+	number float64
+	style  int
+}
+
+// TextCell 文本单元格
+func TextCell(s string) Cell {
+	return Cell{kind: cellText, text: s}
+}
+
+// HeaderCell 加粗的表头单元格
+func HeaderCell(s string) Cell {
+	return Cell{kind: cellText, text: s, style: styleHeader}
+}
+
+// NumberCell 数字单元格
+func NumberCell(v float64) Cell {
+	return Cell{kind: cellNumber, number: v}
+}
+
+// AmountCell 金额单元格（amount 为最小货币单位，按币种小数位转换并设置数字格式）
+func AmountCell(amount int64, currency string) Cell {
+	decimals := CurrencyDecimals(currency)
+	style := styleAmount2
+	switch decimals {
+	case 0:
+		style = styleAmount0
+	case 3:
+		style = styleAmount3
+	}
+	return Cell{kind: cellNumber, number: float64(amount) / math.Pow10(decimals), style: style}
+}
+
+// PercentCell 百分比单元格，ratio 取值 0~1
+func PercentCell(ratio float64) Cell {
+	return Cell{kind: cellNumber, number: ratio, style: stylePercent}
+}
+
+// NewWorkbook 创建工作簿
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet 新增工作表，名称中的非法字符会被替换，超过 31 个字符时截断
+func (w *Workbook) AddSheet(name string) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(w.sheets)+1)
+	}
+	s := &Sheet{name: name}
+	w.sheets = append(w.sheets, s)
+	return s
+}
+
+// SetColumnWidths 设置列宽（字符数）
+func (s *Sheet) SetColumnWidths(widths ...float64) {
+	s.widths = widths
+}
+
+// FreezeHeader 冻结首行
+func (s *Sheet) FreezeHeader() {
+	s.freezeHeader = true
+}
+
+// AddRow 追加一行
+func (s *Sheet) AddRow(cells ...Cell) {
+	s.rows = append(s.rows, cells)
+}
+
+// RowCount 当前行数
+func (s *Sheet) RowCount() int {
+	return len(s.rows)
+}
+
+// Bytes 输出 XLSX 文件内容
+func (w *Workbook) Bytes() ([]byte, error) {
+	if len(w.sheets) == 0 {
+		w.AddSheet("Sheet1")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write([]byte(content))
+		return err
+	}
+
+	var overrides, sheetEntries, sheetRels strings.Builder
+	for i, s := range w.sheets {
+		id := i + 1
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id)
+		fmt.Fprintf(&sheetEntries, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(s.name), id, id)
+		fmt.Fprintf(&sheetRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id)
+	}
+	stylesRel := len(w.sheets) + 1
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheetEntries.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			sheetRels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesRel) +
+			`</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, s := range w.sheets {
+		files = append(files, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+
+	for _, f := range files {
+		if err := write(f.name, f.content); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsxStyles 样式表：cellXfs 的顺序与 style* 常量一一对应
+// numFmtId 1/3/4/10 为内置格式（0、#,##0、#,##0.00、0.00%）
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="#,##0.000"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFF0F0F0"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs></styleSheet>`
+
+// xml 生成工作表 XML
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.freezeHeader {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	if len(s.widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		b.WriteString(`</cols>`)
+	}
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch cell.kind {
+			case cellNumber:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.style, strconv.FormatFloat(cell.number, 'f', -1, 64))
+			default:
+				if cell.text == "" {
+					fmt.Fprintf(&b, `<c r="%s" s="%d"/>`, ref, cell.style)
+					continue
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.style, xmlEscape(cell.text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName 列序号（从 0 开始）转换为 A、B、...、AA 形式
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	// EscapeText 会把非法的 XML 字符替换为 U+FFFD
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
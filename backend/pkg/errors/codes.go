@@ -41,6 +41,7 @@ const (
 	ErrCodeAmountExceedsLimit   = "AMOUNT_EXCEEDS_LIMIT"
 	ErrCodeChannelUnavailable   = "CHANNEL_UNAVAILABLE"
 	ErrCodeChannelNotSupported  = "CHANNEL_NOT_SUPPORTED"
+	ErrCodePaymentDeclined      = "PAYMENT_DECLINED"
 
 	// 退款相关错误 (5000-5999)
 	ErrCodeRefundFailed         = "REFUND_FAILED"
@@ -132,6 +133,7 @@ var errorMessages = map[string]string{
 	ErrCodeAmountExceedsLimit:   "金额超过限制",
 	ErrCodeChannelUnavailable:   "支付渠道不可用",
 	ErrCodeChannelNotSupported:  "不支持该支付渠道",
+	ErrCodePaymentDeclined:      "支付被拒绝",
 
 	ErrCodeRefundFailed:         "退款失败",
 	ErrCodeRefundNotAllowed:     "不允许退款",
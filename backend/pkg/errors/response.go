@@ -125,6 +125,8 @@ func GetHTTPStatus(code string) int {
 		return http.StatusInternalServerError
 	case ErrCodeRiskRejected, ErrCodeRiskScoreTooHigh, ErrCodeBlacklistMatch:
 		return http.StatusForbidden
+	case ErrCodePaymentDeclined:
+		return http.StatusPaymentRequired
 	default:
 		return http.StatusInternalServerError
 	}
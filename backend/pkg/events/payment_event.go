@@ -3,6 +3,8 @@ package events
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/payment-platform/pkg/decline"
 )
 
 // PaymentEvent 支付事件
@@ -13,16 +15,28 @@ type PaymentEvent struct {
 
 // PaymentEventPayload 支付事件载荷
 type PaymentEventPayload struct {
-	PaymentNo     string                 `json:"payment_no"`     // 支付流水号
-	MerchantID    string                 `json:"merchant_id"`    // 商户ID
-	OrderNo       string                 `json:"order_no"`       // 订单号
-	Amount        int64                  `json:"amount"`         // 金额(分)
-	Currency      string                 `json:"currency"`       // 货币
-	Channel       string                 `json:"channel"`        // 支付渠道
-	Status        string                 `json:"status"`         // 状态
-	CustomerEmail string                 `json:"customer_email"` // 客户邮箱
-	PaidAt        *time.Time             `json:"paid_at"`        // 支付时间
-	Extra         map[string]interface{} `json:"extra"`          // 扩展信息
+	PaymentNo     string                 `json:"payment_no"`           // 支付流水号
+	MerchantID    string                 `json:"merchant_id"`          // 商户ID
+	OrderNo       string                 `json:"order_no"`             // 订单号
+	Amount        int64                  `json:"amount"`               // 金额(分)
+	Currency      string                 `json:"currency"`             // 货币
+	Channel       string                 `json:"channel"`              // 支付渠道
+	PayMethod     string                 `json:"pay_method"`           // 支付方式
+	Country       string                 `json:"country"`              // 客户国家/地区（ISO 3166-1）
+	Status        string                 `json:"status"`               // 状态
+	CustomerEmail string                 `json:"customer_email"`       // 客户邮箱
+	PaidAt        *time.Time             `json:"paid_at"`              // 支付时间
+	LatencyMs     int64                  `json:"latency_ms,omitempty"` // 渠道下单耗时(毫秒)
+	Splits        []SplitPosting         `json:"splits,omitempty"`     // 分账明细（平台商户分账支付时按收款方入账）
+	Decline       *decline.Decline       `json:"decline,omitempty"`    // 归一化拒绝原因（支付失败时）
+	Extra         map[string]interface{} `json:"extra"`                // 扩展信息
+}
+
+// SplitPosting 分账记账明细（按收款方拆分的入账或冲正金额）
+type SplitPosting struct {
+	MerchantID string `json:"merchant_id"` // 收款方商户ID
+	Role       string `json:"role"`        // 收款方角色：platform, sub_merchant
+	Amount     int64  `json:"amount"`      // 金额(分)，入账与冲正均为正数
 }
 
 // Event Type Constants
@@ -32,6 +46,9 @@ const (
 	PaymentFailed    = "payment.failed"
 	PaymentCancelled = "payment.cancelled"
 	PaymentExpired   = "payment.expired"
+
+	// PaymentChargeback 拒付败诉，载荷金额为拒付金额，Splits 为按比例冲正的分账明细
+	PaymentChargeback = "payment.chargeback"
 )
 
 // NewPaymentEvent 创建支付事件
@@ -71,16 +88,20 @@ type RefundEvent struct {
 
 // RefundEventPayload 退款事件载荷
 type RefundEventPayload struct {
-	RefundNo   string                 `json:"refund_no"`   // 退款单号
-	PaymentNo  string                 `json:"payment_no"`  // 支付流水号
-	MerchantID string                 `json:"merchant_id"` // 商户ID
-	OrderNo    string                 `json:"order_no"`    // 订单号
-	Amount     int64                  `json:"amount"`      // 退款金额
-	Currency   string                 `json:"currency"`    // 货币
-	Reason     string                 `json:"reason"`      // 退款原因
-	Status     string                 `json:"status"`      // 状态
-	RefundedAt *time.Time             `json:"refunded_at"` // 退款时间
-	Extra      map[string]interface{} `json:"extra"`       // 扩展信息
+	RefundNo   string                 `json:"refund_no"`        // 退款单号
+	PaymentNo  string                 `json:"payment_no"`       // 支付流水号
+	MerchantID string                 `json:"merchant_id"`      // 商户ID
+	OrderNo    string                 `json:"order_no"`         // 订单号
+	Amount     int64                  `json:"amount"`           // 退款金额
+	Currency   string                 `json:"currency"`         // 货币
+	Channel    string                 `json:"channel"`          // 原支付渠道
+	PayMethod  string                 `json:"pay_method"`       // 原支付方式
+	Country    string                 `json:"country"`          // 客户国家/地区（ISO 3166-1）
+	Reason     string                 `json:"reason"`           // 退款原因
+	Status     string                 `json:"status"`           // 状态
+	RefundedAt *time.Time             `json:"refunded_at"`      // 退款时间
+	Splits     []SplitPosting         `json:"splits,omitempty"` // 分账冲正明细（按原分账比例）
+	Extra      map[string]interface{} `json:"extra"`            // 扩展信息
 }
 
 // Refund Event Type Constants
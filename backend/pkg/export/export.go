@@ -186,6 +186,71 @@ func (s *ExportService) ExportToCSV(ctx context.Context, taskID uuid.UUID, heade
 	return nil
 }
 
+// StoreFile 保存已生成的文件（如 PDF 收据、账单）并登记为已完成的导出任务
+// 文件名由调用方给出（不含目录），同名文件会被覆盖
+func (s *ExportService) StoreFile(ctx context.Context, merchantID uuid.UUID, exportType, format, fileName string, content []byte) (*ExportTask, error) {
+	fileName = filepath.Base(fileName)
+	filePath := filepath.Join(s.storageDir, fileName)
+
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return nil, fmt.Errorf("写入文件失败: %w", err)
+	}
+
+	now := time.Now()
+	task := &ExportTask{
+		ID:          uuid.New(),
+		MerchantID:  merchantID,
+		Type:        exportType,
+		Format:      format,
+		Status:      "completed",
+		FileName:    fileName,
+		FilePath:    filePath,
+		FileSize:    int64(len(content)),
+		RowCount:    1,
+		StartDate:   now,
+		EndDate:     now,
+		CreatedAt:   now,
+		CompletedAt: &now,
+	}
+
+	if err := s.db.WithContext(ctx).Create(task).Error; err != nil {
+		os.Remove(filePath)
+		return nil, fmt.Errorf("创建导出任务失败: %w", err)
+	}
+
+	logger.Info("文件已保存",
+		zap.String("task_id", task.ID.String()),
+		zap.String("type", exportType),
+		zap.String("file_path", filePath),
+		zap.Int64("file_size", task.FileSize))
+
+	return task, nil
+}
+
+// FindStoredFile 按文件名查找 StoreFile 保存的文件，不存在时返回 nil
+func (s *ExportService) FindStoredFile(ctx context.Context, merchantID uuid.UUID, fileName string) (*ExportTask, error) {
+	var task ExportTask
+	err := s.db.WithContext(ctx).
+		Where("merchant_id = ? AND file_name = ? AND status = ?", merchantID, filepath.Base(fileName), "completed").
+		Order("created_at DESC").
+		First(&task).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ReadFile 读取导出任务的文件内容（文件已被清理时返回 os.ErrNotExist）
+func (s *ExportService) ReadFile(task *ExportTask) ([]byte, error) {
+	if task == nil || task.FilePath == "" {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(task.FilePath)
+}
+
 // CleanupExpiredTasks 清理过期任务（建议定时调用）
 func (s *ExportService) CleanupExpiredTasks(ctx context.Context, expireDays int) error {
 	expireDate := time.Now().AddDate(0, 0, -expireDays)
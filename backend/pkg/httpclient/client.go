@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/payment-platform/pkg/idempotency"
 	"github.com/payment-platform/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -134,6 +135,13 @@ func (c *Client) doRequest(req *Request) (*Response, error) {
 		httpReq.Header.Set(k, v)
 	}
 
+	// Saga 步骤等调用方通过上下文传递幂等键，重试时下游据此去重
+	if req.Ctx != nil && httpReq.Header.Get(idempotency.HeaderName) == "" {
+		if key := idempotency.RequestKey(req.Ctx, req.Method, httpReq.URL.Path); key != "" {
+			httpReq.Header.Set(idempotency.HeaderName, key)
+		}
+	}
+
 	// 如果有Body，设置Content-Type
 	if req.Body != nil && httpReq.Header.Get("Content-Type") == "" {
 		httpReq.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+)
+
+// HeaderName 幂等键请求头（与 middleware.IdempotencyMiddleware 读取的请求头一致）
+const HeaderName = "Idempotency-Key"
+
+type contextKey struct{}
+
+// WithKey 将幂等键写入上下文，下游 HTTP 客户端据此自动携带幂等键请求头
+func WithKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// KeyFromContext 获取上下文中的幂等键
+func KeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(contextKey{}).(string)
+	return key
+}
+
+// RequestKey 根据上下文幂等键派生单次下游调用的幂等键
+//
+// 同一个 Saga 步骤内可能调用多个接口，按 method + path 区分，避免不同接口共用一个幂等键
+// 而返回彼此的缓存响应；同一接口重试时派生结果不变，下游据此去重。
+func RequestKey(ctx context.Context, method, path string) string {
+	key := KeyFromContext(ctx)
+	if key == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%s", key, method, path)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 服务间调用认证请求头
+const (
+	HeaderServiceName  = "X-Service-Name"
+	HeaderServiceToken = "X-Service-Token"
+)
+
+// ServiceNameKey 认证通过后调用方服务名在 gin.Context 中的键
+const ServiceNameKey = "service_name"
+
+// ServiceAuthMiddleware 服务间调用认证（/api/v1/service/* 路由）
+//
+// 调用方通过 X-Service-Name + X-Service-Token（共享令牌 INTERNAL_SERVICE_TOKEN）认证；
+// 启用 mTLS 时，客户端证书已由 TLS 层验证，直接使用证书 CN 作为服务名。
+// token 为空且请求未携带已验证的客户端证书时拒绝所有请求。
+// allowedServices 非空时只允许列出的服务调用。
+func ServiceAuthMiddleware(token string, allowedServices ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serviceName := c.GetString("client_service") // pkg/tls.MTLSMiddleware 写入
+		if serviceName == "" {
+			provided := c.GetHeader(HeaderServiceToken)
+			if token == "" || provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				c.JSON(401, gin.H{"error": "service authentication required"})
+				c.Abort()
+				return
+			}
+			serviceName = c.GetHeader(HeaderServiceName)
+		}
+
+		if serviceName == "" || (len(allowedServices) > 0 && !containsService(allowedServices, serviceName)) {
+			c.JSON(403, gin.H{"error": "forbidden: service not allowed"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ServiceNameKey, serviceName)
+		c.Next()
+	}
+}
+
+// ServiceAuthHeaders 服务间调用需要携带的认证请求头
+func ServiceAuthHeaders(serviceName, token string) map[string]string {
+	return map[string]string{
+		HeaderServiceName:  serviceName,
+		HeaderServiceToken: token,
+	}
+}
+
+func containsService(services []string, name string) bool {
+	for _, s := range services {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
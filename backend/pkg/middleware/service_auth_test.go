@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(token string, allowed ...string) *gin.Engine {
+		r := gin.New()
+		r.POST("/service/refunds", ServiceAuthMiddleware(token, allowed...), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString(ServiceNameKey))
+		})
+		return r
+	}
+	do := func(r *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/service/refunds", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := newRouter("s3cret", "order-service")
+
+	w := do(r, ServiceAuthHeaders("order-service", "s3cret"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "order-service", w.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(r, ServiceAuthHeaders("order-service", "wrong")).Code)
+	assert.Equal(t, http.StatusForbidden, do(r, ServiceAuthHeaders("dispute-service", "s3cret")).Code)
+
+	// 未配置令牌时拒绝所有请求
+	assert.Equal(t, http.StatusUnauthorized, do(newRouter(""), ServiceAuthHeaders("order-service", "")).Code)
+}
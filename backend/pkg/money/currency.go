@@ -0,0 +1,30 @@
+package money
+
+import "strings"
+
+// minorUnits ISO 4217 货币的小数位数（最小货币单位），未列出的货币按两位小数处理
+var minorUnits = map[string]int{
+	// 无小数位
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// 三位小数
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// 四位小数
+	"CLF": 4, "UYW": 4,
+}
+
+// DefaultDecimals 未登记货币的小数位数
+const DefaultDecimals = 2
+
+// Decimals 货币的小数位数，如 USD 为 2，JPY 为 0，KWD 为 3
+func Decimals(currency string) int {
+	if d, ok := minorUnits[NormalizeCurrency(currency)]; ok {
+		return d
+	}
+	return DefaultDecimals
+}
+
+// NormalizeCurrency 统一为大写货币代码
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,305 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode 舍入方式
+type RoundingMode int
+
+const (
+	// RoundHalfEven 四舍六入五成双（银行家舍入），用于汇率换算，长期累计无偏差
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp 四舍五入（0.5 远离零），用于手续费、税费
+	RoundHalfUp
+	// RoundUp 远离零方向进位，只要有余数就进一
+	RoundUp
+	// RoundDown 向零方向截断
+	RoundDown
+)
+
+// Decimal 十进制定点数，值为 coef × 10^-scale，用于费率、汇率等非整数量的精确运算
+//
+// 零值表示 0，可直接使用。
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+// ErrInvalidDecimal 无法解析的十进制数
+var ErrInvalidDecimal = errors.New("money: 无效的十进制数")
+
+// NewDecimal 创建 coef × 10^-scale，如 NewDecimal(29, 3) 表示 0.029
+func NewDecimal(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), bigPow10(int(-scale)))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// DecimalFromInt 整数
+func DecimalFromInt(v int64) Decimal {
+	return NewDecimal(v, 0)
+}
+
+// DecimalFromFloat 将 float64 按最短十进制表示转换，如 0.029 -> 0.029（而不是 0.0289999...）
+func DecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Decimal{}
+	}
+	return d
+}
+
+// ParseDecimal 解析十进制字符串，如 "1.0845"、"-0.5"、"2.9e-2"
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := len(fracPart) - exp
+	if scale < 0 {
+		coef.Mul(coef, bigPow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal 解析常量，失败时 panic
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) value() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale 提升到更大的小数位数（不损失精度）
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := new(big.Int).Set(d.value())
+	if scale > d.scale {
+		v.Mul(v, bigPow10(int(scale-d.scale)))
+	}
+	return v
+}
+
+// Add 加法
+func (d Decimal) Add(o Decimal) Decimal {
+	scale := maxScale(d.scale, o.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+// Sub 减法
+func (d Decimal) Sub(o Decimal) Decimal {
+	scale := maxScale(d.scale, o.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), o.rescale(scale)), scale: scale}
+}
+
+// Mul 乘法（精确）
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.value(), o.value()), scale: d.scale + o.scale}
+}
+
+// Div 除法，结果保留 scale 位小数并按 mode 舍入
+func (d Decimal) Div(o Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if o.Sign() == 0 {
+		return Decimal{}, errors.New("money: 除数为零")
+	}
+	// d/o = (dc × 10^-ds) / (oc × 10^-os)，结果系数 = dc × 10^(scale+os-ds) / oc
+	num := new(big.Int).Set(d.value())
+	den := new(big.Int).Set(o.value())
+	shift := int(scale) + int(o.scale) - int(d.scale)
+	if shift >= 0 {
+		num.Mul(num, bigPow10(shift))
+	} else {
+		den.Mul(den, bigPow10(-shift))
+	}
+	return Decimal{coef: roundQuo(num, den, mode), scale: scale}, nil
+}
+
+// Round 保留 scale 位小数
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return Decimal{coef: d.rescale(scale), scale: scale}
+	}
+	return Decimal{coef: roundQuo(d.value(), bigPow10(int(d.scale-scale)), mode), scale: scale}
+}
+
+// Neg 取反
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+// Sign 符号：-1、0、1
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// IsZero 是否为零
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp 比较大小
+func (d Decimal) Cmp(o Decimal) int {
+	scale := maxScale(d.scale, o.scale)
+	return d.rescale(scale).Cmp(o.rescale(scale))
+}
+
+// Float64 转换为 float64（仅用于展示或兼容旧接口）
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.value(), bigPow10(int(d.scale))).Float64()
+	return f
+}
+
+// String 十进制字符串，保留原有小数位数，如 "0.0290"
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.value()).String()
+	if d.scale > 0 {
+		if len(s) <= int(d.scale) {
+			s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON 输出为 JSON 数字，保持与原 float64 字段兼容
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON 接受 JSON 数字或字符串
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*d = Decimal{}
+		return nil
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value 实现 driver.Valuer，以字符串写入 numeric/decimal 列，不经过浮点
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan 实现 sql.Scanner
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		return d.UnmarshalJSON(v)
+	case string:
+		return d.UnmarshalJSON([]byte(v))
+	case int64:
+		*d = DecimalFromInt(v)
+		return nil
+	case float64:
+		*d = DecimalFromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("money: 无法将 %T 转换为 Decimal", src)
+	}
+}
+
+// roundQuo 计算 num/den 并按 mode 舍入为整数
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	if den.Sign() < 0 {
+		num = new(big.Int).Neg(num)
+		den = new(big.Int).Neg(den)
+	}
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// 余数与被除数同号，away 为远离零方向的进位
+	away := big.NewInt(int64(num.Sign()))
+	switch mode {
+	case RoundDown:
+		return q
+	case RoundUp:
+		return q.Add(q, away)
+	}
+
+	// 比较 2|r| 与 den 判断是否过半
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	switch c := twice.Cmp(den); {
+	case c > 0:
+		q.Add(q, away)
+	case c == 0:
+		if mode == RoundHalfUp || q.Bit(0) == 1 {
+			q.Add(q, away)
+		}
+	}
+	return q
+}
+
+func bigPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func maxScale(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,250 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrCurrencyMismatch 不同币种的金额不能直接运算
+	ErrCurrencyMismatch = errors.New("money: 币种不一致")
+	// ErrOverflow 运算结果超出 int64 范围
+	ErrOverflow = errors.New("money: 金额溢出")
+	// ErrPrecision 金额的小数位数超过币种允许的精度
+	ErrPrecision = errors.New("money: 金额精度超过币种小数位数")
+)
+
+// Money 金额，Amount 为 ISO 4217 最小货币单位（USD 为分，JPY 为日元，KWD 为 1/1000 第纳尔）
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New 创建金额
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: NormalizeCurrency(currency)}
+}
+
+// FromDecimal 将主单位金额（如 12.345 USD）转换为最小单位，按 mode 舍入
+func FromDecimal(major Decimal, currency string, mode RoundingMode) (Money, error) {
+	currency = NormalizeCurrency(currency)
+	minor := major.Round(int32(Decimals(currency)), mode).value()
+	if !minor.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: minor.Int64(), Currency: currency}, nil
+}
+
+// ParseMajor 解析主单位金额字符串（如 "12.34"），小数位数超过币种精度时报错
+func ParseMajor(s, currency string) (Money, error) {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return Money{}, err
+	}
+	if d.scale > int32(Decimals(currency)) && d.Cmp(d.Round(int32(Decimals(currency)), RoundDown)) != 0 {
+		return Money{}, fmt.Errorf("%w: %s %s", ErrPrecision, s, NormalizeCurrency(currency))
+	}
+	return FromDecimal(d, currency, RoundDown)
+}
+
+// Decimals 币种小数位数
+func (m Money) Decimals() int {
+	return Decimals(m.Currency)
+}
+
+// Major 主单位金额，如 1234 USD -> 12.34
+func (m Money) Major() Decimal {
+	return NewDecimal(m.Amount, int32(m.Decimals()))
+}
+
+// FormatMajor 主单位金额字符串，按币种小数位数输出，如 "12.34"、"1500"（JPY）
+// 用于 PayPal、支付宝等以主单位报送金额的渠道
+func (m Money) FormatMajor() string {
+	return m.Major().String()
+}
+
+// String 如 "12.34 USD"
+func (m Money) String() string {
+	return m.FormatMajor() + " " + m.Currency
+}
+
+// IsZero 是否为零
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative 是否为负
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Neg 取反
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// SameCurrency 币种是否相同
+func (m Money) SameCurrency(o Money) bool {
+	return NormalizeCurrency(m.Currency) == NormalizeCurrency(o.Currency)
+}
+
+// Add 加法，币种不同时报错
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub 减法，币种不同时报错
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+// Cmp 比较大小，币种不同时报错
+func (m Money) Cmp(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, fmt.Errorf("%w: %s <> %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Mul 乘以比例（如费率 0.029），结果按 mode 舍入到最小单位
+func (m Money) Mul(rate Decimal, mode RoundingMode) (Money, error) {
+	num := new(big.Int).Mul(big.NewInt(m.Amount), rate.value())
+	result := roundQuo(num, bigPow10(int(rate.scale)), mode)
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: result.Int64(), Currency: m.Currency}, nil
+}
+
+// Convert 按汇率换算到目标币种，rate 为 1 单位源币种（主单位）兑换的目标币种数量，
+// 自动处理两种币种小数位数的差异，如 1000 JPY × 0.0067 -> 670 USD 分
+func (m Money) Convert(rate Decimal, to string, mode RoundingMode) (Money, error) {
+	to = NormalizeCurrency(to)
+	// 目标最小单位 = 源最小单位 × rate × 10^(目标小数位 - 源小数位)
+	num := new(big.Int).Mul(big.NewInt(m.Amount), rate.value())
+	den := bigPow10(int(rate.scale))
+	if shift := Decimals(to) - m.Decimals(); shift > 0 {
+		num.Mul(num, bigPow10(shift))
+	} else if shift < 0 {
+		den.Mul(den, bigPow10(-shift))
+	}
+	result := roundQuo(num, den, mode)
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: result.Int64(), Currency: to}, nil
+}
+
+// Allocate 按比例分配金额，分配结果之和严格等于原金额
+//
+// 先按比例向零截断，剩余的最小单位按余数从大到小依次补一（余数相同按参数顺序），
+// 例如 100 按 1:1:1 分配为 34、33、33。
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, errors.New("money: 分配比例不能为空")
+	}
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, errors.New("money: 分配比例不能为负")
+		}
+		total.Add(total, big.NewInt(r))
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("money: 分配比例之和不能为零")
+	}
+
+	amount := big.NewInt(m.Amount)
+	parts := make([]Money, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	allocated := int64(0)
+	for i, r := range ratios {
+		q, rem := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(r)), total, new(big.Int))
+		parts[i] = Money{Amount: q.Int64(), Currency: m.Currency}
+		remainders[i] = rem.Abs(rem)
+		allocated += q.Int64()
+	}
+
+	// 剩余的最小单位（与原金额同号）
+	left := m.Amount - allocated
+	step := int64(1)
+	if left < 0 {
+		step, left = -1, -left
+	}
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	// 按余数降序的稳定排序（插入排序，分配份数通常很少）
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && remainders[order[j]].Cmp(remainders[order[j-1]]) > 0; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	for i := int64(0); i < left; i++ {
+		parts[order[i]].Amount += step
+	}
+	return parts, nil
+}
+
+// Split 平均拆分为 n 份，差额分配给前面的份额，如 100 拆 3 份为 34、33、33
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("money: 拆分份数必须大于零")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Value 实现 driver.Valuer，以 JSON 写入 jsonb 列
+func (m Money) Value() (driver.Value, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan 实现 sql.Scanner
+func (m *Money) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("money: 无法将 %T 转换为 Money", src)
+	}
+	var out Money
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	out.Currency = strings.ToUpper(out.Currency)
+	*m = out
+	return nil
+}
@@ -0,0 +1,182 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimals(t *testing.T) {
+	assert.Equal(t, 2, Decimals("usd"))
+	assert.Equal(t, 0, Decimals("JPY"))
+	assert.Equal(t, 3, Decimals("KWD"))
+	assert.Equal(t, 2, Decimals("XYZ"))
+}
+
+func TestParseDecimal(t *testing.T) {
+	cases := map[string]string{
+		"1.0845":  "1.0845",
+		"-0.5":    "-0.5",
+		"2.9e-2":  "0.029",
+		"12":      "12",
+		"1.5e2":   "150",
+		".25":     "0.25",
+		"+0.0290": "0.0290",
+	}
+	for in, want := range cases {
+		d, err := ParseDecimal(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, d.String(), in)
+	}
+
+	_, err := ParseDecimal("1.2.3")
+	assert.ErrorIs(t, err, ErrInvalidDecimal)
+	assert.Equal(t, "0.029", DecimalFromFloat(0.029).String())
+}
+
+func TestRoundingModes(t *testing.T) {
+	cases := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{"2.5", RoundHalfEven, "2"},
+		{"3.5", RoundHalfEven, "4"},
+		{"-2.5", RoundHalfEven, "-2"},
+		{"2.5", RoundHalfUp, "3"},
+		{"-2.5", RoundHalfUp, "-3"},
+		{"2.1", RoundUp, "3"},
+		{"-2.1", RoundUp, "-3"},
+		{"2.9", RoundDown, "2"},
+		{"-2.9", RoundDown, "-2"},
+		{"2.51", RoundHalfEven, "3"},
+	}
+	for _, c := range cases {
+		got := MustParseDecimal(c.value).Round(0, c.mode)
+		assert.Equal(t, c.want, got.String(), "%s mode %d", c.value, c.mode)
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	d, err := DecimalFromInt(1).Div(DecimalFromInt(3), 6, RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, "0.333333", d.String())
+
+	_, err = DecimalFromInt(1).Div(Decimal{}, 2, RoundHalfEven)
+	assert.Error(t, err)
+}
+
+func TestMoneyMul(t *testing.T) {
+	fee, err := New(12345, "USD").Mul(MustParseDecimal("0.029"), RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, int64(358), fee.Amount) // 358.005 -> 358
+
+	fee, err = New(50, "USD").Mul(MustParseDecimal("0.03"), RoundHalfUp)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), fee.Amount) // 1.5 -> 2
+
+	fee, err = New(50, "USD").Mul(MustParseDecimal("0.03"), RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), fee.Amount) // 1.5 -> 2（偶数）
+}
+
+func TestMoneyConvert(t *testing.T) {
+	// 10,000 JPY × 0.0067 = 67.00 USD
+	usd, err := New(10000, "JPY").Convert(MustParseDecimal("0.0067"), "usd", RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, New(6700, "USD"), usd)
+
+	// 12.34 USD × 149.5 = 1844.83 JPY -> 1845
+	jpy, err := New(1234, "USD").Convert(MustParseDecimal("149.5"), "JPY", RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1845), jpy.Amount)
+
+	// 1.000 KWD × 3.25 = 3.25 USD
+	kwdToUSD, err := New(1000, "KWD").Convert(MustParseDecimal("3.25"), "USD", RoundHalfEven)
+	require.NoError(t, err)
+	assert.Equal(t, int64(325), kwdToUSD.Amount)
+}
+
+func TestMoneyAddSub(t *testing.T) {
+	sum, err := New(100, "USD").Add(New(250, "usd"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(350), sum.Amount)
+
+	_, err = New(100, "USD").Add(New(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	diff, err := New(100, "USD").Sub(New(250, "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(-150), diff.Amount)
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	parts, err := New(100, "USD").Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{34, 33, 33}, amounts(parts))
+
+	parts, err = New(1000, "USD").Allocate(70, 20, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{700, 200, 100}, amounts(parts))
+
+	// 余数最大的份额优先获得剩余的分：5 × 1/6 = 0.83、5 × 2/6 = 1.67、5 × 3/6 = 2.5
+	parts, err = New(5, "USD").Allocate(1, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 2}, amounts(parts))
+
+	parts, err = New(-100, "USD").Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-34, -33, -33}, amounts(parts))
+
+	_, err = New(100, "USD").Allocate(0, 0)
+	assert.Error(t, err)
+}
+
+func TestParseMajor(t *testing.T) {
+	m, err := ParseMajor("12.34", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), m.Amount)
+
+	_, err = ParseMajor("12.345", "USD")
+	assert.ErrorIs(t, err, ErrPrecision)
+
+	m, err = ParseMajor("1500", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500), m.Amount)
+	assert.Equal(t, "1500", m.FormatMajor())
+	assert.Equal(t, "1.500 KWD", New(1500, "KWD").String())
+}
+
+func TestCodecs(t *testing.T) {
+	var policy struct {
+		Rate Decimal `json:"rate"`
+		Fee  Money   `json:"fee"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"rate":0.029,"fee":{"amount":30,"currency":"usd"}}`), &policy))
+	assert.Equal(t, "0.029", policy.Rate.String())
+
+	out, err := json.Marshal(policy.Rate)
+	require.NoError(t, err)
+	assert.Equal(t, "0.029", string(out))
+
+	var d Decimal
+	require.NoError(t, d.Scan([]byte("0.0290")))
+	assert.Equal(t, 0, d.Cmp(MustParseDecimal("0.029")))
+	v, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "0.0290", v)
+
+	var m Money
+	require.NoError(t, m.Scan(`{"amount":1234,"currency":"eur"}`))
+	assert.Equal(t, New(1234, "EUR"), m)
+}
+
+func amounts(parts []Money) []int64 {
+	out := make([]int64, len(parts))
+	for i, p := range parts {
+		out[i] = p.Amount
+	}
+	return out
+}
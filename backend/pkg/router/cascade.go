@@ -0,0 +1,27 @@
+package router
+
+import "github.com/payment-platform/pkg/decline"
+
+// CascadeConfig 级联重试配置（同一笔支付在软拒绝时切换到次优渠道）
+type CascadeConfig struct {
+	Enabled     bool
+	MaxAttempts int // 含首次尝试的最大渠道数
+}
+
+// DefaultCascadeConfig 默认级联配置
+func DefaultCascadeConfig() CascadeConfig {
+	return CascadeConfig{Enabled: true, MaxAttempts: 3}
+}
+
+// IsCascadeEligible 判断渠道错误是否允许级联到下一个渠道
+//
+// 仅明确的软拒绝可以重试；硬拒绝、超时等结果未知的错误以及无法识别的错误一律不重试。
+// 渠道错误未携带归一化拒绝原因时按错误信息识别。
+func IsCascadeEligible(reason string) bool {
+	return IsDeclineCascadeEligible(decline.ClassifyMessage("", reason))
+}
+
+// IsDeclineCascadeEligible 判断归一化拒绝原因是否允许级联到下一个渠道
+func IsDeclineCascadeEligible(d *decline.Decline) bool {
+	return d != nil && d.Type == decline.TypeSoft && d.Retryable
+}
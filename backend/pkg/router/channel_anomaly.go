@@ -0,0 +1,100 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// channelAnomalyKey 渠道异常信号（Hash: field = channel 或 channel:country）
+const channelAnomalyKey = "payment:router:channel_anomalies"
+
+// 异常严重级别
+const (
+	AnomalySeverityWarning  = "warning"
+	AnomalySeverityCritical = "critical"
+)
+
+// ChannelAnomaly 渠道异常信号（由分析服务检测后写入，路由时避开严重异常的渠道）
+type ChannelAnomaly struct {
+	Channel    string    `json:"channel"`
+	Country    string    `json:"country,omitempty"` // 为空表示渠道整体异常
+	Metric     string    `json:"metric"`            // auth_rate, latency, refund_rate, volume
+	Severity   string    `json:"severity"`          // warning, critical
+	Reason     string    `json:"reason"`
+	DetectedAt time.Time `json:"detected_at"`
+	ExpiresAt  time.Time `json:"expires_at"` // 过期后自动失效，避免检测服务故障时渠道被永久屏蔽
+}
+
+func channelAnomalyField(channel, country string) string {
+	if country == "" {
+		return channel
+	}
+	return channel + ":" + strings.ToUpper(country)
+}
+
+// PublishChannelAnomaly 发布渠道异常信号
+func PublishChannelAnomaly(ctx context.Context, redisClient *redis.Client, anomaly *ChannelAnomaly) error {
+	if redisClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(anomaly)
+	if err != nil {
+		return fmt.Errorf("序列化渠道异常失败: %w", err)
+	}
+	return redisClient.HSet(ctx, channelAnomalyKey, channelAnomalyField(anomaly.Channel, anomaly.Country), data).Err()
+}
+
+// ClearChannelAnomaly 清除渠道异常信号
+func ClearChannelAnomaly(ctx context.Context, redisClient *redis.Client, channel, country string) error {
+	if redisClient == nil {
+		return nil
+	}
+	return redisClient.HDel(ctx, channelAnomalyKey, channelAnomalyField(channel, country)).Err()
+}
+
+// LoadChannelAnomalies 加载未过期的渠道异常信号
+func LoadChannelAnomalies(ctx context.Context, redisClient *redis.Client) ([]*ChannelAnomaly, error) {
+	if redisClient == nil {
+		return nil, nil
+	}
+	values, err := redisClient.HGetAll(ctx, channelAnomalyKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	anomalies := make([]*ChannelAnomaly, 0, len(values))
+	for _, value := range values {
+		var anomaly ChannelAnomaly
+		if err := json.Unmarshal([]byte(value), &anomaly); err != nil {
+			continue
+		}
+		if !anomaly.ExpiresAt.IsZero() && now.After(anomaly.ExpiresAt) {
+			continue
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+	return anomalies, nil
+}
+
+// degradedChannels 返回对当前请求处于严重异常的渠道
+func degradedChannels(anomalies []*ChannelAnomaly, country string) []string {
+	var channels []string
+	for _, anomaly := range anomalies {
+		if anomaly.Severity != AnomalySeverityCritical {
+			continue
+		}
+		if anomaly.Country != "" && !strings.EqualFold(anomaly.Country, country) {
+			continue
+		}
+		if !contains(channels, anomaly.Channel) {
+			channels = append(channels, anomaly.Channel)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/payment-platform/pkg/logger"
+)
+
+func TestDegradedChannels(t *testing.T) {
+	anomalies := []*ChannelAnomaly{
+		{Channel: "stripe", Severity: AnomalySeverityCritical},
+		{Channel: "paypal", Country: "US", Severity: AnomalySeverityCritical},
+		{Channel: "alipay", Severity: AnomalySeverityWarning},
+		{Channel: "stripe", Country: "GB", Severity: AnomalySeverityCritical},
+	}
+
+	assert.ElementsMatch(t, []string{"stripe", "paypal"}, degradedChannels(anomalies, "us"))
+	assert.Equal(t, []string{"stripe"}, degradedChannels(anomalies, "CN"))
+	assert.Empty(t, degradedChannels(nil, "US"))
+}
+
+func TestRouteSkipsExcludedChannels(t *testing.T) {
+	logger.Log = zap.NewNop()
+	channels := NewConfigManager(nil).getDefaultChannels()
+	r := NewPaymentRouter()
+	r.RegisterStrategy(NewSuccessRateStrategy(channels))
+
+	req := &RoutingRequest{Amount: 10000, Currency: "CNY", Country: "CN", PayMethod: "wallet"}
+	result, err := r.Route(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "alipay", result.Channel)
+
+	req.ExcludeChannels = []string{"alipay"}
+	result, err = r.Route(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "wechat", result.Channel)
+}
@@ -0,0 +1,249 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/redis/go-redis/v9"
+)
+
+// 渠道健康状态（由 channel-adapter 根据熔断器状态、错误类型和超时计算后上报）
+const (
+	ChannelHealthHealthy  = "healthy"
+	ChannelHealthDegraded = "degraded"
+	ChannelHealthDown     = "down"
+)
+
+const (
+	// channelHealthKey 各实例上报的健康状态（Hash: field = channel:instance）
+	channelHealthKey = "payment:router:channel_health"
+	// channelHealthOverrideKey 运营手动覆盖（Hash: field = channel）
+	channelHealthOverrideKey = "payment:router:channel_health_override"
+)
+
+// ChannelHealthReport 单个 channel-adapter 实例上报的渠道健康状态
+type ChannelHealthReport struct {
+	Channel      string    `json:"channel"`
+	Instance     string    `json:"instance"`
+	State        string    `json:"state"`   // healthy, degraded, down
+	Probing      bool      `json:"probing"` // 熔断器半开，允许少量探测流量
+	BreakerState string    `json:"breaker_state"`
+	Requests     int64     `json:"requests"`
+	ErrorRate    float64   `json:"error_rate"`   // 基础设施类错误占比（不含业务拒绝）
+	TimeoutRate  float64   `json:"timeout_rate"` // 超时占比
+	Reason       string    `json:"reason,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+	ExpiresAt    time.Time `json:"expires_at"` // 实例停止上报后自动失效
+}
+
+// ChannelHealthOverride 运营手动覆盖的渠道健康状态
+type ChannelHealthOverride struct {
+	Channel   string     `json:"channel"`
+	State     string     `json:"state"`
+	Reason    string     `json:"reason"`
+	Operator  string     `json:"operator"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 为空表示手动解除前一直生效
+}
+
+// ChannelHealth 渠道的有效健康状态（覆盖优先，否则取各实例中最差的状态）
+type ChannelHealth struct {
+	Channel    string                 `json:"channel"`
+	State      string                 `json:"state"`
+	Probing    bool                   `json:"probing"`
+	Reason     string                 `json:"reason,omitempty"`
+	Overridden bool                   `json:"overridden"`
+	Override   *ChannelHealthOverride `json:"override,omitempty"`
+	Reports    []*ChannelHealthReport `json:"reports,omitempty"`
+}
+
+// ValidChannelHealthState 校验健康状态取值
+func ValidChannelHealthState(state string) bool {
+	switch state {
+	case ChannelHealthHealthy, ChannelHealthDegraded, ChannelHealthDown:
+		return true
+	}
+	return false
+}
+
+func healthSeverity(state string) int {
+	switch state {
+	case ChannelHealthDown:
+		return 2
+	case ChannelHealthDegraded:
+		return 1
+	}
+	return 0
+}
+
+// PublishChannelHealth 上报实例的渠道健康状态
+func PublishChannelHealth(ctx context.Context, redisClient *redis.Client, report *ChannelHealthReport) error {
+	if redisClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("序列化渠道健康状态失败: %w", err)
+	}
+	return redisClient.HSet(ctx, channelHealthKey, report.Channel+":"+report.Instance, data).Err()
+}
+
+// SetChannelHealthOverride 设置渠道健康状态覆盖
+func SetChannelHealthOverride(ctx context.Context, redisClient *redis.Client, override *ChannelHealthOverride) error {
+	if redisClient == nil {
+		return fmt.Errorf("Redis 未配置")
+	}
+	if !ValidChannelHealthState(override.State) {
+		return fmt.Errorf("无效的健康状态: %s", override.State)
+	}
+	data, err := json.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("序列化渠道健康覆盖失败: %w", err)
+	}
+	return redisClient.HSet(ctx, channelHealthOverrideKey, override.Channel, data).Err()
+}
+
+// ClearChannelHealthOverride 解除渠道健康状态覆盖
+func ClearChannelHealthOverride(ctx context.Context, redisClient *redis.Client, channel string) error {
+	if redisClient == nil {
+		return nil
+	}
+	return redisClient.HDel(ctx, channelHealthOverrideKey, channel).Err()
+}
+
+// LoadChannelHealth 加载各渠道的有效健康状态（过期的上报和覆盖会被清理）
+func LoadChannelHealth(ctx context.Context, redisClient *redis.Client) (map[string]*ChannelHealth, error) {
+	result := make(map[string]*ChannelHealth)
+	if redisClient == nil {
+		return result, nil
+	}
+
+	now := time.Now()
+	reports, err := redisClient.HGetAll(ctx, channelHealthKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var expired []string
+	for field, value := range reports {
+		var report ChannelHealthReport
+		if err := json.Unmarshal([]byte(value), &report); err != nil || now.After(report.ExpiresAt) {
+			expired = append(expired, field)
+			continue
+		}
+		health, ok := result[report.Channel]
+		if !ok {
+			health = &ChannelHealth{Channel: report.Channel, State: ChannelHealthHealthy, Probing: true}
+			result[report.Channel] = health
+		}
+		health.Reports = append(health.Reports, &report)
+		mergeHealthReport(health, &report)
+	}
+	if len(expired) > 0 {
+		redisClient.HDel(ctx, channelHealthKey, expired...)
+	}
+	for _, health := range result {
+		if health.State != ChannelHealthDown {
+			health.Probing = false
+		}
+	}
+
+	overrides, err := redisClient.HGetAll(ctx, channelHealthOverrideKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	for channel, value := range overrides {
+		var override ChannelHealthOverride
+		if err := json.Unmarshal([]byte(value), &override); err != nil {
+			continue
+		}
+		if override.ExpiresAt != nil && now.After(*override.ExpiresAt) {
+			redisClient.HDel(ctx, channelHealthOverrideKey, channel)
+			continue
+		}
+		health, ok := result[channel]
+		if !ok {
+			health = &ChannelHealth{Channel: channel}
+			result[channel] = health
+		}
+		health.State = override.State
+		health.Probing = false
+		health.Reason = fmt.Sprintf("运营手动设置: %s", override.Reason)
+		health.Overridden = true
+		health.Override = &override
+	}
+	return result, nil
+}
+
+// mergeHealthReport 多实例合并：取最差状态；渠道 down 时仅当所有 down 实例都处于半开才允许探测
+func mergeHealthReport(health *ChannelHealth, report *ChannelHealthReport) {
+	if !ValidChannelHealthState(report.State) {
+		return
+	}
+	if report.State == ChannelHealthDown && !report.Probing {
+		health.Probing = false
+	}
+	if healthSeverity(report.State) > healthSeverity(health.State) {
+		health.State = report.State
+		health.Reason = report.Reason
+	}
+}
+
+// unhealthyChannels 按健康状态划分需要摘除流量的渠道
+//
+// down 的渠道直接摘除（半开探测期按 probeRatio 放行少量流量）；degraded 的渠道在有其他可用渠道时摘除。
+func unhealthyChannels(healths map[string]*ChannelHealth, probe func() bool) (down, degraded []string) {
+	for channel, health := range healths {
+		switch health.State {
+		case ChannelHealthDown:
+			if health.Probing && probe() {
+				continue
+			}
+			down = append(down, channel)
+		case ChannelHealthDegraded:
+			degraded = append(degraded, channel)
+		}
+	}
+	return down, degraded
+}
+
+// IsInfrastructureError 判断渠道错误是否为基础设施类错误（计入熔断），业务拒绝不计入
+func IsInfrastructureError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// 渠道返回了归一化拒绝原因时以其为准：发卡行拒绝不是渠道故障
+	if d, ok := decline.FromError(err); ok {
+		return d.Category == decline.CategoryChannelUnavailable || d.Category == decline.CategoryTimeout
+	}
+	if IsTimeoutError(err) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"connection refused", "connection reset", "no such host", "eof", "broken pipe",
+		"service unavailable", "bad gateway", "internal server error", "gateway timeout",
+		"status 500", "status 502", "status 503", "status 504", "rate_limit", "too many requests",
+		"api_connection_error", "api_error",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTimeoutError 判断渠道错误是否为超时
+func IsTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded")
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/logger"
+)
+
+func TestMergeHealthReport(t *testing.T) {
+	health := &ChannelHealth{Channel: "stripe", State: ChannelHealthHealthy, Probing: true}
+	mergeHealthReport(health, &ChannelHealthReport{Channel: "stripe", State: ChannelHealthDegraded, Reason: "超时率 12%"})
+	mergeHealthReport(health, &ChannelHealthReport{Channel: "stripe", State: ChannelHealthDown, Probing: true, Reason: "熔断器半开"})
+	assert.Equal(t, ChannelHealthDown, health.State)
+	assert.True(t, health.Probing)
+
+	// 任一实例熔断器完全打开时不允许探测
+	mergeHealthReport(health, &ChannelHealthReport{Channel: "stripe", State: ChannelHealthDown, Reason: "熔断器打开"})
+	assert.False(t, health.Probing)
+}
+
+func TestIsInfrastructureError(t *testing.T) {
+	assert.True(t, IsInfrastructureError(context.DeadlineExceeded))
+	assert.True(t, IsInfrastructureError(errors.New("dial tcp: connection refused")))
+	assert.True(t, IsTimeoutError(errors.New("Client.Timeout exceeded while awaiting headers")))
+	assert.False(t, IsInfrastructureError(errors.New("card_declined: insufficient_funds")))
+	assert.False(t, IsInfrastructureError(nil))
+
+	// 归一化拒绝原因优先于错误信息：发卡行拒绝即使携带 HTTP 5xx 描述也不计入熔断
+	issuerDown := decline.Wrap(decline.New(decline.CategoryIssuerUnavailable, "stripe", "issuer_not_available", ""), errors.New("status 503"))
+	assert.False(t, IsInfrastructureError(issuerDown))
+	channelDown := decline.Wrap(decline.New(decline.CategoryChannelUnavailable, "stripe", "api_error", ""), errors.New("api_error"))
+	assert.True(t, IsInfrastructureError(channelDown))
+}
+
+func TestSelectChannelDrainsUnhealthyChannels(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	s := NewRouterService(nil)
+	require.NoError(t, s.Initialize(context.Background(), "cost"))
+	req := &RoutingRequest{Amount: 10000, Currency: "USD", Country: "US"}
+
+	result, err := s.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	preferred := result.Channel
+
+	setHealth := func(healths map[string]*ChannelHealth, probe bool) {
+		s.health = healths
+		s.healthLoaded = time.Now()
+		s.probe = func() bool { return probe }
+	}
+
+	// 熔断中的渠道被摘除
+	setHealth(map[string]*ChannelHealth{preferred: {Channel: preferred, State: ChannelHealthDown}}, false)
+	result, err = s.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	assert.NotEqual(t, preferred, result.Channel)
+
+	// 半开探测命中时放行
+	setHealth(map[string]*ChannelHealth{preferred: {Channel: preferred, State: ChannelHealthDown, Probing: true}}, true)
+	result, err = s.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, preferred, result.Channel)
+
+	// 规则只允许该渠道时，降级渠道仍可使用；运营手动下线则不可用
+	s.SetRuleProvider(staticRules{{Name: "only-preferred", Channels: []string{preferred}}})
+	setHealth(map[string]*ChannelHealth{preferred: {Channel: preferred, State: ChannelHealthDegraded}}, false)
+	result, err = s.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, preferred, result.Channel)
+	assert.Equal(t, "only-preferred", result.Rule)
+
+	setHealth(map[string]*ChannelHealth{preferred: {Channel: preferred, State: ChannelHealthDown, Overridden: true}}, true)
+	explanation := s.Explain(context.Background(), req)
+	require.NotNil(t, explanation.Result)
+	assert.NotEqual(t, preferred, explanation.Result.Channel)
+	assert.Equal(t, []string{preferred}, explanation.DisabledChannels)
+}
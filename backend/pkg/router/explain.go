@@ -0,0 +1,265 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RuleEvaluation 单条规则的求值结果
+type RuleEvaluation struct {
+	RuleID     string   `json:"rule_id"`
+	Name       string   `json:"name"`
+	Priority   int      `json:"priority"`
+	MerchantID string   `json:"merchant_id,omitempty"`
+	Channels   []string `json:"channels"`
+	Matched    bool     `json:"matched"`
+	Applied    bool     `json:"applied"`          // 是否由该规则限定了最终候选渠道
+	Reason     string   `json:"reason,omitempty"` // 未命中或命中但未采用的原因
+}
+
+// CandidateEvaluation 候选渠道评估
+type CandidateEvaluation struct {
+	Channel      string  `json:"channel"`
+	Eligible     bool    `json:"eligible"`
+	Reason       string  `json:"reason,omitempty"` // 不可用原因
+	SuccessRate  float64 `json:"success_rate"`
+	AvgLatency   float64 `json:"avg_latency_ms"`
+	Attempts     int64   `json:"attempts"`
+	StatsLevel   string  `json:"stats_level"`
+	EstimatedFee int64   `json:"estimated_fee"`
+	Score        float64 `json:"score"` // 多目标综合评分（仅可用渠道）
+}
+
+// RoutingExplanation 路由决策说明
+type RoutingExplanation struct {
+	Request          *RoutingRequest        `json:"request"`
+	StrategyMode     string                 `json:"strategy_mode"`
+	Rules            []*RuleEvaluation      `json:"rules"`
+	AllowedChannels  []string               `json:"allowed_channels,omitempty"`
+	DegradedChannels []string               `json:"degraded_channels,omitempty"` // 严重异常或健康状态 degraded，有其他渠道时避开
+	DownChannels     []string               `json:"down_channels,omitempty"`     // 熔断中的渠道，摘除流量
+	DisabledChannels []string               `json:"disabled_channels,omitempty"` // 运营手动设置为 down 的渠道
+	Candidates       []*CandidateEvaluation `json:"candidates"`
+	Result           *RoutingResult         `json:"result,omitempty"`
+	Error            string                 `json:"error,omitempty"`
+}
+
+// routeOptions 单次路由的执行选项
+type routeOptions struct {
+	rules         []*RoutingRule
+	skipAnomalies bool                // 模拟历史支付时不使用当前异常信号和健康状态
+	trace         *RoutingExplanation // 非空时记录决策过程
+}
+
+// Explain 解释当前配置下请求会被路由到哪个渠道及原因
+func (s *RouterService) Explain(ctx context.Context, req *RoutingRequest) *RoutingExplanation {
+	trace := &RoutingExplanation{Request: req, StrategyMode: s.strategyMode}
+	s.route(ctx, req, &routeOptions{rules: s.loadRules(ctx), trace: trace})
+	return trace
+}
+
+// Simulate 使用指定规则模拟路由（what-if），rules 为 nil 时使用当前规则；不考虑当前渠道异常信号和健康状态
+func (s *RouterService) Simulate(ctx context.Context, req *RoutingRequest, rules []*RoutingRule) (*RoutingResult, error) {
+	if rules == nil {
+		rules = s.loadRules(ctx)
+	} else {
+		rules = append([]*RoutingRule{}, rules...)
+		SortRules(rules)
+	}
+	return s.route(ctx, req, &routeOptions{rules: rules, skipAnomalies: true})
+}
+
+// route 统一路由入口：先按规则限定候选渠道，再由策略在候选渠道内选择
+//
+// 规则按顺序求值，命中的规则若在其渠道范围内无可用渠道，继续尝试下一条命中规则；
+// 所有命中规则都无可用渠道（或没有规则命中）时，在全部渠道内路由。
+func (s *RouterService) route(ctx context.Context, req *RoutingRequest, opts *routeOptions) (*RoutingResult, error) {
+	trace := opts.trace
+
+	var ex exclusions
+	if !opts.skipAnomalies {
+		ex = s.exclusions(ctx, req.Country)
+	}
+	if len(ex.disabled) > 0 {
+		// 运营手动下线的渠道始终不参与路由
+		filtered := *req
+		filtered.ExcludeChannels = append(append([]string{}, req.ExcludeChannels...), ex.disabled...)
+		req = &filtered
+	}
+
+	var matched []*RuleEvaluation
+	var matchedRules []*RoutingRule
+	for _, rule := range opts.rules {
+		ok, reason := rule.Match(req)
+		eval := &RuleEvaluation{
+			RuleID:     rule.ID,
+			Name:       rule.Name,
+			Priority:   rule.Priority,
+			MerchantID: rule.MerchantID,
+			Channels:   rule.Channels,
+			Matched:    ok,
+			Reason:     reason,
+		}
+		if trace != nil {
+			trace.Rules = append(trace.Rules, eval)
+		}
+		if ok {
+			matched = append(matched, eval)
+			matchedRules = append(matchedRules, rule)
+		}
+	}
+
+	for i, rule := range matchedRules {
+		ruleReq := *req
+		ruleReq.AllowedChannels = rule.Channels
+		if len(req.AllowedChannels) > 0 {
+			ruleReq.AllowedChannels = intersect(req.AllowedChannels, rule.Channels)
+			if len(ruleReq.AllowedChannels) == 0 {
+				matched[i].Reason = "规则渠道与请求限定的渠道无交集"
+				continue
+			}
+		}
+
+		result, err := s.routeExcluding(ctx, &ruleReq, ex)
+		if err != nil {
+			matched[i].Reason = "规则渠道范围内无可用渠道"
+			logger.Warn("路由规则范围内无可用渠道，尝试下一条规则",
+				zap.String("rule", describeRule(rule)),
+				zap.Strings("channels", rule.Channels),
+				zap.Error(err))
+			continue
+		}
+
+		matched[i].Applied = true
+		result.Rule = rule.Name
+		if trace != nil {
+			s.traceResult(trace, &ruleReq, ex, result, nil)
+		}
+		return result, nil
+	}
+
+	result, err := s.routeExcluding(ctx, req, ex)
+	if trace != nil {
+		s.traceResult(trace, req, ex, result, err)
+	}
+	return result, err
+}
+
+// exclusions 按渠道健康状态和异常信号划分的避让范围
+type exclusions struct {
+	disabled []string // 运营手动设置为 down，始终排除
+	down     []string // 熔断中（半开探测命中的请求除外）
+	degraded []string // 健康状态 degraded 或存在严重异常
+}
+
+// exclusions 汇总当前需要避让的渠道
+func (s *RouterService) exclusions(ctx context.Context, country string) exclusions {
+	var ex exclusions
+	healths := s.channelHealth(ctx)
+	for channel, health := range healths {
+		if health.Overridden && health.State == ChannelHealthDown {
+			ex.disabled = append(ex.disabled, channel)
+		}
+	}
+	down, degraded := unhealthyChannels(healths, s.probe)
+	for _, channel := range down {
+		if !contains(ex.disabled, channel) {
+			ex.down = append(ex.down, channel)
+		}
+	}
+	ex.degraded = degraded
+	for _, channel := range degradedChannels(s.channelAnomalies(ctx), country) {
+		if !contains(ex.degraded, channel) && !contains(ex.down, channel) && !contains(ex.disabled, channel) {
+			ex.degraded = append(ex.degraded, channel)
+		}
+	}
+	return ex
+}
+
+// routeExcluding 分级避让后路由：先排除熔断和降级渠道；无可用渠道时仅排除熔断渠道；
+// 仍无可用渠道则忽略健康信号重新路由，避免因误判导致全部交易失败
+func (s *RouterService) routeExcluding(ctx context.Context, req *RoutingRequest, ex exclusions) (*RoutingResult, error) {
+	tiers := [][]string{append(append([]string{}, ex.down...), ex.degraded...)}
+	if len(ex.degraded) > 0 && len(ex.down) > 0 {
+		tiers = append(tiers, ex.down)
+	}
+
+	for _, excluded := range tiers {
+		if len(excluded) == 0 {
+			break
+		}
+		filtered := *req
+		filtered.ExcludeChannels = append(append([]string{}, req.ExcludeChannels...), excluded...)
+		result, err := s.router.Route(ctx, &filtered)
+		if err == nil {
+			return result, nil
+		}
+		logger.Warn("排除不健康渠道后无可用渠道，放宽避让范围重新路由",
+			zap.Strings("excluded_channels", excluded),
+			zap.Error(err))
+	}
+	return s.router.Route(ctx, req)
+}
+
+// traceResult 记录最终候选范围内各渠道的评估明细
+func (s *RouterService) traceResult(trace *RoutingExplanation, req *RoutingRequest, ex exclusions, result *RoutingResult, err error) {
+	trace.AllowedChannels = req.AllowedChannels
+	trace.DegradedChannels = ex.degraded
+	trace.DownChannels = ex.down
+	trace.DisabledChannels = ex.disabled
+	trace.Result = result
+	if err != nil {
+		trace.Error = err.Error()
+	}
+
+	channels := s.configManager.GetChannels()
+	scored := make(map[string]*ScoredChannel)
+	for _, sc := range NewSmartRoutingStrategy(channels, s.tracker, s.weights).Rank(req) {
+		scored[sc.Config.Channel] = sc
+	}
+
+	for _, ch := range channels {
+		eval := &CandidateEvaluation{Channel: ch.Channel}
+		if sc, ok := scored[ch.Channel]; ok {
+			eval.Eligible = true
+			eval.SuccessRate = sc.Stats.SuccessRate
+			eval.AvgLatency = sc.Stats.AvgLatency
+			eval.Attempts = sc.Stats.Attempts
+			eval.StatsLevel = sc.Stats.Level
+			eval.EstimatedFee = sc.Fee
+			eval.Score = sc.Score
+			switch {
+			case contains(ex.down, ch.Channel):
+				eval.Reason = "渠道熔断中"
+			case contains(ex.degraded, ch.Channel):
+				eval.Reason = "渠道降级或存在严重异常"
+			}
+		} else if contains(ex.disabled, ch.Channel) {
+			eval.Reason = "渠道已被运营手动下线"
+		} else {
+			eval.Reason = channelIneligibleReason(ch, req)
+		}
+		trace.Candidates = append(trace.Candidates, eval)
+	}
+}
+
+func intersect(a, b []string) []string {
+	result := make([]string, 0)
+	for _, item := range a {
+		if contains(b, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// describeRule 规则摘要（用于日志）
+func describeRule(rule *RoutingRule) string {
+	if rule.MerchantID != "" {
+		return fmt.Sprintf("%s(商户 %s)", rule.Name, rule.MerchantID)
+	}
+	return rule.Name
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// PaymentOutcome 支付结果（用于实时更新路由指标）
+type PaymentOutcome struct {
+	Channel   string
+	Currency  string
+	Country   string
+	PayMethod string
+	Success   bool
+	LatencyMs int64 // 渠道耗时，未知时为 0
+	At        time.Time
+}
+
+// ChannelStats 滑动窗口内的渠道表现
+type ChannelStats struct {
+	Attempts    int64   `json:"attempts"`
+	Successes   int64   `json:"successes"`
+	SuccessRate float64 `json:"success_rate"`   // 平滑后的成功率（样本不足时向上一级维度收敛）
+	AvgLatency  float64 `json:"avg_latency_ms"` // 平均耗时（毫秒），无样本时为 0
+	Level       string  `json:"level"`          // 实际使用的统计维度：full, currency, channel, config
+}
+
+// 维度层级（由细到粗）
+const (
+	statsLevelFull     = "full"     // 渠道 × 币种 × 国家 × 支付方式
+	statsLevelCurrency = "currency" // 渠道 × 币种
+	statsLevelChannel  = "channel"  // 渠道
+	statsLevelConfig   = "config"   // 渠道配置中的历史成功率
+)
+
+// statsPriorWeight 平滑时上一级维度的先验权重（等价样本数）
+const statsPriorWeight = 20
+
+type outcomeBucket struct {
+	start        time.Time
+	attempts     int64
+	successes    int64
+	latencyTotal int64
+	latencyCount int64
+}
+
+// OutcomeTracker 按时间分桶的滑动窗口统计（进程内，多实例各自统计并通过渠道配置汇总）
+type OutcomeTracker struct {
+	window     time.Duration
+	bucketSize time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string][]*outcomeBucket
+}
+
+// NewOutcomeTracker 创建滑动窗口统计，window 为窗口长度，bucketSize 为分桶粒度
+func NewOutcomeTracker(window, bucketSize time.Duration) *OutcomeTracker {
+	if bucketSize <= 0 || bucketSize > window {
+		bucketSize = window
+	}
+	return &OutcomeTracker{
+		window:     window,
+		bucketSize: bucketSize,
+		now:        time.Now,
+		buckets:    make(map[string][]*outcomeBucket),
+	}
+}
+
+func statsKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// outcomeKeys 一个结果同时计入各级维度
+func outcomeKeys(channel, currency, country, payMethod string) map[string]string {
+	return map[string]string{
+		statsLevelFull:     statsKey(channel, strings.ToUpper(currency), strings.ToUpper(country), payMethod),
+		statsLevelCurrency: statsKey(channel, strings.ToUpper(currency)),
+		statsLevelChannel:  statsKey(channel),
+	}
+}
+
+// Record 记录一次支付结果
+func (t *OutcomeTracker) Record(outcome *PaymentOutcome) {
+	at := outcome.At
+	if at.IsZero() {
+		at = t.now()
+	}
+	bucketStart := at.Truncate(t.bucketSize)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range outcomeKeys(outcome.Channel, outcome.Currency, outcome.Country, outcome.PayMethod) {
+		buckets := t.prune(key)
+		var bucket *outcomeBucket
+		if n := len(buckets); n > 0 && buckets[n-1].start.Equal(bucketStart) {
+			bucket = buckets[n-1]
+		} else {
+			bucket = &outcomeBucket{start: bucketStart}
+			buckets = append(buckets, bucket)
+		}
+		bucket.attempts++
+		if outcome.Success {
+			bucket.successes++
+		}
+		if outcome.LatencyMs > 0 {
+			bucket.latencyTotal += outcome.LatencyMs
+			bucket.latencyCount++
+		}
+		t.buckets[key] = buckets
+	}
+}
+
+// prune 丢弃窗口外的分桶（调用方持有锁）
+func (t *OutcomeTracker) prune(key string) []*outcomeBucket {
+	buckets := t.buckets[key]
+	cutoff := t.now().Add(-t.window)
+	i := 0
+	for i < len(buckets) && !buckets[i].start.Add(t.bucketSize).After(cutoff) {
+		i++
+	}
+	if i > 0 {
+		buckets = append([]*outcomeBucket{}, buckets[i:]...)
+		if len(buckets) == 0 {
+			delete(t.buckets, key)
+			return nil
+		}
+		t.buckets[key] = buckets
+	}
+	return buckets
+}
+
+func (t *OutcomeTracker) sum(key string) (attempts, successes, latencyTotal, latencyCount int64) {
+	for _, b := range t.prune(key) {
+		attempts += b.attempts
+		successes += b.successes
+		latencyTotal += b.latencyTotal
+		latencyCount += b.latencyCount
+	}
+	return
+}
+
+// Stats 获取渠道在指定维度下的表现
+//
+// 成功率按 渠道配置 → 渠道 → 渠道×币种 → 全维度 逐级做贝叶斯平滑，
+// 细分维度样本不足时结果接近上一级，避免少量样本导致路由抖动。
+func (t *OutcomeTracker) Stats(ch *ChannelConfig, currency, country, payMethod string) *ChannelStats {
+	keys := outcomeKeys(ch.Channel, currency, country, payMethod)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := &ChannelStats{SuccessRate: ch.SuccessRate, AvgLatency: float64(ch.AvgResponseTime), Level: statsLevelConfig}
+	for _, level := range []string{statsLevelChannel, statsLevelCurrency, statsLevelFull} {
+		attempts, successes, latencyTotal, latencyCount := t.sum(keys[level])
+		if attempts == 0 {
+			continue
+		}
+		stats.SuccessRate = (float64(successes) + stats.SuccessRate*statsPriorWeight) / (float64(attempts) + statsPriorWeight)
+		stats.Attempts = attempts
+		stats.Successes = successes
+		if latencyCount > 0 {
+			stats.AvgLatency = float64(latencyTotal) / float64(latencyCount)
+		}
+		stats.Level = level
+	}
+	return stats
+}
+
+// ChannelSnapshot 渠道整体表现（用于回写渠道配置），无样本时 ok 为 false
+func (t *OutcomeTracker) ChannelSnapshot(channel string) (successRate float64, avgLatency int64, attempts int64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts, successes, latencyTotal, latencyCount := t.sum(statsKey(channel))
+	if attempts == 0 {
+		return 0, 0, 0, false
+	}
+	if latencyCount > 0 {
+		avgLatency = latencyTotal / latencyCount
+	}
+	return float64(successes) / float64(attempts), avgLatency, attempts, true
+}
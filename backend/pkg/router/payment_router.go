@@ -35,6 +35,9 @@ type RoutingRequest struct {
 	Country       string                 `json:"country"`        // 国家代码
 	PayMethod     string                 `json:"pay_method"`     // 支付方式：card, wallet, bank_transfer
 	PreferChannel string                 `json:"prefer_channel"` // 优先渠道（可选）
+	ExcludeChannels []string             `json:"exclude_channels"` // 排除的渠道（如异常检测判定为严重异常）
+	AllowedChannels []string             `json:"allowed_channels"` // 候选渠道范围（由路由规则限定，为空不限制）
+	RequestTime   time.Time              `json:"request_time"`   // 请求时间（用于规则时段匹配，为空取当前时间）
 	Extra         map[string]interface{} `json:"extra"`
 }
 
@@ -45,6 +48,8 @@ type RoutingResult struct {
 	EstimatedFee int64   `json:"estimated_fee"` // 估算手续费
 	FeeRate      float64 `json:"fee_rate"`      // 费率
 	Priority     int     `json:"priority"`      // 优先级评分
+	Strategy     string  `json:"strategy"`      // 做出选择的策略
+	Rule         string  `json:"rule,omitempty"` // 命中的路由规则
 }
 
 // ChannelConfig 渠道配置
@@ -114,6 +119,7 @@ func (r *PaymentRouter) Route(ctx context.Context, req *RoutingRequest) (*Routin
 		}
 
 		if result != nil && result.Channel != "" {
+			result.Strategy = strategy.Name()
 			logger.Info("路由策略选择成功",
 				zap.String("strategy", strategy.Name()),
 				zap.String("channel", result.Channel),
@@ -159,10 +165,7 @@ func (s *CostOptimizationStrategy) SelectChannel(ctx context.Context, req *Routi
 		}
 
 		// 计算手续费
-		fee := int64(float64(req.Amount) * ch.FeeRate)
-		if fee < ch.MinFee {
-			fee = ch.MinFee
-		}
+		fee := ch.estimateFee(req.Amount, req.Currency)
 
 		if lowestFee == -1 || fee < lowestFee {
 			lowestFee = fee
@@ -184,31 +187,46 @@ func (s *CostOptimizationStrategy) SelectChannel(ctx context.Context, req *Routi
 }
 
 func (s *CostOptimizationStrategy) isChannelSupported(ch *ChannelConfig, req *RoutingRequest) bool {
+	return channelIneligibleReason(ch, req) == ""
+}
+
+// channelIneligibleReason 渠道不可用于该请求的原因，可用时返回空字符串
+func channelIneligibleReason(ch *ChannelConfig, req *RoutingRequest) string {
 	if !ch.IsEnabled {
-		return false
+		return "渠道已禁用"
+	}
+
+	// 检查是否被排除
+	if contains(req.ExcludeChannels, ch.Channel) {
+		return "渠道已被排除"
+	}
+
+	// 检查是否在规则限定的候选范围内
+	if len(req.AllowedChannels) > 0 && !contains(req.AllowedChannels, ch.Channel) {
+		return "不在路由规则限定的渠道范围内"
 	}
 
 	// 检查金额范围
 	if req.Amount < ch.MinAmount || req.Amount > ch.MaxAmount {
-		return false
+		return fmt.Sprintf("金额超出范围 [%d, %d]", ch.MinAmount, ch.MaxAmount)
 	}
 
 	// 检查币种
 	if !contains(ch.SupportedCurrencies, req.Currency) {
-		return false
+		return fmt.Sprintf("不支持币种 %s", req.Currency)
 	}
 
 	// 检查国家
 	if req.Country != "" && len(ch.SupportedCountries) > 0 && !contains(ch.SupportedCountries, req.Country) {
-		return false
+		return fmt.Sprintf("不支持国家/地区 %s", req.Country)
 	}
 
 	// 检查支付方式
 	if req.PayMethod != "" && len(ch.SupportedPayMethods) > 0 && !contains(ch.SupportedPayMethods, req.PayMethod) {
-		return false
+		return fmt.Sprintf("不支持支付方式 %s", req.PayMethod)
 	}
 
-	return true
+	return ""
 }
 
 // SuccessRateStrategy 成功率优先策略（选择成功率最高的渠道）
@@ -250,10 +268,7 @@ func (s *SuccessRateStrategy) SelectChannel(ctx context.Context, req *RoutingReq
 		return nil, fmt.Errorf("没有支持的渠道")
 	}
 
-	fee := int64(float64(req.Amount) * bestChannel.FeeRate)
-	if fee < bestChannel.MinFee {
-		fee = bestChannel.MinFee
-	}
+	fee := bestChannel.estimateFee(req.Amount, req.Currency)
 
 	return &RoutingResult{
 		Channel:      bestChannel.Channel,
@@ -324,10 +339,7 @@ func (s *LoadBalanceStrategy) SelectChannel(ctx context.Context, req *RoutingReq
 		selectedChannel = eligibleChannels[0]
 	}
 
-	fee := int64(float64(req.Amount) * selectedChannel.FeeRate)
-	if fee < selectedChannel.MinFee {
-		fee = selectedChannel.MinFee
-	}
+	fee := selectedChannel.estimateFee(req.Amount, req.Currency)
 
 	return &RoutingResult{
 		Channel:      selectedChannel.Channel,
@@ -384,10 +396,7 @@ func (s *GeographicStrategy) SelectChannel(ctx context.Context, req *RoutingRequ
 	// 检查推荐渠道是否可用
 	for _, ch := range s.channels {
 		if ch.Channel == preferredChannel && s.isChannelSupported(ch, req) {
-			fee := int64(float64(req.Amount) * ch.FeeRate)
-			if fee < ch.MinFee {
-				fee = ch.MinFee
-			}
+			fee := ch.estimateFee(req.Amount, req.Currency)
 
 			return &RoutingResult{
 				Channel:      ch.Channel,
@@ -3,6 +3,9 @@ package router
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"sync"
+	"time"
 
 	"github.com/payment-platform/pkg/logger"
 	"github.com/redis/go-redis/v9"
@@ -13,8 +16,47 @@ import (
 type RouterService struct {
 	router        *PaymentRouter
 	configManager *ConfigManager
+	redisClient   *redis.Client
+
+	strategyMode string
+	tracker      *OutcomeTracker
+	weights      ScoringWeights
+
+	ruleProvider RuleProvider
+	ruleMu       sync.RWMutex
+	rules        []*RoutingRule
+	rulesLoaded  time.Time
+
+	anomalyMu       sync.RWMutex
+	anomalies       []*ChannelAnomaly
+	anomaliesLoaded time.Time
+
+	healthMu     sync.RWMutex
+	health       map[string]*ChannelHealth
+	healthLoaded time.Time
+	probe        func() bool
 }
 
+// anomalyRefreshInterval 渠道异常信号本地缓存时长
+const anomalyRefreshInterval = 30 * time.Second
+
+// healthRefreshInterval 渠道健康状态本地缓存时长（熔断需要尽快生效，比异常信号更短）
+const healthRefreshInterval = 10 * time.Second
+
+// healthProbeRatio 熔断器半开期间放行到该渠道的流量比例
+const healthProbeRatio = 0.05
+
+// ruleRefreshInterval 路由规则本地缓存时长
+const ruleRefreshInterval = 30 * time.Second
+
+// 实时路由指标滑动窗口
+const (
+	outcomeWindow     = 15 * time.Minute
+	outcomeBucketSize = time.Minute
+	// metricsSyncMinAttempts 回写渠道配置所需的最少样本数
+	metricsSyncMinAttempts = 50
+)
+
 // NewRouterService 创建路由服务
 func NewRouterService(redisClient *redis.Client) *RouterService {
 	configManager := NewConfigManager(redisClient)
@@ -23,9 +65,58 @@ func NewRouterService(redisClient *redis.Client) *RouterService {
 	return &RouterService{
 		router:        router,
 		configManager: configManager,
+		redisClient:   redisClient,
+		tracker:       NewOutcomeTracker(outcomeWindow, outcomeBucketSize),
+		weights:       DefaultScoringWeights(),
+		probe:         func() bool { return rand.Float64() < healthProbeRatio },
 	}
 }
 
+// SetRuleProvider 设置路由规则来源（数据库维护的商户/平台规则）
+func (s *RouterService) SetRuleProvider(provider RuleProvider) {
+	s.ruleProvider = provider
+}
+
+// InvalidateRules 使规则缓存失效（规则变更后调用）
+func (s *RouterService) InvalidateRules() {
+	s.ruleMu.Lock()
+	defer s.ruleMu.Unlock()
+	s.rulesLoaded = time.Time{}
+}
+
+// loadRules 读取路由规则（带本地缓存，加载失败时沿用上次结果）
+func (s *RouterService) loadRules(ctx context.Context) []*RoutingRule {
+	if s.ruleProvider == nil {
+		return nil
+	}
+
+	s.ruleMu.RLock()
+	if time.Since(s.rulesLoaded) < ruleRefreshInterval {
+		rules := s.rules
+		s.ruleMu.RUnlock()
+		return rules
+	}
+	s.ruleMu.RUnlock()
+
+	rules, err := s.ruleProvider.ListRules(ctx)
+
+	s.ruleMu.Lock()
+	defer s.ruleMu.Unlock()
+	if err != nil {
+		logger.Warn("加载路由规则失败", zap.Error(err))
+	} else {
+		SortRules(rules)
+		s.rules = rules
+	}
+	s.rulesLoaded = time.Now()
+	return s.rules
+}
+
+// SetScoringWeights 设置智能路由评分权重（需在 Initialize 之前调用）
+func (s *RouterService) SetScoringWeights(weights ScoringWeights) {
+	s.weights = weights
+}
+
 // Initialize 初始化路由服务
 func (s *RouterService) Initialize(ctx context.Context, strategyMode string) error {
 	// 加载渠道配置
@@ -34,9 +125,25 @@ func (s *RouterService) Initialize(ctx context.Context, strategyMode string) err
 	}
 
 	channels := s.configManager.GetChannels()
+	s.strategyMode = strategyMode
+	s.registerStrategies(channels)
+
+	logger.Info("路由服务初始化完成", zap.Int("channels", len(channels)))
+	return nil
+}
+
+// registerStrategies 根据路由模式注册策略
+func (s *RouterService) registerStrategies(channels []*ChannelConfig) {
+	switch s.strategyMode {
+	case "smart":
+		// 智能路由模式：按实时成功率、耗时、成本综合评分
+		s.router.RegisterStrategy(NewSmartRoutingStrategy(channels, s.tracker, s.weights))
+		s.router.RegisterStrategy(NewLoadBalanceStrategy(channels))
+		logger.Info("路由模式: 智能路由",
+			zap.Float64("weight_success_rate", s.weights.SuccessRate),
+			zap.Float64("weight_latency", s.weights.Latency),
+			zap.Float64("weight_cost", s.weights.Cost))
 
-	// 根据模式注册策略
-	switch strategyMode {
 	case "cost":
 		// 成本优先模式
 		s.router.RegisterStrategy(NewCostOptimizationStrategy(channels))
@@ -68,14 +175,119 @@ func (s *RouterService) Initialize(ctx context.Context, strategyMode string) err
 		s.router.RegisterStrategy(NewLoadBalanceStrategy(channels))
 		logger.Info("路由模式: 平衡模式（默认）")
 	}
-
-	logger.Info("路由服务初始化完成", zap.Int("channels", len(channels)))
-	return nil
 }
 
 // SelectChannel 选择支付渠道
+//
+// 先按路由规则限定候选渠道，再由策略在候选范围内选择；处于严重异常的渠道会被排除，
+// 若排除后没有可用渠道，则忽略异常信号重新路由，避免因异常检测误报导致全部交易失败。
 func (s *RouterService) SelectChannel(ctx context.Context, req *RoutingRequest) (*RoutingResult, error) {
-	return s.router.Route(ctx, req)
+	return s.route(ctx, req, &routeOptions{rules: s.loadRules(ctx)})
+}
+
+// GetChannelAnomalies 获取当前生效的渠道异常信号
+func (s *RouterService) GetChannelAnomalies(ctx context.Context) []*ChannelAnomaly {
+	return s.channelAnomalies(ctx)
+}
+
+// GetChannelHealth 获取各渠道当前的有效健康状态
+func (s *RouterService) GetChannelHealth(ctx context.Context) map[string]*ChannelHealth {
+	return s.channelHealth(ctx)
+}
+
+// channelHealth 读取渠道健康状态（带本地缓存，Redis 不可用时沿用上次结果）
+func (s *RouterService) channelHealth(ctx context.Context) map[string]*ChannelHealth {
+	s.healthMu.RLock()
+	if time.Since(s.healthLoaded) < healthRefreshInterval {
+		health := s.health
+		s.healthMu.RUnlock()
+		return health
+	}
+	s.healthMu.RUnlock()
+
+	health, err := LoadChannelHealth(ctx, s.redisClient)
+
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	if err != nil {
+		logger.Warn("加载渠道健康状态失败", zap.Error(err))
+	} else {
+		s.health = health
+	}
+	s.healthLoaded = time.Now()
+	return s.health
+}
+
+// channelAnomalies 读取渠道异常信号（带本地缓存，Redis 不可用时沿用上次结果）
+func (s *RouterService) channelAnomalies(ctx context.Context) []*ChannelAnomaly {
+	s.anomalyMu.RLock()
+	if time.Since(s.anomaliesLoaded) < anomalyRefreshInterval {
+		anomalies := s.anomalies
+		s.anomalyMu.RUnlock()
+		return anomalies
+	}
+	s.anomalyMu.RUnlock()
+
+	anomalies, err := LoadChannelAnomalies(ctx, s.redisClient)
+
+	s.anomalyMu.Lock()
+	defer s.anomalyMu.Unlock()
+	if err != nil {
+		logger.Warn("加载渠道异常信号失败", zap.Error(err))
+	} else {
+		s.anomalies = anomalies
+	}
+	s.anomaliesLoaded = time.Now()
+	return s.anomalies
+}
+
+// RecordOutcome 记录支付结果，实时更新路由指标
+func (s *RouterService) RecordOutcome(outcome *PaymentOutcome) {
+	if outcome == nil || outcome.Channel == "" {
+		return
+	}
+	s.tracker.Record(outcome)
+}
+
+// GetChannelStats 获取渠道在指定维度下的实时表现
+func (s *RouterService) GetChannelStats(channel, currency, country, payMethod string) (*ChannelStats, error) {
+	config, err := s.configManager.GetChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return s.tracker.Stats(config, currency, country, payMethod), nil
+}
+
+// StartMetricsSync 定期将滑动窗口内的渠道表现回写到渠道配置
+//
+// 回写后的成功率/耗时既供非智能模式的策略使用，也作为智能路由细分维度的平滑先验。
+func (s *RouterService) StartMetricsSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncMetrics(ctx)
+		}
+	}
+}
+
+func (s *RouterService) syncMetrics(ctx context.Context) {
+	for _, ch := range s.configManager.GetChannels() {
+		successRate, avgLatency, attempts, ok := s.tracker.ChannelSnapshot(ch.Channel)
+		if !ok || attempts < metricsSyncMinAttempts {
+			continue
+		}
+		if avgLatency == 0 {
+			avgLatency = ch.AvgResponseTime
+		}
+		if err := s.configManager.UpdateChannelMetrics(ctx, ch.Channel, successRate, avgLatency); err != nil {
+			logger.Warn("回写渠道指标失败", zap.String("channel", ch.Channel), zap.Error(err))
+		}
+	}
 }
 
 // GetChannelConfig 获取渠道配置
@@ -107,11 +319,7 @@ func (s *RouterService) ReloadChannels(ctx context.Context) error {
 	// 重新注册策略
 	channels := s.configManager.GetChannels()
 	s.router = NewPaymentRouter()
-
-	s.router.RegisterStrategy(NewGeographicStrategy(channels))
-	s.router.RegisterStrategy(NewSuccessRateStrategy(channels))
-	s.router.RegisterStrategy(NewCostOptimizationStrategy(channels))
-	s.router.RegisterStrategy(NewLoadBalanceStrategy(channels))
+	s.registerStrategies(channels)
 
 	logger.Info("渠道配置已重新加载", zap.Int("channels", len(channels)))
 	return nil
@@ -124,10 +332,7 @@ func (s *RouterService) EstimateFee(ctx context.Context, channel string, amount
 		return 0, err
 	}
 
-	fee := int64(float64(amount) * config.FeeRate)
-	if fee < config.MinFee {
-		fee = config.MinFee
-	}
+	fee := config.estimateFee(amount, "")
 
 	return fee, nil
 }
@@ -0,0 +1,171 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoutingRule 路由规则（由商户/运营在数据库中维护）
+//
+// 规则先于策略执行：命中的规则限定候选渠道范围，再由路由策略在范围内评分选择。
+type RoutingRule struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Priority   int            `json:"priority"`              // 优先级（越大越优先）
+	MerchantID string         `json:"merchant_id,omitempty"` // 商户规则（为空表示平台规则）
+	Channels   []string       `json:"channels"`              // 命中后的候选渠道
+	Conditions RuleConditions `json:"conditions"`
+}
+
+// RuleConditions 规则条件，未设置的条件视为不限制
+type RuleConditions struct {
+	MinAmount   int64       `json:"min_amount,omitempty"`
+	MaxAmount   int64       `json:"max_amount,omitempty"`
+	Currencies  []string    `json:"currencies,omitempty"`
+	Countries   []string    `json:"countries,omitempty"`
+	PayMethods  []string    `json:"pay_methods,omitempty"`
+	MerchantIDs []string    `json:"merchant_ids,omitempty"`
+	TimeRanges  []TimeRange `json:"time_ranges,omitempty"` // 生效时段（任一时段命中即可）
+	Timezone    string      `json:"timezone,omitempty"`    // 时段所用时区（IANA），默认 UTC
+}
+
+// TimeRange 每日时段，格式 HH:MM，End 早于 Start 表示跨零点
+type TimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// RuleProvider 路由规则来源
+type RuleProvider interface {
+	ListRules(ctx context.Context) ([]*RoutingRule, error)
+}
+
+// Validate 校验规则配置
+func (r *RoutingRule) Validate() error {
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("规则至少需要一个渠道")
+	}
+	c := r.Conditions
+	if c.MinAmount < 0 || c.MaxAmount < 0 {
+		return fmt.Errorf("金额条件不能为负数")
+	}
+	if c.MaxAmount > 0 && c.MinAmount > c.MaxAmount {
+		return fmt.Errorf("最小金额不能大于最大金额")
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("无效的时区: %s", c.Timezone)
+		}
+	}
+	for _, tr := range c.TimeRanges {
+		if _, err := parseClock(tr.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(tr.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match 判断请求是否命中规则，未命中时返回原因
+func (r *RoutingRule) Match(req *RoutingRequest) (bool, string) {
+	if r.MerchantID != "" && !strings.EqualFold(r.MerchantID, req.MerchantID) {
+		return false, "商户规则不适用于当前商户"
+	}
+
+	c := r.Conditions
+	if c.MinAmount > 0 && req.Amount < c.MinAmount {
+		return false, fmt.Sprintf("金额低于 %d", c.MinAmount)
+	}
+	if c.MaxAmount > 0 && req.Amount > c.MaxAmount {
+		return false, fmt.Sprintf("金额高于 %d", c.MaxAmount)
+	}
+	if len(c.Currencies) > 0 && !containsFold(c.Currencies, req.Currency) {
+		return false, fmt.Sprintf("币种 %s 不在条件内", req.Currency)
+	}
+	if len(c.Countries) > 0 && !containsFold(c.Countries, req.Country) {
+		return false, fmt.Sprintf("国家/地区 %q 不在条件内", req.Country)
+	}
+	if len(c.PayMethods) > 0 && !containsFold(c.PayMethods, req.PayMethod) {
+		return false, fmt.Sprintf("支付方式 %q 不在条件内", req.PayMethod)
+	}
+	if len(c.MerchantIDs) > 0 && !containsFold(c.MerchantIDs, req.MerchantID) {
+		return false, "商户不在条件内"
+	}
+	if len(c.TimeRanges) > 0 && !c.inTimeRanges(req.RequestTime) {
+		return false, "不在生效时段内"
+	}
+	return true, ""
+}
+
+func (c *RuleConditions) inTimeRanges(at time.Time) bool {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	loc := time.UTC
+	if c.Timezone != "" {
+		if l, err := time.LoadLocation(c.Timezone); err == nil {
+			loc = l
+		}
+	}
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, tr := range c.TimeRanges {
+		start, err1 := parseClock(tr.Start)
+		end, err2 := parseClock(tr.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if start <= end {
+			if minute >= start && minute < end {
+				return true
+			}
+		} else if minute >= start || minute < end { // 跨零点
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock 解析 HH:MM，返回当日分钟数
+func parseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("无效的时间格式: %s（应为 HH:MM）", value)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("无效的时间格式: %s（应为 HH:MM）", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("无效的时间格式: %s（应为 HH:MM）", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// SortRules 规则求值顺序：商户规则优先于平台规则，同类按优先级从高到低
+func SortRules(rules []*RoutingRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		mi, mj := rules[i].MerchantID != "", rules[j].MerchantID != ""
+		if mi != mj {
+			return mi
+		}
+		return rules[i].Priority > rules[j].Priority
+	})
+}
+
+func containsFold(slice []string, item string) bool {
+	for _, s := range slice {
+		if strings.EqualFold(s, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/payment-platform/pkg/logger"
+)
+
+type staticRules []*RoutingRule
+
+func (r staticRules) ListRules(ctx context.Context) ([]*RoutingRule, error) {
+	return append([]*RoutingRule{}, r...), nil
+}
+
+func TestRoutingRuleMatch(t *testing.T) {
+	rule := &RoutingRule{
+		Name:     "night-usd",
+		Channels: []string{"paypal"},
+		Conditions: RuleConditions{
+			MinAmount:  100,
+			Currencies: []string{"USD"},
+			PayMethods: []string{"card"},
+			TimeRanges: []TimeRange{{Start: "22:00", End: "06:00"}},
+		},
+	}
+	require.NoError(t, rule.Validate())
+
+	night := time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC)
+	req := &RoutingRequest{Amount: 500, Currency: "usd", PayMethod: "card", RequestTime: night}
+	ok, _ := rule.Match(req)
+	assert.True(t, ok)
+
+	req.RequestTime = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ok, reason := rule.Match(req)
+	assert.False(t, ok)
+	assert.NotEmpty(t, reason)
+
+	req.RequestTime = night
+	req.Amount = 50
+	ok, _ = rule.Match(req)
+	assert.False(t, ok)
+
+	rule.Conditions.TimeRanges = []TimeRange{{Start: "25:00", End: "06:00"}}
+	assert.Error(t, rule.Validate())
+}
+
+func TestSelectChannelAppliesRulesBeforeStrategies(t *testing.T) {
+	logger.Log = zap.NewNop()
+	merchantID := "7f1a2b3c-0000-0000-0000-000000000001"
+
+	s := NewRouterService(nil)
+	require.NoError(t, s.Initialize(context.Background(), "cost"))
+	s.SetRuleProvider(staticRules{
+		{ID: "1", Name: "platform-paypal", Priority: 10, Channels: []string{"paypal"},
+			Conditions: RuleConditions{Currencies: []string{"USD"}}},
+		{ID: "2", Name: "merchant-stripe", Priority: 1, MerchantID: merchantID, Channels: []string{"stripe"}},
+		{ID: "3", Name: "unusable", Priority: 20, Channels: []string{"wechat"},
+			Conditions: RuleConditions{Currencies: []string{"USD"}}},
+	})
+
+	// 平台规则限定 paypal；更高优先级的 wechat 规则不支持 USD，跳过
+	result, err := s.SelectChannel(context.Background(), &RoutingRequest{Amount: 10000, Currency: "USD", Country: "US"})
+	require.NoError(t, err)
+	assert.Equal(t, "paypal", result.Channel)
+	assert.Equal(t, "platform-paypal", result.Rule)
+
+	// 商户规则优先于平台规则
+	result, err = s.SelectChannel(context.Background(), &RoutingRequest{MerchantID: merchantID, Amount: 10000, Currency: "USD", Country: "US"})
+	require.NoError(t, err)
+	assert.Equal(t, "stripe", result.Channel)
+
+	// 未命中规则时由策略在全部渠道中选择
+	result, err = s.SelectChannel(context.Background(), &RoutingRequest{Amount: 10000, Currency: "CNY", Country: "CN", PayMethod: "wallet"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Rule)
+	assert.Equal(t, "CostOptimization", result.Strategy)
+
+	explanation := s.Explain(context.Background(), &RoutingRequest{Amount: 10000, Currency: "USD", Country: "US"})
+	require.NotNil(t, explanation.Result)
+	assert.Equal(t, "paypal", explanation.Result.Channel)
+	assert.Equal(t, []string{"paypal"}, explanation.AllowedChannels)
+	require.Len(t, explanation.Rules, 3)
+	assert.Equal(t, "unusable", explanation.Rules[1].Name)
+	assert.True(t, explanation.Rules[1].Matched)
+	assert.False(t, explanation.Rules[1].Applied)
+	assert.True(t, explanation.Rules[2].Applied)
+
+	// what-if：使用候选规则模拟
+	result, err = s.Simulate(context.Background(), &RoutingRequest{Amount: 10000, Currency: "USD", Country: "US"},
+		[]*RoutingRule{{Name: "try-stripe", Channels: []string{"stripe"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "stripe", result.Channel)
+}
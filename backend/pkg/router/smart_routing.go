@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/payment-platform/pkg/money"
+)
+
+// ScoringWeights 多目标评分权重（成功率、耗时、成本），权重之和无需为 1
+type ScoringWeights struct {
+	SuccessRate float64 `json:"success_rate"`
+	Latency     float64 `json:"latency"`
+	Cost        float64 `json:"cost"`
+}
+
+// DefaultScoringWeights 默认评分权重：授权成功率优先
+func DefaultScoringWeights() ScoringWeights {
+	return ScoringWeights{SuccessRate: 0.6, Latency: 0.2, Cost: 0.2}
+}
+
+// ScoredChannel 渠道评分明细
+type ScoredChannel struct {
+	Config *ChannelConfig
+	Stats  *ChannelStats
+	Fee    int64
+	Score  float64
+}
+
+// SmartRoutingStrategy 实时表现驱动的多目标评分策略
+//
+// 成功率和耗时来自 OutcomeTracker 的滑动窗口（按 渠道 × 币种 × 国家 × 支付方式 统计），
+// 耗时和成本在候选渠道间归一化后与成功率加权求和。
+type SmartRoutingStrategy struct {
+	channels []*ChannelConfig
+	tracker  *OutcomeTracker
+	weights  ScoringWeights
+}
+
+// NewSmartRoutingStrategy 创建多目标评分策略
+func NewSmartRoutingStrategy(channels []*ChannelConfig, tracker *OutcomeTracker, weights ScoringWeights) *SmartRoutingStrategy {
+	return &SmartRoutingStrategy{
+		channels: channels,
+		tracker:  tracker,
+		weights:  weights,
+	}
+}
+
+func (s *SmartRoutingStrategy) Name() string {
+	return "SmartScore"
+}
+
+func (s *SmartRoutingStrategy) Priority() int {
+	return 95 // 高于地域优化
+}
+
+func (s *SmartRoutingStrategy) SelectChannel(ctx context.Context, req *RoutingRequest) (*RoutingResult, error) {
+	ranked := s.Rank(req)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("没有支持的渠道")
+	}
+
+	best := ranked[0]
+	return &RoutingResult{
+		Channel: best.Config.Channel,
+		Reason: fmt.Sprintf("综合评分最高（%.3f，成功率 %.1f%%，耗时 %.0fms，样本 %d/%s）",
+			best.Score, best.Stats.SuccessRate*100, best.Stats.AvgLatency, best.Stats.Attempts, best.Stats.Level),
+		EstimatedFee: best.Fee,
+		FeeRate:      best.Config.FeeRate,
+		Priority:     95,
+	}, nil
+}
+
+// Rank 按综合评分从高到低排列可用渠道
+func (s *SmartRoutingStrategy) Rank(req *RoutingRequest) []*ScoredChannel {
+	var candidates []*ScoredChannel
+	var maxLatency float64
+	var maxFee int64
+
+	for _, ch := range s.channels {
+		if !(&CostOptimizationStrategy{}).isChannelSupported(ch, req) {
+			continue
+		}
+
+		stats := &ChannelStats{SuccessRate: ch.SuccessRate, AvgLatency: float64(ch.AvgResponseTime), Level: statsLevelConfig}
+		if s.tracker != nil {
+			stats = s.tracker.Stats(ch, req.Currency, req.Country, req.PayMethod)
+		}
+
+		fee := ch.estimateFee(req.Amount, req.Currency)
+
+		if stats.AvgLatency > maxLatency {
+			maxLatency = stats.AvgLatency
+		}
+		if fee > maxFee {
+			maxFee = fee
+		}
+		candidates = append(candidates, &ScoredChannel{Config: ch, Stats: stats, Fee: fee})
+	}
+
+	for _, c := range candidates {
+		latencyScore, costScore := 1.0, 1.0
+		if maxLatency > 0 {
+			latencyScore = 1 - c.Stats.AvgLatency/maxLatency
+		}
+		if maxFee > 0 {
+			costScore = 1 - float64(c.Fee)/float64(maxFee)
+		}
+		c.Score = s.weights.SuccessRate*c.Stats.SuccessRate +
+			s.weights.Latency*latencyScore +
+			s.weights.Cost*costScore
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Config.Weight > candidates[j].Config.Weight
+	})
+	return candidates
+}
+
+// estimateFee 按费率估算手续费（最小单位，四舍五入），不低于最低手续费；
+// 溢出时视为成本无穷大
+func (c *ChannelConfig) estimateFee(amount int64, currency string) int64 {
+	fee, err := money.New(amount, currency).Mul(money.DecimalFromFloat(c.FeeRate), money.RoundHalfUp)
+	if err != nil {
+		return math.MaxInt64
+	}
+	if fee.Amount < c.MinFee {
+		return c.MinFee
+	}
+	return fee.Amount
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testChannels() []*ChannelConfig {
+	return []*ChannelConfig{
+		{Channel: "a", IsEnabled: true, SupportedCurrencies: []string{"USD"}, FeeRate: 0.03, MaxAmount: 1000000, SuccessRate: 0.9, AvgResponseTime: 500, Weight: 50},
+		{Channel: "b", IsEnabled: true, SupportedCurrencies: []string{"USD"}, FeeRate: 0.03, MaxAmount: 1000000, SuccessRate: 0.9, AvgResponseTime: 500, Weight: 40},
+	}
+}
+
+func recordN(tracker *OutcomeTracker, channel string, n, successes int) {
+	for i := 0; i < n; i++ {
+		tracker.Record(&PaymentOutcome{
+			Channel: channel, Currency: "USD", Country: "US", PayMethod: "card",
+			Success: i < successes, LatencyMs: 400,
+		})
+	}
+}
+
+func TestOutcomeTrackerSmoothing(t *testing.T) {
+	tracker := NewOutcomeTracker(15*time.Minute, time.Minute)
+	ch := testChannels()[0]
+
+	stats := tracker.Stats(ch, "USD", "US", "card")
+	assert.Equal(t, statsLevelConfig, stats.Level)
+	assert.Equal(t, 0.9, stats.SuccessRate)
+
+	recordN(tracker, "a", 200, 100)
+	stats = tracker.Stats(ch, "usd", "us", "card")
+	assert.Equal(t, statsLevelFull, stats.Level)
+	assert.Equal(t, int64(200), stats.Attempts)
+	assert.InDelta(t, 0.5, stats.SuccessRate, 0.05)
+	assert.Equal(t, 400.0, stats.AvgLatency)
+
+	// 其他国家没有样本，收敛到渠道×币种维度
+	stats = tracker.Stats(ch, "USD", "GB", "card")
+	assert.Equal(t, statsLevelCurrency, stats.Level)
+}
+
+func TestOutcomeTrackerWindowExpiry(t *testing.T) {
+	tracker := NewOutcomeTracker(15*time.Minute, time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	recordN(tracker, "a", 10, 0)
+
+	_, _, attempts, ok := tracker.ChannelSnapshot("a")
+	require.True(t, ok)
+	assert.Equal(t, int64(10), attempts)
+
+	now = now.Add(20 * time.Minute)
+	_, _, _, ok = tracker.ChannelSnapshot("a")
+	assert.False(t, ok)
+}
+
+func TestSmartRoutingPrefersHealthyChannel(t *testing.T) {
+	tracker := NewOutcomeTracker(15*time.Minute, time.Minute)
+	strategy := NewSmartRoutingStrategy(testChannels(), tracker, DefaultScoringWeights())
+	req := &RoutingRequest{Amount: 10000, Currency: "USD", Country: "US", PayMethod: "card"}
+
+	recordN(tracker, "a", 100, 40)
+	recordN(tracker, "b", 100, 95)
+
+	result, err := strategy.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "b", result.Channel)
+
+	req.ExcludeChannels = []string{"b"}
+	result, err = strategy.SelectChannel(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "a", result.Channel)
+}
+
+func TestIsCascadeEligible(t *testing.T) {
+	assert.True(t, IsCascadeEligible("创建支付失败: do_not_honor"))
+	assert.True(t, IsCascadeEligible("调用Channel服务失败: HTTP错误: 503 Service Unavailable"))
+	assert.False(t, IsCascadeEligible("创建支付失败: insufficient_funds"))
+	assert.False(t, IsCascadeEligible("调用Channel服务失败: context deadline exceeded"))
+	assert.False(t, IsCascadeEligible("创建支付失败: unknown"))
+	assert.False(t, IsCascadeEligible(""))
+
+	assert.True(t, IsDeclineCascadeEligible(decline.New(decline.CategoryIssuerUnavailable, "stripe", "issuer_not_available", "")))
+	assert.False(t, IsDeclineCascadeEligible(decline.New(decline.CategoryAuthenticationRequired, "stripe", "authentication_required", "")))
+	assert.False(t, IsDeclineCascadeEligible(nil))
+}
+
+func TestEstimateFeeUsesExactDecimal(t *testing.T) {
+	// 10000 × 0.0029 在 float64 下为 28.999999999999996，截断会少收 1 分
+	assert.Equal(t, int64(29), (&ChannelConfig{FeeRate: 0.0029}).estimateFee(10000, "USD"))
+
+	// 四舍五入到最小单位：1750 × 0.029 = 50.75
+	ch := &ChannelConfig{FeeRate: 0.029, MinFee: 30}
+	assert.Equal(t, int64(51), ch.estimateFee(1750, "USD"))
+	assert.Equal(t, int64(30), ch.estimateFee(100, "USD"), "不低于最低手续费")
+}
@@ -0,0 +1,203 @@
+package saga
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+)
+
+// AdminHandler Saga 查询与人工干预接口（由 admin-bff 完成认证与权限校验）
+type AdminHandler struct {
+	orchestrator *SagaOrchestrator
+	worker       *RecoveryWorker
+}
+
+// NewAdminHandler 创建 Saga 管理处理器
+func NewAdminHandler(orchestrator *SagaOrchestrator, worker *RecoveryWorker) *AdminHandler {
+	return &AdminHandler{orchestrator: orchestrator, worker: worker}
+}
+
+// RegisterServiceRoutes 注册服务间调用的路由
+func (h *AdminHandler) RegisterServiceRoutes(r *gin.RouterGroup) {
+	sagas := r.Group("/service/sagas")
+	{
+		sagas.GET("", h.ListSagas)
+		sagas.GET("/business-types", h.ListBusinessTypes)
+		sagas.GET("/dlq", h.ListDLQ)
+		sagas.GET("/:id", h.GetSaga)
+		sagas.GET("/:id/timeline", h.GetTimeline)
+		sagas.POST("/:id/resume", h.Resume)
+		sagas.POST("/:id/retry-compensation", h.RetryCompensation)
+		sagas.POST("/:id/abort", h.Abort)
+		sagas.POST("/:id/requeue", h.Requeue)
+	}
+}
+
+// ListSagas 查询 Saga 列表
+func (h *AdminHandler) ListSagas(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	sagas, total, err := h.orchestrator.ListSagas(c.Request.Context(), SagaFilter{
+		Status:       SagaStatus(c.Query("status")),
+		BusinessType: c.Query("business_type"),
+		BusinessID:   c.Query("business_id"),
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "查询 Saga 失败", err)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(gin.H{"list": sagas, "total": total}).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListBusinessTypes 已注册步骤定义的业务类型（只有这些类型可以自动恢复和人工驱动）
+func (h *AdminHandler) ListBusinessTypes(c *gin.Context) {
+	resp := errors.NewSuccessResponse(h.orchestrator.Registry().BusinessTypes()).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListDLQ 查询死信队列
+func (h *AdminHandler) ListDLQ(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	sagas, total, err := h.orchestrator.ListSagas(c.Request.Context(), SagaFilter{Status: SagaStatusDeadLetter, Limit: limit})
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "查询死信队列失败", err)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(gin.H{"list": sagas, "total": total}).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSaga 查询 Saga 详情（含步骤）
+func (h *AdminHandler) GetSaga(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	saga, err := h.orchestrator.GetSaga(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, http.StatusNotFound, "Saga 不存在", err)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(saga).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTimeline 查询 Saga 步骤执行时间线（含依赖关系、幂等键与 Mermaid 甘特图）
+func (h *AdminHandler) GetTimeline(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.orchestrator.GetTimeline(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, http.StatusNotFound, "Saga 不存在", err)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(timeline).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// Resume 从当前步骤续跑 Saga
+func (h *AdminHandler) Resume(c *gin.Context) {
+	h.drive(c, "续跑 Saga 失败", func(id uuid.UUID) error {
+		return h.orchestrator.Resume(c.Request.Context(), id)
+	})
+}
+
+// RetryCompensation 重试失败的补偿
+func (h *AdminHandler) RetryCompensation(c *gin.Context) {
+	h.drive(c, "重试补偿失败", func(id uuid.UUID) error {
+		return h.orchestrator.RetryCompensation(c.Request.Context(), id)
+	})
+}
+
+// Abort 终止 Saga 并补偿已完成的步骤
+func (h *AdminHandler) Abort(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.Reason == "" {
+		req.Reason = c.Query("reason")
+	}
+
+	h.drive(c, "终止 Saga 失败", func(id uuid.UUID) error {
+		return h.orchestrator.Abort(c.Request.Context(), id, req.Reason)
+	})
+}
+
+// Requeue 将死信队列中的 Saga 放回自动恢复
+func (h *AdminHandler) Requeue(c *gin.Context) {
+	h.drive(c, "重新入队失败", func(id uuid.UUID) error {
+		if err := h.orchestrator.Requeue(c.Request.Context(), id); err != nil {
+			return err
+		}
+		if h.worker != nil && h.orchestrator.redis != nil {
+			return h.worker.RemoveFromDLQ(c.Request.Context(), id.String())
+		}
+		return nil
+	})
+}
+
+// drive 执行人工操作并返回 Saga 最新状态
+func (h *AdminHandler) drive(c *gin.Context, message string, action func(id uuid.UUID) error) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := action(id); err != nil {
+		h.respondError(c, http.StatusConflict, message, err)
+		return
+	}
+
+	saga, err := h.orchestrator.GetSaga(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "查询 Saga 失败", err)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(saga).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AdminHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "无效的 Saga ID", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *AdminHandler) respondError(c *gin.Context, status int, message string, err error) {
+	code := errors.ErrCodeInternalError
+	switch status {
+	case http.StatusBadRequest:
+		code = errors.ErrCodeInvalidRequest
+	case http.StatusNotFound:
+		code = errors.ErrCodeResourceNotFound
+	case http.StatusConflict:
+		code = errors.ErrCodeConflict
+	}
+	resp := errors.NewErrorResponse(code, message, err.Error()).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(status, resp)
+}
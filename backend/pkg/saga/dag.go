@@ -0,0 +1,360 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/idempotency"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type stepOutputsKey struct{}
+
+// StepOutput 在步骤执行函数中按名称获取已完成步骤的输出（即该步骤 Execute 返回的 result）
+//
+// 恢复续跑时输出从持久化的步骤结果中还原，步骤之间不需要再通过闭包变量传递数据。
+func StepOutput(ctx context.Context, stepName string) string {
+	outputs, _ := ctx.Value(stepOutputsKey{}).(map[string]string)
+	return outputs[stepName]
+}
+
+// StepIdempotencyKey 在步骤执行函数中获取当前步骤的幂等键（同一步骤重试时不变）
+//
+// 通过 pkg/httpclient 或服务内 HTTPClient 发起的调用会自动携带该幂等键，无需手动传递。
+func StepIdempotencyKey(ctx context.Context) string {
+	return idempotency.KeyFromContext(ctx)
+}
+
+// stepIdempotencyKey 根据 Saga ID 与步骤名称派生步骤幂等键
+func stepIdempotencyKey(sagaID uuid.UUID, stepName string) string {
+	return fmt.Sprintf("saga:%s:%s", sagaID.String(), stepName)
+}
+
+// idempotencyKey 步骤幂等键（兼容升级前创建、未持久化幂等键的步骤）
+func (s *SagaStep) idempotencyKey() string {
+	if s.IdempotencyKey != "" {
+		return s.IdempotencyKey
+	}
+	return stepIdempotencyKey(s.SagaID, s.StepName)
+}
+
+// Dependencies 步骤依赖的步骤名称
+func (s *SagaStep) Dependencies() []string {
+	if s.DependsOn == "" {
+		return nil
+	}
+	return strings.Split(s.DependsOn, ",")
+}
+
+// isDAG 任一步骤声明了依赖即按 DAG 调度
+func isDAG(stepDefs []StepDefinition) bool {
+	for _, def := range stepDefs {
+		if len(def.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStepDefinitions 校验步骤名称唯一，且只依赖排在前面的步骤（保证无环，逆序即可补偿）
+func validateStepDefinitions(stepDefs []StepDefinition) error {
+	seen := make(map[string]bool, len(stepDefs))
+	for _, def := range stepDefs {
+		if def.Name == "" {
+			return fmt.Errorf("saga step name is required")
+		}
+		if strings.Contains(def.Name, ",") {
+			return fmt.Errorf("saga step name %q must not contain ','", def.Name)
+		}
+		if seen[def.Name] {
+			return fmt.Errorf("duplicate saga step name %q", def.Name)
+		}
+		for _, dep := range def.DependsOn {
+			if !seen[dep] {
+				return fmt.Errorf("saga step %q depends on %q, which must be defined before it", def.Name, dep)
+			}
+		}
+		seen[def.Name] = true
+	}
+	return nil
+}
+
+// stepOutputs 已完成步骤的输出
+func (s *Saga) stepOutputs() map[string]string {
+	outputs := make(map[string]string, len(s.Steps))
+	for _, step := range s.Steps {
+		if step.Status == StepStatusCompleted {
+			outputs[step.StepName] = step.Result
+		}
+	}
+	return outputs
+}
+
+// completedSteps 已完成的步骤数
+func (s *Saga) completedSteps() int {
+	count := 0
+	for _, step := range s.Steps {
+		if step.Status == StepStatusCompleted {
+			count++
+		}
+	}
+	return count
+}
+
+// nextStepRetryAt 失败步骤中最晚的重试时间（恢复工作器据此等待步骤退避结束）
+func (s *Saga) nextStepRetryAt() *time.Time {
+	var next *time.Time
+	for _, step := range s.Steps {
+		if step.Status != StepStatusFailed || step.NextRetryAt == nil {
+			continue
+		}
+		if next == nil || step.NextRetryAt.After(*next) {
+			next = step.NextRetryAt
+		}
+	}
+	return next
+}
+
+// readySteps 依赖都已完成、自身未完成的步骤下标
+func (s *Saga) readySteps(stepDefs []StepDefinition) []int {
+	completed := make(map[string]bool, len(s.Steps))
+	for _, step := range s.Steps {
+		if step.Status == StepStatusCompleted {
+			completed[step.StepName] = true
+		}
+	}
+
+	var ready []int
+	for i, step := range s.Steps {
+		if step.Status == StepStatusCompleted {
+			continue
+		}
+		satisfied := true
+		for _, dep := range stepDefs[i].DependsOn {
+			if !completed[dep] {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			ready = append(ready, i)
+		}
+	}
+	return ready
+}
+
+// startStep 记录步骤开始执行时间（便于排查进程在步骤中途崩溃的情况）
+func (o *SagaOrchestrator) startStep(step *SagaStep) {
+	now := time.Now()
+	step.StartedAt = &now
+	if err := o.db.Model(&SagaStep{}).Where("id = ?", step.ID).UpdateColumn("started_at", now).Error; err != nil {
+		logger.Warn("failed to record saga step start",
+			zap.String("step_id", step.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// runStep 执行单个步骤（带超时控制），上下文中携带步骤幂等键与已完成步骤的输出
+func (o *SagaOrchestrator) runStep(ctx context.Context, saga *Saga, step *SagaStep, stepDef StepDefinition, outputs map[string]string) (string, error) {
+	stepStartTime := time.Now()
+	stepCtx := context.WithValue(idempotency.WithKey(ctx, step.idempotencyKey()), stepOutputsKey{}, outputs)
+
+	var result string
+	var err error
+
+	if stepDef.Timeout > 0 {
+		// 使用超时上下文
+		executeCtx, cancel := context.WithTimeout(stepCtx, stepDef.Timeout)
+		defer cancel()
+
+		// 在 goroutine 中执行，以便支持超时
+		resultChan := make(chan struct {
+			result string
+			err    error
+		}, 1)
+
+		go func() {
+			r, e := stepDef.Execute(executeCtx, step.ExecuteData)
+			resultChan <- struct {
+				result string
+				err    error
+			}{r, e}
+		}()
+
+		select {
+		case res := <-resultChan:
+			result = res.result
+			err = res.err
+		case <-executeCtx.Done():
+			err = fmt.Errorf("step execution timeout after %v", stepDef.Timeout)
+			logger.Error("saga step execution timeout",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("step_name", step.StepName),
+				zap.Duration("timeout", stepDef.Timeout))
+		}
+	} else {
+		// 无超时限制
+		result, err = stepDef.Execute(stepCtx, step.ExecuteData)
+	}
+
+	// 记录步骤执行指标
+	if o.metrics != nil {
+		status := "completed"
+		if err != nil {
+			status = "failed"
+		}
+		o.metrics.RecordStepExecution(step.StepName, status, time.Since(stepStartTime))
+	}
+
+	return result, err
+}
+
+// completeStep 标记步骤执行成功并持久化结果
+func (o *SagaOrchestrator) completeStep(saga *Saga, step *SagaStep, result string) error {
+	now := time.Now()
+	step.Status = StepStatusCompleted
+	step.Result = result
+	step.ErrorMessage = ""
+	step.ExecutedAt = &now
+	step.NextRetryAt = nil
+	step.UpdatedAt = now
+
+	if err := o.db.Save(step).Error; err != nil {
+		return fmt.Errorf("save step failed: %w", err)
+	}
+
+	logger.Info("saga step completed",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("step_name", step.StepName))
+	return nil
+}
+
+// handleStepFailures 处理失败的步骤：未达最大重试次数的安排重试，任一步骤重试耗尽则开始补偿
+func (o *SagaOrchestrator) handleStepFailures(ctx context.Context, saga *Saga, stepDefs []StepDefinition, steps []*SagaStep, errs []error) error {
+	now := time.Now()
+	exhausted := false
+	var saveErr error
+
+	for i, step := range steps {
+		err := errs[i]
+		step.Status = StepStatusFailed
+		step.ErrorMessage = err.Error()
+		step.RetryCount++
+		step.UpdatedAt = now
+
+		if step.RetryCount < step.MaxRetryCount {
+			// 计算下次重试时间（指数退避）
+			nextRetry := now.Add(time.Duration(1<<uint(step.RetryCount)) * time.Second)
+			step.NextRetryAt = &nextRetry
+			logger.Warn("saga step failed, will retry",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("step_name", step.StepName),
+				zap.Int("retry_count", step.RetryCount),
+				zap.Time("next_retry", nextRetry),
+				zap.Error(err))
+		} else {
+			// 达到最大重试次数，开始补偿
+			logger.Error("saga step failed after max retries, starting compensation",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("step_name", step.StepName),
+				zap.Error(err))
+			saga.ErrorMessage = fmt.Sprintf("步骤 %s 失败: %v", step.StepName, err)
+			exhausted = true
+		}
+
+		if err := o.db.Save(step).Error; err != nil {
+			logger.Error("failed to save step", zap.Error(err))
+			saveErr = err
+		}
+	}
+
+	if exhausted {
+		// 开始补偿流程
+		return o.Compensate(ctx, saga, stepDefs)
+	}
+	if saveErr != nil {
+		return fmt.Errorf("save step failed: %w", saveErr)
+	}
+	if len(steps) == 1 {
+		return fmt.Errorf("step %s failed: %w", steps[0].StepName, errs[0])
+	}
+
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.StepName
+	}
+	return fmt.Errorf("steps %s failed: %w", strings.Join(names, ", "), errors.Join(errs...))
+}
+
+// executeDAG 按依赖关系分批执行步骤：每批并行执行依赖都已完成的步骤，整批结束后再调度下一批
+//
+// finished 为 true 表示所有步骤都已完成；步骤失败转入重试或补偿时为 false，err 为 handleStepFailures 的结果。
+func (o *SagaOrchestrator) executeDAG(ctx context.Context, saga *Saga, stepDefs []StepDefinition) (finished bool, err error) {
+	for {
+		ready := saga.readySteps(stepDefs)
+		if len(ready) == 0 {
+			break
+		}
+
+		names := make([]string, len(ready))
+		for j, i := range ready {
+			names[j] = saga.Steps[i].StepName
+			o.startStep(&saga.Steps[i])
+		}
+		logger.Info("executing saga steps in parallel",
+			zap.String("saga_id", saga.ID.String()),
+			zap.Strings("steps", names))
+
+		// 同一批步骤看到的是批次开始前已完成步骤的输出
+		outputs := saga.stepOutputs()
+		results := make([]string, len(ready))
+		errs := make([]error, len(ready))
+
+		var wg sync.WaitGroup
+		for j, i := range ready {
+			wg.Add(1)
+			go func(j, i int) {
+				defer wg.Done()
+				results[j], errs[j] = o.runStep(ctx, saga, &saga.Steps[i], stepDefs[i], outputs)
+			}(j, i)
+		}
+		wg.Wait()
+
+		// 先持久化成功的步骤，确保随后的补偿能覆盖同批已完成的步骤
+		var failedSteps []*SagaStep
+		var failedErrs []error
+		for j, i := range ready {
+			step := &saga.Steps[i]
+			if errs[j] != nil {
+				failedSteps = append(failedSteps, step)
+				failedErrs = append(failedErrs, errs[j])
+				continue
+			}
+			if err := o.completeStep(saga, step, results[j]); err != nil {
+				return false, err
+			}
+		}
+
+		// DAG 模式下 CurrentStep 表示已完成的步骤数
+		saga.CurrentStep = saga.completedSteps()
+		saga.UpdatedAt = time.Now()
+		if err := o.db.Save(saga).Error; err != nil {
+			return false, fmt.Errorf("update saga current step failed: %w", err)
+		}
+
+		if len(failedSteps) > 0 {
+			return false, o.handleStepFailures(ctx, saga, stepDefs, failedSteps, failedErrs)
+		}
+	}
+
+	if saga.completedSteps() != len(saga.Steps) {
+		return false, fmt.Errorf("saga has steps with unsatisfiable dependencies")
+	}
+	return true, nil
+}
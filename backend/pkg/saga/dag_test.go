@@ -0,0 +1,125 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDAGRunsIndependentStepsInParallel(t *testing.T) {
+	o := newTestOrchestrator(t)
+	ctx := context.Background()
+
+	// Notify 与 Publish 都只依赖 Reserve：两者必须同时在执行中才能通过屏障
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	waitBoth := func(ctx context.Context) error {
+		barrier.Done()
+		done := make(chan struct{})
+		go func() { barrier.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("steps did not run in parallel")
+		}
+	}
+
+	var mu sync.Mutex
+	keys := map[string]string{}
+	stepDefs := []StepDefinition{
+		{Name: "Reserve", Execute: func(ctx context.Context, data string) (string, error) {
+			return "reservation-1", nil
+		}},
+		{Name: "Notify", DependsOn: []string{"Reserve"}, Execute: func(ctx context.Context, data string) (string, error) {
+			mu.Lock()
+			keys["Notify"] = StepIdempotencyKey(ctx)
+			mu.Unlock()
+			return "notified:" + StepOutput(ctx, "Reserve"), waitBoth(ctx)
+		}},
+		{Name: "Publish", DependsOn: []string{"Reserve"}, Execute: func(ctx context.Context, data string) (string, error) {
+			mu.Lock()
+			keys["Publish"] = StepIdempotencyKey(ctx)
+			mu.Unlock()
+			return "published", waitBoth(ctx)
+		}},
+		{Name: "Confirm", DependsOn: []string{"Notify", "Publish"}, Execute: func(ctx context.Context, data string) (string, error) {
+			return StepOutput(ctx, "Notify") + "+" + StepOutput(ctx, "Publish"), nil
+		}},
+	}
+
+	builder := o.NewSagaBuilder("BIZ-DAG", "dag")
+	for _, def := range stepDefs {
+		builder.AddStepDefinition(def)
+	}
+	saga, err := builder.Build(ctx)
+	require.NoError(t, err)
+	require.NoError(t, o.Execute(ctx, saga, stepDefs))
+
+	latest, err := o.GetSaga(ctx, saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusCompleted, latest.Status)
+	assert.Equal(t, "notified:reservation-1+published", latest.StepResult("Confirm"))
+	assert.Equal(t, []string{"Notify", "Publish"}, latest.Steps[3].Dependencies())
+	assert.Equal(t, latest.Steps[1].IdempotencyKey, keys["Notify"])
+	assert.NotEqual(t, keys["Notify"], keys["Publish"])
+
+	timeline := BuildTimeline(latest)
+	assert.Equal(t, "dag", timeline.Mode)
+	assert.Len(t, timeline.Steps, 4)
+	assert.Contains(t, timeline.Mermaid, "gantt")
+}
+
+func TestDAGCompensatesCompletedSiblingsWhenStepFails(t *testing.T) {
+	o := newTestOrchestrator(t)
+	ctx := context.Background()
+
+	var compensated []string
+	var mu sync.Mutex
+	compensate := func(name string) CompensateFunc {
+		return func(ctx context.Context, data, result string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			compensated = append(compensated, name)
+			return nil
+		}
+	}
+	ok := func(ctx context.Context, data string) (string, error) { return "ok", nil }
+
+	stepDefs := []StepDefinition{
+		{Name: "Reserve", Execute: ok, Compensate: compensate("Reserve"), MaxRetryCount: 1},
+		{Name: "Notify", DependsOn: []string{"Reserve"}, Execute: ok, Compensate: compensate("Notify"), MaxRetryCount: 1},
+		{Name: "Charge", DependsOn: []string{"Reserve"}, Execute: func(ctx context.Context, data string) (string, error) {
+			return "", errors.New("declined")
+		}, Compensate: compensate("Charge"), MaxRetryCount: 1},
+	}
+
+	builder := o.NewSagaBuilder("BIZ-DAG-FAIL", "dag")
+	for _, def := range stepDefs {
+		builder.AddStepDefinition(def)
+	}
+	saga, err := builder.Build(ctx)
+	require.NoError(t, err)
+	require.NoError(t, o.Execute(ctx, saga, stepDefs))
+
+	latest, err := o.GetSaga(ctx, saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusCompensated, latest.Status)
+	assert.Equal(t, []string{"Notify", "Reserve"}, compensated)
+}
+
+func TestBuildRejectsForwardDependencies(t *testing.T) {
+	o := newTestOrchestrator(t)
+	noop := func(ctx context.Context, data string) (string, error) { return "", nil }
+
+	_, err := o.NewSagaBuilder("BIZ-BAD", "dag").
+		AddStepDefinition(StepDefinition{Name: "A", DependsOn: []string{"B"}, Execute: noop}).
+		AddStepDefinition(StepDefinition{Name: "B", Execute: noop}).
+		Build(context.Background())
+	assert.Error(t, err)
+}
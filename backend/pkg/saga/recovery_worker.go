@@ -11,7 +11,7 @@ import (
 	"go.uber.org/zap"
 )
 
-// RecoveryWorker Saga 恢复工作器（处理失败的补偿）
+// RecoveryWorker Saga 恢复工作器（续跑中断的 Saga、重试失败的补偿）
 type RecoveryWorker struct {
 	orchestrator *SagaOrchestrator
 	interval     time.Duration
@@ -53,6 +53,18 @@ func (w *RecoveryWorker) Stop() {
 	logger.Info("saga recovery worker stopped")
 }
 
+// 恢复策略
+const (
+	// staleAfter pending/in_progress 超过该时间未更新视为中断（大于执行锁的 5 分钟 TTL）
+	staleAfter = 10 * time.Minute
+	// maxRecoveryAttempts 自动恢复的最大次数，超过后进入死信队列
+	maxRecoveryAttempts = 5
+	// maxRecoveryBackoff 恢复退避上限
+	maxRecoveryBackoff = 6 * time.Hour
+	// dlqAge 失败超过该时间直接进入死信队列
+	dlqAge = 3 * 24 * time.Hour
+)
+
 // run 运行工作器主循环
 func (w *RecoveryWorker) run(ctx context.Context) {
 	defer w.wg.Done()
@@ -61,12 +73,12 @@ func (w *RecoveryWorker) run(ctx context.Context) {
 	defer ticker.Stop()
 
 	// 启动时立即执行一次
-	w.processFailedSagas(ctx)
+	w.processOnce(ctx)
 
 	for {
 		select {
 		case <-ticker.C:
-			w.processFailedSagas(ctx)
+			w.processOnce(ctx)
 		case <-w.stopChan:
 			return
 		case <-ctx.Done():
@@ -75,19 +87,73 @@ func (w *RecoveryWorker) run(ctx context.Context) {
 	}
 }
 
-// processFailedSagas 处理失败的 Saga
-func (w *RecoveryWorker) processFailedSagas(ctx context.Context) {
-	logger.Info("processing failed sagas", zap.Int("batch_size", w.batchSize))
+// processOnce 执行一轮恢复：先续跑中断的 Saga，再重试失败的补偿
+func (w *RecoveryWorker) processOnce(ctx context.Context) {
+	w.processInterruptedSagas(ctx)
+	w.processFailedSagas(ctx)
+}
+
+// processInterruptedSagas 续跑进程崩溃或等待重试而中断的 Saga
+func (w *RecoveryWorker) processInterruptedSagas(ctx context.Context) {
+	sagas, err := w.orchestrator.ListInterruptedSagas(ctx, staleAfter, w.batchSize)
+	if err != nil {
+		logger.Error("failed to list interrupted sagas", zap.Error(err))
+		return
+	}
+	if len(sagas) == 0 {
+		return
+	}
+
+	logger.Info("found interrupted sagas to resume", zap.Int("count", len(sagas)))
+
+	successCount := 0
+	failureCount := 0
+	now := time.Now()
+
+	for _, saga := range sagas {
+		if saga.RecoveryAttempts >= maxRecoveryAttempts {
+			if err := w.moveToDLQ(ctx, saga); err != nil {
+				logger.Error("failed to move saga to DLQ",
+					zap.String("saga_id", saga.ID.String()),
+					zap.Error(err))
+			}
+			failureCount++
+			continue
+		}
+
+		// 失败步骤的重试时间未到，等待下一轮
+		if next := saga.nextStepRetryAt(); next != nil && next.After(now) {
+			continue
+		}
+
+		if err := w.orchestrator.Resume(ctx, saga.ID); err != nil {
+			logger.Warn("failed to resume saga",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("business_id", saga.BusinessID),
+				zap.String("business_type", saga.BusinessType),
+				zap.Int("recovery_attempts", saga.RecoveryAttempts+1),
+				zap.Error(err))
+			w.orchestrator.scheduleRecovery(ctx, saga, w.backoff(saga.RecoveryAttempts), err)
+			failureCount++
+			continue
+		}
+		successCount++
+	}
 
-	// 获取失败的 Saga 列表
-	failedSagas, err := w.orchestrator.ListFailedSagas(ctx, w.batchSize)
+	logger.Info("interrupted saga processing completed",
+		zap.Int("success", successCount),
+		zap.Int("failure", failureCount))
+}
+
+// processFailedSagas 重试补偿失败的 Saga，多次失败后移入死信队列
+func (w *RecoveryWorker) processFailedSagas(ctx context.Context) {
+	failedSagas, err := w.orchestrator.ListRecoverableFailedSagas(ctx, w.batchSize)
 	if err != nil {
 		logger.Error("failed to list failed sagas", zap.Error(err))
 		return
 	}
 
 	if len(failedSagas) == 0 {
-		logger.Info("no failed sagas to process")
 		return
 	}
 
@@ -108,19 +174,25 @@ func (w *RecoveryWorker) processFailedSagas(ctx context.Context) {
 			continue
 		}
 
-		// 尝试重试补偿（注意：这里需要stepDefs，实际使用时需要从业务层传入）
-		// 这是一个示例，实际实现需要根据业务类型获取对应的stepDefs
-		logger.Warn("saga recovery requires business-specific step definitions",
-			zap.String("saga_id", saga.ID.String()),
-			zap.String("business_id", saga.BusinessID),
-			zap.String("business_type", saga.BusinessType))
-
-		// 实际项目中，可以：
-		// 1. 发送到 Kafka 队列，由对应的业务服务消费
-		// 2. 调用业务服务的 API 触发重试
-		// 3. 使用注册表模式，根据 business_type 获取对应的 stepDefs
-
-		failureCount++
+		// 按业务类型从注册表获取步骤定义，重试补偿
+		err := w.orchestrator.RetryCompensation(ctx, saga.ID)
+		if err == nil {
+			if latest, getErr := w.orchestrator.GetSaga(ctx, saga.ID); getErr == nil && latest.Status == SagaStatusFailed {
+				err = fmt.Errorf("%s", latest.ErrorMessage)
+			}
+		}
+		if err != nil {
+			logger.Warn("saga compensation retry failed",
+				zap.String("saga_id", saga.ID.String()),
+				zap.String("business_id", saga.BusinessID),
+				zap.String("business_type", saga.BusinessType),
+				zap.Int("recovery_attempts", saga.RecoveryAttempts+1),
+				zap.Error(err))
+			w.orchestrator.scheduleRecovery(ctx, saga, w.backoff(saga.RecoveryAttempts), err)
+			failureCount++
+			continue
+		}
+		successCount++
 	}
 
 	logger.Info("failed saga processing completed",
@@ -128,23 +200,22 @@ func (w *RecoveryWorker) processFailedSagas(ctx context.Context) {
 		zap.Int("failure", failureCount))
 }
 
-// shouldMoveToDLQ 判断是否应该移动到死信队列
-func (w *RecoveryWorker) shouldMoveToDLQ(saga *Saga) bool {
-	// 失败超过3天，或者补偿失败次数过多
-	if time.Since(saga.UpdatedAt) > 3*24*time.Hour {
-		return true
+// backoff 第 attempts 次恢复失败后的等待时间（扫描间隔的指数倍，封顶 6 小时）
+func (w *RecoveryWorker) backoff(attempts int) time.Duration {
+	backoff := w.interval
+	for i := 0; i < attempts && backoff < maxRecoveryBackoff; i++ {
+		backoff *= 2
 	}
-
-	// 统计补偿失败的步骤数
-	failedCompensationCount := 0
-	for _, step := range saga.Steps {
-		if step.Status == StepStatusFailed && step.RetryCount >= 3 {
-			failedCompensationCount++
-		}
+	if backoff > maxRecoveryBackoff {
+		backoff = maxRecoveryBackoff
 	}
+	return backoff
+}
 
-	// 如果所有需要补偿的步骤都失败了，移动到 DLQ
-	return failedCompensationCount >= saga.CurrentStep
+// shouldMoveToDLQ 判断是否应该移动到死信队列
+func (w *RecoveryWorker) shouldMoveToDLQ(saga *Saga) bool {
+	// 自动恢复次数用尽，或失败时间过长
+	return saga.RecoveryAttempts >= maxRecoveryAttempts || time.Since(saga.CreatedAt) > dlqAge
 }
 
 // moveToDLQ 移动到死信队列
@@ -182,7 +253,8 @@ func (w *RecoveryWorker) moveToDLQ(ctx context.Context, saga *Saga) error {
 		}
 	}
 
-	// 更新数据库记录的错误信息
+	// 更新数据库记录状态，不再参与自动恢复
+	saga.Status = SagaStatusDeadLetter
 	saga.ErrorMessage = fmt.Sprintf("[DLQ] %s (moved to dead letter queue at %s)",
 		saga.ErrorMessage, time.Now().Format(time.RFC3339))
 	if err := w.orchestrator.db.Save(saga).Error; err != nil {
@@ -0,0 +1,295 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// StepDefinitionFactory 根据持久化的 Saga 实例重建步骤定义
+//
+// 恢复时进程内已没有原始业务对象，工厂需要根据 BusinessID / Metadata / 已完成步骤的 Result
+// 重新加载业务数据并构造与创建时顺序一致的步骤定义。
+type StepDefinitionFactory func(ctx context.Context, saga *Saga) ([]StepDefinition, error)
+
+// Registry 按业务类型注册的步骤定义工厂
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]StepDefinitionFactory
+}
+
+// NewRegistry 创建步骤定义注册表
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]StepDefinitionFactory)}
+}
+
+// Register 注册业务类型的步骤定义工厂（重复注册会覆盖）
+func (r *Registry) Register(businessType string, factory StepDefinitionFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[businessType] = factory
+}
+
+// Get 获取业务类型的步骤定义工厂
+func (r *Registry) Get(businessType string) (StepDefinitionFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.factories[businessType]
+	return factory, ok
+}
+
+// BusinessTypes 已注册的业务类型
+func (r *Registry) BusinessTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.factories))
+	for businessType := range r.factories {
+		types = append(types, businessType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// RegisterStepDefinitions 注册业务类型的步骤定义工厂，恢复工作器和管理接口据此续跑或补偿 Saga
+func (o *SagaOrchestrator) RegisterStepDefinitions(businessType string, factory StepDefinitionFactory) {
+	o.registry.Register(businessType, factory)
+	logger.Info("saga step definitions registered", zap.String("business_type", businessType))
+}
+
+// Registry 获取步骤定义注册表
+func (o *SagaOrchestrator) Registry() *Registry {
+	return o.registry
+}
+
+// StepDefinitionsFor 通过注册表重建 Saga 的步骤定义，并校验与持久化步骤一致
+func (o *SagaOrchestrator) StepDefinitionsFor(ctx context.Context, saga *Saga) ([]StepDefinition, error) {
+	factory, ok := o.registry.Get(saga.BusinessType)
+	if !ok {
+		return nil, fmt.Errorf("no step definitions registered for business type %q", saga.BusinessType)
+	}
+
+	stepDefs, err := factory(ctx, saga)
+	if err != nil {
+		return nil, fmt.Errorf("build step definitions failed: %w", err)
+	}
+	if err := validateStepDefinitions(stepDefs); err != nil {
+		return nil, err
+	}
+	if len(stepDefs) != len(saga.Steps) {
+		return nil, fmt.Errorf("step definitions mismatch: registered %d steps, saga has %d", len(stepDefs), len(saga.Steps))
+	}
+	for i := range saga.Steps {
+		if stepDefs[i].Name != saga.Steps[i].StepName {
+			return nil, fmt.Errorf("step definitions mismatch at %d: registered %s, saga has %s",
+				i, stepDefs[i].Name, saga.Steps[i].StepName)
+		}
+	}
+	return stepDefs, nil
+}
+
+// StepResult 获取已完成步骤的执行结果（供步骤定义工厂恢复跨步骤传递的数据）
+func (s *Saga) StepResult(stepName string) string {
+	for _, step := range s.Steps {
+		if step.StepName == stepName && step.Status == StepStatusCompleted {
+			return step.Result
+		}
+	}
+	return ""
+}
+
+// Resume 从 CurrentStep 续跑中断的 Saga（进程崩溃或步骤等待重试）
+func (o *SagaOrchestrator) Resume(ctx context.Context, sagaID uuid.UUID) error {
+	saga, err := o.GetSaga(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get saga: %w", err)
+	}
+	if saga.Status != SagaStatusPending && saga.Status != SagaStatusInProgress {
+		return fmt.Errorf("saga cannot be resumed in %s state", saga.Status)
+	}
+
+	stepDefs, err := o.StepDefinitionsFor(ctx, saga)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("resuming saga",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("business_id", saga.BusinessID),
+		zap.String("business_type", saga.BusinessType),
+		zap.Int("current_step", saga.CurrentStep))
+
+	return o.Execute(ctx, saga, stepDefs)
+}
+
+// RetryCompensation 使用注册的步骤定义重试失败的补偿
+func (o *SagaOrchestrator) RetryCompensation(ctx context.Context, sagaID uuid.UUID) error {
+	saga, err := o.GetSaga(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get saga: %w", err)
+	}
+	stepDefs, err := o.StepDefinitionsFor(ctx, saga)
+	if err != nil {
+		return err
+	}
+	return o.RetryFailedCompensation(ctx, sagaID, stepDefs)
+}
+
+// Abort 人工终止 Saga：停止前向执行并补偿已完成的步骤
+func (o *SagaOrchestrator) Abort(ctx context.Context, sagaID uuid.UUID, reason string) error {
+	saga, err := o.GetSaga(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get saga: %w", err)
+	}
+	switch saga.Status {
+	case SagaStatusPending, SagaStatusInProgress, SagaStatusFailed, SagaStatusDeadLetter:
+	default:
+		return fmt.Errorf("saga cannot be aborted in %s state", saga.Status)
+	}
+
+	stepDefs, err := o.StepDefinitionsFor(ctx, saga)
+	if err != nil {
+		return err
+	}
+
+	// 与正在执行的前向流程互斥
+	if o.redis != nil {
+		lockKey := fmt.Sprintf("saga:lock:%s", saga.ID.String())
+		acquired, err := o.acquireLock(ctx, lockKey, 5*time.Minute)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if !acquired {
+			return fmt.Errorf("saga is being executed by another process")
+		}
+		defer o.releaseLock(ctx, lockKey)
+	}
+
+	logger.Warn("aborting saga manually",
+		zap.String("saga_id", saga.ID.String()),
+		zap.String("business_id", saga.BusinessID),
+		zap.String("reason", reason))
+
+	saga.ErrorMessage = fmt.Sprintf("人工终止: %s", reason)
+	return o.Compensate(ctx, saga, stepDefs)
+}
+
+// Requeue 将死信队列中的 Saga 放回失败状态，重新交给恢复工作器处理
+func (o *SagaOrchestrator) Requeue(ctx context.Context, sagaID uuid.UUID) error {
+	result := o.db.WithContext(ctx).Model(&Saga{}).
+		Where("id = ? AND status = ?", sagaID, SagaStatusDeadLetter).
+		Updates(map[string]interface{}{
+			"status":            SagaStatusFailed,
+			"recovery_attempts": 0,
+			"next_recovery_at":  nil,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("requeue saga failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saga is not in dead letter queue")
+	}
+	return nil
+}
+
+// SagaFilter Saga 查询条件
+type SagaFilter struct {
+	Status       SagaStatus
+	BusinessType string
+	BusinessID   string
+	Limit        int
+	Offset       int
+}
+
+// ListSagas 按条件查询 Saga（按更新时间倒序）
+func (o *SagaOrchestrator) ListSagas(ctx context.Context, filter SagaFilter) ([]*Saga, int64, error) {
+	query := o.db.WithContext(ctx).Model(&Saga{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.BusinessType != "" {
+		query = query.Where("business_type = ?", filter.BusinessType)
+	}
+	if filter.BusinessID != "" {
+		query = query.Where("business_id = ?", filter.BusinessID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var sagas []*Saga
+	err := query.Preload("Steps", orderSteps).
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(filter.Offset).
+		Find(&sagas).Error
+	return sagas, total, err
+}
+
+// ListInterruptedSagas 列出中断的 Saga：pending/in_progress 且超过 staleAfter 未更新，且已到恢复时间
+func (o *SagaOrchestrator) ListInterruptedSagas(ctx context.Context, staleAfter time.Duration, limit int) ([]*Saga, error) {
+	var sagas []*Saga
+	now := time.Now()
+
+	err := o.db.WithContext(ctx).Preload("Steps", orderSteps).
+		Where("status IN ?", []SagaStatus{SagaStatusPending, SagaStatusInProgress}).
+		Where("updated_at < ?", now.Add(-staleAfter)).
+		Where("next_recovery_at IS NULL OR next_recovery_at <= ?", now).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&sagas).Error
+
+	return sagas, err
+}
+
+// ListRecoverableFailedSagas 列出已到恢复时间的补偿失败 Saga
+func (o *SagaOrchestrator) ListRecoverableFailedSagas(ctx context.Context, limit int) ([]*Saga, error) {
+	var sagas []*Saga
+
+	err := o.db.WithContext(ctx).Preload("Steps", orderSteps).
+		Where("status = ?", SagaStatusFailed).
+		Where("next_recovery_at IS NULL OR next_recovery_at <= ?", time.Now()).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&sagas).Error
+
+	return sagas, err
+}
+
+// scheduleRecovery 记录一次自动恢复失败并按指数退避安排下次恢复（只更新恢复字段，避免覆盖执行状态）
+func (o *SagaOrchestrator) scheduleRecovery(ctx context.Context, saga *Saga, backoff time.Duration, lastErr error) {
+	saga.RecoveryAttempts++
+	next := time.Now().Add(backoff)
+	saga.NextRecoveryAt = &next
+
+	updates := map[string]interface{}{
+		"recovery_attempts": saga.RecoveryAttempts,
+		"next_recovery_at":  next,
+	}
+	if lastErr != nil {
+		updates["error_message"] = lastErr.Error()
+	}
+	if err := o.db.WithContext(ctx).Model(&Saga{}).Where("id = ?", saga.ID).UpdateColumns(updates).Error; err != nil {
+		logger.Error("failed to schedule saga recovery",
+			zap.String("saga_id", saga.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// orderSteps 步骤按执行顺序加载
+func orderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("step_order ASC")
+}
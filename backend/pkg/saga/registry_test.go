@@ -0,0 +1,114 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/payment-platform/pkg/logger"
+)
+
+// newTestOrchestrator 不注册 Prometheus 指标，避免重复注册
+func newTestOrchestrator(t *testing.T) *SagaOrchestrator {
+	logger.Log = zap.NewNop()
+	return &SagaOrchestrator{db: setupTestDB(t), registry: NewRegistry()}
+}
+
+// buildInterruptedSaga 构建一个第一步已完成、进程崩溃后停留在 in_progress 的 Saga
+func buildInterruptedSaga(t *testing.T, o *SagaOrchestrator, businessType string) *Saga {
+	ctx := context.Background()
+	noop := func(ctx context.Context, data string) (string, error) { return "", nil }
+	saga, err := o.NewSagaBuilder("BIZ-"+businessType, businessType).
+		AddStep("Reserve", noop, nil, 1).
+		AddStep("Confirm", noop, nil, 1).
+		Build(ctx)
+	require.NoError(t, err)
+
+	stale := time.Now().Add(-time.Hour)
+	saga.Steps[0].Status = StepStatusCompleted
+	saga.Steps[0].Result = "reservation-1"
+	require.NoError(t, o.db.Save(&saga.Steps[0]).Error)
+	require.NoError(t, o.db.Model(&Saga{}).Where("id = ?", saga.ID).UpdateColumns(map[string]interface{}{
+		"status":       SagaStatusInProgress,
+		"current_step": 1,
+		"updated_at":   stale,
+	}).Error)
+	return saga
+}
+
+func TestRecoveryWorkerResumesInterruptedSaga(t *testing.T) {
+	o := newTestOrchestrator(t)
+	saga := buildInterruptedSaga(t, o, "payment")
+
+	var confirmed string
+	o.RegisterStepDefinitions("payment", func(ctx context.Context, s *Saga) ([]StepDefinition, error) {
+		reservation := s.StepResult("Reserve")
+		return []StepDefinition{
+			{Name: "Reserve", Execute: func(ctx context.Context, data string) (string, error) {
+				return "", errors.New("已完成的步骤不应重复执行")
+			}},
+			{Name: "Confirm", Execute: func(ctx context.Context, data string) (string, error) {
+				confirmed = reservation
+				return "ok", nil
+			}, MaxRetryCount: 1},
+		}, nil
+	})
+
+	NewRecoveryWorker(o, time.Minute, 10).processOnce(context.Background())
+
+	latest, err := o.GetSaga(context.Background(), saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusCompleted, latest.Status)
+	assert.Equal(t, "reservation-1", confirmed)
+	assert.Equal(t, "ok", latest.StepResult("Confirm"))
+}
+
+func TestRecoveryWorkerBacksOffThenMovesToDLQ(t *testing.T) {
+	o := newTestOrchestrator(t)
+	saga := buildInterruptedSaga(t, o, "unregistered")
+	worker := NewRecoveryWorker(o, time.Minute, 10)
+
+	// 未注册步骤定义：记录恢复失败并安排退避
+	worker.processOnce(context.Background())
+	latest, err := o.GetSaga(context.Background(), saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusInProgress, latest.Status)
+	assert.Equal(t, 1, latest.RecoveryAttempts)
+	require.NotNil(t, latest.NextRecoveryAt)
+	assert.True(t, latest.NextRecoveryAt.After(time.Now()))
+
+	// 恢复次数用尽后进入死信队列
+	require.NoError(t, o.db.Model(&Saga{}).Where("id = ?", saga.ID).UpdateColumns(map[string]interface{}{
+		"recovery_attempts": maxRecoveryAttempts,
+		"next_recovery_at":  nil,
+	}).Error)
+	worker.processOnce(context.Background())
+	latest, err = o.GetSaga(context.Background(), saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusDeadLetter, latest.Status)
+
+	require.NoError(t, o.Requeue(context.Background(), saga.ID))
+	latest, err = o.GetSaga(context.Background(), saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SagaStatusFailed, latest.Status)
+	assert.Zero(t, latest.RecoveryAttempts)
+}
+
+func TestStepDefinitionsForValidatesSteps(t *testing.T) {
+	o := newTestOrchestrator(t)
+	saga := buildInterruptedSaga(t, o, "refund")
+	o.RegisterStepDefinitions("refund", func(ctx context.Context, s *Saga) ([]StepDefinition, error) {
+		return []StepDefinition{{Name: "Reserve"}, {Name: "Other"}}, nil
+	})
+
+	latest, err := o.GetSaga(context.Background(), saga.ID)
+	require.NoError(t, err)
+	_, err = o.StepDefinitionsFor(context.Background(), latest)
+	assert.Error(t, err)
+	assert.Error(t, o.Resume(context.Background(), saga.ID))
+}
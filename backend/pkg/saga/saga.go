@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/idempotency"
 	"github.com/payment-platform/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -22,6 +24,7 @@ const (
 	SagaStatusCompleted   SagaStatus = "completed"    // 已完成
 	SagaStatusCompensated SagaStatus = "compensated"  // 已补偿
 	SagaStatusFailed      SagaStatus = "failed"       // 失败（补偿也失败）
+	SagaStatusDeadLetter  SagaStatus = "dead_letter"  // 自动恢复放弃，已进入死信队列等待人工处理
 )
 
 // StepStatus 步骤状态
@@ -36,26 +39,29 @@ const (
 
 // SagaOrchestrator Saga 编排器
 type SagaOrchestrator struct {
-	db      *gorm.DB
-	redis   *redis.Client
-	metrics *SagaMetrics
+	db       *gorm.DB
+	redis    *redis.Client
+	metrics  *SagaMetrics
+	registry *Registry
 }
 
 // NewSagaOrchestrator 创建 Saga 编排器
 func NewSagaOrchestrator(db *gorm.DB, redis *redis.Client) *SagaOrchestrator {
 	return &SagaOrchestrator{
-		db:      db,
-		redis:   redis,
-		metrics: NewSagaMetrics("payment_platform"),
+		db:       db,
+		redis:    redis,
+		metrics:  NewSagaMetrics("payment_platform"),
+		registry: NewRegistry(),
 	}
 }
 
 // NewSagaOrchestratorWithMetrics 创建带自定义指标的 Saga 编排器
 func NewSagaOrchestratorWithMetrics(db *gorm.DB, redis *redis.Client, metricsNamespace string) *SagaOrchestrator {
 	return &SagaOrchestrator{
-		db:      db,
-		redis:   redis,
-		metrics: NewSagaMetrics(metricsNamespace),
+		db:       db,
+		redis:    redis,
+		metrics:  NewSagaMetrics(metricsNamespace),
+		registry: NewRegistry(),
 	}
 }
 
@@ -73,6 +79,10 @@ type Saga struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 	CompletedAt   *time.Time     `json:"completed_at"`
 	CompensatedAt *time.Time     `json:"compensated_at"`
+
+	// 恢复工作器使用：自动恢复次数与下次恢复时间（指数退避）
+	RecoveryAttempts int        `json:"recovery_attempts"`
+	NextRecoveryAt   *time.Time `json:"next_recovery_at"`
 }
 
 // SagaStep Saga 步骤
@@ -82,10 +92,13 @@ type SagaStep struct {
 	StepOrder       int        `json:"step_order" gorm:"not null"` // 步骤顺序（从0开始）
 	StepName        string     `json:"step_name" gorm:"not null"`  // 步骤名称
 	Status          StepStatus `json:"status"`
+	DependsOn       string     `json:"depends_on" gorm:"type:text"`       // 依赖的步骤名称（逗号分隔，DAG 模式）
+	IdempotencyKey  string     `json:"idempotency_key" gorm:"size:255"`   // 步骤幂等键（重试时不变，随上下文传给下游）
 	ExecuteData     string     `json:"execute_data" gorm:"type:text"`     // 执行参数（JSON）
 	CompensateData  string     `json:"compensate_data" gorm:"type:text"`  // 补偿参数（JSON）
 	Result          string     `json:"result" gorm:"type:text"`           // 执行结果（JSON）
 	ErrorMessage    string     `json:"error_message"`
+	StartedAt       *time.Time `json:"started_at"`  // 最近一次开始执行时间
 	ExecutedAt      *time.Time `json:"executed_at"`
 	CompensatedAt   *time.Time `json:"compensated_at"`
 	RetryCount      int        `json:"retry_count"`
@@ -118,6 +131,11 @@ type StepDefinition struct {
 	Compensate     CompensateFunc
 	MaxRetryCount  int
 	Timeout        time.Duration // 步骤执行超时时间
+
+	// DependsOn 依赖的步骤名称（只能依赖排在前面的步骤）。
+	// Saga 中任一步骤声明了 DependsOn 即按 DAG 调度：依赖都已完成的步骤并行执行，未声明依赖的步骤作为起点；
+	// 所有步骤都未声明时保持原有的顺序执行。
+	DependsOn []string
 }
 
 // SagaBuilder Saga 构建器
@@ -173,6 +191,18 @@ func (b *SagaBuilder) AddStepWithTimeout(name string, execute StepFunc, compensa
 	return b
 }
 
+// AddStepDefinition 添加完整的步骤定义（可声明 DependsOn）
+func (b *SagaBuilder) AddStepDefinition(def StepDefinition) *SagaBuilder {
+	if def.MaxRetryCount <= 0 {
+		def.MaxRetryCount = 3 // 默认重试3次
+	}
+	if def.Timeout <= 0 {
+		def.Timeout = 30 * time.Second // 默认30秒超时
+	}
+	b.steps = append(b.steps, def)
+	return b
+}
+
 // SetMetadata 设置元数据
 func (b *SagaBuilder) SetMetadata(metadata map[string]interface{}) *SagaBuilder {
 	b.metadata = metadata
@@ -181,6 +211,10 @@ func (b *SagaBuilder) SetMetadata(metadata map[string]interface{}) *SagaBuilder
 
 // Build 构建 Saga 实例
 func (b *SagaBuilder) Build(ctx context.Context) (*Saga, error) {
+	if err := validateStepDefinitions(b.steps); err != nil {
+		return nil, err
+	}
+
 	sagaID := uuid.New()
 
 	metadataJSON := "{}"
@@ -211,15 +245,17 @@ func (b *SagaBuilder) Build(ctx context.Context) (*Saga, error) {
 	// 创建步骤记录（不保存执行函数）
 	for i := range b.steps {
 		step := &SagaStep{
-			ID:            uuid.New(),
-			SagaID:        sagaID,
-			StepOrder:     i,
-			StepName:      b.steps[i].Name,
-			Status:        StepStatusPending,
-			MaxRetryCount: b.steps[i].MaxRetryCount,
-			RetryCount:    0,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
+			ID:             uuid.New(),
+			SagaID:         sagaID,
+			StepOrder:      i,
+			StepName:       b.steps[i].Name,
+			DependsOn:      strings.Join(b.steps[i].DependsOn, ","),
+			IdempotencyKey: stepIdempotencyKey(sagaID, b.steps[i].Name),
+			Status:         StepStatusPending,
+			MaxRetryCount:  b.steps[i].MaxRetryCount,
+			RetryCount:     0,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
 		}
 		if err := b.orchestrator.db.Create(step).Error; err != nil {
 			return nil, fmt.Errorf("create saga step failed: %w", err)
@@ -227,7 +263,7 @@ func (b *SagaBuilder) Build(ctx context.Context) (*Saga, error) {
 	}
 
 	// 重新加载 Saga（包含 Steps）
-	if err := b.orchestrator.db.Preload("Steps").First(saga, "id = ?", sagaID).Error; err != nil {
+	if err := b.orchestrator.db.Preload("Steps", orderSteps).First(saga, "id = ?", sagaID).Error; err != nil {
 		return nil, fmt.Errorf("reload saga failed: %w", err)
 	}
 
@@ -280,129 +316,39 @@ func (o *SagaOrchestrator) Execute(ctx context.Context, saga *Saga, stepDefs []S
 		return fmt.Errorf("update saga status failed: %w", err)
 	}
 
-	// 执行每个步骤
-	for i := saga.CurrentStep; i < len(saga.Steps); i++ {
-		step := &saga.Steps[i]
-		stepDef := stepDefs[i]
-
-		logger.Info("executing saga step",
-			zap.String("saga_id", saga.ID.String()),
-			zap.Int("step", i),
-			zap.String("step_name", step.StepName))
-
-		// 记录步骤开始时间
-		stepStartTime := time.Now()
-
-		// 执行步骤（带超时控制）
-		var result string
-		var err error
-
-		if stepDef.Timeout > 0 {
-			// 使用超时上下文
-			executeCtx, cancel := context.WithTimeout(ctx, stepDef.Timeout)
-			defer cancel()
-
-			// 在 goroutine 中执行，以便支持超时
-			resultChan := make(chan struct {
-				result string
-				err    error
-			}, 1)
-
-			go func() {
-				r, e := stepDef.Execute(executeCtx, step.ExecuteData)
-				resultChan <- struct {
-					result string
-					err    error
-				}{r, e}
-			}()
-
-			select {
-			case res := <-resultChan:
-				result = res.result
-				err = res.err
-			case <-executeCtx.Done():
-				err = fmt.Errorf("step execution timeout after %v", stepDef.Timeout)
-				logger.Error("saga step execution timeout",
-					zap.String("saga_id", saga.ID.String()),
-					zap.String("step_name", step.StepName),
-					zap.Duration("timeout", stepDef.Timeout))
-			}
-		} else {
-			// 无超时限制
-			result, err = stepDef.Execute(ctx, step.ExecuteData)
+	// 执行步骤：声明了依赖的 Saga 按 DAG 并行调度，否则顺序执行
+	if isDAG(stepDefs) {
+		finished, err := o.executeDAG(ctx, saga, stepDefs)
+		if !finished {
+			return err
 		}
+	} else {
+		for i := saga.CurrentStep; i < len(saga.Steps); i++ {
+			step := &saga.Steps[i]
+			stepDef := stepDefs[i]
 
-		now := time.Now()
+			logger.Info("executing saga step",
+				zap.String("saga_id", saga.ID.String()),
+				zap.Int("step", i),
+				zap.String("step_name", step.StepName))
 
-		if err != nil {
-			// 记录步骤执行失败指标
-			if o.metrics != nil {
-				o.metrics.RecordStepExecution(step.StepName, "failed", time.Since(stepStartTime))
+			o.startStep(step)
+			result, err := o.runStep(ctx, saga, step, stepDef, saga.stepOutputs())
+			if err != nil {
+				return o.handleStepFailures(ctx, saga, stepDefs, []*SagaStep{step}, []error{err})
 			}
 
-			// 步骤执行失败
-			step.Status = StepStatusFailed
-			step.ErrorMessage = err.Error()
-			step.RetryCount++
-			step.UpdatedAt = now
-
-			if step.RetryCount < step.MaxRetryCount {
-				// 计算下次重试时间（指数退避）
-				nextRetry := now.Add(time.Duration(1<<uint(step.RetryCount)) * time.Second)
-				step.NextRetryAt = &nextRetry
-				logger.Warn("saga step failed, will retry",
-					zap.String("saga_id", saga.ID.String()),
-					zap.String("step_name", step.StepName),
-					zap.Int("retry_count", step.RetryCount),
-					zap.Time("next_retry", nextRetry),
-					zap.Error(err))
-			} else {
-				// 达到最大重试次数，开始补偿
-				logger.Error("saga step failed after max retries, starting compensation",
-					zap.String("saga_id", saga.ID.String()),
-					zap.String("step_name", step.StepName),
-					zap.Error(err))
-
-				saga.ErrorMessage = fmt.Sprintf("步骤 %s 失败: %v", step.StepName, err)
-				if err := o.db.Save(step).Error; err != nil {
-					logger.Error("failed to save step", zap.Error(err))
-				}
-
-				// 开始补偿流程
-				return o.Compensate(ctx, saga, stepDefs)
+			if err := o.completeStep(saga, step, result); err != nil {
+				return err
 			}
 
-			if err := o.db.Save(step).Error; err != nil {
-				return fmt.Errorf("save step failed: %w", err)
+			// 更新当前步骤
+			saga.CurrentStep = i + 1
+			saga.UpdatedAt = time.Now()
+			if err := o.db.Save(saga).Error; err != nil {
+				return fmt.Errorf("update saga current step failed: %w", err)
 			}
-			return fmt.Errorf("step %s failed: %w", step.StepName, err)
 		}
-
-		// 记录步骤执行成功指标
-		if o.metrics != nil {
-			o.metrics.RecordStepExecution(step.StepName, "completed", time.Since(stepStartTime))
-		}
-
-		// 步骤执行成功
-		step.Status = StepStatusCompleted
-		step.Result = result
-		step.ExecutedAt = &now
-		step.UpdatedAt = now
-
-		if err := o.db.Save(step).Error; err != nil {
-			return fmt.Errorf("save step failed: %w", err)
-		}
-
-		// 更新当前步骤
-		saga.CurrentStep = i + 1
-		saga.UpdatedAt = now
-		if err := o.db.Save(saga).Error; err != nil {
-			return fmt.Errorf("update saga current step failed: %w", err)
-		}
-
-		logger.Info("saga step completed",
-			zap.String("saga_id", saga.ID.String()),
-			zap.String("step_name", step.StepName))
 	}
 
 	// 所有步骤执行成功
@@ -436,8 +382,8 @@ func (o *SagaOrchestrator) Compensate(ctx context.Context, saga *Saga, stepDefs
 
 	hasFailedCompensation := false
 
-	// 按相反顺序补偿已完成的步骤
-	for i := saga.CurrentStep - 1; i >= 0; i-- {
+	// 按相反顺序补偿已完成的步骤（DAG 模式下已完成的步骤不一定连续，依赖总是排在前面，逆序即逆拓扑序）
+	for i := len(saga.Steps) - 1; i >= 0; i-- {
 		step := &saga.Steps[i]
 		if step.Status != StepStatusCompleted {
 			continue // 只补偿已完成的步骤
@@ -562,6 +508,9 @@ func (o *SagaOrchestrator) executeCompensationWithRetry(ctx context.Context, ste
 			defer cancel()
 		}
 
+		// 补偿调用使用独立的幂等键，与正向执行区分
+		compensateCtx = idempotency.WithKey(compensateCtx, step.idempotencyKey()+":compensate")
+
 		err := stepDef.Compensate(compensateCtx, step.CompensateData, step.Result)
 
 		if err == nil {
@@ -594,7 +543,7 @@ func (o *SagaOrchestrator) executeCompensationWithRetry(ctx context.Context, ste
 // GetSaga 获取 Saga
 func (o *SagaOrchestrator) GetSaga(ctx context.Context, sagaID uuid.UUID) (*Saga, error) {
 	var saga Saga
-	if err := o.db.Preload("Steps").First(&saga, "id = ?", sagaID).Error; err != nil {
+	if err := o.db.Preload("Steps", orderSteps).First(&saga, "id = ?", sagaID).Error; err != nil {
 		return nil, err
 	}
 	return &saga, nil
@@ -603,7 +552,7 @@ func (o *SagaOrchestrator) GetSaga(ctx context.Context, sagaID uuid.UUID) (*Saga
 // GetSagaByBusinessID 根据业务ID获取 Saga
 func (o *SagaOrchestrator) GetSagaByBusinessID(ctx context.Context, businessID string) (*Saga, error) {
 	var saga Saga
-	if err := o.db.Preload("Steps").Where("business_id = ?", businessID).Order("created_at DESC").First(&saga).Error; err != nil {
+	if err := o.db.Preload("Steps", orderSteps).Where("business_id = ?", businessID).Order("created_at DESC").First(&saga).Error; err != nil {
 		return nil, err
 	}
 	return &saga, nil
@@ -626,7 +575,7 @@ func (o *SagaOrchestrator) ListPendingRetries(ctx context.Context, limit int) ([
 func (o *SagaOrchestrator) ListFailedSagas(ctx context.Context, limit int) ([]*Saga, error) {
 	var sagas []*Saga
 
-	err := o.db.Preload("Steps").
+	err := o.db.Preload("Steps", orderSteps).
 		Where("status = ?", SagaStatusFailed).
 		Order("updated_at DESC").
 		Limit(limit).
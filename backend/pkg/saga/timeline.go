@@ -0,0 +1,161 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineStep 步骤时间线
+type TimelineStep struct {
+	Name           string     `json:"name"`
+	Order          int        `json:"order"`
+	DependsOn      []string   `json:"depends_on"`
+	Status         StepStatus `json:"status"`
+	IdempotencyKey string     `json:"idempotency_key"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	OffsetMs       int64      `json:"offset_ms"`   // 相对 Saga 创建时间的开始偏移
+	DurationMs     int64      `json:"duration_ms"` // 最近一次执行耗时
+	RetryCount     int        `json:"retry_count"`
+	MaxRetryCount  int        `json:"max_retry_count"`
+	NextRetryAt    *time.Time `json:"next_retry_at"`
+	CompensatedAt  *time.Time `json:"compensated_at"`
+	ErrorMessage   string     `json:"error_message"`
+}
+
+// Timeline Saga 执行时间线（排查用）
+type Timeline struct {
+	SagaID       uuid.UUID      `json:"saga_id"`
+	BusinessID   string         `json:"business_id"`
+	BusinessType string         `json:"business_type"`
+	Status       SagaStatus     `json:"status"`
+	Mode         string         `json:"mode"` // sequential / dag
+	CreatedAt    time.Time      `json:"created_at"`
+	FinishedAt   *time.Time     `json:"finished_at"`
+	DurationMs   int64          `json:"duration_ms"`
+	ErrorMessage string         `json:"error_message"`
+	Steps        []TimelineStep `json:"steps"`
+	Mermaid      string         `json:"mermaid"` // Mermaid 甘特图，可直接粘贴到 Mermaid 渲染器查看
+}
+
+// GetTimeline 获取 Saga 执行时间线
+func (o *SagaOrchestrator) GetTimeline(ctx context.Context, sagaID uuid.UUID) (*Timeline, error) {
+	saga, err := o.GetSaga(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+	return BuildTimeline(saga), nil
+}
+
+// BuildTimeline 根据持久化的步骤记录构建时间线
+func BuildTimeline(saga *Saga) *Timeline {
+	timeline := &Timeline{
+		SagaID:       saga.ID,
+		BusinessID:   saga.BusinessID,
+		BusinessType: saga.BusinessType,
+		Status:       saga.Status,
+		Mode:         "sequential",
+		CreatedAt:    saga.CreatedAt,
+		ErrorMessage: saga.ErrorMessage,
+		Steps:        make([]TimelineStep, 0, len(saga.Steps)),
+	}
+
+	switch {
+	case saga.CompensatedAt != nil:
+		timeline.FinishedAt = saga.CompensatedAt
+	case saga.CompletedAt != nil:
+		timeline.FinishedAt = saga.CompletedAt
+	}
+	if timeline.FinishedAt != nil {
+		timeline.DurationMs = timeline.FinishedAt.Sub(saga.CreatedAt).Milliseconds()
+	}
+
+	for _, step := range saga.Steps {
+		if step.DependsOn != "" {
+			timeline.Mode = "dag"
+		}
+
+		item := TimelineStep{
+			Name:           step.StepName,
+			Order:          step.StepOrder,
+			DependsOn:      step.Dependencies(),
+			Status:         step.Status,
+			IdempotencyKey: step.idempotencyKey(),
+			StartedAt:      step.StartedAt,
+			FinishedAt:     stepFinishedAt(step),
+			RetryCount:     step.RetryCount,
+			MaxRetryCount:  step.MaxRetryCount,
+			NextRetryAt:    step.NextRetryAt,
+			CompensatedAt:  step.CompensatedAt,
+			ErrorMessage:   step.ErrorMessage,
+		}
+		if item.StartedAt != nil {
+			item.OffsetMs = item.StartedAt.Sub(saga.CreatedAt).Milliseconds()
+			if item.FinishedAt != nil {
+				item.DurationMs = item.FinishedAt.Sub(*item.StartedAt).Milliseconds()
+			}
+		}
+		timeline.Steps = append(timeline.Steps, item)
+	}
+
+	timeline.Mermaid = renderMermaidGantt(timeline)
+	return timeline
+}
+
+// stepFinishedAt 步骤最近一次执行的结束时间（成功取 ExecutedAt，失败取最后更新时间）
+func stepFinishedAt(step SagaStep) *time.Time {
+	switch step.Status {
+	case StepStatusCompleted, StepStatusCompensated:
+		return step.ExecutedAt
+	case StepStatusFailed:
+		if step.StartedAt != nil {
+			updatedAt := step.UpdatedAt
+			return &updatedAt
+		}
+	}
+	return nil
+}
+
+// renderMermaidGantt 渲染 Mermaid 甘特图（未开始的步骤不绘制）
+func renderMermaidGantt(timeline *Timeline) string {
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title %s %s (%s)\n", timeline.BusinessType, timeline.BusinessID, timeline.Status)
+	b.WriteString("    dateFormat x\n")
+	b.WriteString("    axisFormat %H:%M:%S\n")
+	b.WriteString("    section steps\n")
+
+	now := time.Now()
+	for i, step := range timeline.Steps {
+		if step.StartedAt == nil {
+			continue
+		}
+
+		tag := "active"
+		end := now
+		switch step.Status {
+		case StepStatusCompleted, StepStatusCompensated:
+			tag = "done"
+		case StepStatusFailed:
+			tag = "crit"
+		}
+		if step.FinishedAt != nil {
+			end = *step.FinishedAt
+		}
+		// 甘特图中耗时为 0 的任务不可见，至少绘制 1ms
+		if !end.After(*step.StartedAt) {
+			end = step.StartedAt.Add(time.Millisecond)
+		}
+
+		name := step.Name
+		if step.Status == StepStatusCompensated {
+			name += " (compensated)"
+		}
+		fmt.Fprintf(&b, "    %s :%s, s%d, %d, %d\n", name, tag, i, step.StartedAt.UnixMilli(), end.UnixMilli())
+	}
+	return b.String()
+}
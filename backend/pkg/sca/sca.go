@@ -0,0 +1,110 @@
+// Package sca 3-D Secure / 强客户认证（SCA）编排
+//
+// payment-gateway 根据商户收银台配置和风控评分决定本次支付的 3DS 要求（是否强制挑战、申请哪种豁免），
+// channel-adapter 将要求转换为各渠道参数，并把渠道返回的挑战动作（Stripe next_action、PayPal payer-action 链接）
+// 和认证结果（是否发生责任转移）统一为本包定义的结构。
+package sca
+
+import "strings"
+
+// Mode 3DS 执行方式
+type Mode string
+
+const (
+	ModeAutomatic Mode = "automatic" // 由渠道/发卡行按需决定是否挑战（可附带豁免申请）
+	ModeChallenge Mode = "challenge" // 强制挑战
+)
+
+// Exemption SCA 豁免类型（PSD2 RTS）
+type Exemption string
+
+const (
+	ExemptionNone     Exemption = ""
+	ExemptionLowValue Exemption = "low_value"                 // 低额交易豁免
+	ExemptionTRA      Exemption = "transaction_risk_analysis" // 交易风险分析豁免
+)
+
+// Request 本次支付的 3DS 要求
+type Request struct {
+	Mode      Mode      `json:"mode"`
+	Exemption Exemption `json:"exemption,omitempty"` // 申请的豁免，发卡行可以拒绝并要求挑战
+	ReturnURL string    `json:"return_url"`          // 消费者完成挑战后的返回地址
+}
+
+// NextAction 类型
+const (
+	ActionRedirect = "redirect_to_url" // 跳转到发卡行/渠道验证页面
+	ActionSDK      = "use_sdk"         // 由前端渠道 SDK 处理挑战（如 Stripe.js handleNextAction）
+)
+
+// NextAction 消费者需要完成的验证动作
+type NextAction struct {
+	Type         string `json:"type"`
+	RedirectURL  string `json:"redirect_url,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// Status 认证结果
+type Status string
+
+const (
+	StatusAuthenticated Status = "authenticated" // 认证成功
+	StatusAttempted     Status = "attempted"     // 发卡行未参与，由卡组织代为确认（仍有责任转移）
+	StatusExempted      Status = "exempted"      // 豁免生效，未挑战
+	StatusFailed        Status = "failed"        // 认证失败或被消费者放弃
+	StatusNotPerformed  Status = "not_performed" // 未执行 3DS（卡不支持、渠道未要求等）
+)
+
+// Result 3DS 认证结果
+type Result struct {
+	Status         Status    `json:"status"`
+	Version        string    `json:"version,omitempty"`   // 3DS 协议版本，如 2.2.0
+	Exemption      Exemption `json:"exemption,omitempty"` // 实际生效的豁免
+	LiabilityShift bool      `json:"liability_shift"`     // 欺诈拒付责任是否转移给发卡行
+	Reason         string    `json:"reason,omitempty"`    // 渠道返回的结果原因
+}
+
+// HasLiabilityShift 按认证结果判断是否发生责任转移（豁免交易不转移责任）
+func HasLiabilityShift(status Status) bool {
+	return status == StatusAuthenticated || status == StatusAttempted
+}
+
+// Policy 豁免与挑战策略
+type Policy struct {
+	LowValueLimits    map[string]int64 // 各币种低额豁免上限（分，含）
+	TRALimits         map[string]int64 // 各币种 TRA 豁免上限（分，含）
+	TRAMaxScore       int              // 申请 TRA 豁免允许的最高风险评分
+	ChallengeMinScore int              // 风险评分达到该值时强制挑战
+}
+
+// DefaultPolicy 默认策略：PSD2 低额豁免 30 EUR，TRA 豁免 100 EUR（对应收单机构欺诈率 ≤ 0.13%）
+func DefaultPolicy() Policy {
+	return Policy{
+		LowValueLimits: map[string]int64{"EUR": 3000, "GBP": 2500, "SEK": 30000, "DKK": 22000, "PLN": 13000, "CHF": 3000},
+		TRALimits:      map[string]int64{"EUR": 10000, "GBP": 8500, "SEK": 100000, "DKK": 75000, "PLN": 43000, "CHF": 10000},
+		TRAMaxScore:    30,
+		// 与风控 review 阈值一致
+		ChallengeMinScore: 60,
+	}
+}
+
+// Decide 按金额和风险评分决定 3DS 要求，riskScore 小于 0 表示未取得风控评分（不申请豁免）
+//
+// 高风险交易强制挑战；低额交易优先申请低额豁免；低风险且金额在 TRA 上限内的交易申请 TRA 豁免；
+// 其余交易由渠道/发卡行决定是否挑战。
+func (p Policy) Decide(amount int64, currency string, riskScore int) *Request {
+	currency = strings.ToUpper(currency)
+	if riskScore >= p.ChallengeMinScore && p.ChallengeMinScore > 0 {
+		return &Request{Mode: ModeChallenge}
+	}
+	if riskScore < 0 {
+		return &Request{Mode: ModeAutomatic}
+	}
+	if limit, ok := p.LowValueLimits[currency]; ok && amount <= limit {
+		return &Request{Mode: ModeAutomatic, Exemption: ExemptionLowValue}
+	}
+	if limit, ok := p.TRALimits[currency]; ok && amount <= limit && riskScore <= p.TRAMaxScore {
+		return &Request{Mode: ModeAutomatic, Exemption: ExemptionTRA}
+	}
+	return &Request{Mode: ModeAutomatic}
+}
@@ -0,0 +1,41 @@
+package sca
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDecide(t *testing.T) {
+	p := DefaultPolicy()
+
+	tests := []struct {
+		name      string
+		amount    int64
+		currency  string
+		score     int
+		mode      Mode
+		exemption Exemption
+	}{
+		{"高风险强制挑战", 1000, "EUR", 75, ModeChallenge, ExemptionNone},
+		{"低额豁免", 3000, "eur", 10, ModeAutomatic, ExemptionLowValue},
+		{"低风险TRA豁免", 9000, "EUR", 20, ModeAutomatic, ExemptionTRA},
+		{"中风险不申请TRA", 9000, "EUR", 45, ModeAutomatic, ExemptionNone},
+		{"超过TRA上限", 50000, "EUR", 5, ModeAutomatic, ExemptionNone},
+		{"无风控评分不申请豁免", 1000, "EUR", -1, ModeAutomatic, ExemptionNone},
+		{"未配置币种", 1000, "USD", 5, ModeAutomatic, ExemptionNone},
+	}
+	for _, tt := range tests {
+		req := p.Decide(tt.amount, tt.currency, tt.score)
+		assert.Equal(t, tt.mode, req.Mode, tt.name)
+		assert.Equal(t, tt.exemption, req.Exemption, tt.name)
+	}
+}
+
+func TestHasLiabilityShift(t *testing.T) {
+	assert.True(t, HasLiabilityShift(StatusAuthenticated))
+	assert.True(t, HasLiabilityShift(StatusAttempted))
+	assert.False(t, HasLiabilityShift(StatusExempted))
+	assert.False(t, HasLiabilityShift(StatusFailed))
+	assert.False(t, HasLiabilityShift(StatusNotPerformed))
+}
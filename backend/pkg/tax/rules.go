@@ -0,0 +1,126 @@
+package tax
+
+import (
+	"context"
+	"strings"
+
+	"github.com/payment-platform/pkg/money"
+)
+
+// Rule 税率规则
+type Rule struct {
+	Country       string        `json:"country"`        // ISO 3166-1 二位国家代码
+	Region        string        `json:"region"`         // 州/省代码，为空表示全国适用
+	Category      string        `json:"category"`       // 税务类别，为空等同 standard
+	TaxName       string        `json:"tax_name"`       // 税种名称
+	Rate          money.Decimal `json:"rate"`           // 税率（百分比）
+	ReverseCharge bool          `json:"reverse_charge"` // 跨境 B2B 且买方提供税号时适用反向征收
+}
+
+// RuleSource 税率表来源
+type RuleSource interface {
+	// Rules 返回指定国家的全部税率规则
+	Rules(ctx context.Context, country string) ([]Rule, error)
+}
+
+// StaticRules 内存税率表
+type StaticRules []Rule
+
+// Rules 实现 RuleSource
+func (s StaticRules) Rules(ctx context.Context, country string) ([]Rule, error) {
+	var rules []Rule
+	for _, rule := range s {
+		if strings.EqualFold(rule.Country, country) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// ChainSource 依次查询多个税率表来源，返回第一个非空结果（如数据库税率表优先，内置税率表兜底）
+type ChainSource []RuleSource
+
+// Rules 实现 RuleSource
+func (c ChainSource) Rules(ctx context.Context, country string) ([]Rule, error) {
+	for _, source := range c {
+		rules, err := source.Rules(ctx, country)
+		if err != nil {
+			return nil, err
+		}
+		if len(rules) > 0 {
+			return rules, nil
+		}
+	}
+	return nil, nil
+}
+
+// Match 按 地区+类别 > 地区+标准 > 全国+类别 > 全国+标准 的优先级匹配税率规则
+//
+// 零税率和免税类别未单独配置时不回退到标准税率。
+func Match(rules []Rule, region, category string) (*Rule, bool) {
+	if category == "" {
+		category = CategoryStandard
+	}
+
+	candidates := [][2]string{{region, category}, {"", category}}
+	if category != CategoryZero && category != CategoryExempt {
+		candidates = [][2]string{{region, category}, {region, CategoryStandard}, {"", category}, {"", CategoryStandard}}
+	}
+
+	for _, candidate := range candidates {
+		for i := range rules {
+			ruleCategory := rules[i].Category
+			if ruleCategory == "" {
+				ruleCategory = CategoryStandard
+			}
+			if strings.EqualFold(rules[i].Region, candidate[0]) && ruleCategory == candidate[1] {
+				return &rules[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// DefaultRules 内置税率表（常用国家/地区的标准税率与低税率，可被数据库税率表覆盖）
+func DefaultRules() StaticRules {
+	rate := money.MustParseDecimal
+	return StaticRules{
+		// 欧盟增值税（跨境 B2B 反向征收）
+		{Country: "DE", Category: CategoryStandard, TaxName: "VAT", Rate: rate("19"), ReverseCharge: true},
+		{Country: "DE", Category: CategoryReduced, TaxName: "VAT", Rate: rate("7"), ReverseCharge: true},
+		{Country: "FR", Category: CategoryStandard, TaxName: "TVA", Rate: rate("20"), ReverseCharge: true},
+		{Country: "FR", Category: CategoryReduced, TaxName: "TVA", Rate: rate("5.5"), ReverseCharge: true},
+		{Country: "NL", Category: CategoryStandard, TaxName: "BTW", Rate: rate("21"), ReverseCharge: true},
+		{Country: "NL", Category: CategoryReduced, TaxName: "BTW", Rate: rate("9"), ReverseCharge: true},
+		{Country: "IT", Category: CategoryStandard, TaxName: "IVA", Rate: rate("22"), ReverseCharge: true},
+		{Country: "IT", Category: CategoryReduced, TaxName: "IVA", Rate: rate("10"), ReverseCharge: true},
+		{Country: "ES", Category: CategoryStandard, TaxName: "IVA", Rate: rate("21"), ReverseCharge: true},
+		{Country: "ES", Category: CategoryReduced, TaxName: "IVA", Rate: rate("10"), ReverseCharge: true},
+		{Country: "IE", Category: CategoryStandard, TaxName: "VAT", Rate: rate("23"), ReverseCharge: true},
+		{Country: "IE", Category: CategoryReduced, TaxName: "VAT", Rate: rate("13.5"), ReverseCharge: true},
+
+		// 英国增值税
+		{Country: "GB", Category: CategoryStandard, TaxName: "VAT", Rate: rate("20"), ReverseCharge: true},
+		{Country: "GB", Category: CategoryReduced, TaxName: "VAT", Rate: rate("5"), ReverseCharge: true},
+
+		// 美国销售税（按州，未列出的州不征收）
+		{Country: "US", Region: "CA", Category: CategoryStandard, TaxName: "Sales Tax", Rate: rate("7.25")},
+		{Country: "US", Region: "NY", Category: CategoryStandard, TaxName: "Sales Tax", Rate: rate("4")},
+		{Country: "US", Region: "TX", Category: CategoryStandard, TaxName: "Sales Tax", Rate: rate("6.25")},
+		{Country: "US", Region: "WA", Category: CategoryStandard, TaxName: "Sales Tax", Rate: rate("6.5")},
+
+		// 加拿大（GST 全国适用，HST/QST 省份为合并税率）
+		{Country: "CA", Category: CategoryStandard, TaxName: "GST", Rate: rate("5")},
+		{Country: "CA", Region: "ON", Category: CategoryStandard, TaxName: "HST", Rate: rate("13")},
+		{Country: "CA", Region: "QC", Category: CategoryStandard, TaxName: "GST+QST", Rate: rate("14.975")},
+
+		// 亚太
+		{Country: "AU", Category: CategoryStandard, TaxName: "GST", Rate: rate("10")},
+		{Country: "SG", Category: CategoryStandard, TaxName: "GST", Rate: rate("9")},
+		{Country: "JP", Category: CategoryStandard, TaxName: "消费税", Rate: rate("10")},
+		{Country: "JP", Category: CategoryReduced, TaxName: "消费税", Rate: rate("8")},
+		{Country: "CN", Category: CategoryStandard, TaxName: "增值税", Rate: rate("13")},
+		{Country: "CN", Category: CategoryReduced, TaxName: "增值税", Rate: rate("9")},
+		{Country: "CN", Category: CategoryDigital, TaxName: "增值税", Rate: rate("6")},
+	}
+}
@@ -0,0 +1,183 @@
+// Package tax 税费计算引擎
+//
+// 按税务管辖地（国家/地区）和商品税务类别匹配税率表，支持含税价与不含税价，
+// 以及 B2B 跨境交易买方提供税号时的反向征收（reverse charge）。
+// 税率表通过 RuleSource 接入（内置默认税率表、数据库税率表等），
+// 计算逻辑通过 Engine 接口替换（如对接第三方税务服务）。
+package tax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/payment-platform/pkg/money"
+)
+
+// 商品税务类别
+const (
+	CategoryStandard = "standard" // 标准税率
+	CategoryReduced  = "reduced"  // 低税率（食品、书籍等）
+	CategoryZero     = "zero"     // 零税率
+	CategoryExempt   = "exempt"   // 免税
+	CategoryDigital  = "digital"  // 数字商品/电子服务
+	CategoryShipping = "shipping" // 运费
+)
+
+// Location 税务管辖地
+type Location struct {
+	Country string `json:"country"` // ISO 3166-1 二位国家代码
+	Region  string `json:"region"`  // 州/省代码，可为空
+}
+
+// LineInput 待计税明细
+type LineInput struct {
+	Reference string `json:"reference"` // 明细标识（如订单项ID），原样返回
+	Category  string `json:"category"`  // 税务类别，为空按标准税率
+	Amount    int64  `json:"amount"`    // 金额（分），含税价模式下为含税金额
+}
+
+// Request 计税请求
+type Request struct {
+	Currency         string      `json:"currency"`
+	SellerCountry    string      `json:"seller_country"`     // 卖方所在国家，用于判断是否跨境
+	Destination      Location    `json:"destination"`        // 税务管辖地（通常为收货地址）
+	BuyerTaxID       string      `json:"buyer_tax_id"`       // 买方税号（B2B）
+	PricesIncludeTax bool        `json:"prices_include_tax"` // 金额是否已含税
+	Lines            []LineInput `json:"lines"`
+}
+
+// Line 计税结果明细
+type Line struct {
+	Reference     string        `json:"reference"`
+	Category      string        `json:"category"`
+	Country       string        `json:"country"`
+	Region        string        `json:"region"`
+	TaxName       string        `json:"tax_name"`       // 税种名称（VAT、GST、Sales Tax 等）
+	Rate          money.Decimal `json:"rate"`           // 税率（百分比）
+	TaxableAmount int64         `json:"taxable_amount"` // 计税金额（不含税，分）
+	TaxAmount     int64         `json:"tax_amount"`     // 税额（分）
+	ReverseCharge bool          `json:"reverse_charge"` // 是否反向征收（税额由买方自行申报）
+}
+
+// Result 计税结果
+type Result struct {
+	Lines            []Line `json:"lines"`
+	TaxableAmount    int64  `json:"taxable_amount"`
+	TaxAmount        int64  `json:"tax_amount"`
+	PricesIncludeTax bool   `json:"prices_include_tax"`
+	ReverseCharge    bool   `json:"reverse_charge"` // 任一明细适用反向征收
+}
+
+// Engine 税费计算引擎
+type Engine interface {
+	Calculate(ctx context.Context, req *Request) (*Result, error)
+}
+
+// RuleEngine 基于税率表的计税引擎
+type RuleEngine struct {
+	source RuleSource
+}
+
+// NewRuleEngine 创建基于税率表的计税引擎
+func NewRuleEngine(source RuleSource) *RuleEngine {
+	return &RuleEngine{source: source}
+}
+
+// Calculate 逐行匹配税率并计算税额
+//
+// 未配置管辖地税率时不计税；跨境 B2B 且税率规则允许反向征收时税额为 0 并标记 ReverseCharge。
+// 税额按行四舍五入到最小货币单位，合计为各行之和。
+func (e *RuleEngine) Calculate(ctx context.Context, req *Request) (*Result, error) {
+	if req.Currency == "" {
+		return nil, fmt.Errorf("计税币种不能为空")
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(req.Destination.Country))
+	region := strings.ToUpper(strings.TrimSpace(req.Destination.Region))
+
+	var rules []Rule
+	if country != "" {
+		var err error
+		rules, err = e.source.Rules(ctx, country)
+		if err != nil {
+			return nil, fmt.Errorf("加载税率表失败: %w", err)
+		}
+	}
+
+	crossBorder := req.SellerCountry != "" && !strings.EqualFold(req.SellerCountry, country)
+	reverseCharge := crossBorder && strings.TrimSpace(req.BuyerTaxID) != ""
+
+	result := &Result{
+		Lines:            make([]Line, 0, len(req.Lines)),
+		PricesIncludeTax: req.PricesIncludeTax,
+	}
+	for _, in := range req.Lines {
+		if in.Amount < 0 {
+			return nil, fmt.Errorf("计税金额不能为负数: %s", in.Reference)
+		}
+
+		category := in.Category
+		if category == "" {
+			category = CategoryStandard
+		}
+		line := Line{
+			Reference:     in.Reference,
+			Category:      category,
+			Country:       country,
+			Region:        region,
+			TaxableAmount: in.Amount,
+		}
+
+		rule, ok := Match(rules, region, category)
+		if ok {
+			line.TaxName = rule.TaxName
+			line.Rate = rule.Rate
+			line.Region = rule.Region
+
+			if rule.ReverseCharge && reverseCharge {
+				// 反向征收：卖方不收税，价格按不含税处理
+				line.ReverseCharge = true
+				result.ReverseCharge = true
+			} else {
+				taxable, taxAmount, err := compute(in.Amount, req.Currency, rule.Rate, req.PricesIncludeTax)
+				if err != nil {
+					return nil, err
+				}
+				line.TaxableAmount = taxable
+				line.TaxAmount = taxAmount
+			}
+		}
+
+		result.TaxableAmount += line.TaxableAmount
+		result.TaxAmount += line.TaxAmount
+		result.Lines = append(result.Lines, line)
+	}
+	return result, nil
+}
+
+// compute 按税率计算计税金额与税额（税率为百分比）
+func compute(amount int64, currency string, rate money.Decimal, inclusive bool) (int64, int64, error) {
+	if rate.Sign() <= 0 || amount == 0 {
+		return amount, 0, nil
+	}
+
+	fraction := rate.Mul(money.NewDecimal(1, 2))
+	if inclusive {
+		// 含税价：税额 = 含税金额 × 税率 / (1 + 税率)
+		var err error
+		fraction, err = fraction.Div(fraction.Add(money.DecimalFromInt(1)), 12, money.RoundHalfUp)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	tax, err := money.New(amount, currency).Mul(fraction, money.RoundHalfUp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("计算税额失败: %w", err)
+	}
+	if inclusive {
+		return amount - tax.Amount, tax.Amount, nil
+	}
+	return amount, tax.Amount, nil
+}
@@ -0,0 +1,86 @@
+package tax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/payment-platform/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateExclusiveAndInclusive(t *testing.T) {
+	engine := NewRuleEngine(DefaultRules())
+
+	// 不含税价：10000 × 19% = 1900，低税率 999 × 7% = 69.93 -> 70
+	result, err := engine.Calculate(context.Background(), &Request{
+		Currency:    "EUR",
+		Destination: Location{Country: "de"},
+		Lines: []LineInput{
+			{Reference: "a", Amount: 10000},
+			{Reference: "b", Category: CategoryReduced, Amount: 999},
+			{Reference: "c", Category: CategoryExempt, Amount: 500},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1900), result.Lines[0].TaxAmount)
+	assert.Equal(t, int64(70), result.Lines[1].TaxAmount)
+	assert.Equal(t, int64(0), result.Lines[2].TaxAmount) // 免税不回退到标准税率
+	assert.Equal(t, int64(1970), result.TaxAmount)
+	assert.Equal(t, int64(11499), result.TaxableAmount)
+
+	// 含税价：11900 含 19% 增值税，税额 1900，不含税 10000
+	result, err = engine.Calculate(context.Background(), &Request{
+		Currency:         "EUR",
+		Destination:      Location{Country: "DE"},
+		PricesIncludeTax: true,
+		Lines:            []LineInput{{Amount: 11900}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1900), result.TaxAmount)
+	assert.Equal(t, int64(10000), result.TaxableAmount)
+}
+
+func TestCalculateRegionAndReverseCharge(t *testing.T) {
+	engine := NewRuleEngine(ChainSource{
+		StaticRules{{Country: "US", Region: "NY", TaxName: "Sales Tax", Rate: money.MustParseDecimal("8.875")}},
+		DefaultRules(),
+	})
+
+	// 自定义税率表优先于内置税率表；未配置的州不计税
+	result, err := engine.Calculate(context.Background(), &Request{
+		Currency:    "USD",
+		Destination: Location{Country: "US", Region: "NY"},
+		Lines:       []LineInput{{Amount: 10000}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(888), result.TaxAmount)
+
+	result, err = engine.Calculate(context.Background(), &Request{
+		Currency:    "USD",
+		Destination: Location{Country: "US", Region: "OR"},
+		Lines:       []LineInput{{Amount: 10000}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TaxAmount)
+
+	// 跨境 B2B 提供税号时反向征收，境内 B2B 照常征税
+	req := &Request{
+		Currency:      "EUR",
+		SellerCountry: "NL",
+		Destination:   Location{Country: "FR"},
+		BuyerTaxID:    "FR12345678901",
+		Lines:         []LineInput{{Amount: 10000}},
+	}
+	result, err = engine.Calculate(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.ReverseCharge)
+	assert.True(t, result.Lines[0].ReverseCharge)
+	assert.Equal(t, int64(0), result.TaxAmount)
+
+	req.SellerCountry = "FR"
+	result, err = engine.Calculate(context.Background(), req)
+	require.NoError(t, err)
+	assert.False(t, result.ReverseCharge)
+	assert.Equal(t, int64(2000), result.TaxAmount)
+}
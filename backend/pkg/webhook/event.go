@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook 负载版本
+// 商户端点可以固定（pin）某个版本，平台升级负载结构时不影响已固定版本的商户
+const (
+	APIVersionV1 = "v1" // 扁平结构：data 直接是业务对象
+	APIVersionV2 = "v2" // 对象结构：data.object 为业务对象，data.previous_attributes 为变更前字段
+
+	LatestAPIVersion = APIVersionV2
+)
+
+// Event 统一的 Webhook 事件信封
+type Event struct {
+	ID         string          `json:"id"`          // 事件ID（evt_ 前缀，重试时不变）
+	Type       string          `json:"type"`        // 事件类型，如 payment.success
+	APIVersion string          `json:"api_version"` // 负载版本
+	Created    int64           `json:"created"`     // 事件创建时间（unix秒）
+	Data       json.RawMessage `json:"data"`        // 事件数据（结构由 APIVersion 决定）
+}
+
+// VersionTransformer 把最新版本的事件数据转换为指定版本
+type VersionTransformer func(eventType string, object map[string]interface{}, previous map[string]interface{}) (interface{}, error)
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = map[string]VersionTransformer{
+		APIVersionV1: func(_ string, object map[string]interface{}, _ map[string]interface{}) (interface{}, error) {
+			return object, nil
+		},
+		APIVersionV2: func(_ string, object map[string]interface{}, previous map[string]interface{}) (interface{}, error) {
+			data := map[string]interface{}{"object": object}
+			if len(previous) > 0 {
+				data["previous_attributes"] = previous
+			}
+			return data, nil
+		},
+	}
+)
+
+// RegisterVersionTransformer 注册负载版本转换器（新增版本时调用）
+func RegisterVersionTransformer(version string, transformer VersionTransformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers[version] = transformer
+}
+
+// IsSupportedAPIVersion 检查负载版本是否支持
+func IsSupportedAPIVersion(version string) bool {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+	_, ok := transformers[version]
+	return ok
+}
+
+// NewEventID 生成事件ID
+func NewEventID() string {
+	return "evt_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// NewEvent 构造事件信封
+// object 为业务对象（最新结构），previous 为变更前的字段（可选），
+// version 为空时使用 LatestAPIVersion，eventID 为空时自动生成
+func NewEvent(eventID, eventType, version string, created time.Time, object map[string]interface{}, previous map[string]interface{}) (*Event, error) {
+	if version == "" {
+		version = LatestAPIVersion
+	}
+	if eventID == "" {
+		eventID = NewEventID()
+	}
+
+	transformersMu.RLock()
+	transformer, ok := transformers[version]
+	transformersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("webhook: 不支持的负载版本 %s", version)
+	}
+
+	data, err := transformer(eventType, object, previous)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: 转换负载版本 %s 失败: %w", version, err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: 序列化事件数据失败: %w", err)
+	}
+
+	return &Event{
+		ID:         eventID,
+		Type:       eventType,
+		APIVersion: version,
+		Created:    created.Unix(),
+		Data:       raw,
+	}, nil
+}
+
+// Marshal 序列化事件（作为 HTTP 请求体，签名基于该字节序列）
+func (e *Event) Marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -3,9 +3,6 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -52,10 +49,14 @@ type WebhookPayload struct {
 
 // WebhookRequest Webhook 请求
 type WebhookRequest struct {
-	URL       string          // 通知 URL
-	Secret    string          // 签名密钥
-	Payload   *WebhookPayload // 通知负载
-	MerchantID string         // 商户 ID（用于日志和指标）
+	URL        string          // 通知 URL
+	Secret     string          // 签名密钥
+	Secrets    []string        // 轮换期间仍有效的旧密钥（可选，会额外生成签名）
+	Payload    *WebhookPayload // 通知负载（作为事件信封的 data）
+	MerchantID string          // 商户 ID（用于日志和指标）
+	EventID    string          // 事件ID（为空时自动生成，同一次 Send 的所有重试共用）
+	APIVersion string          // 负载版本（为空时使用 LatestAPIVersion）
+	Created    time.Time       // 事件创建时间（为空时取 Payload.Timestamp，同一事件的所有投递共用）
 }
 
 // WebhookResponse Webhook 响应
@@ -95,6 +96,18 @@ func NewWebhookRetrier(config *RetryConfig, redisClient *redis.Client) *WebhookR
 func (r *WebhookRetrier) Send(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
 	var lastResp *WebhookResponse
 
+	// 事件ID在重试间保持不变，商户据此去重
+	if req.EventID == "" {
+		req.EventID = NewEventID()
+	}
+	// 事件创建时间在首次发送时固定，重试只重新签名，请求体保持不变
+	if req.Created.IsZero() {
+		req.Created = time.Now()
+		if req.Payload.Timestamp > 0 {
+			req.Created = time.Unix(req.Payload.Timestamp, 0)
+		}
+	}
+
 	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
 		// 如果是重试，等待退避时间
 		if attempt > 0 {
@@ -162,20 +175,17 @@ func (r *WebhookRetrier) Send(ctx context.Context, req *WebhookRequest) (*Webhoo
 func (r *WebhookRetrier) sendOnce(ctx context.Context, req *WebhookRequest, attempt int) *WebhookResponse {
 	start := time.Now()
 
-	// 序列化 payload
-	payloadBytes, err := json.Marshal(req.Payload)
+	// 构造事件信封并序列化
+	payloadBytes, err := BuildEventBody(req)
 	if err != nil {
 		return &WebhookResponse{
 			Success:  false,
-			Error:    fmt.Errorf("序列化 payload 失败: %w", err),
+			Error:    err,
 			Duration: time.Since(start),
 			Attempt:  attempt,
 		}
 	}
 
-	// 计算签名
-	signature := r.calculateSignature(payloadBytes, req.Secret)
-
 	// 创建 HTTP 请求
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.URL, bytes.NewReader(payloadBytes))
 	if err != nil {
@@ -187,12 +197,11 @@ func (r *WebhookRetrier) sendOnce(ctx context.Context, req *WebhookRequest, atte
 		}
 	}
 
-	// 设置请求头
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-Webhook-Signature", signature)
-	httpReq.Header.Set("X-Webhook-Event", req.Payload.Event)
-	httpReq.Header.Set("X-Webhook-Timestamp", fmt.Sprintf("%d", req.Payload.Timestamp))
-	httpReq.Header.Set("X-Webhook-Attempt", fmt.Sprintf("%d", attempt))
+	// 设置请求头（每次投递重新签名，时间戳为本次发送时间）
+	event := &Event{ID: req.EventID, Type: req.Payload.Event, APIVersion: apiVersionOrLatest(req.APIVersion)}
+	for k, v := range SignedHeaders(event, payloadBytes, req.signingSecrets(), attempt) {
+		httpReq.Header.Set(k, v)
+	}
 
 	// 发送请求
 	httpResp, err := r.httpClient.Do(httpReq)
@@ -266,11 +275,53 @@ func (r *WebhookRetrier) shouldRetry(statusCode int, attempt int) bool {
 	return false
 }
 
-// calculateSignature 计算 Webhook 签名
-func (r *WebhookRetrier) calculateSignature(payload []byte, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write(payload)
-	return hex.EncodeToString(h.Sum(nil))
+// BuildEventBody 把 WebhookPayload 包装为事件信封并序列化
+// created 取事件创建时固定的时间（req.Created 或负载中的业务时间戳），不使用发送时间，保证重试时请求体不变
+func BuildEventBody(req *WebhookRequest) ([]byte, error) {
+	object, err := payloadToObject(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 payload 失败: %w", err)
+	}
+
+	created := req.Created
+	if created.IsZero() {
+		if req.Payload.Timestamp <= 0 {
+			return nil, fmt.Errorf("事件创建时间为空")
+		}
+		created = time.Unix(req.Payload.Timestamp, 0)
+	}
+
+	event, err := NewEvent(req.EventID, req.Payload.Event, req.APIVersion, created, object, nil)
+	if err != nil {
+		return nil, err
+	}
+	return event.Marshal()
+}
+
+// payloadToObject 把 WebhookPayload 转换为通用业务对象
+func payloadToObject(payload *WebhookPayload) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// signingSecrets 返回用于签名的全部密钥（当前密钥在前）
+func (req *WebhookRequest) signingSecrets() []string {
+	return append([]string{req.Secret}, req.Secrets...)
+}
+
+// apiVersionOrLatest 返回负载版本（为空时使用最新版本）
+func apiVersionOrLatest(version string) string {
+	if version == "" {
+		return LatestAPIVersion
+	}
+	return version
 }
 
 // recordFailure 记录失败的 Webhook（用于后台任务继续重试）
@@ -282,15 +333,20 @@ func (r *WebhookRetrier) recordFailure(ctx context.Context, req *WebhookRequest,
 	// 失败记录的 key
 	key := fmt.Sprintf("webhook:failed:%s:%s", req.MerchantID, req.Payload.PaymentNo)
 
-	// 记录失败数据
-	data := map[string]interface{}{
-		"url":         req.URL,
-		"payload":     req.Payload,
-		"merchant_id": req.MerchantID,
-		"attempts":    resp.Attempt,
-		"last_error":  resp.Error.Error(),
-		"status_code": resp.StatusCode,
-		"created_at":  time.Now().Unix(),
+	// 记录失败数据（不保存签名和密钥，后台重试时按商户当前密钥重新签名）
+	data := failedWebhook{
+		URL:          req.URL,
+		Payload:      req.Payload,
+		MerchantID:   req.MerchantID,
+		EventID:      req.EventID,
+		APIVersion:   req.APIVersion,
+		EventCreated: req.Created.Unix(),
+		Attempts:     resp.Attempt,
+		StatusCode:   resp.StatusCode,
+		CreatedAt:    time.Now().Unix(),
+	}
+	if resp.Error != nil {
+		data.LastError = resp.Error.Error()
 	}
 
 	dataBytes, _ := json.Marshal(data)
@@ -314,6 +370,20 @@ func (r *WebhookRetrier) recordFailure(ctx context.Context, req *WebhookRequest,
 		zap.String("key", key))
 }
 
+// failedWebhook Redis 中的失败 Webhook 记录
+type failedWebhook struct {
+	URL          string          `json:"url"`
+	Payload      *WebhookPayload `json:"payload"`
+	MerchantID   string          `json:"merchant_id"`
+	EventID      string          `json:"event_id"`
+	APIVersion   string          `json:"api_version"`
+	EventCreated int64           `json:"event_created"` // 事件创建时间（unix秒）
+	Attempts     int             `json:"attempts"`
+	LastError    string          `json:"last_error"`
+	StatusCode   int             `json:"status_code"`
+	CreatedAt    int64           `json:"created_at"` // 记录时间（unix秒）
+}
+
 // clearFailureCount 清除失败计数
 func (r *WebhookRetrier) clearFailureCount(ctx context.Context, merchantID, paymentNo string) {
 	if r.redisClient == nil {
@@ -324,17 +394,22 @@ func (r *WebhookRetrier) clearFailureCount(ctx context.Context, merchantID, paym
 	r.redisClient.Del(ctx, key)
 }
 
+// SecretResolver 返回商户当前有效的签名密钥（当前密钥在前，轮换期间的旧密钥在后）
+type SecretResolver func(ctx context.Context, merchantID string) ([]string, error)
+
 // RetryWorker Webhook 重试后台任务
 type RetryWorker struct {
-	retrier  *WebhookRetrier
-	interval time.Duration
+	retrier   *WebhookRetrier
+	secrets   SecretResolver
+	interval  time.Duration
 	batchSize int
 }
 
 // NewRetryWorker 创建 Webhook 重试后台任务
-func NewRetryWorker(retrier *WebhookRetrier, interval time.Duration, batchSize int) *RetryWorker {
+func NewRetryWorker(retrier *WebhookRetrier, secrets SecretResolver, interval time.Duration, batchSize int) *RetryWorker {
 	return &RetryWorker{
 		retrier:   retrier,
+		secrets:   secrets,
 		interval:  interval,
 		batchSize: batchSize,
 	}
@@ -385,28 +460,50 @@ func (w *RetryWorker) processBatch(ctx context.Context) {
 			continue
 		}
 
-		var data map[string]interface{}
-		if err := json.Unmarshal(dataBytes, &data); err != nil {
+		var data failedWebhook
+		if err := json.Unmarshal(dataBytes, &data); err != nil || data.Payload == nil {
 			logger.Warn("解析失败 Webhook 数据失败",
 				zap.Error(err),
 				zap.String("key", key))
 			continue
 		}
 
-		// 重新构造请求
-		req := &WebhookRequest{
-			URL:        data["url"].(string),
-			MerchantID: data["merchant_id"].(string),
+		// 发送时获取商户当前密钥，签名时间戳为本次发送时间
+		secrets, err := w.secrets(ctx, data.MerchantID)
+		if err != nil || len(secrets) == 0 {
+			logger.Warn("获取商户 Webhook 密钥失败，稍后重试",
+				zap.Error(err),
+				zap.String("merchant_id", data.MerchantID),
+				zap.String("key", key))
+			w.retrier.redisClient.LPush(ctx, queueKey, key)
+			continue
 		}
 
-		// TODO: 从数据库重新获取 payload 和 secret
+		// 重新构造请求（事件ID、负载版本和创建时间沿用原事件）
+		req := &WebhookRequest{
+			URL:        data.URL,
+			Secret:     secrets[0],
+			Secrets:    secrets[1:],
+			Payload:    data.Payload,
+			MerchantID: data.MerchantID,
+			EventID:    data.EventID,
+			APIVersion: data.APIVersion,
+		}
+		if data.EventCreated > 0 {
+			req.Created = time.Unix(data.EventCreated, 0)
+		}
 
-		// 重新发送
 		logger.Info("后台任务重试 Webhook",
 			zap.String("merchant_id", req.MerchantID),
+			zap.String("event_id", req.EventID),
 			zap.String("key", key))
 
-		// resp, err := w.retrier.Send(ctx, req)
-		// 处理结果...
+		// 失败时 Send 会重新记录到失败队列
+		if _, err := w.retrier.Send(ctx, req); err != nil {
+			logger.Warn("后台任务重试 Webhook 失败",
+				zap.Error(err),
+				zap.String("merchant_id", req.MerchantID),
+				zap.String("event_id", req.EventID))
+		}
 	}
 }
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook 请求头（所有服务发出的商户 Webhook 统一使用）
+const (
+	HeaderSignature  = "X-Webhook-Signature"   // t=<unix秒>,v1=<hex>[,v1=<hex>...]
+	HeaderEventID    = "X-Webhook-Id"          // 事件ID（重试时不变，商户用于去重）
+	HeaderEventType  = "X-Webhook-Event"       // 事件类型
+	HeaderTimestamp  = "X-Webhook-Timestamp"   // 签名时间戳（与签名头中的 t 一致）
+	HeaderAPIVersion = "X-Webhook-Api-Version" // 负载版本
+	HeaderAttempt    = "X-Webhook-Attempt"     // 第几次投递
+)
+
+// SignatureScheme 当前签名方案名称（签名头中的键）
+const SignatureScheme = "v1"
+
+// DefaultSignatureTolerance 默认允许的签名时间误差（商户验签时使用）
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// 签名验证错误
+var (
+	ErrInvalidSignatureHeader = errors.New("webhook: 签名头格式无效")
+	ErrSignatureExpired       = errors.New("webhook: 签名时间戳超出允许范围")
+	ErrNoValidSignature       = errors.New("webhook: 没有匹配的签名")
+)
+
+// ComputeSignature 计算单个签名：HMAC-SHA256(secret, "<timestamp>.<body>")，十六进制小写
+func ComputeSignature(secret string, timestamp int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SignPayload 生成签名头的值
+// secrets 中每个密钥生成一个 v1 签名：密钥轮换期间同时使用新旧密钥签名，
+// 商户在任一密钥下验签通过即可，从而实现零停机轮换
+func SignPayload(secrets []string, timestamp time.Time, body []byte) string {
+	ts := timestamp.Unix()
+	parts := []string{"t=" + strconv.FormatInt(ts, 10)}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		parts = append(parts, SignatureScheme+"="+ComputeSignature(secret, ts, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSignatureHeader 解析签名头，返回时间戳和所有 v1 签名
+func ParseSignatureHeader(header string) (int64, []string, error) {
+	var (
+		timestamp  int64
+		hasTS      bool
+		signatures []string
+	)
+
+	for _, item := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return 0, nil, ErrInvalidSignatureHeader
+			}
+			timestamp = ts
+			hasTS = true
+		case SignatureScheme:
+			signatures = append(signatures, kv[1])
+		}
+	}
+
+	if !hasTS || len(signatures) == 0 {
+		return 0, nil, ErrInvalidSignatureHeader
+	}
+	return timestamp, signatures, nil
+}
+
+// VerifySignature 验证签名头（供商户 SDK 和内部测试使用）
+// tolerance 为允许的时间误差，<=0 时使用 DefaultSignatureTolerance
+func VerifySignature(header string, body []byte, secret string, tolerance time.Duration) error {
+	timestamp, signatures, err := ParseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	if time.Since(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected := ComputeSignature(secret, timestamp, body)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrNoValidSignature
+}
+
+// SignedHeaders 构造一次投递的完整请求头（签名时间为当前时间）
+func SignedHeaders(event *Event, body []byte, secrets []string, attempt int) map[string]string {
+	now := time.Now()
+	return map[string]string{
+		"Content-Type":   "application/json",
+		HeaderSignature:  SignPayload(secrets, now, body),
+		HeaderEventID:    event.ID,
+		HeaderEventType:  event.Type,
+		HeaderTimestamp:  fmt.Sprintf("%d", now.Unix()),
+		HeaderAPIVersion: event.APIVersion,
+		HeaderAttempt:    fmt.Sprintf("%d", attempt),
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSignature_KnownVector(t *testing.T) {
+	// 固定测试向量，供商户 SDK 对照实现
+	body := []byte(`{"id":"evt_test","type":"payment.success","api_version":"v2","created":1700000000,"data":{"object":{"amount":1000}}}`)
+	sig := ComputeSignature("whsec_test", 1700000000, body)
+
+	assert.Equal(t, "cba1e50d897043f037f75990e5a6cd96adaff9062edd97161cc6eeb61388de52", sig)
+	assert.NotEqual(t, sig, ComputeSignature("whsec_test", 1700000001, body), "时间戳必须参与签名")
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"hello":"world"}`)
+	header := SignPayload([]string{"secret-new", "secret-old"}, time.Now(), body)
+
+	timestamp, sigs, err := ParseSignatureHeader(header)
+	require.NoError(t, err)
+	assert.NotZero(t, timestamp)
+	assert.Len(t, sigs, 2, "轮换期间应同时携带新旧密钥签名")
+
+	assert.NoError(t, VerifySignature(header, body, "secret-new", 0))
+	assert.NoError(t, VerifySignature(header, body, "secret-old", 0))
+	assert.ErrorIs(t, VerifySignature(header, body, "secret-other", 0), ErrNoValidSignature)
+	assert.ErrorIs(t, VerifySignature(header, []byte(`{"hello":"tampered"}`), "secret-new", 0), ErrNoValidSignature)
+}
+
+func TestVerifySignature_RejectsReplay(t *testing.T) {
+	body := []byte(`{}`)
+	header := SignPayload([]string{"secret"}, time.Now().Add(-10*time.Minute), body)
+
+	assert.ErrorIs(t, VerifySignature(header, body, "secret", 5*time.Minute), ErrSignatureExpired)
+}
+
+func TestParseSignatureHeader_Invalid(t *testing.T) {
+	for _, header := range []string{"", "v1=abc", "t=123", "t=abc,v1=def"} {
+		_, _, err := ParseSignatureHeader(header)
+		assert.ErrorIs(t, err, ErrInvalidSignatureHeader, fmt.Sprintf("header=%q", header))
+	}
+}
+
+func TestNewEvent_Versions(t *testing.T) {
+	object := map[string]interface{}{"payment_no": "PAY1", "amount": float64(1000)}
+	created := time.Unix(1700000000, 0)
+
+	v1, err := NewEvent("evt_1", "payment.success", APIVersionV1, created, object, nil)
+	require.NoError(t, err)
+	var v1Data map[string]interface{}
+	require.NoError(t, json.Unmarshal(v1.Data, &v1Data))
+	assert.Equal(t, "PAY1", v1Data["payment_no"])
+
+	v2, err := NewEvent("evt_1", "payment.success", "", created, object, map[string]interface{}{"status": "pending"})
+	require.NoError(t, err)
+	assert.Equal(t, LatestAPIVersion, v2.APIVersion)
+	var v2Data map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(v2.Data, &v2Data))
+	assert.Equal(t, "PAY1", v2Data["object"]["payment_no"])
+	assert.Equal(t, "pending", v2Data["previous_attributes"]["status"])
+
+	_, err = NewEvent("", "payment.success", "v99", created, object, nil)
+	assert.Error(t, err)
+}
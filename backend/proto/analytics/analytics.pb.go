@@ -1634,6 +1634,219 @@ func (x *ListReportsResponse) GetTotal() int64 {
 	return 0
 }
 
+// 多维指标查询相关消息
+type QueryMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Metrics       []string               `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`         // payment_count, success_count, success_amount, success_rate ...
+	Dimensions    []string               `protobuf:"bytes,3,rep,name=dimensions,proto3" json:"dimensions,omitempty"`   // merchant_id, channel, payment_method, country, currency, merchant_tier
+	Granularity   string                 `protobuf:"bytes,4,opt,name=granularity,proto3" json:"granularity,omitempty"` // hour, day, week, month, total
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Filters       map[string]string      `protobuf:"bytes,7,rep,name=filters,proto3" json:"filters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 维度过滤条件
+	Limit         int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryMetricsRequest) Reset() {
+	*x = QueryMetricsRequest{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryMetricsRequest) ProtoMessage() {}
+
+func (x *QueryMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryMetricsRequest.ProtoReflect.Descriptor instead.
+func (*QueryMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *QueryMetricsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *QueryMetricsRequest) GetMetrics() []string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *QueryMetricsRequest) GetDimensions() []string {
+	if x != nil {
+		return x.Dimensions
+	}
+	return nil
+}
+
+func (x *QueryMetricsRequest) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *QueryMetricsRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *QueryMetricsRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *QueryMetricsRequest) GetFilters() map[string]string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *QueryMetricsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type MetricRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Dimensions    map[string]string      `protobuf:"bytes,2,rep,name=dimensions,proto3" json:"dimensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Values        map[string]float64     `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricRow) Reset() {
+	*x = MetricRow{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricRow) ProtoMessage() {}
+
+func (x *MetricRow) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricRow.ProtoReflect.Descriptor instead.
+func (*MetricRow) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *MetricRow) GetBucket() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Bucket
+	}
+	return nil
+}
+
+func (x *MetricRow) GetDimensions() map[string]string {
+	if x != nil {
+		return x.Dimensions
+	}
+	return nil
+}
+
+func (x *MetricRow) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type QueryMetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          []*MetricRow           `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	Granularity   string                 `protobuf:"bytes,2,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryMetricsResponse) Reset() {
+	*x = QueryMetricsResponse{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryMetricsResponse) ProtoMessage() {}
+
+func (x *QueryMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryMetricsResponse.ProtoReflect.Descriptor instead.
+func (*QueryMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *QueryMetricsResponse) GetRows() []*MetricRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *QueryMetricsResponse) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
 var File_proto_analytics_analytics_proto protoreflect.FileDescriptor
 
 const file_proto_analytics_analytics_proto_rawDesc = "" +
@@ -1767,7 +1980,38 @@ const file_proto_analytics_analytics_proto_rawDesc = "" +
 	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"X\n" +
 	"\x13ListReportsResponse\x12+\n" +
 	"\areports\x18\x01 \x03(\v2\x11.analytics.ReportR\areports\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x03R\x05total2\x9f\x06\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"\x9d\x03\n" +
+	"\x13QueryMetricsRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x18\n" +
+	"\ametrics\x18\x02 \x03(\tR\ametrics\x12\x1e\n" +
+	"\n" +
+	"dimensions\x18\x03 \x03(\tR\n" +
+	"dimensions\x12 \n" +
+	"\vgranularity\x18\x04 \x01(\tR\vgranularity\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12E\n" +
+	"\afilters\x18\a \x03(\v2+.analytics.QueryMetricsRequest.FiltersEntryR\afilters\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limit\x1a:\n" +
+	"\fFiltersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb9\x02\n" +
+	"\tMetricRow\x122\n" +
+	"\x06bucket\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x06bucket\x12D\n" +
+	"\n" +
+	"dimensions\x18\x02 \x03(\v2$.analytics.MetricRow.DimensionsEntryR\n" +
+	"dimensions\x128\n" +
+	"\x06values\x18\x03 \x03(\v2 .analytics.MetricRow.ValuesEntryR\x06values\x1a=\n" +
+	"\x0fDimensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"b\n" +
+	"\x14QueryMetricsResponse\x12(\n" +
+	"\x04rows\x18\x01 \x03(\v2\x14.analytics.MetricRowR\x04rows\x12 \n" +
+	"\vgranularity\x18\x02 \x01(\tR\vgranularity2\xf0\x06\n" +
 	"\x10AnalyticsService\x12U\n" +
 	"\x0fGetPaymentStats\x12!.analytics.GetPaymentStatsRequest\x1a\x1f.analytics.PaymentStatsResponse\x12X\n" +
 	"\x10GetPaymentTrends\x12\".analytics.GetPaymentTrendsRequest\x1a .analytics.PaymentTrendsResponse\x12U\n" +
@@ -1777,7 +2021,8 @@ const file_proto_analytics_analytics_proto_rawDesc = "" +
 	"\x12GetRealtimeMetrics\x12$.analytics.GetRealtimeMetricsRequest\x1a\".analytics.RealtimeMetricsResponse\x12U\n" +
 	"\x0fGetSystemHealth\x12!.analytics.GetSystemHealthRequest\x1a\x1f.analytics.SystemHealthResponse\x12M\n" +
 	"\x0eGenerateReport\x12 .analytics.GenerateReportRequest\x1a\x19.analytics.ReportResponse\x12L\n" +
-	"\vListReports\x12\x1d.analytics.ListReportsRequest\x1a\x1e.analytics.ListReportsResponseB7Z5github.com/payment-platform/proto/analytics;analyticsb\x06proto3"
+	"\vListReports\x12\x1d.analytics.ListReportsRequest\x1a\x1e.analytics.ListReportsResponse\x12O\n" +
+	"\fQueryMetrics\x12\x1e.analytics.QueryMetricsRequest\x1a\x1f.analytics.QueryMetricsResponseB7Z5github.com/payment-platform/proto/analytics;analyticsb\x06proto3"
 
 var (
 	file_proto_analytics_analytics_proto_rawDescOnce sync.Once
@@ -1791,7 +2036,7 @@ func file_proto_analytics_analytics_proto_rawDescGZIP() []byte {
 	return file_proto_analytics_analytics_proto_rawDescData
 }
 
-var file_proto_analytics_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_proto_analytics_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
 var file_proto_analytics_analytics_proto_goTypes = []any{
 	(*PaymentStats)(nil),              // 0: analytics.PaymentStats
 	(*GetPaymentStatsRequest)(nil),    // 1: analytics.GetPaymentStatsRequest
@@ -1819,54 +2064,69 @@ var file_proto_analytics_analytics_proto_goTypes = []any{
 	(*ReportResponse)(nil),            // 23: analytics.ReportResponse
 	(*ListReportsRequest)(nil),        // 24: analytics.ListReportsRequest
 	(*ListReportsResponse)(nil),       // 25: analytics.ListReportsResponse
-	(*timestamppb.Timestamp)(nil),     // 26: google.protobuf.Timestamp
+	(*QueryMetricsRequest)(nil),       // 26: analytics.QueryMetricsRequest
+	(*MetricRow)(nil),                 // 27: analytics.MetricRow
+	(*QueryMetricsResponse)(nil),      // 28: analytics.QueryMetricsResponse
+	nil,                               // 29: analytics.QueryMetricsRequest.FiltersEntry
+	nil,                               // 30: analytics.MetricRow.DimensionsEntry
+	nil,                               // 31: analytics.MetricRow.ValuesEntry
+	(*timestamppb.Timestamp)(nil),     // 32: google.protobuf.Timestamp
 }
 var file_proto_analytics_analytics_proto_depIdxs = []int32{
-	26, // 0: analytics.GetPaymentStatsRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 1: analytics.GetPaymentStatsRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 0: analytics.GetPaymentStatsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 1: analytics.GetPaymentStatsRequest.end_time:type_name -> google.protobuf.Timestamp
 	0,  // 2: analytics.PaymentStatsResponse.stats:type_name -> analytics.PaymentStats
-	26, // 3: analytics.GetPaymentTrendsRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 4: analytics.GetPaymentTrendsRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 3: analytics.GetPaymentTrendsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 4: analytics.GetPaymentTrendsRequest.end_time:type_name -> google.protobuf.Timestamp
 	3,  // 5: analytics.PaymentTrendsResponse.trends:type_name -> analytics.TrendPoint
-	26, // 6: analytics.GetChannelStatsRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 7: analytics.GetChannelStatsRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 6: analytics.GetChannelStatsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 7: analytics.GetChannelStatsRequest.end_time:type_name -> google.protobuf.Timestamp
 	6,  // 8: analytics.ChannelStatsResponse.channels:type_name -> analytics.ChannelStat
-	26, // 9: analytics.GetMerchantStatsRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 10: analytics.GetMerchantStatsRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 9: analytics.GetMerchantStatsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 10: analytics.GetMerchantStatsRequest.end_time:type_name -> google.protobuf.Timestamp
 	9,  // 11: analytics.MerchantStatsResponse.stats:type_name -> analytics.MerchantStats
-	26, // 12: analytics.GetTopMerchantsRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 13: analytics.GetTopMerchantsRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 12: analytics.GetTopMerchantsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 13: analytics.GetTopMerchantsRequest.end_time:type_name -> google.protobuf.Timestamp
 	12, // 14: analytics.TopMerchantsResponse.merchants:type_name -> analytics.TopMerchant
 	15, // 15: analytics.RealtimeMetricsResponse.metrics:type_name -> analytics.RealtimeMetrics
 	18, // 16: analytics.SystemHealthResponse.services:type_name -> analytics.ServiceHealth
-	26, // 17: analytics.Report.created_at:type_name -> google.protobuf.Timestamp
-	26, // 18: analytics.GenerateReportRequest.start_time:type_name -> google.protobuf.Timestamp
-	26, // 19: analytics.GenerateReportRequest.end_time:type_name -> google.protobuf.Timestamp
+	32, // 17: analytics.Report.created_at:type_name -> google.protobuf.Timestamp
+	32, // 18: analytics.GenerateReportRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 19: analytics.GenerateReportRequest.end_time:type_name -> google.protobuf.Timestamp
 	21, // 20: analytics.ReportResponse.report:type_name -> analytics.Report
 	21, // 21: analytics.ListReportsResponse.reports:type_name -> analytics.Report
-	1,  // 22: analytics.AnalyticsService.GetPaymentStats:input_type -> analytics.GetPaymentStatsRequest
-	4,  // 23: analytics.AnalyticsService.GetPaymentTrends:input_type -> analytics.GetPaymentTrendsRequest
-	7,  // 24: analytics.AnalyticsService.GetChannelStats:input_type -> analytics.GetChannelStatsRequest
-	10, // 25: analytics.AnalyticsService.GetMerchantStats:input_type -> analytics.GetMerchantStatsRequest
-	13, // 26: analytics.AnalyticsService.GetTopMerchants:input_type -> analytics.GetTopMerchantsRequest
-	16, // 27: analytics.AnalyticsService.GetRealtimeMetrics:input_type -> analytics.GetRealtimeMetricsRequest
-	19, // 28: analytics.AnalyticsService.GetSystemHealth:input_type -> analytics.GetSystemHealthRequest
-	22, // 29: analytics.AnalyticsService.GenerateReport:input_type -> analytics.GenerateReportRequest
-	24, // 30: analytics.AnalyticsService.ListReports:input_type -> analytics.ListReportsRequest
-	2,  // 31: analytics.AnalyticsService.GetPaymentStats:output_type -> analytics.PaymentStatsResponse
-	5,  // 32: analytics.AnalyticsService.GetPaymentTrends:output_type -> analytics.PaymentTrendsResponse
-	8,  // 33: analytics.AnalyticsService.GetChannelStats:output_type -> analytics.ChannelStatsResponse
-	11, // 34: analytics.AnalyticsService.GetMerchantStats:output_type -> analytics.MerchantStatsResponse
-	14, // 35: analytics.AnalyticsService.GetTopMerchants:output_type -> analytics.TopMerchantsResponse
-	17, // 36: analytics.AnalyticsService.GetRealtimeMetrics:output_type -> analytics.RealtimeMetricsResponse
-	20, // 37: analytics.AnalyticsService.GetSystemHealth:output_type -> analytics.SystemHealthResponse
-	23, // 38: analytics.AnalyticsService.GenerateReport:output_type -> analytics.ReportResponse
-	25, // 39: analytics.AnalyticsService.ListReports:output_type -> analytics.ListReportsResponse
-	31, // [31:40] is the sub-list for method output_type
-	22, // [22:31] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	32, // 22: analytics.QueryMetricsRequest.start_time:type_name -> google.protobuf.Timestamp
+	32, // 23: analytics.QueryMetricsRequest.end_time:type_name -> google.protobuf.Timestamp
+	29, // 24: analytics.QueryMetricsRequest.filters:type_name -> analytics.QueryMetricsRequest.FiltersEntry
+	32, // 25: analytics.MetricRow.bucket:type_name -> google.protobuf.Timestamp
+	30, // 26: analytics.MetricRow.dimensions:type_name -> analytics.MetricRow.DimensionsEntry
+	31, // 27: analytics.MetricRow.values:type_name -> analytics.MetricRow.ValuesEntry
+	27, // 28: analytics.QueryMetricsResponse.rows:type_name -> analytics.MetricRow
+	1,  // 29: analytics.AnalyticsService.GetPaymentStats:input_type -> analytics.GetPaymentStatsRequest
+	4,  // 30: analytics.AnalyticsService.GetPaymentTrends:input_type -> analytics.GetPaymentTrendsRequest
+	7,  // 31: analytics.AnalyticsService.GetChannelStats:input_type -> analytics.GetChannelStatsRequest
+	10, // 32: analytics.AnalyticsService.GetMerchantStats:input_type -> analytics.GetMerchantStatsRequest
+	13, // 33: analytics.AnalyticsService.GetTopMerchants:input_type -> analytics.GetTopMerchantsRequest
+	16, // 34: analytics.AnalyticsService.GetRealtimeMetrics:input_type -> analytics.GetRealtimeMetricsRequest
+	19, // 35: analytics.AnalyticsService.GetSystemHealth:input_type -> analytics.GetSystemHealthRequest
+	22, // 36: analytics.AnalyticsService.GenerateReport:input_type -> analytics.GenerateReportRequest
+	24, // 37: analytics.AnalyticsService.ListReports:input_type -> analytics.ListReportsRequest
+	26, // 38: analytics.AnalyticsService.QueryMetrics:input_type -> analytics.QueryMetricsRequest
+	2,  // 39: analytics.AnalyticsService.GetPaymentStats:output_type -> analytics.PaymentStatsResponse
+	5,  // 40: analytics.AnalyticsService.GetPaymentTrends:output_type -> analytics.PaymentTrendsResponse
+	8,  // 41: analytics.AnalyticsService.GetChannelStats:output_type -> analytics.ChannelStatsResponse
+	11, // 42: analytics.AnalyticsService.GetMerchantStats:output_type -> analytics.MerchantStatsResponse
+	14, // 43: analytics.AnalyticsService.GetTopMerchants:output_type -> analytics.TopMerchantsResponse
+	17, // 44: analytics.AnalyticsService.GetRealtimeMetrics:output_type -> analytics.RealtimeMetricsResponse
+	20, // 45: analytics.AnalyticsService.GetSystemHealth:output_type -> analytics.SystemHealthResponse
+	23, // 46: analytics.AnalyticsService.GenerateReport:output_type -> analytics.ReportResponse
+	25, // 47: analytics.AnalyticsService.ListReports:output_type -> analytics.ListReportsResponse
+	28, // 48: analytics.AnalyticsService.QueryMetrics:output_type -> analytics.QueryMetricsResponse
+	39, // [39:49] is the sub-list for method output_type
+	29, // [29:39] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_proto_analytics_analytics_proto_init() }
@@ -1880,7 +2140,7 @@ func file_proto_analytics_analytics_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_analytics_analytics_proto_rawDesc), len(file_proto_analytics_analytics_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   26,
+			NumMessages:   32,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -28,6 +28,7 @@ const (
 	AnalyticsService_GetSystemHealth_FullMethodName    = "/analytics.AnalyticsService/GetSystemHealth"
 	AnalyticsService_GenerateReport_FullMethodName     = "/analytics.AnalyticsService/GenerateReport"
 	AnalyticsService_ListReports_FullMethodName        = "/analytics.AnalyticsService/ListReports"
+	AnalyticsService_QueryMetrics_FullMethodName       = "/analytics.AnalyticsService/QueryMetrics"
 )
 
 // AnalyticsServiceClient is the client API for AnalyticsService service.
@@ -49,6 +50,8 @@ type AnalyticsServiceClient interface {
 	// 自定义报表
 	GenerateReport(ctx context.Context, in *GenerateReportRequest, opts ...grpc.CallOption) (*ReportResponse, error)
 	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error)
+	// 多维指标查询
+	QueryMetrics(ctx context.Context, in *QueryMetricsRequest, opts ...grpc.CallOption) (*QueryMetricsResponse, error)
 }
 
 type analyticsServiceClient struct {
@@ -149,6 +152,16 @@ func (c *analyticsServiceClient) ListReports(ctx context.Context, in *ListReport
 	return out, nil
 }
 
+func (c *analyticsServiceClient) QueryMetrics(ctx context.Context, in *QueryMetricsRequest, opts ...grpc.CallOption) (*QueryMetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryMetricsResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_QueryMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AnalyticsServiceServer is the server API for AnalyticsService service.
 // All implementations must embed UnimplementedAnalyticsServiceServer
 // for forward compatibility.
@@ -168,6 +181,8 @@ type AnalyticsServiceServer interface {
 	// 自定义报表
 	GenerateReport(context.Context, *GenerateReportRequest) (*ReportResponse, error)
 	ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error)
+	// 多维指标查询
+	QueryMetrics(context.Context, *QueryMetricsRequest) (*QueryMetricsResponse, error)
 	mustEmbedUnimplementedAnalyticsServiceServer()
 }
 
@@ -205,6 +220,9 @@ func (UnimplementedAnalyticsServiceServer) GenerateReport(context.Context, *Gene
 func (UnimplementedAnalyticsServiceServer) ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
 }
+func (UnimplementedAnalyticsServiceServer) QueryMetrics(context.Context, *QueryMetricsRequest) (*QueryMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryMetrics not implemented")
+}
 func (UnimplementedAnalyticsServiceServer) mustEmbedUnimplementedAnalyticsServiceServer() {}
 func (UnimplementedAnalyticsServiceServer) testEmbeddedByValue()                          {}
 
@@ -388,6 +406,24 @@ func _AnalyticsService_ListReports_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AnalyticsService_QueryMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).QueryMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_QueryMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).QueryMetrics(ctx, req.(*QueryMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AnalyticsService_ServiceDesc is the grpc.ServiceDesc for AnalyticsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -431,6 +467,10 @@ var AnalyticsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListReports",
 			Handler:    _AnalyticsService_ListReports_Handler,
 		},
+		{
+			MethodName: "QueryMetrics",
+			Handler:    _AnalyticsService_QueryMetrics_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/analytics/analytics.proto",
@@ -11,8 +11,10 @@ import (
 	"github.com/payment-platform/pkg/auth"
 	"github.com/payment-platform/pkg/config"
 	"github.com/payment-platform/pkg/configclient"
+	exportpkg "github.com/payment-platform/pkg/export"
 	"github.com/payment-platform/pkg/kafka"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/tax"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -88,11 +90,15 @@ func main() {
 		DBName:      config.GetEnv("DB_NAME", "payment_accounting"),
 		Port:        config.GetEnvInt("PORT", 40007),
 
-		// 自动迁移数据库模型（仅核心账户模型）
+		// 自动迁移数据库模型（核心账户模型和账单）
 		AutoMigrate: []any{
 			&model.Account{},
 			&model.AccountTransaction{},
 			&model.DoubleEntry{},
+			&model.Invoice{},
+			&model.InvoiceItem{},
+			&model.InvoiceTaxLine{},
+			&exportpkg.ExportTask{}, // 账单PDF文件登记
 		},
 
 		// 启用企业级功能
@@ -118,20 +124,45 @@ func main() {
 	channelAdapterClient := client.NewChannelAdapterClient(channelAdapterURL)
 	logger.Info(fmt.Sprintf("渠道适配器客户端初始化: %s", channelAdapterURL))
 
+	cashierServiceURL := getConfig("CASHIER_SERVICE_URL", "http://localhost:40016")
+	cashierClient := client.NewCashierClient(cashierServiceURL)
+	logger.Info(fmt.Sprintf("收银台客户端初始化: %s", cashierServiceURL))
+
 	// 3. 初始化Repository
 	accountRepo := repository.NewAccountRepository(application.DB)
 
 	// 4. 初始化Service（传入 application.DB 用于事务支持）
 	accountService := service.NewAccountService(application.DB, accountRepo, channelAdapterClient)
+	if ts, ok := accountService.(interface{ SetTaxEngine(tax.Engine, string) }); ok {
+		// 账单计税使用内置税率表，开票方所在国家用于判断跨境反向征收
+		ts.SetTaxEngine(tax.NewRuleEngine(tax.DefaultRules()), getConfig("INVOICE_ISSUER_COUNTRY", ""))
+	}
+
+	// 账单PDF（保存在导出存储中，开票方信息来自配置）
+	exportStorageDir := getConfig("EXPORT_STORAGE_DIR", "/home/eric/payment/backend/exports")
+	invoiceDocumentService := service.NewInvoiceDocumentService(
+		accountRepo,
+		exportpkg.NewExportService(application.DB, application.Redis, exportStorageDir),
+		cashierClient,
+		service.InvoiceIssuer{
+			Name:       getConfig("INVOICE_ISSUER_NAME", "Payment Platform"),
+			Address:    getConfig("INVOICE_ISSUER_ADDRESS", ""),
+			TaxID:      getConfig("INVOICE_ISSUER_TAX_ID", ""),
+			Email:      getConfig("INVOICE_ISSUER_EMAIL", ""),
+			ThemeColor: getConfig("INVOICE_THEME_COLOR", "#1890ff"),
+		},
+	)
 
 	// 5. 初始化Handler
 	accountHandler := handler.NewAccountHandler(accountService)
+	invoiceDocumentHandler := handler.NewInvoiceDocumentHandler(invoiceDocumentService)
 
 	// 6. Swagger UI
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// 7. 注册账户路由
 	accountHandler.RegisterRoutes(application.Router)
+	invoiceDocumentHandler.RegisterRoutes(application.Router)
 
 	// 8. 初始化Kafka (事件驱动架构，优先从配置中心获取)
 	var kafkaBrokers []string
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/httpclient"
+)
+
+// CashierClient Cashier Service HTTP客户端（用于获取商户品牌信息）
+type CashierClient struct {
+	baseURL string
+	breaker *httpclient.BreakerClient
+}
+
+// NewCashierClient 创建Cashier客户端实例（带熔断器）
+func NewCashierClient(baseURL string) *CashierClient {
+	config := &httpclient.Config{
+		Timeout:    5 * time.Second,
+		MaxRetries: 2,
+		RetryDelay: 500 * time.Millisecond,
+	}
+
+	breakerConfig := httpclient.DefaultBreakerConfig("cashier-service")
+
+	return &CashierClient{
+		baseURL: baseURL,
+		breaker: httpclient.NewBreakerClient(config, breakerConfig),
+	}
+}
+
+// MerchantBranding 商户品牌信息
+type MerchantBranding struct {
+	BrandName        string   `json:"brand_name"`
+	ThemeColor       string   `json:"theme_color"`
+	LogoURL          string   `json:"logo_url"`
+	DefaultLanguage  string   `json:"default_language"`
+	EnabledLanguages []string `json:"enabled_languages"`
+}
+
+// GetBrandingAPIResponse API响应封装
+type GetBrandingAPIResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    MerchantBranding `json:"data"`
+}
+
+// GetBranding 获取商户品牌信息（使用熔断器）
+func (c *CashierClient) GetBranding(ctx context.Context, merchantID uuid.UUID) (*MerchantBranding, error) {
+	url := fmt.Sprintf("%s/api/v1/cashier/branding/%s", c.baseURL, merchantID.String())
+
+	req := &httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Ctx:    ctx,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}
+
+	resp, err := c.breaker.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求商户品牌信息失败: %w", err)
+	}
+
+	var result GetBrandingAPIResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析商户品牌信息失败: %w", err)
+	}
+
+	if resp.StatusCode != 200 || result.Code != 0 {
+		return nil, fmt.Errorf("获取商户品牌信息失败: %s (status %d)", result.Message, resp.StatusCode)
+	}
+
+	return &result.Data, nil
+}
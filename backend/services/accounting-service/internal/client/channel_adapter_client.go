@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/money"
 )
 
 // ChannelAdapterClient Channel Adapter HTTP客户端（用于汇率查询）
 type ChannelAdapterClient struct {
-	baseURL string
-	breaker *httpclient.BreakerClient
+	baseURL      string
+	breaker      *httpclient.BreakerClient
+	serviceToken string // 服务间调用令牌（INTERNAL_SERVICE_TOKEN），访问 channel-adapter /api/v1/fx 路由
 }
 
 // NewChannelAdapterClient 创建Channel Adapter客户端实例（带熔断器）
@@ -28,18 +33,20 @@ func NewChannelAdapterClient(baseURL string) *ChannelAdapterClient {
 	breakerConfig := httpclient.DefaultBreakerConfig("channel-adapter-service")
 
 	return &ChannelAdapterClient{
-		baseURL: baseURL,
-		breaker: httpclient.NewBreakerClient(config, breakerConfig),
+		baseURL:      baseURL,
+		breaker:      httpclient.NewBreakerClient(config, breakerConfig),
+		serviceToken: os.Getenv("INTERNAL_SERVICE_TOKEN"),
 	}
 }
 
 // ExchangeRateResponse 汇率响应
 type ExchangeRateResponse struct {
-	BaseCurrency   string    `json:"base_currency"`
-	TargetCurrency string    `json:"target_currency"`
-	Rate           float64   `json:"rate"`
-	Source         string    `json:"source"`
-	ValidFrom      time.Time `json:"valid_from"`
+	BaseCurrency   string        `json:"base_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	Rate           money.Decimal `json:"rate"`
+	Source         string        `json:"source"`
+	ValidFrom      time.Time     `json:"valid_from"`
+	SnapshotID     string        `json:"snapshot_id"`
 }
 
 // GetExchangeRateAPIResponse API响应封装
@@ -49,8 +56,8 @@ type GetExchangeRateAPIResponse struct {
 	Data    ExchangeRateResponse `json:"data"`
 }
 
-// GetExchangeRate 获取实时汇率（使用熔断器）
-func (c *ChannelAdapterClient) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+// GetExchangeRate 获取市场中间价（使用熔断器），汇率数据过期时返回错误
+func (c *ChannelAdapterClient) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (money.Decimal, error) {
 	url := fmt.Sprintf("%s/api/v1/exchange-rates/latest?from=%s&to=%s", c.baseURL, fromCurrency, toCurrency)
 
 	// 创建请求
@@ -66,17 +73,17 @@ func (c *ChannelAdapterClient) GetExchangeRate(ctx context.Context, fromCurrency
 	// 通过熔断器发送请求
 	resp, err := c.breaker.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("请求汇率API失败: %w", err)
+		return money.Decimal{}, fmt.Errorf("请求汇率API失败: %w", err)
 	}
 
 	// 解析响应
 	var result GetExchangeRateAPIResponse
 	if err := json.Unmarshal(resp.Body, &result); err != nil {
-		return 0, fmt.Errorf("解析汇率响应失败: %w", err)
+		return money.Decimal{}, fmt.Errorf("解析汇率响应失败: %w", err)
 	}
 
 	if result.Code != 0 {
-		return 0, fmt.Errorf("汇率API错误: %s", result.Message)
+		return money.Decimal{}, fmt.Errorf("汇率API错误: %s", result.Message)
 	}
 
 	return result.Data.Rate, nil
@@ -129,3 +136,100 @@ func (c *ChannelAdapterClient) GetExchangeRateSnapshot(ctx context.Context, base
 
 	return result.Data.Rates, nil
 }
+
+// FXQuote 换汇报价
+type FXQuote struct {
+	QuoteID        string        `json:"quote_id"`
+	MerchantID     uuid.UUID     `json:"merchant_id"`
+	BaseCurrency   string        `json:"base_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	MidRate        money.Decimal `json:"mid_rate"`   // 报价时市场中间价
+	MarkupBps      int           `json:"markup_bps"` // 加点（基点）
+	Rate           money.Decimal `json:"rate"`       // 成交汇率
+	BaseAmount     int64         `json:"base_amount"`
+	TargetAmount   int64         `json:"target_amount"`
+	SnapshotID     string        `json:"snapshot_id"` // 汇率快照ID
+	RateSource     string        `json:"rate_source"`
+	RateTime       time.Time     `json:"rate_time"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	Status         string        `json:"status"`
+	Reference      string        `json:"reference"`
+}
+
+// CreateFXQuoteRequest 创建换汇报价请求
+type CreateFXQuoteRequest struct {
+	MerchantID     uuid.UUID `json:"merchant_id"`
+	BaseCurrency   string    `json:"base_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	BaseAmount     int64     `json:"base_amount"`
+}
+
+// LockFXQuoteRequest 锁定换汇报价请求
+type LockFXQuoteRequest struct {
+	MerchantID     uuid.UUID `json:"merchant_id"`
+	Reference      string    `json:"reference"` // 转换单号
+	BaseCurrency   string    `json:"base_currency"`
+	TargetCurrency string    `json:"target_currency"`
+}
+
+// ReleaseFXQuoteRequest 释放换汇报价请求
+type ReleaseFXQuoteRequest struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	Reference  string    `json:"reference"` // 转换单号
+}
+
+// fxQuoteAPIResponse 报价API响应
+type fxQuoteAPIResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    *FXQuote `json:"data"`
+}
+
+// CreateFXQuote 创建换汇报价（使用熔断器）
+func (c *ChannelAdapterClient) CreateFXQuote(ctx context.Context, req *CreateFXQuoteRequest) (*FXQuote, error) {
+	return c.doFXQuote(ctx, fmt.Sprintf("%s/api/v1/fx/quotes", c.baseURL), req, "创建换汇报价")
+}
+
+// LockFXQuote 将换汇报价锁定到货币转换（使用熔断器）
+func (c *ChannelAdapterClient) LockFXQuote(ctx context.Context, quoteID string, req *LockFXQuoteRequest) (*FXQuote, error) {
+	return c.doFXQuote(ctx, fmt.Sprintf("%s/api/v1/fx/quotes/%s/lock", c.baseURL, quoteID), req, "锁定换汇报价")
+}
+
+// ReleaseFXQuote 释放货币转换锁定的换汇报价（使用熔断器）
+func (c *ChannelAdapterClient) ReleaseFXQuote(ctx context.Context, quoteID string, req *ReleaseFXQuoteRequest) (*FXQuote, error) {
+	return c.doFXQuote(ctx, fmt.Sprintf("%s/api/v1/fx/quotes/%s/release", c.baseURL, quoteID), req, "释放换汇报价")
+}
+
+func (c *ChannelAdapterClient) doFXQuote(ctx context.Context, url string, body interface{}, action string) (*FXQuote, error) {
+	headers := middleware.ServiceAuthHeaders("accounting-service", c.serviceToken)
+	headers["Content-Type"] = "application/json"
+	req := &httpclient.Request{
+		Method:  "POST",
+		URL:     url,
+		Body:    body,
+		Ctx:     ctx,
+		Headers: headers,
+	}
+
+	resp, err := c.breaker.Do(req)
+
+	// 业务错误（报价过期、汇率过期等）以非2xx返回，优先使用响应中的错误信息
+	var result fxQuoteAPIResponse
+	if resp != nil && len(resp.Body) > 0 {
+		if jsonErr := json.Unmarshal(resp.Body, &result); jsonErr != nil && err == nil {
+			return nil, fmt.Errorf("解析%s响应失败: %w", action, jsonErr)
+		}
+	}
+	if err != nil {
+		if result.Message != "" {
+			return nil, fmt.Errorf("%s失败: %s", action, result.Message)
+		}
+		return nil, fmt.Errorf("请求%s失败: %w", action, err)
+	}
+
+	if result.Code != 0 || result.Data == nil {
+		return nil, fmt.Errorf("%s失败: %s", action, result.Message)
+	}
+
+	return result.Data, nil
+}
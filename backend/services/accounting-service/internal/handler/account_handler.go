@@ -43,6 +43,7 @@ func (h *AccountHandler) RegisterRoutes(router *gin.Engine) {
 		transactions := v1.Group("/transactions")
 		{
 			transactions.POST("", h.CreateTransaction)
+			transactions.GET("/split-recipients", h.ListSplitRecipients)
 			transactions.GET("/:transactionNo", h.GetTransaction)
 			transactions.GET("", h.ListTransactions)
 			transactions.POST("/:transactionNo/reverse", h.ReverseTransaction)
@@ -106,6 +107,7 @@ func (h *AccountHandler) RegisterRoutes(router *gin.Engine) {
 		conversions := v1.Group("/conversions")
 		{
 			conversions.POST("", h.CreateCurrencyConversion)
+			conversions.GET("/fx-gain-loss", h.GetFXGainLossReport)
 			conversions.GET("/:conversionNo", h.GetCurrencyConversion)
 			conversions.GET("", h.ListCurrencyConversions)
 			conversions.POST("/:conversionNo/process", h.ProcessCurrencyConversion)
@@ -410,6 +412,43 @@ func (h *AccountHandler) ListTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// ListSplitRecipients 查询有分账交易的收款方商户
+func (h *AccountHandler) ListSplitRecipients(c *gin.Context) {
+	var startTime, endTime *time.Time
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的开始日期", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		startTime = &startDate
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的结束日期", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		endTime = &endDate
+	}
+
+	merchantIDs, err := h.accountService.ListSplitRecipients(c.Request.Context(), startTime, endTime)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询分账收款方失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(gin.H{"merchant_ids": merchantIDs}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
 // ReverseTransaction 冲正交易
 func (h *AccountHandler) ReverseTransaction(c *gin.Context) {
 	transactionNo := c.Param("transactionNo")
@@ -1582,6 +1621,51 @@ func (h *AccountHandler) ListCurrencyConversions(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GetFXGainLossReport 汇兑损益报表
+func (h *AccountHandler) GetFXGainLossReport(c *gin.Context) {
+	query := &service.FXGainLossQuery{}
+
+	if merchantIDStr := c.Query("merchant_id"); merchantIDStr != "" {
+		merchantID, err := uuid.Parse(merchantIDStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		query.MerchantID = &merchantID
+	}
+
+	startTime, err := time.Parse(time.RFC3339, c.Query("start_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "开始时间格式错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, c.Query("end_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "结束时间格式错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	query.StartTime = startTime
+	query.EndTime = endTime
+
+	report, err := h.accountService.GetFXGainLossReport(c.Request.Context(), query)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询汇兑损益失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(report).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
 // ProcessCurrencyConversion 处理货币转换
 func (h *AccountHandler) ProcessCurrencyConversion(c *gin.Context) {
 	conversionNo := c.Param("conversionNo")
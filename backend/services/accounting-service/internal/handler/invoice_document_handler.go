@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"payment-platform/accounting-service/internal/service"
+)
+
+// InvoiceDocumentHandler 账单文档处理器
+type InvoiceDocumentHandler struct {
+	documentService service.InvoiceDocumentService
+}
+
+// NewInvoiceDocumentHandler 创建账单文档处理器实例
+func NewInvoiceDocumentHandler(documentService service.InvoiceDocumentService) *InvoiceDocumentHandler {
+	return &InvoiceDocumentHandler{documentService: documentService}
+}
+
+// RegisterRoutes 注册路由
+func (h *InvoiceDocumentHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/v1/invoices/:invoiceNo/pdf", h.DownloadInvoicePDF)
+}
+
+// DownloadInvoicePDF 下载账单 PDF
+//
+//	@Summary		下载账单PDF
+//	@Tags			Invoices
+//	@Produce		application/pdf
+//	@Param			invoiceNo	path		string	true	"账单号"
+//	@Param			merchant_id	query		string	false	"商户ID（传入时校验账单归属）"
+//	@Param			lang		query		string	false	"语言（en, zh-CN, zh-TW, ja, ko），默认使用商户收银台语言"
+//	@Success		200			{file}		binary
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/api/v1/invoices/{invoiceNo}/pdf [get]
+func (h *InvoiceDocumentHandler) DownloadInvoicePDF(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+
+	var merchantID uuid.UUID
+	if merchantIDStr := c.Query("merchant_id"); merchantIDStr != "" {
+		var err error
+		if merchantID, err = uuid.Parse(merchantIDStr); err != nil {
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+	}
+
+	doc, err := h.documentService.GetInvoicePDF(c.Request.Context(), c.Param("invoiceNo"), merchantID, c.Query("lang"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeResourceNotFound, "获取账单PDF失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusNotFound, resp)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.FileName))
+	c.Data(http.StatusOK, "application/pdf", doc.Content)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -120,6 +121,11 @@ const (
 	TransactionTypeWithdraw   = "withdraw"    // 提现
 	TransactionTypeFee        = "fee"         // 手续费
 	TransactionTypeAdjustment = "adjustment"  // 调账
+
+	// 平台分账（按收款方分别记账，结算服务据此为子商户单独结算）
+	TransactionTypeSplitIn       = "split_in"       // 分账入账
+	TransactionTypeSplitReversal = "split_reversal" // 分账冲正（退款）
+	TransactionTypeChargeback    = "chargeback"     // 拒付出账
 )
 
 // 结算状态常量
@@ -191,6 +197,9 @@ type Invoice struct {
 	PaidAt          *time.Time     `gorm:"type:timestamptz" json:"paid_at"`                                // 支付时间
 	Notes           string         `gorm:"type:text" json:"notes"`                                         // 备注
 	Items           []InvoiceItem  `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`                    // 账单明细
+	TaxLines        []InvoiceTaxLine `gorm:"foreignKey:InvoiceID" json:"tax_lines,omitempty"`             // 税费明细（计税引擎计税时生成）
+	BuyerTaxID      string         `gorm:"type:varchar(64)" json:"buyer_tax_id,omitempty"`                 // 买方税号
+	ReverseCharge   bool           `gorm:"default:false" json:"reverse_charge"`                            // 是否反向征收
 	CreatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -212,6 +221,8 @@ type InvoiceItem struct {
 	Amount      int64          `gorm:"type:bigint;not null" json:"amount"`                     // 金额（分）
 	RelatedID   *uuid.UUID     `gorm:"type:uuid" json:"related_id"`                            // 关联ID
 	RelatedNo   string         `gorm:"type:varchar(64)" json:"related_no"`                     // 关联单号
+	TaxCategory string         `gorm:"type:varchar(20)" json:"tax_category,omitempty"`         // 税务类别
+	TaxAmount   int64          `gorm:"type:bigint;default:0" json:"tax_amount"`                // 税额（分）
 	CreatedAt   time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -221,6 +232,28 @@ func (InvoiceItem) TableName() string {
 	return "invoice_items"
 }
 
+// InvoiceTaxLine 账单税费明细（每个账单明细一行）
+type InvoiceTaxLine struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	InvoiceID     uuid.UUID     `gorm:"type:uuid;not null;index" json:"invoice_id"`       // 账单ID
+	InvoiceItemID *uuid.UUID    `gorm:"type:uuid;index" json:"invoice_item_id,omitempty"` // 账单明细ID
+	Category      string        `gorm:"type:varchar(20);not null" json:"category"`        // 税务类别
+	Country       string        `gorm:"type:varchar(2)" json:"country"`                   // 税务管辖国家
+	Region        string        `gorm:"type:varchar(10)" json:"region"`                   // 税务管辖州/省
+	TaxName       string        `gorm:"type:varchar(50)" json:"tax_name"`                 // 税种名称
+	Rate          money.Decimal `gorm:"type:decimal(8,4);default:0" json:"rate"`          // 税率（百分比）
+	TaxableAmount int64         `gorm:"type:bigint;not null" json:"taxable_amount"`       // 计税金额（不含税，分）
+	TaxAmount     int64         `gorm:"type:bigint;not null;default:0" json:"tax_amount"` // 税额（分）
+	ReverseCharge bool          `gorm:"default:false" json:"reverse_charge"`              // 是否反向征收
+	Currency      string        `gorm:"type:varchar(10);not null" json:"currency"`        // 货币类型
+	CreatedAt     time.Time     `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (InvoiceTaxLine) TableName() string {
+	return "invoice_tax_lines"
+}
+
 // 账单状态常量
 const (
 	InvoiceStatusDraft     = "draft"     // 草稿
@@ -338,9 +371,16 @@ type CurrencyConversion struct {
 	TargetCurrency     string         `gorm:"type:varchar(10);not null" json:"target_currency"`                   // 目标货币
 	SourceAmount       int64          `gorm:"type:bigint;not null" json:"source_amount"`                          // 源货币金额（分）
 	TargetAmount       int64          `gorm:"type:bigint;not null" json:"target_amount"`                          // 目标货币金额（分）
-	ExchangeRate       float64        `gorm:"type:decimal(20,8);not null" json:"exchange_rate"`                   // 汇率
+	ExchangeRate       money.Decimal  `gorm:"type:decimal(24,10);not null" json:"exchange_rate"`                  // 成交汇率（锁定报价的汇率）
+	FXQuoteID          string         `gorm:"type:varchar(64);index" json:"fx_quote_id"`                          // 锁定的换汇报价编号
+	MidRate            money.Decimal  `gorm:"type:decimal(24,10);default:0" json:"mid_rate"`                    // 报价时市场中间价
+	MarkupBps          int            `gorm:"type:integer;default:0" json:"markup_bps"`                           // 报价加点（基点）
+	MarketRate         money.Decimal  `gorm:"type:decimal(24,10);default:0" json:"market_rate"`                 // 成交时市场中间价（汇兑损益基准）
+	RateSnapshotID     string         `gorm:"type:varchar(36)" json:"rate_snapshot_id"`                           // 报价使用的汇率快照ID
+	RateSource         string         `gorm:"type:varchar(50)" json:"rate_source"`                                // 汇率来源
+	RateTime           *time.Time     `gorm:"type:timestamptz" json:"rate_time"`                                  // 汇率快照时间
 	FeeAmount          int64          `gorm:"type:bigint;default:0" json:"fee_amount"`                            // 手续费（分，以源货币计）
-	FeePercentage      float64        `gorm:"type:decimal(5,4);default:0" json:"fee_percentage"`                  // 手续费率（如0.005表示0.5%）
+	FeePercentage      money.Decimal  `gorm:"type:decimal(5,4);default:0" json:"fee_percentage"`                  // 手续费率（如0.005表示0.5%）
 	Status             string         `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`          // 状态：pending, completed, failed, cancelled
 	SourceTransactionNo string        `gorm:"type:varchar(64);index" json:"source_transaction_no"`                // 源账户交易流水号
 	TargetTransactionNo string        `gorm:"type:varchar(64);index" json:"target_transaction_no"`                // 目标账户交易流水号
@@ -25,6 +25,7 @@ type AccountRepository interface {
 	CreateTransaction(ctx context.Context, tx *model.AccountTransaction) error
 	GetTransactionByNo(ctx context.Context, transactionNo string) (*model.AccountTransaction, error)
 	ListTransactions(ctx context.Context, query *TransactionQuery) ([]*model.AccountTransaction, int64, error)
+	ListMerchantsByTransactionTypes(ctx context.Context, transactionTypes []string, startTime, endTime *time.Time) ([]uuid.UUID, error)
 
 	// 结算管理
 	CreateSettlement(ctx context.Context, settlement *model.Settlement) error
@@ -47,6 +48,7 @@ type AccountRepository interface {
 	// 账单管理
 	CreateInvoice(ctx context.Context, invoice *model.Invoice) error
 	CreateInvoiceItem(ctx context.Context, item *model.InvoiceItem) error
+	CreateInvoiceTaxLines(ctx context.Context, lines []*model.InvoiceTaxLine) error
 	GetInvoiceByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error)
 	GetInvoiceByNo(ctx context.Context, invoiceNo string) (*model.Invoice, error)
 	ListInvoices(ctx context.Context, query *InvoiceQuery) ([]*model.Invoice, int64, error)
@@ -69,6 +71,7 @@ type AccountRepository interface {
 	GetCurrencyConversionByNo(ctx context.Context, conversionNo string) (*model.CurrencyConversion, error)
 	ListCurrencyConversions(ctx context.Context, query *CurrencyConversionQuery) ([]*model.CurrencyConversion, int64, error)
 	UpdateCurrencyConversion(ctx context.Context, conversion *model.CurrencyConversion) error
+	ListCompletedFXConversions(ctx context.Context, merchantID *uuid.UUID, startTime, endTime time.Time) ([]*model.CurrencyConversion, error)
 }
 
 type accountRepository struct {
@@ -95,6 +98,7 @@ type TransactionQuery struct {
 	AccountID       *uuid.UUID
 	MerchantID      *uuid.UUID
 	TransactionType string
+	RelatedNo       string
 	Currency        string
 	StartTime       *time.Time
 	EndTime         *time.Time
@@ -303,6 +307,9 @@ func (r *accountRepository) ListTransactions(ctx context.Context, query *Transac
 	if query.TransactionType != "" {
 		db = db.Where("transaction_type = ?", query.TransactionType)
 	}
+	if query.RelatedNo != "" {
+		db = db.Where("related_no = ?", query.RelatedNo)
+	}
 	if query.Currency != "" {
 		db = db.Where("currency = ?", query.Currency)
 	}
@@ -322,6 +329,23 @@ func (r *accountRepository) ListTransactions(ctx context.Context, query *Transac
 	return transactions, total, err
 }
 
+// ListMerchantsByTransactionTypes 查询时间范围内有指定类型交易的商户
+func (r *accountRepository) ListMerchantsByTransactionTypes(ctx context.Context, transactionTypes []string, startTime, endTime *time.Time) ([]uuid.UUID, error) {
+	var merchantIDs []uuid.UUID
+
+	db := r.db.WithContext(ctx).Model(&model.AccountTransaction{}).
+		Where("transaction_type IN ?", transactionTypes)
+	if startTime != nil {
+		db = db.Where("created_at >= ?", *startTime)
+	}
+	if endTime != nil {
+		db = db.Where("created_at < ?", *endTime)
+	}
+
+	err := db.Distinct("merchant_id").Pluck("merchant_id", &merchantIDs).Error
+	return merchantIDs, err
+}
+
 // CreateSettlement 创建结算记录
 func (r *accountRepository) CreateSettlement(ctx context.Context, settlement *model.Settlement) error {
 	return r.db.WithContext(ctx).Create(settlement).Error
@@ -504,10 +528,18 @@ func (r *accountRepository) CreateInvoiceItem(ctx context.Context, item *model.I
 	return r.db.WithContext(ctx).Create(item).Error
 }
 
+// CreateInvoiceTaxLines 批量创建账单税费明细
+func (r *accountRepository) CreateInvoiceTaxLines(ctx context.Context, lines []*model.InvoiceTaxLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(lines).Error
+}
+
 // GetInvoiceByID 根据ID获取账单
 func (r *accountRepository) GetInvoiceByID(ctx context.Context, id uuid.UUID) (*model.Invoice, error) {
 	var invoice model.Invoice
-	err := r.db.WithContext(ctx).Preload("Items").First(&invoice, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("Items").Preload("TaxLines").First(&invoice, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -520,7 +552,7 @@ func (r *accountRepository) GetInvoiceByID(ctx context.Context, id uuid.UUID) (*
 // GetInvoiceByNo 根据账单号获取账单
 func (r *accountRepository) GetInvoiceByNo(ctx context.Context, invoiceNo string) (*model.Invoice, error) {
 	var invoice model.Invoice
-	err := r.db.WithContext(ctx).Preload("Items").First(&invoice, "invoice_no = ?", invoiceNo).Error
+	err := r.db.WithContext(ctx).Preload("Items").Preload("TaxLines").First(&invoice, "invoice_no = ?", invoiceNo).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -735,3 +767,19 @@ func (r *accountRepository) ListCurrencyConversions(ctx context.Context, query *
 func (r *accountRepository) UpdateCurrencyConversion(ctx context.Context, conversion *model.CurrencyConversion) error {
 	return r.db.WithContext(ctx).Save(conversion).Error
 }
+
+// ListCompletedFXConversions 查询时间范围内已完成且锁定了报价的货币转换（汇兑损益统计）
+func (r *accountRepository) ListCompletedFXConversions(ctx context.Context, merchantID *uuid.UUID, startTime, endTime time.Time) ([]*model.CurrencyConversion, error) {
+	var conversions []*model.CurrencyConversion
+
+	db := r.db.WithContext(ctx).
+		Where("status = ?", model.ConversionStatusCompleted).
+		Where("fx_quote_id <> ''").
+		Where("processed_at >= ? AND processed_at < ?", startTime, endTime)
+	if merchantID != nil {
+		db = db.Where("merchant_id = ?", *merchantID)
+	}
+
+	err := db.Order("processed_at").Find(&conversions).Error
+	return conversions, err
+}
@@ -5,10 +5,13 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/tax"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"payment-platform/accounting-service/internal/client"
@@ -30,6 +33,7 @@ type AccountService interface {
 	CreateTransaction(ctx context.Context, input *CreateTransactionInput) (*model.AccountTransaction, error)
 	GetTransaction(ctx context.Context, transactionNo string) (*model.AccountTransaction, error)
 	ListTransactions(ctx context.Context, query *repository.TransactionQuery) ([]*model.AccountTransaction, int64, error)
+	ListSplitRecipients(ctx context.Context, startTime, endTime *time.Time) ([]uuid.UUID, error)
 	ReverseTransaction(ctx context.Context, transactionNo string, reason string) error
 
 	// 复式记账
@@ -82,12 +86,21 @@ type AccountService interface {
 	ListCurrencyConversions(ctx context.Context, query *repository.CurrencyConversionQuery) ([]*model.CurrencyConversion, int64, error)
 	ProcessCurrencyConversion(ctx context.Context, conversionNo string) error
 	CancelCurrencyConversion(ctx context.Context, conversionNo string, reason string) error
+	GetFXGainLossReport(ctx context.Context, query *FXGainLossQuery) (*FXGainLossReport, error)
 }
 
 type accountService struct {
 	db                   *gorm.DB                      // 添加数据库连接，用于事务支持
 	accountRepo          repository.AccountRepository
 	channelAdapterClient *client.ChannelAdapterClient  // 汇率查询客户端（可选）
+	taxEngine            tax.Engine                    // 计税引擎（可选）
+	sellerCountry        string                        // 开票方所在国家（判断跨境反向征收）
+}
+
+// SetTaxEngine 设置账单计税引擎和开票方所在国家
+func (s *accountService) SetTaxEngine(engine tax.Engine, sellerCountry string) {
+	s.taxEngine = engine
+	s.sellerCountry = sellerCountry
 }
 
 // NewAccountService 创建账户服务实例
@@ -158,7 +171,8 @@ type CreateInvoiceInput struct {
 	PeriodEnd    time.Time           `json:"period_end" binding:"required"`
 	Currency     string              `json:"currency" binding:"required"`
 	DueDate      time.Time           `json:"due_date" binding:"required"`
-	TaxRate      float64             `json:"tax_rate"`                        // 税率（百分比）
+	TaxRate      money.Decimal       `json:"tax_rate"`                        // 统一税率（百分比），Tax 为空时生效
+	Tax          *InvoiceTaxInput    `json:"tax"`                             // 计税参数（按买方所在地的税率表计税）
 	Notes        string              `json:"notes"`
 	Items        []InvoiceItemInput  `json:"items" binding:"required,min=1"`
 }
@@ -171,6 +185,15 @@ type InvoiceItemInput struct {
 	UnitPrice   int64      `json:"unit_price" binding:"required,gt=0"`
 	RelatedID   *uuid.UUID `json:"related_id"`
 	RelatedNo   string     `json:"related_no"`
+	TaxCategory string     `json:"tax_category"` // 税务类别（默认 standard）
+}
+
+// InvoiceTaxInput 账单计税参数
+type InvoiceTaxInput struct {
+	BuyerCountry     string `json:"buyer_country" binding:"required,len=2"` // 买方所在国家
+	BuyerRegion      string `json:"buyer_region"`                           // 买方所在州/省
+	BuyerTaxID       string `json:"buyer_tax_id"`                           // 买方税号（跨境 B2B 反向征收）
+	PricesIncludeTax bool   `json:"prices_include_tax"`                     // 单价是否含税
 }
 
 // CreateReconciliationInput 创建对账单输入
@@ -204,6 +227,36 @@ type CreateCurrencyConversionInput struct {
 	Reason         string    `json:"reason"`                             // 转换原因
 	RequestedBy    uuid.UUID `json:"requested_by"`                       // 请求人ID
 	Notes          string    `json:"notes"`                              // 备注
+	FXQuoteID      string    `json:"fx_quote_id"`                        // 换汇报价编号（可选，未指定时自动报价）
+	FeeInclusive   bool      `json:"fee_inclusive"`                      // source_amount 是否为含手续费的出账总额（结算换汇使用）
+}
+
+// FXGainLossQuery 汇兑损益查询参数
+type FXGainLossQuery struct {
+	MerchantID *uuid.UUID
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// FXGainLossItem 按币种对汇总的汇兑损益（损益金额以目标币种最小单位计，正数为平台收益）
+type FXGainLossItem struct {
+	SourceCurrency string `json:"source_currency"`
+	TargetCurrency string `json:"target_currency"`
+	Count          int    `json:"count"`
+	SourceAmount   int64  `json:"source_amount"`   // 转换金额（源币种）
+	TargetAmount   int64  `json:"target_amount"`   // 实际入账金额（目标币种）
+	FeeAmount      int64  `json:"fee_amount"`      // 手续费（源币种）
+	MarkupGain     int64  `json:"markup_gain"`     // 加点收益：按报价中间价换算金额 - 实际入账
+	MarketMovement int64  `json:"market_movement"` // 汇率波动：按成交时中间价换算金额 - 按报价中间价换算金额
+	TotalGainLoss  int64  `json:"total_gain_loss"` // 合计汇兑损益
+}
+
+// FXGainLossReport 汇兑损益报表
+type FXGainLossReport struct {
+	StartTime time.Time         `json:"start_time"`
+	EndTime   time.Time         `json:"end_time"`
+	Items     []*FXGainLossItem `json:"items"`
+	Totals    map[string]int64  `json:"totals"` // 按目标币种汇总的汇兑损益
 }
 
 // Balance Aggregation Response Structures
@@ -429,6 +482,15 @@ func (s *accountService) ListTransactions(ctx context.Context, query *repository
 	return s.accountRepo.ListTransactions(ctx, query)
 }
 
+// ListSplitRecipients 查询时间范围内有分账入账或冲正的收款方商户（结算服务据此为子商户单独结算）
+func (s *accountService) ListSplitRecipients(ctx context.Context, startTime, endTime *time.Time) ([]uuid.UUID, error) {
+	return s.accountRepo.ListMerchantsByTransactionTypes(ctx, []string{
+		model.TransactionTypeSplitIn,
+		model.TransactionTypeSplitReversal,
+		model.TransactionTypeChargeback,
+	}, startTime, endTime)
+}
+
 // ReverseTransaction 冲正交易
 func (s *accountService) ReverseTransaction(ctx context.Context, transactionNo string, reason string) error {
 	// 获取原交易
@@ -494,10 +556,14 @@ func (s *accountService) CreateSettlement(ctx context.Context, input *CreateSett
 		}
 	}
 
-	// 计算手续费（简单示例：2% 费率）
+	// 计算手续费（简单示例：2% 费率，四舍五入到最小货币单位）
 	// 实际应该从merchant-service的fee_configs表查询商户费率
-	feeRate := 0.02
-	feeAmount := int64(float64(totalAmount) * feeRate)
+	feeRate := money.MustParseDecimal("0.02")
+	fee, err := money.New(totalAmount, input.Currency).Mul(feeRate, money.RoundHalfUp)
+	if err != nil {
+		return nil, fmt.Errorf("计算手续费失败: %w", err)
+	}
+	feeAmount := fee.Amount
 
 	// 计算净额
 	netAmount := totalAmount - feeAmount
@@ -1004,14 +1070,30 @@ func (s *accountService) CreateInvoice(ctx context.Context, input *CreateInvoice
 	// 生成账单号
 	invoiceNo := s.generateInvoiceNo()
 
-	// 计算小计金额
-	var subtotalAmount int64
-	for _, item := range input.Items {
-		subtotalAmount += int64(item.Quantity) * item.UnitPrice
-	}
+	// 计算小计金额和税额
+	var subtotalAmount, taxAmount int64
+	var taxResult *tax.Result
+	if input.Tax != nil {
+		// 按计税引擎逐项计税，小计为不含税金额
+		result, err := s.calculateInvoiceTax(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		taxResult = result
+		subtotalAmount = result.TaxableAmount
+		taxAmount = result.TaxAmount
+	} else {
+		for _, item := range input.Items {
+			subtotalAmount += int64(item.Quantity) * item.UnitPrice
+		}
 
-	// 计算税额
-	taxAmount := int64(float64(subtotalAmount) * input.TaxRate / 100)
+		// 统一税率计税（税率为百分比，四舍五入到最小货币单位）
+		taxMoney, err := money.New(subtotalAmount, input.Currency).Mul(input.TaxRate.Mul(money.NewDecimal(1, 2)), money.RoundHalfUp)
+		if err != nil {
+			return nil, fmt.Errorf("计算税额失败: %w", err)
+		}
+		taxAmount = taxMoney.Amount
+	}
 
 	// 计算总金额
 	totalAmount := subtotalAmount + taxAmount
@@ -1033,13 +1115,18 @@ func (s *accountService) CreateInvoice(ctx context.Context, input *CreateInvoice
 		DueDate:           input.DueDate,
 		Notes:             input.Notes,
 	}
+	if taxResult != nil {
+		invoice.BuyerTaxID = input.Tax.BuyerTaxID
+		invoice.ReverseCharge = taxResult.ReverseCharge
+	}
 
 	if err := s.accountRepo.CreateInvoice(ctx, invoice); err != nil {
 		return nil, fmt.Errorf("创建账单失败: %w", err)
 	}
 
 	// 创建账单明细
-	for _, itemInput := range input.Items {
+	itemIDs := make([]uuid.UUID, 0, len(input.Items))
+	for i, itemInput := range input.Items {
 		amount := int64(itemInput.Quantity) * itemInput.UnitPrice
 		item := &model.InvoiceItem{
 			InvoiceID:   invoice.ID,
@@ -1051,15 +1138,76 @@ func (s *accountService) CreateInvoice(ctx context.Context, input *CreateInvoice
 			RelatedID:   itemInput.RelatedID,
 			RelatedNo:   itemInput.RelatedNo,
 		}
+		if taxResult != nil {
+			item.TaxCategory = taxResult.Lines[i].Category
+			item.TaxAmount = taxResult.Lines[i].TaxAmount
+		}
 		if err := s.accountRepo.CreateInvoiceItem(ctx, item); err != nil {
 			return nil, fmt.Errorf("创建账单明细失败: %w", err)
 		}
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	// 创建税费明细
+	if taxResult != nil {
+		lines := make([]*model.InvoiceTaxLine, 0, len(taxResult.Lines))
+		for _, line := range taxResult.Lines {
+			taxLine := &model.InvoiceTaxLine{
+				InvoiceID:     invoice.ID,
+				Category:      line.Category,
+				Country:       line.Country,
+				Region:        line.Region,
+				TaxName:       line.TaxName,
+				Rate:          line.Rate,
+				TaxableAmount: line.TaxableAmount,
+				TaxAmount:     line.TaxAmount,
+				ReverseCharge: line.ReverseCharge,
+				Currency:      invoice.Currency,
+			}
+			if idx, err := strconv.Atoi(line.Reference); err == nil && idx < len(itemIDs) {
+				itemID := itemIDs[idx]
+				taxLine.InvoiceItemID = &itemID
+			}
+			lines = append(lines, taxLine)
+		}
+		if err := s.accountRepo.CreateInvoiceTaxLines(ctx, lines); err != nil {
+			return nil, fmt.Errorf("创建账单税费明细失败: %w", err)
+		}
 	}
 
 	// 重新加载账单（包含明细）
 	return s.GetInvoice(ctx, invoiceNo)
 }
 
+// calculateInvoiceTax 按买方所在地的税率表逐项计税
+func (s *accountService) calculateInvoiceTax(ctx context.Context, input *CreateInvoiceInput) (*tax.Result, error) {
+	if s.taxEngine == nil {
+		return nil, fmt.Errorf("未配置计税引擎")
+	}
+
+	req := &tax.Request{
+		Currency:         input.Currency,
+		SellerCountry:    s.sellerCountry,
+		Destination:      tax.Location{Country: input.Tax.BuyerCountry, Region: input.Tax.BuyerRegion},
+		BuyerTaxID:       input.Tax.BuyerTaxID,
+		PricesIncludeTax: input.Tax.PricesIncludeTax,
+		Lines:            make([]tax.LineInput, 0, len(input.Items)),
+	}
+	for i, item := range input.Items {
+		req.Lines = append(req.Lines, tax.LineInput{
+			Reference: strconv.Itoa(i),
+			Category:  item.TaxCategory,
+			Amount:    int64(item.Quantity) * item.UnitPrice,
+		})
+	}
+
+	result, err := s.taxEngine.Calculate(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("计算税额失败: %w", err)
+	}
+	return result, nil
+}
+
 // GetInvoice 获取账单
 func (s *accountService) GetInvoice(ctx context.Context, invoiceNo string) (*model.Invoice, error) {
 	invoice, err := s.accountRepo.GetInvoiceByNo(ctx, invoiceNo)
@@ -1652,26 +1800,52 @@ func (s *accountService) CreateCurrencyConversion(ctx context.Context, input *Cr
 		}
 	}
 
-	// 5. 获取实时汇率（通过 channel-adapter 的汇率API或降级到备用汇率）
-	exchangeRate := s.getExchangeRate(input.SourceCurrency, input.TargetCurrency)
-	if exchangeRate <= 0 {
-		return nil, fmt.Errorf("无法获取汇率: %s -> %s", input.SourceCurrency, input.TargetCurrency)
+	// 5. 生成转换单号（作为报价锁定的业务单号）
+	conversionNo, err := s.generateConversionNo()
+	if err != nil {
+		return nil, fmt.Errorf("生成转换单号失败: %w", err)
 	}
 
-	// 6. 计算目标货币金额
-	targetAmount := int64(float64(input.SourceAmount) * exchangeRate)
+	// 6. 锁定换汇报价（未指定报价时按当前汇率自动报价），汇率过期时拒绝转换
+	quote, err := s.lockFXQuote(ctx, input, conversionNo)
+	if err != nil {
+		return nil, err
+	}
+	// 转换记录创建前任一步骤失败时释放报价，避免报价被未创建的转换占用
+	created := false
+	defer func() {
+		if !created {
+			s.releaseFXQuote(ctx, input.MerchantID, quote.QuoteID, conversionNo)
+		}
+	}()
 
-	// 7. 计算手续费（0.5%）
-	feePercentage := 0.005
-	feeAmount := int64(float64(input.SourceAmount) * feePercentage)
+	// 7. 确定换汇金额：含费模式下从出账总额中扣出手续费，保证 换汇金额 + 手续费 不超过 source_amount
+	feePercentage := money.MustParseDecimal("0.005")
+	sourceAmount := input.SourceAmount
+	if input.FeeInclusive {
+		sourceAmount, err = conversionAmountExcludingFee(input.SourceAmount, input.SourceCurrency, feePercentage)
+		if err != nil {
+			return nil, fmt.Errorf("计算换汇金额失败: %w", err)
+		}
+	}
 
-	// 8. 生成转换单号
-	conversionNo, err := s.generateConversionNo()
+	// 8. 计算目标货币金额（按锁定汇率换算，处理两种币种的小数位数差异）
+	rate := quote.Rate
+	source := money.New(sourceAmount, input.SourceCurrency)
+	target, err := source.Convert(rate, input.TargetCurrency, money.RoundHalfEven)
 	if err != nil {
-		return nil, fmt.Errorf("生成转换单号失败: %w", err)
+		return nil, fmt.Errorf("计算目标货币金额失败: %w", err)
+	}
+	targetAmount := target.Amount
+
+	// 9. 计算手续费（0.5%，以源货币计）
+	fee, err := source.Mul(feePercentage, money.RoundHalfUp)
+	if err != nil {
+		return nil, fmt.Errorf("计算手续费失败: %w", err)
 	}
+	feeAmount := fee.Amount
 
-	// 9. 创建货币转换记录
+	// 10. 创建货币转换记录
 	conversion := &model.CurrencyConversion{
 		ConversionNo:    conversionNo,
 		MerchantID:      input.MerchantID,
@@ -1679,9 +1853,16 @@ func (s *accountService) CreateCurrencyConversion(ctx context.Context, input *Cr
 		TargetAccountID: targetAccount.ID,
 		SourceCurrency:  input.SourceCurrency,
 		TargetCurrency:  input.TargetCurrency,
-		SourceAmount:    input.SourceAmount,
+		SourceAmount:    sourceAmount,
 		TargetAmount:    targetAmount,
-		ExchangeRate:    exchangeRate,
+		ExchangeRate:    rate,
+		FXQuoteID:       quote.QuoteID,
+		MidRate:         quote.MidRate,
+		MarkupBps:       quote.MarkupBps,
+		MarketRate:      quote.MidRate,
+		RateSnapshotID:  quote.SnapshotID,
+		RateSource:      quote.RateSource,
+		RateTime:        &quote.RateTime,
 		FeeAmount:       feeAmount,
 		FeePercentage:   feePercentage,
 		Status:          model.ConversionStatusPending,
@@ -1693,6 +1874,7 @@ func (s *accountService) CreateCurrencyConversion(ctx context.Context, input *Cr
 	if err := s.accountRepo.CreateCurrencyConversion(ctx, conversion); err != nil {
 		return nil, fmt.Errorf("创建货币转换记录失败: %w", err)
 	}
+	created = true
 
 	return conversion, nil
 }
@@ -1744,7 +1926,8 @@ func (s *accountService) ProcessCurrencyConversion(ctx context.Context, conversi
 			return fmt.Errorf("创建目标账户交易失败: %w", err)
 		}
 
-		// 3.3 更新转换记录状态
+		// 3.3 更新转换记录状态，记录成交时的市场中间价用于汇兑损益
+		conversion.MarketRate = s.marketRate(ctx, conversion)
 		conversion.Status = model.ConversionStatusCompleted
 		conversion.SourceTransactionNo = sourceTx.TransactionNo
 		conversion.TargetTransactionNo = targetTx.TransactionNo
@@ -1808,78 +1991,200 @@ func (s *accountService) CancelCurrencyConversion(ctx context.Context, conversio
 		return fmt.Errorf("更新货币转换记录失败: %w", err)
 	}
 
+	// 转换已取消，释放其锁定的报价
+	if conversion.FXQuoteID != "" {
+		s.releaseFXQuote(ctx, conversion.MerchantID, conversion.FXQuoteID, conversion.ConversionNo)
+	}
+
 	return nil
 }
 
-// getExchangeRate 获取汇率（优先使用 channel-adapter 的实时汇率API）
-func (s *accountService) getExchangeRate(fromCurrency, toCurrency string) float64 {
-	// 同一货币，汇率为1
-	if fromCurrency == toCurrency {
-		return 1.0
+// GetFXGainLossReport 汇兑损益报表
+//
+// 以成交时市场中间价为基准：锁定汇率低于中间价的部分（加点）为平台收益，
+// 报价锁定到成交期间的汇率波动计入市场损益。
+func (s *accountService) GetFXGainLossReport(ctx context.Context, query *FXGainLossQuery) (*FXGainLossReport, error) {
+	if !query.EndTime.After(query.StartTime) {
+		return nil, fmt.Errorf("结束时间必须晚于开始时间")
 	}
 
-	// 如果配置了 channel-adapter 客户端，优先使用实时汇率
-	if s.channelAdapterClient != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	conversions, err := s.accountRepo.ListCompletedFXConversions(ctx, query.MerchantID, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("查询货币转换记录失败: %w", err)
+	}
 
-		rate, err := s.channelAdapterClient.GetExchangeRate(ctx, fromCurrency, toCurrency)
-		if err == nil && rate > 0 {
-			logger.Info("获取实时汇率成功",
-				zap.String("from", fromCurrency),
-				zap.String("to", toCurrency),
-				zap.Float64("rate", rate),
-			)
-			return rate
+	report := &FXGainLossReport{
+		StartTime: query.StartTime,
+		EndTime:   query.EndTime,
+		Items:     []*FXGainLossItem{},
+		Totals:    map[string]int64{},
+	}
+	items := map[string]*FXGainLossItem{}
+	for _, conversion := range conversions {
+		source := money.New(conversion.SourceAmount, conversion.SourceCurrency)
+		atMid, err := source.Convert(conversion.MidRate, conversion.TargetCurrency, money.RoundHalfEven)
+		if err != nil {
+			return nil, fmt.Errorf("计算汇兑损益失败 %s: %w", conversion.ConversionNo, err)
+		}
+		atMarket, err := source.Convert(conversion.MarketRate, conversion.TargetCurrency, money.RoundHalfEven)
+		if err != nil {
+			return nil, fmt.Errorf("计算汇兑损益失败 %s: %w", conversion.ConversionNo, err)
 		}
 
-		// 如果获取实时汇率失败，记录警告并降级到备用汇率
-		logger.Warn("获取实时汇率失败，使用备用汇率",
-			zap.String("from", fromCurrency),
-			zap.String("to", toCurrency),
-			zap.Error(err),
-		)
+		key := conversion.SourceCurrency + "/" + conversion.TargetCurrency
+		item, ok := items[key]
+		if !ok {
+			item = &FXGainLossItem{
+				SourceCurrency: conversion.SourceCurrency,
+				TargetCurrency: conversion.TargetCurrency,
+			}
+			items[key] = item
+			report.Items = append(report.Items, item)
+		}
+
+		markupGain := atMid.Amount - conversion.TargetAmount
+		movement := atMarket.Amount - atMid.Amount
+		item.Count++
+		item.SourceAmount += conversion.SourceAmount
+		item.TargetAmount += conversion.TargetAmount
+		item.FeeAmount += conversion.FeeAmount
+		item.MarkupGain += markupGain
+		item.MarketMovement += movement
+		item.TotalGainLoss += markupGain + movement
+		report.Totals[conversion.TargetCurrency] += markupGain + movement
 	}
 
-	// 备用静态汇率（当channel-adapter不可用时使用）
-	fallbackRates := map[string]map[string]float64{
-		"USD": {
-			"EUR": 0.92,
-			"GBP": 0.79,
-			"CNY": 7.24,
-			"JPY": 149.50,
-			"KRW": 1320.00,
-			"HKD": 7.82,
-			"SGD": 1.35,
-		},
-		"EUR": {
-			"USD": 1.09,
-			"GBP": 0.86,
-			"CNY": 7.87,
-		},
-		"CNY": {
-			"USD": 0.14,
-			"EUR": 0.13,
-		},
-	}
-
-	if from, ok := fallbackRates[fromCurrency]; ok {
-		if rate, ok := from[toCurrency]; ok {
-			logger.Info("使用备用静态汇率",
-				zap.String("from", fromCurrency),
-				zap.String("to", toCurrency),
-				zap.Float64("rate", rate),
-			)
-			return rate
+	return report, nil
+}
+
+// conversionAmountExcludingFee 含费金额拆分：返回满足 换汇金额 + 手续费 <= total 的最大换汇金额
+func conversionAmountExcludingFee(total int64, currency string, feeRate money.Decimal) (int64, error) {
+	estimate, err := money.DecimalFromInt(total).Div(money.DecimalFromInt(1).Add(feeRate), 0, money.RoundDown)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := strconv.ParseInt(estimate.String(), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// 手续费按四舍五入取整，估算值可能差一分，向两侧修正
+	withFee := func(a int64) (int64, error) {
+		fee, err := money.New(a, currency).Mul(feeRate, money.RoundHalfUp)
+		if err != nil {
+			return 0, err
+		}
+		return a + fee.Amount, nil
+	}
+	for amount > 0 {
+		sum, err := withFee(amount)
+		if err != nil {
+			return 0, err
 		}
+		if sum <= total {
+			break
+		}
+		amount--
 	}
+	for {
+		sum, err := withFee(amount + 1)
+		if err != nil {
+			return 0, err
+		}
+		if sum > total {
+			break
+		}
+		amount++
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("出账金额不足以支付换汇手续费")
+	}
+	return amount, nil
+}
 
-	// 如果没有找到汇率，返回0表示不支持
-	logger.Warn("未找到汇率数据",
-		zap.String("from", fromCurrency),
-		zap.String("to", toCurrency),
+// lockFXQuote 锁定货币转换使用的换汇报价
+func (s *accountService) lockFXQuote(ctx context.Context, input *CreateCurrencyConversionInput, conversionNo string) (*client.FXQuote, error) {
+	if s.channelAdapterClient == nil {
+		return nil, fmt.Errorf("未配置汇率服务，无法进行货币转换")
+	}
+
+	quoteID := input.FXQuoteID
+	if quoteID == "" {
+		quote, err := s.channelAdapterClient.CreateFXQuote(ctx, &client.CreateFXQuoteRequest{
+			MerchantID:     input.MerchantID,
+			BaseCurrency:   input.SourceCurrency,
+			TargetCurrency: input.TargetCurrency,
+			BaseAmount:     input.SourceAmount,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取换汇报价失败: %w", err)
+		}
+		quoteID = quote.QuoteID
+	}
+
+	quote, err := s.channelAdapterClient.LockFXQuote(ctx, quoteID, &client.LockFXQuoteRequest{
+		MerchantID:     input.MerchantID,
+		Reference:      conversionNo,
+		BaseCurrency:   input.SourceCurrency,
+		TargetCurrency: input.TargetCurrency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("锁定换汇报价失败: %w", err)
+	}
+
+	logger.Info("货币转换已锁定报价",
+		zap.String("conversion_no", conversionNo),
+		zap.String("quote_id", quote.QuoteID),
+		zap.String("rate", quote.Rate.String()),
+		zap.String("mid_rate", quote.MidRate.String()),
+		zap.String("snapshot_id", quote.SnapshotID),
 	)
-	return 0
+	return quote, nil
+}
+
+// releaseFXQuote 释放货币转换锁定的换汇报价（失败只记录日志）
+func (s *accountService) releaseFXQuote(ctx context.Context, merchantID uuid.UUID, quoteID, conversionNo string) {
+	if s.channelAdapterClient == nil {
+		return
+	}
+
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.channelAdapterClient.ReleaseFXQuote(releaseCtx, quoteID, &client.ReleaseFXQuoteRequest{
+		MerchantID: merchantID,
+		Reference:  conversionNo,
+	}); err != nil {
+		logger.Error("释放换汇报价失败",
+			zap.Error(err),
+			zap.String("conversion_no", conversionNo),
+			zap.String("quote_id", quoteID))
+		return
+	}
+
+	logger.Info("货币转换已释放报价",
+		zap.String("conversion_no", conversionNo),
+		zap.String("quote_id", quoteID))
+}
+
+// marketRate 成交时的市场中间价，获取失败时沿用报价时的中间价
+func (s *accountService) marketRate(ctx context.Context, conversion *model.CurrencyConversion) money.Decimal {
+	if s.channelAdapterClient == nil {
+		return conversion.MidRate
+	}
+
+	rateCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rate, err := s.channelAdapterClient.GetExchangeRate(rateCtx, conversion.SourceCurrency, conversion.TargetCurrency)
+	if err != nil || rate.Sign() <= 0 {
+		logger.Warn("获取成交时市场汇率失败，使用报价中间价",
+			zap.String("conversion_no", conversion.ConversionNo),
+			zap.Error(err),
+		)
+		return conversion.MidRate
+	}
+	return rate
 }
 
 // generateConversionNo 生成货币转换单号
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/document"
+	exportpkg "github.com/payment-platform/pkg/export"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/accounting-service/internal/client"
+	"payment-platform/accounting-service/internal/model"
+	"payment-platform/accounting-service/internal/repository"
+)
+
+// InvoiceIssuer 开票方信息（平台主体）
+type InvoiceIssuer struct {
+	Name       string
+	Address    string
+	TaxID      string
+	Email      string
+	ThemeColor string
+}
+
+// InvoiceDocument 账单 PDF
+type InvoiceDocument struct {
+	FileName string
+	Content  []byte
+}
+
+// InvoiceDocumentService 账单文档服务
+type InvoiceDocumentService interface {
+	// GetInvoicePDF 获取账单 PDF（账单未变更时复用已生成的文件）
+	// merchantID 非空时校验账单归属
+	GetInvoicePDF(ctx context.Context, invoiceNo string, merchantID uuid.UUID, language string) (*InvoiceDocument, error)
+}
+
+type invoiceDocumentService struct {
+	accountRepo   repository.AccountRepository
+	exportService *exportpkg.ExportService
+	cashierClient *client.CashierClient
+	issuer        InvoiceIssuer
+}
+
+// NewInvoiceDocumentService 创建账单文档服务
+func NewInvoiceDocumentService(
+	accountRepo repository.AccountRepository,
+	exportService *exportpkg.ExportService,
+	cashierClient *client.CashierClient,
+	issuer InvoiceIssuer,
+) InvoiceDocumentService {
+	return &invoiceDocumentService{
+		accountRepo:   accountRepo,
+		exportService: exportService,
+		cashierClient: cashierClient,
+		issuer:        issuer,
+	}
+}
+
+// GetInvoicePDF 获取账单 PDF
+func (s *invoiceDocumentService) GetInvoicePDF(ctx context.Context, invoiceNo string, merchantID uuid.UUID, language string) (*InvoiceDocument, error) {
+	invoice, err := s.accountRepo.GetInvoiceByNo(ctx, invoiceNo)
+	if err != nil {
+		return nil, fmt.Errorf("获取账单失败: %w", err)
+	}
+	if invoice == nil || (merchantID != uuid.Nil && invoice.MerchantID != merchantID) {
+		return nil, fmt.Errorf("账单不存在")
+	}
+	if len(invoice.Items) == 0 {
+		items, err := s.accountRepo.GetInvoiceItems(ctx, invoice.ID)
+		if err != nil {
+			return nil, fmt.Errorf("获取账单明细失败: %w", err)
+		}
+		for _, item := range items {
+			invoice.Items = append(invoice.Items, *item)
+		}
+	}
+
+	// 付款方展示商户品牌名称，未指定语言时使用商户收银台默认语言
+	var branding *client.MerchantBranding
+	if s.cashierClient != nil {
+		if branding, err = s.cashierClient.GetBranding(ctx, invoice.MerchantID); err != nil {
+			logger.Warn("获取商户品牌信息失败，使用默认展示",
+				zap.Error(err),
+				zap.String("merchant_id", invoice.MerchantID.String()))
+			branding = nil
+		}
+	}
+	if language == "" && branding != nil {
+		language = branding.DefaultLanguage
+	}
+	lang := document.NormalizeLanguage(language)
+
+	// 文件名包含更新时间，账单状态变化（如已支付）后会重新生成
+	fileName := fmt.Sprintf("invoice_%s_%s_%d.pdf", invoice.InvoiceNo, lang, invoice.UpdatedAt.Unix())
+	if task, err := s.exportService.FindStoredFile(ctx, invoice.MerchantID, fileName); err == nil && task != nil {
+		if content, err := s.exportService.ReadFile(task); err == nil {
+			return &InvoiceDocument{FileName: fileName, Content: content}, nil
+		}
+	}
+
+	content, err := document.RenderInvoice(buildInvoiceDocument(invoice, branding, s.issuer, lang))
+	if err != nil {
+		return nil, fmt.Errorf("生成账单PDF失败: %w", err)
+	}
+
+	if _, err := s.exportService.StoreFile(ctx, invoice.MerchantID, "invoice", "pdf", fileName, content); err != nil {
+		// 保存失败不影响本次下载
+		logger.Warn("保存账单PDF失败",
+			zap.Error(err),
+			zap.String("invoice_no", invoice.InvoiceNo))
+	}
+
+	return &InvoiceDocument{FileName: fileName, Content: content}, nil
+}
+
+func buildInvoiceDocument(invoice *model.Invoice, branding *client.MerchantBranding, issuer InvoiceIssuer, lang string) *document.Invoice {
+	doc := &document.Invoice{
+		Language: lang,
+		Issuer: document.Branding{
+			Name:       issuer.Name,
+			ThemeColor: issuer.ThemeColor,
+			Address:    issuer.Address,
+			TaxID:      issuer.TaxID,
+			Email:      issuer.Email,
+		},
+		BillTo:      document.Branding{Name: invoice.MerchantID.String()},
+		InvoiceNo:   invoice.InvoiceNo,
+		Status:      invoice.Status,
+		Currency:    invoice.Currency,
+		PeriodStart: invoice.PeriodStart,
+		PeriodEnd:   invoice.PeriodEnd,
+		IssuedAt:    invoice.CreatedAt,
+		DueDate:     invoice.DueDate,
+		Subtotal:    invoice.SubtotalAmount,
+		Total:       invoice.TotalAmount,
+		Paid:        invoice.PaidAmount,
+		Outstanding: invoice.OutstandingAmount,
+		Notes:       invoice.Notes,
+	}
+	if invoice.ReverseCharge {
+		// 反向征收的账单需注明由买方自行申报缴纳
+		doc.Notes = strings.TrimSpace(fmt.Sprintf("Reverse charge - VAT to be accounted for by the recipient (%s)\n%s", invoice.BuyerTaxID, doc.Notes))
+	}
+	if branding != nil && branding.BrandName != "" {
+		doc.BillTo.Name = branding.BrandName
+		doc.BillTo.Address = invoice.MerchantID.String()
+	}
+
+	for _, item := range invoice.Items {
+		description := item.Description
+		if item.RelatedNo != "" {
+			description += " (" + item.RelatedNo + ")"
+		}
+		doc.Items = append(doc.Items, document.LineItem{
+			Description: description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+
+	// 计税引擎计税的账单按税种和税率汇总税费明细
+	if len(invoice.TaxLines) > 0 {
+		doc.TaxLines = buildInvoiceTaxLines(invoice.TaxLines)
+		return doc
+	}
+
+	// 统一税率计税的账单只保存税额，税率按小计反推
+	if invoice.TaxAmount != 0 {
+		taxLine := document.TaxLine{Amount: invoice.TaxAmount}
+		if invoice.SubtotalAmount > 0 {
+			taxLine.RateBps = int(math.Round(float64(invoice.TaxAmount) * 10000 / float64(invoice.SubtotalAmount)))
+		}
+		doc.TaxLines = append(doc.TaxLines, taxLine)
+	}
+	return doc
+}
+
+// buildInvoiceTaxLines 按 税种+税率 汇总税费明细（反向征收的明细税额为 0，不展示）
+func buildInvoiceTaxLines(lines []model.InvoiceTaxLine) []document.TaxLine {
+	var result []document.TaxLine
+	index := make(map[string]int)
+	for _, line := range lines {
+		if line.TaxAmount == 0 {
+			continue
+		}
+		key := line.TaxName + "|" + line.Rate.String()
+		if i, ok := index[key]; ok {
+			result[i].Amount += line.TaxAmount
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, document.TaxLine{
+			Name:    line.TaxName,
+			RateBps: int(math.Round(line.Rate.Float64() * 100)),
+			Amount:  line.TaxAmount,
+		})
+	}
+	return result
+}
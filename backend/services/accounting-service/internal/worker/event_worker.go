@@ -10,6 +10,7 @@ import (
 	"github.com/payment-platform/pkg/logger"
 	"go.uber.org/zap"
 	"payment-platform/accounting-service/internal/model"
+	"payment-platform/accounting-service/internal/repository"
 	"payment-platform/accounting-service/internal/service"
 )
 
@@ -53,6 +54,8 @@ func (w *EventWorker) StartPaymentEventWorker(ctx context.Context, consumer *kaf
 			return w.handlePaymentSuccess(ctx, message)
 		case events.RefundSuccess:
 			return w.handleRefundSuccess(ctx, message)
+		case events.PaymentChargeback:
+			return w.handlePaymentChargeback(ctx, message)
 		default:
 			logger.Info("Accounting: 未处理的支付事件类型", zap.String("event_type", baseEvent.EventType))
 			return nil
@@ -86,24 +89,21 @@ func (w *EventWorker) handlePaymentSuccess(ctx context.Context, message []byte)
 		zap.Int64("amount", event.Payload.Amount),
 		zap.String("currency", event.Payload.Currency))
 
+	// 分账支付: 按收款方分别入账（平台分成记入平台商户，子商户分账记入子商户）
+	if len(event.Payload.Splits) > 0 {
+		return w.postSplits(ctx, event.Payload.Splits, event.Payload.Currency, event.Payload.PaymentNo,
+			model.TransactionTypeSplitIn, 1, "分账入账: ", map[string]interface{}{
+				"payment_no":  event.Payload.PaymentNo,
+				"order_no":    event.Payload.OrderNo,
+				"channel":     event.Payload.Channel,
+				"merchant_id": event.Payload.MerchantID,
+			})
+	}
+
 	// 1. 获取或创建商户账户 (自动创建待结算账户)
-	account, err := w.accountService.GetMerchantAccount(ctx, merchantID, "settlement", event.Payload.Currency)
+	account, err := w.getOrCreateSettlementAccount(ctx, merchantID, event.Payload.Currency)
 	if err != nil {
-		// 如果账户不存在，创建新账户
-		createAccountInput := &service.CreateAccountInput{
-			MerchantID:  merchantID,
-			AccountType: "settlement", // 待结算账户
-			Currency:    event.Payload.Currency,
-		}
-		account, err = w.accountService.CreateAccount(ctx, createAccountInput)
-		if err != nil {
-			logger.Error("Accounting: 创建商户账户失败", zap.Error(err))
-			return err
-		}
-		logger.Info("Accounting: 自动创建商户账户",
-			zap.String("merchant_id", merchantID.String()),
-			zap.String("account_type", "settlement"),
-			zap.String("currency", event.Payload.Currency))
+		return err
 	}
 
 	// 2. 创建财务交易 (复式记账)
@@ -159,24 +159,21 @@ func (w *EventWorker) handleRefundSuccess(ctx context.Context, message []byte) e
 		zap.Int64("amount", event.Payload.Amount),
 		zap.String("currency", event.Payload.Currency))
 
+	// 分账支付的退款: 按原分账比例从各收款方冲正
+	if len(event.Payload.Splits) > 0 {
+		return w.postSplits(ctx, event.Payload.Splits, event.Payload.Currency, event.Payload.RefundNo,
+			model.TransactionTypeSplitReversal, -1, "分账退款冲正: ", map[string]interface{}{
+				"refund_no":   event.Payload.RefundNo,
+				"payment_no":  event.Payload.PaymentNo,
+				"reason":      event.Payload.Reason,
+				"merchant_id": event.Payload.MerchantID,
+			})
+	}
+
 	// 1. 获取或创建商户账户 (自动创建待结算账户)
-	account, err := w.accountService.GetMerchantAccount(ctx, merchantID, "settlement", event.Payload.Currency)
+	account, err := w.getOrCreateSettlementAccount(ctx, merchantID, event.Payload.Currency)
 	if err != nil {
-		// 如果账户不存在，创建新账户
-		createAccountInput := &service.CreateAccountInput{
-			MerchantID:  merchantID,
-			AccountType: "settlement",
-			Currency:    event.Payload.Currency,
-		}
-		account, err = w.accountService.CreateAccount(ctx, createAccountInput)
-		if err != nil {
-			logger.Error("Accounting: 创建商户账户失败", zap.Error(err))
-			return err
-		}
-		logger.Info("Accounting: 自动创建商户账户 (退款)",
-			zap.String("merchant_id", merchantID.String()),
-			zap.String("account_type", "settlement"),
-			zap.String("currency", event.Payload.Currency))
+		return err
 	}
 
 	// 2. 创建退款财务交易 (反向记账)
@@ -213,6 +210,136 @@ func (w *EventWorker) handleRefundSuccess(ctx context.Context, message []byte) e
 	return w.publishAccountingEvent(ctx, events.TransactionCreated, transaction)
 }
 
+// handlePaymentChargeback 处理拒付败诉事件 → 按分账比例从各收款方出账
+func (w *EventWorker) handlePaymentChargeback(ctx context.Context, message []byte) error {
+	var event events.PaymentEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		return err
+	}
+
+	disputeNo, _ := event.Payload.Extra["dispute_no"].(string)
+	logger.Info("Accounting: 处理拒付事件，分账冲正",
+		zap.String("payment_no", event.Payload.PaymentNo),
+		zap.String("dispute_no", disputeNo),
+		zap.Int64("amount", event.Payload.Amount),
+		zap.Int("recipients", len(event.Payload.Splits)))
+
+	if disputeNo == "" || len(event.Payload.Splits) == 0 {
+		logger.Warn("Accounting: 拒付事件缺少拒付单号或分账明细，跳过",
+			zap.String("payment_no", event.Payload.PaymentNo))
+		return nil
+	}
+
+	return w.postSplits(ctx, event.Payload.Splits, event.Payload.Currency, disputeNo,
+		model.TransactionTypeChargeback, -1, "拒付出账: ", map[string]interface{}{
+			"dispute_no":  disputeNo,
+			"payment_no":  event.Payload.PaymentNo,
+			"merchant_id": event.Payload.MerchantID,
+		})
+}
+
+// postSplits 按分账明细为各收款方分别记账（sign 为 1 入账，-1 出账）
+//
+// 同一关联单号在收款方账户下已有同类交易时跳过，消息重试不会重复记账。
+func (w *EventWorker) postSplits(
+	ctx context.Context,
+	splits []events.SplitPosting,
+	currency, relatedNo, transactionType string,
+	sign int64,
+	description string,
+	extra map[string]interface{},
+) error {
+	for _, split := range splits {
+		merchantID, err := uuid.Parse(split.MerchantID)
+		if err != nil {
+			logger.Error("Accounting: 解析分账收款方merchant_id失败", zap.Error(err))
+			return err
+		}
+
+		account, err := w.getOrCreateSettlementAccount(ctx, merchantID, currency)
+		if err != nil {
+			return err
+		}
+
+		existing, total, err := w.accountService.ListTransactions(ctx, &repository.TransactionQuery{
+			AccountID:       &account.ID,
+			TransactionType: transactionType,
+			RelatedNo:       relatedNo,
+			Page:            1,
+			PageSize:        1,
+		})
+		if err != nil {
+			return err
+		}
+		if total > 0 {
+			logger.Info("Accounting: 分账交易已记账，跳过",
+				zap.String("transaction_no", existing[0].TransactionNo),
+				zap.String("related_no", relatedNo),
+				zap.String("merchant_id", split.MerchantID))
+			continue
+		}
+
+		splitExtra := make(map[string]interface{}, len(extra)+2)
+		for k, v := range extra {
+			splitExtra[k] = v
+		}
+		splitExtra["recipient_id"] = split.MerchantID
+		splitExtra["split_role"] = split.Role
+
+		transaction, err := w.accountService.CreateTransaction(ctx, &service.CreateTransactionInput{
+			AccountID:       account.ID,
+			TransactionType: transactionType,
+			Amount:          sign * split.Amount,
+			RelatedNo:       relatedNo,
+			Description:     description + relatedNo,
+			Extra:           splitExtra,
+		})
+		if err != nil {
+			logger.Error("Accounting: 创建分账交易失败",
+				zap.Error(err),
+				zap.String("related_no", relatedNo),
+				zap.String("merchant_id", split.MerchantID))
+			return err
+		}
+
+		logger.Info("Accounting: 分账交易创建成功",
+			zap.String("transaction_no", transaction.TransactionNo),
+			zap.String("related_no", relatedNo),
+			zap.String("merchant_id", split.MerchantID),
+			zap.String("role", split.Role),
+			zap.Int64("amount", transaction.Amount))
+
+		if err := w.publishAccountingEvent(ctx, events.TransactionCreated, transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOrCreateSettlementAccount 获取商户待结算账户，不存在时自动创建
+func (w *EventWorker) getOrCreateSettlementAccount(ctx context.Context, merchantID uuid.UUID, currency string) (*model.Account, error) {
+	account, err := w.accountService.GetMerchantAccount(ctx, merchantID, "settlement", currency)
+	if err == nil {
+		return account, nil
+	}
+
+	// 如果账户不存在，创建新账户
+	account, err = w.accountService.CreateAccount(ctx, &service.CreateAccountInput{
+		MerchantID:  merchantID,
+		AccountType: "settlement", // 待结算账户
+		Currency:    currency,
+	})
+	if err != nil {
+		logger.Error("Accounting: 创建商户账户失败", zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Accounting: 自动创建商户账户",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("account_type", "settlement"),
+		zap.String("currency", currency))
+	return account, nil
+}
+
 // ========== 事件发布器 (Producer) ==========
 
 // publishAccountingEvent 发布财务事件到Kafka
@@ -207,7 +207,7 @@ func main() {
 	merchantBFFHandler := handler.NewMerchantBFFHandler(getConfig("MERCHANT_SERVICE_URL", "http://localhost:40002"), auditLogService)
 	analyticsBFFHandler := handler.NewAnalyticsBFFHandler(getConfig("ANALYTICS_SERVICE_URL", "http://localhost:40009"))
 	limitBFFHandler := handler.NewLimitBFFHandler(getConfig("LIMIT_SERVICE_URL", "http://localhost:40022"))
-	channelBFFHandler := handler.NewChannelBFFHandler(getConfig("CHANNEL_SERVICE_URL", "http://localhost:40005"))
+	channelBFFHandler := handler.NewChannelBFFHandler(getConfig("CHANNEL_SERVICE_URL", "http://localhost:40005"), auditLogService)
 	cashierBFFHandler := handler.NewCashierBFFHandler(getConfig("CASHIER_SERVICE_URL", "http://localhost:40016"))
 	orderBFFHandler := handler.NewOrderBFFHandler(getConfig("ORDER_SERVICE_URL", "http://localhost:40004"), auditLogService)
 
@@ -221,6 +221,12 @@ func main() {
 	reconciliationBFFHandler := handler.NewReconciliationBFFHandler(getConfig("RECONCILIATION_SERVICE_URL", "http://localhost:40020"))
 	settlementBFFHandler := handler.NewSettlementBFFHandler(getConfig("SETTLEMENT_SERVICE_URL", "http://localhost:40013"), auditLogService)
 	withdrawalBFFHandler := handler.NewWithdrawalBFFHandler(getConfig("WITHDRAWAL_SERVICE_URL", "http://localhost:40014"))
+	sagaBFFHandler := handler.NewSagaBFFHandler(
+		getConfig("PAYMENT_GATEWAY_URL", "http://localhost:40003"),
+		getConfig("SETTLEMENT_SERVICE_URL", "http://localhost:40013"),
+		getConfig("WITHDRAWAL_SERVICE_URL", "http://localhost:40014"),
+		auditLogService,
+	)
 
 	logger.Info("BFF Handlers 已初始化",
 		zap.Int("total_bff_handlers", 18),
@@ -284,6 +290,7 @@ func main() {
 			settlementBFFHandler.RegisterRoutes(sensitiveGroup, authMiddleware)
 			withdrawalBFFHandler.RegisterRoutes(sensitiveGroup, authMiddleware)
 			disputeBFFHandler.RegisterRoutes(sensitiveGroup, authMiddleware)
+			sagaBFFHandler.RegisterRoutes(sensitiveGroup, authMiddleware)
 		}
 	}
 
@@ -14,13 +14,15 @@ import (
 	"time"
 
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/middleware"
 	"go.uber.org/zap"
 )
 
 // ServiceClient 微服务客户端
 type ServiceClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL      string
+	httpClient   *http.Client
+	serviceToken string // 服务间调用令牌（INTERNAL_SERVICE_TOKEN），访问下游 /api/v1/service/* 路由
 }
 
 // NewServiceClient 创建微服务客户端(支持mTLS)
@@ -55,8 +57,9 @@ func NewServiceClient(baseURL string) *ServiceClient {
 	}
 
 	return &ServiceClient{
-		baseURL:    baseURL,
-		httpClient: httpClient,
+		baseURL:      baseURL,
+		httpClient:   httpClient,
+		serviceToken: os.Getenv("INTERNAL_SERVICE_TOKEN"),
 	}
 }
 
@@ -113,7 +116,7 @@ func (c *ServiceClient) Get(ctx context.Context, path string, queryParams map[st
 	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -152,7 +155,7 @@ func (c *ServiceClient) Post(ctx context.Context, path string, body interface{})
 	req.Header.Set("Content-Type", "application/json")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -191,7 +194,7 @@ func (c *ServiceClient) Put(ctx context.Context, path string, body interface{})
 	req.Header.Set("Content-Type", "application/json")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -223,7 +226,7 @@ func (c *ServiceClient) Delete(ctx context.Context, path string) (map[string]int
 	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, 0, fmt.Errorf("HTTP请求失败: %w", err)
 	}
@@ -244,6 +247,14 @@ func (c *ServiceClient) Delete(ctx context.Context, path string) (map[string]int
 	return result, resp.StatusCode, nil
 }
 
+// do 附加服务间认证请求头后发送请求
+func (c *ServiceClient) do(req *http.Request) (*http.Response, error) {
+	for k, v := range middleware.ServiceAuthHeaders("admin-bff-service", c.serviceToken) {
+		req.Header.Set(k, v)
+	}
+	return c.httpClient.Do(req)
+}
+
 // buildURL 构建完整URL
 func (c *ServiceClient) buildURL(path string, queryParams map[string]string) string {
 	fullURL := c.baseURL + path
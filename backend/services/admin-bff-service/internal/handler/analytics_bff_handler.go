@@ -50,6 +50,13 @@ func (h *AnalyticsBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware
 			merchants.GET("/trends", h.GetMerchantTrends)
 			merchants.GET("/top", h.GetTopMerchants)
 		}
+
+		// 指标异常告警
+		anomalies := admin.Group("/anomalies")
+		{
+			anomalies.GET("", h.ListAnomalyAlerts)
+			anomalies.POST("/:id/acknowledge", h.AcknowledgeAnomalyAlert)
+		}
 	}
 }
 
@@ -267,3 +274,41 @@ func (h *AnalyticsBFFHandler) GetTopMerchants(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ========== 指标异常告警 ==========
+
+// ListAnomalyAlerts 获取指标异常告警列表
+func (h *AnalyticsBFFHandler) ListAnomalyAlerts(c *gin.Context) {
+	queryParams := make(map[string]string)
+	for _, key := range []string{"status", "scope_type", "scope_key", "metric", "severity", "start_time", "end_time", "page", "page_size"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := h.analyticsClient.Get(c.Request.Context(), "/api/v1/analytics/anomalies", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Analytics Service失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// AcknowledgeAnomalyAlert 确认指标异常告警
+func (h *AnalyticsBFFHandler) AcknowledgeAnomalyAlert(c *gin.Context) {
+	alertID := c.Param("id")
+	adminID := c.GetString("user_id")
+
+	body := map[string]interface{}{
+		"acknowledged_by": adminID,
+	}
+
+	result, statusCode, err := h.analyticsClient.Post(c.Request.Context(), "/api/v1/analytics/anomalies/"+alertID+"/acknowledge", body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Analytics Service失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
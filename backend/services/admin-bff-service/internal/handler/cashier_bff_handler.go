@@ -55,6 +55,13 @@ func (h *CashierBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gi
 			fields.PUT("/:id", h.UpdateField)
 			fields.DELETE("/:id", h.DeleteField)
 		}
+
+		// 转化漏斗分析
+		analytics := admin.Group("/analytics")
+		{
+			analytics.GET("/funnel", h.GetFunnel)
+			analytics.GET("/templates/compare", h.CompareTemplates)
+		}
 	}
 }
 
@@ -338,3 +345,41 @@ func (h *CashierBFFHandler) DeleteField(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ========== 转化漏斗分析 ==========
+
+// GetFunnel 获取收银台转化漏斗（merchant_id 为空表示全平台）
+func (h *CashierBFFHandler) GetFunnel(c *gin.Context) {
+	queryParams := make(map[string]string)
+	for _, key := range []string{"merchant_id", "dimension", "template_id", "start_time", "end_time"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := h.cashierClient.Get(c.Request.Context(), "/api/v1/service/cashier/funnel", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Cashier Service失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// CompareTemplates 收银台模板 A/B 对比
+func (h *CashierBFFHandler) CompareTemplates(c *gin.Context) {
+	queryParams := make(map[string]string)
+	for _, key := range []string{"merchant_id", "template_a", "template_b", "start_time", "end_time"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := h.cashierClient.Get(c.Request.Context(), "/api/v1/service/cashier/templates/compare", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Cashier Service失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
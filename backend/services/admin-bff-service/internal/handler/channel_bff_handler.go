@@ -5,17 +5,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"payment-platform/admin-service/internal/client"
+	localMiddleware "payment-platform/admin-service/internal/middleware"
+	"payment-platform/admin-service/internal/service"
+	"payment-platform/admin-service/internal/utils"
 )
 
 // ChannelBFFHandler Channel Adapter BFF处理器
 type ChannelBFFHandler struct {
 	channelClient *client.ServiceClient
+	auditHelper   *utils.AuditHelper
 }
 
 // NewChannelBFFHandler 创建Channel BFF处理器
-func NewChannelBFFHandler(channelServiceURL string) *ChannelBFFHandler {
+func NewChannelBFFHandler(channelServiceURL string, auditLogService service.AuditLogService) *ChannelBFFHandler {
 	return &ChannelBFFHandler{
 		channelClient: client.NewServiceClient(channelServiceURL),
+		auditHelper:   utils.NewAuditHelper(auditLogService),
 	}
 }
 
@@ -43,6 +48,26 @@ func (h *ChannelBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gi
 		admin.GET("/exchange-rates", h.GetExchangeRates)
 		admin.POST("/exchange-rates/update", h.UpdateExchangeRates)
 	}
+
+	// 渠道健康与熔断（手动覆盖会直接影响路由摘流，仅 routing.manage 权限可操作）
+	health := r.Group("/admin/channel-health")
+	health.Use(authMiddleware)
+	{
+		health.GET("",
+			localMiddleware.RequirePermission("payments.view"),
+			h.GetChannelHealth,
+		)
+		health.PUT("/:channel/override",
+			localMiddleware.RequirePermission("routing.manage"),
+			localMiddleware.RequireReason,
+			h.SetChannelHealthOverride,
+		)
+		health.DELETE("/:channel/override",
+			localMiddleware.RequirePermission("routing.manage"),
+			localMiddleware.RequireReason,
+			h.ClearChannelHealthOverride,
+		)
+	}
 }
 
 // ========== 支付通道管理 ==========
@@ -230,3 +255,54 @@ func (h *ChannelBFFHandler) UpdateExchangeRates(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ========== 渠道健康与熔断 ==========
+
+// GetChannelHealth 查询渠道健康状态
+func (h *ChannelBFFHandler) GetChannelHealth(c *gin.Context) {
+	result, statusCode, err := h.channelClient.Get(c.Request.Context(), "/api/v1/admin/channel-health", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Channel Service失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// SetChannelHealthOverride 手动设置渠道健康状态（如强制下线或恢复渠道）
+func (h *ChannelBFFHandler) SetChannelHealthOverride(c *gin.Context) {
+	channel := c.Param("channel")
+
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	req["reason"] = c.GetString("operation_reason")
+	req["operator"] = c.GetString("username")
+
+	result, statusCode, err := h.channelClient.Put(c.Request.Context(), "/api/v1/admin/channel-health/"+channel+"/override", req)
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, "OVERRIDE_CHANNEL_HEALTH", channel, false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Channel Service失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, "OVERRIDE_CHANNEL_HEALTH", channel, statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
+
+// ClearChannelHealthOverride 解除渠道健康状态覆盖，恢复自动判定
+func (h *ChannelBFFHandler) ClearChannelHealthOverride(c *gin.Context) {
+	channel := c.Param("channel")
+
+	result, statusCode, err := h.channelClient.Delete(c.Request.Context(), "/api/v1/admin/channel-health/"+channel+"/override")
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, "CLEAR_CHANNEL_HEALTH_OVERRIDE", channel, false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Channel Service失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, "CLEAR_CHANNEL_HEALTH_OVERRIDE", channel, statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
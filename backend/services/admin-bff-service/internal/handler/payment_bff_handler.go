@@ -105,6 +105,38 @@ func (h *PaymentBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gi
 			localMiddleware.RequirePermission("payments.view"),
 			h.GetAlerts,
 		)
+
+		// 路由规则（规则变更影响全部交易，仅 routing.manage 权限可修改）
+		routing := admin.Group("/routing")
+		{
+			routing.GET("/rules",
+				localMiddleware.RequirePermission("payments.view"),
+				h.ListRoutingRules,
+			)
+			routing.POST("/rules",
+				localMiddleware.RequirePermission("routing.manage"),
+				localMiddleware.RequireReason,
+				h.CreateRoutingRule,
+			)
+			routing.PUT("/rules/:id",
+				localMiddleware.RequirePermission("routing.manage"),
+				localMiddleware.RequireReason,
+				h.UpdateRoutingRule,
+			)
+			routing.DELETE("/rules/:id",
+				localMiddleware.RequirePermission("routing.manage"),
+				localMiddleware.RequireReason,
+				h.DeleteRoutingRule,
+			)
+			routing.POST("/explain",
+				localMiddleware.RequirePermission("payments.view"),
+				h.ExplainRouting,
+			)
+			routing.POST("/simulate",
+				localMiddleware.RequirePermission("payments.view"),
+				h.SimulateRouting,
+			)
+		}
 	}
 }
 
@@ -481,3 +513,104 @@ func (h *PaymentBFFHandler) GetAlerts(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ========== 路由规则 ==========
+
+func (h *PaymentBFFHandler) ListRoutingRules(c *gin.Context) {
+	queryParams := make(map[string]string)
+	if merchantID := c.Query("merchant_id"); merchantID != "" {
+		queryParams["merchant_id"] = merchantID
+	}
+
+	result, statusCode, err := h.paymentClient.Get(c.Request.Context(), "/api/v1/service/routing/rules", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+func (h *PaymentBFFHandler) CreateRoutingRule(c *gin.Context) {
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	result, statusCode, err := h.paymentClient.Post(c.Request.Context(), "/api/v1/service/routing/rules", req)
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, "CREATE_ROUTING_RULE", "", false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, "CREATE_ROUTING_RULE", "", statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
+
+func (h *PaymentBFFHandler) UpdateRoutingRule(c *gin.Context) {
+	id := c.Param("id")
+
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	result, statusCode, err := h.paymentClient.Put(c.Request.Context(), "/api/v1/service/routing/rules/"+id, req)
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, "UPDATE_ROUTING_RULE", id, false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, "UPDATE_ROUTING_RULE", id, statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
+
+func (h *PaymentBFFHandler) DeleteRoutingRule(c *gin.Context) {
+	id := c.Param("id")
+
+	result, statusCode, err := h.paymentClient.Delete(c.Request.Context(), "/api/v1/service/routing/rules/"+id)
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, "DELETE_ROUTING_RULE", id, false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, "DELETE_ROUTING_RULE", id, statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
+
+func (h *PaymentBFFHandler) ExplainRouting(c *gin.Context) {
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	result, statusCode, err := h.paymentClient.Post(c.Request.Context(), "/api/v1/service/routing/explain", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+func (h *PaymentBFFHandler) SimulateRouting(c *gin.Context) {
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	result, statusCode, err := h.paymentClient.Post(c.Request.Context(), "/api/v1/service/routing/simulate", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Payment Gateway失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
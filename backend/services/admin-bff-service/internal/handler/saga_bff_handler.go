@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-platform/admin-service/internal/client"
+	localMiddleware "payment-platform/admin-service/internal/middleware"
+	"payment-platform/admin-service/internal/service"
+	"payment-platform/admin-service/internal/utils"
+)
+
+// SagaBFFHandler Saga 分布式事务 BFF处理器（聚合 payment-gateway / settlement / withdrawal 的 Saga 管理接口）
+type SagaBFFHandler struct {
+	clients     map[string]*client.ServiceClient
+	auditHelper *utils.AuditHelper
+}
+
+// NewSagaBFFHandler 创建Saga BFF处理器
+func NewSagaBFFHandler(paymentGatewayURL, settlementServiceURL, withdrawalServiceURL string, auditLogService service.AuditLogService) *SagaBFFHandler {
+	return &SagaBFFHandler{
+		clients: map[string]*client.ServiceClient{
+			"payment":    client.NewServiceClient(paymentGatewayURL),
+			"settlement": client.NewServiceClient(settlementServiceURL),
+			"withdrawal": client.NewServiceClient(withdrawalServiceURL),
+		},
+		auditHelper: utils.NewAuditHelper(auditLogService),
+	}
+}
+
+// RegisterRoutes 注册路由
+//
+// :service 取值 payment（支付/退款/回调 Saga）、settlement、withdrawal。
+// 续跑、重试补偿、终止、重新入队会触发真实资金操作，需 sagas.manage 权限并填写操作原因。
+func (h *SagaBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	sagas := r.Group("/admin/sagas/:service")
+	sagas.Use(authMiddleware)
+	{
+		sagas.GET("", localMiddleware.RequirePermission("sagas.view"), h.ListSagas)
+		sagas.GET("/business-types", localMiddleware.RequirePermission("sagas.view"), h.ListBusinessTypes)
+		sagas.GET("/dlq", localMiddleware.RequirePermission("sagas.view"), h.ListDLQ)
+		sagas.GET("/:id", localMiddleware.RequirePermission("sagas.view"), h.GetSaga)
+		sagas.GET("/:id/timeline", localMiddleware.RequirePermission("sagas.view"), h.GetTimeline)
+
+		sagas.POST("/:id/resume",
+			localMiddleware.RequirePermission("sagas.manage"),
+			localMiddleware.RequireReason,
+			h.Resume,
+		)
+		sagas.POST("/:id/retry-compensation",
+			localMiddleware.RequirePermission("sagas.manage"),
+			localMiddleware.RequireReason,
+			h.RetryCompensation,
+		)
+		sagas.POST("/:id/abort",
+			localMiddleware.RequirePermission("sagas.manage"),
+			localMiddleware.RequireReason,
+			h.Abort,
+		)
+		sagas.POST("/:id/requeue",
+			localMiddleware.RequirePermission("sagas.manage"),
+			localMiddleware.RequireReason,
+			h.Requeue,
+		)
+	}
+}
+
+// ListSagas 查询 Saga 列表
+func (h *SagaBFFHandler) ListSagas(c *gin.Context) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	queryParams := make(map[string]string)
+	for _, key := range []string{"status", "business_type", "business_id", "limit", "offset"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := sagaClient.Get(c.Request.Context(), "/api/v1/service/sagas", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// ListBusinessTypes 查询已注册步骤定义的业务类型
+func (h *SagaBFFHandler) ListBusinessTypes(c *gin.Context) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	result, statusCode, err := sagaClient.Get(c.Request.Context(), "/api/v1/service/sagas/business-types", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// ListDLQ 查询死信队列
+func (h *SagaBFFHandler) ListDLQ(c *gin.Context) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	queryParams := make(map[string]string)
+	if limit := c.Query("limit"); limit != "" {
+		queryParams["limit"] = limit
+	}
+
+	result, statusCode, err := sagaClient.Get(c.Request.Context(), "/api/v1/service/sagas/dlq", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// GetSaga 查询 Saga 详情（含步骤）
+func (h *SagaBFFHandler) GetSaga(c *gin.Context) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	result, statusCode, err := sagaClient.Get(c.Request.Context(), "/api/v1/service/sagas/"+c.Param("id"), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// GetTimeline 查询 Saga 步骤执行时间线（含依赖关系、幂等键与 Mermaid 甘特图）
+func (h *SagaBFFHandler) GetTimeline(c *gin.Context) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	result, statusCode, err := sagaClient.Get(c.Request.Context(), "/api/v1/service/sagas/"+c.Param("id")+"/timeline", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// Resume 从当前步骤续跑 Saga
+func (h *SagaBFFHandler) Resume(c *gin.Context) {
+	h.drive(c, "resume", "RESUME_SAGA")
+}
+
+// RetryCompensation 重试失败的补偿
+func (h *SagaBFFHandler) RetryCompensation(c *gin.Context) {
+	h.drive(c, "retry-compensation", "RETRY_SAGA_COMPENSATION")
+}
+
+// Abort 终止 Saga 并补偿已完成的步骤
+func (h *SagaBFFHandler) Abort(c *gin.Context) {
+	h.drive(c, "abort", "ABORT_SAGA")
+}
+
+// Requeue 将死信队列中的 Saga 放回自动恢复
+func (h *SagaBFFHandler) Requeue(c *gin.Context) {
+	h.drive(c, "requeue", "REQUEUE_SAGA")
+}
+
+// drive 转发人工干预操作并记录审计日志
+func (h *SagaBFFHandler) drive(c *gin.Context, action, operation string) {
+	sagaClient, ok := h.client(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	req := map[string]interface{}{
+		"reason":   c.GetString("operation_reason"),
+		"operator": c.GetString("username"),
+	}
+
+	result, statusCode, err := sagaClient.Post(c.Request.Context(), "/api/v1/service/sagas/"+id+"/"+action, req)
+	if err != nil {
+		h.auditHelper.LogSensitiveOperation(c, operation, c.Param("service")+":"+id, false)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "调用Saga服务失败", "details": err.Error()})
+		return
+	}
+
+	h.auditHelper.LogSensitiveOperation(c, operation, c.Param("service")+":"+id, statusCode == http.StatusOK)
+	c.JSON(statusCode, result)
+}
+
+// client 根据 :service 参数选择下游服务
+func (h *SagaBFFHandler) client(c *gin.Context) (*client.ServiceClient, bool) {
+	sagaClient, ok := h.clients[c.Param("service")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的服务", "details": "service 必须为 payment、settlement 或 withdrawal"})
+		return nil, false
+	}
+	return sagaClient, true
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 
@@ -24,6 +27,7 @@ var permissionMap = map[string][]string{
 		"kyc.view",
 		"kyc.approve",
 		"analytics.view",
+		"sagas.view",
 	},
 
 	// 财务管理员 - 财务相关
@@ -35,6 +39,7 @@ var permissionMap = map[string][]string{
 		"withdrawals.approve",
 		"reconciliation.view",
 		"invoices.view",
+		"sagas.view",
 	},
 
 	// 风控管理员 - 风控和争议
@@ -193,11 +198,15 @@ func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 func RequireReason(c *gin.Context) {
 	reason := c.Query("reason")
 	if reason == "" {
-		// 尝试从body获取
-		var body map[string]interface{}
-		if err := c.ShouldBindJSON(&body); err == nil {
-			if r, ok := body["reason"].(string); ok {
-				reason = r
+		// 尝试从body获取（读取后回填，后续处理器仍需绑定请求体）
+		if c.Request.Body != nil {
+			data, _ := io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(data))
+			var body map[string]interface{}
+			if err := json.Unmarshal(data, &body); err == nil {
+				if r, ok := body["reason"].(string); ok {
+					reason = r
+				}
 			}
 		}
 	}
@@ -13,9 +13,12 @@ import (
 	"github.com/payment-platform/pkg/configclient"
 	"github.com/payment-platform/pkg/kafka"
 	"github.com/payment-platform/pkg/logger"
+	pb "github.com/payment-platform/proto/analytics"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/client"
+	grpcServer "payment-platform/analytics-service/internal/grpc"
 	"payment-platform/analytics-service/internal/handler"
 	"payment-platform/analytics-service/internal/model"
 	"payment-platform/analytics-service/internal/repository"
@@ -89,11 +92,17 @@ func main() {
 			&model.MerchantMetrics{},
 			&model.ChannelMetrics{},
 			&model.RealtimeStats{},
+			&model.ProcessedEvent{},
+			&model.PaymentRollup{},
+			&model.DeclineRollup{},
+			&model.AnomalyBaseline{},
+			&model.AnomalyAlert{},
 		},
 		EnableTracing:     true,
 		EnableMetrics:     true,
 		EnableRedis:       true,
-		EnableGRPC:        false,
+		EnableGRPC:        config.GetEnvBool("ENABLE_GRPC", false), // 多维指标查询可通过 gRPC 提供
+		GRPCPort:          config.GetEnvInt("GRPC_PORT", 50009),
 		EnableHealthCheck: true,
 		EnableRateLimit:   true,
 		EnableMTLS:        config.GetEnvBool("ENABLE_MTLS", false), // mTLS 服务间认证
@@ -108,12 +117,42 @@ func main() {
 
 	// 初始化Repository和Service
 	analyticsRepo := repository.NewAnalyticsRepository(application.DB)
-	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	rollupRepo := repository.NewRollupRepository(application.DB)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, rollupRepo)
 	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
 
+	// 商户等级维度来自策略服务（带缓存）
+	policyServiceURL := getConfig("POLICY_SERVICE_URL", "http://localhost:40012")
+	tierResolver := service.NewTierResolver(client.NewMerchantPolicyClient(policyServiceURL), 10*time.Minute)
+	logger.Info("MerchantPolicyClient初始化成功", zap.String("url", policyServiceURL))
+
+	// 指标异常检测（告警通过通知服务发送，渠道异常同步给支付路由）
+	notificationServiceURL := getConfig("NOTIFICATION_SERVICE_URL", "http://localhost:40008")
+	anomalyConfig := service.DefaultAnomalyDetectorConfig()
+	anomalyConfig.AlertEmail = getConfig("ANOMALY_ALERT_EMAIL", "")
+	anomalyDetector := service.NewAnomalyDetector(
+		rollupRepo,
+		repository.NewAnomalyRepository(application.DB),
+		client.NewNotificationClient(notificationServiceURL),
+		application.Redis,
+		anomalyConfig,
+	)
+	anomalyHandler := handler.NewAnomalyHandler(anomalyDetector)
+	logger.Info("NotificationClient初始化成功", zap.String("url", notificationServiceURL))
+
 	// 注册HTTP路由
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	analyticsHandler.RegisterRoutes(application.Router)
+	anomalyHandler.RegisterRoutes(application.Router)
+
+	// 注册gRPC服务（ENABLE_GRPC=true 时启用）
+	if application.GRPCServer != nil {
+		pb.RegisterAnalyticsServiceServer(application.GRPCServer, grpcServer.NewAnalyticsServer(analyticsService))
+		logger.Info(fmt.Sprintf("gRPC Server 已注册，将监听端口 %d", config.GetEnvInt("GRPC_PORT", 50009)))
+	}
+
+	// 每小时整点后5分钟检测上一小时的指标
+	go worker.NewAnomalyWorker(anomalyDetector, application.Redis, 5*time.Minute).Start(context.Background())
 
 	// 启动事件消费Workers (消费所有业务事件进行统计分析，优先从配置中心获取)
 	var kafkaBrokers []string
@@ -123,7 +162,10 @@ func main() {
 		logger.Info(fmt.Sprintf("Kafka Brokers配置完成: %v", kafkaBrokers))
 
 		// 创建EventWorker
-		eventWorker := worker.NewEventWorker(application.DB, analyticsRepo)
+		eventWorker := worker.NewEventWorker(application.DB, analyticsRepo, rollupRepo, tierResolver)
+
+		// 定期清理已处理事件记录（去重表）
+		go eventWorker.StartCleanupWorker(context.Background(), time.Hour)
 
 		// 启动支付事件消费Worker
 		paymentEventConsumer := kafka.NewConsumer(kafka.ConsumerConfig{
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/httpclient"
+)
+
+// MerchantPolicyClient 商户策略服务客户端（用于获取商户等级维度）
+type MerchantPolicyClient interface {
+	// GetMerchantTierCode 获取商户当前等级代码，未绑定等级时返回空字符串
+	GetMerchantTierCode(ctx context.Context, merchantID uuid.UUID) (string, error)
+}
+
+type merchantPolicyClient struct {
+	baseURL    string
+	httpClient *httpclient.Client
+}
+
+// NewMerchantPolicyClient 创建商户策略服务客户端
+func NewMerchantPolicyClient(baseURL string) MerchantPolicyClient {
+	return &merchantPolicyClient{
+		baseURL: baseURL,
+		httpClient: httpclient.NewClient(&httpclient.Config{
+			Timeout:       5 * time.Second,
+			MaxRetries:    1,
+			RetryDelay:    500 * time.Millisecond,
+			EnableLogging: false,
+		}),
+	}
+}
+
+// GetMerchantTierCode 获取商户等级代码
+func (c *merchantPolicyClient) GetMerchantTierCode(ctx context.Context, merchantID uuid.UUID) (string, error) {
+	url := fmt.Sprintf("%s/api/v1/policy-bindings/%s", c.baseURL, merchantID.String())
+
+	resp, err := c.httpClient.Do(&httpclient.Request{
+		Method: http.MethodGet,
+		URL:    url,
+		Ctx:    ctx,
+	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// 商户未绑定等级
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("request merchant binding failed: %w", err)
+	}
+
+	var result struct {
+		Code int `json:"code"`
+		Data *struct {
+			Tier *struct {
+				TierCode string `json:"tier_code"`
+			} `json:"tier"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return "", fmt.Errorf("decode response failed: %w", err)
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("policy service returned error code: %d", result.Code)
+	}
+	if result.Data == nil || result.Data.Tier == nil {
+		return "", nil
+	}
+
+	return result.Data.Tier.TierCode, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/httpclient"
+)
+
+// NotificationClient Notification Service HTTP客户端（发送运营告警）
+type NotificationClient struct {
+	baseURL string
+	breaker *httpclient.BreakerClient
+}
+
+// NewNotificationClient 创建Notification客户端实例（带熔断器）
+func NewNotificationClient(baseURL string) *NotificationClient {
+	config := &httpclient.Config{
+		Timeout:    10 * time.Second,
+		MaxRetries: 2,
+		RetryDelay: time.Second,
+	}
+
+	breakerConfig := httpclient.DefaultBreakerConfig("notification-service")
+
+	return &NotificationClient{
+		baseURL: baseURL,
+		breaker: httpclient.NewBreakerClient(config, breakerConfig),
+	}
+}
+
+// SendNotificationRequest 发送通知请求
+type SendNotificationRequest struct {
+	MerchantID uuid.UUID              `json:"merchant_id"` // 平台级告警为空UUID
+	Type       string                 `json:"type"`        // anomaly_alert, anomaly_resolved
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Email      string                 `json:"email,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Priority   string                 `json:"priority,omitempty"` // low, medium, high
+}
+
+// SendNotificationResponse 发送通知响应
+type SendNotificationResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SendAnomalyNotification 发送指标异常告警
+func (c *NotificationClient) SendAnomalyNotification(ctx context.Context, req *SendNotificationRequest) error {
+	url := fmt.Sprintf("%s/api/v1/notifications/send", c.baseURL)
+
+	resp, err := c.breaker.Do(&httpclient.Request{
+		Method: "POST",
+		URL:    url,
+		Body:   req,
+		Ctx:    ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("发送通知失败: %w", err)
+	}
+
+	var result SendNotificationResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("通知服务错误: %s", result.Message)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
 	pb "github.com/payment-platform/proto/analytics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -273,3 +274,54 @@ func (s *AnalyticsServer) ListReports(ctx context.Context, req *pb.ListReportsRe
 		Total:   0,
 	}, nil
 }
+
+// QueryMetrics 多维指标查询
+func (s *AnalyticsServer) QueryMetrics(ctx context.Context, req *pb.QueryMetricsRequest) (*pb.QueryMetricsResponse, error) {
+	if req.StartTime == nil || req.EndTime == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_time 和 end_time 不能为空")
+	}
+
+	filters := make(map[string]string, len(req.Filters)+1)
+	for dim, value := range req.Filters {
+		filters[dim] = value
+	}
+	if req.MerchantId != "" {
+		if _, err := uuid.Parse(req.MerchantId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "无效的商户ID: %v", err)
+		}
+		filters["merchant_id"] = req.MerchantId
+	}
+
+	result, err := s.analyticsService.QueryMetrics(ctx, &service.MetricsQuery{
+		Metrics:     req.Metrics,
+		Dimensions:  req.Dimensions,
+		Granularity: req.Granularity,
+		StartTime:   req.StartTime.AsTime(),
+		EndTime:     req.EndTime.AsTime(),
+		Filters:     filters,
+		Limit:       int(req.Limit),
+	})
+	if err != nil {
+		if bizErr, ok := errors.GetBusinessError(err); ok && bizErr.Code == errors.ErrCodeInvalidRequest {
+			return nil, status.Error(codes.InvalidArgument, bizErr.Message)
+		}
+		return nil, status.Errorf(codes.Internal, "查询指标失败: %v", err)
+	}
+
+	rows := make([]*pb.MetricRow, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		pbRow := &pb.MetricRow{
+			Dimensions: row.Dimensions,
+			Values:     row.Values,
+		}
+		if row.Bucket != nil {
+			pbRow.Bucket = timestamppb.New(*row.Bucket)
+		}
+		rows = append(rows, pbRow)
+	}
+
+	return &pb.QueryMetricsResponse{
+		Rows:        rows,
+		Granularity: result.Granularity,
+	}, nil
+}
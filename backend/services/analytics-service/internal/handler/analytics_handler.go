@@ -2,6 +2,8 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -54,6 +56,12 @@ func (h *AnalyticsHandler) RegisterRoutes(router *gin.Engine) {
 		{
 			realtime.GET("/stats", h.GetRealtimeStats)
 		}
+
+		// 多维指标查询
+		v1.GET("/analytics/query", h.QueryMetrics)
+
+		// 拒绝原因分析
+		v1.GET("/analytics/declines", h.QueryDeclines)
 	}
 }
 
@@ -343,8 +351,174 @@ func (h *AnalyticsHandler) GetRealtimeStats(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// Metrics Query
+
+// QueryMetrics 多维指标查询
+//
+// 示例: /api/v1/analytics/query?metrics=success_count,success_rate&dimensions=channel,country&granularity=day&start_time=2024-01-01&end_time=2024-02-01&merchant_id=xxx
+// 维度参数（merchant_id, channel, payment_method, country, currency, merchant_tier）同时作为过滤条件。
+func (h *AnalyticsHandler) QueryMetrics(c *gin.Context) {
+	startTime, err := parseQueryTime(c.Query("start_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "start_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	endTime, err := parseQueryTime(c.Query("end_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "end_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	query := &service.MetricsQuery{
+		Metrics:     splitQueryList(c.Query("metrics")),
+		Dimensions:  splitQueryList(c.Query("dimensions")),
+		Granularity: c.Query("granularity"),
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Filters:     make(map[string]string),
+	}
+	for dim := range repository.RollupDimensionColumns {
+		if value := c.Query(dim); value != "" {
+			query.Filters[dim] = value
+		}
+	}
+	if merchantIDStr, ok := query.Filters["merchant_id"]; ok {
+		if _, err := uuid.Parse(merchantIDStr); err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "limit 参数错误", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		query.Limit = limit
+	}
+
+	result, err := h.analyticsService.QueryMetrics(c.Request.Context(), query)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询指标失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(result).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// QueryDeclines 拒绝原因分析
+//
+// 示例: /api/v1/analytics/declines?dimensions=channel,category&granularity=week&start_time=2024-01-01&end_time=2024-04-01&merchant_id=xxx
+// 维度参数（merchant_id, channel, payment_method, country, currency, category, decline_type）同时作为过滤条件。
+func (h *AnalyticsHandler) QueryDeclines(c *gin.Context) {
+	startTime, err := parseQueryTime(c.Query("start_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "start_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	endTime, err := parseQueryTime(c.Query("end_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "end_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	query := &service.DeclineQuery{
+		Dimensions:  splitQueryList(c.Query("dimensions")),
+		Granularity: c.Query("granularity"),
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Filters:     make(map[string]string),
+	}
+	for dim := range repository.DeclineDimensionColumns {
+		if value := c.Query(dim); value != "" {
+			query.Filters[dim] = value
+		}
+	}
+	if merchantIDStr, ok := query.Filters["merchant_id"]; ok {
+		if _, err := uuid.Parse(merchantIDStr); err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "limit 参数错误", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		query.Limit = limit
+	}
+
+	result, err := h.analyticsService.QueryDeclines(c.Request.Context(), query)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询拒绝原因失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(result).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
 // Helper functions
 
+// parseQueryTime 解析 RFC3339 时间或 YYYY-MM-DD 日期（UTC）
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// splitQueryList 解析逗号分隔的参数列表
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
 	startDateStr := c.DefaultQuery("start_date", time.Now().AddDate(0, 0, -30).Format("2006-01-02"))
 	endDateStr := c.DefaultQuery("end_date", time.Now().Format("2006-01-02"))
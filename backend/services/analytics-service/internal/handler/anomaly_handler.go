@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"payment-platform/analytics-service/internal/repository"
+	"payment-platform/analytics-service/internal/service"
+)
+
+// AnomalyHandler 指标异常告警处理器
+type AnomalyHandler struct {
+	detector service.AnomalyDetector
+}
+
+// NewAnomalyHandler 创建异常告警处理器实例
+func NewAnomalyHandler(detector service.AnomalyDetector) *AnomalyHandler {
+	return &AnomalyHandler{
+		detector: detector,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *AnomalyHandler) RegisterRoutes(router *gin.Engine) {
+	anomalies := router.Group("/api/v1/analytics/anomalies")
+	{
+		anomalies.GET("", h.ListAlerts)
+		anomalies.POST("/:id/acknowledge", h.AcknowledgeAlert)
+	}
+}
+
+// ListAlerts 查询异常告警
+//
+// 示例: /api/v1/analytics/anomalies?status=open&scope_type=channel&metric=auth_rate&page=1&page_size=20
+func (h *AnomalyHandler) ListAlerts(c *gin.Context) {
+	query := &repository.AnomalyAlertQuery{
+		Status:    c.Query("status"),
+		ScopeType: c.Query("scope_type"),
+		ScopeKey:  c.Query("scope_key"),
+		Metric:    c.Query("metric"),
+		Severity:  c.Query("severity"),
+	}
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	startTime, err := parseQueryTime(c.Query("start_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "start_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	if !startTime.IsZero() {
+		query.StartTime = &startTime
+	}
+	endTime, err := parseQueryTime(c.Query("end_time"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "end_time 参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	if !endTime.IsZero() {
+		query.EndTime = &endTime
+	}
+
+	alerts, total, err := h.detector.ListAlerts(c.Request.Context(), query)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询异常告警失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(gin.H{
+		"list":      alerts,
+		"total":     total,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+	}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// AcknowledgeAlertRequest 确认告警请求
+type AcknowledgeAlertRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by" binding:"required"`
+}
+
+// AcknowledgeAlert 确认异常告警
+func (h *AnomalyHandler) AcknowledgeAlert(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的告警ID", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	var req AcknowledgeAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	alert, err := h.detector.AcknowledgeAlert(c.Request.Context(), id, req.AcknowledgedBy)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "确认告警失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(alert).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 异常检测范围
+const (
+	AnomalyScopeChannel  = "channel"
+	AnomalyScopeMerchant = "merchant"
+	AnomalyScopeCountry  = "country"
+)
+
+// 异常检测指标
+const (
+	AnomalyMetricAuthRate   = "auth_rate"   // 授权成功率（成功 / (成功 + 失败)）
+	AnomalyMetricLatency    = "latency"     // 渠道平均耗时（毫秒）
+	AnomalyMetricRefundRate = "refund_rate" // 退款率（退款笔数 / 成功笔数）
+	AnomalyMetricVolume     = "volume"      // 终态交易笔数
+)
+
+// 异常告警状态
+const (
+	AnomalyStatusOpen         = "open"
+	AnomalyStatusAcknowledged = "acknowledged"
+	AnomalyStatusResolved     = "resolved"
+)
+
+// 异常严重级别
+const (
+	AnomalySeverityWarning  = "warning"
+	AnomalySeverityCritical = "critical"
+)
+
+// AnomalyBaseline 指标滚动基线（EWMA 均值与方差）
+//
+// 成功率、耗时、退款率使用全时段基线（Season = -1）；交易量存在明显的日内周期，
+// 按小时（Season = 0..23）分别维护季节性基线。
+type AnomalyBaseline struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScopeType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_anomaly_baseline_key,priority:1" json:"scope_type"`
+	ScopeKey  string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_anomaly_baseline_key,priority:2" json:"scope_key"`
+	Metric    string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_anomaly_baseline_key,priority:3" json:"metric"`
+	Season    int       `gorm:"not null;default:-1;uniqueIndex:idx_anomaly_baseline_key,priority:4" json:"season"`
+	Mean      float64   `gorm:"not null;default:0" json:"mean"`
+	Variance  float64   `gorm:"not null;default:0" json:"variance"`
+	Samples   int       `gorm:"not null;default:0" json:"samples"`
+	LastValue float64   `gorm:"not null;default:0" json:"last_value"`
+	LastAt    time.Time `json:"last_at"` // 最近一次纳入基线的时间桶
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AnomalyBaseline) TableName() string {
+	return "anomaly_baselines"
+}
+
+// AnomalyAlert 异常告警
+type AnomalyAlert struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScopeType      string     `gorm:"type:varchar(20);not null;index:idx_anomaly_alert_scope" json:"scope_type"`
+	ScopeKey       string     `gorm:"type:varchar(64);not null;index:idx_anomaly_alert_scope" json:"scope_key"`
+	Metric         string     `gorm:"type:varchar(20);not null;index:idx_anomaly_alert_scope" json:"metric"`
+	Severity       string     `gorm:"type:varchar(20);not null" json:"severity"`
+	Status         string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Observed       float64    `json:"observed"`     // 最近一次观测值
+	Expected       float64    `json:"expected"`     // 基线均值
+	ZScore         float64    `json:"z_score"`      // 偏离程度（标准差倍数）
+	SampleSize     int64      `json:"sample_size"`  // 观测窗口内的样本数
+	WindowStart    time.Time  `json:"window_start"` // 首次触发的时间桶
+	LastWindow     time.Time  `json:"last_window"`  // 最近一次触发的时间桶
+	Message        string     `gorm:"type:text" json:"message"`
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+	AcknowledgedBy string     `gorm:"type:varchar(64)" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (AnomalyAlert) TableName() string {
+	return "anomaly_alerts"
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 汇总粒度
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// ProcessedEvent 已处理事件记录（按事件ID去重，保证聚合只计一次）
+type ProcessedEvent struct {
+	EventID     string    `gorm:"type:varchar(128);primary_key" json:"event_id"`
+	EventType   string    `gorm:"type:varchar(64);not null" json:"event_type"`
+	Topic       string    `gorm:"type:varchar(64);not null" json:"topic"`
+	OccurredAt  time.Time `gorm:"not null" json:"occurred_at"`        // 事件发生时间
+	ProcessedAt time.Time `gorm:"not null;index" json:"processed_at"` // 处理时间（用于过期清理）
+}
+
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// PaymentRollup 支付多维汇总（小时/天粒度）
+//
+// 维度为空字符串表示未知（例如事件中缺少国家信息），不使用 NULL，保证唯一索引生效。
+type PaymentRollup struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Granularity    string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_payment_rollup_dims,priority:1" json:"granularity"`
+	BucketStart    time.Time `gorm:"not null;uniqueIndex:idx_payment_rollup_dims,priority:2;index" json:"bucket_start"` // 时间桶起点（UTC）
+	MerchantID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_rollup_dims,priority:3" json:"merchant_id"`
+	Channel        string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_payment_rollup_dims,priority:4" json:"channel"`
+	PaymentMethod  string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_payment_rollup_dims,priority:5" json:"payment_method"`
+	Country        string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_payment_rollup_dims,priority:6" json:"country"`
+	Currency       string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_payment_rollup_dims,priority:7" json:"currency"`
+	MerchantTier   string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_payment_rollup_dims,priority:8" json:"merchant_tier"`
+	PaymentCount   int64     `gorm:"not null;default:0" json:"payment_count"` // 终态支付数（成功+失败+取消）
+	SuccessCount   int64     `gorm:"not null;default:0" json:"success_count"`
+	FailedCount    int64     `gorm:"not null;default:0" json:"failed_count"`
+	CancelledCount int64     `gorm:"not null;default:0" json:"cancelled_count"`
+	SuccessAmount  int64     `gorm:"not null;default:0" json:"success_amount"`
+	RefundCount    int64     `gorm:"not null;default:0" json:"refund_count"`
+	RefundAmount   int64     `gorm:"not null;default:0" json:"refund_amount"`
+	LatencyTotalMs int64     `gorm:"not null;default:0" json:"latency_total_ms"` // 渠道耗时累计（毫秒）
+	LatencyCount   int64     `gorm:"not null;default:0" json:"latency_count"`    // 有耗时数据的支付数
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (PaymentRollup) TableName() string {
+	return "payment_rollups"
+}
+
+// DeclineRollup 支付拒绝原因汇总（天粒度）
+//
+// 分类与类型取自 pkg/decline，维度为空字符串表示未知。
+type DeclineRollup struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BucketStart    time.Time `gorm:"not null;uniqueIndex:idx_decline_rollup_dims,priority:1;index" json:"bucket_start"` // 时间桶起点（UTC 自然日）
+	MerchantID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_decline_rollup_dims,priority:2" json:"merchant_id"`
+	Channel        string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:3" json:"channel"`
+	PaymentMethod  string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:4" json:"payment_method"`
+	Country        string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:5" json:"country"`
+	Currency       string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:6" json:"currency"`
+	Category       string    `gorm:"type:varchar(40);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:7" json:"category"`
+	DeclineType    string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_decline_rollup_dims,priority:8" json:"decline_type"`
+	DeclineCount   int64     `gorm:"not null;default:0" json:"decline_count"`
+	DeclineAmount  int64     `gorm:"not null;default:0" json:"decline_amount"`
+	RetryableCount int64     `gorm:"not null;default:0" json:"retryable_count"` // 可原样重试的拒绝数
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (DeclineRollup) TableName() string {
+	return "decline_rollups"
+}
+
+// SourcePayment payment-gateway 支付表的只读映射（仅用于回填，不参与迁移）
+type SourcePayment struct {
+	ID         uuid.UUID
+	MerchantID uuid.UUID
+	Channel    string
+	PayMethod  string
+	Country    string
+	Currency   string
+	Amount     int64
+	Status     string
+	LatencyMs  int64
+	OccurredAt time.Time
+}
+
+// SourceRefund payment-gateway 退款表的只读映射（维度取自关联的支付记录）
+type SourceRefund struct {
+	ID         uuid.UUID
+	MerchantID uuid.UUID
+	Channel    string
+	PayMethod  string
+	Country    string
+	Currency   string
+	Amount     int64
+	OccurredAt time.Time
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-platform/analytics-service/internal/model"
+)
+
+// AnomalyRepository 异常检测仓储接口
+type AnomalyRepository interface {
+	// 基线
+	ListBaselines(ctx context.Context, scopeType, metric string, season int) ([]*model.AnomalyBaseline, error)
+	SaveBaseline(ctx context.Context, baseline *model.AnomalyBaseline) error
+
+	// 告警
+	GetActiveAlert(ctx context.Context, scopeType, scopeKey, metric string) (*model.AnomalyAlert, error)
+	GetAlert(ctx context.Context, id uuid.UUID) (*model.AnomalyAlert, error)
+	ListActiveAlerts(ctx context.Context, scopeType, metric string) ([]*model.AnomalyAlert, error)
+	ListAlerts(ctx context.Context, query *AnomalyAlertQuery) ([]*model.AnomalyAlert, int64, error)
+	SaveAlert(ctx context.Context, alert *model.AnomalyAlert) error
+}
+
+type anomalyRepository struct {
+	db *gorm.DB
+}
+
+// NewAnomalyRepository 创建异常检测仓储实例
+func NewAnomalyRepository(db *gorm.DB) AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+// AnomalyAlertQuery 告警查询条件
+type AnomalyAlertQuery struct {
+	Status    string
+	ScopeType string
+	ScopeKey  string
+	Metric    string
+	Severity  string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Page      int
+	PageSize  int
+}
+
+func (r *anomalyRepository) ListBaselines(ctx context.Context, scopeType, metric string, season int) ([]*model.AnomalyBaseline, error) {
+	var baselines []*model.AnomalyBaseline
+	err := r.db.WithContext(ctx).
+		Where("scope_type = ? AND metric = ? AND season = ?", scopeType, metric, season).
+		Find(&baselines).Error
+	return baselines, err
+}
+
+func (r *anomalyRepository) SaveBaseline(ctx context.Context, baseline *model.AnomalyBaseline) error {
+	return r.db.WithContext(ctx).Save(baseline).Error
+}
+
+// GetActiveAlert 获取未解决的告警（open 或 acknowledged）
+func (r *anomalyRepository) GetActiveAlert(ctx context.Context, scopeType, scopeKey, metric string) (*model.AnomalyAlert, error) {
+	var alert model.AnomalyAlert
+	err := r.db.WithContext(ctx).
+		Where("scope_type = ? AND scope_key = ? AND metric = ? AND status <> ?",
+			scopeType, scopeKey, metric, model.AnomalyStatusResolved).
+		Order("created_at DESC").
+		First(&alert).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &alert, err
+}
+
+func (r *anomalyRepository) GetAlert(ctx context.Context, id uuid.UUID) (*model.AnomalyAlert, error) {
+	var alert model.AnomalyAlert
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&alert).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &alert, err
+}
+
+func (r *anomalyRepository) ListActiveAlerts(ctx context.Context, scopeType, metric string) ([]*model.AnomalyAlert, error) {
+	var alerts []*model.AnomalyAlert
+	err := r.db.WithContext(ctx).
+		Where("scope_type = ? AND metric = ? AND status <> ?", scopeType, metric, model.AnomalyStatusResolved).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *anomalyRepository) ListAlerts(ctx context.Context, query *AnomalyAlertQuery) ([]*model.AnomalyAlert, int64, error) {
+	db := r.db.WithContext(ctx).Model(&model.AnomalyAlert{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.ScopeType != "" {
+		db = db.Where("scope_type = ?", query.ScopeType)
+	}
+	if query.ScopeKey != "" {
+		db = db.Where("scope_key = ?", query.ScopeKey)
+	}
+	if query.Metric != "" {
+		db = db.Where("metric = ?", query.Metric)
+	}
+	if query.Severity != "" {
+		db = db.Where("severity = ?", query.Severity)
+	}
+	if query.StartTime != nil {
+		db = db.Where("created_at >= ?", *query.StartTime)
+	}
+	if query.EndTime != nil {
+		db = db.Where("created_at < ?", *query.EndTime)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []*model.AnomalyAlert
+	err := db.Order("created_at DESC").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+func (r *anomalyRepository) SaveAlert(ctx context.Context, alert *model.AnomalyAlert) error {
+	return r.db.WithContext(ctx).Save(alert).Error
+}
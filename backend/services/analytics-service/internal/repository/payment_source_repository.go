@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-platform/analytics-service/internal/model"
+)
+
+// PaymentSourceRepository payment-gateway 数据只读仓储（回填指标使用）
+type PaymentSourceRepository interface {
+	// ScanCreatedPayments 按批次遍历 [start, end) 内创建的支付（OccurredAt 为创建时间）
+	ScanCreatedPayments(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourcePayment) error) error
+	// ScanTerminalPayments 按批次遍历 [start, end) 内进入终态的支付
+	ScanTerminalPayments(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourcePayment) error) error
+	// ScanSuccessRefunds 按批次遍历 [start, end) 内成功的退款
+	ScanSuccessRefunds(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourceRefund) error) error
+}
+
+type paymentSourceRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentSourceRepository 创建支付数据只读仓储
+func NewPaymentSourceRepository(db *gorm.DB) PaymentSourceRepository {
+	return &paymentSourceRepository{db: db}
+}
+
+// paymentOccurredAtExpr 支付进入终态的时间（成功取 paid_at，其余取最后更新时间）
+const paymentOccurredAtExpr = "CASE WHEN status = 'success' THEN COALESCE(paid_at, updated_at) ELSE updated_at END"
+
+func (r *paymentSourceRepository) ScanCreatedPayments(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourcePayment) error) error {
+	lastID := uuid.Nil
+	for {
+		var batch []*model.SourcePayment
+		err := r.db.WithContext(ctx).Table("payments").
+			Select("id, merchant_id, channel, COALESCE(pay_method, '') AS pay_method, COALESCE(country, '') AS country, currency, amount, status, created_at AS occurred_at").
+			Where("deleted_at IS NULL AND id > ?", lastID).
+			Where("created_at >= ? AND created_at < ?", start, end).
+			Order("id").
+			Limit(batchSize).
+			Scan(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func (r *paymentSourceRepository) ScanTerminalPayments(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourcePayment) error) error {
+	lastID := uuid.Nil
+	for {
+		var batch []*model.SourcePayment
+		err := r.db.WithContext(ctx).Table("payments").
+			Select("id, merchant_id, channel, COALESCE(pay_method, '') AS pay_method, COALESCE(country, '') AS country, currency, amount, status, COALESCE(channel_latency_ms, 0) AS latency_ms, "+paymentOccurredAtExpr+" AS occurred_at").
+			Where("deleted_at IS NULL AND status IN ? AND id > ?", []string{"success", "failed", "cancelled"}, lastID).
+			Where(paymentOccurredAtExpr+" >= ? AND "+paymentOccurredAtExpr+" < ?", start, end).
+			Order("id").
+			Limit(batchSize).
+			Scan(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func (r *paymentSourceRepository) ScanSuccessRefunds(ctx context.Context, start, end time.Time, batchSize int, fn func([]*model.SourceRefund) error) error {
+	lastID := uuid.Nil
+	for {
+		var batch []*model.SourceRefund
+		err := r.db.WithContext(ctx).Table("refunds r").
+			Select("r.id, r.merchant_id, p.channel, COALESCE(p.pay_method, '') AS pay_method, COALESCE(p.country, '') AS country, r.currency, r.amount, COALESCE(r.refunded_at, r.updated_at) AS occurred_at").
+			Joins("JOIN payments p ON p.id = r.payment_id").
+			Where("r.deleted_at IS NULL AND r.status = ? AND r.id > ?", "success", lastID).
+			Where("COALESCE(r.refunded_at, r.updated_at) >= ? AND COALESCE(r.refunded_at, r.updated_at) < ?", start, end).
+			Order("r.id").
+			Limit(batchSize).
+			Scan(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/analytics-service/internal/model"
+)
+
+// RollupDimensionColumns 可查询维度与列名映射
+var RollupDimensionColumns = map[string]string{
+	"merchant_id":    "merchant_id",
+	"channel":        "channel",
+	"payment_method": "payment_method",
+	"country":        "country",
+	"currency":       "currency",
+	"merchant_tier":  "merchant_tier",
+}
+
+// rollupCounterColumns 汇总计数列（查询时求和，写入时累加）
+var rollupCounterColumns = []string{
+	"payment_count",
+	"success_count",
+	"failed_count",
+	"cancelled_count",
+	"success_amount",
+	"refund_count",
+	"refund_amount",
+	"latency_total_ms",
+	"latency_count",
+}
+
+// DeclineDimensionColumns 拒绝原因汇总可查询维度与列名映射
+var DeclineDimensionColumns = map[string]string{
+	"merchant_id":    "merchant_id",
+	"channel":        "channel",
+	"payment_method": "payment_method",
+	"country":        "country",
+	"currency":       "currency",
+	"category":       "category",
+	"decline_type":   "decline_type",
+}
+
+// declineCounterColumns 拒绝原因汇总计数列
+var declineCounterColumns = []string{
+	"decline_count",
+	"decline_amount",
+	"retryable_count",
+}
+
+// RollupRepository 多维汇总仓储接口
+type RollupRepository interface {
+	// ClaimEvent 在事务内登记事件ID，返回 false 表示事件已处理过（重复投递）
+	ClaimEvent(tx *gorm.DB, evt *model.ProcessedEvent) (bool, error)
+	// UpsertRollups 在事务内原子累加汇总计数
+	UpsertRollups(tx *gorm.DB, rollups []*model.PaymentRollup) error
+	// QueryRollups 按维度和粒度聚合查询
+	QueryRollups(ctx context.Context, query *RollupQuery) ([]*RollupAggregate, error)
+	// UpsertDeclineRollups 在事务内原子累加拒绝原因汇总
+	UpsertDeclineRollups(tx *gorm.DB, rollups []*model.DeclineRollup) error
+	// QueryDeclines 按维度和粒度聚合查询拒绝原因（粒度不支持 hour）
+	QueryDeclines(ctx context.Context, query *RollupQuery) ([]*DeclineAggregate, error)
+	// ReplaceRange 用回填结果替换 [start, end) 范围内的多维汇总和旧版日指标（支付指标、渠道指标）
+	ReplaceRange(ctx context.Context, start, end time.Time, rollups []*model.PaymentRollup, paymentMetrics []*model.PaymentMetrics, channelMetrics []*model.ChannelMetrics) error
+	// PurgeProcessedEvents 清理过期的已处理事件记录
+	PurgeProcessedEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+type rollupRepository struct {
+	db *gorm.DB
+}
+
+// NewRollupRepository 创建汇总仓储实例
+func NewRollupRepository(db *gorm.DB) RollupRepository {
+	return &rollupRepository{db: db}
+}
+
+// RollupQuery 汇总查询条件
+type RollupQuery struct {
+	Granularity string            // hour, day, week, month, total
+	Dimensions  []string          // 分组维度（见 RollupDimensionColumns）
+	Filters     map[string]string // 维度过滤
+	StartTime   time.Time
+	EndTime     time.Time // 不含
+	Limit       int
+}
+
+// RollupAggregate 汇总查询结果行（未参与分组的维度为空）
+type RollupAggregate struct {
+	Bucket         *time.Time `json:"bucket,omitempty"`
+	MerchantID     string     `json:"merchant_id,omitempty"`
+	Channel        string     `json:"channel,omitempty"`
+	PaymentMethod  string     `json:"payment_method,omitempty"`
+	Country        string     `json:"country,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	MerchantTier   string     `json:"merchant_tier,omitempty"`
+	PaymentCount   int64      `json:"payment_count"`
+	SuccessCount   int64      `json:"success_count"`
+	FailedCount    int64      `json:"failed_count"`
+	CancelledCount int64      `json:"cancelled_count"`
+	SuccessAmount  int64      `json:"success_amount"`
+	RefundCount    int64      `json:"refund_count"`
+	RefundAmount   int64      `json:"refund_amount"`
+	LatencyTotalMs int64      `json:"latency_total_ms"`
+	LatencyCount   int64      `json:"latency_count"`
+}
+
+// Dimension 按维度名取值
+func (a *RollupAggregate) Dimension(name string) string {
+	switch name {
+	case "merchant_id":
+		return a.MerchantID
+	case "channel":
+		return a.Channel
+	case "payment_method":
+		return a.PaymentMethod
+	case "country":
+		return a.Country
+	case "currency":
+		return a.Currency
+	case "merchant_tier":
+		return a.MerchantTier
+	}
+	return ""
+}
+
+// DeclineAggregate 拒绝原因汇总查询结果行（未参与分组的维度为空）
+type DeclineAggregate struct {
+	Bucket         *time.Time `json:"bucket,omitempty"`
+	MerchantID     string     `json:"merchant_id,omitempty"`
+	Channel        string     `json:"channel,omitempty"`
+	PaymentMethod  string     `json:"payment_method,omitempty"`
+	Country        string     `json:"country,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	Category       string     `json:"category,omitempty"`
+	DeclineType    string     `json:"decline_type,omitempty"`
+	DeclineCount   int64      `json:"decline_count"`
+	DeclineAmount  int64      `json:"decline_amount"`
+	RetryableCount int64      `json:"retryable_count"`
+}
+
+// Dimension 按维度名取值
+func (a *DeclineAggregate) Dimension(name string) string {
+	switch name {
+	case "merchant_id":
+		return a.MerchantID
+	case "channel":
+		return a.Channel
+	case "payment_method":
+		return a.PaymentMethod
+	case "country":
+		return a.Country
+	case "currency":
+		return a.Currency
+	case "category":
+		return a.Category
+	case "decline_type":
+		return a.DeclineType
+	}
+	return ""
+}
+
+func (r *rollupRepository) ClaimEvent(tx *gorm.DB, evt *model.ProcessedEvent) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(evt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *rollupRepository) UpsertRollups(tx *gorm.DB, rollups []*model.PaymentRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	// 计数列在冲突时与已有值相加，避免读-改-写竞争
+	assignments := map[string]interface{}{"updated_at": gorm.Expr("EXCLUDED.updated_at")}
+	for _, col := range rollupCounterColumns {
+		assignments[col] = gorm.Expr(fmt.Sprintf("payment_rollups.%s + EXCLUDED.%s", col, col))
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "granularity"}, {Name: "bucket_start"}, {Name: "merchant_id"}, {Name: "channel"},
+			{Name: "payment_method"}, {Name: "country"}, {Name: "currency"}, {Name: "merchant_tier"},
+		},
+		DoUpdates: clause.Assignments(assignments),
+	}).Create(&rollups).Error
+}
+
+func (r *rollupRepository) QueryRollups(ctx context.Context, query *RollupQuery) ([]*RollupAggregate, error) {
+	sourceGranularity := model.GranularityDay
+	bucketExpr := ""
+	switch query.Granularity {
+	case model.GranularityHour:
+		sourceGranularity = model.GranularityHour
+		bucketExpr = "bucket_start"
+	case model.GranularityDay:
+		bucketExpr = "bucket_start"
+	case "week", "month":
+		bucketExpr = fmt.Sprintf("date_trunc('%s', bucket_start AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'", query.Granularity)
+	case "total":
+	default:
+		return nil, fmt.Errorf("不支持的粒度: %s", query.Granularity)
+	}
+
+	selects := make([]string, 0, len(query.Dimensions)+len(rollupCounterColumns)+1)
+	groups := make([]string, 0, len(query.Dimensions)+1)
+	if bucketExpr != "" {
+		selects = append(selects, bucketExpr+" AS bucket")
+		groups = append(groups, bucketExpr)
+	}
+	for _, dim := range query.Dimensions {
+		col, ok := RollupDimensionColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("不支持的维度: %s", dim)
+		}
+		selects = append(selects, fmt.Sprintf("%s::text AS %s", col, dim))
+		groups = append(groups, col)
+	}
+	for _, col := range rollupCounterColumns {
+		selects = append(selects, fmt.Sprintf("SUM(%s) AS %s", col, col))
+	}
+
+	db := r.db.WithContext(ctx).Model(&model.PaymentRollup{}).
+		Select(strings.Join(selects, ", ")).
+		Where("granularity = ? AND bucket_start >= ? AND bucket_start < ?", sourceGranularity, query.StartTime, query.EndTime)
+	for dim, value := range query.Filters {
+		col, ok := RollupDimensionColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("不支持的过滤维度: %s", dim)
+		}
+		db = db.Where(fmt.Sprintf("%s = ?", col), value)
+	}
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var rows []*RollupAggregate
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) UpsertDeclineRollups(tx *gorm.DB, rollups []*model.DeclineRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	assignments := map[string]interface{}{"updated_at": gorm.Expr("EXCLUDED.updated_at")}
+	for _, col := range declineCounterColumns {
+		assignments[col] = gorm.Expr(fmt.Sprintf("decline_rollups.%s + EXCLUDED.%s", col, col))
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "bucket_start"}, {Name: "merchant_id"}, {Name: "channel"}, {Name: "payment_method"},
+			{Name: "country"}, {Name: "currency"}, {Name: "category"}, {Name: "decline_type"},
+		},
+		DoUpdates: clause.Assignments(assignments),
+	}).Create(&rollups).Error
+}
+
+func (r *rollupRepository) QueryDeclines(ctx context.Context, query *RollupQuery) ([]*DeclineAggregate, error) {
+	bucketExpr := ""
+	switch query.Granularity {
+	case model.GranularityDay:
+		bucketExpr = "bucket_start"
+	case "week", "month":
+		bucketExpr = fmt.Sprintf("date_trunc('%s', bucket_start AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'", query.Granularity)
+	case "total":
+	default:
+		return nil, fmt.Errorf("不支持的粒度: %s", query.Granularity)
+	}
+
+	selects := make([]string, 0, len(query.Dimensions)+len(declineCounterColumns)+1)
+	groups := make([]string, 0, len(query.Dimensions)+1)
+	if bucketExpr != "" {
+		selects = append(selects, bucketExpr+" AS bucket")
+		groups = append(groups, bucketExpr)
+	}
+	for _, dim := range query.Dimensions {
+		col, ok := DeclineDimensionColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("不支持的维度: %s", dim)
+		}
+		selects = append(selects, fmt.Sprintf("%s::text AS %s", col, dim))
+		groups = append(groups, col)
+	}
+	for _, col := range declineCounterColumns {
+		selects = append(selects, fmt.Sprintf("SUM(%s) AS %s", col, col))
+	}
+
+	db := r.db.WithContext(ctx).Model(&model.DeclineRollup{}).
+		Select(strings.Join(selects, ", ")).
+		Where("bucket_start >= ? AND bucket_start < ?", query.StartTime, query.EndTime)
+	for dim, value := range query.Filters {
+		col, ok := DeclineDimensionColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("不支持的过滤维度: %s", dim)
+		}
+		db = db.Where(fmt.Sprintf("%s = ?", col), value)
+	}
+	if len(groups) > 0 {
+		db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var rows []*DeclineAggregate
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) ReplaceRange(ctx context.Context, start, end time.Time, rollups []*model.PaymentRollup, paymentMetrics []*model.PaymentMetrics, channelMetrics []*model.ChannelMetrics) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bucket_start >= ? AND bucket_start < ?", start, end).
+			Delete(&model.PaymentRollup{}).Error; err != nil {
+			return err
+		}
+		if len(rollups) > 0 {
+			if err := tx.CreateInBatches(rollups, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		// 旧版日指标整天替换（硬删除，避免软删除记录干扰唯一日期查询）
+		startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+		if err := tx.Unscoped().Where("date >= ? AND date < ?", startDate, endDate).
+			Delete(&model.PaymentMetrics{}).Error; err != nil {
+			return err
+		}
+		if len(paymentMetrics) > 0 {
+			if err := tx.CreateInBatches(paymentMetrics, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("date >= ? AND date < ?", startDate, endDate).
+			Delete(&model.ChannelMetrics{}).Error; err != nil {
+			return err
+		}
+		if len(channelMetrics) > 0 {
+			return tx.CreateInBatches(channelMetrics, 500).Error
+		}
+		return nil
+	})
+}
+
+func (r *rollupRepository) PurgeProcessedEvents(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("processed_at < ?", before).Delete(&model.ProcessedEvent{})
+	return result.RowsAffected, result.Error
+}
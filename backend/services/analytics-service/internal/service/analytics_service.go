@@ -30,16 +30,24 @@ type AnalyticsService interface {
 	// 实时统计
 	GetRealtimeStats(ctx context.Context, query *repository.RealtimeStatsQuery) ([]*model.RealtimeStats, error)
 	IncrementStats(ctx context.Context, merchantID *uuid.UUID, statType, statKey string, increment int64) error
+
+	// 多维指标查询
+	QueryMetrics(ctx context.Context, query *MetricsQuery) (*MetricsResult, error)
+
+	// 拒绝原因分析
+	QueryDeclines(ctx context.Context, query *DeclineQuery) (*DeclineResult, error)
 }
 
 type analyticsService struct {
 	analyticsRepo repository.AnalyticsRepository
+	rollupRepo    repository.RollupRepository
 }
 
 // NewAnalyticsService 创建分析服务实例
-func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository) AnalyticsService {
+func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository, rollupRepo repository.RollupRepository) AnalyticsService {
 	return &analyticsService{
 		analyticsRepo: analyticsRepo,
+		rollupRepo:    rollupRepo,
 	}
 }
 
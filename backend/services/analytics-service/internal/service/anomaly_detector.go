@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/router"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/client"
+	"payment-platform/analytics-service/internal/model"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+// AnomalyScopeChannelCountry 渠道 × 国家组合范围（scope_key 形如 stripe:US）
+const AnomalyScopeChannelCountry = "channel_country"
+
+// AnomalyDetectorConfig 异常检测配置
+type AnomalyDetectorConfig struct {
+	Alpha            float64       // EWMA 平滑系数
+	WarningZ         float64       // 告警阈值（标准差倍数）
+	CriticalZ        float64       // 严重阈值（标准差倍数）
+	MinSamples       int           // 全时段基线最少样本数（小时）
+	VolumeMinSamples int           // 交易量季节性基线最少样本数（天）
+	MinVolume        int64         // 单个窗口参与计算的最少笔数
+	AlertEmail       string        // 运营告警邮箱
+	RouterSignalTTL  time.Duration // 渠道异常信号有效期
+}
+
+// DefaultAnomalyDetectorConfig 默认异常检测配置
+func DefaultAnomalyDetectorConfig() AnomalyDetectorConfig {
+	return AnomalyDetectorConfig{
+		Alpha:            0.1,
+		WarningZ:         3,
+		CriticalZ:        4.5,
+		MinSamples:       24,
+		VolumeMinSamples: 7,
+		MinVolume:        30,
+		RouterSignalTTL:  2 * time.Hour,
+	}
+}
+
+// AnomalyDetectResult 单个窗口的检测结果
+type AnomalyDetectResult struct {
+	WindowStart time.Time `json:"window_start"`
+	Evaluated   int       `json:"evaluated"`
+	Opened      int       `json:"opened"`
+	Escalated   int       `json:"escalated"`
+	Resolved    int       `json:"resolved"`
+}
+
+// AnomalyDetector 指标异常检测服务
+type AnomalyDetector interface {
+	// Detect 检测 [windowStart, windowStart+1h) 的小时汇总，窗口已处理过时不会重复计入基线
+	Detect(ctx context.Context, windowStart time.Time) (*AnomalyDetectResult, error)
+	ListAlerts(ctx context.Context, query *repository.AnomalyAlertQuery) ([]*model.AnomalyAlert, int64, error)
+	AcknowledgeAlert(ctx context.Context, id uuid.UUID, operator string) (*model.AnomalyAlert, error)
+}
+
+type anomalyDetector struct {
+	rollupRepo  repository.RollupRepository
+	anomalyRepo repository.AnomalyRepository
+	notifier    *client.NotificationClient
+	redisClient *redis.Client
+	config      AnomalyDetectorConfig
+}
+
+// NewAnomalyDetector 创建异常检测服务，notifier / redisClient 为 nil 时分别跳过通知和路由信号
+func NewAnomalyDetector(
+	rollupRepo repository.RollupRepository,
+	anomalyRepo repository.AnomalyRepository,
+	notifier *client.NotificationClient,
+	redisClient *redis.Client,
+	config AnomalyDetectorConfig,
+) AnomalyDetector {
+	return &anomalyDetector{
+		rollupRepo:  rollupRepo,
+		anomalyRepo: anomalyRepo,
+		notifier:    notifier,
+		redisClient: redisClient,
+		config:      config,
+	}
+}
+
+// anomalyScopeDimensions 检测范围对应的汇总维度
+var anomalyScopeDimensions = []struct {
+	scope      string
+	dimensions []string
+}{
+	{model.AnomalyScopeChannel, []string{"channel"}},
+	{model.AnomalyScopeMerchant, []string{"merchant_id"}},
+	{model.AnomalyScopeCountry, []string{"country"}},
+	{AnomalyScopeChannelCountry, []string{"channel", "country"}},
+}
+
+// anomalyMetric 指标定义
+type anomalyMetric struct {
+	name     string
+	lowerBad bool // true 表示指标下降为异常（成功率、交易量），否则上升为异常
+	seasonal bool // 是否按小时维护季节性基线
+	// observe 返回观测值和样本数
+	observe func(a *repository.RollupAggregate) (float64, int64)
+	// stdFloor 标准差下限，避免基线过于平稳时对微小波动告警
+	stdFloor func(mean float64) float64
+}
+
+var anomalyMetrics = []anomalyMetric{
+	{
+		name:     model.AnomalyMetricAuthRate,
+		lowerBad: true,
+		observe: func(a *repository.RollupAggregate) (float64, int64) {
+			attempts := a.SuccessCount + a.FailedCount
+			if attempts == 0 {
+				return 0, 0
+			}
+			return float64(a.SuccessCount) / float64(attempts), attempts
+		},
+		stdFloor: func(float64) float64 { return 0.02 },
+	},
+	{
+		name: model.AnomalyMetricLatency,
+		observe: func(a *repository.RollupAggregate) (float64, int64) {
+			if a.LatencyCount == 0 {
+				return 0, 0
+			}
+			return float64(a.LatencyTotalMs) / float64(a.LatencyCount), a.LatencyCount
+		},
+		stdFloor: func(mean float64) float64 { return math.Max(mean*0.1, 50) },
+	},
+	{
+		name: model.AnomalyMetricRefundRate,
+		observe: func(a *repository.RollupAggregate) (float64, int64) {
+			if a.SuccessCount == 0 {
+				return 0, 0
+			}
+			return float64(a.RefundCount) / float64(a.SuccessCount), a.SuccessCount
+		},
+		stdFloor: func(float64) float64 { return 0.005 },
+	},
+	{
+		name:     model.AnomalyMetricVolume,
+		lowerBad: true,
+		seasonal: true,
+		observe: func(a *repository.RollupAggregate) (float64, int64) {
+			return float64(a.PaymentCount), a.PaymentCount
+		},
+		// 交易笔数近似泊松分布，标准差不小于 sqrt(mean)
+		stdFloor: func(mean float64) float64 { return math.Max(math.Sqrt(mean), mean*0.1) },
+	},
+}
+
+// Detect 检测单个小时窗口
+func (d *anomalyDetector) Detect(ctx context.Context, windowStart time.Time) (*AnomalyDetectResult, error) {
+	windowStart = windowStart.UTC().Truncate(time.Hour)
+	result := &AnomalyDetectResult{WindowStart: windowStart}
+
+	for _, scope := range anomalyScopeDimensions {
+		aggregates, err := d.rollupRepo.QueryRollups(ctx, &repository.RollupQuery{
+			Granularity: model.GranularityHour,
+			Dimensions:  scope.dimensions,
+			StartTime:   windowStart,
+			EndTime:     windowStart.Add(time.Hour),
+			Limit:       maxMetricsQueryLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("查询小时汇总失败: %w", err)
+		}
+
+		observations := make(map[string]*repository.RollupAggregate, len(aggregates))
+		for _, agg := range aggregates {
+			key := anomalyScopeKey(agg, scope.dimensions)
+			if key == "" {
+				continue
+			}
+			observations[key] = agg
+		}
+
+		for i := range anomalyMetrics {
+			if err := d.detectMetric(ctx, scope.scope, &anomalyMetrics[i], windowStart, observations, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	logger.Info("Analytics: 异常检测完成",
+		zap.Time("window_start", windowStart),
+		zap.Int("evaluated", result.Evaluated),
+		zap.Int("opened", result.Opened),
+		zap.Int("escalated", result.Escalated),
+		zap.Int("resolved", result.Resolved))
+
+	return result, nil
+}
+
+func (d *anomalyDetector) detectMetric(
+	ctx context.Context,
+	scope string,
+	metric *anomalyMetric,
+	windowStart time.Time,
+	observations map[string]*repository.RollupAggregate,
+	result *AnomalyDetectResult,
+) error {
+	season, minSamples := -1, d.config.MinSamples
+	if metric.seasonal {
+		season, minSamples = windowStart.Hour(), d.config.VolumeMinSamples
+	}
+
+	baselines, err := d.anomalyRepo.ListBaselines(ctx, scope, metric.name, season)
+	if err != nil {
+		return fmt.Errorf("查询指标基线失败: %w", err)
+	}
+	baselineByKey := make(map[string]*model.AnomalyBaseline, len(baselines))
+	for _, b := range baselines {
+		baselineByKey[b.ScopeKey] = b
+	}
+
+	keys := make(map[string]bool, len(observations)+len(baselineByKey))
+	for key := range observations {
+		keys[key] = true
+	}
+	if metric.seasonal {
+		// 交易量中断时该范围不会出现在汇总中，需按 0 计算
+		for key := range baselineByKey {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		var value float64
+		var size int64
+		if agg, ok := observations[key]; ok {
+			value, size = metric.observe(agg)
+		}
+
+		baseline := baselineByKey[key]
+		if baseline == nil {
+			baseline = &model.AnomalyBaseline{ScopeType: scope, ScopeKey: key, Metric: metric.name, Season: season}
+		}
+		if !baseline.LastAt.IsZero() && !baseline.LastAt.Before(windowStart) {
+			// 窗口已处理过
+			continue
+		}
+
+		if metric.seasonal {
+			if float64(size) < float64(d.config.MinVolume) && baseline.Mean < float64(d.config.MinVolume) {
+				continue
+			}
+		} else if size < d.config.MinVolume {
+			continue
+		}
+		result.Evaluated++
+
+		severity, zScore := "", 0.0
+		if baseline.Samples >= minSamples {
+			std := math.Max(math.Sqrt(baseline.Variance), metric.stdFloor(baseline.Mean))
+			zScore = (value - baseline.Mean) / std
+			deviation := zScore
+			if metric.lowerBad {
+				deviation = -zScore
+			}
+			switch {
+			case deviation >= d.config.CriticalZ:
+				severity = model.AnomalySeverityCritical
+			case deviation >= d.config.WarningZ:
+				severity = model.AnomalySeverityWarning
+			}
+		}
+
+		if severity != "" {
+			// 异常值不计入基线，避免基线被持续异常拉偏
+			if err := d.raiseAlert(ctx, scope, key, metric.name, severity, value, baseline.Mean, zScore, size, windowStart, result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		updateBaseline(baseline, value, d.config.Alpha, windowStart)
+		if err := d.anomalyRepo.SaveBaseline(ctx, baseline); err != nil {
+			return fmt.Errorf("保存指标基线失败: %w", err)
+		}
+		if err := d.resolveAlert(ctx, scope, key, metric.name, value, windowStart, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateBaseline EWMA 更新均值和方差
+//
+// 样本不足时使用 1/(n+1) 作为平滑系数，相当于累计平均，使基线更快收敛。
+func updateBaseline(b *model.AnomalyBaseline, value, alpha float64, windowStart time.Time) {
+	if b.Samples == 0 {
+		b.Mean, b.Variance = value, 0
+	} else {
+		a := math.Max(alpha, 1/float64(b.Samples+1))
+		diff := value - b.Mean
+		incr := a * diff
+		b.Mean += incr
+		b.Variance = (1 - a) * (b.Variance + diff*incr)
+	}
+	b.Samples++
+	b.LastValue = value
+	b.LastAt = windowStart
+}
+
+func (d *anomalyDetector) raiseAlert(
+	ctx context.Context,
+	scope, key, metric, severity string,
+	observed, expected, zScore float64,
+	size int64,
+	windowStart time.Time,
+	result *AnomalyDetectResult,
+) error {
+	alert, err := d.anomalyRepo.GetActiveAlert(ctx, scope, key, metric)
+	if err != nil {
+		return fmt.Errorf("查询告警失败: %w", err)
+	}
+
+	notify := false
+	if alert == nil {
+		alert = &model.AnomalyAlert{
+			ScopeType:   scope,
+			ScopeKey:    key,
+			Metric:      metric,
+			Severity:    severity,
+			Status:      model.AnomalyStatusOpen,
+			WindowStart: windowStart,
+		}
+		notify = true
+		result.Opened++
+	} else {
+		if !alert.LastWindow.Before(windowStart) {
+			return nil
+		}
+		if alert.Severity == model.AnomalySeverityWarning && severity == model.AnomalySeverityCritical {
+			alert.Severity = severity
+			alert.Status = model.AnomalyStatusOpen
+			notify = true
+			result.Escalated++
+		}
+		// 上次通知失败时重试
+		notify = notify || alert.NotifiedAt == nil
+	}
+
+	alert.Observed = observed
+	alert.Expected = expected
+	alert.ZScore = zScore
+	alert.SampleSize = size
+	alert.LastWindow = windowStart
+	alert.Message = describeAnomaly(scope, key, metric, observed, expected, zScore)
+
+	if notify && d.sendNotification(ctx, alert, false) {
+		now := time.Now()
+		alert.NotifiedAt = &now
+	}
+	if err := d.anomalyRepo.SaveAlert(ctx, alert); err != nil {
+		return fmt.Errorf("保存告警失败: %w", err)
+	}
+
+	if channel, country, ok := routerSignalTarget(scope, key); ok {
+		signal := &router.ChannelAnomaly{
+			Channel:    channel,
+			Country:    country,
+			Metric:     metric,
+			Severity:   alert.Severity,
+			Reason:     alert.Message,
+			DetectedAt: alert.CreatedAt,
+			ExpiresAt:  windowStart.Add(time.Hour + d.config.RouterSignalTTL),
+		}
+		if err := router.PublishChannelAnomaly(ctx, d.redisClient, signal); err != nil {
+			logger.Warn("发布渠道异常信号失败", zap.String("scope_key", key), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (d *anomalyDetector) resolveAlert(ctx context.Context, scope, key, metric string, observed float64, windowStart time.Time, result *AnomalyDetectResult) error {
+	alert, err := d.anomalyRepo.GetActiveAlert(ctx, scope, key, metric)
+	if err != nil {
+		return fmt.Errorf("查询告警失败: %w", err)
+	}
+	if alert == nil {
+		return nil
+	}
+
+	now := time.Now()
+	alert.Status = model.AnomalyStatusResolved
+	alert.ResolvedAt = &now
+	alert.Observed = observed
+	alert.LastWindow = windowStart
+	if err := d.anomalyRepo.SaveAlert(ctx, alert); err != nil {
+		return fmt.Errorf("保存告警失败: %w", err)
+	}
+	result.Resolved++
+
+	d.sendNotification(ctx, alert, true)
+
+	if channel, country, ok := routerSignalTarget(scope, key); ok {
+		// 同一渠道可能还有其他指标异常，仅在该渠道没有其他未解决告警时清除信号
+		if !d.hasOtherActiveAlert(ctx, scope, key, metric) {
+			if err := router.ClearChannelAnomaly(ctx, d.redisClient, channel, country); err != nil {
+				logger.Warn("清除渠道异常信号失败", zap.String("scope_key", key), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+func (d *anomalyDetector) hasOtherActiveAlert(ctx context.Context, scope, key, resolvedMetric string) bool {
+	for _, metric := range anomalyMetrics {
+		if metric.name == resolvedMetric {
+			continue
+		}
+		alert, err := d.anomalyRepo.GetActiveAlert(ctx, scope, key, metric.name)
+		if err != nil || alert != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// sendNotification 发送告警通知，返回是否成功
+func (d *anomalyDetector) sendNotification(ctx context.Context, alert *model.AnomalyAlert, resolved bool) bool {
+	if d.notifier == nil {
+		return false
+	}
+
+	req := &client.SendNotificationRequest{
+		Type:    "anomaly_alert",
+		Title:   fmt.Sprintf("[%s] 指标异常: %s %s", strings.ToUpper(alert.Severity), alert.ScopeKey, alert.Metric),
+		Content: alert.Message,
+		Email:   d.config.AlertEmail,
+		Data: map[string]interface{}{
+			"alert_id":   alert.ID.String(),
+			"scope_type": alert.ScopeType,
+			"scope_key":  alert.ScopeKey,
+			"metric":     alert.Metric,
+			"severity":   alert.Severity,
+			"observed":   alert.Observed,
+			"expected":   alert.Expected,
+			"z_score":    alert.ZScore,
+			"window":     alert.LastWindow,
+		},
+		Priority: "medium",
+	}
+	if alert.Severity == model.AnomalySeverityCritical {
+		req.Priority = "high"
+	}
+	if resolved {
+		req.Type = "anomaly_resolved"
+		req.Title = fmt.Sprintf("指标恢复: %s %s", alert.ScopeKey, alert.Metric)
+		req.Content = fmt.Sprintf("%s 的 %s 指标已恢复正常（当前值 %.4f）", alert.ScopeKey, alert.Metric, alert.Observed)
+		req.Priority = "low"
+	}
+	if alert.ScopeType == model.AnomalyScopeMerchant {
+		if merchantID, err := uuid.Parse(alert.ScopeKey); err == nil {
+			req.MerchantID = merchantID
+		}
+	}
+
+	if err := d.notifier.SendAnomalyNotification(ctx, req); err != nil {
+		logger.Warn("发送异常告警通知失败",
+			zap.String("scope_key", alert.ScopeKey),
+			zap.String("metric", alert.Metric),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// ListAlerts 查询告警列表
+func (d *anomalyDetector) ListAlerts(ctx context.Context, query *repository.AnomalyAlertQuery) ([]*model.AnomalyAlert, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	return d.anomalyRepo.ListAlerts(ctx, query)
+}
+
+// AcknowledgeAlert 确认告警（确认后不再重复通知，指标恢复后自动解决）
+func (d *anomalyDetector) AcknowledgeAlert(ctx context.Context, id uuid.UUID, operator string) (*model.AnomalyAlert, error) {
+	alert, err := d.anomalyRepo.GetAlert(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("查询告警失败: %w", err)
+	}
+	if alert == nil {
+		return nil, pkgerrors.NewNotFoundError("告警不存在")
+	}
+	if alert.Status != model.AnomalyStatusOpen {
+		return nil, pkgerrors.NewInvalidRequestError(fmt.Sprintf("告警状态为 %s，无法确认", alert.Status))
+	}
+
+	now := time.Now()
+	alert.Status = model.AnomalyStatusAcknowledged
+	alert.AcknowledgedBy = operator
+	alert.AcknowledgedAt = &now
+	if err := d.anomalyRepo.SaveAlert(ctx, alert); err != nil {
+		return nil, fmt.Errorf("保存告警失败: %w", err)
+	}
+	return alert, nil
+}
+
+// anomalyScopeKey 由汇总维度生成范围键
+func anomalyScopeKey(agg *repository.RollupAggregate, dimensions []string) string {
+	parts := make([]string, 0, len(dimensions))
+	for _, dim := range dimensions {
+		value := agg.Dimension(dim)
+		if value == "" {
+			return ""
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, ":")
+}
+
+// routerSignalTarget 渠道相关范围的告警同步到路由（返回渠道和国家）
+func routerSignalTarget(scope, key string) (string, string, bool) {
+	switch scope {
+	case model.AnomalyScopeChannel:
+		return key, "", true
+	case AnomalyScopeChannelCountry:
+		if channel, country, ok := strings.Cut(key, ":"); ok {
+			return channel, country, true
+		}
+	}
+	return "", "", false
+}
+
+func describeAnomaly(scope, key, metric string, observed, expected, zScore float64) string {
+	return fmt.Sprintf("%s %s 的 %s 指标异常: 当前值 %.4f，基线 %.4f，偏离 %.1f 个标准差",
+		scope, key, metric, observed, expected, zScore)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"payment-platform/analytics-service/internal/model"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+// channelRollupRepo 只返回渠道维度的小时汇总（按窗口起点设置）
+type channelRollupRepo struct {
+	repository.RollupRepository
+	windows map[time.Time][]*repository.RollupAggregate
+}
+
+func (r *channelRollupRepo) QueryRollups(_ context.Context, query *repository.RollupQuery) ([]*repository.RollupAggregate, error) {
+	if len(query.Dimensions) != 1 || query.Dimensions[0] != "channel" {
+		return nil, nil
+	}
+	return r.windows[query.StartTime], nil
+}
+
+// memoryAnomalyRepo 内存基线和告警仓储
+type memoryAnomalyRepo struct {
+	repository.AnomalyRepository
+	baselines map[string]*model.AnomalyBaseline
+	alerts    []*model.AnomalyAlert
+}
+
+func newMemoryAnomalyRepo() *memoryAnomalyRepo {
+	return &memoryAnomalyRepo{baselines: map[string]*model.AnomalyBaseline{}}
+}
+
+func baselineKey(scopeType, scopeKey, metric string, season int) string {
+	return fmt.Sprintf("%s|%s|%s|%d", scopeType, scopeKey, metric, season)
+}
+
+func (r *memoryAnomalyRepo) ListBaselines(_ context.Context, scopeType, metric string, season int) ([]*model.AnomalyBaseline, error) {
+	var baselines []*model.AnomalyBaseline
+	for _, b := range r.baselines {
+		if b.ScopeType == scopeType && b.Metric == metric && b.Season == season {
+			baselines = append(baselines, b)
+		}
+	}
+	return baselines, nil
+}
+
+func (r *memoryAnomalyRepo) SaveBaseline(_ context.Context, baseline *model.AnomalyBaseline) error {
+	r.baselines[baselineKey(baseline.ScopeType, baseline.ScopeKey, baseline.Metric, baseline.Season)] = baseline
+	return nil
+}
+
+func (r *memoryAnomalyRepo) GetActiveAlert(_ context.Context, scopeType, scopeKey, metric string) (*model.AnomalyAlert, error) {
+	for _, a := range r.alerts {
+		if a.ScopeType == scopeType && a.ScopeKey == scopeKey && a.Metric == metric && a.Status != model.AnomalyStatusResolved {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryAnomalyRepo) SaveAlert(_ context.Context, alert *model.AnomalyAlert) error {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+		alert.CreatedAt = time.Now()
+		r.alerts = append(r.alerts, alert)
+	}
+	return nil
+}
+
+func channelWindow(success, failed int64) []*repository.RollupAggregate {
+	return []*repository.RollupAggregate{{
+		Channel:       "stripe",
+		PaymentCount:  success + failed,
+		SuccessCount:  success,
+		FailedCount:   failed,
+		SuccessAmount: success * 1000,
+	}}
+}
+
+func TestUpdateBaselineEWMA(t *testing.T) {
+	now := time.Now()
+	b := &model.AnomalyBaseline{}
+
+	// 样本不足时等价于累计平均和总体方差
+	for _, v := range []float64{10, 20, 30} {
+		updateBaseline(b, v, 0.1, now)
+	}
+	if math.Abs(b.Mean-20) > 1e-9 || math.Abs(b.Variance-200.0/3) > 1e-9 || b.Samples != 3 {
+		t.Fatalf("baseline = %+v, want mean 20, variance 66.67", b)
+	}
+
+	// 样本充足后按 alpha 平滑
+	b = &model.AnomalyBaseline{Mean: 100, Samples: 100}
+	updateBaseline(b, 110, 0.1, now)
+	if math.Abs(b.Mean-101) > 1e-9 || math.Abs(b.Variance-9) > 1e-9 {
+		t.Fatalf("baseline = %+v, want mean 101, variance 9", b)
+	}
+	if b.LastValue != 110 || !b.LastAt.Equal(now) {
+		t.Fatalf("baseline last = %v @ %v", b.LastValue, b.LastAt)
+	}
+}
+
+func TestDetectAuthRateAlertLifecycle(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	rollups := &channelRollupRepo{windows: map[time.Time][]*repository.RollupAggregate{}}
+	repo := newMemoryAnomalyRepo()
+	detector := NewAnomalyDetector(rollups, repo, nil, nil, DefaultAnomalyDetectorConfig())
+
+	// 24 小时稳定在 90% 建立基线（方差为 0，标准差取下限 0.02）
+	window := start
+	for i := 0; i < 24; i++ {
+		rollups.windows[window] = channelWindow(90, 10)
+		if _, err := detector.Detect(ctx, window); err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		window = window.Add(time.Hour)
+	}
+	baseline := repo.baselines[baselineKey(model.AnomalyScopeChannel, "stripe", model.AnomalyMetricAuthRate, -1)]
+	if baseline == nil || baseline.Samples != 24 || math.Abs(baseline.Mean-0.9) > 1e-9 {
+		t.Fatalf("baseline = %+v, want 24 samples around 0.9", baseline)
+	}
+
+	steps := []struct {
+		success, failed int64
+		wantOpened      int
+		wantEscalated   int
+		wantSeverity    string
+		wantStatus      string
+	}{
+		// 降到 83%：z = -3.5，超过告警阈值
+		{83, 17, 1, 0, model.AnomalySeverityWarning, model.AnomalyStatusOpen},
+		// 降到 80%：z = -5，升级为严重
+		{80, 20, 0, 1, model.AnomalySeverityCritical, model.AnomalyStatusOpen},
+		// 持续异常：不重复开启或升级
+		{80, 20, 0, 0, model.AnomalySeverityCritical, model.AnomalyStatusOpen},
+		// 恢复到基线：自动解决
+		{90, 10, 0, 0, model.AnomalySeverityCritical, model.AnomalyStatusResolved},
+	}
+	for i, step := range steps {
+		rollups.windows[window] = channelWindow(step.success, step.failed)
+		result, err := detector.Detect(ctx, window)
+		if err != nil {
+			t.Fatalf("step %d: Detect() error = %v", i, err)
+		}
+		if result.Opened != step.wantOpened || result.Escalated != step.wantEscalated {
+			t.Fatalf("step %d: result = %+v", i, result)
+		}
+		wantResolved := 0
+		if step.wantStatus == model.AnomalyStatusResolved {
+			wantResolved = 1
+		}
+		if result.Resolved != wantResolved {
+			t.Fatalf("step %d: resolved = %d, want %d", i, result.Resolved, wantResolved)
+		}
+		if len(repo.alerts) != 1 {
+			t.Fatalf("step %d: %d alerts, want 1", i, len(repo.alerts))
+		}
+		alert := repo.alerts[0]
+		if alert.Metric != model.AnomalyMetricAuthRate || alert.Severity != step.wantSeverity || alert.Status != step.wantStatus {
+			t.Fatalf("step %d: alert = %+v", i, alert)
+		}
+		if i == 0 && math.Abs(alert.ZScore+3.5) > 1e-6 {
+			t.Fatalf("z-score = %v, want -3.5", alert.ZScore)
+		}
+		window = window.Add(time.Hour)
+	}
+
+	// 异常窗口不计入基线，只有恢复后的窗口更新基线
+	if baseline.Samples != 25 || math.Abs(baseline.Mean-0.9) > 1e-9 {
+		t.Fatalf("baseline = %+v, want 25 samples at 0.9", baseline)
+	}
+
+	// 已处理的窗口重复检测时跳过
+	result, err := detector.Detect(ctx, window.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if result.Evaluated != 0 || baseline.Samples != 25 {
+		t.Fatalf("re-detect result = %+v, samples = %d", result, baseline.Samples)
+	}
+}
+
+func TestDetectVolumeOutageUsesSeasonalBaseline(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rollups := &channelRollupRepo{windows: map[time.Time][]*repository.RollupAggregate{}}
+	repo := newMemoryAnomalyRepo()
+	detector := NewAnomalyDetector(rollups, repo, nil, nil, DefaultAnomalyDetectorConfig())
+
+	// 连续 7 天 10 点的交易量都是 100 笔
+	for day := 0; day < 7; day++ {
+		window := start.AddDate(0, 0, day)
+		rollups.windows[window] = channelWindow(90, 10)
+		if _, err := detector.Detect(ctx, window); err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+	}
+	baseline := repo.baselines[baselineKey(model.AnomalyScopeChannel, "stripe", model.AnomalyMetricVolume, 10)]
+	if baseline == nil || baseline.Samples != 7 || baseline.Mean != 100 {
+		t.Fatalf("volume baseline = %+v, want 7 samples at 100", baseline)
+	}
+
+	// 第 8 天该渠道没有任何交易（汇总中不存在），按 0 笔计算并触发严重告警
+	result, err := detector.Detect(ctx, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if result.Opened != 1 || len(repo.alerts) != 1 {
+		t.Fatalf("result = %+v, alerts = %d", result, len(repo.alerts))
+	}
+	alert := repo.alerts[0]
+	if alert.Metric != model.AnomalyMetricVolume || alert.Severity != model.AnomalySeverityCritical ||
+		alert.ScopeKey != "stripe" || alert.Observed != 0 || alert.Expected != 100 {
+		t.Fatalf("alert = %+v", alert)
+	}
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/model"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+const backfillBatchSize = 1000
+
+// BackfillResult 回填结果
+type BackfillResult struct {
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Created        int       `json:"created"`  // 范围内创建的支付数
+	Payments       int       `json:"payments"` // 范围内进入终态的支付数
+	Refunds        int       `json:"refunds"`
+	Rollups        int       `json:"rollups"`
+	PaymentMetrics int       `json:"payment_metrics"`
+	ChannelMetrics int       `json:"channel_metrics"`
+}
+
+// BackfillService 指标回填服务（从 payment-gateway 数据重建汇总）
+type BackfillService interface {
+	// Rebuild 重建 [start, end) 范围内的多维汇总及旧版日指标（支付指标、渠道指标）
+	Rebuild(ctx context.Context, start, end time.Time) (*BackfillResult, error)
+}
+
+type backfillService struct {
+	sourceRepo   repository.PaymentSourceRepository
+	rollupRepo   repository.RollupRepository
+	tierResolver TierResolver
+}
+
+// NewBackfillService 创建指标回填服务
+func NewBackfillService(sourceRepo repository.PaymentSourceRepository, rollupRepo repository.RollupRepository, tierResolver TierResolver) BackfillService {
+	return &backfillService{
+		sourceRepo:   sourceRepo,
+		rollupRepo:   rollupRepo,
+		tierResolver: tierResolver,
+	}
+}
+
+type rollupKey struct {
+	granularity   string
+	bucketStart   time.Time
+	merchantID    uuid.UUID
+	channel       string
+	paymentMethod string
+	country       string
+	currency      string
+	tier          string
+}
+
+type paymentMetricsKey struct {
+	merchantID uuid.UUID
+	date       time.Time
+	currency   string
+}
+
+type channelMetricsKey struct {
+	channel  string
+	date     time.Time
+	currency string
+}
+
+// channelMetricsAccumulator 渠道日指标及耗时累计（平均耗时最后计算）
+type channelMetricsAccumulator struct {
+	metrics        *model.ChannelMetrics
+	latencyTotalMs int64
+	latencyCount   int64
+}
+
+// Rebuild 重建指标
+//
+// 范围需按UTC整天对齐，否则天粒度汇总只包含部分数据。商户等级取当前绑定的等级。
+// 旧版日指标与实时事件的口径一致：总支付数按创建日统计（对应 payment.created），
+// 成功/失败按进入终态的日期统计，取消不计入；渠道指标只统计成功和失败的交易。
+// 订单维度的商户指标（merchant_metrics）不在回填范围内。
+func (s *backfillService) Rebuild(ctx context.Context, start, end time.Time) (*BackfillResult, error) {
+	start, end = start.UTC(), end.UTC()
+	if !start.Equal(start.Truncate(24*time.Hour)) || !end.Equal(end.Truncate(24*time.Hour)) {
+		return nil, fmt.Errorf("回填范围必须按UTC整天对齐")
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("结束时间必须晚于开始时间")
+	}
+
+	result := &BackfillResult{StartTime: start, EndTime: end}
+	rollups := make(map[rollupKey]*model.PaymentRollup)
+	paymentMetrics := make(map[paymentMetricsKey]*model.PaymentMetrics)
+	channelMetrics := make(map[channelMetricsKey]*channelMetricsAccumulator)
+
+	// 旧版日指标按UTC自然日、原始币种聚合（与实时事件处理一致）
+	paymentMetricsFor := func(merchantID uuid.UUID, currency string, occurredAt time.Time) *model.PaymentMetrics {
+		key := paymentMetricsKey{merchantID: merchantID, date: occurredAt.UTC().Truncate(24 * time.Hour), currency: currency}
+		metrics, ok := paymentMetrics[key]
+		if !ok {
+			metrics = &model.PaymentMetrics{MerchantID: key.merchantID, Date: key.date, Currency: key.currency}
+			paymentMetrics[key] = metrics
+		}
+		return metrics
+	}
+	channelMetricsFor := func(channel, currency string, occurredAt time.Time) *channelMetricsAccumulator {
+		key := channelMetricsKey{channel: channel, date: occurredAt.UTC().Truncate(24 * time.Hour), currency: currency}
+		acc, ok := channelMetrics[key]
+		if !ok {
+			acc = &channelMetricsAccumulator{metrics: &model.ChannelMetrics{ChannelCode: key.channel, Date: key.date, Currency: key.currency}}
+			channelMetrics[key] = acc
+		}
+		return acc
+	}
+
+	add := func(merchantID uuid.UUID, channel, payMethod, country, currency string, occurredAt time.Time, fill func(*model.PaymentRollup)) {
+		tier := ""
+		if s.tierResolver != nil {
+			tier = s.tierResolver.Resolve(ctx, merchantID)
+		}
+		occurredAt = occurredAt.UTC()
+		for _, g := range []struct {
+			granularity string
+			bucket      time.Duration
+		}{
+			{model.GranularityHour, time.Hour},
+			{model.GranularityDay, 24 * time.Hour},
+		} {
+			key := rollupKey{
+				granularity:   g.granularity,
+				bucketStart:   occurredAt.Truncate(g.bucket),
+				merchantID:    merchantID,
+				channel:       channel,
+				paymentMethod: payMethod,
+				country:       strings.ToUpper(country),
+				currency:      strings.ToUpper(currency),
+				tier:          tier,
+			}
+			rollup, ok := rollups[key]
+			if !ok {
+				rollup = &model.PaymentRollup{
+					Granularity:   key.granularity,
+					BucketStart:   key.bucketStart,
+					MerchantID:    key.merchantID,
+					Channel:       key.channel,
+					PaymentMethod: key.paymentMethod,
+					Country:       key.country,
+					Currency:      key.currency,
+					MerchantTier:  key.tier,
+				}
+				rollups[key] = rollup
+			}
+			fill(rollup)
+		}
+	}
+
+	err := s.sourceRepo.ScanCreatedPayments(ctx, start, end, backfillBatchSize, func(batch []*model.SourcePayment) error {
+		for _, payment := range batch {
+			paymentMetricsFor(payment.MerchantID, payment.Currency, payment.OccurredAt).TotalPayments++
+		}
+		result.Created += len(batch)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取支付数据失败: %w", err)
+	}
+
+	err = s.sourceRepo.ScanTerminalPayments(ctx, start, end, backfillBatchSize, func(batch []*model.SourcePayment) error {
+		for _, p := range batch {
+			payment := p
+			add(payment.MerchantID, payment.Channel, payment.PayMethod, payment.Country, payment.Currency, payment.OccurredAt, func(r *model.PaymentRollup) {
+				r.PaymentCount++
+				if payment.LatencyMs > 0 {
+					r.LatencyTotalMs += payment.LatencyMs
+					r.LatencyCount++
+				}
+				switch payment.Status {
+				case "success":
+					r.SuccessCount++
+					r.SuccessAmount += payment.Amount
+				case "failed":
+					r.FailedCount++
+				case "cancelled":
+					r.CancelledCount++
+				}
+			})
+
+			// 旧版日指标不统计取消
+			if payment.Status != "success" && payment.Status != "failed" {
+				continue
+			}
+			metrics := paymentMetricsFor(payment.MerchantID, payment.Currency, payment.OccurredAt)
+			channel := channelMetricsFor(payment.Channel, payment.Currency, payment.OccurredAt)
+			channel.metrics.TotalTransactions++
+			if payment.LatencyMs > 0 {
+				channel.latencyTotalMs += payment.LatencyMs
+				channel.latencyCount++
+			}
+			if payment.Status == "success" {
+				metrics.SuccessPayments++
+				metrics.SuccessAmount += payment.Amount
+				metrics.TotalAmount += payment.Amount
+				channel.metrics.SuccessTransactions++
+				channel.metrics.SuccessAmount += payment.Amount
+				channel.metrics.TotalAmount += payment.Amount
+			} else {
+				metrics.FailedPayments++
+				channel.metrics.FailedTransactions++
+			}
+		}
+		result.Payments += len(batch)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取支付数据失败: %w", err)
+	}
+
+	err = s.sourceRepo.ScanSuccessRefunds(ctx, start, end, backfillBatchSize, func(batch []*model.SourceRefund) error {
+		for _, r := range batch {
+			refund := r
+			add(refund.MerchantID, refund.Channel, refund.PayMethod, refund.Country, refund.Currency, refund.OccurredAt, func(r *model.PaymentRollup) {
+				r.RefundCount++
+				r.RefundAmount += refund.Amount
+			})
+
+			metrics := paymentMetricsFor(refund.MerchantID, refund.Currency, refund.OccurredAt)
+			metrics.TotalRefunds++
+			metrics.TotalRefundAmount += refund.Amount
+		}
+		result.Refunds += len(batch)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取退款数据失败: %w", err)
+	}
+
+	list := make([]*model.PaymentRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		list = append(list, rollup)
+	}
+
+	paymentList := make([]*model.PaymentMetrics, 0, len(paymentMetrics))
+	for _, metrics := range paymentMetrics {
+		if metrics.TotalPayments > 0 {
+			metrics.SuccessRate = float64(metrics.SuccessPayments) / float64(metrics.TotalPayments) * 100
+		}
+		if metrics.SuccessPayments > 0 {
+			metrics.AverageAmount = metrics.SuccessAmount / int64(metrics.SuccessPayments)
+		}
+		paymentList = append(paymentList, metrics)
+	}
+
+	channelList := make([]*model.ChannelMetrics, 0, len(channelMetrics))
+	for _, acc := range channelMetrics {
+		metrics := acc.metrics
+		if metrics.TotalTransactions > 0 {
+			metrics.SuccessRate = float64(metrics.SuccessTransactions) / float64(metrics.TotalTransactions) * 100
+		}
+		if acc.latencyCount > 0 {
+			metrics.AverageLatency = int(acc.latencyTotalMs / acc.latencyCount)
+		}
+		channelList = append(channelList, metrics)
+	}
+
+	if err := s.rollupRepo.ReplaceRange(ctx, start, end, list, paymentList, channelList); err != nil {
+		return nil, fmt.Errorf("写入汇总数据失败: %w", err)
+	}
+	result.Rollups = len(list)
+	result.PaymentMetrics = len(paymentList)
+	result.ChannelMetrics = len(channelList)
+
+	logger.Info("Analytics: 指标回填完成",
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.Int("created", result.Created),
+		zap.Int("payments", result.Payments),
+		zap.Int("refunds", result.Refunds),
+		zap.Int("rollups", result.Rollups),
+		zap.Int("payment_metrics", result.PaymentMetrics),
+		zap.Int("channel_metrics", result.ChannelMetrics))
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/model"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+func init() {
+	logger.Log = zap.NewNop()
+}
+
+// fakePaymentSource 内存支付数据源
+type fakePaymentSource struct {
+	created  []*model.SourcePayment
+	terminal []*model.SourcePayment
+	refunds  []*model.SourceRefund
+}
+
+func (f *fakePaymentSource) ScanCreatedPayments(_ context.Context, _, _ time.Time, _ int, fn func([]*model.SourcePayment) error) error {
+	return fn(f.created)
+}
+
+func (f *fakePaymentSource) ScanTerminalPayments(_ context.Context, _, _ time.Time, _ int, fn func([]*model.SourcePayment) error) error {
+	return fn(f.terminal)
+}
+
+func (f *fakePaymentSource) ScanSuccessRefunds(_ context.Context, _, _ time.Time, _ int, fn func([]*model.SourceRefund) error) error {
+	return fn(f.refunds)
+}
+
+// captureRollupRepo 记录回填写入的数据
+type captureRollupRepo struct {
+	repository.RollupRepository
+	rollups        []*model.PaymentRollup
+	paymentMetrics []*model.PaymentMetrics
+	channelMetrics []*model.ChannelMetrics
+}
+
+func (r *captureRollupRepo) ReplaceRange(_ context.Context, _, _ time.Time, rollups []*model.PaymentRollup, paymentMetrics []*model.PaymentMetrics, channelMetrics []*model.ChannelMetrics) error {
+	r.rollups, r.paymentMetrics, r.channelMetrics = rollups, paymentMetrics, channelMetrics
+	return nil
+}
+
+func TestBackfillRebuildMatchesLiveMetrics(t *testing.T) {
+	merchantID := uuid.New()
+	day1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	payment := func(status string, amount, latency int64, at time.Time) *model.SourcePayment {
+		return &model.SourcePayment{
+			ID: uuid.New(), MerchantID: merchantID, Channel: "stripe", PayMethod: "card", Country: "us",
+			Currency: "USD", Amount: amount, Status: status, LatencyMs: latency, OccurredAt: at,
+		}
+	}
+	source := &fakePaymentSource{
+		// 第一天创建 4 笔（含一笔仍在处理中的）
+		created: []*model.SourcePayment{
+			payment("success", 1000, 0, day1.Add(time.Hour)),
+			payment("failed", 500, 0, day1.Add(2*time.Hour)),
+			payment("cancelled", 700, 0, day1.Add(3*time.Hour)),
+			payment("processing", 900, 0, day1.Add(4*time.Hour)),
+		},
+		terminal: []*model.SourcePayment{
+			payment("success", 1000, 300, day1.Add(time.Hour)),
+			payment("failed", 500, 100, day1.Add(2*time.Hour)),
+			payment("cancelled", 700, 0, day1.Add(3*time.Hour)),
+		},
+		refunds: []*model.SourceRefund{
+			{ID: uuid.New(), MerchantID: merchantID, Channel: "stripe", PayMethod: "card", Country: "us", Currency: "USD", Amount: 400, OccurredAt: day2.Add(time.Hour)},
+		},
+	}
+	repo := &captureRollupRepo{}
+	svc := NewBackfillService(source, repo, nil)
+
+	result, err := svc.Rebuild(context.Background(), day1, day2.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	if result.Created != 4 || result.Payments != 3 || result.Refunds != 1 {
+		t.Fatalf("result = %+v, want created 4, payments 3, refunds 1", result)
+	}
+
+	// 支付日指标：总数按创建统计，成功/失败按终态统计，退款计入退款当天
+	metricsByDate := map[time.Time]*model.PaymentMetrics{}
+	for _, m := range repo.paymentMetrics {
+		metricsByDate[m.Date] = m
+	}
+	first := metricsByDate[day1]
+	if first == nil || first.TotalPayments != 4 || first.SuccessPayments != 1 || first.FailedPayments != 1 ||
+		first.SuccessAmount != 1000 || first.TotalAmount != 1000 || first.AverageAmount != 1000 || first.SuccessRate != 25 {
+		t.Fatalf("day1 payment metrics = %+v", first)
+	}
+	second := metricsByDate[day2]
+	if second == nil || second.TotalRefunds != 1 || second.TotalRefundAmount != 400 || second.TotalPayments != 0 {
+		t.Fatalf("day2 payment metrics = %+v", second)
+	}
+
+	// 渠道日指标：只统计成功和失败，取消不计入
+	if len(repo.channelMetrics) != 1 {
+		t.Fatalf("channel metrics = %d rows, want 1", len(repo.channelMetrics))
+	}
+	channel := repo.channelMetrics[0]
+	if channel.ChannelCode != "stripe" || !channel.Date.Equal(day1) || channel.TotalTransactions != 2 ||
+		channel.SuccessTransactions != 1 || channel.FailedTransactions != 1 || channel.SuccessAmount != 1000 ||
+		channel.SuccessRate != 50 || channel.AverageLatency != 200 {
+		t.Fatalf("channel metrics = %+v", channel)
+	}
+
+	// 多维汇总的终态支付数包含取消
+	for _, r := range repo.rollups {
+		if r.Granularity == model.GranularityDay && r.BucketStart.Equal(day1) {
+			if r.PaymentCount != 3 || r.CancelledCount != 1 || r.Country != "US" {
+				t.Fatalf("day1 rollup = %+v", r)
+			}
+			return
+		}
+	}
+	t.Fatal("day1 rollup not found")
+}
+
+func TestBackfillRebuildRequiresWholeDays(t *testing.T) {
+	svc := NewBackfillService(&fakePaymentSource{}, &captureRollupRepo{}, nil)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := svc.Rebuild(context.Background(), start.Add(time.Hour), start.AddDate(0, 0, 1)); err == nil {
+		t.Fatal("expected error for unaligned start")
+	}
+	if _, err := svc.Rebuild(context.Background(), start, start); err == nil {
+		t.Fatal("expected error for empty range")
+	}
+}
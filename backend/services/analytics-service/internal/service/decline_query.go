@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/payment-platform/pkg/decline"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+// maxDeclineQueryRange 拒绝原因查询的最大时间范围
+const maxDeclineQueryRange = 366 * 24 * time.Hour
+
+// DeclineQuery 拒绝原因分析查询
+type DeclineQuery struct {
+	Dimensions  []string          `json:"dimensions"`  // 分组维度（默认按 category）
+	Granularity string            `json:"granularity"` // day, week, month, total
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time"` // 不含
+	Filters     map[string]string `json:"filters"`  // 维度过滤（商户查询时必须包含 merchant_id）
+	Limit       int               `json:"limit"`
+}
+
+// DeclineRow 拒绝原因查询结果行
+type DeclineRow struct {
+	Bucket         *time.Time        `json:"bucket,omitempty"`
+	Dimensions     map[string]string `json:"dimensions,omitempty"`
+	DeclineCount   int64             `json:"decline_count"`
+	DeclineAmount  int64             `json:"decline_amount"`
+	RetryableCount int64             `json:"retryable_count"`
+	Share          float64           `json:"share"` // 占同一时间桶内拒绝总数的百分比
+}
+
+// DeclineResult 拒绝原因查询结果
+type DeclineResult struct {
+	Granularity  string        `json:"granularity"`
+	Dimensions   []string      `json:"dimensions"`
+	DeclineCount int64         `json:"decline_count"` // 查询范围内拒绝总数
+	Rows         []*DeclineRow `json:"rows"`
+}
+
+// QueryDeclines 按维度 × 粒度查询拒绝原因分布
+func (s *analyticsService) QueryDeclines(ctx context.Context, query *DeclineQuery) (*DeclineResult, error) {
+	if err := normalizeDeclineQuery(query); err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.rollupRepo.QueryDeclines(ctx, &repository.RollupQuery{
+		Granularity: query.Granularity,
+		Dimensions:  query.Dimensions,
+		Filters:     query.Filters,
+		StartTime:   query.StartTime,
+		EndTime:     query.EndTime,
+		Limit:       query.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询拒绝原因汇总失败: %w", err)
+	}
+
+	result := &DeclineResult{
+		Granularity: query.Granularity,
+		Dimensions:  query.Dimensions,
+		Rows:        make([]*DeclineRow, 0, len(aggregates)),
+	}
+	bucketTotals := make(map[time.Time]int64)
+	for _, agg := range aggregates {
+		result.DeclineCount += agg.DeclineCount
+		if agg.Bucket != nil {
+			bucketTotals[*agg.Bucket] += agg.DeclineCount
+		}
+	}
+	for _, agg := range aggregates {
+		row := &DeclineRow{
+			Bucket:         agg.Bucket,
+			DeclineCount:   agg.DeclineCount,
+			DeclineAmount:  agg.DeclineAmount,
+			RetryableCount: agg.RetryableCount,
+		}
+		if len(query.Dimensions) > 0 {
+			row.Dimensions = make(map[string]string, len(query.Dimensions))
+			for _, dim := range query.Dimensions {
+				row.Dimensions[dim] = agg.Dimension(dim)
+			}
+		}
+		total := result.DeclineCount
+		if agg.Bucket != nil {
+			total = bucketTotals[*agg.Bucket]
+		}
+		if total > 0 {
+			row.Share = float64(agg.DeclineCount) / float64(total) * 100
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// normalizeDeclineQuery 校验并补全查询参数
+func normalizeDeclineQuery(query *DeclineQuery) error {
+	if len(query.Dimensions) == 0 {
+		query.Dimensions = []string{"category"}
+	}
+	seen := make(map[string]bool, len(query.Dimensions))
+	dims := make([]string, 0, len(query.Dimensions))
+	for _, dim := range query.Dimensions {
+		if _, ok := repository.DeclineDimensionColumns[dim]; !ok {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的维度: %s", dim))
+		}
+		if !seen[dim] {
+			seen[dim] = true
+			dims = append(dims, dim)
+		}
+	}
+	query.Dimensions = dims
+
+	for dim, value := range query.Filters {
+		if _, ok := repository.DeclineDimensionColumns[dim]; !ok {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的过滤维度: %s", dim))
+		}
+		switch dim {
+		case "country", "currency":
+			query.Filters[dim] = strings.ToUpper(value)
+		case "category":
+			if !decline.IsValidCategory(value) {
+				return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的拒绝原因分类: %s", value))
+			}
+		case "decline_type":
+			if value != string(decline.TypeHard) && value != string(decline.TypeSoft) {
+				return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的拒绝类型: %s", value))
+			}
+		}
+	}
+
+	if query.Granularity == "" {
+		query.Granularity = "total"
+	}
+	switch query.Granularity {
+	case "day", "week", "month", "total":
+	default:
+		return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的粒度: %s", query.Granularity))
+	}
+
+	if query.StartTime.IsZero() || query.EndTime.IsZero() {
+		return pkgerrors.NewInvalidRequestError("start_time 和 end_time 不能为空")
+	}
+	if !query.EndTime.After(query.StartTime) {
+		return pkgerrors.NewInvalidRequestError("end_time 必须晚于 start_time")
+	}
+	if query.EndTime.Sub(query.StartTime) > maxDeclineQueryRange {
+		return pkgerrors.NewInvalidRequestError("查询范围不能超过366天")
+	}
+	// 拒绝原因按天汇总，时间范围按UTC自然日对齐
+	query.StartTime = query.StartTime.UTC().Truncate(24 * time.Hour)
+	if end := query.EndTime.UTC().Truncate(24 * time.Hour); end.Before(query.EndTime) {
+		query.EndTime = end.Add(24 * time.Hour)
+	} else {
+		query.EndTime = end
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultMetricsQueryLimit
+	}
+	if query.Limit > maxMetricsQueryLimit {
+		query.Limit = maxMetricsQueryLimit
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+// 指标查询限制
+const (
+	defaultMetricsQueryLimit = 1000
+	maxMetricsQueryLimit     = 10000
+	maxHourlyQueryRange      = 31 * 24 * time.Hour
+)
+
+// metricEvaluators 支持的指标及其计算方式（金额单位：分）
+var metricEvaluators = map[string]func(a *repository.RollupAggregate) float64{
+	"payment_count":   func(a *repository.RollupAggregate) float64 { return float64(a.PaymentCount) },
+	"success_count":   func(a *repository.RollupAggregate) float64 { return float64(a.SuccessCount) },
+	"failed_count":    func(a *repository.RollupAggregate) float64 { return float64(a.FailedCount) },
+	"cancelled_count": func(a *repository.RollupAggregate) float64 { return float64(a.CancelledCount) },
+	"success_amount":  func(a *repository.RollupAggregate) float64 { return float64(a.SuccessAmount) },
+	"refund_count":    func(a *repository.RollupAggregate) float64 { return float64(a.RefundCount) },
+	"refund_amount":   func(a *repository.RollupAggregate) float64 { return float64(a.RefundAmount) },
+	"net_amount":      func(a *repository.RollupAggregate) float64 { return float64(a.SuccessAmount - a.RefundAmount) },
+	"refund_rate": func(a *repository.RollupAggregate) float64 {
+		if a.SuccessCount == 0 {
+			return 0
+		}
+		return float64(a.RefundCount) / float64(a.SuccessCount) * 100
+	},
+	"avg_latency_ms": func(a *repository.RollupAggregate) float64 {
+		if a.LatencyCount == 0 {
+			return 0
+		}
+		return float64(a.LatencyTotalMs) / float64(a.LatencyCount)
+	},
+	"success_rate": func(a *repository.RollupAggregate) float64 {
+		if a.PaymentCount == 0 {
+			return 0
+		}
+		return float64(a.SuccessCount) / float64(a.PaymentCount) * 100
+	},
+	"average_amount": func(a *repository.RollupAggregate) float64 {
+		if a.SuccessCount == 0 {
+			return 0
+		}
+		return float64(a.SuccessAmount) / float64(a.SuccessCount)
+	},
+}
+
+// defaultMetrics 未指定指标时返回的指标
+var defaultMetrics = []string{"payment_count", "success_count", "success_amount", "success_rate"}
+
+// MetricsQuery 多维指标查询
+type MetricsQuery struct {
+	Metrics     []string          `json:"metrics"`     // 指标列表
+	Dimensions  []string          `json:"dimensions"`  // 分组维度
+	Granularity string            `json:"granularity"` // hour, day, week, month, total
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time"` // 不含
+	Filters     map[string]string `json:"filters"`  // 维度过滤（商户查询时必须包含 merchant_id）
+	Limit       int               `json:"limit"`
+}
+
+// MetricsRow 查询结果行
+type MetricsRow struct {
+	Bucket     *time.Time         `json:"bucket,omitempty"`
+	Dimensions map[string]string  `json:"dimensions,omitempty"`
+	Values     map[string]float64 `json:"values"`
+}
+
+// MetricsResult 查询结果
+type MetricsResult struct {
+	Granularity string        `json:"granularity"`
+	Metrics     []string      `json:"metrics"`
+	Dimensions  []string      `json:"dimensions"`
+	Rows        []*MetricsRow `json:"rows"`
+}
+
+// QueryMetrics 按指标 × 维度 × 粒度查询汇总数据
+func (s *analyticsService) QueryMetrics(ctx context.Context, query *MetricsQuery) (*MetricsResult, error) {
+	if err := normalizeMetricsQuery(query); err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.rollupRepo.QueryRollups(ctx, &repository.RollupQuery{
+		Granularity: query.Granularity,
+		Dimensions:  query.Dimensions,
+		Filters:     query.Filters,
+		StartTime:   query.StartTime,
+		EndTime:     query.EndTime,
+		Limit:       query.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询汇总指标失败: %w", err)
+	}
+
+	rows := make([]*MetricsRow, 0, len(aggregates))
+	for _, agg := range aggregates {
+		row := &MetricsRow{
+			Bucket: agg.Bucket,
+			Values: make(map[string]float64, len(query.Metrics)),
+		}
+		if len(query.Dimensions) > 0 {
+			row.Dimensions = make(map[string]string, len(query.Dimensions))
+			for _, dim := range query.Dimensions {
+				row.Dimensions[dim] = agg.Dimension(dim)
+			}
+		}
+		for _, metric := range query.Metrics {
+			row.Values[metric] = metricEvaluators[metric](agg)
+		}
+		rows = append(rows, row)
+	}
+
+	return &MetricsResult{
+		Granularity: query.Granularity,
+		Metrics:     query.Metrics,
+		Dimensions:  query.Dimensions,
+		Rows:        rows,
+	}, nil
+}
+
+// normalizeMetricsQuery 校验并补全查询参数
+func normalizeMetricsQuery(query *MetricsQuery) error {
+	if len(query.Metrics) == 0 {
+		query.Metrics = defaultMetrics
+	}
+	for _, metric := range query.Metrics {
+		if _, ok := metricEvaluators[metric]; !ok {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的指标: %s", metric))
+		}
+	}
+
+	seen := make(map[string]bool, len(query.Dimensions))
+	dims := make([]string, 0, len(query.Dimensions))
+	for _, dim := range query.Dimensions {
+		if _, ok := repository.RollupDimensionColumns[dim]; !ok {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的维度: %s", dim))
+		}
+		if !seen[dim] {
+			seen[dim] = true
+			dims = append(dims, dim)
+		}
+	}
+	query.Dimensions = dims
+
+	for dim, value := range query.Filters {
+		if _, ok := repository.RollupDimensionColumns[dim]; !ok {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的过滤维度: %s", dim))
+		}
+		if dim == "country" || dim == "currency" {
+			query.Filters[dim] = strings.ToUpper(value)
+		}
+	}
+
+	if query.Granularity == "" {
+		query.Granularity = "day"
+	}
+	switch query.Granularity {
+	case "hour", "day", "week", "month", "total":
+	default:
+		return pkgerrors.NewInvalidRequestError(fmt.Sprintf("不支持的粒度: %s", query.Granularity))
+	}
+
+	if query.StartTime.IsZero() || query.EndTime.IsZero() {
+		return pkgerrors.NewInvalidRequestError("start_time 和 end_time 不能为空")
+	}
+	if !query.EndTime.After(query.StartTime) {
+		return pkgerrors.NewInvalidRequestError("end_time 必须晚于 start_time")
+	}
+	if query.Granularity == "hour" && query.EndTime.Sub(query.StartTime) > maxHourlyQueryRange {
+		return pkgerrors.NewInvalidRequestError("小时粒度查询范围不能超过31天")
+	}
+	if query.Granularity != "hour" {
+		// 天及以上粒度基于天汇总，时间范围按UTC自然日对齐
+		query.StartTime = query.StartTime.UTC().Truncate(24 * time.Hour)
+		if end := query.EndTime.UTC().Truncate(24 * time.Hour); end.Before(query.EndTime) {
+			query.EndTime = end.Add(24 * time.Hour)
+		} else {
+			query.EndTime = end
+		}
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultMetricsQueryLimit
+	}
+	if query.Limit > maxMetricsQueryLimit {
+		query.Limit = maxMetricsQueryLimit
+	}
+	return nil
+}
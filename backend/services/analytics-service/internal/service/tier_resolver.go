@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/client"
+)
+
+// TierResolver 商户等级解析器（带本地缓存，策略服务不可用时降级为空等级）
+type TierResolver interface {
+	Resolve(ctx context.Context, merchantID uuid.UUID) string
+}
+
+type tierCacheEntry struct {
+	tierCode  string
+	expiresAt time.Time
+}
+
+type cachedTierResolver struct {
+	policyClient client.MerchantPolicyClient
+	ttl          time.Duration
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]tierCacheEntry
+}
+
+// NewTierResolver 创建商户等级解析器，policyClient 为 nil 时总是返回空等级
+func NewTierResolver(policyClient client.MerchantPolicyClient, ttl time.Duration) TierResolver {
+	return &cachedTierResolver{
+		policyClient: policyClient,
+		ttl:          ttl,
+		cache:        make(map[uuid.UUID]tierCacheEntry),
+	}
+}
+
+// Resolve 获取商户等级代码
+func (r *cachedTierResolver) Resolve(ctx context.Context, merchantID uuid.UUID) string {
+	if r.policyClient == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	entry, ok := r.cache[merchantID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.tierCode
+	}
+
+	tierCode, err := r.policyClient.GetMerchantTierCode(ctx, merchantID)
+	if err != nil {
+		logger.Warn("Analytics: 获取商户等级失败，使用缓存或空等级",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err))
+		// 过期缓存仍优于空值
+		if ok {
+			return entry.tierCode
+		}
+		return ""
+	}
+
+	r.mu.Lock()
+	r.cache[merchantID] = tierCacheEntry{tierCode: tierCode, expiresAt: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return tierCode
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/payment-platform/pkg/db"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"payment-platform/analytics-service/internal/service"
+)
+
+// AnomalyWorker 异常检测定时任务（每小时检测上一个完整小时）
+type AnomalyWorker struct {
+	detector    service.AnomalyDetector
+	redisClient *redis.Client
+	delay       time.Duration // 整点后延迟执行，等待迟到事件写入汇总
+}
+
+// NewAnomalyWorker 创建异常检测Worker
+func NewAnomalyWorker(detector service.AnomalyDetector, redisClient *redis.Client, delay time.Duration) *AnomalyWorker {
+	return &AnomalyWorker{
+		detector:    detector,
+		redisClient: redisClient,
+		delay:       delay,
+	}
+}
+
+// Start 启动异常检测
+func (w *AnomalyWorker) Start(ctx context.Context) {
+	logger.Info("Analytics: 异常检测Worker启动", zap.Duration("delay", w.delay))
+
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(time.Hour).Add(w.delay)
+		if !next.After(now) {
+			next = next.Add(time.Hour)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			w.runOnce(ctx, next.Truncate(time.Hour).Add(-time.Hour))
+		}
+	}
+}
+
+func (w *AnomalyWorker) runOnce(ctx context.Context, windowStart time.Time) {
+	// 多实例部署时仅由一个实例执行（锁不主动释放，到期自动失效）
+	if w.redisClient != nil {
+		lock := db.NewDistributedLock(w.redisClient, fmt.Sprintf("analytics:anomaly:%d", windowStart.Unix()), 30*time.Minute)
+		acquired, err := lock.Acquire(ctx)
+		if err != nil {
+			logger.Error("Analytics: 获取异常检测锁失败", zap.Error(err))
+			return
+		}
+		if !acquired {
+			return
+		}
+	}
+
+	if _, err := w.detector.Detect(ctx, windowStart); err != nil {
+		logger.Error("Analytics: 异常检测失败", zap.Time("window_start", windowStart), zap.Error(err))
+	}
+}
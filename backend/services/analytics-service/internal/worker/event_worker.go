@@ -2,7 +2,10 @@ package worker
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,21 +14,55 @@ import (
 	"github.com/payment-platform/pkg/logger"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"payment-platform/analytics-service/internal/model"
 	"payment-platform/analytics-service/internal/repository"
+	"payment-platform/analytics-service/internal/service"
 )
 
+// processedEventRetention 已处理事件记录保留时长（需大于Kafka消息的最长重投窗口）
+const processedEventRetention = 7 * 24 * time.Hour
+
 // EventWorker 分析服务事件处理worker (消费所有业务事件进行统计分析)
+//
+// 每个事件在同一事务中先登记事件ID再更新指标，Kafka 重复投递的事件会被跳过，
+// 保证指标只累计一次。
 type EventWorker struct {
-	db   *gorm.DB
-	repo repository.AnalyticsRepository
+	db           *gorm.DB
+	repo         repository.AnalyticsRepository
+	rollupRepo   repository.RollupRepository
+	tierResolver service.TierResolver
 }
 
 // NewEventWorker 创建事件worker
-func NewEventWorker(db *gorm.DB, repo repository.AnalyticsRepository) *EventWorker {
+func NewEventWorker(db *gorm.DB, repo repository.AnalyticsRepository, rollupRepo repository.RollupRepository, tierResolver service.TierResolver) *EventWorker {
 	return &EventWorker{
-		db:   db,
-		repo: repo,
+		db:           db,
+		repo:         repo,
+		rollupRepo:   rollupRepo,
+		tierResolver: tierResolver,
+	}
+}
+
+// StartCleanupWorker 定期清理过期的已处理事件记录
+func (w *EventWorker) StartCleanupWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := w.rollupRepo.PurgeProcessedEvents(ctx, time.Now().Add(-processedEventRetention))
+			if err != nil {
+				logger.Error("Analytics: 清理已处理事件记录失败", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.Info("Analytics: 已清理过期事件记录", zap.Int64("count", deleted))
+			}
+		}
 	}
 }
 
@@ -49,15 +86,15 @@ func (w *EventWorker) StartPaymentEventWorker(ctx context.Context, consumer *kaf
 		// 根据事件类型路由处理
 		switch baseEvent.EventType {
 		case events.PaymentCreated:
-			return w.handlePaymentCreated(ctx, message)
+			return w.handlePaymentCreated(ctx, &baseEvent, message)
 		case events.PaymentSuccess:
-			return w.handlePaymentSuccess(ctx, message)
+			return w.handlePaymentSuccess(ctx, &baseEvent, message)
 		case events.PaymentFailed:
-			return w.handlePaymentFailed(ctx, message)
+			return w.handlePaymentFailed(ctx, &baseEvent, message)
 		case events.PaymentCancelled:
-			return w.handlePaymentCancelled(ctx, message)
+			return w.handlePaymentCancelled(ctx, &baseEvent, message)
 		case events.RefundSuccess:
-			return w.handleRefundSuccess(ctx, message)
+			return w.handleRefundSuccess(ctx, &baseEvent, message)
 		default:
 			logger.Info("Analytics: 未处理的支付事件类型", zap.String("event_type", baseEvent.EventType))
 			return nil
@@ -90,11 +127,11 @@ func (w *EventWorker) StartOrderEventWorker(ctx context.Context, consumer *kafka
 		// 根据事件类型路由处理
 		switch baseEvent.EventType {
 		case events.OrderCreated:
-			return w.handleOrderCreated(ctx, message)
+			return w.handleOrderCreated(ctx, &baseEvent, message)
 		case events.OrderPaid:
-			return w.handleOrderPaid(ctx, message)
+			return w.handleOrderPaid(ctx, &baseEvent, message)
 		case events.OrderCancelled:
-			return w.handleOrderCancelled(ctx, message)
+			return w.handleOrderCancelled(ctx, &baseEvent, message)
 		case events.OrderCompleted:
 			return w.handleOrderCompleted(ctx, message)
 		default:
@@ -112,7 +149,7 @@ func (w *EventWorker) StartOrderEventWorker(ctx context.Context, consumer *kafka
 // ========== 支付事件处理器 ==========
 
 // handlePaymentCreated 处理支付创建事件
-func (w *EventWorker) handlePaymentCreated(ctx context.Context, message []byte) error {
+func (w *EventWorker) handlePaymentCreated(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.PaymentEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -124,16 +161,18 @@ func (w *EventWorker) handlePaymentCreated(ctx context.Context, message []byte)
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
+	date := eventTime(base).Truncate(24 * time.Hour)
 
-	// 更新支付指标 (增加总支付数)
-	return w.updatePaymentMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
-		metrics.TotalPayments++
+	return w.processOnce(ctx, topicPaymentEvents, base, message, func(tx *gorm.DB) error {
+		// 更新支付指标 (增加总支付数)
+		return w.updatePaymentMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
+			metrics.TotalPayments++
+		})
 	})
 }
 
 // handlePaymentSuccess 处理支付成功事件
-func (w *EventWorker) handlePaymentSuccess(ctx context.Context, message []byte) error {
+func (w *EventWorker) handlePaymentSuccess(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.PaymentEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -145,44 +184,56 @@ func (w *EventWorker) handlePaymentSuccess(ctx context.Context, message []byte)
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
-
-	// 更新支付指标
-	err = w.updatePaymentMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
-		metrics.SuccessPayments++
-		metrics.SuccessAmount += event.Payload.Amount
-		metrics.TotalAmount += event.Payload.Amount
+	occurredAt := eventTime(base)
+	date := occurredAt.Truncate(24 * time.Hour)
+	rollups := w.buildPaymentRollups(ctx, merchantID, &event.Payload, occurredAt, func(r *model.PaymentRollup) {
+		r.PaymentCount = 1
+		r.SuccessCount = 1
+		r.SuccessAmount = event.Payload.Amount
+		setRollupLatency(r, event.Payload.LatencyMs)
+	})
 
-		// 计算成功率
-		if metrics.TotalPayments > 0 {
-			metrics.SuccessRate = float64(metrics.SuccessPayments) / float64(metrics.TotalPayments) * 100
+	return w.processOnce(ctx, topicPaymentEvents, base, message, func(tx *gorm.DB) error {
+		if err := w.rollupRepo.UpsertRollups(tx, rollups); err != nil {
+			return err
 		}
 
-		// 计算平均金额
-		if metrics.SuccessPayments > 0 {
-			metrics.AverageAmount = metrics.SuccessAmount / int64(metrics.SuccessPayments)
-		}
-	})
+		// 更新支付指标
+		err := w.updatePaymentMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
+			metrics.SuccessPayments++
+			metrics.SuccessAmount += event.Payload.Amount
+			metrics.TotalAmount += event.Payload.Amount
 
-	if err != nil {
-		return err
-	}
-
-	// 更新渠道指标
-	return w.updateChannelMetrics(ctx, event.Payload.Channel, date, event.Payload.Currency, func(metrics *model.ChannelMetrics) {
-		metrics.SuccessTransactions++
-		metrics.SuccessAmount += event.Payload.Amount
-		metrics.TotalAmount += event.Payload.Amount
+			// 计算成功率
+			if metrics.TotalPayments > 0 {
+				metrics.SuccessRate = float64(metrics.SuccessPayments) / float64(metrics.TotalPayments) * 100
+			}
 
-		// 计算成功率
-		if metrics.TotalTransactions > 0 {
-			metrics.SuccessRate = float64(metrics.SuccessTransactions) / float64(metrics.TotalTransactions) * 100
+			// 计算平均金额
+			if metrics.SuccessPayments > 0 {
+				metrics.AverageAmount = metrics.SuccessAmount / int64(metrics.SuccessPayments)
+			}
+		})
+		if err != nil {
+			return err
 		}
+
+		// 更新渠道指标
+		return w.updateChannelMetrics(tx, event.Payload.Channel, date, event.Payload.Currency, func(metrics *model.ChannelMetrics) {
+			metrics.SuccessTransactions++
+			metrics.SuccessAmount += event.Payload.Amount
+			metrics.TotalAmount += event.Payload.Amount
+
+			// 计算成功率
+			if metrics.TotalTransactions > 0 {
+				metrics.SuccessRate = float64(metrics.SuccessTransactions) / float64(metrics.TotalTransactions) * 100
+			}
+		})
 	})
 }
 
 // handlePaymentFailed 处理支付失败事件
-func (w *EventWorker) handlePaymentFailed(ctx context.Context, message []byte) error {
+func (w *EventWorker) handlePaymentFailed(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.PaymentEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -194,47 +245,75 @@ func (w *EventWorker) handlePaymentFailed(ctx context.Context, message []byte) e
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
-
-	// 更新支付指标
-	err = w.updatePaymentMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
-		metrics.FailedPayments++
-
-		// 重新计算成功率
-		if metrics.TotalPayments > 0 {
-			metrics.SuccessRate = float64(metrics.SuccessPayments) / float64(metrics.TotalPayments) * 100
-		}
+	occurredAt := eventTime(base)
+	date := occurredAt.Truncate(24 * time.Hour)
+	rollups := w.buildPaymentRollups(ctx, merchantID, &event.Payload, occurredAt, func(r *model.PaymentRollup) {
+		r.PaymentCount = 1
+		r.FailedCount = 1
+		setRollupLatency(r, event.Payload.LatencyMs)
 	})
 
-	if err != nil {
-		return err
-	}
+	return w.processOnce(ctx, topicPaymentEvents, base, message, func(tx *gorm.DB) error {
+		if err := w.rollupRepo.UpsertRollups(tx, rollups); err != nil {
+			return err
+		}
+		if event.Payload.Decline != nil {
+			if err := w.rollupRepo.UpsertDeclineRollups(tx, []*model.DeclineRollup{buildDeclineRollup(merchantID, &event.Payload, occurredAt)}); err != nil {
+				return err
+			}
+		}
 
-	// 更新渠道指标
-	return w.updateChannelMetrics(ctx, event.Payload.Channel, date, event.Payload.Currency, func(metrics *model.ChannelMetrics) {
-		metrics.FailedTransactions++
+		// 更新支付指标
+		err := w.updatePaymentMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
+			metrics.FailedPayments++
 
-		// 重新计算成功率
-		if metrics.TotalTransactions > 0 {
-			metrics.SuccessRate = float64(metrics.SuccessTransactions) / float64(metrics.TotalTransactions) * 100
+			// 重新计算成功率
+			if metrics.TotalPayments > 0 {
+				metrics.SuccessRate = float64(metrics.SuccessPayments) / float64(metrics.TotalPayments) * 100
+			}
+		})
+		if err != nil {
+			return err
 		}
+
+		// 更新渠道指标
+		return w.updateChannelMetrics(tx, event.Payload.Channel, date, event.Payload.Currency, func(metrics *model.ChannelMetrics) {
+			metrics.FailedTransactions++
+
+			// 重新计算成功率
+			if metrics.TotalTransactions > 0 {
+				metrics.SuccessRate = float64(metrics.SuccessTransactions) / float64(metrics.TotalTransactions) * 100
+			}
+		})
 	})
 }
 
 // handlePaymentCancelled 处理支付取消事件
-func (w *EventWorker) handlePaymentCancelled(ctx context.Context, message []byte) error {
+func (w *EventWorker) handlePaymentCancelled(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.PaymentEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
 	}
 
-	// 支付取消暂不更新统计指标
-	logger.Info("Analytics: 支付取消事件已记录", zap.String("payment_no", event.Payload.PaymentNo))
-	return nil
+	merchantID, err := uuid.Parse(event.Payload.MerchantID)
+	if err != nil {
+		logger.Error("Analytics: 解析merchant_id失败", zap.Error(err))
+		return err
+	}
+
+	// 旧版日指标不统计取消，仅计入多维汇总
+	rollups := w.buildPaymentRollups(ctx, merchantID, &event.Payload, eventTime(base), func(r *model.PaymentRollup) {
+		r.PaymentCount = 1
+		r.CancelledCount = 1
+	})
+
+	return w.processOnce(ctx, topicPaymentEvents, base, message, func(tx *gorm.DB) error {
+		return w.rollupRepo.UpsertRollups(tx, rollups)
+	})
 }
 
 // handleRefundSuccess 处理退款成功事件
-func (w *EventWorker) handleRefundSuccess(ctx context.Context, message []byte) error {
+func (w *EventWorker) handleRefundSuccess(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.RefundEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -246,19 +325,35 @@ func (w *EventWorker) handleRefundSuccess(ctx context.Context, message []byte) e
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
+	occurredAt := eventTime(base)
+	date := occurredAt.Truncate(24 * time.Hour)
+	rollups := w.buildRollups(ctx, merchantID, rollupDimensions{
+		Channel:       event.Payload.Channel,
+		PaymentMethod: event.Payload.PayMethod,
+		Country:       event.Payload.Country,
+		Currency:      event.Payload.Currency,
+	}, occurredAt, func(r *model.PaymentRollup) {
+		r.RefundCount = 1
+		r.RefundAmount = event.Payload.Amount
+	})
 
-	// 更新支付指标 (增加退款数和退款金额)
-	return w.updatePaymentMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
-		metrics.TotalRefunds++
-		metrics.TotalRefundAmount += event.Payload.Amount
+	return w.processOnce(ctx, topicPaymentEvents, base, message, func(tx *gorm.DB) error {
+		if err := w.rollupRepo.UpsertRollups(tx, rollups); err != nil {
+			return err
+		}
+
+		// 更新支付指标 (增加退款数和退款金额)
+		return w.updatePaymentMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.PaymentMetrics) {
+			metrics.TotalRefunds++
+			metrics.TotalRefundAmount += event.Payload.Amount
+		})
 	})
 }
 
 // ========== 订单事件处理器 ==========
 
 // handleOrderCreated 处理订单创建事件
-func (w *EventWorker) handleOrderCreated(ctx context.Context, message []byte) error {
+func (w *EventWorker) handleOrderCreated(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.OrderEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -270,16 +365,18 @@ func (w *EventWorker) handleOrderCreated(ctx context.Context, message []byte) er
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
+	date := eventTime(base).Truncate(24 * time.Hour)
 
-	// 更新商户指标
-	return w.updateMerchantMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
-		metrics.TotalOrders++
+	return w.processOnce(ctx, topicOrderEvents, base, message, func(tx *gorm.DB) error {
+		// 更新商户指标
+		return w.updateMerchantMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
+			metrics.TotalOrders++
+		})
 	})
 }
 
 // handleOrderPaid 处理订单支付成功事件
-func (w *EventWorker) handleOrderPaid(ctx context.Context, message []byte) error {
+func (w *EventWorker) handleOrderPaid(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.OrderEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -291,7 +388,7 @@ func (w *EventWorker) handleOrderPaid(ctx context.Context, message []byte) error
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
+	date := eventTime(base).Truncate(24 * time.Hour)
 
 	// 从Extra中获取pay_amount
 	payAmount := event.Payload.TotalAmount
@@ -299,20 +396,22 @@ func (w *EventWorker) handleOrderPaid(ctx context.Context, message []byte) error
 		payAmount = int64(extra)
 	}
 
-	// 更新商户指标
-	return w.updateMerchantMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
-		metrics.CompletedOrders++
-		metrics.TotalRevenue += payAmount
-
-		// 假设费率2%
-		fee := payAmount * 2 / 100
-		metrics.TotalFees += fee
-		metrics.NetRevenue = metrics.TotalRevenue - metrics.TotalFees
+	return w.processOnce(ctx, topicOrderEvents, base, message, func(tx *gorm.DB) error {
+		// 更新商户指标
+		return w.updateMerchantMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
+			metrics.CompletedOrders++
+			metrics.TotalRevenue += payAmount
+
+			// 假设费率2%
+			fee := payAmount * 2 / 100
+			metrics.TotalFees += fee
+			metrics.NetRevenue = metrics.TotalRevenue - metrics.TotalFees
+		})
 	})
 }
 
 // handleOrderCancelled 处理订单取消事件
-func (w *EventWorker) handleOrderCancelled(ctx context.Context, message []byte) error {
+func (w *EventWorker) handleOrderCancelled(ctx context.Context, base *events.BaseEvent, message []byte) error {
 	var event events.OrderEvent
 	if err := json.Unmarshal(message, &event); err != nil {
 		return err
@@ -324,11 +423,13 @@ func (w *EventWorker) handleOrderCancelled(ctx context.Context, message []byte)
 		return err
 	}
 
-	date := time.Now().Truncate(24 * time.Hour)
+	date := eventTime(base).Truncate(24 * time.Hour)
 
-	// 更新商户指标
-	return w.updateMerchantMetrics(ctx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
-		metrics.CancelledOrders++
+	return w.processOnce(ctx, topicOrderEvents, base, message, func(tx *gorm.DB) error {
+		// 更新商户指标
+		return w.updateMerchantMetrics(tx, merchantID, date, event.Payload.Currency, func(metrics *model.MerchantMetrics) {
+			metrics.CancelledOrders++
+		})
 	})
 }
 
@@ -346,112 +447,224 @@ func (w *EventWorker) handleOrderCompleted(ctx context.Context, message []byte)
 
 // ========== 辅助方法 ==========
 
-// updatePaymentMetrics 更新支付指标 (使用UPSERT模式)
+const (
+	topicPaymentEvents = "payment.events"
+	topicOrderEvents   = "order.events"
+)
+
+// processOnce 在同一事务中登记事件并执行指标更新，已处理过的事件直接跳过
+func (w *EventWorker) processOnce(ctx context.Context, topic string, base *events.BaseEvent, message []byte, apply func(tx *gorm.DB) error) error {
+	eventID := base.EventID
+	if eventID == "" {
+		// 兼容缺少事件ID的旧消息：以消息内容摘要作为去重键
+		sum := sha256.Sum256(message)
+		eventID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed, err := w.rollupRepo.ClaimEvent(tx, &model.ProcessedEvent{
+			EventID:     eventID,
+			EventType:   base.EventType,
+			Topic:       topic,
+			OccurredAt:  eventTime(base),
+			ProcessedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			logger.Info("Analytics: 重复事件已跳过",
+				zap.String("event_id", eventID),
+				zap.String("event_type", base.EventType))
+			return nil
+		}
+		return apply(tx)
+	})
+}
+
+// eventTime 事件发生时间（UTC），缺失时使用当前时间
+func eventTime(base *events.BaseEvent) time.Time {
+	if base.Timestamp.IsZero() {
+		return time.Now().UTC()
+	}
+	return base.Timestamp.UTC()
+}
+
+// rollupDimensions 汇总维度（商户与等级除外）
+type rollupDimensions struct {
+	Channel       string
+	PaymentMethod string
+	Country       string
+	Currency      string
+}
+
+// buildPaymentRollups 根据支付事件构建小时和天粒度的汇总增量
+func (w *EventWorker) buildPaymentRollups(ctx context.Context, merchantID uuid.UUID, payload *events.PaymentEventPayload, occurredAt time.Time, fill func(*model.PaymentRollup)) []*model.PaymentRollup {
+	return w.buildRollups(ctx, merchantID, rollupDimensions{
+		Channel:       payload.Channel,
+		PaymentMethod: payload.PayMethod,
+		Country:       payload.Country,
+		Currency:      payload.Currency,
+	}, occurredAt, fill)
+}
+
+// buildRollups 构建小时和天粒度的汇总增量
+func (w *EventWorker) buildRollups(ctx context.Context, merchantID uuid.UUID, dims rollupDimensions, occurredAt time.Time, fill func(*model.PaymentRollup)) []*model.PaymentRollup {
+	tier := ""
+	if w.tierResolver != nil {
+		tier = w.tierResolver.Resolve(ctx, merchantID)
+	}
+
+	rollups := make([]*model.PaymentRollup, 0, 2)
+	for _, g := range []struct {
+		granularity string
+		bucket      time.Duration
+	}{
+		{model.GranularityHour, time.Hour},
+		{model.GranularityDay, 24 * time.Hour},
+	} {
+		rollup := &model.PaymentRollup{
+			Granularity:   g.granularity,
+			BucketStart:   occurredAt.Truncate(g.bucket),
+			MerchantID:    merchantID,
+			Channel:       dims.Channel,
+			PaymentMethod: dims.PaymentMethod,
+			Country:       strings.ToUpper(dims.Country),
+			Currency:      strings.ToUpper(dims.Currency),
+			MerchantTier:  tier,
+		}
+		fill(rollup)
+		rollups = append(rollups, rollup)
+	}
+	return rollups
+}
+
+// buildDeclineRollup 构建拒绝原因的天粒度汇总增量
+func buildDeclineRollup(merchantID uuid.UUID, payload *events.PaymentEventPayload, occurredAt time.Time) *model.DeclineRollup {
+	rollup := &model.DeclineRollup{
+		BucketStart:   occurredAt.Truncate(24 * time.Hour),
+		MerchantID:    merchantID,
+		Channel:       payload.Channel,
+		PaymentMethod: payload.PayMethod,
+		Country:       strings.ToUpper(payload.Country),
+		Currency:      strings.ToUpper(payload.Currency),
+		Category:      string(payload.Decline.Category),
+		DeclineType:   string(payload.Decline.Type),
+		DeclineCount:  1,
+		DeclineAmount: payload.Amount,
+	}
+	if payload.Decline.Retryable {
+		rollup.RetryableCount = 1
+	}
+	return rollup
+}
+
+// setRollupLatency 记录渠道耗时（事件未携带耗时则不计入）
+func setRollupLatency(r *model.PaymentRollup, latencyMs int64) {
+	if latencyMs > 0 {
+		r.LatencyTotalMs = latencyMs
+		r.LatencyCount = 1
+	}
+}
+
+// updatePaymentMetrics 更新支付指标 (在事件事务内执行)
 func (w *EventWorker) updatePaymentMetrics(
-	ctx context.Context,
+	tx *gorm.DB,
 	merchantID uuid.UUID,
 	date time.Time,
 	currency string,
 	updateFn func(*model.PaymentMetrics),
 ) error {
-	// 使用事务保证原子性
-	return w.db.Transaction(func(tx *gorm.DB) error {
-		var metrics model.PaymentMetrics
-
-		// 尝试查找现有记录
-		err := tx.Where("merchant_id = ? AND date = ? AND currency = ?", merchantID, date, currency).
-			First(&metrics).Error
-
-		if err == gorm.ErrRecordNotFound {
-			// 创建新记录
-			metrics = model.PaymentMetrics{
-				MerchantID: merchantID,
-				Date:       date,
-				Currency:   currency,
-			}
-		} else if err != nil {
-			return err
+	var metrics model.PaymentMetrics
+
+	// 尝试查找现有记录（行锁，避免并发事件覆盖彼此的累加结果）
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND date = ? AND currency = ?", merchantID, date, currency).
+		First(&metrics).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// 创建新记录
+		metrics = model.PaymentMetrics{
+			MerchantID: merchantID,
+			Date:       date,
+			Currency:   currency,
 		}
+	} else if err != nil {
+		return err
+	}
 
-		// 执行更新函数
-		updateFn(&metrics)
+	// 执行更新函数
+	updateFn(&metrics)
 
-		// 保存或更新
-		return tx.Save(&metrics).Error
-	})
+	// 保存或更新
+	return tx.Save(&metrics).Error
 }
 
-// updateMerchantMetrics 更新商户指标 (使用UPSERT模式)
+// updateMerchantMetrics 更新商户指标 (在事件事务内执行)
 func (w *EventWorker) updateMerchantMetrics(
-	ctx context.Context,
+	tx *gorm.DB,
 	merchantID uuid.UUID,
 	date time.Time,
 	currency string,
 	updateFn func(*model.MerchantMetrics),
 ) error {
-	// 使用事务保证原子性
-	return w.db.Transaction(func(tx *gorm.DB) error {
-		var metrics model.MerchantMetrics
-
-		// 尝试查找现有记录
-		err := tx.Where("merchant_id = ? AND date = ?", merchantID, date).
-			First(&metrics).Error
-
-		if err == gorm.ErrRecordNotFound {
-			// 创建新记录
-			metrics = model.MerchantMetrics{
-				MerchantID: merchantID,
-				Date:       date,
-				Currency:   currency,
-			}
-		} else if err != nil {
-			return err
+	var metrics model.MerchantMetrics
+
+	// 尝试查找现有记录
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND date = ?", merchantID, date).
+		First(&metrics).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// 创建新记录
+		metrics = model.MerchantMetrics{
+			MerchantID: merchantID,
+			Date:       date,
+			Currency:   currency,
 		}
+	} else if err != nil {
+		return err
+	}
 
-		// 执行更新函数
-		updateFn(&metrics)
+	// 执行更新函数
+	updateFn(&metrics)
 
-		// 保存或更新
-		return tx.Save(&metrics).Error
-	})
+	// 保存或更新
+	return tx.Save(&metrics).Error
 }
 
-// updateChannelMetrics 更新渠道指标 (使用UPSERT模式)
+// updateChannelMetrics 更新渠道指标 (在事件事务内执行)
 func (w *EventWorker) updateChannelMetrics(
-	ctx context.Context,
+	tx *gorm.DB,
 	channelCode string,
 	date time.Time,
 	currency string,
 	updateFn func(*model.ChannelMetrics),
 ) error {
-	// 使用事务保证原子性
-	return w.db.Transaction(func(tx *gorm.DB) error {
-		var metrics model.ChannelMetrics
-
-		// 尝试查找现有记录
-		err := tx.Where("channel_code = ? AND date = ? AND currency = ?", channelCode, date, currency).
-			First(&metrics).Error
-
-		if err == gorm.ErrRecordNotFound {
-			// 创建新记录
-			metrics = model.ChannelMetrics{
-				ChannelCode: channelCode,
-				Date:        date,
-				Currency:    currency,
-			}
-		} else if err != nil {
-			return err
+	var metrics model.ChannelMetrics
+
+	// 尝试查找现有记录
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("channel_code = ? AND date = ? AND currency = ?", channelCode, date, currency).
+		First(&metrics).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// 创建新记录
+		metrics = model.ChannelMetrics{
+			ChannelCode: channelCode,
+			Date:        date,
+			Currency:    currency,
 		}
+	} else if err != nil {
+		return err
+	}
 
-		// 在首次创建时也要增加TotalTransactions
-		if err == gorm.ErrRecordNotFound {
-			metrics.TotalTransactions++
-		}
+	// 每笔终态交易计入总交易数
+	metrics.TotalTransactions++
 
-		// 执行更新函数
-		updateFn(&metrics)
+	// 执行更新函数
+	updateFn(&metrics)
 
-		// 保存或更新
-		return tx.Save(&metrics).Error
-	})
+	// 保存或更新
+	return tx.Save(&metrics).Error
 }
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-platform/pkg/events"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"payment-platform/analytics-service/internal/model"
+	"payment-platform/analytics-service/internal/repository"
+)
+
+func init() {
+	logger.Log = zap.NewNop()
+}
+
+func newTestWorker(t *testing.T) *EventWorker {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ProcessedEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewEventWorker(db, nil, repository.NewRollupRepository(db), nil)
+}
+
+func TestProcessOnceSkipsRedelivery(t *testing.T) {
+	w := newTestWorker(t)
+	ctx := context.Background()
+
+	applied := 0
+	apply := func(tx *gorm.DB) error {
+		applied++
+		return nil
+	}
+
+	base := &events.BaseEvent{EventID: "evt_1", EventType: events.PaymentSuccess, Timestamp: time.Now()}
+	for i := 0; i < 3; i++ {
+		if err := w.processOnce(ctx, topicPaymentEvents, base, []byte(`{"event_id":"evt_1"}`), apply); err != nil {
+			t.Fatalf("processOnce() error = %v", err)
+		}
+	}
+	if applied != 1 {
+		t.Fatalf("applied = %d, want 1", applied)
+	}
+
+	// 缺少事件ID的旧消息按消息内容去重
+	legacy := &events.BaseEvent{EventType: events.PaymentSuccess}
+	for _, message := range []string{`{"amount":100}`, `{"amount":100}`, `{"amount":200}`} {
+		if err := w.processOnce(ctx, topicPaymentEvents, legacy, []byte(message), apply); err != nil {
+			t.Fatalf("processOnce() error = %v", err)
+		}
+	}
+	if applied != 3 {
+		t.Fatalf("applied = %d, want 3", applied)
+	}
+}
+
+func TestProcessOnceReleasesClaimOnFailure(t *testing.T) {
+	w := newTestWorker(t)
+	ctx := context.Background()
+	base := &events.BaseEvent{EventID: "evt_2", EventType: events.PaymentFailed, Timestamp: time.Now()}
+
+	// 指标更新失败时事件登记随事务回滚，重投后可以再次处理
+	failure := errors.New("db unavailable")
+	err := w.processOnce(ctx, topicPaymentEvents, base, nil, func(tx *gorm.DB) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("processOnce() error = %v, want %v", err, failure)
+	}
+
+	applied := 0
+	if err := w.processOnce(ctx, topicPaymentEvents, base, nil, func(tx *gorm.DB) error {
+		applied++
+		return nil
+	}); err != nil {
+		t.Fatalf("processOnce() error = %v", err)
+	}
+	if applied != 1 {
+		t.Fatalf("applied = %d, want 1 after redelivery", applied)
+	}
+}
@@ -106,7 +106,14 @@ func main() {
 	// 6. 注册 Swagger 文档路由 (公开访问)
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// 7. 注册路由 (需要认证)
+	// 7. 注册路由
+	// 品牌信息为公开数据，无需认证
+	cashierHandler.RegisterPublicRoutes(application.Router.Group("/api/v1"))
+
+	// 服务间调用路由（BFF 漏斗分析）
+	cashierHandler.RegisterServiceRoutes(application.Router.Group("/api/v1"))
+
+	// 需要认证的路由
 	api := application.Router.Group("/api/v1")
 	api.Use(authMiddleware)
 	{
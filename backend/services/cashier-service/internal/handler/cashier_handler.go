@@ -40,6 +40,8 @@ func (h *CashierHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 		// 统计分析
 		cashier.GET("/analytics", h.GetAnalytics)
+		cashier.GET("/analytics/funnel", h.GetFunnel)
+		cashier.GET("/analytics/templates/compare", h.CompareTemplates)
 	}
 
 	// 管理员API
@@ -53,6 +55,24 @@ func (h *CashierHandler) RegisterRoutes(router *gin.RouterGroup) {
 	}
 }
 
+// RegisterServiceRoutes 注册服务间调用的路由（BFF 已完成认证并传入 merchant_id，生产环境通过 mTLS 保护）
+func (h *CashierHandler) RegisterServiceRoutes(router *gin.RouterGroup) {
+	svc := router.Group("/service/cashier")
+	{
+		svc.GET("/funnel", h.GetFunnel)
+		svc.GET("/templates/compare", h.CompareTemplates)
+	}
+}
+
+// RegisterPublicRoutes 注册无需认证的路由（供收据/账单渲染等内部服务调用）
+func (h *CashierHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.GET("/cashier/branding/:merchant_id", h.GetBranding)
+	router.GET("/cashier/security/:merchant_id", h.GetSecuritySettings)
+
+	// 漏斗事件由收银台前端上报，以会话token作为凭证
+	router.POST("/cashier/events", h.TrackFunnelEvent)
+}
+
 // CreateOrUpdateConfig 创建或更新配置
 func (h *CashierHandler) CreateOrUpdateConfig(c *gin.Context) {
 	var input service.ConfigInput
@@ -102,6 +122,59 @@ func (h *CashierHandler) GetConfig(c *gin.Context) {
 	})
 }
 
+// GetBranding 获取商户品牌信息（只返回可公开展示的外观配置）
+func (h *CashierHandler) GetBranding(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant_id"})
+		return
+	}
+
+	config, err := h.service.GetConfig(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get config", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"merchant_id":       config.MerchantID,
+			"brand_name":        config.BrandName,
+			"theme_color":       config.ThemeColor,
+			"logo_url":          config.LogoURL,
+			"default_language":  config.DefaultLanguage,
+			"enabled_languages": config.EnabledLanguages,
+		},
+		"message": "success",
+	})
+}
+
+// GetSecuritySettings 获取商户支付安全设置（供支付网关决定是否发起 3DS 验证）
+func (h *CashierHandler) GetSecuritySettings(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant_id"})
+		return
+	}
+
+	config, err := h.service.GetConfig(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get config", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"merchant_id":      config.MerchantID,
+			"enable_3d_secure": config.Enable3DSecure,
+			"require_cvv":      config.RequireCVV,
+		},
+		"message": "success",
+	})
+}
+
 // DeleteConfig 删除配置
 func (h *CashierHandler) DeleteConfig(c *gin.Context) {
 	merchantID, err := getMerchantID(c)
@@ -382,3 +455,145 @@ func (h *CashierHandler) GetPlatformStats(c *gin.Context) {
 		"message": "success",
 	})
 }
+
+// TrackFunnelEvent 上报漏斗事件 (收银台前端)
+func (h *CashierHandler) TrackFunnelEvent(c *gin.Context) {
+	var input service.FunnelEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input", "message": err.Error()})
+		return
+	}
+
+	if err := h.service.TrackFunnelEvent(c.Request.Context(), &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to track event", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+	})
+}
+
+// GetFunnel 获取收银台转化漏斗
+//
+// 示例: /cashier/analytics/funnel?dimension=channel&start_time=2024-01-01T00:00:00Z&end_time=2024-02-01T00:00:00Z
+// dimension 可选 channel, method, country, device_type, language, template_id
+func (h *CashierHandler) GetFunnel(c *gin.Context) {
+	merchantID, ok := funnelMerchantScope(c)
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := parseFunnelRange(c)
+	if !ok {
+		return
+	}
+
+	input := &service.FunnelQueryInput{
+		MerchantID: merchantID,
+		Dimension:  c.Query("dimension"),
+		StartTime:  startTime,
+		EndTime:    endTime,
+	}
+	if templateIDStr := c.Query("template_id"); templateIDStr != "" {
+		templateID, err := uuid.Parse(templateIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template_id"})
+			return
+		}
+		input.TemplateID = &templateID
+	}
+
+	report, err := h.service.GetFunnel(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to get funnel", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    report,
+		"message": "success",
+	})
+}
+
+// CompareTemplates 收银台模板 A/B 对比
+//
+// 示例: /cashier/analytics/templates/compare?template_a=xxx&template_b=yyy&start_time=...&end_time=...
+func (h *CashierHandler) CompareTemplates(c *gin.Context) {
+	merchantID, ok := funnelMerchantScope(c)
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := parseFunnelRange(c)
+	if !ok {
+		return
+	}
+
+	templateA, err := uuid.Parse(c.Query("template_a"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template_a"})
+		return
+	}
+	templateB, err := uuid.Parse(c.Query("template_b"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template_b"})
+		return
+	}
+
+	comparison, err := h.service.CompareTemplates(c.Request.Context(), merchantID, templateA, templateB, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to compare templates", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    comparison,
+		"message": "success",
+	})
+}
+
+// funnelMerchantScope 确定漏斗查询的商户范围
+//
+// 商户JWT调用时只能查询自己的数据；服务间调用（BFF）通过 merchant_id 参数指定，为空表示全平台。
+func funnelMerchantScope(c *gin.Context) (*uuid.UUID, bool) {
+	if merchantID, err := getMerchantID(c); err == nil && merchantID != uuid.Nil {
+		return &merchantID, true
+	}
+
+	merchantIDStr := c.Query("merchant_id")
+	if merchantIDStr == "" {
+		return nil, true
+	}
+	merchantID, err := uuid.Parse(merchantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merchant_id"})
+		return nil, false
+	}
+	return &merchantID, true
+}
+
+// parseFunnelRange 解析时间范围（RFC3339，默认最近7天）
+func parseFunnelRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	startTime, endTime := now.AddDate(0, 0, -7), now
+
+	if value := c.Query("start_time"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_time", "message": err.Error()})
+			return time.Time{}, time.Time{}, false
+		}
+		startTime = t
+	}
+	if value := c.Query("end_time"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_time", "message": err.Error()})
+			return time.Time{}, time.Time{}, false
+		}
+		endTime = t
+	}
+	return startTime, endTime, true
+}
@@ -39,32 +39,36 @@ type CashierConfig struct {
 	TenantID   uuid.UUID `gorm:"type:uuid;not null;index" json:"tenant_id"`
 
 	// 外观配置
+	BrandName          string `gorm:"type:varchar(100)" json:"brand_name"` // 品牌名称（收银台、收据和账单上展示）
 	ThemeColor         string `gorm:"type:varchar(20);default:'#1890ff'" json:"theme_color"`
 	LogoURL            string `gorm:"type:varchar(500)" json:"logo_url"`
 	BackgroundImageURL string `gorm:"type:varchar(500)" json:"background_image_url"`
 	CustomCSS          string `gorm:"type:text" json:"custom_css"`
 
 	// 功能配置
-	EnabledChannels  StringArray `gorm:"type:jsonb" json:"enabled_channels"`  // ["stripe", "paypal", "alipay"]
+	EnabledChannels  StringArray `gorm:"type:jsonb" json:"enabled_channels"` // ["stripe", "paypal", "alipay"]
 	DefaultChannel   string      `gorm:"type:varchar(50)" json:"default_channel"`
 	EnabledLanguages StringArray `gorm:"type:jsonb" json:"enabled_languages"` // ["en", "zh-CN", "ja"]
 	DefaultLanguage  string      `gorm:"type:varchar(10);default:'en'" json:"default_language"`
 
 	// 支付配置
-	AutoSubmit           bool `json:"auto_submit"`
-	ShowAmountBreakdown  bool `gorm:"default:true" json:"show_amount_breakdown"`
-	AllowChannelSwitch   bool `gorm:"default:true" json:"allow_channel_switch"`
+	AutoSubmit            bool `json:"auto_submit"`
+	ShowAmountBreakdown   bool `gorm:"default:true" json:"show_amount_breakdown"`
+	AllowChannelSwitch    bool `gorm:"default:true" json:"allow_channel_switch"`
 	SessionTimeoutMinutes int  `gorm:"default:30" json:"session_timeout_minutes"`
 
 	// 安全配置
-	RequireCVV      bool        `gorm:"default:true" json:"require_cvv"`
-	Enable3DSecure  bool        `gorm:"default:true" json:"enable_3d_secure"`
+	RequireCVV       bool        `gorm:"default:true" json:"require_cvv"`
+	Enable3DSecure   bool        `gorm:"default:true" json:"enable_3d_secure"`
 	AllowedCountries StringArray `gorm:"type:jsonb" json:"allowed_countries"` // ["US", "CN", "JP"]
 
 	// 回调配置
 	SuccessRedirectURL string `gorm:"type:varchar(500)" json:"success_redirect_url"`
 	CancelRedirectURL  string `gorm:"type:varchar(500)" json:"cancel_redirect_url"`
 
+	// A/B 实验：新会话在这些模板间按会话均匀分流（为空表示不参与实验）
+	ExperimentTemplates StringArray `gorm:"type:jsonb" json:"experiment_templates"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -100,8 +104,23 @@ type CashierSession struct {
 	Status    string `gorm:"type:varchar(20);default:'pending';index" json:"status"` // pending/active/completed/expired
 	PaymentNo string `gorm:"type:varchar(100)" json:"payment_no"`                    // 关联的支付单号
 
+	// 漏斗分析维度
+	TemplateID      *uuid.UUID `gorm:"type:uuid;index" json:"template_id,omitempty"` // 收银台模板（A/B 实验分组）
+	Country         string     `gorm:"type:varchar(2)" json:"country"`
+	Language        string     `gorm:"type:varchar(10)" json:"language"`
+	DeviceType      string     `gorm:"type:varchar(20)" json:"device_type"`
+	SelectedChannel string     `gorm:"type:varchar(50)" json:"selected_channel"`
+	SelectedMethod  string     `gorm:"type:varchar(50)" json:"selected_method"`
+
+	// 漏斗步骤时间（成功时间为 CompletedAt）
+	MethodSelectedAt *time.Time `json:"method_selected_at,omitempty"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	ThreeDSAt        *time.Time `json:"three_ds_at,omitempty"`
+	FailedAt         *time.Time `json:"failed_at,omitempty"`
+	FailureReason    string     `gorm:"type:varchar(100)" json:"failure_reason,omitempty"`
+
 	// 时间管理
-	CreatedAt   time.Time  `json:"created_at"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
 	ExpiresAt   time.Time  `gorm:"index" json:"expires_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
@@ -113,6 +132,16 @@ func (CashierSession) TableName() string {
 	return "cashier_sessions"
 }
 
+// 收银台漏斗步骤
+const (
+	FunnelStepCreated        = "created"         // 会话创建
+	FunnelStepMethodSelected = "method_selected" // 选择支付方式
+	FunnelStepSubmitted      = "submitted"       // 提交支付
+	FunnelStepThreeDS        = "three_ds"        // 进入 3DS 验证（可选步骤）
+	FunnelStepSuccess        = "success"         // 支付成功
+	FunnelStepFailed         = "failed"          // 支付失败
+)
+
 // CashierLog 收银台访问日志模型
 type CashierLog struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
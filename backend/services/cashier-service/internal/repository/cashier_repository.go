@@ -40,6 +40,7 @@ type CashierRepository interface {
 	// 统计分析
 	GetConversionRate(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) (float64, error)
 	GetChannelStats(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) (map[string]int, error)
+	GetFunnel(ctx context.Context, query *FunnelQuery) ([]*FunnelRow, error)
 
 	// 平台统计
 	GetActiveMerchantCount(ctx context.Context) (int, error)
@@ -63,11 +64,11 @@ func (r *cashierRepository) CreateOrUpdateConfig(ctx context.Context, config *mo
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "merchant_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{
-				"theme_color", "logo_url", "background_image_url", "custom_css",
+				"brand_name", "theme_color", "logo_url", "background_image_url", "custom_css",
 				"enabled_channels", "default_channel", "enabled_languages", "default_language",
 				"auto_submit", "show_amount_breakdown", "allow_channel_switch", "session_timeout_minutes",
 				"require_cvv", "enable_3d_secure", "allowed_countries",
-				"success_redirect_url", "cancel_redirect_url", "updated_at",
+				"success_redirect_url", "cancel_redirect_url", "experiment_templates", "updated_at",
 			}),
 		}).
 		Create(config).Error
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"payment-platform/cashier-service/internal/model"
+)
+
+// FunnelDimensionColumns 漏斗可分组维度与列表达式映射
+var FunnelDimensionColumns = map[string]string{
+	"channel":     "selected_channel",
+	"method":      "selected_method",
+	"country":     "country",
+	"device_type": "device_type",
+	"language":    "language",
+	"template_id": "COALESCE(template_id::text, '')",
+}
+
+// FunnelQuery 漏斗查询条件
+type FunnelQuery struct {
+	MerchantID  *uuid.UUID  // 为空表示全平台
+	Dimension   string      // 分组维度（见 FunnelDimensionColumns），为空表示不分组
+	TemplateIDs []uuid.UUID // 仅统计指定模板的会话
+	StartTime   time.Time
+	EndTime     time.Time // 不含
+}
+
+// FunnelRow 漏斗统计行（各步骤为到达该步骤的会话数）
+type FunnelRow struct {
+	Dimension      string  `json:"dimension"`
+	Created        int64   `json:"created"`
+	MethodSelected int64   `json:"method_selected"`
+	Submitted      int64   `json:"submitted"`
+	ThreeDS        int64   `json:"three_ds"`
+	Succeeded      int64   `json:"succeeded"`
+	Failed         int64   `json:"failed"`
+	MedianSeconds  float64 `json:"median_seconds"` // 成功会话从创建到支付完成的耗时中位数
+}
+
+// GetFunnel 按会话统计漏斗各步骤到达数
+func (r *cashierRepository) GetFunnel(ctx context.Context, query *FunnelQuery) ([]*FunnelRow, error) {
+	dimensionExpr := "''"
+	if query.Dimension != "" {
+		column, ok := FunnelDimensionColumns[query.Dimension]
+		if !ok {
+			return nil, fmt.Errorf("unsupported funnel dimension: %s", query.Dimension)
+		}
+		dimensionExpr = column
+	}
+
+	db := r.db.WithContext(ctx).
+		Model(&model.CashierSession{}).
+		Select(dimensionExpr+" AS dimension, "+
+			"COUNT(*) AS created, "+
+			"COUNT(method_selected_at) AS method_selected, "+
+			"COUNT(submitted_at) AS submitted, "+
+			"COUNT(three_ds_at) AS three_ds, "+
+			"COUNT(completed_at) AS succeeded, "+
+			"COUNT(*) FILTER (WHERE failed_at IS NOT NULL AND completed_at IS NULL) AS failed, "+
+			"COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM completed_at - created_at)) "+
+			"FILTER (WHERE completed_at IS NOT NULL), 0) AS median_seconds").
+		Where("created_at >= ? AND created_at < ?", query.StartTime, query.EndTime)
+
+	if query.MerchantID != nil {
+		db = db.Where("merchant_id = ?", *query.MerchantID)
+	}
+	if len(query.TemplateIDs) > 0 {
+		db = db.Where("template_id IN ?", query.TemplateIDs)
+	}
+	if query.Dimension != "" {
+		db = db.Group(dimensionExpr).Order("created DESC")
+	}
+
+	var rows []*FunnelRow
+	err := db.Scan(&rows).Error
+	return rows, err
+}
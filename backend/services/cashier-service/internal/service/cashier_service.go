@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -33,6 +34,11 @@ type CashierService interface {
 	// 统计分析
 	GetAnalytics(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) (*AnalyticsData, error)
 
+	// 漏斗分析
+	TrackFunnelEvent(ctx context.Context, input *FunnelEventInput) error
+	GetFunnel(ctx context.Context, input *FunnelQueryInput) (*FunnelReport, error)
+	CompareTemplates(ctx context.Context, merchantID *uuid.UUID, templateA, templateB uuid.UUID, startTime, endTime time.Time) (*TemplateComparison, error)
+
 	// 管理员API
 	ListTemplates(ctx context.Context) ([]*model.CashierTemplate, error)
 	CreateTemplate(ctx context.Context, input *TemplateInput) (*model.CashierTemplate, error)
@@ -52,6 +58,7 @@ func NewCashierService(repo repository.CashierRepository) CashierService {
 
 // ConfigInput 配置输入
 type ConfigInput struct {
+	BrandName             string   `json:"brand_name"`
 	ThemeColor            string   `json:"theme_color"`
 	LogoURL               string   `json:"logo_url"`
 	BackgroundImageURL    string   `json:"background_image_url"`
@@ -69,6 +76,7 @@ type ConfigInput struct {
 	AllowedCountries      []string `json:"allowed_countries"`
 	SuccessRedirectURL    string   `json:"success_redirect_url"`
 	CancelRedirectURL     string   `json:"cancel_redirect_url"`
+	ExperimentTemplates   []string `json:"experiment_templates"`
 }
 
 // SessionInput 会话输入
@@ -85,6 +93,9 @@ type SessionInput struct {
 	AllowedMethods  []string               `json:"allowed_methods"`
 	Metadata        map[string]interface{} `json:"metadata"`
 	ExpiresInMinutes int                    `json:"expires_in_minutes"`
+	TemplateID      *uuid.UUID             `json:"template_id"` // 指定模板，为空时按实验配置分流
+	Country         string                 `json:"country"`
+	Language        string                 `json:"language"`
 }
 
 // LogInput 日志输入
@@ -136,6 +147,7 @@ func (s *cashierService) CreateOrUpdateConfig(ctx context.Context, merchantID uu
 	config := &model.CashierConfig{
 		MerchantID:            merchantID,
 		TenantID:              merchantID, // 暂时使用 merchantID 作为 tenantID
+		BrandName:             input.BrandName,
 		ThemeColor:            input.ThemeColor,
 		LogoURL:               input.LogoURL,
 		BackgroundImageURL:    input.BackgroundImageURL,
@@ -153,6 +165,12 @@ func (s *cashierService) CreateOrUpdateConfig(ctx context.Context, merchantID uu
 		AllowedCountries:      input.AllowedCountries,
 		SuccessRedirectURL:    input.SuccessRedirectURL,
 		CancelRedirectURL:     input.CancelRedirectURL,
+		ExperimentTemplates:   input.ExperimentTemplates,
+	}
+	for _, templateID := range input.ExperimentTemplates {
+		if _, err := uuid.Parse(templateID); err != nil {
+			return nil, fmt.Errorf("无效的实验模板ID: %s", templateID)
+		}
 	}
 
 	err := s.repo.CreateOrUpdateConfig(ctx, config)
@@ -236,6 +254,21 @@ func (s *cashierService) CreateSession(ctx context.Context, input *SessionInput)
 		Metadata:        input.Metadata,
 		Status:          "pending",
 		ExpiresAt:       expiresAt,
+		TemplateID:      input.TemplateID,
+		Country:         strings.ToUpper(input.Country),
+		Language:        input.Language,
+	}
+
+	// 未指定模板时按商户的 A/B 实验配置分流
+	if session.TemplateID == nil || session.Language == "" {
+		if config, err := s.repo.GetConfig(ctx, input.MerchantID); err == nil && config != nil {
+			if session.TemplateID == nil {
+				session.TemplateID = pickExperimentTemplate(config.ExperimentTemplates, sessionToken)
+			}
+			if session.Language == "" {
+				session.Language = config.DefaultLanguage
+			}
+		}
 	}
 
 	err = s.repo.CreateSession(ctx, session)
@@ -292,6 +325,7 @@ func (s *cashierService) CompleteSession(ctx context.Context, sessionToken strin
 	session.Status = "completed"
 	session.PaymentNo = paymentNo
 	session.CompletedAt = &now
+	applyFunnelStep(session, model.FunnelStepSuccess, now)
 
 	err = s.repo.UpdateSession(ctx, session)
 	if err != nil {
@@ -348,6 +382,34 @@ func (s *cashierService) RecordLog(ctx context.Context, input *LogInput) error {
 		return fmt.Errorf("记录日志失败: %w", err)
 	}
 
+	// 旧版日志同样推进漏斗步骤
+	if session.Status != "completed" && (input.SelectedChannel != "" || input.PaymentSubmitted || input.DeviceType != "") {
+		funnel := &FunnelEventInput{
+			SessionToken: input.SessionToken,
+			Channel:      input.SelectedChannel,
+			Method:       input.SelectedMethod,
+			DeviceType:   input.DeviceType,
+		}
+		switch {
+		case input.PaymentSubmitted:
+			funnel.Step = model.FunnelStepSubmitted
+		case input.SelectedChannel != "" || input.SelectedMethod != "":
+			funnel.Step = model.FunnelStepMethodSelected
+		}
+		if funnel.Step != "" {
+			if err := s.TrackFunnelEvent(ctx, funnel); err != nil {
+				logger.Warn("Failed to update funnel from cashier log",
+					zap.String("session_token", input.SessionToken),
+					zap.Error(err))
+			}
+		} else if session.DeviceType == "" {
+			session.DeviceType = input.DeviceType
+			if err := s.repo.UpdateSession(ctx, session); err != nil {
+				logger.Warn("Failed to update session device type", zap.Error(err))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/cashier-service/internal/model"
+	"payment-platform/cashier-service/internal/repository"
+)
+
+// 漏斗查询限制
+const (
+	maxFunnelRange = 92 * 24 * time.Hour
+	// 两组转化率差异显著性的置信水平（双侧 95%）
+	significanceZ = 1.96
+)
+
+// FunnelEventInput 收银台前端上报的漏斗事件
+type FunnelEventInput struct {
+	SessionToken string `json:"session_token" binding:"required"`
+	Step         string `json:"step" binding:"required"` // method_selected/submitted/three_ds/failed
+	Channel      string `json:"channel"`
+	Method       string `json:"method"`
+	Country      string `json:"country"`
+	Language     string `json:"language"`
+	DeviceType   string `json:"device_type"`
+	Reason       string `json:"reason"` // 失败原因（step=failed）
+}
+
+// FunnelQueryInput 漏斗查询
+type FunnelQueryInput struct {
+	MerchantID *uuid.UUID
+	Dimension  string
+	TemplateID *uuid.UUID
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// FunnelStage 漏斗步骤
+type FunnelStage struct {
+	Step         string  `json:"step"`
+	Count        int64   `json:"count"`
+	FromPrevious float64 `json:"from_previous"`  // 相对上一步的转化率(%)
+	FromStart    float64 `json:"from_start"`     // 相对会话创建的转化率(%)
+	DropOffCount int64   `json:"drop_off_count"` // 在上一步之后流失的会话数
+}
+
+// FunnelBreakdown 单个维度值的漏斗
+type FunnelBreakdown struct {
+	Value              string         `json:"value"`
+	Stages             []*FunnelStage `json:"stages"`
+	ThreeDS            int64          `json:"three_ds"`        // 进入 3DS 的会话数
+	ThreeDSRate        float64        `json:"three_ds_rate"`   // 提交后进入 3DS 的比例(%)
+	Failed             int64          `json:"failed"`          // 明确支付失败的会话数
+	ConversionRate     float64        `json:"conversion_rate"` // 会话创建到支付成功(%)
+	MedianTimeToPaySec float64        `json:"median_time_to_pay_sec"`
+}
+
+// FunnelReport 漏斗报告
+type FunnelReport struct {
+	Dimension string             `json:"dimension,omitempty"`
+	StartTime time.Time          `json:"start_time"`
+	EndTime   time.Time          `json:"end_time"`
+	Total     *FunnelBreakdown   `json:"total"`
+	Breakdown []*FunnelBreakdown `json:"breakdown,omitempty"`
+}
+
+// TemplateComparison 模板 A/B 对比
+type TemplateComparison struct {
+	StartTime   time.Time        `json:"start_time"`
+	EndTime     time.Time        `json:"end_time"`
+	TemplateA   *FunnelBreakdown `json:"template_a"`
+	TemplateB   *FunnelBreakdown `json:"template_b"`
+	Lift        float64          `json:"lift"`    // B 相对 A 的转化率提升(%)
+	ZScore      float64          `json:"z_score"` // 两比例 z 检验
+	PValue      float64          `json:"p_value"`
+	Significant bool             `json:"significant"`
+}
+
+// frontendFunnelSteps 允许前端上报的步骤（成功只能由服务端完成会话时记录）
+var frontendFunnelSteps = map[string]bool{
+	model.FunnelStepMethodSelected: true,
+	model.FunnelStepSubmitted:      true,
+	model.FunnelStepThreeDS:        true,
+	model.FunnelStepFailed:         true,
+}
+
+// TrackFunnelEvent 记录漏斗事件
+func (s *cashierService) TrackFunnelEvent(ctx context.Context, input *FunnelEventInput) error {
+	if !frontendFunnelSteps[input.Step] {
+		return fmt.Errorf("不支持的漏斗步骤: %s", input.Step)
+	}
+
+	session, err := s.repo.GetSession(ctx, input.SessionToken)
+	if err != nil {
+		return fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("会话不存在")
+	}
+	if session.Status == "completed" {
+		// 会话已完成，忽略迟到事件
+		return nil
+	}
+
+	if input.Channel != "" {
+		session.SelectedChannel = input.Channel
+	}
+	if input.Method != "" {
+		session.SelectedMethod = input.Method
+	}
+	if input.Country != "" {
+		session.Country = strings.ToUpper(input.Country)
+	}
+	if input.Language != "" {
+		session.Language = input.Language
+	}
+	if input.DeviceType != "" {
+		session.DeviceType = input.DeviceType
+	}
+	if input.Step == model.FunnelStepFailed {
+		session.FailureReason = input.Reason
+	}
+	applyFunnelStep(session, input.Step, time.Now())
+
+	if err := s.repo.UpdateSession(ctx, session); err != nil {
+		logger.Error("Failed to track funnel event",
+			zap.String("session_token", input.SessionToken),
+			zap.String("step", input.Step),
+			zap.Error(err))
+		return fmt.Errorf("记录漏斗事件失败: %w", err)
+	}
+	return nil
+}
+
+// applyFunnelStep 记录步骤时间，并补齐之前未上报的必经步骤，保证漏斗单调
+func applyFunnelStep(session *model.CashierSession, step string, at time.Time) {
+	mark := func(t **time.Time) {
+		if *t == nil {
+			*t = &at
+		}
+	}
+
+	switch step {
+	case model.FunnelStepMethodSelected:
+		mark(&session.MethodSelectedAt)
+	case model.FunnelStepSubmitted:
+		mark(&session.MethodSelectedAt)
+		mark(&session.SubmittedAt)
+	case model.FunnelStepThreeDS:
+		mark(&session.MethodSelectedAt)
+		mark(&session.SubmittedAt)
+		mark(&session.ThreeDSAt)
+	case model.FunnelStepFailed:
+		// 失败可能发生在提交后或 3DS 后，重试成功时仍以成功计
+		mark(&session.FailedAt)
+	case model.FunnelStepSuccess:
+		mark(&session.MethodSelectedAt)
+		mark(&session.SubmittedAt)
+	}
+}
+
+// pickExperimentTemplate 按会话token在实验模板间均匀分流
+func pickExperimentTemplate(templates []string, sessionToken string) *uuid.UUID {
+	if len(templates) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(sessionToken))
+	id, err := uuid.Parse(templates[h.Sum32()%uint32(len(templates))])
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// GetFunnel 获取收银台转化漏斗
+func (s *cashierService) GetFunnel(ctx context.Context, input *FunnelQueryInput) (*FunnelReport, error) {
+	if err := validateFunnelRange(input.StartTime, input.EndTime); err != nil {
+		return nil, err
+	}
+	if input.Dimension != "" {
+		if _, ok := repository.FunnelDimensionColumns[input.Dimension]; !ok {
+			return nil, fmt.Errorf("不支持的维度: %s", input.Dimension)
+		}
+	}
+
+	query := &repository.FunnelQuery{
+		MerchantID: input.MerchantID,
+		StartTime:  input.StartTime,
+		EndTime:    input.EndTime,
+	}
+	if input.TemplateID != nil {
+		query.TemplateIDs = []uuid.UUID{*input.TemplateID}
+	}
+
+	totals, err := s.repo.GetFunnel(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("获取漏斗数据失败: %w", err)
+	}
+
+	report := &FunnelReport{
+		Dimension: input.Dimension,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		Total:     buildFunnelBreakdown(&repository.FunnelRow{}),
+	}
+	if len(totals) > 0 {
+		report.Total = buildFunnelBreakdown(totals[0])
+	}
+
+	if input.Dimension != "" {
+		query.Dimension = input.Dimension
+		rows, err := s.repo.GetFunnel(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("获取漏斗数据失败: %w", err)
+		}
+		report.Breakdown = make([]*FunnelBreakdown, 0, len(rows))
+		for _, row := range rows {
+			report.Breakdown = append(report.Breakdown, buildFunnelBreakdown(row))
+		}
+	}
+
+	return report, nil
+}
+
+// CompareTemplates 对比两个收银台模板的转化效果
+func (s *cashierService) CompareTemplates(ctx context.Context, merchantID *uuid.UUID, templateA, templateB uuid.UUID, startTime, endTime time.Time) (*TemplateComparison, error) {
+	if err := validateFunnelRange(startTime, endTime); err != nil {
+		return nil, err
+	}
+	if templateA == templateB {
+		return nil, fmt.Errorf("对比的模板不能相同")
+	}
+
+	rows, err := s.repo.GetFunnel(ctx, &repository.FunnelQuery{
+		MerchantID:  merchantID,
+		Dimension:   "template_id",
+		TemplateIDs: []uuid.UUID{templateA, templateB},
+		StartTime:   startTime,
+		EndTime:     endTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取漏斗数据失败: %w", err)
+	}
+
+	rowA := &repository.FunnelRow{Dimension: templateA.String()}
+	rowB := &repository.FunnelRow{Dimension: templateB.String()}
+	for _, row := range rows {
+		switch row.Dimension {
+		case templateA.String():
+			rowA = row
+		case templateB.String():
+			rowB = row
+		}
+	}
+
+	comparison := &TemplateComparison{
+		StartTime: startTime,
+		EndTime:   endTime,
+		TemplateA: buildFunnelBreakdown(rowA),
+		TemplateB: buildFunnelBreakdown(rowB),
+	}
+	if comparison.TemplateA.ConversionRate > 0 {
+		comparison.Lift = (comparison.TemplateB.ConversionRate - comparison.TemplateA.ConversionRate) /
+			comparison.TemplateA.ConversionRate * 100
+	}
+	comparison.ZScore, comparison.PValue = twoProportionZTest(rowA.Succeeded, rowA.Created, rowB.Succeeded, rowB.Created)
+	comparison.Significant = math.Abs(comparison.ZScore) >= significanceZ
+
+	return comparison, nil
+}
+
+func validateFunnelRange(startTime, endTime time.Time) error {
+	if !endTime.After(startTime) {
+		return fmt.Errorf("end_time 必须晚于 start_time")
+	}
+	if endTime.Sub(startTime) > maxFunnelRange {
+		return fmt.Errorf("查询范围不能超过92天")
+	}
+	return nil
+}
+
+func buildFunnelBreakdown(row *repository.FunnelRow) *FunnelBreakdown {
+	steps := []struct {
+		step  string
+		count int64
+	}{
+		{model.FunnelStepCreated, row.Created},
+		{model.FunnelStepMethodSelected, row.MethodSelected},
+		{model.FunnelStepSubmitted, row.Submitted},
+		{model.FunnelStepSuccess, row.Succeeded},
+	}
+
+	breakdown := &FunnelBreakdown{
+		Value:              row.Dimension,
+		Stages:             make([]*FunnelStage, 0, len(steps)),
+		ThreeDS:            row.ThreeDS,
+		ThreeDSRate:        percentage(row.ThreeDS, row.Submitted),
+		Failed:             row.Failed,
+		ConversionRate:     percentage(row.Succeeded, row.Created),
+		MedianTimeToPaySec: math.Round(row.MedianSeconds*10) / 10,
+	}
+	for i, step := range steps {
+		stage := &FunnelStage{
+			Step:         step.step,
+			Count:        step.count,
+			FromPrevious: 100,
+			FromStart:    percentage(step.count, row.Created),
+		}
+		if i > 0 {
+			prev := steps[i-1].count
+			stage.FromPrevious = percentage(step.count, prev)
+			stage.DropOffCount = prev - step.count
+		}
+		breakdown.Stages = append(breakdown.Stages, stage)
+	}
+	return breakdown
+}
+
+func percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+// twoProportionZTest 两比例 z 检验，返回 z 值和双侧 p 值
+func twoProportionZTest(successA, totalA, successB, totalB int64) (float64, float64) {
+	if totalA == 0 || totalB == 0 {
+		return 0, 1
+	}
+	pA := float64(successA) / float64(totalA)
+	pB := float64(successB) / float64(totalB)
+	pooled := float64(successA+successB) / float64(totalA+totalB)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(totalA) + 1/float64(totalB)))
+	if se == 0 {
+		return 0, 1
+	}
+	z := (pB - pA) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"payment-platform/cashier-service/internal/model"
+	"payment-platform/cashier-service/internal/repository"
+)
+
+func TestApplyFunnelStepBackfillsEarlierSteps(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	t2 := t0.Add(2 * time.Minute)
+
+	// 前端漏报选择方式，直接上报 3DS 时补齐选择和提交
+	session := &model.CashierSession{}
+	applyFunnelStep(session, model.FunnelStepThreeDS, t0)
+	for name, at := range map[string]*time.Time{
+		"method_selected": session.MethodSelectedAt,
+		"submitted":       session.SubmittedAt,
+		"three_ds":        session.ThreeDSAt,
+	} {
+		if at == nil || !at.Equal(t0) {
+			t.Fatalf("%s = %v, want %v", name, at, t0)
+		}
+	}
+
+	// 重复上报不覆盖首次时间；失败只记录失败时间
+	applyFunnelStep(session, model.FunnelStepSubmitted, t1)
+	applyFunnelStep(session, model.FunnelStepFailed, t1)
+	if !session.SubmittedAt.Equal(t0) || session.FailedAt == nil || !session.FailedAt.Equal(t1) {
+		t.Fatalf("submitted = %v, failed = %v", session.SubmittedAt, session.FailedAt)
+	}
+	applyFunnelStep(session, model.FunnelStepFailed, t2)
+	if !session.FailedAt.Equal(t1) {
+		t.Fatalf("failed = %v, want first failure %v", session.FailedAt, t1)
+	}
+
+	// 失败不补齐之前的步骤
+	failedOnly := &model.CashierSession{}
+	applyFunnelStep(failedOnly, model.FunnelStepFailed, t0)
+	if failedOnly.MethodSelectedAt != nil || failedOnly.SubmittedAt != nil {
+		t.Fatalf("failed step backfilled earlier steps: %+v", failedOnly)
+	}
+
+	// 成功补齐选择和提交，但不标记 3DS
+	succeeded := &model.CashierSession{}
+	applyFunnelStep(succeeded, model.FunnelStepSuccess, t2)
+	if succeeded.MethodSelectedAt == nil || succeeded.SubmittedAt == nil || succeeded.ThreeDSAt != nil {
+		t.Fatalf("success step = %+v", succeeded)
+	}
+}
+
+func TestBuildFunnelBreakdown(t *testing.T) {
+	breakdown := buildFunnelBreakdown(&repository.FunnelRow{
+		Dimension:      "US",
+		Created:        200,
+		MethodSelected: 150,
+		Submitted:      120,
+		Succeeded:      90,
+		ThreeDS:        30,
+		Failed:         25,
+		MedianSeconds:  42.46,
+	})
+
+	want := []struct {
+		step         string
+		count        int64
+		fromPrevious float64
+		fromStart    float64
+		dropOff      int64
+	}{
+		{model.FunnelStepCreated, 200, 100, 100, 0},
+		{model.FunnelStepMethodSelected, 150, 75, 75, 50},
+		{model.FunnelStepSubmitted, 120, 80, 60, 30},
+		{model.FunnelStepSuccess, 90, 75, 45, 30},
+	}
+	if len(breakdown.Stages) != len(want) {
+		t.Fatalf("stages = %d, want %d", len(breakdown.Stages), len(want))
+	}
+	for i, w := range want {
+		s := breakdown.Stages[i]
+		if s.Step != w.step || s.Count != w.count || s.FromPrevious != w.fromPrevious ||
+			s.FromStart != w.fromStart || s.DropOffCount != w.dropOff {
+			t.Errorf("stage %d = %+v, want %+v", i, s, w)
+		}
+	}
+	if breakdown.Value != "US" || breakdown.ConversionRate != 45 || breakdown.ThreeDSRate != 25 ||
+		breakdown.Failed != 25 || breakdown.MedianTimeToPaySec != 42.5 {
+		t.Fatalf("breakdown = %+v", breakdown)
+	}
+
+	// 没有会话时各比例为 0
+	empty := buildFunnelBreakdown(&repository.FunnelRow{})
+	if empty.ConversionRate != 0 || empty.ThreeDSRate != 0 || empty.Stages[1].FromPrevious != 0 {
+		t.Fatalf("empty breakdown = %+v", empty)
+	}
+}
+
+func TestTwoProportionZTest(t *testing.T) {
+	// 10% vs 13%，各 1000 个会话：z ≈ 2.10，p ≈ 0.036，显著
+	z, p := twoProportionZTest(100, 1000, 130, 1000)
+	if math.Abs(z-2.1027) > 1e-3 || math.Abs(p-0.0355) > 1e-3 {
+		t.Fatalf("z = %.4f, p = %.4f, want z ≈ 2.1027, p ≈ 0.0355", z, p)
+	}
+	if math.Abs(z) < significanceZ {
+		t.Fatalf("z = %.4f should be significant", z)
+	}
+
+	// 方向：B 低于 A 时 z 为负，p 值相同
+	zNeg, pNeg := twoProportionZTest(130, 1000, 100, 1000)
+	if math.Abs(zNeg+z) > 1e-9 || math.Abs(pNeg-p) > 1e-9 {
+		t.Fatalf("reversed z = %.4f, p = %.4f", zNeg, pNeg)
+	}
+
+	// 样本太少时不显著
+	if z, _ := twoProportionZTest(1, 10, 2, 10); math.Abs(z) >= significanceZ {
+		t.Fatalf("small sample z = %.4f should not be significant", z)
+	}
+
+	// 没有样本或转化率全为 0/100% 时无法检验
+	for _, tt := range [][4]int64{{0, 0, 10, 100}, {10, 100, 0, 0}, {0, 100, 0, 100}, {100, 100, 50, 50}} {
+		if z, p := twoProportionZTest(tt[0], tt[1], tt[2], tt[3]); z != 0 || p != 1 {
+			t.Errorf("twoProportionZTest(%v) = %v, %v, want 0, 1", tt, z, p)
+		}
+	}
+}
+
+func TestPickExperimentTemplate(t *testing.T) {
+	templates := []string{uuid.NewString(), uuid.NewString()}
+
+	// 同一会话始终分到同一模板
+	first := pickExperimentTemplate(templates, "cs_token_1")
+	if first == nil {
+		t.Fatal("expected a template")
+	}
+	for i := 0; i < 5; i++ {
+		if got := pickExperimentTemplate(templates, "cs_token_1"); *got != *first {
+			t.Fatalf("template changed for the same session: %s != %s", got, first)
+		}
+	}
+
+	// 多个会话大致均匀分流
+	counts := map[uuid.UUID]int{}
+	for i := 0; i < 1000; i++ {
+		counts[*pickExperimentTemplate(templates, uuid.NewString())]++
+	}
+	for _, id := range templates {
+		if n := counts[uuid.MustParse(id)]; n < 400 || n > 600 {
+			t.Fatalf("template %s got %d of 1000 sessions", id, n)
+		}
+	}
+
+	if pickExperimentTemplate(nil, "cs_token_1") != nil {
+		t.Fatal("expected nil without templates")
+	}
+}
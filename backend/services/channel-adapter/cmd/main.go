@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -11,7 +12,9 @@ import (
 	"github.com/payment-platform/pkg/auth"
 	"github.com/payment-platform/pkg/config"
 	"github.com/payment-platform/pkg/configclient"
+	"github.com/payment-platform/pkg/crypto"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/middleware"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -99,6 +102,11 @@ func main() {
 			&model.ExchangeRate{},
 			&model.ExchangeRateSnapshot{},
 			&model.PreAuthRecord{},
+			&model.CryptoDeposit{},
+			&model.FXQuote{},
+			&model.FXMarkup{},
+			&model.ChannelCredential{},
+			&model.ChannelCredentialAudit{},
 		},
 
 		// 启用企业级功能
@@ -176,17 +184,30 @@ func main() {
 	exchangeRateRepo := repository.NewExchangeRateRepository(application.DB, application.Redis)
 
 	// 7. 初始化汇率客户端（用于 Crypto 适配器的法币转换）
+	// API 不可用时最近一次快照的最大可用时长（默认6小时），超过后拒绝换汇而不是使用静态汇率
 	exchangeRateCacheTTL := time.Duration(config.GetEnvInt("EXCHANGE_RATE_CACHE_TTL", 3600)) * time.Second
-	exchangeRateClient := client.NewExchangeRateClient(application.Redis, exchangeRateRepo, exchangeRateCacheTTL)
+	exchangeRateMaxAge := time.Duration(config.GetEnvInt("EXCHANGE_RATE_MAX_AGE", 21600)) * time.Second
+	exchangeRateClient := client.NewExchangeRateClient(application.Redis, exchangeRateRepo, exchangeRateCacheTTL, exchangeRateMaxAge)
 	logger.Info("汇率客户端初始化完成 (exchangerate-api.com + 历史存储)")
 
 	// 8. 启动汇率定期更新任务（默认每2小时更新一次）
 	exchangeRateUpdateInterval := time.Duration(config.GetEnvInt("EXCHANGE_RATE_UPDATE_INTERVAL", 7200)) * time.Second
 	exchangeRateClient.StartPeriodicUpdate(context.Background(), exchangeRateUpdateInterval)
 
-	// 9. 注册加密货币适配器（可选，优先从配置中心获取）
-	cryptoWallet := getConfig("CRYPTO_WALLET_ADDRESS", "")
-	if cryptoWallet != "" {
+	// 9. 初始化Repository
+	channelRepo := repository.NewChannelRepository(application.DB)
+	preAuthRepo := repository.NewPreAuthRepository(application.DB)
+	cryptoDepositRepo := repository.NewCryptoDepositRepository(application.DB)
+	fxQuoteRepo := repository.NewFXQuoteRepository(application.DB)
+	credentialRepo := repository.NewCredentialRepository(application.DB)
+
+	// 10. 注册加密货币适配器（可选，优先从配置中心获取）
+	// 每笔支付从 xpub 派生独立收款地址，私钥离线保管
+	cryptoXPubs := map[string]string{
+		"evm":  getConfig("CRYPTO_XPUB_EVM", ""),
+		"tron": getConfig("CRYPTO_XPUB_TRON", ""),
+	}
+	if cryptoXPubs["evm"] != "" || cryptoXPubs["tron"] != "" {
 		// 解析支持的网络列表
 		networksStr := getConfig("CRYPTO_NETWORKS", "ETH,BSC,TRON")
 		networks := []string{}
@@ -194,38 +215,144 @@ func main() {
 			networks = append(networks, strings.TrimSpace(network))
 		}
 
+		// RPC 端点格式：ETH=https://...,BSC=https://...,TRON=https://.../jsonrpc
+		rpcEndpoints := map[string]string{}
+		for _, item := range strings.Split(getConfig("CRYPTO_RPC_ENDPOINTS", ""), ",") {
+			if parts := strings.SplitN(strings.TrimSpace(item), "=", 2); len(parts) == 2 {
+				rpcEndpoints[strings.ToUpper(parts[0])] = parts[1]
+			}
+		}
+
+		// 代币配置（JSON 数组），未配置时使用主网 USDT/USDC 合约
+		var tokens []model.CryptoToken
+		if tokensJSON := getConfig("CRYPTO_TOKENS", ""); tokensJSON != "" {
+			if err := json.Unmarshal([]byte(tokensJSON), &tokens); err != nil {
+				logger.Fatal(fmt.Sprintf("CRYPTO_TOKENS 配置无效: %v", err))
+			}
+		}
+
 		cryptoConfig := &model.CryptoConfig{
-			WalletAddress: cryptoWallet,
-			Networks:      networks,
-			Confirmations: config.GetEnvInt("CRYPTO_CONFIRMATIONS", 12),
-			APIEndpoint:   getConfig("CRYPTO_API_ENDPOINT", ""),
-			APIKey:        getConfig("CRYPTO_API_KEY", ""),
+			Networks:       networks,
+			Confirmations:  config.GetEnvInt("CRYPTO_CONFIRMATIONS", 12),
+			XPubs:          cryptoXPubs,
+			RPCEndpoints:   rpcEndpoints,
+			Tokens:         tokens,
+			PaymentTimeout: time.Duration(config.GetEnvInt("CRYPTO_PAYMENT_TIMEOUT", 1800)) * time.Second,
+			ToleranceBps:   int64(config.GetEnvInt("CRYPTO_PAYMENT_TOLERANCE_BPS", 50)),
+		}
+		cryptoAdapter, err := adapter.NewCryptoAdapter(cryptoConfig, exchangeRateClient, cryptoDepositRepo)
+		if err != nil {
+			logger.Error(fmt.Sprintf("创建 Crypto 适配器失败: %v", err))
+		} else {
+			adapterFactory.Register(model.ChannelCrypto, cryptoAdapter)
+			logger.Info(fmt.Sprintf("Crypto 适配器已注册，支持网络: %s", networksStr))
 		}
-		cryptoAdapter := adapter.NewCryptoAdapter(cryptoConfig, exchangeRateClient)
-		adapterFactory.Register(model.ChannelCrypto, cryptoAdapter)
-		logger.Info(fmt.Sprintf("Crypto 适配器已注册，支持网络: %s", networksStr))
 	}
 
-	// 10. 初始化Repository
-	channelRepo := repository.NewChannelRepository(application.DB)
-	preAuthRepo := repository.NewPreAuthRepository(application.DB)
-
 	// 11. 初始化Service
 	channelService := service.NewChannelService(channelRepo, preAuthRepo, adapterFactory)
 
+	// 渠道级熔断与健康监控：健康状态上报到 Redis，payment-gateway 路由据此自动摘流
+	healthConfig := service.DefaultHealthMonitorConfig()
+	healthConfig.OpenTimeout = time.Duration(config.GetEnvInt("CHANNEL_BREAKER_OPEN_TIMEOUT", 30)) * time.Second
+	healthConfig.PublishInterval = time.Duration(config.GetEnvInt("CHANNEL_HEALTH_PUBLISH_INTERVAL", 10)) * time.Second
+	healthMonitor := service.NewChannelHealthMonitor(application.Redis, healthConfig)
+	if cs, ok := channelService.(interface {
+		SetHealthMonitor(*service.ChannelHealthMonitor)
+	}); ok {
+		cs.SetHealthMonitor(healthMonitor)
+	}
+	healthMonitor.StartPublishing(context.Background())
+	fxService := service.NewFXService(fxQuoteRepo, exchangeRateClient, service.FXConfig{
+		QuoteTTL:         time.Duration(config.GetEnvInt("FX_QUOTE_TTL", 300)) * time.Second,
+		DefaultMarkupBps: config.GetEnvInt("FX_DEFAULT_MARKUP_BPS", 0),
+	})
+
 	// 12. 初始化Handler
 	channelHandler := handler.NewChannelHandler(channelService)
-	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateRepo)
+	channelHealthHandler := handler.NewChannelHealthHandler(healthMonitor)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateRepo, fxService)
+	fxHandler := handler.NewFXHandler(fxService)
 
 	// 13. Swagger UI
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// 14. 注册渠道路由
 	channelHandler.RegisterRoutes(application.Router)
+	channelHealthHandler.RegisterRoutes(application.Router)
 
-	// 15. 注册汇率路由
+	// 15. 注册汇率路由（换汇报价路由需要服务间认证，在 JWT 初始化后注册）
 	exchangeRateHandler.RegisterRoutes(application.Router)
 
+	// 商户渠道凭证：按解密后的凭证创建适配器（回调地址等非敏感配置沿用平台配置）
+	adapterFactory.RegisterBuilder(model.ChannelStripe, func(mode string, secret []byte) (adapter.PaymentAdapter, error) {
+		cfg := &model.StripeConfig{}
+		if err := json.Unmarshal(secret, cfg); err != nil {
+			return nil, err
+		}
+		if cfg.StatementDescriptor == "" {
+			cfg.StatementDescriptor = stripeConfig.StatementDescriptor
+		}
+		if cfg.CaptureMethod == "" {
+			cfg.CaptureMethod = stripeConfig.CaptureMethod
+		}
+		return adapter.NewStripeAdapter(cfg), nil
+	})
+	adapterFactory.RegisterBuilder(model.ChannelPayPal, func(mode string, secret []byte) (adapter.PaymentAdapter, error) {
+		cfg := &model.PayPalConfig{}
+		if err := json.Unmarshal(secret, cfg); err != nil {
+			return nil, err
+		}
+		if cfg.Mode == "" {
+			cfg.Mode = "live"
+			if mode == model.ModeTest {
+				cfg.Mode = "sandbox"
+			}
+		}
+		return adapter.NewPayPalAdapter(cfg), nil
+	})
+	adapterFactory.RegisterBuilder(model.ChannelAlipay, func(mode string, secret []byte) (adapter.PaymentAdapter, error) {
+		cfg := &model.AlipayConfig{
+			NotifyURL:  getConfig("ALIPAY_NOTIFY_URL", ""),
+			ReturnURL:  getConfig("ALIPAY_RETURN_URL", ""),
+			SignType:   "RSA2",
+			Format:     "json",
+			Charset:    "utf-8",
+			APIGateway: getConfig("ALIPAY_API_GATEWAY", "https://openapi.alipay.com/gateway.do"),
+		}
+		if err := json.Unmarshal(secret, cfg); err != nil {
+			return nil, err
+		}
+		return adapter.NewAlipayAdapter(cfg)
+	})
+	adapterFactory.RegisterBuilder(model.ChannelCrypto, func(mode string, secret []byte) (adapter.PaymentAdapter, error) {
+		cfg := &model.CryptoConfig{}
+		if err := json.Unmarshal(secret, cfg); err != nil {
+			return nil, err
+		}
+		return adapter.NewCryptoAdapter(cfg, exchangeRateClient, cryptoDepositRepo)
+	})
+
+	// 渠道凭证管理（信封加密）：KEK 格式 key_id:base64密钥，多个以逗号分隔，轮换时新增 KEK 并切换 KEK_ID
+	var credentialHandler *handler.CredentialHandler
+	keyring, err := crypto.ParseKeyring(getConfig("CHANNEL_CREDENTIAL_KEK_ID", ""), getConfig("CHANNEL_CREDENTIAL_KEKS", ""))
+	if err != nil {
+		logger.Warn("渠道凭证 KEK 未配置，凭证管理接口未启用，所有商户使用平台配置", zap.Error(err))
+	} else {
+		credentialService := service.NewCredentialService(credentialRepo, keyring)
+		credentialHandler = handler.NewCredentialHandler(credentialService)
+
+		// 渠道调用优先使用商户使用中的凭证版本
+		if cs, ok := channelService.(interface {
+			SetCredentialResolver(service.CredentialResolver)
+		}); ok {
+			cs.SetCredentialResolver(credentialService)
+		}
+
+		// 渠道配置中的明文凭证已废弃，由 cmd/migrate-credentials 一次性导入并激活后清空明文
+		logger.Info(fmt.Sprintf("渠道凭证管理已启用，当前 KEK: %s", keyring.ActiveKeyID()))
+	}
+
 	// 16. gRPC 服务（预留但不启用，系统使用 HTTP/REST 通信）
 	// channelGrpcServer := grpcServer.NewChannelServer(channelService)
 	// pb.RegisterChannelServiceServer(application.GRPCServer, channelGrpcServer)
@@ -244,7 +371,19 @@ func main() {
 	}
 	logger.Info("JWT_SECRET validation passed", zap.Int("length", len(jwtSecret)))
 	jwtManager := auth.NewJWTManager(jwtSecret, 24*time.Hour)
-	_ = jwtManager // 预留给需要认证的路由使用
+
+	// 渠道凭证管理接口仅允许管理员访问，审计日志的操作人取自 JWT
+	if credentialHandler != nil {
+		credentialHandler.RegisterRoutes(application.Router.Group("", middleware.AuthMiddleware(jwtManager), middleware.RequireAdminType()))
+	}
+
+	// 换汇报价仅供内部服务调用（锁定、释放报价会占用和回收报价），设置商户加点还需管理员身份
+	internalServiceToken := getConfig("INTERNAL_SERVICE_TOKEN", "")
+	if internalServiceToken == "" {
+		logger.Warn("INTERNAL_SERVICE_TOKEN 未配置，换汇报价路由仅接受 mTLS 客户端证书")
+	}
+	fxServiceAuth := middleware.ServiceAuthMiddleware(internalServiceToken, "payment-gateway", "accounting-service", "admin-bff-service")
+	fxHandler.RegisterRoutes(application.Router.Group("", fxServiceAuth), middleware.AuthMiddleware(jwtManager), middleware.RequireAdminType())
 
 
 
@@ -0,0 +1,62 @@
+// migrate-credentials 将渠道配置中的明文凭证一次性迁移为加密的渠道凭证版本
+//
+// 用法:
+//
+//	migrate-credentials
+//
+// 每个明文配置依次导入、向渠道校验并激活，确认使用中的版本存在后才清空明文；
+// 失败的配置保留明文，修正后可重复执行。会向渠道发起校验调用，只需在一个实例上执行。
+// 数据库使用与服务相同的 DB_* 环境变量，KEK 使用 CHANNEL_CREDENTIAL_KEK_ID / CHANNEL_CREDENTIAL_KEKS。
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/payment-platform/pkg/config"
+	"github.com/payment-platform/pkg/crypto"
+	"github.com/payment-platform/pkg/db"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+	"payment-platform/channel-adapter/internal/service"
+)
+
+func main() {
+	if err := logger.InitLogger(config.GetEnv("ENV", "development")); err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+	defer logger.Sync()
+
+	keyring, err := crypto.ParseKeyring(config.GetEnv("CHANNEL_CREDENTIAL_KEK_ID", ""), config.GetEnv("CHANNEL_CREDENTIAL_KEKS", ""))
+	if err != nil {
+		logger.Fatal("渠道凭证 KEK 未配置", zap.Error(err))
+	}
+
+	database, err := db.NewPostgresDB(db.Config{
+		Host:     config.GetEnv("DB_HOST", "localhost"),
+		Port:     config.GetEnvInt("DB_PORT", 40432),
+		User:     config.GetEnv("DB_USER", "postgres"),
+		Password: config.GetEnv("DB_PASSWORD", "postgres"),
+		DBName:   config.GetEnv("DB_NAME", "payment_channel"),
+		SSLMode:  config.GetEnv("DB_SSL_MODE", "disable"),
+		TimeZone: config.GetEnv("DB_TIMEZONE", "UTC"),
+	})
+	if err != nil {
+		logger.Fatal("连接数据库失败", zap.Error(err))
+	}
+	if err := database.AutoMigrate(&model.ChannelCredential{}, &model.ChannelCredentialAudit{}); err != nil {
+		logger.Fatal("数据库迁移失败", zap.Error(err))
+	}
+
+	credentialService := service.NewCredentialService(repository.NewCredentialRepository(database), keyring)
+	result, err := service.MigratePlaintextConfigs(context.Background(), repository.NewChannelRepository(database), credentialService)
+	if err != nil {
+		logger.Fatal("迁移明文渠道配置失败", zap.Error(err))
+	}
+
+	fmt.Printf("迁移完成: 已迁移并激活 %d 个，已有使用中凭证直接清空 %d 个，保留明文待处理 %d 个\n",
+		result.Migrated, result.Cleared, result.Skipped)
+}
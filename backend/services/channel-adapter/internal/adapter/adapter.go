@@ -2,6 +2,11 @@ package adapter
 
 import (
 	"context"
+	"sync"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/sca"
 )
 
 // PaymentAdapter 支付适配器接口
@@ -44,6 +49,15 @@ type PaymentAdapter interface {
 
 	// QueryPreAuth 查询预授权状态
 	QueryPreAuth(ctx context.Context, channelPreAuthNo string) (*QueryPreAuthResponse, error)
+
+	// IncrementPreAuth 增量授权（在原预授权上提高冻结金额，可能被发卡行拒绝）
+	IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error)
+
+	// ReauthorizePreAuth 重新授权（授权即将到期时重新冻结剩余金额，可能返回新的渠道预授权号）
+	ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error)
+
+	// CompleteAuthentication 消费者完成 3DS 挑战返回后继续支付，返回认证结果（可选，不是所有渠道都支持）
+	CompleteAuthentication(ctx context.Context, req *CompleteAuthenticationRequest) (*CompleteAuthenticationResponse, error)
 }
 
 // CreatePaymentRequest 创建支付请求
@@ -58,6 +72,8 @@ type CreatePaymentRequest struct {
 	SuccessURL    string                 `json:"success_url"`     // 成功跳转URL
 	CancelURL     string                 `json:"cancel_url"`      // 取消跳转URL
 	CallbackURL   string                 `json:"callback_url"`    // 回调URL
+	PayMethod     string                 `json:"pay_method"`      // 支付方式：card, wallet 等
+	ThreeDS       *sca.Request           `json:"three_ds"`        // 3DS 要求（nil 表示按渠道默认行为）
 	Extra         map[string]interface{} `json:"extra"`           // 扩展信息
 }
 
@@ -68,6 +84,25 @@ type CreatePaymentResponse struct {
 	PaymentURL     string                 `json:"payment_url"`      // 支付URL（重定向方式）
 	QRCodeURL      string                 `json:"qr_code_url"`      // 二维码URL
 	Status         string                 `json:"status"`           // 状态
+	NextAction     *sca.NextAction        `json:"next_action"`      // 消费者需要完成的验证动作（status 为 requires_action 时）
+	Authentication *sca.Result            `json:"authentication"`   // 3DS 认证结果（无挑战直接完成时）
+	Extra          map[string]interface{} `json:"extra"`            // 扩展信息
+}
+
+// CompleteAuthenticationRequest 3DS 挑战完成后继续支付请求
+type CompleteAuthenticationRequest struct {
+	PaymentNo      string                 `json:"payment_no"`       // 平台支付流水号
+	ChannelTradeNo string                 `json:"channel_trade_no"` // 渠道交易号
+	Extra          map[string]interface{} `json:"extra"`            // 扩展信息
+}
+
+// CompleteAuthenticationResponse 3DS 挑战完成后继续支付响应
+type CompleteAuthenticationResponse struct {
+	ChannelTradeNo string                 `json:"channel_trade_no"` // 渠道交易号
+	Status         string                 `json:"status"`           // 支付状态（仍为 requires_action 表示消费者尚未完成挑战）
+	NextAction     *sca.NextAction        `json:"next_action"`      // 仍需完成的验证动作
+	Authentication *sca.Result            `json:"authentication"`   // 3DS 认证结果
+	Decline        *decline.Decline       `json:"decline"`          // 认证失败或发卡行拒绝时的拒绝原因
 	Extra          map[string]interface{} `json:"extra"`            // 扩展信息
 }
 
@@ -142,6 +177,7 @@ type CapturePreAuthRequest struct {
 	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"` // 渠道预授权号
 	Amount           int64                  `json:"amount"`              // 确认金额（分，可小于等于预授权金额）
 	Currency         string                 `json:"currency"`            // 货币
+	FinalCapture     *bool                  `json:"final_capture"`       // 是否最后一次确认（nil 视为 true，最后一次确认后剩余冻结金额自动释放）
 	Description      string                 `json:"description"`         // 描述
 	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
 }
@@ -151,7 +187,9 @@ type CapturePreAuthResponse struct {
 	ChannelTradeNo   string                 `json:"channel_trade_no"`    // 支付交易号
 	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"` // 渠道预授权号
 	Status           string                 `json:"status"`              // 状态
-	Amount           int64                  `json:"amount"`              // 实际扣款金额
+	Amount           int64                  `json:"amount"`              // 本次实际扣款金额
+	CapturedAmount   int64                  `json:"captured_amount"`     // 累计已扣款金额
+	CapturableAmount int64                  `json:"capturable_amount"`   // 剩余可确认金额（最后一次确认后为 0）
 	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
 }
 
@@ -182,6 +220,46 @@ type QueryPreAuthResponse struct {
 	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
 }
 
+// IncrementPreAuthRequest 增量授权请求
+type IncrementPreAuthRequest struct {
+	PreAuthNo        string                 `json:"pre_auth_no"`         // 预授权流水号
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"` // 渠道预授权号
+	Amount           int64                  `json:"amount"`              // 增量后的授权总金额（分，必须大于当前授权金额）
+	Currency         string                 `json:"currency"`            // 货币
+	Description      string                 `json:"description"`         // 描述
+	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
+}
+
+// IncrementPreAuthResponse 增量授权响应
+type IncrementPreAuthResponse struct {
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"` // 渠道预授权号
+	Status           string                 `json:"status"`              // 状态
+	Amount           int64                  `json:"amount"`              // 当前授权总金额
+	CapturableAmount int64                  `json:"capturable_amount"`   // 剩余可确认金额
+	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
+}
+
+// ReauthorizePreAuthRequest 重新授权请求
+type ReauthorizePreAuthRequest struct {
+	PreAuthNo        string                 `json:"pre_auth_no"`         // 预授权流水号
+	OrderNo          string                 `json:"order_no"`            // 订单号
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"` // 原渠道预授权号
+	Amount           int64                  `json:"amount"`              // 重新冻结的金额（分，通常为剩余可确认金额）
+	Currency         string                 `json:"currency"`            // 货币
+	Description      string                 `json:"description"`         // 描述
+	Extra            map[string]interface{} `json:"extra"`               // 扩展信息
+}
+
+// ReauthorizePreAuthResponse 重新授权响应
+type ReauthorizePreAuthResponse struct {
+	ChannelPreAuthNo         string                 `json:"channel_pre_auth_no"`          // 新的渠道预授权号（渠道原地延期时与原单号相同）
+	PreviousChannelPreAuthNo string                 `json:"previous_channel_pre_auth_no"` // 原渠道预授权号
+	Status                   string                 `json:"status"`                       // 状态
+	Amount                   int64                  `json:"amount"`                       // 重新冻结的金额
+	ExpiresAt                *int64                 `json:"expires_at"`                   // 新授权的过期时间
+	Extra                    map[string]interface{} `json:"extra"`                        // 扩展信息
+}
+
 // WebhookEvent Webhook 事件
 type WebhookEvent struct {
 	EventID        string                 `json:"event_id"`         // 事件ID
@@ -192,18 +270,26 @@ type WebhookEvent struct {
 	Amount         int64                  `json:"amount"`           // 金额（分）
 	Currency       string                 `json:"currency"`         // 货币
 	Extra          map[string]interface{} `json:"extra"`            // 扩展信息
+	Decline        *decline.Decline       `json:"decline,omitempty"` // 归一化拒绝原因（支付失败事件）
 	RawData        interface{}            `json:"raw_data"`         // 原始数据
 }
 
 // AdapterFactory 适配器工厂
+//
+// adapters 为使用平台配置创建的适配器；商户有使用中的渠道凭证时通过 builders 按凭证创建适配器（见 Build）
 type AdapterFactory struct {
 	adapters map[string]PaymentAdapter
+	builders map[string]AdapterBuilder
+	mu       sync.Mutex
+	built    map[string]PaymentAdapter // 按渠道+凭证指纹缓存
 }
 
 // NewAdapterFactory 创建适配器工厂
 func NewAdapterFactory() *AdapterFactory {
 	return &AdapterFactory{
 		adapters: make(map[string]PaymentAdapter),
+		builders: make(map[string]AdapterBuilder),
+		built:    make(map[string]PaymentAdapter),
 	}
 }
 
@@ -220,12 +306,14 @@ func (f *AdapterFactory) GetAdapter(channel string) (PaymentAdapter, bool) {
 
 // 支付状态常量（统一状态）
 const (
-	PaymentStatusPending    = "pending"    // 待支付
-	PaymentStatusProcessing = "processing" // 处理中
-	PaymentStatusSuccess    = "success"    // 成功
-	PaymentStatusFailed     = "failed"     // 失败
-	PaymentStatusCancelled  = "cancelled"  // 已取消
-	PaymentStatusRefunded   = "refunded"   // 已退款
+	PaymentStatusPending        = "pending"         // 待支付
+	PaymentStatusRequiresAction = "requires_action" // 待消费者完成身份验证（3DS/SCA）
+	PaymentStatusProcessing     = "processing"      // 处理中
+	PaymentStatusAuthorized     = "authorized"      // 已授权待确认（预授权）
+	PaymentStatusSuccess        = "success"         // 成功
+	PaymentStatusFailed         = "failed"          // 失败
+	PaymentStatusCancelled      = "cancelled"       // 已取消
+	PaymentStatusRefunded       = "refunded"        // 已退款
 )
 
 // Webhook 事件类型常量
@@ -236,3 +324,25 @@ const (
 	EventTypeRefundSuccess    = "refund.success"     // 退款成功
 	EventTypeRefundFailed     = "refund.failed"      // 退款失败
 )
+
+// wrapDecline 为渠道错误附加归一化拒绝原因（由各适配器按渠道错误码映射），d 为 nil 时原样返回
+func wrapDecline(d *decline.Decline, err error) error {
+	if d == nil {
+		return err
+	}
+	return decline.Wrap(d, err)
+}
+
+// parseChannelAmount 将渠道返回的主单位金额字符串（如 "19.99"）转换为最小货币单位
+// 按十进制解析，避免 float64 乘以 100 时 19.99 变成 1998 的误差，解析失败返回 0
+func parseChannelAmount(value, currency string) int64 {
+	major, err := money.ParseDecimal(value)
+	if err != nil {
+		return 0
+	}
+	m, err := money.FromDecimal(major, currency, money.RoundHalfEven)
+	if err != nil {
+		return 0
+	}
+	return m.Amount
+}
@@ -18,12 +18,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/money"
 	"payment-platform/channel-adapter/internal/model"
 )
 
 // AlipayAdapter 支付宝支付适配器
 type AlipayAdapter struct {
-	DefaultPreAuthNotSupported  // 嵌入默认预授权实现
+	DefaultPreAuthNotSupported        // 嵌入默认预授权实现
+	DefaultAuthenticationNotSupported // 嵌入默认 3DS 实现
 	config      *model.AlipayConfig
 	httpClient  *http.Client
 	privateKey  *rsa.PrivateKey
@@ -202,7 +205,7 @@ func (a *AlipayAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 	// 构建业务参数
 	bizContent := map[string]interface{}{
 		"out_trade_no": req.PaymentNo,
-		"total_amount": fmt.Sprintf("%.2f", float64(req.Amount)/100.0),
+		"total_amount": money.New(req.Amount, "CNY").FormatMajor(),
 		"subject":      req.Description,
 		"product_code": "FAST_INSTANT_TRADE_PAY",
 	}
@@ -233,7 +236,7 @@ func (a *AlipayAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 	if code != "10000" {
 		msg, _ := respData["msg"].(string)
 		subMsg, _ := respData["sub_msg"].(string)
-		return nil, fmt.Errorf("支付宝接口返回错误: %s - %s", msg, subMsg)
+		return nil, wrapDecline(alipayDecline(respData), fmt.Errorf("支付宝接口返回错误: %s - %s", msg, subMsg))
 	}
 
 	tradeNo, _ := respData["trade_no"].(string)
@@ -297,14 +300,10 @@ func (a *AlipayAdapter) QueryPayment(ctx context.Context, channelTradeNo string)
 	tradeStatus, _ := respData["trade_status"].(string)
 	totalAmount, _ := respData["total_amount"].(string)
 
-	// 将金额字符串转换为分
-	var amountFloat float64
-	fmt.Sscanf(totalAmount, "%f", &amountFloat)
-
 	response := &QueryPaymentResponse{
 		ChannelTradeNo: tradeNo,
 		Status:         convertAlipayStatus(tradeStatus),
-		Amount:         int64(amountFloat * 100),
+		Amount:         parseChannelAmount(totalAmount, "CNY"),
 		Currency:       "CNY",
 		PaymentMethod:  "alipay",
 	}
@@ -355,7 +354,7 @@ func (a *AlipayAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 	// 构建业务参数
 	bizContent := map[string]interface{}{
 		"trade_no":      req.ChannelTradeNo,
-		"refund_amount": fmt.Sprintf("%.2f", float64(req.Amount)/100.0),
+		"refund_amount": money.New(req.Amount, "CNY").FormatMajor(),
 		"refund_reason": req.Reason,
 		"out_request_no": req.RefundNo,
 	}
@@ -419,14 +418,10 @@ func (a *AlipayAdapter) QueryRefund(ctx context.Context, refundNo string) (*Quer
 
 	refundAmount, _ := respData["refund_amount"].(string)
 
-	// 将金额字符串转换为分
-	var amountFloat float64
-	fmt.Sscanf(refundAmount, "%f", &amountFloat)
-
 	response := &QueryRefundResponse{
 		ChannelRefundNo: refundNo,
 		Status:          PaymentStatusRefunded,
-		Amount:          int64(amountFloat * 100),
+		Amount:          parseChannelAmount(refundAmount, "CNY"),
 		Currency:        "CNY",
 	}
 
@@ -494,9 +489,7 @@ func (a *AlipayAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 
 	// 提取金额信息
 	if totalAmount := params["total_amount"]; totalAmount != "" {
-		var amountFloat float64
-		fmt.Sscanf(totalAmount, "%f", &amountFloat)
-		webhookEvent.Amount = int64(amountFloat * 100)
+		webhookEvent.Amount = parseChannelAmount(totalAmount, "CNY")
 	}
 
 	webhookEvent.Currency = "CNY"
@@ -530,6 +523,50 @@ func convertAlipayEventType(status string) string {
 	}
 }
 
+// alipayDeclineCodes 支付宝错误码到拒绝分类的映射（业务错误码 sub_code，以及无 sub_code 时的公共错误码 code）
+var alipayDeclineCodes = decline.CodeTable{
+	"ACQ.BUYER_BALANCE_NOT_ENOUGH":               decline.CategoryInsufficientFunds,
+	"ACQ.BUYER_BANKCARD_BALANCE_NOT_ENOUGH":      decline.CategoryInsufficientFunds,
+	"ACQ.PAYMENT_AUTH_CODE_INVALID":              decline.CategoryInvalidCard,
+	"ACQ.BUYER_NOT_EXIST":                        decline.CategoryInvalidCard,
+	"ACQ.PAYMENT_REQUEST_HAS_RISK":               decline.CategoryFraudSuspected,
+	"ACQ.BUYER_ENABLE_STATUS_FORBID":             decline.CategoryRestricted,
+	"ACQ.ERROR_BALANCE_PAYMENT_DISABLE":          decline.CategoryRestricted,
+	"ACQ.MOBILE_PAYMENT_SWITCH_OFF":              decline.CategoryRestricted,
+	"ACQ.ACCESS_FORBIDDEN":                       decline.CategoryRestricted,
+	"ACQ.TOTAL_FEE_EXCEED":                       decline.CategoryLimitExceeded,
+	"ACQ.PRODUCT_AMOUNT_LIMIT_ERROR":             decline.CategoryLimitExceeded,
+	"ACQ.BEYOND_PAY_RESTRICTION":                 decline.CategoryLimitExceeded,
+	"ACQ.BEYOND_PER_RECEIPT_RESTRICTION":         decline.CategoryLimitExceeded,
+	"ACQ.BUYER_PAYMENT_AMOUNT_DAY_LIMIT_ERROR":   decline.CategoryLimitExceeded,
+	"ACQ.BUYER_PAYMENT_AMOUNT_MONTH_LIMIT_ERROR": decline.CategoryLimitExceeded,
+	"ACQ.PAYMENT_FAIL":                           decline.CategoryDoNotHonor,
+	"ACQ.SYSTEM_ERROR":                           decline.CategoryTimeout, // 支付宝要求先查询交易结果，不能直接重试
+	"ACQ.TRADE_HAS_SUCCESS":                      decline.CategoryDuplicate,
+	"ACQ.CONTEXT_INCONSISTENT":                   decline.CategoryDuplicate,
+	"ACQ.TRADE_HAS_CLOSE":                        decline.CategoryInvalidRequest,
+	"ACQ.BUYER_SELLER_EQUAL":                     decline.CategoryInvalidRequest,
+	"ACQ.INVALID_PARAMETER":                      decline.CategoryInvalidRequest,
+	"ACQ.EXIST_FORBIDDEN_WORD":                   decline.CategoryInvalidRequest,
+	"ACQ.PARTNER_ERROR":                          decline.CategoryInvalidRequest,
+	"20000":                                      decline.CategoryChannelUnavailable, // 服务不可用
+	"20001":                                      decline.CategoryInvalidRequest,     // 授权权限不足
+	"40001":                                      decline.CategoryInvalidRequest,     // 缺少必选参数
+	"40002":                                      decline.CategoryInvalidRequest,     // 非法的参数
+	"40006":                                      decline.CategoryRestricted,         // 权限不足
+}
+
+// alipayDecline 将支付宝接口错误归一化为拒绝原因（sub_code 优先，其次 code）
+func alipayDecline(respData map[string]interface{}) *decline.Decline {
+	code, _ := respData["sub_code"].(string)
+	message, _ := respData["sub_msg"].(string)
+	if code == "" {
+		code, _ = respData["code"].(string)
+		message, _ = respData["msg"].(string)
+	}
+	return alipayDeclineCodes.Classify(model.ChannelAlipay, code, message)
+}
+
 // parsePrivateKey 解析私钥
 func parsePrivateKey(privateKeyStr string) (*rsa.PrivateKey, error) {
 	// 移除头尾标记
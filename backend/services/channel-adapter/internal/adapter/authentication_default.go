@@ -0,0 +1,15 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultAuthenticationNotSupported 3DS 默认实现（不支持）
+// 不支持卡支付或由渠道收银台自行完成身份验证的 adapter 可以嵌入这个结构体
+
+type DefaultAuthenticationNotSupported struct{}
+
+func (d *DefaultAuthenticationNotSupported) CompleteAuthentication(ctx context.Context, req *CompleteAuthenticationRequest) (*CompleteAuthenticationResponse, error) {
+	return nil, fmt.Errorf("当前支付渠道不支持 3DS 验证")
+}
@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+// AdapterBuilder 使用渠道配置 JSON（model.StripeConfig 等）创建适配器，mode 为凭证所属模式（test、live）
+type AdapterBuilder func(mode string, secret []byte) (PaymentAdapter, error)
+
+// RegisterBuilder 注册按商户凭证创建适配器的构造函数
+func (f *AdapterFactory) RegisterBuilder(channel string, builder AdapterBuilder) {
+	f.builders[channel] = builder
+}
+
+// Build 按商户凭证创建适配器，相同凭证复用已创建的实例（轮换后新凭证指纹不同，自动创建新实例）
+func (f *AdapterFactory) Build(channel, mode string, secret []byte) (PaymentAdapter, error) {
+	builder, ok := f.builders[channel]
+	if !ok {
+		return nil, fmt.Errorf("渠道 %s 不支持商户凭证", channel)
+	}
+
+	sum := sha256.Sum256(secret)
+	key := channel + ":" + mode + ":" + hex.EncodeToString(sum[:])
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if a, ok := f.built[key]; ok {
+		return a, nil
+	}
+	a, err := builder(mode, secret)
+	if err != nil {
+		return nil, err
+	}
+	f.built[key] = a
+	return a, nil
+}
+
+// credentialErrorMarkers 各渠道凭证无效/被吊销时的错误标识
+var credentialErrorMarkers = []string{
+	"invalid_client",         // PayPal OAuth
+	"authentication_failure", // PayPal
+	"isv.invalid-app-id",     // 支付宝
+	"isv.invalid-signature",  // 支付宝
+	"isv.app-unauthorized",   // 支付宝
+	"invalid api key",        // Stripe
+	"expired api key",        // Stripe
+}
+
+// IsCredentialError 判断渠道错误是否为凭证无效（认证失败的请求不会在渠道侧产生交易，可换用其他凭证版本重试）
+func IsCredentialError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var stripeErr *stripe.Error
+	if errors.As(err, &stripeErr) {
+		return stripeErr.HTTPStatusCode == 401
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range credentialErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
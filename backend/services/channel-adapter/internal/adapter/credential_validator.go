@@ -0,0 +1,152 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stripe/stripe-go/v76"
+	stripeclient "github.com/stripe/stripe-go/v76/client"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// alipayDefaultGateway 支付宝正式环境网关
+const alipayDefaultGateway = "https://openapi.alipay.com/gateway.do"
+
+// ValidateCredentials 用候选凭证对渠道发起一次只读的认证调用，校验通过后凭证才允许激活
+//
+// secret 为对应渠道的配置 JSON（model.StripeConfig、PayPalConfig、AlipayConfig、CryptoConfig），
+// mode 为 test 或 live，用于检查凭证与环境是否匹配。
+func ValidateCredentials(ctx context.Context, channel, mode string, secret []byte) error {
+	switch channel {
+	case model.ChannelStripe:
+		var cfg model.StripeConfig
+		if err := json.Unmarshal(secret, &cfg); err != nil {
+			return fmt.Errorf("Stripe 凭证格式错误: %w", err)
+		}
+		return validateStripeCredentials(ctx, mode, &cfg)
+	case model.ChannelPayPal:
+		var cfg model.PayPalConfig
+		if err := json.Unmarshal(secret, &cfg); err != nil {
+			return fmt.Errorf("PayPal 凭证格式错误: %w", err)
+		}
+		return validatePayPalCredentials(ctx, mode, &cfg)
+	case model.ChannelAlipay:
+		var cfg model.AlipayConfig
+		if err := json.Unmarshal(secret, &cfg); err != nil {
+			return fmt.Errorf("支付宝凭证格式错误: %w", err)
+		}
+		return validateAlipayCredentials(ctx, &cfg)
+	case model.ChannelCrypto:
+		var cfg model.CryptoConfig
+		if err := json.Unmarshal(secret, &cfg); err != nil {
+			return fmt.Errorf("加密货币凭证格式错误: %w", err)
+		}
+		return validateCryptoCredentials(ctx, &cfg)
+	default:
+		return fmt.Errorf("不支持的支付渠道: %s", channel)
+	}
+}
+
+// validateStripeCredentials 查询账户余额（只读）确认密钥有效
+func validateStripeCredentials(ctx context.Context, mode string, cfg *model.StripeConfig) error {
+	if cfg.APIKey == "" {
+		return fmt.Errorf("api_key 不能为空")
+	}
+	// 密钥前缀区分环境：sk_test_/rk_test_ 与 sk_live_/rk_live_
+	if live := strings.Contains(cfg.APIKey, "_live_"); live != (mode == model.ModeLive) {
+		return fmt.Errorf("Stripe 密钥与 %s 模式不匹配", mode)
+	}
+	if cfg.WebhookSecret != "" && !strings.HasPrefix(cfg.WebhookSecret, "whsec_") {
+		return fmt.Errorf("webhook_secret 格式错误")
+	}
+
+	// 使用独立客户端，避免修改全局 stripe.Key 影响正在使用的凭证
+	sc := stripeclient.New(cfg.APIKey, nil)
+	params := &stripe.BalanceParams{}
+	params.Context = ctx
+	if _, err := sc.Balance.Get(params); err != nil {
+		return fmt.Errorf("Stripe 凭证校验失败: %w", err)
+	}
+	return nil
+}
+
+// validatePayPalCredentials 获取 OAuth 访问令牌确认 client_id/client_secret 有效
+func validatePayPalCredentials(ctx context.Context, mode string, cfg *model.PayPalConfig) error {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return fmt.Errorf("client_id 和 client_secret 不能为空")
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = "live"
+		if mode == model.ModeTest {
+			cfg.Mode = "sandbox"
+		}
+	}
+	if (cfg.Mode == "live") != (mode == model.ModeLive) {
+		return fmt.Errorf("PayPal %s 环境与 %s 模式不匹配", cfg.Mode, mode)
+	}
+
+	if _, err := NewPayPalAdapter(cfg).getAccessToken(ctx); err != nil {
+		return fmt.Errorf("PayPal 凭证校验失败: %w", err)
+	}
+	return nil
+}
+
+// validateAlipayCredentials 查询一笔不存在的交易：签名和应用有效时支付宝返回 ACQ.TRADE_NOT_EXIST
+func validateAlipayCredentials(ctx context.Context, cfg *model.AlipayConfig) error {
+	if cfg.AppID == "" {
+		return fmt.Errorf("app_id 不能为空")
+	}
+	if cfg.SignType == "" {
+		cfg.SignType = "RSA2"
+	}
+	if cfg.Format == "" {
+		cfg.Format = "json"
+	}
+	if cfg.Charset == "" {
+		cfg.Charset = "utf-8"
+	}
+	if cfg.APIGateway == "" {
+		cfg.APIGateway = alipayDefaultGateway
+	}
+
+	a, err := NewAlipayAdapter(cfg)
+	if err != nil {
+		return err
+	}
+	result, err := a.request(ctx, "alipay.trade.query", map[string]interface{}{
+		"out_trade_no": "CREDENTIAL_CHECK",
+	})
+	if err != nil {
+		return fmt.Errorf("支付宝凭证校验失败: %w", err)
+	}
+	respData, ok := result["alipay_trade_query_response"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("支付宝凭证校验失败: 响应格式错误")
+	}
+	code, _ := respData["code"].(string)
+	subCode, _ := respData["sub_code"].(string)
+	if code == "10000" || subCode == "ACQ.TRADE_NOT_EXIST" {
+		return nil
+	}
+	subMsg, _ := respData["sub_msg"].(string)
+	return fmt.Errorf("支付宝凭证校验失败: %s %s %s", code, subCode, subMsg)
+}
+
+// validateCryptoCredentials 解析扩展公钥并访问每个 RPC 端点（端点 URL 通常包含节点服务商 API Key）
+func validateCryptoCredentials(ctx context.Context, cfg *model.CryptoConfig) error {
+	if cfg.XPubs["evm"] == "" && cfg.XPubs["tron"] == "" {
+		return fmt.Errorf("至少需要配置一个扩展公钥")
+	}
+	a, err := NewCryptoAdapter(cfg, nil, nil)
+	if err != nil {
+		return err
+	}
+	for network, rpc := range a.rpcClients {
+		if _, err := rpc.BlockNumber(ctx); err != nil {
+			return fmt.Errorf("%s RPC 端点不可用: %w", network, err)
+		}
+	}
+	return nil
+}
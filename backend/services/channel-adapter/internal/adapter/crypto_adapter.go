@@ -3,25 +3,38 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/payment-platform/pkg/money"
+	"payment-platform/channel-adapter/internal/chain"
 	"payment-platform/channel-adapter/internal/client"
 	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
 )
 
 // CryptoAdapter 加密货币支付适配器
-// 支持多链：ETH, BSC, TRON, BTC 等
+// 支持多链：ETH, BSC, TRON 的原生币及 USDT/USDC 等代币
+//
+// 每笔支付从账户层级 xpub 派生独立的收款地址（.../0/index），按地址扫描链上到账，
+// 不再需要按金额和时间猜测交易归属。
 type CryptoAdapter struct {
-	DefaultPreAuthNotSupported  // 嵌入默认预授权实现
-	config             *model.CryptoConfig
-	httpClient         *http.Client
-	exchangeRateClient *client.ExchangeRateClient // 汇率客户端
-	priceCache         map[string]CryptoPrice     // 价格缓存
-	cacheTime          time.Time
+	DefaultPreAuthNotSupported        // 嵌入默认预授权实现
+	DefaultAuthenticationNotSupported // 嵌入默认 3DS 实现
+	config                            *model.CryptoConfig
+	httpClient                        *http.Client
+	exchangeRateClient                *client.ExchangeRateClient          // 汇率客户端
+	deposits                          repository.CryptoDepositRepository  // 收款记录
+	xpubs                             map[string]*chain.ExtendedPublicKey // 按网络族区分的扩展公钥
+	rpcClients                        map[string]*chain.RPCClient         // 按网络区分的 JSON-RPC 客户端
+	priceCache                        map[string]CryptoPrice              // 价格缓存
+	cacheTime                         time.Time
 }
 
 // CryptoPrice 加密货币价格
@@ -30,29 +43,67 @@ type CryptoPrice struct {
 	USD    float64 `json:"usd"`    // 美元价格
 }
 
-// CryptoTransaction 链上交易信息
-type CryptoTransaction struct {
-	TxHash        string    `json:"tx_hash"`
-	From          string    `json:"from"`
-	To            string    `json:"to"`
-	Amount        string    `json:"amount"`
-	Symbol        string    `json:"symbol"`
-	Confirmations int       `json:"confirmations"`
-	Status        string    `json:"status"`
-	BlockNumber   int64     `json:"block_number"`
-	Timestamp     time.Time `json:"timestamp"`
+// cryptoAsset 收款币种
+type cryptoAsset struct {
+	Symbol   string
+	Contract string // 代币合约地址，原生币为空
+	Decimals int
+}
+
+// nativeAssets 各网络的原生币
+var nativeAssets = map[string]cryptoAsset{
+	chain.NetworkETH:  {Symbol: "ETH", Decimals: 18},
+	chain.NetworkBSC:  {Symbol: "BNB", Decimals: 18},
+	chain.NetworkTRON: {Symbol: "TRX", Decimals: 6},
+}
+
+// evmChainIDs EIP-681 支付链接使用的链 ID
+var evmChainIDs = map[string]int{
+	chain.NetworkETH: 1,
+	chain.NetworkBSC: 56,
 }
 
 // NewCryptoAdapter 创建加密货币适配器实例
-func NewCryptoAdapter(config *model.CryptoConfig, exchangeRateClient *client.ExchangeRateClient) *CryptoAdapter {
-	return &CryptoAdapter{
+func NewCryptoAdapter(config *model.CryptoConfig, exchangeRateClient *client.ExchangeRateClient, deposits repository.CryptoDepositRepository) (*CryptoAdapter, error) {
+	if config.PaymentTimeout <= 0 {
+		config.PaymentTimeout = 30 * time.Minute
+	}
+	if config.Confirmations <= 0 {
+		config.Confirmations = 1
+	}
+	if config.Tokens == nil {
+		config.Tokens = model.DefaultCryptoTokens
+	}
+
+	a := &CryptoAdapter{
 		config: config,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 		exchangeRateClient: exchangeRateClient,
+		deposits:           deposits,
+		xpubs:              make(map[string]*chain.ExtendedPublicKey),
+		rpcClients:         make(map[string]*chain.RPCClient),
 		priceCache:         make(map[string]CryptoPrice),
 	}
+
+	for family, encoded := range config.XPubs {
+		if encoded == "" {
+			continue
+		}
+		key, err := chain.ParseExtendedPublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s 扩展公钥无效: %w", family, err)
+		}
+		a.xpubs[family] = key
+	}
+	for network, endpoint := range config.RPCEndpoints {
+		if endpoint != "" {
+			a.rpcClients[strings.ToUpper(network)] = chain.NewRPCClient(endpoint, a.httpClient)
+		}
+	}
+
+	return a, nil
 }
 
 // GetChannel 获取渠道名称
@@ -130,16 +181,17 @@ func (a *CryptoAdapter) calculateCryptoAmount(ctx context.Context, fiatAmount in
 	}
 
 	// 将法币金额转换为美元（使用真实汇率）
-	usdAmount := float64(fiatAmount) / 100.0
+	usdAmount := money.New(fiatAmount, fiatCurrency).Major().Float64()
 	if fiatCurrency != "USD" {
-		// 使用汇率客户端进行转换
-		if a.exchangeRateClient != nil {
-			rate, err := a.exchangeRateClient.GetRate(ctx, fiatCurrency, "USD")
-			if err == nil {
-				usdAmount = usdAmount * rate
-			}
-			// 如果获取汇率失败，使用原始金额（已有降级处理）
+		if a.exchangeRateClient == nil {
+			return 0, fmt.Errorf("未配置汇率客户端，无法换算 %s", fiatCurrency)
 		}
+		// 汇率不可用时拒绝下单，避免按错误金额收款
+		rate, err := a.exchangeRateClient.GetRate(ctx, fiatCurrency, "USD")
+		if err != nil {
+			return 0, fmt.Errorf("获取汇率失败: %w", err)
+		}
+		usdAmount = usdAmount * rate
 	}
 
 	// 计算加密货币数量
@@ -148,16 +200,33 @@ func (a *CryptoAdapter) calculateCryptoAmount(ctx context.Context, fiatAmount in
 	return cryptoAmount, nil
 }
 
+// resolveAsset 根据网络和币种确定收款币种，symbol 为空时使用网络原生币
+func (a *CryptoAdapter) resolveAsset(network, symbol string) (cryptoAsset, error) {
+	native := nativeAssets[network]
+	if symbol == "" || strings.EqualFold(symbol, native.Symbol) {
+		return native, nil
+	}
+	for _, token := range a.config.Tokens {
+		if strings.EqualFold(token.Network, network) && strings.EqualFold(token.Symbol, symbol) {
+			return cryptoAsset{Symbol: strings.ToUpper(token.Symbol), Contract: token.Contract, Decimals: token.Decimals}, nil
+		}
+	}
+	return cryptoAsset{}, fmt.Errorf("网络 %s 不支持币种 %s", network, symbol)
+}
+
 // CreatePayment 创建支付
 func (a *CryptoAdapter) CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*CreatePaymentResponse, error) {
-	// 确定使用的网络和代币
-	network := "ETH" // 默认使用以太坊
-	symbol := "ETH"  // 默认使用 ETH
+	// 同一支付重复创建时返回已分配的收款地址
+	if existing, err := a.deposits.GetByPaymentNo(ctx, req.PaymentNo); err == nil {
+		return a.buildCreateResponse(existing), nil
+	}
 
-	// 从扩展信息中获取网络和代币配置
+	// 确定使用的网络和代币（默认以太坊原生币）
+	network := chain.NetworkETH
+	symbol := ""
 	if req.Extra != nil {
 		if n, ok := req.Extra["network"].(string); ok && n != "" {
-			network = n
+			network = strings.ToUpper(n)
 		}
 		if s, ok := req.Extra["symbol"].(string); ok && s != "" {
 			symbol = s
@@ -172,217 +241,332 @@ func (a *CryptoAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 			break
 		}
 	}
-	if !networkSupported {
+	family := chain.NetworkFamily(network)
+	if !networkSupported || family == "" {
 		return nil, fmt.Errorf("不支持的网络: %s", network)
 	}
+	xpub, ok := a.xpubs[family]
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 未配置扩展公钥", network)
+	}
+	rpc, ok := a.rpcClients[network]
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 未配置 RPC 端点", network)
+	}
 
-	// 计算需要支付的加密货币数量
-	cryptoAmount, err := a.calculateCryptoAmount(ctx, req.Amount, req.Currency, symbol)
+	asset, err := a.resolveAsset(network, symbol)
 	if err != nil {
-		return nil, fmt.Errorf("计算加密货币数量失败: %w", err)
+		return nil, err
 	}
 
-	// 获取当前加密货币价格（用于记录，确保后续查询时使用相同价格）
-	cryptoPrice, err := a.getCryptoPrice(ctx, symbol)
+	// 计算需要支付的加密货币数量，并锁定当前价格
+	cryptoAmount, err := a.calculateCryptoAmount(ctx, req.Amount, req.Currency, asset.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("计算加密货币数量失败: %w", err)
+	}
+	cryptoPrice, err := a.getCryptoPrice(ctx, asset.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("获取加密货币价格失败: %w", err)
 	}
+	expected := toBaseUnits(cryptoAmount, asset.Decimals)
+	if expected.Sign() <= 0 {
+		return nil, fmt.Errorf("支付金额过小")
+	}
 
-	// 生成支付信息（使用配置的钱包地址）
-	paymentAddress := a.config.WalletAddress
-
-	// 生成二维码数据（适用于钱包扫码支付）
-	// 格式：ethereum:0xAddress@chainId?value=amount
-	var qrData string
-	switch strings.ToUpper(network) {
-	case "ETH":
-		qrData = fmt.Sprintf("ethereum:%s?value=%.8f", paymentAddress, cryptoAmount)
-	case "BSC":
-		qrData = fmt.Sprintf("ethereum:%s@56?value=%.8f", paymentAddress, cryptoAmount)
-	case "TRON":
-		qrData = fmt.Sprintf("tron:%s?amount=%.8f", paymentAddress, cryptoAmount)
-	default:
-		qrData = fmt.Sprintf("%s:%s?amount=%.8f", strings.ToLower(network), paymentAddress, cryptoAmount)
+	// 以当前区块作为扫描起点
+	startBlock, err := rpc.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询区块高度失败: %w", err)
+	}
+
+	deposit := &model.CryptoDeposit{
+		PaymentNo:      req.PaymentNo,
+		Network:        network,
+		Symbol:         asset.Symbol,
+		TokenContract:  asset.Contract,
+		TokenDecimals:  asset.Decimals,
+		KeyID:          family,
+		ExpectedAmount: expected.String(),
+		ReceivedAmount: "0",
+		StartBlock:     startBlock,
+		Status:         model.CryptoDepositStatusPending,
+		FiatAmount:     req.Amount,
+		FiatCurrency:   req.Currency,
+		CryptoPrice:    cryptoPrice,
+		ExpiresAt:      time.Now().Add(a.config.PaymentTimeout),
+	}
+	derive := func(index uint32) (string, error) {
+		key, err := xpub.Derive(0, index)
+		if err != nil {
+			return "", err
+		}
+		return chain.Address(family, key)
 	}
+	skip := func(err error) bool {
+		return errors.Is(err, chain.ErrInvalidChild)
+	}
+	if err := a.deposits.CreateWithNextIndex(ctx, deposit, derive, skip); err != nil {
+		return nil, fmt.Errorf("分配收款地址失败: %w", err)
+	}
+
+	return a.buildCreateResponse(deposit), nil
+}
 
-	// 构造响应
-	response := &CreatePaymentResponse{
-		ChannelTradeNo: req.PaymentNo, // 使用平台支付号作为交易号
-		QRCodeURL:      qrData,         // 二维码数据
+// buildCreateResponse 构造创建支付响应
+func (a *CryptoAdapter) buildCreateResponse(d *model.CryptoDeposit) *CreatePaymentResponse {
+	expected, _ := new(big.Int).SetString(d.ExpectedAmount, 10)
+	return &CreatePaymentResponse{
+		ChannelTradeNo: d.PaymentNo, // 使用平台支付号作为交易号
+		QRCodeURL:      paymentURI(d, expected),
 		Status:         PaymentStatusPending,
 		Extra: map[string]interface{}{
-			"payment_address": paymentAddress,
-			"crypto_amount":   cryptoAmount,
-			"crypto_symbol":   symbol,
-			"crypto_price":    cryptoPrice,      // 锁定支付时的价格
-			"fiat_amount":     req.Amount,       // 原始法币金额（分）
-			"fiat_currency":   req.Currency,     // 原始法币币种
-			"network":         network,
+			"payment_address":        d.Address,
+			"derivation_index":       d.DerivationIndex,
+			"crypto_amount":          formatUnits(expected, d.TokenDecimals),
+			"crypto_amount_units":    d.ExpectedAmount,
+			"crypto_symbol":          d.Symbol,
+			"token_contract":         d.TokenContract,
+			"crypto_price":           d.CryptoPrice, // 锁定支付时的价格
+			"fiat_amount":            d.FiatAmount,  // 原始法币金额（分）
+			"fiat_currency":          d.FiatCurrency,
+			"network":                d.Network,
 			"confirmations_required": a.config.Confirmations,
-			"expires_at": time.Now().Add(30 * time.Minute).Unix(), // 30分钟有效期
+			"expires_at":             d.ExpiresAt.Unix(),
 		},
 	}
-
-	return response, nil
 }
 
-// QueryPayment 查询支付状态
-func (a *CryptoAdapter) QueryPayment(ctx context.Context, channelTradeNo string) (*QueryPaymentResponse, error) {
-	// 查询链上交易
-	// 这里需要根据 channelTradeNo（支付号）查询对应的交易记录
-	// 实际实现中应该：
-	// 1. 从数据库获取期望的支付金额和地址
-	// 2. 调用区块链浏览器 API 查询该地址的交易记录
-	// 3. 匹配金额和时间范围，找到对应的交易
-
-	// 模拟查询结果（实际应该调用区块链 API）
-	tx, err := a.queryBlockchainTransaction(ctx, a.config.WalletAddress)
-	if err != nil {
-		return nil, err
-	}
-
-	if tx == nil {
-		// 未找到交易，仍在等待支付
-		return &QueryPaymentResponse{
-			ChannelTradeNo: channelTradeNo,
-			Status:         PaymentStatusPending,
-		}, nil
+// paymentURI 生成钱包扫码数据
+// EVM 网络使用 EIP-681：原生币 ethereum:<地址>@<链ID>?value=<wei>，
+// 代币 ethereum:<合约>@<链ID>/transfer?address=<地址>&uint256=<数量>
+func paymentURI(d *model.CryptoDeposit, expected *big.Int) string {
+	if chainID, ok := evmChainIDs[d.Network]; ok {
+		if d.TokenContract != "" {
+			return fmt.Sprintf("ethereum:%s@%d/transfer?address=%s&uint256=%s", d.TokenContract, chainID, d.Address, expected)
+		}
+		return fmt.Sprintf("ethereum:%s@%d?value=%s", d.Address, chainID, expected)
 	}
 
-	// 判断确认数是否足够
-	status := PaymentStatusProcessing
-	if tx.Confirmations >= a.config.Confirmations {
-		status = PaymentStatusSuccess
+	uri := fmt.Sprintf("%s:%s?amount=%s", strings.ToLower(d.Network), d.Address, formatUnits(expected, d.TokenDecimals))
+	if d.TokenContract != "" {
+		uri += "&token=" + d.TokenContract
 	}
+	return uri
+}
 
-	// 将加密货币金额转换为法币金额（分）
-	// 使用支付创建时锁定的价格和法币金额，避免汇率波动
-	//
-	// 重要说明：
-	// 1. CreatePayment时已经在Extra中保存了 crypto_price, fiat_amount, fiat_currency
-	// 2. 这些信息应该由payment-gateway在调用CreatePayment后保存到数据库
-	// 3. QueryPayment时，payment-gateway应该从数据库查询原始订单信息
-	// 4. 然后将 fiat_amount 和 fiat_currency 作为参数传递给这里
-	//
-	// 当前实现：由于channelTradeNo不包含这些信息，我们无法从链上交易恢复法币金额
-	// 生产环境必须从payment数据库查询原始订单的fiat_amount和fiat_currency
-	// 这里返回0作为占位符，提示调用方必须使用数据库中保存的金额
-
-	// 占位符：实际金额应该从payment-gateway的数据库查询
-	amount := int64(0)
-	currency := "USD"
-
-	response := &QueryPaymentResponse{
-		ChannelTradeNo: channelTradeNo,
-		Status:         status,
-		Amount:         amount,    // 使用支付创建时的法币金额
-		Currency:       currency,  // 使用支付创建时的法币币种
-		PaymentMethod:  "crypto",
-		PaymentMethodDetails: map[string]interface{}{
-			"tx_hash":        tx.TxHash,
-			"crypto_amount":  tx.Amount,
-			"crypto_symbol":  tx.Symbol,
-			"confirmations":  tx.Confirmations,
-			"block_number":   tx.BlockNumber,
-		},
+// QueryPayment 查询支付状态
+//
+// 按收款地址扫描链上到账并更新收款记录，足额（含超额）且达到确认数后为成功。
+func (a *CryptoAdapter) QueryPayment(ctx context.Context, channelTradeNo string) (*QueryPaymentResponse, error) {
+	deposit, err := a.deposits.GetByPaymentNo(ctx, channelTradeNo)
+	if err != nil {
+		return nil, fmt.Errorf("收款记录不存在: %w", err)
 	}
 
-	if tx.Confirmations >= a.config.Confirmations {
-		paidAt := tx.Timestamp.Unix()
-		response.PaidAt = &paidAt
+	if !deposit.IsFinal() {
+		if err := a.refreshDeposit(ctx, deposit); err != nil {
+			return nil, err
+		}
 	}
 
-	return response, nil
+	return a.buildQueryResponse(deposit), nil
 }
 
-// queryBlockchainTransaction 查询区块链交易
-func (a *CryptoAdapter) queryBlockchainTransaction(ctx context.Context, address string) (*CryptoTransaction, error) {
-	// 这里应该调用区块链浏览器 API
-	// 例如：Etherscan API, BSCScan API, TronScan API 等
-
-	if a.config.APIEndpoint == "" {
-		// 如果没有配置 API 端点，返回 nil（表示需要手动确认）
-		return nil, nil
+// refreshDeposit 扫描链上到账并更新收款状态
+func (a *CryptoAdapter) refreshDeposit(ctx context.Context, d *model.CryptoDeposit) error {
+	rpc, ok := a.rpcClients[d.Network]
+	if !ok {
+		return fmt.Errorf("网络 %s 未配置 RPC 端点", d.Network)
 	}
-
-	// 示例：调用 Etherscan API 查询交易
-	// GET https://api.etherscan.io/api?module=account&action=txlist&address=0x...&apikey=...
-
-	url := fmt.Sprintf("%s?module=account&action=txlist&address=%s&startblock=0&endblock=99999999&sort=desc&apikey=%s",
-		a.config.APIEndpoint, address, a.config.APIKey)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	address, err := chain.HexAddress(d.Address)
 	if err != nil {
-		return nil, fmt.Errorf("创建区块链查询请求失败: %w", err)
+		return err
+	}
+	contract := ""
+	if d.TokenContract != "" {
+		if contract, err = chain.HexAddress(d.TokenContract); err != nil {
+			return err
+		}
 	}
 
-	resp, err := a.httpClient.Do(req)
+	scan, err := rpc.ScanDeposit(ctx, address, contract, d.StartBlock, uint64(a.config.Confirmations))
 	if err != nil {
-		return nil, fmt.Errorf("查询区块链交易失败: %w", err)
+		return fmt.Errorf("扫描链上到账失败: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("查询区块链交易失败，状态码: %d", resp.StatusCode)
+	if scan.FirstSeenBlock > 0 && (d.FirstSeenAt == nil || scan.FirstSeenBlock != d.FirstSeenBlock) {
+		seenAt, err := rpc.BlockTimestamp(ctx, scan.FirstSeenBlock)
+		if err != nil {
+			return fmt.Errorf("查询区块时间失败: %w", err)
+		}
+		d.FirstSeenAt = &seenAt
 	}
+	d.FirstSeenBlock = scan.FirstSeenBlock
+	if scan.TxHash != "" {
+		d.TxHash = scan.TxHash
+	}
+	d.Confirmations = scan.Confirmations
+	d.ReceivedAmount = scan.Received.String()
 
-	// 解析响应（具体格式取决于使用的 API）
-	var result struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Result  []struct {
-			Hash          string `json:"hash"`
-			From          string `json:"from"`
-			To            string `json:"to"`
-			Value         string `json:"value"`
-			BlockNumber   string `json:"blockNumber"`
-			Confirmations string `json:"confirmations"`
-			TimeStamp     string `json:"timeStamp"`
-		} `json:"result"`
+	now := time.Now()
+	d.Status = a.evaluateDeposit(d, scan.Received, scan.Confirmed, now)
+	if d.IsFinal() && d.PaidAt == nil {
+		d.PaidAt = &now
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("解析区块链响应失败: %w", err)
+	return a.deposits.Update(ctx, d)
+}
+
+// evaluateDeposit 根据到账金额、确认情况和有效期判定收款状态
+//
+// 到账金额在应收金额 ± ToleranceBps 内视为足额；过期后才出现的首笔到账为 late，需人工处理。
+func (a *CryptoAdapter) evaluateDeposit(d *model.CryptoDeposit, received, confirmed *big.Int, now time.Time) string {
+	expected, _ := new(big.Int).SetString(d.ExpectedAmount, 10)
+	lower, upper := toleranceBounds(expected, a.config.ToleranceBps)
+
+	switch {
+	case received.Sign() == 0:
+		if now.After(d.ExpiresAt) {
+			return model.CryptoDepositStatusExpired
+		}
+		return model.CryptoDepositStatusPending
+	case d.FirstSeenAt != nil && d.FirstSeenAt.After(d.ExpiresAt):
+		return model.CryptoDepositStatusLate
+	case received.Cmp(lower) < 0:
+		return model.CryptoDepositStatusUnderpaid
+	case confirmed.Cmp(lower) < 0:
+		return model.CryptoDepositStatusConfirming
+	case confirmed.Cmp(upper) > 0:
+		return model.CryptoDepositStatusOverpaid
+	default:
+		return model.CryptoDepositStatusPaid
 	}
+}
 
-	if result.Status != "1" || len(result.Result) == 0 {
-		// 未找到交易
-		return nil, nil
+// buildQueryResponse 构造查询支付响应，金额为创建支付时锁定的法币金额
+func (a *CryptoAdapter) buildQueryResponse(d *model.CryptoDeposit) *QueryPaymentResponse {
+	expected, _ := new(big.Int).SetString(d.ExpectedAmount, 10)
+	received, _ := new(big.Int).SetString(d.ReceivedAmount, 10)
+	if received == nil {
+		received = new(big.Int)
 	}
 
-	// 返回最新的一笔交易
-	tx := result.Result[0]
+	response := &QueryPaymentResponse{
+		ChannelTradeNo: d.PaymentNo,
+		Status:         convertDepositStatus(d, time.Now()),
+		Amount:         d.FiatAmount,
+		Currency:       d.FiatCurrency,
+		PaymentMethod:  "crypto",
+		PaymentMethodDetails: map[string]interface{}{
+			"tx_hash":         d.TxHash,
+			"network":         d.Network,
+			"payment_address": d.Address,
+			"crypto_symbol":   d.Symbol,
+			"token_contract":  d.TokenContract,
+			"expected_amount": formatUnits(expected, d.TokenDecimals),
+			"received_amount": formatUnits(received, d.TokenDecimals),
+			"confirmations":   d.Confirmations,
+			"block_number":    d.FirstSeenBlock,
+		},
+		Extra: map[string]interface{}{
+			"deposit_status":         d.Status,
+			"confirmations_required": a.config.Confirmations,
+			"crypto_price":           d.CryptoPrice,
+			"expires_at":             d.ExpiresAt.Unix(),
+		},
+	}
 
-	// 解析时间戳
-	var timestamp int64
-	fmt.Sscanf(tx.TimeStamp, "%d", &timestamp)
+	switch d.Status {
+	case model.CryptoDepositStatusOverpaid:
+		response.Extra["overpaid_amount"] = formatUnits(new(big.Int).Sub(received, expected), d.TokenDecimals)
+	case model.CryptoDepositStatusUnderpaid:
+		response.Extra["shortfall_amount"] = formatUnits(new(big.Int).Sub(expected, received), d.TokenDecimals)
+	case model.CryptoDepositStatusLate:
+		response.Extra["requires_manual_review"] = true
+	}
 
-	// 解析确认数
-	var confirmations int
-	fmt.Sscanf(tx.Confirmations, "%d", &confirmations)
+	if d.PaidAt != nil {
+		paidAt := d.PaidAt.Unix()
+		response.PaidAt = &paidAt
+	}
 
-	// 解析区块号
-	var blockNumber int64
-	fmt.Sscanf(tx.BlockNumber, "%d", &blockNumber)
+	return response
+}
 
-	return &CryptoTransaction{
-		TxHash:        tx.Hash,
-		From:          tx.From,
-		To:            tx.To,
-		Amount:        tx.Value,
-		Symbol:        "ETH",
-		Confirmations: confirmations,
-		Status:        "confirmed",
-		BlockNumber:   blockNumber,
-		Timestamp:     time.Unix(timestamp, 0),
-	}, nil
+// convertDepositStatus 收款状态转换为统一支付状态
+func convertDepositStatus(d *model.CryptoDeposit, now time.Time) string {
+	switch d.Status {
+	case model.CryptoDepositStatusPaid, model.CryptoDepositStatusOverpaid:
+		return PaymentStatusSuccess
+	case model.CryptoDepositStatusConfirming:
+		return PaymentStatusProcessing
+	case model.CryptoDepositStatusUnderpaid:
+		// 有效期内允许补足差额
+		if now.After(d.ExpiresAt) {
+			return PaymentStatusFailed
+		}
+		return PaymentStatusPending
+	case model.CryptoDepositStatusLate:
+		return PaymentStatusFailed
+	case model.CryptoDepositStatusExpired:
+		return PaymentStatusCancelled
+	default:
+		return PaymentStatusPending
+	}
 }
 
 // CancelPayment 取消支付
 func (a *CryptoAdapter) CancelPayment(ctx context.Context, channelTradeNo string) error {
 	// 加密货币支付无法取消（链上交易不可逆）
-	// 只能标记为过期
-	return nil
+	// 只能将尚未到账的收款标记为过期，之后的到账按 late 处理
+	deposit, err := a.deposits.GetByPaymentNo(ctx, channelTradeNo)
+	if err != nil {
+		return fmt.Errorf("收款记录不存在: %w", err)
+	}
+	if deposit.Status != model.CryptoDepositStatusPending {
+		return nil
+	}
+	deposit.Status = model.CryptoDepositStatusExpired
+	deposit.ExpiresAt = time.Now()
+	return a.deposits.Update(ctx, deposit)
+}
+
+// toleranceBounds 计算足额判定的上下限
+func toleranceBounds(expected *big.Int, bps int64) (*big.Int, *big.Int) {
+	delta := new(big.Int).Mul(expected, big.NewInt(bps))
+	delta.Quo(delta, big.NewInt(10000))
+	return new(big.Int).Sub(expected, delta), new(big.Int).Add(expected, delta)
+}
+
+// toBaseUnits 将币种数量转换为最小单位（四舍五入）
+// 先按 15 位有效数字转为十进制，避免 0.05 这类数量因二进制浮点误差多出尾数
+func toBaseUnits(amount float64, decimals int) *big.Int {
+	v, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'g', 15, 64))
+	if !ok {
+		return new(big.Int)
+	}
+	v.Mul(v, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	v.Add(v, big.NewRat(1, 2))
+	return new(big.Int).Quo(v.Num(), v.Denom())
+}
+
+// formatUnits 将最小单位格式化为币种数量，去掉末尾的零
+func formatUnits(units *big.Int, decimals int) string {
+	if units == nil {
+		return "0"
+	}
+	s := new(big.Int).Abs(units).String()
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	intPart, fracPart := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if units.Sign() < 0 {
+		intPart = "-" + intPart
+	}
+	if fracPart == "" {
+		return intPart
+	}
+	return intPart + "." + fracPart
 }
 
 // CreateRefund 创建退款
@@ -395,7 +579,7 @@ func (a *CryptoAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 		ChannelRefundNo: req.RefundNo,
 		Status:          PaymentStatusProcessing, // 标记为处理中，需要人工确认
 		Extra: map[string]interface{}{
-			"note": "加密货币退款需要手动转账到用户地址",
+			"note":                       "加密货币退款需要手动转账到用户地址",
 			"requires_manual_processing": true,
 		},
 	}, nil
@@ -427,14 +611,14 @@ func (a *CryptoAdapter) VerifyWebhook(ctx context.Context, signature string, bod
 func (a *CryptoAdapter) ParseWebhook(ctx context.Context, body []byte) (*WebhookEvent, error) {
 	// 解析区块链事件通知
 	var event struct {
-		TxHash   string `json:"tx_hash"`
-		From     string `json:"from"`
-		To       string `json:"to"`
-		Amount   string `json:"amount"`
-		Symbol   string `json:"symbol"`
-		Network  string `json:"network"`
-		Status   string `json:"status"`
-		Confirmations int `json:"confirmations"`
+		TxHash        string `json:"tx_hash"`
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Amount        string `json:"amount"`
+		Symbol        string `json:"symbol"`
+		Network       string `json:"network"`
+		Status        string `json:"status"`
+		Confirmations int    `json:"confirmations"`
 	}
 
 	if err := json.Unmarshal(body, &event); err != nil {
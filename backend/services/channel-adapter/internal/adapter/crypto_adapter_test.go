@@ -0,0 +1,399 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"payment-platform/channel-adapter/internal/chain"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+const testXPub = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+
+const testUSDT = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+
+// fakeNode 本地 JSON-RPC 节点替身，只实现适配器用到的 eth_* 方法
+type fakeNode struct {
+	mu        sync.Mutex
+	latest    uint64
+	credits   map[string][]credit // 原生币到账，按小写地址
+	transfers []transferLog
+	times     map[uint64]time.Time
+}
+
+type credit struct {
+	block  uint64
+	amount *big.Int
+}
+
+type transferLog struct {
+	contract string
+	to       string
+	block    uint64
+	amount   *big.Int
+	txHash   string
+}
+
+func newFakeNode(latest uint64) *fakeNode {
+	return &fakeNode{latest: latest, credits: map[string][]credit{}, times: map[uint64]time.Time{}}
+}
+
+// mine 出块，返回新区块高度
+func (n *fakeNode) mine(blocks uint64) uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.latest += blocks
+	return n.latest
+}
+
+// sendNative 在下一个区块转入原生币
+func (n *fakeNode) sendNative(to string, amount int64) {
+	block := n.mine(1)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := strings.ToLower(to)
+	n.credits[key] = append(n.credits[key], credit{block: block, amount: big.NewInt(amount)})
+}
+
+// sendToken 在下一个区块转入代币
+func (n *fakeNode) sendToken(contract, to string, amount int64) {
+	block := n.mine(1)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.transfers = append(n.transfers, transferLog{
+		contract: strings.ToLower(contract),
+		to:       strings.ToLower(to),
+		block:    block,
+		amount:   big.NewInt(amount),
+		txHash:   fmt.Sprintf("0x%064x", len(n.transfers)+1),
+	})
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var result interface{}
+	switch req.Method {
+	case "eth_blockNumber":
+		result = fmt.Sprintf("0x%x", n.latest)
+	case "eth_getBalance":
+		var address, block string
+		_ = json.Unmarshal(req.Params[0], &address)
+		_ = json.Unmarshal(req.Params[1], &block)
+		height := parseHex(block)
+		balance := new(big.Int)
+		for _, c := range n.credits[strings.ToLower(address)] {
+			if c.block <= height {
+				balance.Add(balance, c.amount)
+			}
+		}
+		result = fmt.Sprintf("0x%x", balance)
+	case "eth_getBlockByNumber":
+		var block string
+		_ = json.Unmarshal(req.Params[0], &block)
+		ts, ok := n.times[parseHex(block)]
+		if !ok {
+			ts = time.Now()
+		}
+		result = map[string]string{"timestamp": fmt.Sprintf("0x%x", ts.Unix())}
+	case "eth_getLogs":
+		var filter struct {
+			Address   string        `json:"address"`
+			FromBlock string        `json:"fromBlock"`
+			ToBlock   string        `json:"toBlock"`
+			Topics    []interface{} `json:"topics"`
+		}
+		_ = json.Unmarshal(req.Params[0], &filter)
+		toTopic, _ := filter.Topics[2].(string)
+		from, to := parseHex(filter.FromBlock), parseHex(filter.ToBlock)
+		logs := []map[string]interface{}{}
+		for _, t := range n.transfers {
+			if t.contract != strings.ToLower(filter.Address) || t.block < from || t.block > to ||
+				!strings.HasSuffix(toTopic, strings.TrimPrefix(t.to, "0x")) {
+				continue
+			}
+			logs = append(logs, map[string]interface{}{
+				"transactionHash": t.txHash,
+				"blockNumber":     fmt.Sprintf("0x%x", t.block),
+				"topics":          []string{chain.TransferTopic, "0x" + strings.Repeat("0", 64), toTopic},
+				"data":            fmt.Sprintf("0x%064x", t.amount),
+				"removed":         false,
+			})
+		}
+		result = logs
+	default:
+		http.Error(w, "unsupported method "+req.Method, http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func parseHex(s string) uint64 {
+	v, _ := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	return v.Uint64()
+}
+
+// memoryDepositStore 内存收款记录仓储
+type memoryDepositStore struct {
+	deposits map[string]*model.CryptoDeposit
+	next     map[string]uint32
+}
+
+func newMemoryDepositStore() *memoryDepositStore {
+	return &memoryDepositStore{deposits: map[string]*model.CryptoDeposit{}, next: map[string]uint32{}}
+}
+
+func (s *memoryDepositStore) CreateWithNextIndex(ctx context.Context, deposit *model.CryptoDeposit, derive func(index uint32) (string, error), skip func(error) bool) error {
+	index := s.next[deposit.KeyID]
+	for {
+		address, err := derive(index)
+		if err == nil {
+			deposit.DerivationIndex = index
+			deposit.Address = address
+			break
+		}
+		if !skip(err) {
+			return err
+		}
+		index++
+	}
+	s.next[deposit.KeyID] = index + 1
+	copied := *deposit
+	s.deposits[deposit.PaymentNo] = &copied
+	return nil
+}
+
+func (s *memoryDepositStore) GetByPaymentNo(ctx context.Context, paymentNo string) (*model.CryptoDeposit, error) {
+	d, ok := s.deposits[paymentNo]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (s *memoryDepositStore) Update(ctx context.Context, deposit *model.CryptoDeposit) error {
+	copied := *deposit
+	s.deposits[deposit.PaymentNo] = &copied
+	return nil
+}
+
+func newTestCryptoAdapter(t *testing.T, node *fakeNode) (*CryptoAdapter, *memoryDepositStore) {
+	t.Helper()
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	store := newMemoryDepositStore()
+	a, err := NewCryptoAdapter(&model.CryptoConfig{
+		Networks:      []string{"ETH"},
+		Confirmations: 3,
+		XPubs:         map[string]string{chain.FamilyEVM: testXPub},
+		RPCEndpoints:  map[string]string{"ETH": server.URL},
+		ToleranceBps:  50,
+	}, nil, store)
+	if err != nil {
+		t.Fatalf("NewCryptoAdapter: %v", err)
+	}
+
+	// 预置价格缓存，避免访问 CoinGecko
+	a.priceCache["USDT"] = CryptoPrice{Symbol: "USDT", USD: 1}
+	a.priceCache["ETH"] = CryptoPrice{Symbol: "ETH", USD: 2000}
+	a.cacheTime = time.Now()
+	return a, store
+}
+
+func createUSDTPayment(t *testing.T, a *CryptoAdapter, paymentNo string) *CreatePaymentResponse {
+	t.Helper()
+	resp, err := a.CreatePayment(context.Background(), &CreatePaymentRequest{
+		PaymentNo: paymentNo,
+		Amount:    10000, // 100.00 USD
+		Currency:  "USD",
+		Extra:     map[string]interface{}{"network": "ETH", "symbol": "USDT"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	return resp
+}
+
+func queryStatus(t *testing.T, a *CryptoAdapter, paymentNo string) (*QueryPaymentResponse, string) {
+	t.Helper()
+	resp, err := a.QueryPayment(context.Background(), paymentNo)
+	if err != nil {
+		t.Fatalf("QueryPayment: %v", err)
+	}
+	return resp, resp.Extra["deposit_status"].(string)
+}
+
+func TestCryptoAdapterDerivesAddressPerPayment(t *testing.T) {
+	a, _ := newTestCryptoAdapter(t, newFakeNode(100))
+
+	first := createUSDTPayment(t, a, "P001")
+	second := createUSDTPayment(t, a, "P002")
+	again := createUSDTPayment(t, a, "P001")
+
+	addr1 := first.Extra["payment_address"].(string)
+	addr2 := second.Extra["payment_address"].(string)
+	if addr1 == addr2 {
+		t.Fatalf("payments share deposit address %s", addr1)
+	}
+	if again.Extra["payment_address"] != addr1 {
+		t.Fatalf("repeated CreatePayment allocated a new address")
+	}
+	if first.Extra["crypto_amount"] != "100" || first.Extra["crypto_amount_units"] != "100000000" {
+		t.Fatalf("unexpected crypto amount: %v / %v", first.Extra["crypto_amount"], first.Extra["crypto_amount_units"])
+	}
+
+	wantURI := fmt.Sprintf("ethereum:%s@1/transfer?address=%s&uint256=100000000", testUSDT, addr1)
+	if first.QRCodeURL != wantURI {
+		t.Fatalf("QR data = %s, want %s", first.QRCodeURL, wantURI)
+	}
+}
+
+func TestCryptoAdapterTokenConfirmations(t *testing.T) {
+	node := newFakeNode(100)
+	a, _ := newTestCryptoAdapter(t, node)
+	addr := createUSDTPayment(t, a, "P001").Extra["payment_address"].(string)
+
+	if resp, status := queryStatus(t, a, "P001"); status != model.CryptoDepositStatusPending || resp.Status != PaymentStatusPending {
+		t.Fatalf("before transfer: %s / %s", status, resp.Status)
+	}
+
+	// 转入到其他地址的代币不计入
+	node.sendToken(testUSDT, "0x000000000000000000000000000000000000dead", 100000000)
+	node.sendToken(testUSDT, addr, 100000000)
+	resp, status := queryStatus(t, a, "P001")
+	if status != model.CryptoDepositStatusConfirming || resp.Status != PaymentStatusProcessing {
+		t.Fatalf("after transfer: %s / %s", status, resp.Status)
+	}
+	if resp.PaymentMethodDetails["confirmations"] != uint64(1) {
+		t.Fatalf("confirmations = %v", resp.PaymentMethodDetails["confirmations"])
+	}
+
+	node.mine(2)
+	resp, status = queryStatus(t, a, "P001")
+	if status != model.CryptoDepositStatusPaid || resp.Status != PaymentStatusSuccess {
+		t.Fatalf("after confirmations: %s / %s", status, resp.Status)
+	}
+	if resp.Amount != 10000 || resp.Currency != "USD" || resp.PaidAt == nil {
+		t.Fatalf("unexpected fiat amount %d %s, paid_at %v", resp.Amount, resp.Currency, resp.PaidAt)
+	}
+	if resp.PaymentMethodDetails["tx_hash"] == "" {
+		t.Fatal("tx hash not recorded")
+	}
+}
+
+func TestCryptoAdapterAmountStates(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64
+		wantStatus string
+		wantResult string
+	}{
+		{name: "within tolerance", amount: 99600000, wantStatus: model.CryptoDepositStatusPaid, wantResult: PaymentStatusSuccess},
+		{name: "underpaid", amount: 50000000, wantStatus: model.CryptoDepositStatusUnderpaid, wantResult: PaymentStatusPending},
+		{name: "overpaid", amount: 110000000, wantStatus: model.CryptoDepositStatusOverpaid, wantResult: PaymentStatusSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newFakeNode(100)
+			a, _ := newTestCryptoAdapter(t, node)
+			addr := createUSDTPayment(t, a, "P001").Extra["payment_address"].(string)
+
+			node.sendToken(testUSDT, addr, tt.amount)
+			node.mine(5)
+			resp, status := queryStatus(t, a, "P001")
+			if status != tt.wantStatus || resp.Status != tt.wantResult {
+				t.Fatalf("got %s / %s, want %s / %s", status, resp.Status, tt.wantStatus, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCryptoAdapterExpiry(t *testing.T) {
+	node := newFakeNode(100)
+	a, store := newTestCryptoAdapter(t, node)
+	addr := createUSDTPayment(t, a, "P001").Extra["payment_address"].(string)
+	createUSDTPayment(t, a, "P002")
+
+	// 两笔支付均已过期
+	for _, no := range []string{"P001", "P002"} {
+		store.deposits[no].ExpiresAt = time.Now().Add(-time.Minute)
+	}
+
+	if resp, status := queryStatus(t, a, "P002"); status != model.CryptoDepositStatusExpired || resp.Status != PaymentStatusCancelled {
+		t.Fatalf("unpaid after expiry: %s / %s", status, resp.Status)
+	}
+
+	node.sendToken(testUSDT, addr, 100000000)
+	node.mine(5)
+	resp, status := queryStatus(t, a, "P001")
+	if status != model.CryptoDepositStatusLate || resp.Status != PaymentStatusFailed {
+		t.Fatalf("paid after expiry: %s / %s", status, resp.Status)
+	}
+	if resp.Extra["requires_manual_review"] != true {
+		t.Fatal("late payment not flagged for manual review")
+	}
+}
+
+func TestCryptoAdapterNativeDeposit(t *testing.T) {
+	node := newFakeNode(100)
+	a, _ := newTestCryptoAdapter(t, node)
+	resp, err := a.CreatePayment(context.Background(), &CreatePaymentRequest{
+		PaymentNo: "P001",
+		Amount:    10000, // 100.00 USD = 0.05 ETH
+		Currency:  "USD",
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	addr := resp.Extra["payment_address"].(string)
+	if resp.QRCodeURL != fmt.Sprintf("ethereum:%s@1?value=50000000000000000", addr) {
+		t.Fatalf("QR data = %s", resp.QRCodeURL)
+	}
+
+	node.mine(10)
+	node.sendNative(addr, 30000000000000000)
+	node.mine(4)
+	query, status := queryStatus(t, a, "P001")
+	if status != model.CryptoDepositStatusUnderpaid {
+		t.Fatalf("partial payment: %s", status)
+	}
+	if query.PaymentMethodDetails["block_number"] != uint64(111) {
+		t.Fatalf("first seen block = %v", query.PaymentMethodDetails["block_number"])
+	}
+	if query.Extra["shortfall_amount"] != "0.02" {
+		t.Fatalf("shortfall = %v", query.Extra["shortfall_amount"])
+	}
+
+	node.sendNative(addr, 20000000000000000)
+	if _, status = queryStatus(t, a, "P001"); status != model.CryptoDepositStatusConfirming {
+		t.Fatalf("topped up: %s", status)
+	}
+	node.mine(2)
+	if _, status = queryStatus(t, a, "P001"); status != model.CryptoDepositStatusPaid {
+		t.Fatalf("confirmed: %s", status)
+	}
+}
@@ -0,0 +1,54 @@
+package adapter
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestChannelDeclineMapping(t *testing.T) {
+	// Stripe：发卡行 decline_code 优先于 Stripe 错误码
+	stripeErr := &stripe.Error{Type: stripe.ErrorTypeCard, Code: stripe.ErrorCodeCardDeclined, DeclineCode: stripe.DeclineCodeInsufficientFunds, Msg: "Your card has insufficient funds."}
+	err := wrapDecline(stripeDecline(stripeErr), fmt.Errorf("创建 Stripe PaymentIntent 失败: %w", stripeErr))
+	d, ok := decline.FromError(fmt.Errorf("创建支付失败: %w", err))
+	if !ok || d.Category != decline.CategoryInsufficientFunds || d.Type != decline.TypeHard || d.ChannelCode != "insufficient_funds" {
+		t.Fatalf("stripe decline = %+v", d)
+	}
+	if d := stripeDecline(&stripe.Error{Type: stripe.ErrorTypeAPI, Msg: "internal error"}); d.Category != decline.CategoryChannelUnavailable {
+		t.Fatalf("stripe api_error = %+v", d)
+	}
+	if d := stripeDecline(fmt.Errorf("dial tcp: connection refused")); d != nil {
+		t.Fatalf("non-stripe error should not be classified by adapter: %+v", d)
+	}
+
+	// PayPal：details[0].issue 优先于 name
+	body := []byte(`{"name":"UNPROCESSABLE_ENTITY","message":"The requested action could not be performed","details":[{"issue":"INSTRUMENT_DECLINED","description":"The instrument presented was either declined by the processor or bank."}]}`)
+	if d := paypalDecline(http.StatusUnprocessableEntity, body); d.Category != decline.CategoryDoNotHonor || !d.Retryable || d.ChannelCode != "INSTRUMENT_DECLINED" {
+		t.Fatalf("paypal decline = %+v", d)
+	}
+	if d := paypalDecline(http.StatusServiceUnavailable, []byte("upstream error")); d.Category != decline.CategoryChannelUnavailable {
+		t.Fatalf("paypal 503 = %+v", d)
+	}
+	if d := paypalDecline(http.StatusBadRequest, nil); d != nil {
+		t.Fatalf("paypal empty 400 should fall back to message classification: %+v", d)
+	}
+	resource := map[string]interface{}{"processor_response": map[string]interface{}{"response_code": "5400"}}
+	if d := paypalCaptureDecline(resource); d.Category != decline.CategoryCardExpired {
+		t.Fatalf("paypal capture decline = %+v", d)
+	}
+
+	// 支付宝：sub_code 优先于 code
+	respData := map[string]interface{}{"code": "40004", "msg": "Business Failed", "sub_code": "ACQ.BUYER_BALANCE_NOT_ENOUGH", "sub_msg": "买家余额不足"}
+	if d := alipayDecline(respData); d.Category != decline.CategoryInsufficientFunds || d.ChannelCode != "ACQ.BUYER_BALANCE_NOT_ENOUGH" {
+		t.Fatalf("alipay decline = %+v", d)
+	}
+	if d := alipayDecline(map[string]interface{}{"code": "20000", "msg": "Service Currently Unavailable"}); d.Category != decline.CategoryChannelUnavailable {
+		t.Fatalf("alipay 20000 = %+v", d)
+	}
+	if d := alipayDecline(map[string]interface{}{"code": "40004", "sub_code": "ACQ.SYSTEM_ERROR"}); d.Retryable {
+		t.Fatalf("alipay system error must not be retried: %+v", d)
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/sca"
 	"payment-platform/channel-adapter/internal/model"
 )
 
@@ -105,7 +108,7 @@ func (a *PayPalAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 				"reference_id": req.OrderNo,
 				"amount": map[string]interface{}{
 					"currency_code": strings.ToUpper(req.Currency),
-					"value":         fmt.Sprintf("%.2f", float64(req.Amount)/100.0),
+					"value":         money.New(req.Amount, req.Currency).FormatMajor(),
 				},
 				"description": req.Description,
 				"custom_id":   req.PaymentNo,
@@ -117,6 +120,27 @@ func (a *PayPalAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 		},
 	}
 
+	// 卡支付（PayPal 高级卡支付）按 3DS 要求设置验证方式，需要挑战时订单返回 payer-action 链接
+	if req.ThreeDS != nil && req.PayMethod == "card" {
+		returnURL := req.ThreeDS.ReturnURL
+		if returnURL == "" {
+			returnURL = req.SuccessURL
+		}
+		card := map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"verification": map[string]interface{}{"method": paypalVerificationMethod(req.ThreeDS.Mode)},
+			},
+			"experience_context": map[string]interface{}{
+				"return_url": returnURL,
+				"cancel_url": req.CancelURL,
+			},
+		}
+		if vaultID, ok := req.Extra["card_vault_id"].(string); ok && vaultID != "" {
+			card["vault_id"] = vaultID
+		}
+		paypalReq["payment_source"] = map[string]interface{}{"card": card}
+	}
+
 	body, _ := json.Marshal(paypalReq)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.apiBase+"/v2/checkout/orders", bytes.NewReader(body))
 	if err != nil {
@@ -134,35 +158,22 @@ func (a *PayPalAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 
 	respBody, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("创建 PayPal 订单失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+		return nil, wrapDecline(paypalDecline(resp.StatusCode, respBody), fmt.Errorf("创建 PayPal 订单失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody)))
 	}
 
-	var orderResp struct {
-		ID     string `json:"id"`
-		Status string `json:"status"`
-		Links  []struct {
-			Href string `json:"href"`
-			Rel  string `json:"rel"`
-		} `json:"links"`
-	}
-
-	if err := json.Unmarshal(respBody, &orderResp); err != nil {
-		return nil, fmt.Errorf("解析 PayPal 订单响应失败: %w", err)
+	orderResp, err := parsePayPalOrder(respBody)
+	if err != nil {
+		return nil, err
 	}
 
 	// 查找批准链接
-	var approveURL string
-	for _, link := range orderResp.Links {
-		if link.Rel == "approve" {
-			approveURL = link.Href
-			break
-		}
-	}
+	approveURL := orderResp.link("approve")
 
 	return &CreatePaymentResponse{
 		ChannelTradeNo: orderResp.ID,
 		PaymentURL:     approveURL,
 		Status:         convertPayPalStatus(orderResp.Status),
+		NextAction:     orderResp.nextAction(),
 		Extra: map[string]interface{}{
 			"order_id":    orderResp.ID,
 			"approve_url": approveURL,
@@ -227,10 +238,8 @@ func (a *PayPalAdapter) QueryPayment(ctx context.Context, channelTradeNo string)
 		pu := orderResp.PurchaseUnits[0]
 		response.Currency = pu.Amount.CurrencyCode
 
-		// 将金额字符串转换为分
-		var amountFloat float64
-		fmt.Sscanf(pu.Amount.Value, "%f", &amountFloat)
-		response.Amount = int64(amountFloat * 100)
+		// 将金额字符串转换为最小货币单位
+		response.Amount = parseChannelAmount(pu.Amount.Value, pu.Amount.CurrencyCode)
 
 		if len(pu.Payments.Captures) > 0 {
 			capture := pu.Payments.Captures[0]
@@ -275,7 +284,7 @@ func (a *PayPalAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 	refundReq := map[string]interface{}{
 		"amount": map[string]interface{}{
 			"currency_code": strings.ToUpper(req.Currency),
-			"value":         fmt.Sprintf("%.2f", float64(req.Amount)/100.0),
+			"value":         money.New(req.Amount, req.Currency).FormatMajor(),
 		},
 		"note_to_payer": req.Reason,
 	}
@@ -359,14 +368,10 @@ func (a *PayPalAdapter) QueryRefund(ctx context.Context, refundNo string) (*Quer
 		return nil, fmt.Errorf("解析退款响应失败: %w", err)
 	}
 
-	// 将金额字符串转换为分
-	var amountFloat float64
-	fmt.Sscanf(refundResp.Amount.Value, "%f", &amountFloat)
-
 	response := &QueryRefundResponse{
 		ChannelRefundNo: refundResp.ID,
 		Status:          convertPayPalRefundStatus(refundResp.Status),
-		Amount:          int64(amountFloat * 100),
+		Amount:          parseChannelAmount(refundResp.Amount.Value, refundResp.Amount.CurrencyCode),
 		Currency:        refundResp.Amount.CurrencyCode,
 	}
 
@@ -428,6 +433,9 @@ func (a *PayPalAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 		webhookEvent.Status = PaymentStatusSuccess
 	case "PAYMENT.CAPTURE.DECLINED", "CHECKOUT.ORDER.VOIDED":
 		webhookEvent.Status = PaymentStatusFailed
+		if event.EventType == "PAYMENT.CAPTURE.DECLINED" {
+			webhookEvent.Decline = paypalCaptureDecline(event.Resource)
+		}
 	case "PAYMENT.CAPTURE.REFUNDED":
 		webhookEvent.Status = PaymentStatusRefunded
 	default:
@@ -436,24 +444,242 @@ func (a *PayPalAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 
 	// 提取金额信息
 	if resource, ok := event.Resource["amount"].(map[string]interface{}); ok {
-		if value, ok := resource["value"].(string); ok {
-			var amountFloat float64
-			fmt.Sscanf(value, "%f", &amountFloat)
-			webhookEvent.Amount = int64(amountFloat * 100)
-		}
 		if currency, ok := resource["currency_code"].(string); ok {
 			webhookEvent.Currency = currency
 		}
+		if value, ok := resource["value"].(string); ok {
+			webhookEvent.Amount = parseChannelAmount(value, webhookEvent.Currency)
+		}
 	}
 
 	return webhookEvent, nil
 }
 
+// CompleteAuthentication 消费者完成 3DS 挑战（payer-action）返回后，按认证结果决定是否扣款
+//
+// 按 PayPal 建议的处理方式：可能发生责任转移（POSSIBLE/YES）时扣款；卡已注册 3DS 但认证失败、被拒绝或无法完成时不扣款；
+// 卡未注册或发卡行不支持 3DS 时继续扣款（不发生责任转移）。
+func (a *PayPalAdapter) CompleteAuthentication(ctx context.Context, req *CompleteAuthenticationRequest) (*CompleteAuthenticationResponse, error) {
+	status, body, err := a.doOrderRequest(ctx, "GET", "/v2/checkout/orders/"+req.ChannelTradeNo)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("查询 PayPal 订单失败，状态码: %d, 响应: %s", status, string(body))
+	}
+
+	order, err := parsePayPalOrder(body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CompleteAuthenticationResponse{
+		ChannelTradeNo: order.ID,
+		Status:         convertPayPalStatus(order.Status),
+		NextAction:     order.nextAction(),
+		Authentication: order.authentication(),
+	}
+	if order.Status != "APPROVED" {
+		return response, nil
+	}
+
+	if !order.canCapture() {
+		response.Status = PaymentStatusFailed
+		response.Decline = decline.New(decline.CategoryAuthenticationRequired, model.ChannelPayPal, "AUTHENTICATION_FAILED",
+			"3DS authentication_status="+order.threeDSecure().AuthenticationStatus)
+		return response, nil
+	}
+
+	status, body, err = a.doOrderRequest(ctx, "POST", "/v2/checkout/orders/"+req.ChannelTradeNo+"/capture")
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusCreated && status != http.StatusOK {
+		return nil, wrapDecline(paypalDecline(status, body), fmt.Errorf("PayPal 订单扣款失败，状态码: %d, 响应: %s", status, string(body)))
+	}
+
+	var captured struct {
+		Status        string `json:"status"`
+		PurchaseUnits []struct {
+			Payments struct {
+				Captures []map[string]interface{} `json:"captures"`
+			} `json:"payments"`
+		} `json:"purchase_units"`
+	}
+	if err := json.Unmarshal(body, &captured); err != nil {
+		return nil, fmt.Errorf("解析 PayPal 扣款响应失败: %w", err)
+	}
+	response.Status = convertPayPalStatus(captured.Status)
+	response.NextAction = nil
+	if len(captured.PurchaseUnits) > 0 && len(captured.PurchaseUnits[0].Payments.Captures) > 0 {
+		capture := captured.PurchaseUnits[0].Payments.Captures[0]
+		switch capture["status"] {
+		case "DECLINED", "FAILED":
+			response.Status = PaymentStatusFailed
+			response.Decline = paypalCaptureDecline(capture)
+		case "PENDING":
+			response.Status = PaymentStatusProcessing
+		}
+		if id, ok := capture["id"].(string); ok {
+			response.Extra = map[string]interface{}{"capture_id": id}
+		}
+	}
+	return response, nil
+}
+
+// doOrderRequest 调用 PayPal Orders API，返回状态码和响应体
+func (a *PayPalAdapter) doOrderRequest(ctx context.Context, method, path string) (int, []byte, error) {
+	token, err := a.getAccessToken(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, a.apiBase+path, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("创建 PayPal 请求失败: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, nil, fmt.Errorf("调用 PayPal 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, nil
+}
+
+// paypalVerificationMethod 转换 3DS 执行方式为 PayPal 卡验证方式
+//
+// PayPal Orders API 不支持逐笔申请豁免，SCA_WHEN_REQUIRED 下由 PayPal 按监管要求和发卡行要求决定是否挑战。
+func paypalVerificationMethod(mode sca.Mode) string {
+	if mode == sca.ModeChallenge {
+		return "SCA_ALWAYS"
+	}
+	return "SCA_WHEN_REQUIRED"
+}
+
+// paypalThreeDSecure PayPal 卡支付 3DS 结果
+type paypalThreeDSecure struct {
+	EnrollmentStatus     string `json:"enrollment_status"`     // Y 已注册, N 未注册, U 不可用, B 已绕过
+	AuthenticationStatus string `json:"authentication_status"` // Y 成功, A 尝试, N 失败, R 拒绝, U 无法完成, C 需挑战
+}
+
+// paypalOrder PayPal 订单（3DS 处理需要的字段）
+type paypalOrder struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	PaymentSource struct {
+		Card *struct {
+			AuthenticationResult *struct {
+				LiabilityShift string              `json:"liability_shift"` // POSSIBLE, YES, NO, UNKNOWN
+				ThreeDSecure   *paypalThreeDSecure `json:"three_d_secure"`
+			} `json:"authentication_result"`
+		} `json:"card"`
+	} `json:"payment_source"`
+	Links []struct {
+		Href string `json:"href"`
+		Rel  string `json:"rel"`
+	} `json:"links"`
+}
+
+// parsePayPalOrder 解析 PayPal 订单响应
+func parsePayPalOrder(body []byte) (*paypalOrder, error) {
+	var order paypalOrder
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析 PayPal 订单响应失败: %w", err)
+	}
+	return &order, nil
+}
+
+// link 按 rel 查找链接
+func (o *paypalOrder) link(rel string) string {
+	for _, link := range o.Links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// nextAction 订单等待消费者完成 3DS 时返回 payer-action 跳转
+func (o *paypalOrder) nextAction() *sca.NextAction {
+	if o.Status != "PAYER_ACTION_REQUIRED" {
+		return nil
+	}
+	if href := o.link("payer-action"); href != "" {
+		return &sca.NextAction{Type: sca.ActionRedirect, RedirectURL: href}
+	}
+	return nil
+}
+
+// threeDSecure 返回 3DS 结果，未执行 3DS 时返回空结构
+func (o *paypalOrder) threeDSecure() paypalThreeDSecure {
+	if card := o.PaymentSource.Card; card != nil && card.AuthenticationResult != nil && card.AuthenticationResult.ThreeDSecure != nil {
+		return *card.AuthenticationResult.ThreeDSecure
+	}
+	return paypalThreeDSecure{}
+}
+
+// liabilityShift 返回责任转移结果
+func (o *paypalOrder) liabilityShift() string {
+	if card := o.PaymentSource.Card; card != nil && card.AuthenticationResult != nil {
+		return card.AuthenticationResult.LiabilityShift
+	}
+	return ""
+}
+
+// authentication 统一的 3DS 认证结果，非卡支付返回 nil
+func (o *paypalOrder) authentication() *sca.Result {
+	if o.PaymentSource.Card == nil {
+		return nil
+	}
+	tds := o.threeDSecure()
+	status := sca.StatusNotPerformed
+	switch tds.AuthenticationStatus {
+	case "Y":
+		status = sca.StatusAuthenticated
+	case "A":
+		status = sca.StatusAttempted
+	case "N", "R", "U":
+		status = sca.StatusFailed
+	}
+	shift := o.liabilityShift()
+	return &sca.Result{
+		Status:         status,
+		LiabilityShift: shift == "POSSIBLE" || shift == "YES",
+		Reason:         strings.TrimSpace(shift + " " + tds.EnrollmentStatus + tds.AuthenticationStatus),
+	}
+}
+
+// canCapture 按 3DS 结果判断是否继续扣款
+func (o *paypalOrder) canCapture() bool {
+	if o.PaymentSource.Card == nil {
+		return true
+	}
+	switch o.liabilityShift() {
+	case "POSSIBLE", "YES":
+		return true
+	case "UNKNOWN":
+		return false
+	}
+	tds := o.threeDSecure()
+	// 卡已注册 3DS 但认证未通过
+	if tds.EnrollmentStatus == "Y" && tds.AuthenticationStatus != "Y" && tds.AuthenticationStatus != "A" {
+		return false
+	}
+	return true
+}
+
 // convertPayPalStatus 转换 PayPal 订单状态为统一状态
 func convertPayPalStatus(status string) string {
 	switch status {
-	case "CREATED", "SAVED", "APPROVED", "PAYER_ACTION_REQUIRED":
+	case "CREATED", "SAVED", "APPROVED":
 		return PaymentStatusPending
+	case "PAYER_ACTION_REQUIRED":
+		return PaymentStatusRequiresAction
 	case "COMPLETED":
 		return PaymentStatusSuccess
 	case "VOIDED":
@@ -492,3 +718,81 @@ func convertPayPalEventType(eventType string) string {
 		return eventType
 	}
 }
+
+// paypalDeclineCodes PayPal 错误码到拒绝分类的映射（API 错误 issue/name、扣款拒绝原因及处理方响应码）
+var paypalDeclineCodes = decline.CodeTable{
+	"INSTRUMENT_DECLINED":                     decline.CategoryDoNotHonor,
+	"TRANSACTION_REFUSED":                     decline.CategoryDoNotHonor,
+	"REDIRECT_PAYER_FOR_ALTERNATE_FUNDING":    decline.CategoryDoNotHonor,
+	"PAYER_ACTION_REQUIRED":                   decline.CategoryAuthenticationRequired,
+	"PAYER_CANNOT_PAY":                        decline.CategoryRestricted,
+	"PAYER_ACCOUNT_RESTRICTED":                decline.CategoryRestricted,
+	"PAYER_ACCOUNT_LOCKED_OR_CLOSED":          decline.CategoryRestricted,
+	"PAYEE_ACCOUNT_RESTRICTED":                decline.CategoryRestricted,
+	"PAYEE_NOT_ENABLED_FOR_CARD_PROCESSING":   decline.CategoryRestricted,
+	"CURRENCY_NOT_SUPPORTED":                  decline.CategoryRestricted,
+	"COMPLIANCE_VIOLATION":                    decline.CategoryRestricted,
+	"CARD_EXPIRED":                            decline.CategoryCardExpired,
+	"CARD_CLOSED":                             decline.CategoryInvalidCard,
+	"INVALID_SECURITY_CODE_LENGTH":            decline.CategoryInvalidCard,
+	"MAX_NUMBER_OF_PAYMENT_ATTEMPTS_EXCEEDED": decline.CategoryLimitExceeded,
+	"TRANSACTION_LIMIT_EXCEEDED":              decline.CategoryLimitExceeded,
+	"DUPLICATE_INVOICE_ID":                    decline.CategoryDuplicate,
+	"INVALID_REQUEST":                         decline.CategoryInvalidRequest,
+	"INVALID_PARAMETER_VALUE":                 decline.CategoryInvalidRequest,
+	"MISSING_REQUIRED_PARAMETER":              decline.CategoryInvalidRequest,
+	"UNPROCESSABLE_ENTITY":                    decline.CategoryInvalidRequest,
+	"INTERNAL_SERVER_ERROR":                   decline.CategoryChannelUnavailable,
+	"INTERNAL_SERVICE_ERROR":                  decline.CategoryChannelUnavailable,
+	"RATE_LIMIT_REACHED":                      decline.CategoryChannelUnavailable,
+	// 处理方响应码（processor_response.response_code）
+	"0500": decline.CategoryDoNotHonor,
+	"5100": decline.CategoryDoNotHonor,
+	"5110": decline.CategoryInvalidCard,
+	"1330": decline.CategoryInvalidCard,
+	"5120": decline.CategoryInsufficientFunds,
+	"5400": decline.CategoryCardExpired,
+	"5650": decline.CategoryAuthenticationRequired,
+	"9500": decline.CategoryFraudSuspected,
+}
+
+// paypalDecline 解析 PayPal 错误响应并归一化为拒绝原因（details[0].issue 优先，其次 name），
+// 无法解析且为 5xx/429 时视为渠道不可用，其他情况返回 nil
+func paypalDecline(statusCode int, body []byte) *decline.Decline {
+	var paypalErr struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+		Details []struct {
+			Issue       string `json:"issue"`
+			Description string `json:"description"`
+		} `json:"details"`
+	}
+	_ = json.Unmarshal(body, &paypalErr)
+
+	code, message := paypalErr.Name, paypalErr.Message
+	if len(paypalErr.Details) > 0 && paypalErr.Details[0].Issue != "" {
+		code, message = paypalErr.Details[0].Issue, paypalErr.Details[0].Description
+	}
+	if code == "" {
+		if statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests {
+			return decline.New(decline.CategoryChannelUnavailable, model.ChannelPayPal, fmt.Sprintf("HTTP_%d", statusCode), string(body))
+		}
+		return nil
+	}
+	return paypalDeclineCodes.Classify(model.ChannelPayPal, code, message)
+}
+
+// paypalCaptureDecline 扣款被拒绝时的拒绝原因（处理方响应码优先，其次 status_details.reason）
+func paypalCaptureDecline(resource map[string]interface{}) *decline.Decline {
+	if processor, ok := resource["processor_response"].(map[string]interface{}); ok {
+		if code, ok := processor["response_code"].(string); ok && code != "" {
+			return paypalDeclineCodes.Classify(model.ChannelPayPal, code, "")
+		}
+	}
+	if details, ok := resource["status_details"].(map[string]interface{}); ok {
+		if reason, ok := details["reason"].(string); ok && reason != "" {
+			return paypalDeclineCodes.Classify(model.ChannelPayPal, reason, "")
+		}
+	}
+	return paypalDeclineCodes.Classify(model.ChannelPayPal, "INSTRUMENT_DECLINED", "")
+}
@@ -25,3 +25,11 @@ func (d *DefaultPreAuthNotSupported) CancelPreAuth(ctx context.Context, req *Can
 func (d *DefaultPreAuthNotSupported) QueryPreAuth(ctx context.Context, channelPreAuthNo string) (*QueryPreAuthResponse, error) {
 	return nil, fmt.Errorf("当前支付渠道不支持预授权功能")
 }
+
+func (d *DefaultPreAuthNotSupported) IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error) {
+	return nil, fmt.Errorf("当前支付渠道不支持增量授权")
+}
+
+func (d *DefaultPreAuthNotSupported) ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error) {
+	return nil, fmt.Errorf("当前支付渠道不支持重新授权")
+}
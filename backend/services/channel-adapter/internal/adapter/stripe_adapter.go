@@ -3,28 +3,30 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/sca"
 	"payment-platform/channel-adapter/internal/model"
 	"github.com/stripe/stripe-go/v76"
-	"github.com/stripe/stripe-go/v76/charge"
-	"github.com/stripe/stripe-go/v76/paymentintent"
-	"github.com/stripe/stripe-go/v76/refund"
+	stripeclient "github.com/stripe/stripe-go/v76/client"
 	"github.com/stripe/stripe-go/v76/webhook"
 )
 
 // StripeAdapter Stripe 支付适配器
 type StripeAdapter struct {
 	config *model.StripeConfig
+	client *stripeclient.API // 每个适配器使用独立客户端，不同商户的凭证互不影响
 }
 
 // NewStripeAdapter 创建 Stripe 适配器实例
 func NewStripeAdapter(config *model.StripeConfig) *StripeAdapter {
-	// 设置 Stripe API 密钥
-	stripe.Key = config.APIKey
-
 	return &StripeAdapter{
 		config: config,
+		client: stripeclient.New(config.APIKey, nil),
 	}
 }
 
@@ -37,7 +39,7 @@ func (a *StripeAdapter) GetChannel() string {
 func (a *StripeAdapter) CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*CreatePaymentResponse, error) {
 	// 创建 PaymentIntent
 	params := &stripe.PaymentIntentParams{
-		Amount:      stripe.Int64(req.Amount),
+		Amount:      stripe.Int64(ConvertAmountToStripe(req.Amount, req.Currency)),
 		Currency:    stripe.String(req.Currency),
 		Description: stripe.String(req.Description),
 		Metadata: map[string]string{
@@ -66,10 +68,38 @@ func (a *StripeAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 		Enabled: stripe.Bool(true),
 	}
 
+	// 3DS/SCA 要求
+	// Stripe 不支持逐笔指定豁免类型，automatic 模式下由 Stripe 按自身风险评估向发卡行申请豁免，
+	// 平台申请的豁免记录在 metadata 中便于对账
+	if req.ThreeDS != nil {
+		params.PaymentMethodOptions = &stripe.PaymentIntentPaymentMethodOptionsParams{
+			Card: &stripe.PaymentIntentPaymentMethodOptionsCardParams{
+				RequestThreeDSecure: stripe.String(stripeThreeDSecureMode(req.ThreeDS.Mode)),
+			},
+		}
+		if req.ThreeDS.Exemption != sca.ExemptionNone {
+			params.Metadata["sca_exemption"] = string(req.ThreeDS.Exemption)
+		}
+	}
+
+	// 前端已收集支付方式时由服务端直接确认，需要挑战时返回 next_action
+	if pm, ok := req.Extra["payment_method"].(string); ok && pm != "" {
+		params.PaymentMethod = stripe.String(pm)
+		params.Confirm = stripe.Bool(true)
+		returnURL := req.SuccessURL
+		if req.ThreeDS != nil && req.ThreeDS.ReturnURL != "" {
+			returnURL = req.ThreeDS.ReturnURL
+		}
+		if returnURL != "" {
+			params.ReturnURL = stripe.String(returnURL)
+		}
+		params.AddExpand("latest_charge")
+	}
+
 	// 调用 Stripe API 创建支付意图
-	pi, err := paymentintent.New(params)
+	pi, err := a.client.PaymentIntents.New(params)
 	if err != nil {
-		return nil, fmt.Errorf("创建 Stripe PaymentIntent 失败: %w", err)
+		return nil, wrapDecline(stripeDecline(err), fmt.Errorf("创建 Stripe PaymentIntent 失败: %w", err))
 	}
 
 	// 构造响应
@@ -77,6 +107,8 @@ func (a *StripeAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 		ChannelTradeNo: pi.ID,
 		ClientSecret:   pi.ClientSecret,
 		Status:         convertStripeStatus(pi.Status),
+		NextAction:     stripeNextAction(pi),
+		Authentication: stripeAuthentication(pi.LatestCharge),
 		Extra: map[string]interface{}{
 			"payment_intent_id": pi.ID,
 			"client_secret":     pi.ClientSecret,
@@ -86,10 +118,56 @@ func (a *StripeAdapter) CreatePayment(ctx context.Context, req *CreatePaymentReq
 	return response, nil
 }
 
+// CompleteAuthentication 消费者完成 3DS 挑战后查询 PaymentIntent 结果
+//
+// Stripe 在挑战完成后自动继续授权，这里只需要读取最新状态；前端 SDK 尚未确认的 PaymentIntent 由服务端确认。
+func (a *StripeAdapter) CompleteAuthentication(ctx context.Context, req *CompleteAuthenticationRequest) (*CompleteAuthenticationResponse, error) {
+	params := &stripe.PaymentIntentParams{}
+	params.Context = ctx
+	params.AddExpand("latest_charge")
+	pi, err := a.client.PaymentIntents.Get(req.ChannelTradeNo, params)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Stripe PaymentIntent 失败: %w", err)
+	}
+
+	if pi.Status == stripe.PaymentIntentStatusRequiresConfirmation {
+		confirmParams := &stripe.PaymentIntentConfirmParams{}
+		confirmParams.Context = ctx
+		confirmParams.AddExpand("latest_charge")
+		pi, err = a.client.PaymentIntents.Confirm(req.ChannelTradeNo, confirmParams)
+		if err != nil {
+			return nil, wrapDecline(stripeDecline(err), fmt.Errorf("确认 Stripe PaymentIntent 失败: %w", err))
+		}
+	}
+
+	response := &CompleteAuthenticationResponse{
+		ChannelTradeNo: pi.ID,
+		Status:         convertStripeStatus(pi.Status),
+		NextAction:     stripeNextAction(pi),
+		Authentication: stripeAuthentication(pi.LatestCharge),
+	}
+
+	// 挑战失败或被放弃时 PaymentIntent 回到 requires_payment_method
+	if pi.Status == stripe.PaymentIntentStatusRequiresPaymentMethod {
+		response.Status = PaymentStatusFailed
+		if pi.LastPaymentError != nil {
+			response.Decline = stripeDecline(pi.LastPaymentError)
+		}
+		if response.Decline == nil {
+			response.Decline = decline.New(decline.CategoryAuthenticationRequired, model.ChannelStripe, "payment_intent_authentication_failure", "")
+		}
+		if response.Authentication == nil {
+			response.Authentication = &sca.Result{Status: sca.StatusFailed}
+		}
+	}
+
+	return response, nil
+}
+
 // QueryPayment 查询支付状态
 func (a *StripeAdapter) QueryPayment(ctx context.Context, channelTradeNo string) (*QueryPaymentResponse, error) {
 	// 查询 PaymentIntent
-	pi, err := paymentintent.Get(channelTradeNo, nil)
+	pi, err := a.client.PaymentIntents.Get(channelTradeNo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("查询 Stripe PaymentIntent 失败: %w", err)
 	}
@@ -98,7 +176,7 @@ func (a *StripeAdapter) QueryPayment(ctx context.Context, channelTradeNo string)
 	response := &QueryPaymentResponse{
 		ChannelTradeNo: pi.ID,
 		Status:         convertStripeStatus(pi.Status),
-		Amount:         pi.Amount,
+		Amount:         ConvertAmountFromStripe(pi.Amount, string(pi.Currency)),
 		Currency:       string(pi.Currency),
 	}
 
@@ -110,7 +188,7 @@ func (a *StripeAdapter) QueryPayment(ctx context.Context, channelTradeNo string)
 
 	// 支付方式详情 - 通过 LatestCharge 获取
 	if pi.LatestCharge != nil && pi.LatestCharge.ID != "" {
-		ch, err := charge.Get(pi.LatestCharge.ID, nil)
+		ch, err := a.client.Charges.Get(pi.LatestCharge.ID, nil)
 		if err == nil && ch.PaymentMethodDetails != nil {
 			response.PaymentMethod = string(ch.PaymentMethodDetails.Type)
 
@@ -137,7 +215,7 @@ func (a *StripeAdapter) QueryPayment(ctx context.Context, channelTradeNo string)
 func (a *StripeAdapter) CancelPayment(ctx context.Context, channelTradeNo string) error {
 	// 取消 PaymentIntent
 	params := &stripe.PaymentIntentCancelParams{}
-	_, err := paymentintent.Cancel(channelTradeNo, params)
+	_, err := a.client.PaymentIntents.Cancel(channelTradeNo, params)
 	if err != nil {
 		return fmt.Errorf("取消 Stripe PaymentIntent 失败: %w", err)
 	}
@@ -150,7 +228,7 @@ func (a *StripeAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 	// 创建退款
 	params := &stripe.RefundParams{
 		PaymentIntent: stripe.String(req.ChannelTradeNo),
-		Amount:        stripe.Int64(req.Amount),
+		Amount:        stripe.Int64(ConvertAmountToStripe(req.Amount, req.Currency)),
 		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
 		Metadata: map[string]string{
 			"refund_no":  req.RefundNo,
@@ -159,7 +237,7 @@ func (a *StripeAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 		},
 	}
 
-	r, err := refund.New(params)
+	r, err := a.client.Refunds.New(params)
 	if err != nil {
 		return nil, fmt.Errorf("创建 Stripe Refund 失败: %w", err)
 	}
@@ -180,7 +258,7 @@ func (a *StripeAdapter) CreateRefund(ctx context.Context, req *CreateRefundReque
 // QueryRefund 查询退款状态
 func (a *StripeAdapter) QueryRefund(ctx context.Context, refundNo string) (*QueryRefundResponse, error) {
 	// 查询退款
-	r, err := refund.Get(refundNo, nil)
+	r, err := a.client.Refunds.Get(refundNo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("查询 Stripe Refund 失败: %w", err)
 	}
@@ -190,7 +268,7 @@ func (a *StripeAdapter) QueryRefund(ctx context.Context, refundNo string) (*Quer
 		RefundNo:        r.Metadata["refund_no"],
 		ChannelRefundNo: r.ID,
 		Status:          convertRefundStatus(r.Status),
-		Amount:          r.Amount,
+		Amount:          ConvertAmountFromStripe(r.Amount, string(r.Currency)),
 		Currency:        string(r.Currency),
 	}
 
@@ -239,7 +317,7 @@ func (a *StripeAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 		webhookEvent.ChannelTradeNo = pi.ID
 		webhookEvent.PaymentNo = pi.Metadata["payment_no"]
 		webhookEvent.Status = PaymentStatusSuccess
-		webhookEvent.Amount = pi.Amount
+		webhookEvent.Amount = ConvertAmountFromStripe(pi.Amount, string(pi.Currency))
 		webhookEvent.Currency = string(pi.Currency)
 
 	case "payment_intent.payment_failed":
@@ -250,8 +328,11 @@ func (a *StripeAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 		webhookEvent.ChannelTradeNo = pi.ID
 		webhookEvent.PaymentNo = pi.Metadata["payment_no"]
 		webhookEvent.Status = PaymentStatusFailed
-		webhookEvent.Amount = pi.Amount
+		webhookEvent.Amount = ConvertAmountFromStripe(pi.Amount, string(pi.Currency))
 		webhookEvent.Currency = string(pi.Currency)
+		if pi.LastPaymentError != nil {
+			webhookEvent.Decline = stripeDecline(pi.LastPaymentError)
+		}
 
 	case "payment_intent.canceled":
 		var pi stripe.PaymentIntent
@@ -261,7 +342,7 @@ func (a *StripeAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 		webhookEvent.ChannelTradeNo = pi.ID
 		webhookEvent.PaymentNo = pi.Metadata["payment_no"]
 		webhookEvent.Status = PaymentStatusCancelled
-		webhookEvent.Amount = pi.Amount
+		webhookEvent.Amount = ConvertAmountFromStripe(pi.Amount, string(pi.Currency))
 		webhookEvent.Currency = string(pi.Currency)
 
 	case "charge.refunded":
@@ -271,22 +352,81 @@ func (a *StripeAdapter) ParseWebhook(ctx context.Context, body []byte) (*Webhook
 		}
 		webhookEvent.ChannelTradeNo = charge.PaymentIntent.ID
 		webhookEvent.Status = PaymentStatusRefunded
-		webhookEvent.Amount = charge.AmountRefunded
+		webhookEvent.Amount = ConvertAmountFromStripe(charge.AmountRefunded, string(charge.Currency))
 		webhookEvent.Currency = string(charge.Currency)
 	}
 
 	return webhookEvent, nil
 }
 
+// stripeThreeDSecureMode 转换 3DS 执行方式为 Stripe request_three_d_secure 参数
+func stripeThreeDSecureMode(mode sca.Mode) string {
+	if mode == sca.ModeChallenge {
+		return string(stripe.PaymentIntentPaymentMethodOptionsCardRequestThreeDSecureChallenge)
+	}
+	return string(stripe.PaymentIntentPaymentMethodOptionsCardRequestThreeDSecureAutomatic)
+}
+
+// stripeNextAction 提取需要消费者完成的验证动作
+func stripeNextAction(pi *stripe.PaymentIntent) *sca.NextAction {
+	if pi.Status != stripe.PaymentIntentStatusRequiresAction || pi.NextAction == nil {
+		return nil
+	}
+	if pi.NextAction.Type == stripe.PaymentIntentNextActionTypeRedirectToURL && pi.NextAction.RedirectToURL != nil {
+		return &sca.NextAction{Type: sca.ActionRedirect, RedirectURL: pi.NextAction.RedirectToURL.URL}
+	}
+	// use_stripe_sdk 等类型由前端 Stripe.js 的 handleNextAction 处理
+	return &sca.NextAction{Type: sca.ActionSDK, ClientSecret: pi.ClientSecret}
+}
+
+// stripeThreeDSecureStatus Stripe 3DS 结果到统一认证结果的映射
+var stripeThreeDSecureStatus = map[stripe.ChargePaymentMethodDetailsCardThreeDSecureResult]sca.Status{
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultAuthenticated:       sca.StatusAuthenticated,
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultAttemptAcknowledged: sca.StatusAttempted,
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultExempted:            sca.StatusExempted,
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultFailed:              sca.StatusFailed,
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultProcessingError:     sca.StatusFailed,
+	stripe.ChargePaymentMethodDetailsCardThreeDSecureResultNotSupported:        sca.StatusNotPerformed,
+}
+
+// stripeAuthentication 从 Charge 的卡支付详情中提取 3DS 认证结果，非卡支付返回 nil
+func stripeAuthentication(ch *stripe.Charge) *sca.Result {
+	if ch == nil || ch.PaymentMethodDetails == nil || ch.PaymentMethodDetails.Card == nil {
+		return nil
+	}
+	tds := ch.PaymentMethodDetails.Card.ThreeDSecure
+	if tds == nil {
+		return &sca.Result{Status: sca.StatusNotPerformed}
+	}
+	status, ok := stripeThreeDSecureStatus[tds.Result]
+	if !ok {
+		status = sca.StatusNotPerformed
+	}
+	result := &sca.Result{
+		Status:         status,
+		Version:        tds.Version,
+		LiabilityShift: sca.HasLiabilityShift(status),
+		Reason:         string(tds.ResultReason),
+	}
+	// Stripe 申请并被发卡行接受的豁免只有 low_risk（TRA）
+	if tds.ExemptionIndicator == stripe.ChargePaymentMethodDetailsCardThreeDSecureExemptionIndicatorLowRisk {
+		result.Exemption = sca.ExemptionTRA
+	}
+	return result
+}
+
 // convertStripeStatus 转换 Stripe 支付状态为统一状态
 func convertStripeStatus(status stripe.PaymentIntentStatus) string {
 	switch status {
 	case stripe.PaymentIntentStatusRequiresPaymentMethod,
-		stripe.PaymentIntentStatusRequiresConfirmation,
-		stripe.PaymentIntentStatusRequiresAction:
+		stripe.PaymentIntentStatusRequiresConfirmation:
 		return PaymentStatusPending
+	case stripe.PaymentIntentStatusRequiresAction:
+		return PaymentStatusRequiresAction
 	case stripe.PaymentIntentStatusProcessing:
 		return PaymentStatusProcessing
+	case stripe.PaymentIntentStatusRequiresCapture:
+		return PaymentStatusAuthorized
 	case stripe.PaymentIntentStatusSucceeded:
 		return PaymentStatusSuccess
 	case stripe.PaymentIntentStatusCanceled:
@@ -328,44 +468,137 @@ func convertStripeEventType(eventType string) string {
 	}
 }
 
-// ConvertAmountToStripe 将金额（分）转换为 Stripe 金额
-// Stripe 对于零小数位货币（如 JPY、KRW），金额单位是最小单位（无需转换）
-// 对于两位小数货币（如 USD、EUR），金额单位是分（cents）
-// 对于三位小数货币（如 BHD、KWD），金额单位是 1/1000
-func ConvertAmountToStripe(amount int64, currency string) int64 {
-	// 零小数位货币列表
-	zeroDecimalCurrencies := map[string]bool{
-		"BIF": true, "CLP": true, "DJF": true, "GNF": true,
-		"JPY": true, "KMF": true, "KRW": true, "MGA": true,
-		"PYG": true, "RWF": true, "UGX": true, "VND": true,
-		"VUV": true, "XAF": true, "XOF": true, "XPF": true,
-	}
+// stripeDeclineCodes Stripe 错误码到拒绝分类的映射（发卡行 decline_code 与 Stripe 错误码共用一张表）
+var stripeDeclineCodes = decline.CodeTable{
+	"insufficient_funds":                    decline.CategoryInsufficientFunds,
+	"expired_card":                          decline.CategoryCardExpired,
+	"incorrect_number":                      decline.CategoryInvalidCard,
+	"invalid_number":                        decline.CategoryInvalidCard,
+	"incorrect_cvc":                         decline.CategoryInvalidCard,
+	"invalid_cvc":                           decline.CategoryInvalidCard,
+	"incorrect_zip":                         decline.CategoryInvalidCard,
+	"incorrect_address":                     decline.CategoryInvalidCard,
+	"incorrect_pin":                         decline.CategoryInvalidCard,
+	"invalid_pin":                           decline.CategoryInvalidCard,
+	"invalid_expiry_month":                  decline.CategoryInvalidCard,
+	"invalid_expiry_year":                   decline.CategoryInvalidCard,
+	"invalid_account":                       decline.CategoryInvalidCard,
+	"new_account_information_available":     decline.CategoryInvalidCard,
+	"lost_card":                             decline.CategoryLostOrStolen,
+	"stolen_card":                           decline.CategoryLostOrStolen,
+	"pickup_card":                           decline.CategoryLostOrStolen,
+	"fraudulent":                            decline.CategoryFraudSuspected,
+	"merchant_blacklist":                    decline.CategoryFraudSuspected,
+	"security_violation":                    decline.CategoryFraudSuspected,
+	"card_not_supported":                    decline.CategoryRestricted,
+	"currency_not_supported":                decline.CategoryRestricted,
+	"not_permitted":                         decline.CategoryRestricted,
+	"restricted_card":                       decline.CategoryRestricted,
+	"service_not_allowed":                   decline.CategoryRestricted,
+	"transaction_not_allowed":               decline.CategoryRestricted,
+	"stop_payment_order":                    decline.CategoryRestricted,
+	"revocation_of_authorization":           decline.CategoryRestricted,
+	"revocation_of_all_authorizations":      decline.CategoryRestricted,
+	"do_not_try_again":                      decline.CategoryRestricted,
+	"card_velocity_exceeded":                decline.CategoryLimitExceeded,
+	"withdrawal_count_limit_exceeded":       decline.CategoryLimitExceeded,
+	"pin_try_exceeded":                      decline.CategoryLimitExceeded,
+	"card_decline_rate_limit_exceeded":      decline.CategoryLimitExceeded,
+	"authentication_required":               decline.CategoryAuthenticationRequired,
+	"payment_intent_authentication_failure": decline.CategoryAuthenticationRequired,
+	"offline_pin_required":                  decline.CategoryAuthenticationRequired,
+	"online_or_offline_pin_required":        decline.CategoryAuthenticationRequired,
+	"card_declined":                         decline.CategoryDoNotHonor,
+	"do_not_honor":                          decline.CategoryDoNotHonor,
+	"generic_decline":                       decline.CategoryDoNotHonor,
+	"call_issuer":                           decline.CategoryDoNotHonor,
+	"approve_with_id":                       decline.CategoryDoNotHonor,
+	"no_action_taken":                       decline.CategoryDoNotHonor,
+	"reenter_transaction":                   decline.CategoryDoNotHonor,
+	"try_again_later":                       decline.CategoryDoNotHonor,
+	"issuer_not_available":                  decline.CategoryIssuerUnavailable,
+	"processing_error":                      decline.CategoryProcessingError,
+	"rate_limit":                            decline.CategoryChannelUnavailable,
+	"duplicate_transaction":                 decline.CategoryDuplicate,
+	"invalid_amount":                        decline.CategoryInvalidRequest,
+	"amount_too_large":                      decline.CategoryInvalidRequest,
+	"amount_too_small":                      decline.CategoryInvalidRequest,
+	"invalid_charge_amount":                 decline.CategoryInvalidRequest,
+}
 
-	// 如果是零小数位货币，直接返回
-	if zeroDecimalCurrencies[currency] {
-		return amount / 100 // 从分转换为主单位
+// stripeDecline 将 Stripe API 错误归一化为拒绝原因（decline_code 优先），非 Stripe API 错误返回 nil
+func stripeDecline(err error) *decline.Decline {
+	var stripeErr *stripe.Error
+	if !errors.As(err, &stripeErr) {
+		return nil
+	}
+	code := string(stripeErr.DeclineCode)
+	if code == "" {
+		code = string(stripeErr.Code)
+	}
+	if code == "" && stripeErr.Type == stripe.ErrorTypeAPI {
+		return decline.New(decline.CategoryChannelUnavailable, model.ChannelStripe, string(stripeErr.Type), stripeErr.Msg)
 	}
+	return stripeDeclineCodes.Classify(model.ChannelStripe, code, stripeErr.Msg)
+}
+
+// stripeTwoDecimalCurrencies ISO 4217 为无小数位、但 Stripe 为兼容历史仍按两位小数报送的货币（小数部分必须为 00）
+var stripeTwoDecimalCurrencies = map[string]bool{
+	"ISK": true,
+	"UGX": true,
+}
 
-	// 其他货币，Stripe 使用最小货币单位（分）
+// ConvertAmountToStripe 将系统金额（ISO 4217 最小货币单位）转换为 Stripe 金额
+// Stripe 的金额单位与 ISO 最小单位基本一致（USD 为分，JPY 为日元），例外：
+// ISK、UGX 按两位小数报送；三位小数货币（如 BHD、KWD）最后一位必须为 0，按银行家舍入到 10 的倍数
+//
+// 注意：旧版本中零小数位货币的预授权金额按“分”（主单位×100）存储，报送时除以 100；
+// 现在系统金额即 ISO 最小单位（JPY 1000 表示 1000 日元）。迁移前创建的在途预授权
+// 由 ChannelService 按预授权记录换算后再调用适配器（见 LegacyAmountToISO）
+func ConvertAmountToStripe(amount int64, currency string) int64 {
+	code := money.NormalizeCurrency(currency)
+	if stripeTwoDecimalCurrencies[code] {
+		return amount * 100
+	}
+	if money.Decimals(code) == 3 {
+		m := money.New(amount, code)
+		rounded, err := money.FromDecimal(m.Major().Round(2, money.RoundHalfEven), code, money.RoundDown)
+		if err == nil {
+			return rounded.Amount
+		}
+	}
 	return amount
 }
 
-// ConvertAmountFromStripe 将 Stripe 金额转换为系统金额（分）
+// ConvertAmountFromStripe 将 Stripe 金额转换为系统金额（ISO 4217 最小货币单位）
 func ConvertAmountFromStripe(amount int64, currency string) int64 {
-	// 零小数位货币列表
-	zeroDecimalCurrencies := map[string]bool{
-		"BIF": true, "CLP": true, "DJF": true, "GNF": true,
-		"JPY": true, "KMF": true, "KRW": true, "MGA": true,
-		"PYG": true, "RWF": true, "UGX": true, "VND": true,
-		"VUV": true, "XAF": true, "XOF": true, "XPF": true,
+	if stripeTwoDecimalCurrencies[money.NormalizeCurrency(currency)] {
+		return amount / 100
 	}
+	return amount
+}
 
-	// 如果是零小数位货币，需要乘以100转换为分
-	if zeroDecimalCurrencies[currency] {
-		return amount * 100
+// legacyZeroDecimalCurrencies 旧版本按“主单位×100”存储金额的零小数位货币
+var legacyZeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true,
+	"JPY": true, "KMF": true, "KRW": true, "MGA": true,
+	"PYG": true, "RWF": true, "UGX": true, "VND": true,
+	"VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// LegacyAmountToISO 将旧约定的金额（零小数位货币为主单位×100）换算为 ISO 4217 最小单位
+func LegacyAmountToISO(amount int64, currency string) int64 {
+	if legacyZeroDecimalCurrencies[money.NormalizeCurrency(currency)] {
+		return amount / 100
 	}
+	return amount
+}
 
-	// 其他货币直接返回
+// ISOAmountToLegacy 将 ISO 4217 最小单位换算为旧约定的金额
+func ISOAmountToLegacy(amount int64, currency string) int64 {
+	if legacyZeroDecimalCurrencies[money.NormalizeCurrency(currency)] {
+		return amount * 100
+	}
 	return amount
 }
 
@@ -394,10 +627,13 @@ func (a *StripeAdapter) CreatePreAuth(ctx context.Context, req *CreatePreAuthReq
 		Enabled: stripe.Bool(true),
 	}
 
+	// 卡支付尽量开启增量授权与多次确认（发卡行不支持时 Stripe 会忽略）
+	params.PaymentMethodOptions = stripePreAuthCardOptions()
+
 	// 调用 Stripe API 创建支付意图
-	pi, err := paymentintent.New(params)
+	pi, err := a.client.PaymentIntents.New(params)
 	if err != nil {
-		return nil, fmt.Errorf("创建 Stripe 预授权失败: %w", err)
+		return nil, wrapDecline(stripeDecline(err), fmt.Errorf("创建 Stripe 预授权失败: %w", err))
 	}
 
 	// 计算过期时间（Stripe PaymentIntent 默认24小时过期）
@@ -428,17 +664,30 @@ func (a *StripeAdapter) CapturePreAuth(ctx context.Context, req *CapturePreAuthR
 		params.AmountToCapture = stripe.Int64(ConvertAmountToStripe(req.Amount, req.Currency))
 	}
 
+	// 非最后一次确认时保留剩余冻结金额（需要 PaymentIntent 支持 multicapture）
+	if req.FinalCapture != nil {
+		params.FinalCapture = stripe.Bool(*req.FinalCapture)
+	}
+
 	// 调用 Stripe API 确认支付
-	pi, err := paymentintent.Capture(req.ChannelPreAuthNo, params)
+	pi, err := a.client.PaymentIntents.Capture(req.ChannelPreAuthNo, params)
 	if err != nil {
 		return nil, fmt.Errorf("确认 Stripe 预授权失败: %w", err)
 	}
 
+	capturedAmount := ConvertAmountFromStripe(pi.AmountReceived, req.Currency)
+	amount := req.Amount
+	if amount <= 0 {
+		amount = capturedAmount
+	}
+
 	return &CapturePreAuthResponse{
 		ChannelTradeNo:   pi.ID,
 		ChannelPreAuthNo: pi.ID,
 		Status:           convertStripeStatus(pi.Status),
-		Amount:           ConvertAmountFromStripe(pi.AmountCapturable, req.Currency),
+		Amount:           amount,
+		CapturedAmount:   capturedAmount,
+		CapturableAmount: ConvertAmountFromStripe(pi.AmountCapturable, req.Currency),
 		Extra: map[string]interface{}{
 			"payment_intent_id": pi.ID,
 			"captured_at":       pi.Created,
@@ -458,7 +707,7 @@ func (a *StripeAdapter) CancelPreAuth(ctx context.Context, req *CancelPreAuthReq
 	}
 
 	// 调用 Stripe API 取消支付意图
-	pi, err := paymentintent.Cancel(req.ChannelPreAuthNo, params)
+	pi, err := a.client.PaymentIntents.Cancel(req.ChannelPreAuthNo, params)
 	if err != nil {
 		return nil, fmt.Errorf("取消 Stripe 预授权失败: %w", err)
 	}
@@ -476,7 +725,7 @@ func (a *StripeAdapter) CancelPreAuth(ctx context.Context, req *CancelPreAuthReq
 // QueryPreAuth 查询预授权状态
 func (a *StripeAdapter) QueryPreAuth(ctx context.Context, channelPreAuthNo string) (*QueryPreAuthResponse, error) {
 	// 查询 PaymentIntent
-	pi, err := paymentintent.Get(channelPreAuthNo, nil)
+	pi, err := a.client.PaymentIntents.Get(channelPreAuthNo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("查询 Stripe 预授权失败: %w", err)
 	}
@@ -512,3 +761,114 @@ func (a *StripeAdapter) QueryPreAuth(ctx context.Context, channelPreAuthNo strin
 		},
 	}, nil
 }
+
+// IncrementPreAuth 增量授权（increment_authorization PaymentIntent）
+//
+// 仅当创建预授权时发卡行支持 incremental authorization 才能调用，Amount 为增量后的授权总金额。
+func (a *StripeAdapter) IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error) {
+	params := &stripe.PaymentIntentIncrementAuthorizationParams{
+		Amount: stripe.Int64(ConvertAmountToStripe(req.Amount, req.Currency)),
+	}
+	if req.Description != "" {
+		params.Description = stripe.String(req.Description)
+	}
+
+	pi, err := a.client.PaymentIntents.IncrementAuthorization(req.ChannelPreAuthNo, params)
+	if err != nil {
+		return nil, wrapDecline(stripeDecline(err), fmt.Errorf("Stripe 增量授权失败: %w", err))
+	}
+
+	return &IncrementPreAuthResponse{
+		ChannelPreAuthNo: pi.ID,
+		Status:           convertStripeStatus(pi.Status),
+		Amount:           ConvertAmountFromStripe(pi.Amount, req.Currency),
+		CapturableAmount: ConvertAmountFromStripe(pi.AmountCapturable, req.Currency),
+		Extra: map[string]interface{}{
+			"payment_intent_id": pi.ID,
+			"amount_capturable": pi.AmountCapturable,
+		},
+	}, nil
+}
+
+// ReauthorizePreAuth 重新授权
+//
+// Stripe 不支持延长已有授权的有效期，这里使用原 PaymentIntent 的支付方式离线创建并确认一个新的
+// manual capture PaymentIntent，新授权成功后再取消原授权；要求原支付方式已关联 Stripe Customer。
+func (a *StripeAdapter) ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error) {
+	previous, err := a.client.PaymentIntents.Get(req.ChannelPreAuthNo, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Stripe 原预授权失败: %w", err)
+	}
+	if previous.Status != stripe.PaymentIntentStatusRequiresCapture {
+		return nil, fmt.Errorf("Stripe 原预授权状态不允许重新授权: %s", previous.Status)
+	}
+	if previous.PaymentMethod == nil || previous.Customer == nil {
+		return nil, fmt.Errorf("Stripe 原预授权未关联 Customer 与支付方式，无法离线重新授权")
+	}
+
+	params := &stripe.PaymentIntentParams{
+		Amount:               stripe.Int64(ConvertAmountToStripe(req.Amount, req.Currency)),
+		Currency:             stripe.String(req.Currency),
+		Customer:             stripe.String(previous.Customer.ID),
+		PaymentMethod:        stripe.String(previous.PaymentMethod.ID),
+		CaptureMethod:        stripe.String("manual"),
+		Confirm:              stripe.Bool(true),
+		OffSession:           stripe.Bool(true),
+		PaymentMethodOptions: stripePreAuthCardOptions(),
+		Metadata: map[string]string{
+			"pre_auth_no":       req.PreAuthNo,
+			"order_no":          req.OrderNo,
+			"type":              "pre_auth",
+			"reauthorized_from": previous.ID,
+		},
+	}
+	if req.Description != "" {
+		params.Description = stripe.String(req.Description)
+	}
+
+	pi, err := a.client.PaymentIntents.New(params)
+	if err != nil {
+		return nil, wrapDecline(stripeDecline(err), fmt.Errorf("Stripe 重新授权失败: %w", err))
+	}
+	if pi.Status != stripe.PaymentIntentStatusRequiresCapture {
+		// 离线确认需要持卡人验证等情况下不会进入待确认状态，放弃新授权，原授权保持不变
+		_, _ = a.client.PaymentIntents.Cancel(pi.ID, &stripe.PaymentIntentCancelParams{
+			CancellationReason: stripe.String("abandoned"),
+		})
+		return nil, fmt.Errorf("Stripe 重新授权未成功冻结资金: status=%s", pi.Status)
+	}
+
+	extra := map[string]interface{}{
+		"payment_intent_id":          pi.ID,
+		"previous_payment_intent_id": previous.ID,
+	}
+
+	// 新授权已冻结资金，释放原授权；失败不影响新授权，记录下来由人工处理
+	if _, err := a.client.PaymentIntents.Cancel(previous.ID, &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String("abandoned"),
+	}); err != nil {
+		extra["previous_cancel_error"] = err.Error()
+	}
+
+	// Stripe 线上卡授权默认 7 天有效
+	expiresAt := time.Now().Add(7 * 24 * time.Hour).Unix()
+
+	return &ReauthorizePreAuthResponse{
+		ChannelPreAuthNo:         pi.ID,
+		PreviousChannelPreAuthNo: previous.ID,
+		Status:                   convertStripeStatus(pi.Status),
+		Amount:                   ConvertAmountFromStripe(pi.AmountCapturable, req.Currency),
+		ExpiresAt:                &expiresAt,
+		Extra:                    extra,
+	}, nil
+}
+
+// stripePreAuthCardOptions 预授权的卡支付选项：按可用性请求增量授权与多次确认
+func stripePreAuthCardOptions() *stripe.PaymentIntentPaymentMethodOptionsParams {
+	return &stripe.PaymentIntentPaymentMethodOptionsParams{
+		Card: &stripe.PaymentIntentPaymentMethodOptionsCardParams{
+			RequestIncrementalAuthorization: stripe.String("if_available"),
+			RequestMulticapture:             stripe.String("if_available"),
+		},
+	}
+}
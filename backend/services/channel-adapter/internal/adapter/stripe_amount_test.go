@@ -0,0 +1,50 @@
+package adapter
+
+import "testing"
+
+func TestConvertAmountToStripe(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     int64
+	}{
+		{1050, "USD", 1050},   // 10.50 美元
+		{1000, "JPY", 1000},   // 1000 日元（ISO 无小数位）
+		{1000, "jpy", 1000},   // 小写币种
+		{500, "ISK", 50000},   // Stripe 按两位小数报送
+		{12345, "KWD", 12340}, // 三位小数最后一位必须为 0，银行家舍入
+		{12355, "KWD", 12360},
+	}
+	for _, tt := range tests {
+		if got := ConvertAmountToStripe(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("ConvertAmountToStripe(%d, %s) = %d, want %d", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+
+	if got := ConvertAmountFromStripe(50000, "ISK"); got != 500 {
+		t.Errorf("ConvertAmountFromStripe(50000, ISK) = %d, want 500", got)
+	}
+	if got := ConvertAmountFromStripe(1000, "JPY"); got != 1000 {
+		t.Errorf("ConvertAmountFromStripe(1000, JPY) = %d, want 1000", got)
+	}
+}
+
+func TestLegacyAmountConversion(t *testing.T) {
+	// 迁移前创建的在途预授权：系统中 1000 日元存为 100000
+	if got := LegacyAmountToISO(100000, "jpy"); got != 1000 {
+		t.Fatalf("LegacyAmountToISO(JPY) = %d, want 1000", got)
+	}
+	if got := ISOAmountToLegacy(1000, "JPY"); got != 100000 {
+		t.Fatalf("ISOAmountToLegacy(JPY) = %d, want 100000", got)
+	}
+
+	// 旧约定只影响零小数位货币
+	for _, currency := range []string{"USD", "EUR", "KWD"} {
+		if got := LegacyAmountToISO(12340, currency); got != 12340 {
+			t.Errorf("LegacyAmountToISO(%s) = %d, want 12340", currency, got)
+		}
+		if got := ISOAmountToLegacy(12340, currency); got != 12340 {
+			t.Errorf("ISOAmountToLegacy(%s) = %d, want 12340", currency, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/payment-platform/pkg/sca"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestPayPalOrderAuthentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		status     sca.Status
+		shift      bool
+		canCapture bool
+	}{
+		{"认证成功", `{"id":"O1","status":"APPROVED","payment_source":{"card":{"authentication_result":{"liability_shift":"POSSIBLE","three_d_secure":{"enrollment_status":"Y","authentication_status":"Y"}}}}}`, sca.StatusAuthenticated, true, true},
+		{"认证失败", `{"id":"O2","status":"APPROVED","payment_source":{"card":{"authentication_result":{"liability_shift":"NO","three_d_secure":{"enrollment_status":"Y","authentication_status":"N"}}}}}`, sca.StatusFailed, false, false},
+		{"卡未注册", `{"id":"O3","status":"APPROVED","payment_source":{"card":{"authentication_result":{"liability_shift":"NO","three_d_secure":{"enrollment_status":"N"}}}}}`, sca.StatusNotPerformed, false, true},
+		{"结果未知", `{"id":"O4","status":"APPROVED","payment_source":{"card":{"authentication_result":{"liability_shift":"UNKNOWN"}}}}`, sca.StatusNotPerformed, false, false},
+	}
+	for _, tt := range tests {
+		order, err := parsePayPalOrder([]byte(tt.body))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result := order.authentication()
+		if result.Status != tt.status || result.LiabilityShift != tt.shift {
+			t.Errorf("%s: authentication = %+v", tt.name, result)
+		}
+		if order.canCapture() != tt.canCapture {
+			t.Errorf("%s: canCapture = %v, want %v", tt.name, order.canCapture(), tt.canCapture)
+		}
+	}
+
+	// 等待消费者完成挑战
+	order, _ := parsePayPalOrder([]byte(`{"id":"O5","status":"PAYER_ACTION_REQUIRED","links":[{"href":"https://www.paypal.com/webapps/helios?action=verify","rel":"payer-action"}]}`))
+	if action := order.nextAction(); action == nil || action.Type != sca.ActionRedirect || action.RedirectURL == "" {
+		t.Fatalf("nextAction = %+v", action)
+	}
+	if convertPayPalStatus(order.Status) != PaymentStatusRequiresAction {
+		t.Fatalf("status = %s", convertPayPalStatus(order.Status))
+	}
+}
+
+func TestStripeAuthentication(t *testing.T) {
+	ch := &stripe.Charge{PaymentMethodDetails: &stripe.ChargePaymentMethodDetails{Card: &stripe.ChargePaymentMethodDetailsCard{
+		ThreeDSecure: &stripe.ChargePaymentMethodDetailsCardThreeDSecure{
+			Result:  stripe.ChargePaymentMethodDetailsCardThreeDSecureResultAuthenticated,
+			Version: "2.2.0",
+		},
+	}}}
+	if result := stripeAuthentication(ch); result.Status != sca.StatusAuthenticated || !result.LiabilityShift || result.Version != "2.2.0" {
+		t.Fatalf("authenticated = %+v", result)
+	}
+
+	ch.PaymentMethodDetails.Card.ThreeDSecure = &stripe.ChargePaymentMethodDetailsCardThreeDSecure{
+		Result:             stripe.ChargePaymentMethodDetailsCardThreeDSecureResultExempted,
+		ExemptionIndicator: stripe.ChargePaymentMethodDetailsCardThreeDSecureExemptionIndicatorLowRisk,
+	}
+	if result := stripeAuthentication(ch); result.Status != sca.StatusExempted || result.LiabilityShift || result.Exemption != sca.ExemptionTRA {
+		t.Fatalf("exempted = %+v", result)
+	}
+
+	pi := &stripe.PaymentIntent{
+		Status:       stripe.PaymentIntentStatusRequiresAction,
+		ClientSecret: "pi_123_secret",
+		NextAction:   &stripe.PaymentIntentNextAction{Type: stripe.PaymentIntentNextActionTypeUseStripeSDK},
+	}
+	if action := stripeNextAction(pi); action == nil || action.Type != sca.ActionSDK || action.ClientSecret != "pi_123_secret" {
+		t.Fatalf("next action = %+v", action)
+	}
+	if stripeAuthentication(nil) != nil {
+		t.Fatal("non-card payment should have no authentication result")
+	}
+}
@@ -0,0 +1,165 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// 支持派生收款地址的网络
+const (
+	NetworkETH  = "ETH"
+	NetworkBSC  = "BSC"
+	NetworkTRON = "TRON"
+)
+
+// 网络族：同一族的网络共用 xpub 和地址格式
+const (
+	FamilyEVM  = "evm"
+	FamilyTRON = "tron"
+)
+
+// tronAddressPrefix TRON 主网地址前缀
+const tronAddressPrefix = 0x41
+
+// NetworkFamily 返回网络所属的网络族，不支持的网络返回空字符串
+func NetworkFamily(network string) string {
+	switch strings.ToUpper(network) {
+	case NetworkETH, NetworkBSC:
+		return FamilyEVM
+	case NetworkTRON:
+		return FamilyTRON
+	default:
+		return ""
+	}
+}
+
+// Address 按网络族生成扩展公钥对应的地址（EVM 为 EIP-55 校验格式，TRON 为 Base58Check）
+func Address(family string, key *ExtendedPublicKey) (string, error) {
+	hash := addressHash(key.key)
+	switch family {
+	case FamilyEVM:
+		return checksumAddress(hash), nil
+	case FamilyTRON:
+		return base58CheckEncode(append([]byte{tronAddressPrefix}, hash...)), nil
+	default:
+		return "", fmt.Errorf("不支持的网络族: %s", family)
+	}
+}
+
+// HexAddress 将地址转换为 JSON-RPC 使用的 0x 十六进制格式（小写），TRON 地址会去掉 0x41 前缀
+func HexAddress(address string) (string, error) {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		b, err := hex.DecodeString(address[2:])
+		if err != nil || len(b) != 20 {
+			return "", fmt.Errorf("无效的地址: %s", address)
+		}
+		return "0x" + hex.EncodeToString(b), nil
+	}
+
+	data, err := base58CheckDecode(address)
+	if err != nil || len(data) != 21 || data[0] != tronAddressPrefix {
+		return "", fmt.Errorf("无效的地址: %s", address)
+	}
+	return "0x" + hex.EncodeToString(data[1:]), nil
+}
+
+// addressHash 公钥的 Keccak-256 哈希后 20 字节
+func addressHash(p point) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(p.uncompressed()[1:])
+	return h.Sum(nil)[12:]
+}
+
+// checksumAddress EIP-55 大小写校验地址
+func checksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && hash[i/2]>>(4*(1-uint(i%2)))&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	base := big.NewInt(58)
+	for _, c := range s {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx < 0 {
+			return nil, fmt.Errorf("无效的 Base58 字符: %q", c)
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(idx)))
+	}
+
+	out := x.Bytes()
+	for _, c := range s {
+		if c != rune(base58Alphabet[0]) {
+			break
+		}
+		out = append([]byte{0}, out...)
+	}
+	return out, nil
+}
+
+func base58CheckEncode(payload []byte) string {
+	return base58Encode(append(append([]byte{}, payload...), doubleSHA256(payload)[:4]...))
+}
+
+func base58CheckDecode(s string) ([]byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errors.New("数据过短")
+	}
+	payload, checksum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(doubleSHA256(payload)[:4], checksum) {
+		return nil, errors.New("校验和错误")
+	}
+	return payload, nil
+}
+
+func doubleSHA256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
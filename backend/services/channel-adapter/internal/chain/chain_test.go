@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+)
+
+// BIP32 测试向量 1：m/0H 的 xpub 派生 m/0H/1
+func TestExtendedPublicKeyChild(t *testing.T) {
+	parent, err := ParseExtendedPublicKey("xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw")
+	if err != nil {
+		t.Fatalf("parse xpub: %v", err)
+	}
+	if got := parent.String(); got != "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw" {
+		t.Fatalf("round trip mismatch: %s", got)
+	}
+
+	child, err := parent.Child(1)
+	if err != nil {
+		t.Fatalf("derive child: %v", err)
+	}
+	want := "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+	if got := child.String(); got != want {
+		t.Fatalf("child xpub = %s, want %s", got, want)
+	}
+
+	if _, err := parent.Child(HardenedOffset); err == nil {
+		t.Fatal("expected hardened derivation to fail")
+	}
+}
+
+func TestParseExtendedPublicKeyRejectsPrivateKey(t *testing.T) {
+	_, err := ParseExtendedPublicKey("xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi")
+	if err == nil {
+		t.Fatal("expected xprv to be rejected")
+	}
+}
+
+func TestAddress(t *testing.T) {
+	// 私钥为 1 的公钥即生成元 G
+	key := &ExtendedPublicKey{key: scalarBaseMult(big.NewInt(1))}
+
+	evm, err := Address(FamilyEVM, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evm != "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf" {
+		t.Fatalf("evm address = %s", evm)
+	}
+
+	tron, err := Address(FamilyTRON, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexAddr, err := HexAddress(tron)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hexAddr != "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf" {
+		t.Fatalf("tron hex address = %s", hexAddr)
+	}
+}
@@ -0,0 +1,133 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // BIP32 指纹规定使用 RIPEMD-160
+)
+
+// HardenedOffset 强化派生的序号起点，扩展公钥无法进行强化派生
+const HardenedOffset uint32 = 0x80000000
+
+// ErrInvalidChild 派生出的子密钥无效（概率约 2^-127），调用方应跳过该序号
+var ErrInvalidChild = errors.New("派生的子密钥无效")
+
+// ExtendedPublicKey BIP32 扩展公钥（xpub）
+//
+// 平台只保存账户层级的 xpub（如 m/44'/60'/0'），按支付派生 .../0/index 的收款地址，
+// 私钥离线保管，服务端无法动用资金。
+type ExtendedPublicKey struct {
+	version     [4]byte
+	depth       byte
+	fingerprint [4]byte // 父密钥指纹
+	childNumber uint32
+	chainCode   [32]byte
+	key         point
+}
+
+// ParseExtendedPublicKey 解析 Base58Check 编码的扩展公钥，拒绝扩展私钥
+func ParseExtendedPublicKey(s string) (*ExtendedPublicKey, error) {
+	data, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("解析扩展公钥失败: %w", err)
+	}
+	if len(data) != 78 {
+		return nil, fmt.Errorf("扩展公钥长度无效: %d", len(data))
+	}
+	if data[45] == 0x00 {
+		return nil, errors.New("不接受扩展私钥，请配置 xpub")
+	}
+
+	key, err := decompress(data[45:78])
+	if err != nil {
+		return nil, fmt.Errorf("解析扩展公钥失败: %w", err)
+	}
+
+	k := &ExtendedPublicKey{
+		depth:       data[4],
+		childNumber: binary.BigEndian.Uint32(data[9:13]),
+		key:         key,
+	}
+	copy(k.version[:], data[0:4])
+	copy(k.fingerprint[:], data[5:9])
+	copy(k.chainCode[:], data[13:45])
+	return k, nil
+}
+
+// Child 非强化派生子公钥 CKDpub
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= HardenedOffset {
+		return nil, errors.New("扩展公钥不支持强化派生")
+	}
+
+	parentKey := k.key.compressed()
+	data := make([]byte, 37)
+	copy(data, parentKey)
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveN) >= 0 {
+		return nil, ErrInvalidChild
+	}
+	childKey := addPoints(scalarBaseMult(il), k.key)
+	if childKey.isInfinity() {
+		return nil, ErrInvalidChild
+	}
+
+	child := &ExtendedPublicKey{
+		version:     k.version,
+		depth:       k.depth + 1,
+		childNumber: index,
+		key:         childKey,
+	}
+	copy(child.fingerprint[:], hash160(parentKey)[:4])
+	copy(child.chainCode[:], sum[32:])
+	return child, nil
+}
+
+// Derive 按路径依次派生，如 Derive(0, 5) 得到 .../0/5
+func (k *ExtendedPublicKey) Derive(path ...uint32) (*ExtendedPublicKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PublicKey 33 字节压缩公钥
+func (k *ExtendedPublicKey) PublicKey() []byte {
+	return k.key.compressed()
+}
+
+// String Base58Check 编码
+func (k *ExtendedPublicKey) String() string {
+	var buf bytes.Buffer
+	buf.Write(k.version[:])
+	buf.WriteByte(k.depth)
+	buf.Write(k.fingerprint[:])
+	_ = binary.Write(&buf, binary.BigEndian, k.childNumber)
+	buf.Write(k.chainCode[:])
+	buf.Write(k.key.compressed())
+	return base58CheckEncode(buf.Bytes())
+}
+
+func hash160(b []byte) []byte {
+	sha := sha256.Sum256(b)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
@@ -0,0 +1,298 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TransferTopic ERC-20/TRC-20 Transfer(address,address,uint256) 事件签名
+const TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// RPCClient 以太坊兼容的 JSON-RPC 客户端
+//
+// ETH、BSC 节点以及 TRON 的 /jsonrpc 接口都实现了这里用到的 eth_* 方法，地址统一使用 0x 十六进制格式。
+type RPCClient struct {
+	endpoint   string
+	httpClient *http.Client
+	nextID     int64
+}
+
+// NewRPCClient 创建 JSON-RPC 客户端
+func NewRPCClient(endpoint string, httpClient *http.Client) *RPCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &RPCClient{endpoint: endpoint, httpClient: httpClient}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *RPCClient) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddInt64(&c.nextID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s 请求失败: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 请求失败: HTTP %d", method, resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(data, &rpcResp); err != nil {
+		return fmt.Errorf("%s 响应解析失败: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s 返回错误: %d %s", method, rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// BlockNumber 最新区块高度
+func (c *RPCClient) BlockNumber(ctx context.Context) (uint64, error) {
+	var hex string
+	if err := c.call(ctx, "eth_blockNumber", &hex); err != nil {
+		return 0, err
+	}
+	n, err := parseQuantity(hex)
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64(), nil
+}
+
+// GetBalance 地址在指定区块的原生币余额（最小单位）
+func (c *RPCClient) GetBalance(ctx context.Context, address string, block uint64) (*big.Int, error) {
+	var hex string
+	if err := c.call(ctx, "eth_getBalance", &hex, address, toQuantity(block)); err != nil {
+		return nil, err
+	}
+	return parseQuantity(hex)
+}
+
+// BlockTimestamp 区块时间
+func (c *RPCClient) BlockTimestamp(ctx context.Context, block uint64) (time.Time, error) {
+	var header struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := c.call(ctx, "eth_getBlockByNumber", &header, toQuantity(block), false); err != nil {
+		return time.Time{}, err
+	}
+	ts, err := parseQuantity(header.Timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts.Int64(), 0), nil
+}
+
+// Transfer 代币转账事件
+type Transfer struct {
+	TxHash      string
+	BlockNumber uint64
+	From        string
+	To          string
+	Value       *big.Int
+}
+
+// GetTransfers 查询区块区间内转入 to 地址的代币转账
+func (c *RPCClient) GetTransfers(ctx context.Context, contract, to string, fromBlock, toBlock uint64) ([]Transfer, error) {
+	filter := map[string]interface{}{
+		"address":   contract,
+		"fromBlock": toQuantity(fromBlock),
+		"toBlock":   toQuantity(toBlock),
+		"topics":    []interface{}{TransferTopic, nil, addressTopic(to)},
+	}
+
+	var logs []struct {
+		TxHash      string   `json:"transactionHash"`
+		BlockNumber string   `json:"blockNumber"`
+		Topics      []string `json:"topics"`
+		Data        string   `json:"data"`
+		Removed     bool     `json:"removed"`
+	}
+	if err := c.call(ctx, "eth_getLogs", &logs, filter); err != nil {
+		return nil, err
+	}
+
+	transfers := make([]Transfer, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) < 3 {
+			continue
+		}
+		block, err := parseQuantity(l.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseQuantity(l.Data)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, Transfer{
+			TxHash:      l.TxHash,
+			BlockNumber: block.Uint64(),
+			From:        topicAddress(l.Topics[1]),
+			To:          topicAddress(l.Topics[2]),
+			Value:       value,
+		})
+	}
+	return transfers, nil
+}
+
+// DepositScan 收款地址的链上到账情况
+type DepositScan struct {
+	LatestBlock    uint64
+	Received       *big.Int // 截至最新区块的累计到账
+	Confirmed      *big.Int // 已达到确认数要求的到账
+	FirstSeenBlock uint64   // 首笔到账所在区块，未到账为 0
+	TxHash         string   // 首笔到账交易哈希（原生币余额扫描无法得到）
+	Confirmations  uint64   // 首笔到账的确认数
+}
+
+// ScanDeposit 扫描收款地址自 startBlock 起的到账
+//
+// contract 为空时按原生币余额统计：收款地址为每笔支付新派生，余额即到账金额；
+// 否则按代币合约的 Transfer 事件统计。confirmations 为到账被视为不可逆所需的确认数。
+func (c *RPCClient) ScanDeposit(ctx context.Context, address, contract string, startBlock, confirmations uint64) (*DepositScan, error) {
+	latest, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	// 区块高度不超过 safeBlock 的到账已满足确认数
+	var safeBlock uint64
+	if latest+1 >= confirmations {
+		safeBlock = latest + 1 - confirmations
+	}
+
+	scan := &DepositScan{LatestBlock: latest, Received: new(big.Int), Confirmed: new(big.Int)}
+	if latest < startBlock {
+		return scan, nil
+	}
+
+	if contract != "" {
+		transfers, err := c.GetTransfers(ctx, contract, address, startBlock, latest)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range transfers {
+			scan.Received.Add(scan.Received, t.Value)
+			if t.BlockNumber <= safeBlock {
+				scan.Confirmed.Add(scan.Confirmed, t.Value)
+			}
+			if scan.FirstSeenBlock == 0 || t.BlockNumber < scan.FirstSeenBlock {
+				scan.FirstSeenBlock = t.BlockNumber
+				scan.TxHash = t.TxHash
+			}
+		}
+	} else {
+		if scan.Received, err = c.GetBalance(ctx, address, latest); err != nil {
+			return nil, err
+		}
+		if scan.Received.Sign() > 0 {
+			if safeBlock >= startBlock {
+				if scan.Confirmed, err = c.GetBalance(ctx, address, safeBlock); err != nil {
+					return nil, err
+				}
+			}
+			if scan.FirstSeenBlock, err = c.firstFundedBlock(ctx, address, startBlock, latest); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if scan.FirstSeenBlock > 0 {
+		scan.Confirmations = latest - scan.FirstSeenBlock + 1
+	}
+	return scan, nil
+}
+
+// firstFundedBlock 二分查找地址余额首次大于零的区块（调用方保证 high 处余额大于零）
+func (c *RPCClient) firstFundedBlock(ctx context.Context, address string, low, high uint64) (uint64, error) {
+	for low < high {
+		mid := low + (high-low)/2
+		balance, err := c.GetBalance(ctx, address, mid)
+		if err != nil {
+			return 0, err
+		}
+		if balance.Sign() > 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
+
+func toQuantity(n uint64) string {
+	return fmt.Sprintf("0x%x", n)
+}
+
+func parseQuantity(s string) (*big.Int, error) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if hex == "" {
+		return new(big.Int), nil
+	}
+	n, ok := new(big.Int).SetString(hex, 16)
+	if !ok {
+		return nil, fmt.Errorf("无效的十六进制数值: %s", s)
+	}
+	return n, nil
+}
+
+// addressTopic 地址左补零为 32 字节的事件 topic
+func addressTopic(address string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(address, "0x"))
+}
+
+func topicAddress(topic string) string {
+	if len(topic) < 40 {
+		return topic
+	}
+	return "0x" + strings.ToLower(topic[len(topic)-40:])
+}
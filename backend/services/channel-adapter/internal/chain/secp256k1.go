@@ -0,0 +1,143 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+)
+
+// secp256k1 曲线参数（y² = x³ + 7）
+var (
+	curveP  = mustHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F")
+	curveN  = mustHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141")
+	curveG  = point{x: mustHex("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"), y: mustHex("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8")}
+	curveB  = big.NewInt(7)
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(curveP, big.NewInt(1)), 2) // (p+1)/4，p ≡ 3 (mod 4)
+)
+
+// point 曲线上的点（仿射坐标），x 为 nil 表示无穷远点
+//
+// 只用于公钥派生（不涉及私钥），因此不要求常数时间实现。
+type point struct {
+	x, y *big.Int
+}
+
+func (p point) isInfinity() bool {
+	return p.x == nil
+}
+
+// addPoints 点加
+func addPoints(a, b point) point {
+	if a.isInfinity() {
+		return b
+	}
+	if b.isInfinity() {
+		return a
+	}
+	if a.x.Cmp(b.x) == 0 {
+		if a.y.Cmp(b.y) == 0 {
+			return doublePoint(a)
+		}
+		return point{}
+	}
+
+	// λ = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(b.y, a.y)
+	den := new(big.Int).Sub(b.x, a.x)
+	den.ModInverse(den.Mod(den, curveP), curveP)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, curveP)
+	return lineIntersection(a, b.x, lambda)
+}
+
+// doublePoint 倍点
+func doublePoint(a point) point {
+	if a.isInfinity() || a.y.Sign() == 0 {
+		return point{}
+	}
+
+	// λ = 3x² / 2y
+	num := new(big.Int).Mul(a.x, a.x)
+	num.Mul(num, big.NewInt(3))
+	den := new(big.Int).Lsh(a.y, 1)
+	den.ModInverse(den.Mod(den, curveP), curveP)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, curveP)
+	return lineIntersection(a, a.x, lambda)
+}
+
+// lineIntersection 根据斜率计算第三个交点的对称点
+func lineIntersection(a point, bx, lambda *big.Int) point {
+	// x3 = λ² - x1 - x2, y3 = λ(x1 - x3) - y1
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, a.x)
+	x3.Sub(x3, bx)
+	x3.Mod(x3, curveP)
+
+	y3 := new(big.Int).Sub(a.x, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, a.y)
+	y3.Mod(y3, curveP)
+	return point{x: x3, y: y3}
+}
+
+// scalarBaseMult 计算 k·G
+func scalarBaseMult(k *big.Int) point {
+	result := point{}
+	addend := curveG
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			result = addPoints(result, addend)
+		}
+		addend = doublePoint(addend)
+	}
+	return result
+}
+
+// compressed 33 字节压缩公钥
+func (p point) compressed() []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 + byte(p.y.Bit(0))
+	p.x.FillBytes(out[1:])
+	return out
+}
+
+// uncompressed 65 字节非压缩公钥
+func (p point) uncompressed() []byte {
+	out := make([]byte, 65)
+	out[0] = 0x04
+	p.x.FillBytes(out[1:33])
+	p.y.FillBytes(out[33:])
+	return out
+}
+
+// decompress 解析 33 字节压缩公钥
+func decompress(b []byte) (point, error) {
+	if len(b) != 33 || (b[0] != 0x02 && b[0] != 0x03) {
+		return point{}, errors.New("无效的压缩公钥")
+	}
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(curveP) >= 0 {
+		return point{}, errors.New("无效的压缩公钥")
+	}
+
+	// y² = x³ + 7
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	y2.Add(y2, curveB)
+	y2.Mod(y2, curveP)
+	y := new(big.Int).Exp(y2, sqrtExp, curveP)
+	if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(y2) != 0 {
+		return point{}, errors.New("公钥不在曲线上")
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(curveP, y)
+	}
+	return point{x: x, y: y}, nil
+}
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex constant: " + s)
+	}
+	return v
+}
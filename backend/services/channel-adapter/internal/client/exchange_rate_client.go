@@ -3,11 +3,13 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/payment-platform/pkg/httpclient"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/money"
 	"github.com/redis/go-redis/v9"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
@@ -15,13 +17,19 @@ import (
 	"payment-platform/channel-adapter/internal/repository"
 )
 
+// ErrRateStale 汇率API不可用且最近一次快照已超过最大允许时长
+//
+// 不再使用静态备用汇率，调用方应拒绝换汇而不是按过期汇率成交。
+var ErrRateStale = errors.New("汇率数据已过期")
+
 // ExchangeRateClient 汇率API客户端
 type ExchangeRateClient struct {
-	baseURL string
-	breaker *httpclient.BreakerClient
-	redis   *redis.Client
-	repo    repository.ExchangeRateRepository
+	baseURL  string
+	breaker  *httpclient.BreakerClient
+	redis    *redis.Client
+	repo     repository.ExchangeRateRepository
 	cacheTTL time.Duration
+	maxAge   time.Duration // API 不可用时快照的最大可用时长
 }
 
 // ExchangeRateResponse 汇率API响应
@@ -39,7 +47,8 @@ type ExchangeRateResponse struct {
 
 // NewExchangeRateClient 创建汇率API客户端（带熔断器）
 // 使用 exchangerate-api.com 免费版（1500次/月）
-func NewExchangeRateClient(redis *redis.Client, repo repository.ExchangeRateRepository, cacheTTL time.Duration) *ExchangeRateClient {
+// maxAge: API 不可用时，最近一次快照在该时长内仍可使用，超过后返回 ErrRateStale
+func NewExchangeRateClient(redis *redis.Client, repo repository.ExchangeRateRepository, cacheTTL, maxAge time.Duration) *ExchangeRateClient {
 	// 创建 httpclient 配置
 	config := &httpclient.Config{
 		Timeout:    5 * time.Second, // 外部API使用较短超时
@@ -61,6 +70,7 @@ func NewExchangeRateClient(redis *redis.Client, repo repository.ExchangeRateRepo
 		redis:    redis,
 		repo:     repo,
 		cacheTTL: cacheTTL,
+		maxAge:   maxAge,
 	}
 }
 
@@ -88,15 +98,21 @@ func (c *ExchangeRateClient) GetRate(ctx context.Context, from, to string) (floa
 		}
 	}
 
-	// 2. 调用汇率API获取最新数据
-	rates, err := c.fetchRates(ctx, from)
-	if err != nil {
-		// API调用失败，尝试获取备用汇率
-		logger.Warn("汇率API调用失败，使用备用汇率",
-			zap.String("from", from),
-			zap.String("to", to),
-			zap.Error(err))
-		return c.getFallbackRate(from, to), nil
+	// 2. 获取最新汇率（有历史存储时经由快照，API 失败时使用未过期的快照）
+	var rates map[string]float64
+	fresh := true
+	if c.repo != nil {
+		snapshot, err := c.LatestSnapshot(ctx, from)
+		if err != nil {
+			return 0, err
+		}
+		rates = snapshot.Rates
+		fresh = time.Since(snapshot.SnapshotTime) <= c.cacheTTL
+	} else {
+		rates, err = c.fetchRates(ctx, from)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s -> %s: %v", ErrRateStale, from, to, err)
+		}
 	}
 
 	// 3. 从响应中提取目标货币汇率
@@ -105,9 +121,11 @@ func (c *ExchangeRateClient) GetRate(ctx context.Context, from, to string) (floa
 		return 0, fmt.Errorf("不支持的货币: %s", to)
 	}
 
-	// 4. 写入缓存
-	if data, err := json.Marshal(rate); err == nil {
-		c.redis.Set(ctx, cacheKey, string(data), c.cacheTTL)
+	// 4. 写入缓存（降级使用的旧快照不缓存，以便 API 恢复后尽快更新）
+	if fresh {
+		if data, err := json.Marshal(rate); err == nil {
+			c.redis.Set(ctx, cacheKey, string(data), c.cacheTTL)
+		}
 	}
 
 	logger.Info("汇率查询成功",
@@ -133,34 +151,85 @@ func (c *ExchangeRateClient) GetRates(ctx context.Context, baseCurrency string)
 		}
 	}
 
-	// 调用API获取
+	// 调用API获取并保存快照
+	snapshot, err := c.refreshSnapshot(ctx, baseCurrency)
+	if snapshot == nil {
+		return nil, err
+	}
+	if err != nil {
+		logger.Warn("保存汇率快照失败",
+			zap.String("base", baseCurrency),
+			zap.Error(err))
+		// 不影响正常返回
+	}
+
+	return snapshot.Rates, nil
+}
+
+// LatestSnapshot 获取基准货币的最新汇率快照（报价的汇率来源）
+//
+// 快照超过缓存有效期时先尝试从API刷新；刷新失败时在 maxAge 内继续使用旧快照，
+// 超过 maxAge 返回 ErrRateStale。
+func (c *ExchangeRateClient) LatestSnapshot(ctx context.Context, baseCurrency string) (*model.ExchangeRateSnapshot, error) {
+	if c.repo == nil {
+		return nil, fmt.Errorf("未配置汇率历史存储")
+	}
+
+	snapshot, err := c.repo.GetLatestSnapshot(ctx, baseCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("查询汇率快照失败: %w", err)
+	}
+	if snapshot != nil && time.Since(snapshot.SnapshotTime) <= c.cacheTTL {
+		return snapshot, nil
+	}
+
+	fresh, refreshErr := c.refreshSnapshot(ctx, baseCurrency)
+	if refreshErr == nil {
+		return fresh, nil
+	}
+
+	if snapshot != nil && time.Since(snapshot.SnapshotTime) <= c.maxAge {
+		logger.Warn("汇率刷新失败，使用最近一次快照",
+			zap.String("base", baseCurrency),
+			zap.Time("snapshot_time", snapshot.SnapshotTime),
+			zap.Error(refreshErr))
+		return snapshot, nil
+	}
+
+	logger.Error("汇率刷新失败且无可用快照",
+		zap.String("base", baseCurrency),
+		zap.Error(refreshErr))
+	return nil, fmt.Errorf("%w: %s: %v", ErrRateStale, baseCurrency, refreshErr)
+}
+
+// refreshSnapshot 从API获取最新汇率，写入缓存并保存快照
+//
+// 快照保存失败时同时返回未保存的快照和错误。
+func (c *ExchangeRateClient) refreshSnapshot(ctx context.Context, baseCurrency string) (*model.ExchangeRateSnapshot, error) {
 	rates, err := c.fetchRates(ctx, baseCurrency)
 	if err != nil {
 		return nil, err
 	}
 
 	// 写入缓存
+	cacheKey := fmt.Sprintf("exchange_rates:%s:all", baseCurrency)
 	if data, err := json.Marshal(rates); err == nil {
 		c.redis.Set(ctx, cacheKey, string(data), c.cacheTTL)
 	}
 
-	// 保存历史快照到数据库（如果配置了repository）
-	if c.repo != nil {
-		snapshot := &model.ExchangeRateSnapshot{
-			BaseCurrency: baseCurrency,
-			Rates:        rates,
-			Source:       "exchangerate-api",
-			SnapshotTime: time.Now(),
-		}
-		if err := c.repo.SaveSnapshot(ctx, snapshot); err != nil {
-			logger.Warn("保存汇率快照失败",
-				zap.String("base", baseCurrency),
-				zap.Error(err))
-			// 不影响正常返回
-		}
+	snapshot := &model.ExchangeRateSnapshot{
+		BaseCurrency: baseCurrency,
+		Rates:        rates,
+		Source:       "exchangerate-api",
+		SnapshotTime: time.Now(),
 	}
-
-	return rates, nil
+	if c.repo == nil {
+		return snapshot, nil
+	}
+	if err := c.repo.SaveSnapshot(ctx, snapshot); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
 }
 
 // fetchRates 从API获取汇率数据（使用熔断器）
@@ -193,53 +262,6 @@ func (c *ExchangeRateClient) fetchRates(ctx context.Context, baseCurrency string
 	return apiResp.ConversionRates, nil
 }
 
-// getFallbackRate 获取备用汇率（当API失败时使用）
-func (c *ExchangeRateClient) getFallbackRate(from, to string) float64 {
-	// 常用货币的近似汇率（用于降级）
-	// 实际生产环境应该从数据库加载最近一次成功的汇率
-	fallbackRates := map[string]map[string]float64{
-		"USD": {
-			"EUR": 0.92,
-			"GBP": 0.79,
-			"CNY": 7.24,
-			"JPY": 149.50,
-			"KRW": 1320.00,
-			"HKD": 7.82,
-			"SGD": 1.35,
-			"AUD": 1.52,
-			"CAD": 1.36,
-			"CHF": 0.88,
-			"INR": 83.10,
-			"BRL": 4.98,
-		},
-		"EUR": {
-			"USD": 1.09,
-			"GBP": 0.86,
-			"CNY": 7.87,
-		},
-		"CNY": {
-			"USD": 0.14,
-			"EUR": 0.13,
-		},
-	}
-
-	if rates, ok := fallbackRates[from]; ok {
-		if rate, ok := rates[to]; ok {
-			logger.Warn("使用备用汇率",
-				zap.String("from", from),
-				zap.String("to", to),
-				zap.Float64("rate", rate))
-			return rate
-		}
-	}
-
-	// 如果没有备用汇率，返回1（需要人工处理）
-	logger.Error("无法获取汇率，返回默认值1.0",
-		zap.String("from", from),
-		zap.String("to", to))
-	return 1.0
-}
-
 // Convert 货币转换
 // amount: 金额（以最小单位，如分）
 // from: 源货币
@@ -251,10 +273,13 @@ func (c *ExchangeRateClient) Convert(ctx context.Context, amount int64, from, to
 		return 0, err
 	}
 
-	// 转换计算（保持精度）
-	result := float64(amount) * rate
+	// 按十进制汇率换算，处理两种币种的小数位数差异
+	result, err := money.New(amount, from).Convert(money.DecimalFromFloat(rate), to, money.RoundHalfEven)
+	if err != nil {
+		return 0, err
+	}
 
-	return int64(result), nil
+	return result.Amount, nil
 }
 
 // SupportedCurrencies 返回支持的货币列表
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/decline"
 	"github.com/payment-platform/pkg/errors"
 	"github.com/payment-platform/pkg/middleware"
 	"payment-platform/channel-adapter/internal/service"
@@ -56,6 +57,12 @@ func (h *ChannelHandler) CreatePayment(c *gin.Context) {
 	resp, err := h.channelService.CreatePayment(c.Request.Context(), &req)
 	if err != nil {
 		traceID := middleware.GetRequestID(c)
+		// 渠道拒绝：返回归一化拒绝原因，供 payment-gateway 记录和判断是否级联
+		if d, ok := decline.FromError(err); ok {
+			response := &errors.Response{Code: errors.ErrCodePaymentDeclined, Message: err.Error(), Data: d}
+			c.JSON(http.StatusPaymentRequired, response.WithTraceID(traceID))
+			return
+		}
 		if bizErr, ok := errors.GetBusinessError(err); ok {
 			response := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
 			c.JSON(errors.GetHTTPStatus(bizErr.Code), response)
@@ -199,6 +206,48 @@ func (h *ChannelHandler) CancelPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// CompleteAuthentication 3DS 验证完成后继续支付
+// @Summary 3DS 验证完成后继续支付
+// @Tags Channel
+// @Accept json
+// @Produce json
+// @Param payment_no path string true "支付流水号"
+// @Success 200 {object} service.CompleteAuthenticationResponse
+// @Router /api/v1/channel/payments/{payment_no}/authenticate [post]
+func (h *ChannelHandler) CompleteAuthentication(c *gin.Context) {
+	paymentNo := c.Param("payment_no")
+	if paymentNo == "" {
+		traceID := middleware.GetRequestID(c)
+		response := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "支付流水号不能为空", "").
+			WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	resp, err := h.channelService.CompleteAuthentication(c.Request.Context(), paymentNo)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if d, ok := decline.FromError(err); ok {
+			response := &errors.Response{Code: errors.ErrCodePaymentDeclined, Message: err.Error(), Data: d}
+			c.JSON(http.StatusPaymentRequired, response.WithTraceID(traceID))
+			return
+		}
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			response := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), response)
+		} else {
+			response := errors.NewErrorResponse(errors.ErrCodeInternalError, "3DS 验证后继续支付失败", err.Error()).
+				WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	response := errors.NewSuccessResponse(resp).WithTraceID(traceID)
+	c.JSON(http.StatusOK, response)
+}
+
 // CreateRefund 创建退款
 // @Summary 创建退款
 // @Tags Channel
@@ -591,6 +640,74 @@ func (h *ChannelHandler) CancelPreAuth(c *gin.Context) {
 	})
 }
 
+// IncrementPreAuth 增量授权（提高冻结金额）
+// @Summary 增量授权
+// @Tags Channel
+// @Accept json
+// @Produce json
+// @Param request body service.IncrementPreAuthRequest true "增量授权请求"
+// @Success 200 {object} service.IncrementPreAuthResponse
+// @Router /api/v1/channel/pre-auth/increment [post]
+func (h *ChannelHandler) IncrementPreAuth(c *gin.Context) {
+	var req service.IncrementPreAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		response := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	resp, err := h.channelService.IncrementPreAuth(c.Request.Context(), &req)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		response := errors.NewErrorResponse(errors.ErrCodeInternalError, "增量授权失败", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    "SUCCESS",
+		"message": "增量授权成功",
+		"data":    resp,
+	})
+}
+
+// ReauthorizePreAuth 重新授权（授权到期前重新冻结剩余金额）
+// @Summary 重新授权
+// @Tags Channel
+// @Accept json
+// @Produce json
+// @Param request body service.ReauthorizePreAuthRequest true "重新授权请求"
+// @Success 200 {object} service.ReauthorizePreAuthResponse
+// @Router /api/v1/channel/pre-auth/reauthorize [post]
+func (h *ChannelHandler) ReauthorizePreAuth(c *gin.Context) {
+	var req service.ReauthorizePreAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		response := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	resp, err := h.channelService.ReauthorizePreAuth(c.Request.Context(), &req)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		response := errors.NewErrorResponse(errors.ErrCodeInternalError, "重新授权失败", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    "SUCCESS",
+		"message": "重新授权成功",
+		"data":    resp,
+	})
+}
+
 // QueryPreAuth 查询预授权状态
 // @Summary 查询预授权
 // @Tags Channel
@@ -857,6 +974,7 @@ func (h *ChannelHandler) RegisterRoutes(router *gin.Engine) {
 		api.POST("/channel/payments", h.CreatePayment)
 		api.GET("/channel/payments/:payment_no", h.QueryPayment)
 		api.POST("/channel/payments/:payment_no/cancel", h.CancelPayment)
+		api.POST("/channel/payments/:payment_no/authenticate", h.CompleteAuthentication)
 
 		// 退款相关（完整RESTful路由）
 		api.POST("/channel/refunds", h.CreateRefund)
@@ -866,6 +984,8 @@ func (h *ChannelHandler) RegisterRoutes(router *gin.Engine) {
 		api.POST("/channel/pre-auth", h.CreatePreAuth)
 		api.POST("/channel/pre-auth/capture", h.CapturePreAuth)
 		api.POST("/channel/pre-auth/cancel", h.CancelPreAuth)
+		api.POST("/channel/pre-auth/increment", h.IncrementPreAuth)
+		api.POST("/channel/pre-auth/reauthorize", h.ReauthorizePreAuth)
 		api.GET("/channel/pre-auth/:channel_pre_auth_no", h.QueryPreAuth)
 
 		// Payment-Gateway 兼容路由（简化版）
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/router"
+	"payment-platform/channel-adapter/internal/service"
+)
+
+// ChannelHealthHandler 渠道健康状态与熔断管理处理器
+type ChannelHealthHandler struct {
+	monitor *service.ChannelHealthMonitor
+}
+
+// NewChannelHealthHandler 创建渠道健康处理器
+func NewChannelHealthHandler(monitor *service.ChannelHealthMonitor) *ChannelHealthHandler {
+	return &ChannelHealthHandler{monitor: monitor}
+}
+
+// ChannelHealthOverrideRequest 渠道健康状态覆盖请求
+type ChannelHealthOverrideRequest struct {
+	State            string `json:"state" binding:"required,oneof=healthy degraded down"`
+	Reason           string `json:"reason" binding:"required"`
+	Operator         string `json:"operator"`
+	ExpiresInMinutes int    `json:"expires_in_minutes" binding:"min=0"` // 0 表示手动解除前一直生效
+}
+
+// RegisterRoutes 注册路由
+func (h *ChannelHealthHandler) RegisterRoutes(router *gin.Engine) {
+	api := router.Group("/api/v1")
+	{
+		// ====== 管理接口 (Admin Only) ======
+		api.GET("/admin/channel-health", h.GetChannelHealth)
+		api.PUT("/admin/channel-health/:channel/override", h.SetOverride)
+		api.DELETE("/admin/channel-health/:channel/override", h.ClearOverride)
+	}
+}
+
+// GetChannelHealth 查询渠道健康状态
+// @Summary 查询渠道健康状态
+// @Description 返回各渠道合并后的有效健康状态（含运营覆盖）以及本实例的熔断器状态
+// @Tags Admin-Channel
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/channel-health [get]
+func (h *ChannelHealthHandler) GetChannelHealth(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+
+	channels, err := h.monitor.LoadHealth(c.Request.Context())
+	if err != nil {
+		response := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询渠道健康状态失败", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := errors.NewSuccessResponse(gin.H{
+		"channels": channels,
+		"instance": h.monitor.Snapshot(),
+	}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, response)
+}
+
+// SetOverride 手动设置渠道健康状态
+// @Summary 手动设置渠道健康状态
+// @Description 运营覆盖优先于自动计算的健康状态；设置为 down 时路由不再向该渠道分配流量
+// @Tags Admin-Channel
+// @Accept json
+// @Produce json
+// @Param channel path string true "渠道代码"
+// @Param request body ChannelHealthOverrideRequest true "覆盖参数"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/channel-health/{channel}/override [put]
+func (h *ChannelHealthHandler) SetOverride(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+
+	var req ChannelHealthOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	override := &router.ChannelHealthOverride{
+		Channel:  c.Param("channel"),
+		State:    req.State,
+		Reason:   req.Reason,
+		Operator: req.Operator,
+	}
+	if req.ExpiresInMinutes > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInMinutes) * time.Minute)
+		override.ExpiresAt = &expiresAt
+	}
+
+	if err := h.monitor.SetOverride(c.Request.Context(), override); err != nil {
+		response := errors.NewErrorResponse(errors.ErrCodeInternalError, "设置渠道健康状态失败", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := errors.NewSuccessResponse(override).WithTraceID(traceID)
+	c.JSON(http.StatusOK, response)
+}
+
+// ClearOverride 解除渠道健康状态覆盖
+// @Summary 解除渠道健康状态覆盖
+// @Tags Admin-Channel
+// @Produce json
+// @Param channel path string true "渠道代码"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/channel-health/{channel}/override [delete]
+func (h *ChannelHealthHandler) ClearOverride(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+	channel := c.Param("channel")
+
+	if err := h.monitor.ClearOverride(c.Request.Context(), channel); err != nil {
+		response := errors.NewErrorResponse(errors.ErrCodeInternalError, "解除渠道健康状态覆盖失败", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := errors.NewSuccessResponse(gin.H{
+		"message": "已恢复自动健康判定",
+		"channel": channel,
+	}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/crypto"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/service"
+)
+
+// CredentialHandler 渠道凭证管理HTTP处理器
+type CredentialHandler struct {
+	credentialService service.CredentialService
+}
+
+// NewCredentialHandler 创建渠道凭证管理处理器
+func NewCredentialHandler(credentialService service.CredentialService) *CredentialHandler {
+	return &CredentialHandler{
+		credentialService: credentialService,
+	}
+}
+
+// RegisterRoutes 注册渠道凭证路由（r 需已挂载管理员 JWT 认证，操作人取自认证身份）
+func (h *CredentialHandler) RegisterRoutes(r gin.IRouter) {
+	creds := r.Group("/api/v1/channel/credentials")
+	{
+		creds.POST("", h.CreateCredential)                // 提交凭证（新版本）
+		creds.GET("", h.ListCredentials)                  // 查询凭证版本
+		creds.POST("/rewrap", h.RewrapCredentials)        // KEK 轮换后重新加密
+		creds.POST("/:id/validate", h.ValidateCredential) // 渠道校验
+		creds.POST("/:id/activate", h.ActivateCredential) // 激活
+		creds.POST("/:id/retire", h.RetireCredential)     // 停用旧版本
+		creds.POST("/:id/revoke", h.RevokeCredential)     // 吊销
+	}
+	r.GET("/api/v1/channel/credential-audits", h.ListAudits) // 审计日志
+}
+
+// CreateCredential 提交渠道凭证
+//
+//	@Summary		提交渠道凭证
+//	@Description	凭证以信封加密保存为新的 pending 版本，校验并激活后才会用于渠道调用
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		service.CreateCredentialInput	true	"凭证"
+//	@Success		200		{object}	map[string]interface{}
+//	@Failure		400		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials [post]
+func (h *CredentialHandler) CreateCredential(c *gin.Context) {
+	op, ok := h.operator(c)
+	if !ok {
+		return
+	}
+	var input service.CreateCredentialInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	input.CredentialOperator = *op
+
+	cred, err := h.credentialService.Create(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, "提交凭证失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    cred,
+	})
+}
+
+// ListCredentials 查询渠道凭证版本
+//
+//	@Summary		查询渠道凭证版本
+//	@Description	只返回元数据（版本、状态、指纹、脱敏提示），不返回密文
+//	@Tags			ChannelCredential
+//	@Produce		json
+//	@Param			merchant_id	query		string	true	"商户ID"
+//	@Param			channel		query		string	false	"渠道"
+//	@Success		200			{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials [get]
+func (h *CredentialHandler) ListCredentials(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Query("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商户ID",
+		})
+		return
+	}
+
+	creds, err := h.credentialService.List(c.Request.Context(), merchantID, c.Query("channel"))
+	if err != nil {
+		h.respondError(c, "查询凭证失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    creds,
+	})
+}
+
+// ValidateCredential 使用凭证向渠道发起校验调用
+//
+//	@Summary		校验渠道凭证
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"凭证ID"
+//	@Success		200		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials/{id}/validate [post]
+func (h *CredentialHandler) ValidateCredential(c *gin.Context) {
+	h.transition(c, "校验凭证失败", h.credentialService.Validate)
+}
+
+// ActivateCredential 激活已通过校验的凭证
+//
+//	@Summary		激活渠道凭证
+//	@Description	已有的使用中版本保持可用，确认切换后再停用
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"凭证ID"
+//	@Success		200		{object}	map[string]interface{}
+//	@Failure		409		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials/{id}/activate [post]
+func (h *CredentialHandler) ActivateCredential(c *gin.Context) {
+	h.transition(c, "激活凭证失败", h.credentialService.Activate)
+}
+
+// RetireCredential 停用轮换后的旧版本凭证
+//
+//	@Summary		停用渠道凭证
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"凭证ID"
+//	@Success		200		{object}	map[string]interface{}
+//	@Failure		409		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials/{id}/retire [post]
+func (h *CredentialHandler) RetireCredential(c *gin.Context) {
+	h.transition(c, "停用凭证失败", h.credentialService.Retire)
+}
+
+// RevokeCredentialRequest 吊销凭证请求
+type RevokeCredentialRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RevokeCredential 吊销凭证（立即生效）
+//
+//	@Summary		吊销渠道凭证
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"凭证ID"
+//	@Param			request	body		RevokeCredentialRequest	true	"吊销原因"
+//	@Success		200		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials/{id}/revoke [post]
+func (h *CredentialHandler) RevokeCredential(c *gin.Context) {
+	var req RevokeCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	h.transition(c, "吊销凭证失败", func(ctx context.Context, id uuid.UUID, op *service.CredentialOperator) (*model.ChannelCredential, error) {
+		return h.credentialService.Revoke(ctx, id, req.Reason, op)
+	})
+}
+
+// RewrapCredentials 用当前 KEK 重新加密凭证
+//
+//	@Summary		重新加密渠道凭证
+//	@Description	KEK 轮换后分批执行（每批100条），直到 rewrapped 为 0
+//	@Tags			ChannelCredential
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credentials/rewrap [post]
+func (h *CredentialHandler) RewrapCredentials(c *gin.Context) {
+	op, ok := h.operator(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.credentialService.Rewrap(c.Request.Context(), op)
+	if err != nil {
+		h.respondError(c, "重新加密凭证失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"rewrapped": count,
+		},
+	})
+}
+
+// ListAudits 查询凭证审计日志
+//
+//	@Summary		查询渠道凭证审计日志
+//	@Tags			ChannelCredential
+//	@Produce		json
+//	@Param			merchant_id	query		string	true	"商户ID"
+//	@Param			channel		query		string	false	"渠道"
+//	@Param			limit		query		int		false	"条数（默认100）"
+//	@Success		200			{object}	map[string]interface{}
+//	@Router			/api/v1/channel/credential-audits [get]
+func (h *CredentialHandler) ListAudits(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Query("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商户ID",
+		})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	audits, err := h.credentialService.ListAudits(c.Request.Context(), merchantID, c.Query("channel"), limit)
+	if err != nil {
+		h.respondError(c, "查询审计日志失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    audits,
+	})
+}
+
+// transition 执行凭证状态变更
+func (h *CredentialHandler) transition(c *gin.Context, message string,
+	fn func(ctx context.Context, id uuid.UUID, op *service.CredentialOperator) (*model.ChannelCredential, error)) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的凭证ID",
+		})
+		return
+	}
+
+	operator, ok := h.operator(c)
+	if !ok {
+		return
+	}
+
+	cred, err := fn(c.Request.Context(), id, operator)
+	if err != nil {
+		h.respondError(c, message, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    cred,
+	})
+}
+
+// operator 从认证身份获取操作人（AuthMiddleware 写入 user_id），未认证时返回 401
+func (h *CredentialHandler) operator(c *gin.Context) (*service.CredentialOperator, bool) {
+	userID, ok := c.Get("user_id")
+	operatorID, _ := userID.(uuid.UUID)
+	if !ok || operatorID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未认证的操作人",
+		})
+		return nil, false
+	}
+	return &service.CredentialOperator{
+		OperatorID: operatorID,
+		ClientIP:   c.ClientIP(),
+	}, true
+}
+
+// respondError 按错误类型返回状态码
+func (h *CredentialHandler) respondError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrCredentialNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrCredentialNotValidated),
+		errors.Is(err, service.ErrCredentialStatus),
+		errors.Is(err, service.ErrCredentialLastActive):
+		status = http.StatusConflict
+	case errors.Is(err, crypto.ErrUnknownKeyID):
+		status = http.StatusInternalServerError
+	}
+
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message + ": " + err.Error(),
+	})
+}
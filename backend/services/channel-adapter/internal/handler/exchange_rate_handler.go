@@ -1,22 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
+	"payment-platform/channel-adapter/internal/client"
 	"payment-platform/channel-adapter/internal/repository"
+	"payment-platform/channel-adapter/internal/service"
 )
 
 // ExchangeRateHandler 汇率HTTP处理器
 type ExchangeRateHandler struct {
 	exchangeRateRepo repository.ExchangeRateRepository
+	fxService        service.FXService
 }
 
 // NewExchangeRateHandler 创建汇率处理器
-func NewExchangeRateHandler(exchangeRateRepo repository.ExchangeRateRepository) *ExchangeRateHandler {
+func NewExchangeRateHandler(exchangeRateRepo repository.ExchangeRateRepository, fxService service.FXService) *ExchangeRateHandler {
 	return &ExchangeRateHandler{
 		exchangeRateRepo: exchangeRateRepo,
+		fxService:        fxService,
 	}
 }
 
@@ -38,17 +45,18 @@ type GetLatestRateRequest struct {
 
 // GetLatestRateResponse 获取最新汇率响应
 type GetLatestRateResponse struct {
-	BaseCurrency   string    `json:"base_currency"`
-	TargetCurrency string    `json:"target_currency"`
-	Rate           float64   `json:"rate"`
-	Source         string    `json:"source"`
-	ValidFrom      time.Time `json:"valid_from"`
+	BaseCurrency   string        `json:"base_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	Rate           money.Decimal `json:"rate"`
+	Source         string        `json:"source"`
+	ValidFrom      time.Time     `json:"valid_from"`
+	SnapshotID     uuid.UUID     `json:"snapshot_id"` // 汇率快照ID（汇率来源）
 }
 
 // GetLatestRate 获取最新汇率
 //
 //	@Summary		获取最新汇率
-//	@Description	获取两种货币之间的最新汇率
+//	@Description	获取两种货币之间的最新市场中间价，汇率数据过期时返回 503
 //	@Tags			ExchangeRate
 //	@Accept			json
 //	@Produce		json
@@ -57,7 +65,7 @@ type GetLatestRateResponse struct {
 //	@Success		200		{object}	map[string]interface{}
 //	@Failure		400		{object}	map[string]interface{}
 //	@Failure		404		{object}	map[string]interface{}
-//	@Failure		500		{object}	map[string]interface{}
+//	@Failure		503		{object}	map[string]interface{}
 //	@Router			/api/v1/exchange-rates/latest [get]
 func (h *ExchangeRateHandler) GetLatestRate(c *gin.Context) {
 	var req GetLatestRateRequest
@@ -69,8 +77,15 @@ func (h *ExchangeRateHandler) GetLatestRate(c *gin.Context) {
 		return
 	}
 
-	rate, err := h.exchangeRateRepo.GetLatestRate(c.Request.Context(), req.From, req.To)
+	rate, err := h.fxService.GetMidRate(c.Request.Context(), req.From, req.To)
 	if err != nil {
+		if errors.Is(err, client.ErrRateStale) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"code":    503,
+				"message": "汇率数据已过期: " + err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
 			"message": "未找到汇率数据: " + err.Error(),
@@ -86,7 +101,8 @@ func (h *ExchangeRateHandler) GetLatestRate(c *gin.Context) {
 			TargetCurrency: rate.TargetCurrency,
 			Rate:           rate.Rate,
 			Source:         rate.Source,
-			ValidFrom:      rate.ValidFrom,
+			ValidFrom:      rate.SnapshotTime,
+			SnapshotID:     rate.SnapshotID,
 		},
 	})
 }
@@ -127,11 +143,19 @@ func (h *ExchangeRateHandler) GetLatestSnapshot(c *gin.Context) {
 		})
 		return
 	}
+	if snapshot == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "未找到快照数据",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
 		"message": "success",
 		"data": gin.H{
+			"snapshot_id":    snapshot.ID,
 			"base_currency":  snapshot.BaseCurrency,
 			"rates":          snapshot.Rates,
 			"source":         snapshot.Source,
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"payment-platform/channel-adapter/internal/client"
+	"payment-platform/channel-adapter/internal/repository"
+	"payment-platform/channel-adapter/internal/service"
+)
+
+// FXHandler 换汇报价HTTP处理器
+type FXHandler struct {
+	fxService service.FXService
+}
+
+// NewFXHandler 创建换汇报价处理器
+func NewFXHandler(fxService service.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+// RegisterRoutes 注册换汇路由
+//
+// r 需已挂载服务间认证，设置商户加点额外经过 adminAuth（管理员认证）。
+func (h *FXHandler) RegisterRoutes(r gin.IRouter, adminAuth ...gin.HandlerFunc) {
+	fx := r.Group("/api/v1/fx")
+	{
+		fx.POST("/quotes", h.CreateQuote)                     // 创建报价
+		fx.GET("/quotes/:quote_id", h.GetQuote)               // 查询报价
+		fx.POST("/quotes/:quote_id/lock", h.LockQuote)        // 锁定报价
+		fx.POST("/quotes/:quote_id/release", h.ReleaseQuote)  // 释放报价
+		fx.GET("/markups", h.ListMarkups)                     // 查询商户加点
+		fx.PUT("/markups", append(adminAuth, h.SetMarkup)...) // 设置商户加点
+	}
+}
+
+// CreateQuote 创建换汇报价
+//
+//	@Summary		创建换汇报价
+//	@Description	按最新汇率快照和商户加点生成有效期内可锁定的报价
+//	@Tags			FX
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		service.CreateFXQuoteInput	true	"报价请求"
+//	@Success		200		{object}	map[string]interface{}
+//	@Failure		400		{object}	map[string]interface{}
+//	@Failure		503		{object}	map[string]interface{}
+//	@Router			/api/v1/fx/quotes [post]
+func (h *FXHandler) CreateQuote(c *gin.Context) {
+	var input service.CreateFXQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	quote, err := h.fxService.CreateQuote(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, "创建报价失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    quote,
+	})
+}
+
+// GetQuote 查询换汇报价
+//
+//	@Summary		查询换汇报价
+//	@Tags			FX
+//	@Produce		json
+//	@Param			quote_id	path		string	true	"报价编号"
+//	@Success		200			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/api/v1/fx/quotes/{quote_id} [get]
+func (h *FXHandler) GetQuote(c *gin.Context) {
+	quote, err := h.fxService.GetQuote(c.Request.Context(), c.Param("quote_id"))
+	if err != nil {
+		h.respondError(c, "查询报价失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    quote,
+	})
+}
+
+// LockQuote 锁定换汇报价
+//
+//	@Summary		锁定换汇报价
+//	@Description	将报价锁定到支付或货币转换，同一业务单重复锁定返回原报价
+//	@Tags			FX
+//	@Accept			json
+//	@Produce		json
+//	@Param			quote_id	path		string						true	"报价编号"
+//	@Param			request		body		service.LockFXQuoteInput	true	"锁定请求"
+//	@Success		200			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Router			/api/v1/fx/quotes/{quote_id}/lock [post]
+func (h *FXHandler) LockQuote(c *gin.Context) {
+	var input service.LockFXQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	input.QuoteID = c.Param("quote_id")
+
+	quote, err := h.fxService.LockQuote(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, "锁定报价失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    quote,
+	})
+}
+
+// ReleaseQuote 释放换汇报价
+//
+//	@Summary		释放换汇报价
+//	@Description	业务单未成交（创建失败或取消）时释放其锁定的报价，未被该业务单锁定时不做处理
+//	@Tags			FX
+//	@Accept			json
+//	@Produce		json
+//	@Param			quote_id	path		string						true	"报价编号"
+//	@Param			request		body		service.ReleaseFXQuoteInput	true	"释放请求"
+//	@Success		200			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/api/v1/fx/quotes/{quote_id}/release [post]
+func (h *FXHandler) ReleaseQuote(c *gin.Context) {
+	var input service.ReleaseFXQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	input.QuoteID = c.Param("quote_id")
+
+	quote, err := h.fxService.ReleaseQuote(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, "释放报价失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    quote,
+	})
+}
+
+// ListMarkups 查询商户换汇加点
+//
+//	@Summary		查询商户换汇加点
+//	@Tags			FX
+//	@Produce		json
+//	@Param			merchant_id	query		string	true	"商户ID"
+//	@Success		200			{object}	map[string]interface{}
+//	@Router			/api/v1/fx/markups [get]
+func (h *FXHandler) ListMarkups(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Query("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的商户ID",
+		})
+		return
+	}
+
+	markups, err := h.fxService.ListMarkups(c.Request.Context(), merchantID)
+	if err != nil {
+		h.respondError(c, "查询加点配置失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    markups,
+	})
+}
+
+// SetMarkup 设置商户换汇加点
+//
+//	@Summary		设置商户换汇加点
+//	@Description	币种对为空时对商户所有币种对生效
+//	@Tags			FX
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		service.SetFXMarkupInput	true	"加点配置"
+//	@Success		200		{object}	map[string]interface{}
+//	@Router			/api/v1/fx/markups [put]
+func (h *FXHandler) SetMarkup(c *gin.Context) {
+	var input service.SetFXMarkupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	markup, err := h.fxService.SetMarkup(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, "设置加点失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    markup,
+	})
+}
+
+// respondError 按错误类型返回状态码
+func (h *FXHandler) respondError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrFXQuoteNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrFXQuoteUnavailable):
+		status = http.StatusConflict
+	case errors.Is(err, client.ErrRateStale):
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message + ": " + err.Error(),
+	})
+}
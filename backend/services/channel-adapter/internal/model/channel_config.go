@@ -14,7 +14,7 @@ type ChannelConfig struct {
 	Channel     string         `gorm:"type:varchar(50);not null;index" json:"channel"`     // 渠道：stripe, paypal, crypto
 	IsEnabled   bool           `gorm:"default:true" json:"is_enabled"`                     // 是否启用
 	Mode        string         `gorm:"type:varchar(20);not null" json:"mode"`              // 模式：test, live
-	Config      string         `gorm:"type:jsonb;not null" json:"config"`                  // 配置信息（JSON加密存储）
+	Config      string         `gorm:"type:jsonb;not null" json:"-"`                       // 已废弃：渠道凭证改为 channel_credentials 信封加密存储，migrate-credentials 导入并激活后置为 {}
 	FeeRate     float64        `gorm:"type:decimal(10,4);default:0" json:"fee_rate"`       // 费率（百分比）
 	FixedFee    int64          `gorm:"type:bigint;default:0" json:"fixed_fee"`             // 固定手续费（分）
 	MinAmount   int64          `gorm:"type:bigint;default:0" json:"min_amount"`            // 最小金额（分）
@@ -54,11 +54,31 @@ type PayPalConfig struct {
 
 // CryptoConfig 加密货币配置结构
 type CryptoConfig struct {
-	WalletAddress string   `json:"wallet_address"`    // 钱包地址
-	Networks      []string `json:"networks"`          // 支持的网络：ETH, BSC, TRON
-	Confirmations int      `json:"confirmations"`     // 确认数
-	APIEndpoint   string   `json:"api_endpoint"`      // API 端点
-	APIKey        string   `json:"api_key"`           // API 密钥
+	Networks       []string          `json:"networks"`         // 支持的网络：ETH, BSC, TRON
+	Confirmations  int               `json:"confirmations"`    // 确认数
+	XPubs          map[string]string `json:"xpubs"`            // 账户层级扩展公钥，按网络族区分：evm, tron
+	RPCEndpoints   map[string]string `json:"rpc_endpoints"`    // 各网络的 JSON-RPC 端点
+	Tokens         []CryptoToken     `json:"tokens"`           // 支持的代币（ERC-20/TRC-20）
+	PaymentTimeout time.Duration     `json:"payment_timeout"`  // 支付有效期
+	ToleranceBps   int64             `json:"tolerance_bps"`    // 到账金额容差（基点），容差内视为足额
+}
+
+// CryptoToken 代币合约配置
+type CryptoToken struct {
+	Network  string `json:"network"`  // 网络
+	Symbol   string `json:"symbol"`   // 代币符号：USDT, USDC
+	Contract string `json:"contract"` // 合约地址
+	Decimals int    `json:"decimals"` // 精度
+}
+
+// DefaultCryptoTokens 主网 USDT/USDC 合约
+var DefaultCryptoTokens = []CryptoToken{
+	{Network: "ETH", Symbol: "USDT", Contract: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Decimals: 6},
+	{Network: "ETH", Symbol: "USDC", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6},
+	{Network: "BSC", Symbol: "USDT", Contract: "0x55d398326f99059fF775485246999027B3197955", Decimals: 18},
+	{Network: "BSC", Symbol: "USDC", Contract: "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d", Decimals: 18},
+	{Network: "TRON", Symbol: "USDT", Contract: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Decimals: 6},
+	{Network: "TRON", Symbol: "USDC", Contract: "TEkxiTehnzSmSe2XqrBj4w32RUN966rdz8", Decimals: 6},
 }
 
 // AlipayConfig 支付宝配置结构
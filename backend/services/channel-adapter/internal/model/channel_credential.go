@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/crypto"
+	"gorm.io/gorm"
+)
+
+// ChannelCredential 渠道凭证表（信封加密存储，每次更换凭证新增一个版本）
+//
+// 同一商户/渠道/模式可以同时有多个 active 版本：轮换时先激活新版本，确认流量切换后再停用旧版本，
+// 调用渠道使用最新的 active 版本，验证渠道 Webhook 时所有 active 版本均可用。
+type ChannelCredential struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID      uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_channel_credential_version,priority:1" json:"merchant_id"`    // 商户ID
+	Channel         string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_channel_credential_version,priority:2" json:"channel"` // 渠道：stripe, paypal, alipay, crypto
+	Mode            string         `gorm:"type:varchar(20);not null;uniqueIndex:idx_channel_credential_version,priority:3" json:"mode"`    // 模式：test, live
+	Version         int            `gorm:"not null;uniqueIndex:idx_channel_credential_version,priority:4" json:"version"`                  // 凭证版本（从1递增）
+	Status          string         `gorm:"type:varchar(20);not null;index" json:"status"`                                                  // 状态：pending, active, retired, revoked
+	KeyID           string         `gorm:"type:varchar(64);not null;index" json:"key_id"`                                                  // 加密 DEK 所用的 KEK 标识
+	EncryptedDEK    string         `gorm:"type:text;not null" json:"-"`                                                                    // KEK 加密后的数据密钥
+	Ciphertext      string         `gorm:"type:text;not null" json:"-"`                                                                    // 数据密钥加密后的凭证 JSON
+	Fingerprint     string         `gorm:"type:varchar(64);not null" json:"fingerprint"`                                                   // 凭证指纹（SHA-256 前16位），用于识别重复提交
+	Hint            string         `gorm:"type:varchar(64)" json:"hint"`                                                                   // 脱敏提示，如 sk_live_…4242
+	ValidatedAt     *time.Time     `gorm:"type:timestamptz" json:"validated_at,omitempty"`                                                 // 最近一次渠道校验通过时间
+	ValidationError string         `gorm:"type:text" json:"validation_error,omitempty"`                                                    // 最近一次渠道校验失败原因
+	ActivatedAt     *time.Time     `gorm:"type:timestamptz" json:"activated_at,omitempty"`
+	RetiredAt       *time.Time     `gorm:"type:timestamptz" json:"retired_at,omitempty"` // 停用或吊销时间
+	CreatedBy       uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`         // 提交凭证的操作人
+	CreatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (ChannelCredential) TableName() string {
+	return "channel_credentials"
+}
+
+// Envelope 返回凭证的信封密文
+func (c *ChannelCredential) Envelope() *crypto.Envelope {
+	return &crypto.Envelope{KeyID: c.KeyID, EncryptedDEK: c.EncryptedDEK, Ciphertext: c.Ciphertext}
+}
+
+// SetEnvelope 保存信封密文
+func (c *ChannelCredential) SetEnvelope(env *crypto.Envelope) {
+	c.KeyID = env.KeyID
+	c.EncryptedDEK = env.EncryptedDEK
+	c.Ciphertext = env.Ciphertext
+}
+
+// ChannelCredentialAudit 渠道凭证审计日志表（只追加）
+type ChannelCredentialAudit struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CredentialID uuid.UUID `gorm:"type:uuid;not null;index" json:"credential_id"`
+	MerchantID   uuid.UUID `gorm:"type:uuid;not null;index:idx_channel_credential_audit,priority:1" json:"merchant_id"`
+	Channel      string    `gorm:"type:varchar(50);not null;index:idx_channel_credential_audit,priority:2" json:"channel"`
+	Mode         string    `gorm:"type:varchar(20);not null" json:"mode"`
+	Version      int       `gorm:"not null" json:"version"`
+	Action       string    `gorm:"type:varchar(20);not null" json:"action"`     // 操作：create, validate, activate, retire, revoke, rewrap
+	Result       string    `gorm:"type:varchar(20);not null" json:"result"`     // 结果：success, failed
+	Detail       string    `gorm:"type:text" json:"detail,omitempty"`           // 失败原因、吊销原因、KEK 变化等
+	OperatorID   uuid.UUID `gorm:"type:uuid" json:"operator_id"`                // 操作人（系统任务为空）
+	ClientIP     string    `gorm:"type:varchar(50)" json:"client_ip,omitempty"` // 操作来源IP
+	CreatedAt    time.Time `gorm:"type:timestamptz;default:now();index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ChannelCredentialAudit) TableName() string {
+	return "channel_credential_audits"
+}
+
+// 凭证状态常量
+const (
+	CredentialStatusPending = "pending" // 已提交，未激活
+	CredentialStatusActive  = "active"  // 使用中
+	CredentialStatusRetired = "retired" // 轮换后停用
+	CredentialStatusRevoked = "revoked" // 泄露等原因吊销
+)
+
+// 凭证审计操作常量
+const (
+	CredentialActionCreate   = "create"
+	CredentialActionValidate = "validate"
+	CredentialActionActivate = "activate"
+	CredentialActionRetire   = "retire"
+	CredentialActionRevoke   = "revoke"
+	CredentialActionRewrap   = "rewrap"
+)
+
+// 审计结果常量
+const (
+	CredentialAuditSuccess = "success"
+	CredentialAuditFailed  = "failed"
+)
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CryptoDeposit 加密货币收款记录表，每笔支付对应一个 HD 钱包派生的收款地址
+type CryptoDeposit struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentNo       string         `gorm:"type:varchar(64);unique;not null" json:"payment_no"`                         // 支付流水号
+	Network         string         `gorm:"type:varchar(20);not null" json:"network"`                                   // 网络：ETH, BSC, TRON
+	Symbol          string         `gorm:"type:varchar(20);not null" json:"symbol"`                                    // 币种：ETH, USDT 等
+	TokenContract   string         `gorm:"type:varchar(100)" json:"token_contract"`                                    // 代币合约地址，原生币为空
+	TokenDecimals   int            `gorm:"not null" json:"token_decimals"`                                             // 币种精度
+	KeyID           string         `gorm:"type:varchar(20);not null;uniqueIndex:idx_crypto_deposit_key" json:"key_id"` // 派生所用的 xpub 标识：evm, tron
+	DerivationIndex uint32         `gorm:"not null;uniqueIndex:idx_crypto_deposit_key" json:"derivation_index"`        // 派生序号（路径 .../0/index）
+	Address         string         `gorm:"type:varchar(100);not null;index" json:"address"`                            // 收款地址
+	ExpectedAmount  string         `gorm:"type:varchar(80);not null" json:"expected_amount"`                           // 应收数量（最小单位）
+	ReceivedAmount  string         `gorm:"type:varchar(80);default:'0'" json:"received_amount"`                        // 已到账数量（最小单位）
+	StartBlock      uint64         `gorm:"not null" json:"start_block"`                                                // 创建支付时的区块高度，扫描起点
+	FirstSeenBlock  uint64         `gorm:"default:0" json:"first_seen_block"`                                          // 首笔到账区块
+	FirstSeenAt     *time.Time     `gorm:"type:timestamptz" json:"first_seen_at"`                                      // 首笔到账区块时间
+	TxHash          string         `gorm:"type:varchar(100)" json:"tx_hash"`                                           // 首笔到账交易哈希
+	Confirmations   uint64         `gorm:"default:0" json:"confirmations"`                                             // 首笔到账确认数
+	Status          string         `gorm:"type:varchar(20);not null;index" json:"status"`                              // 收款状态
+	FiatAmount      int64          `gorm:"type:bigint;not null" json:"fiat_amount"`                                    // 法币金额（分）
+	FiatCurrency    string         `gorm:"type:varchar(10);not null" json:"fiat_currency"`                             // 法币币种
+	CryptoPrice     float64        `gorm:"type:decimal(30,10)" json:"crypto_price"`                                    // 创建时锁定的美元价格
+	ExpiresAt       time.Time      `gorm:"type:timestamptz;not null" json:"expires_at"`                                // 支付截止时间
+	PaidAt          *time.Time     `gorm:"type:timestamptz" json:"paid_at"`                                            // 到账确认时间
+	CreatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (CryptoDeposit) TableName() string {
+	return "crypto_deposits"
+}
+
+// IsFinal 是否已是终态（不再扫描链上数据）
+func (d *CryptoDeposit) IsFinal() bool {
+	return d.Status == CryptoDepositStatusPaid || d.Status == CryptoDepositStatusOverpaid
+}
+
+// 加密货币收款状态常量
+const (
+	CryptoDepositStatusPending    = "pending"    // 等待付款
+	CryptoDepositStatusConfirming = "confirming" // 已到账，等待确认数
+	CryptoDepositStatusPaid       = "paid"       // 已足额到账
+	CryptoDepositStatusUnderpaid  = "underpaid"  // 到账不足
+	CryptoDepositStatusOverpaid   = "overpaid"   // 超额到账
+	CryptoDepositStatusLate       = "late"       // 过期后到账，需人工处理
+	CryptoDepositStatusExpired    = "expired"    // 过期未付款
+)
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
+)
+
+// FXQuote 换汇报价
+//
+// 报价锁定某一时刻的汇率快照和商户加点，在有效期内可被一笔支付或货币转换锁定使用，
+// 锁定后汇率不再随市场变动，便于事后追溯汇率来源和计算汇兑损益。
+type FXQuote struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	QuoteID        string        `gorm:"type:varchar(64);uniqueIndex;not null" json:"quote_id"`        // 报价编号（对外）
+	MerchantID     uuid.UUID     `gorm:"type:uuid;not null;index" json:"merchant_id"`                  // 商户ID
+	BaseCurrency   string        `gorm:"type:varchar(10);not null" json:"base_currency"`               // 卖出币种
+	TargetCurrency string        `gorm:"type:varchar(10);not null" json:"target_currency"`             // 买入币种
+	MidRate        money.Decimal `gorm:"type:decimal(24,10);not null" json:"mid_rate"`                 // 市场中间价（1 base = mid_rate target）
+	MarkupBps      int           `gorm:"type:integer;not null;default:0" json:"markup_bps"`            // 加点（基点，1bp = 0.01%）
+	Rate           money.Decimal `gorm:"type:decimal(24,10);not null" json:"rate"`                     // 成交汇率（已扣除加点）
+	BaseAmount     int64         `gorm:"type:bigint;default:0" json:"base_amount"`                     // 报价金额（最小单位，可选）
+	TargetAmount   int64         `gorm:"type:bigint;default:0" json:"target_amount"`                   // 按成交汇率换算的金额
+	SnapshotID     uuid.UUID     `gorm:"type:uuid;not null;index" json:"snapshot_id"`                  // 汇率快照ID
+	RateSource     string        `gorm:"type:varchar(50)" json:"rate_source"`                          // 汇率来源
+	RateTime       time.Time     `gorm:"type:timestamptz;not null" json:"rate_time"`                   // 快照时间
+	ExpiresAt      time.Time     `gorm:"type:timestamptz;not null;index" json:"expires_at"`            // 报价过期时间
+	Status         string        `gorm:"type:varchar(20);not null;default:'open';index" json:"status"` // open, locked, expired
+	Reference      string        `gorm:"type:varchar(64);index" json:"reference,omitempty"`            // 锁定该报价的业务单号（支付流水号/转换单号）
+	LockedAt       *time.Time    `gorm:"type:timestamptz" json:"locked_at,omitempty"`                  // 锁定时间
+	CreatedAt      time.Time     `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time     `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FXQuote) TableName() string {
+	return "fx_quotes"
+}
+
+// 报价状态
+const (
+	FXQuoteStatusOpen    = "open"    // 有效，可锁定
+	FXQuoteStatusLocked  = "locked"  // 已被业务单锁定
+	FXQuoteStatusExpired = "expired" // 已过期
+)
+
+// IsExpired 报价是否已过期
+func (q *FXQuote) IsExpired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// FXMarkup 商户换汇加点配置
+//
+// BaseCurrency/TargetCurrency 为空表示对该商户所有币种对生效，指定币种对的配置优先。
+type FXMarkup struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_fx_markup_pair" json:"merchant_id"`
+	BaseCurrency   string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_fx_markup_pair" json:"base_currency"`
+	TargetCurrency string    `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_fx_markup_pair" json:"target_currency"`
+	MarkupBps      int       `gorm:"type:integer;not null" json:"markup_bps"`
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FXMarkup) TableName() string {
+	return "fx_markups"
+}
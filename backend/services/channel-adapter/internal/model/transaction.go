@@ -92,6 +92,7 @@ type PreAuthRecord struct {
 	ChannelPreAuthNo  string         `gorm:"type:varchar(200);unique;index" json:"channel_pre_auth_no"` // 渠道预授权号
 	Amount            int64          `gorm:"type:bigint;not null" json:"amount"`                    // 预授权金额（分）
 	Currency          string         `gorm:"type:varchar(10);not null" json:"currency"`             // 货币
+	ISOMinorUnits     bool           `gorm:"default:false" json:"iso_minor_units"`                  // 金额是否为 ISO 4217 最小单位（迁移前创建的记录为 false，零小数位货币金额为主单位×100）
 	Status            string         `gorm:"type:varchar(20);not null;index" json:"status"`         // 状态
 	CapturedAmount    int64          `gorm:"type:bigint;default:0" json:"captured_amount"`          // 已捕获金额（分）
 	ExpiresAt         *time.Time     `gorm:"type:timestamptz" json:"expires_at"`                    // 过期时间
@@ -14,6 +14,8 @@ type ChannelRepository interface {
 	GetConfig(ctx context.Context, merchantID uuid.UUID, channel string) (*model.ChannelConfig, error)
 	GetConfigByID(ctx context.Context, id uuid.UUID) (*model.ChannelConfig, error)
 	ListConfigs(ctx context.Context, merchantID uuid.UUID) ([]*model.ChannelConfig, error)
+	// ListPlaintextConfigs 按 ID 顺序查询 afterID 之后仍在 config 字段保存明文凭证的渠道配置（待迁移到 channel_credentials）
+	ListPlaintextConfigs(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.ChannelConfig, error)
 	CreateConfig(ctx context.Context, config *model.ChannelConfig) error
 	UpdateConfig(ctx context.Context, config *model.ChannelConfig) error
 	DeleteConfig(ctx context.Context, id uuid.UUID) error
@@ -89,6 +91,17 @@ func (r *channelRepository) ListConfigs(ctx context.Context, merchantID uuid.UUI
 	return configs, err
 }
 
+// ListPlaintextConfigs 查询仍保存明文凭证的渠道配置
+func (r *channelRepository) ListPlaintextConfigs(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.ChannelConfig, error) {
+	var configs []*model.ChannelConfig
+	err := r.db.WithContext(ctx).
+		Where("config::text NOT IN ('{}', 'null') AND id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&configs).Error
+	return configs, err
+}
+
 // CreateConfig 创建渠道配置
 func (r *channelRepository) CreateConfig(ctx context.Context, config *model.ChannelConfig) error {
 	return r.db.WithContext(ctx).Create(config).Error
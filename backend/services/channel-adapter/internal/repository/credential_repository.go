@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// CredentialRepository 渠道凭证仓储接口
+type CredentialRepository interface {
+	// CreateVersion 以当前最大版本号+1保存新凭证，并写入审计日志
+	CreateVersion(ctx context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error
+	// Save 更新凭证并写入审计日志（同一事务）
+	Save(ctx context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ChannelCredential, error)
+	List(ctx context.Context, merchantID uuid.UUID, channel string) ([]*model.ChannelCredential, error)
+	// ListActive 查询使用中的凭证，按版本从新到旧排列
+	ListActive(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]*model.ChannelCredential, error)
+	// ListNotEncryptedWith 查询 DEK 未使用指定 KEK 加密的凭证（KEK 轮换后重新加密）
+	ListNotEncryptedWith(ctx context.Context, keyID string, limit int) ([]*model.ChannelCredential, error)
+
+	CreateAudit(ctx context.Context, audit *model.ChannelCredentialAudit) error
+	ListAudits(ctx context.Context, merchantID uuid.UUID, channel string, limit int) ([]*model.ChannelCredentialAudit, error)
+}
+
+type credentialRepository struct {
+	db *gorm.DB
+}
+
+// NewCredentialRepository 创建渠道凭证仓储
+func NewCredentialRepository(db *gorm.DB) CredentialRepository {
+	return &credentialRepository{db: db}
+}
+
+// CreateVersion 保存新版本凭证
+func (r *credentialRepository) CreateVersion(ctx context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住同一商户/渠道/模式的已有版本，避免并发提交分配到相同版本号
+		var versions []int
+		if err := tx.Model(&model.ChannelCredential{}).
+			Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("merchant_id = ? AND channel = ? AND mode = ?", cred.MerchantID, cred.Channel, cred.Mode).
+			Pluck("version", &versions).Error; err != nil {
+			return err
+		}
+		cred.Version = 1
+		for _, v := range versions {
+			if v >= cred.Version {
+				cred.Version = v + 1
+			}
+		}
+		if err := tx.Create(cred).Error; err != nil {
+			return err
+		}
+		audit.CredentialID = cred.ID
+		audit.Version = cred.Version
+		return tx.Create(audit).Error
+	})
+}
+
+// Save 更新凭证并写入审计日志
+func (r *credentialRepository) Save(ctx context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(cred).Error; err != nil {
+			return err
+		}
+		if audit == nil {
+			return nil
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+// GetByID 根据ID获取凭证
+func (r *credentialRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.ChannelCredential, error) {
+	var cred model.ChannelCredential
+	err := r.db.WithContext(ctx).First(&cred, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// List 查询商户的凭证版本，channel 为空时返回所有渠道
+func (r *credentialRepository) List(ctx context.Context, merchantID uuid.UUID, channel string) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	db := r.db.WithContext(ctx).Where("merchant_id = ?", merchantID)
+	if channel != "" {
+		db = db.Where("channel = ?", channel)
+	}
+	err := db.Order("channel, mode, version DESC").Find(&creds).Error
+	return creds, err
+}
+
+// ListActive 查询使用中的凭证
+func (r *credentialRepository) ListActive(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	err := r.db.WithContext(ctx).
+		Where("merchant_id = ? AND channel = ? AND mode = ? AND status = ?", merchantID, channel, mode, model.CredentialStatusActive).
+		Order("version DESC").
+		Find(&creds).Error
+	return creds, err
+}
+
+// ListNotEncryptedWith 查询未使用指定 KEK 的凭证（不含已吊销凭证）
+func (r *credentialRepository) ListNotEncryptedWith(ctx context.Context, keyID string, limit int) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	err := r.db.WithContext(ctx).
+		Where("key_id <> ? AND status <> ?", keyID, model.CredentialStatusRevoked).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&creds).Error
+	return creds, err
+}
+
+// CreateAudit 写入审计日志
+func (r *credentialRepository) CreateAudit(ctx context.Context, audit *model.ChannelCredentialAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+// ListAudits 查询审计日志，按时间倒序
+func (r *credentialRepository) ListAudits(ctx context.Context, merchantID uuid.UUID, channel string, limit int) ([]*model.ChannelCredentialAudit, error) {
+	var audits []*model.ChannelCredentialAudit
+	db := r.db.WithContext(ctx).Where("merchant_id = ?", merchantID)
+	if channel != "" {
+		db = db.Where("channel = ?", channel)
+	}
+	err := db.Order("created_at DESC").Limit(limit).Find(&audits).Error
+	return audits, err
+}
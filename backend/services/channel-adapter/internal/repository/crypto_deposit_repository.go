@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// maxIndexAllocationRetries 并发分配派生序号冲突时的重试次数
+const maxIndexAllocationRetries = 5
+
+// CryptoDepositRepository 加密货币收款记录仓储接口
+type CryptoDepositRepository interface {
+	// CreateWithNextIndex 为收款记录分配同一 xpub 下的下一个派生序号并保存，
+	// derive 根据序号生成收款地址，skip 判断 derive 返回的错误是否应跳过该序号
+	CreateWithNextIndex(ctx context.Context, deposit *model.CryptoDeposit, derive func(index uint32) (string, error), skip func(error) bool) error
+	GetByPaymentNo(ctx context.Context, paymentNo string) (*model.CryptoDeposit, error)
+	Update(ctx context.Context, deposit *model.CryptoDeposit) error
+}
+
+type cryptoDepositRepository struct {
+	db *gorm.DB
+}
+
+// NewCryptoDepositRepository 创建加密货币收款记录仓储
+func NewCryptoDepositRepository(db *gorm.DB) CryptoDepositRepository {
+	return &cryptoDepositRepository{db: db}
+}
+
+// CreateWithNextIndex 分配派生序号并创建收款记录
+//
+// 序号取同一 key_id 下的最大值加一，(key_id, derivation_index) 唯一索引保证地址不会复用，
+// 并发冲突时重新分配。
+func (r *cryptoDepositRepository) CreateWithNextIndex(ctx context.Context, deposit *model.CryptoDeposit, derive func(index uint32) (string, error), skip func(error) bool) error {
+	var lastErr error
+	for attempt := 0; attempt < maxIndexAllocationRetries; attempt++ {
+		lastErr = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var next uint32
+			err := tx.Unscoped().Model(&model.CryptoDeposit{}).
+				Where("key_id = ?", deposit.KeyID).
+				Select("COALESCE(MAX(derivation_index) + 1, 0)").
+				Scan(&next).Error
+			if err != nil {
+				return err
+			}
+
+			for {
+				address, err := derive(next)
+				if err == nil {
+					deposit.DerivationIndex = next
+					deposit.Address = address
+					break
+				}
+				if skip == nil || !skip(err) {
+					return err
+				}
+				next++
+			}
+
+			return tx.Create(deposit).Error
+		})
+		if lastErr == nil || !isUniqueViolation(lastErr) {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("分配派生序号失败: %w", lastErr)
+}
+
+// GetByPaymentNo 根据支付流水号查询
+func (r *cryptoDepositRepository) GetByPaymentNo(ctx context.Context, paymentNo string) (*model.CryptoDeposit, error) {
+	var deposit model.CryptoDeposit
+	err := r.db.WithContext(ctx).
+		Where("payment_no = ?", paymentNo).
+		First(&deposit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+// Update 更新收款记录
+func (r *cryptoDepositRepository) Update(ctx context.Context, deposit *model.CryptoDeposit) error {
+	return r.db.WithContext(ctx).Save(deposit).Error
+}
+
+// isUniqueViolation 是否为唯一约束冲突（PostgreSQL 23505）
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "23505") || strings.Contains(msg, "duplicate key")
+}
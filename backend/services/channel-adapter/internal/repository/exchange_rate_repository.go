@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -33,6 +34,9 @@ type ExchangeRateRepository interface {
 	// 获取最新的汇率快照
 	GetLatestSnapshot(ctx context.Context, baseCurrency string) (*model.ExchangeRateSnapshot, error)
 
+	// 根据ID获取汇率快照（报价溯源）
+	GetSnapshotByID(ctx context.Context, id uuid.UUID) (*model.ExchangeRateSnapshot, error)
+
 	// 查询时间范围内的快照
 	GetSnapshotHistory(ctx context.Context, baseCurrency string, startTime, endTime time.Time) ([]*model.ExchangeRateSnapshot, error)
 }
@@ -171,6 +175,18 @@ func (r *exchangeRateRepository) GetLatestSnapshot(ctx context.Context, baseCurr
 	return &snapshot, err
 }
 
+// GetSnapshotByID 根据ID获取汇率快照
+func (r *exchangeRateRepository) GetSnapshotByID(ctx context.Context, id uuid.UUID) (*model.ExchangeRateSnapshot, error) {
+	var snapshot model.ExchangeRateSnapshot
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&snapshot).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+
+	return &snapshot, err
+}
+
 // GetSnapshotHistory 查询时间范围内的快照
 func (r *exchangeRateRepository) GetSnapshotHistory(ctx context.Context, baseCurrency string, startTime, endTime time.Time) ([]*model.ExchangeRateSnapshot, error) {
 	var snapshots []*model.ExchangeRateSnapshot
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// ErrFXQuoteUnavailable 报价已过期或已被其他业务单锁定
+var ErrFXQuoteUnavailable = errors.New("报价已过期或已被使用")
+
+// FXQuoteRepository 换汇报价仓储接口
+type FXQuoteRepository interface {
+	Create(ctx context.Context, quote *model.FXQuote) error
+	GetByQuoteID(ctx context.Context, quoteID string) (*model.FXQuote, error)
+	// Lock 将有效报价锁定到业务单，同一业务单重复锁定返回原报价
+	Lock(ctx context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error)
+	// Release 释放业务单锁定的报价（业务单未成交时调用），未被该业务单锁定时不做处理
+	Release(ctx context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error)
+
+	// 商户加点配置
+	GetMarkup(ctx context.Context, merchantID uuid.UUID, baseCurrency, targetCurrency string) (*model.FXMarkup, error)
+	SaveMarkup(ctx context.Context, markup *model.FXMarkup) error
+	ListMarkups(ctx context.Context, merchantID uuid.UUID) ([]*model.FXMarkup, error)
+}
+
+type fxQuoteRepository struct {
+	db *gorm.DB
+}
+
+// NewFXQuoteRepository 创建换汇报价仓储
+func NewFXQuoteRepository(db *gorm.DB) FXQuoteRepository {
+	return &fxQuoteRepository{db: db}
+}
+
+// Create 创建报价
+func (r *fxQuoteRepository) Create(ctx context.Context, quote *model.FXQuote) error {
+	return r.db.WithContext(ctx).Create(quote).Error
+}
+
+// GetByQuoteID 根据报价编号查询
+func (r *fxQuoteRepository) GetByQuoteID(ctx context.Context, quoteID string) (*model.FXQuote, error) {
+	var quote model.FXQuote
+	err := r.db.WithContext(ctx).Where("quote_id = ?", quoteID).First(&quote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// Lock 锁定报价
+//
+// 在行锁内校验状态和有效期，保证一个报价只能被一个业务单使用。
+func (r *fxQuoteRepository) Lock(ctx context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error) {
+	var quote model.FXQuote
+	expired := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("quote_id = ?", quoteID).
+			First(&quote).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case quote.Status == model.FXQuoteStatusLocked && quote.Reference == reference:
+			return nil
+		case quote.Status != model.FXQuoteStatusOpen:
+			return ErrFXQuoteUnavailable
+		case quote.IsExpired(now):
+			// 标记过期后提交事务，由调用方返回不可用
+			expired = true
+			return tx.Model(&quote).Update("status", model.FXQuoteStatusExpired).Error
+		}
+
+		quote.Status = model.FXQuoteStatusLocked
+		quote.Reference = reference
+		quote.LockedAt = &now
+		return tx.Model(&quote).Updates(map[string]interface{}{
+			"status":     quote.Status,
+			"reference":  quote.Reference,
+			"locked_at":  quote.LockedAt,
+			"updated_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrFXQuoteUnavailable
+	}
+	return &quote, nil
+}
+
+// Release 释放报价
+//
+// 仅释放被该业务单锁定的报价，未过期的恢复为可锁定，已过期的标记为过期。
+func (r *fxQuoteRepository) Release(ctx context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error) {
+	var quote model.FXQuote
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("quote_id = ?", quoteID).
+			First(&quote).Error; err != nil {
+			return err
+		}
+
+		if quote.Status != model.FXQuoteStatusLocked || quote.Reference != reference {
+			return nil
+		}
+
+		quote.Status = model.FXQuoteStatusOpen
+		if quote.IsExpired(now) {
+			quote.Status = model.FXQuoteStatusExpired
+		}
+		quote.Reference = ""
+		quote.LockedAt = nil
+		return tx.Model(&quote).Updates(map[string]interface{}{
+			"status":     quote.Status,
+			"reference":  "",
+			"locked_at":  nil,
+			"updated_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// GetMarkup 查询商户加点，指定币种对的配置优先于商户通用配置
+func (r *fxQuoteRepository) GetMarkup(ctx context.Context, merchantID uuid.UUID, baseCurrency, targetCurrency string) (*model.FXMarkup, error) {
+	var markups []*model.FXMarkup
+	err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Where("(base_currency = ? AND target_currency = ?) OR (base_currency = '' AND target_currency = '')", baseCurrency, targetCurrency).
+		Order("base_currency DESC").
+		Find(&markups).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(markups) == 0 {
+		return nil, nil
+	}
+	return markups[0], nil
+}
+
+// SaveMarkup 新增或更新商户加点
+func (r *fxQuoteRepository) SaveMarkup(ctx context.Context, markup *model.FXMarkup) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "base_currency"}, {Name: "target_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"markup_bps", "updated_at"}),
+	}).Create(markup).Error
+}
+
+// ListMarkups 查询商户的全部加点配置
+func (r *fxQuoteRepository) ListMarkups(ctx context.Context, merchantID uuid.UUID) ([]*model.FXMarkup, error) {
+	var markups []*model.FXMarkup
+	err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Order("base_currency, target_currency").
+		Find(&markups).Error
+	return markups, err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/adapter"
+)
+
+// CredentialResolver 解密商户使用中的渠道凭证（由 CredentialService 实现）
+type CredentialResolver interface {
+	ResolveCredentials(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]json.RawMessage, error)
+}
+
+// SetCredentialResolver 设置商户渠道凭证来源（依赖注入），未设置时所有商户使用平台配置的适配器
+func (s *channelService) SetCredentialResolver(resolver CredentialResolver) {
+	s.credentials = resolver
+}
+
+// channelAdapters 返回调用渠道使用的适配器
+//
+// 商户有使用中的渠道凭证时，按凭证版本从新到旧返回对应的适配器；没有时使用平台配置的适配器。
+// mode 为空时从商户渠道配置读取。
+func (s *channelService) channelAdapters(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]adapter.PaymentAdapter, error) {
+	if s.credentials != nil && merchantID != uuid.Nil {
+		if mode == "" {
+			config, err := s.repo.GetConfig(ctx, merchantID, channel)
+			if err != nil {
+				return nil, fmt.Errorf("获取渠道配置失败: %w", err)
+			}
+			if config != nil {
+				mode = config.Mode
+			}
+		}
+
+		secrets, err := s.credentials.ResolveCredentials(ctx, merchantID, channel, mode)
+		switch {
+		case err == nil:
+			adapters := make([]adapter.PaymentAdapter, 0, len(secrets))
+			for i, secret := range secrets {
+				a, err := s.adapterFactory.Build(channel, mode, secret)
+				if err != nil {
+					logger.Error("failed to build adapter from merchant credential",
+						zap.String("merchant_id", merchantID.String()),
+						zap.String("channel", channel),
+						zap.Int("index", i),
+						zap.Error(err))
+					continue
+				}
+				adapters = append(adapters, a)
+			}
+			if len(adapters) == 0 {
+				return nil, fmt.Errorf("商户 %s 渠道凭证不可用", channel)
+			}
+			return adapters, nil
+		case errors.Is(err, ErrNoActiveCredential):
+			// 未迁移到渠道凭证的商户继续使用平台配置
+		default:
+			return nil, fmt.Errorf("加载渠道凭证失败: %w", err)
+		}
+	}
+
+	adpt, ok := s.adapterFactory.GetAdapter(channel)
+	if !ok {
+		return nil, fmt.Errorf("不支持的支付渠道: %s", channel)
+	}
+	return []adapter.PaymentAdapter{adpt}, nil
+}
+
+// callAdapters 依次使用各凭证版本调用渠道，仅在凭证被渠道拒绝时换用下一个版本（轮换期间新旧版本均可用）
+func callAdapters(adapters []adapter.PaymentAdapter, call func(adpt adapter.PaymentAdapter) error) error {
+	var err error
+	for _, adpt := range adapters {
+		if err = call(adpt); err == nil || !adapter.IsCredentialError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// verifyWebhook 验证 Webhook 签名，返回验证通过的适配器
+//
+// 先使用平台配置验证；失败时按事件关联的交易找到商户，依次尝试该商户所有使用中的凭证版本。
+func (s *channelService) verifyWebhook(ctx context.Context, channel, signature string, body []byte) (adapter.PaymentAdapter, error) {
+	platform, ok := s.adapterFactory.GetAdapter(channel)
+	if !ok {
+		return nil, fmt.Errorf("不支持的支付渠道: %s", channel)
+	}
+	verified, err := platform.VerifyWebhook(ctx, signature, body)
+	if err == nil && verified {
+		return platform, nil
+	}
+	if err == nil {
+		err = errors.New("签名无效")
+	}
+	if s.credentials == nil {
+		return nil, fmt.Errorf("Webhook 签名验证失败: %w", err)
+	}
+
+	// 签名验证前解析的内容只用于定位商户，不参与后续处理
+	event, parseErr := platform.ParseWebhook(ctx, body)
+	if parseErr != nil || event == nil {
+		return nil, fmt.Errorf("Webhook 签名验证失败: %w", err)
+	}
+	tx, _ := s.repo.GetTransaction(ctx, event.PaymentNo)
+	if tx == nil && event.ChannelTradeNo != "" {
+		tx, _ = s.repo.GetTransactionByChannelTradeNo(ctx, event.ChannelTradeNo)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("Webhook 签名验证失败: %w", err)
+	}
+
+	adapters, resolveErr := s.channelAdapters(ctx, tx.MerchantID, channel, "")
+	if resolveErr != nil {
+		return nil, fmt.Errorf("Webhook 签名验证失败: %w", resolveErr)
+	}
+	for _, adpt := range adapters {
+		if ok, verifyErr := adpt.VerifyWebhook(ctx, signature, body); verifyErr == nil && ok {
+			return adpt, nil
+		}
+	}
+	return nil, fmt.Errorf("Webhook 签名验证失败: %w", err)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76"
+	"payment-platform/channel-adapter/internal/adapter"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// stubResolver 固定返回的商户凭证
+type stubResolver struct {
+	secrets []json.RawMessage
+	err     error
+}
+
+func (r *stubResolver) ResolveCredentials(context.Context, uuid.UUID, string, string) ([]json.RawMessage, error) {
+	return r.secrets, r.err
+}
+
+// stubAdapter 只实现 CancelPayment，按凭证返回预设错误并记录调用顺序（适配器按凭证缓存，错误在调用时读取）
+type stubAdapter struct {
+	adapter.PaymentAdapter
+	key   string
+	errs  map[string]error
+	calls *[]string
+}
+
+func (a *stubAdapter) CancelPayment(context.Context, string) error {
+	*a.calls = append(*a.calls, a.key)
+	return a.errs[a.key]
+}
+
+func TestChannelAdaptersCredentialFallback(t *testing.T) {
+	var calls []string
+	errs := map[string]error{
+		"new": &stripe.Error{HTTPStatusCode: 401, Msg: "Invalid API Key provided"},
+		"old": nil,
+	}
+
+	factory := adapter.NewAdapterFactory()
+	platform := &stubAdapter{key: "platform", calls: &calls}
+	factory.Register(model.ChannelStripe, platform)
+	factory.RegisterBuilder(model.ChannelStripe, func(mode string, secret []byte) (adapter.PaymentAdapter, error) {
+		var cfg model.StripeConfig
+		if err := json.Unmarshal(secret, &cfg); err != nil {
+			return nil, err
+		}
+		return &stubAdapter{key: cfg.APIKey, errs: errs, calls: &calls}, nil
+	})
+	s := &channelService{adapterFactory: factory}
+	merchantID := uuid.New()
+
+	// 未设置凭证来源时使用平台配置
+	adapters, err := s.channelAdapters(context.Background(), merchantID, model.ChannelStripe, model.ModeLive)
+	if err != nil || len(adapters) != 1 || adapters[0] != platform {
+		t.Fatalf("expected platform adapter, got %v, %v", adapters, err)
+	}
+
+	// 没有使用中的凭证时回退到平台配置
+	s.SetCredentialResolver(&stubResolver{err: ErrNoActiveCredential})
+	adapters, err = s.channelAdapters(context.Background(), merchantID, model.ChannelStripe, model.ModeLive)
+	if err != nil || len(adapters) != 1 || adapters[0] != platform {
+		t.Fatalf("expected platform adapter, got %v, %v", adapters, err)
+	}
+
+	// 解密失败不能静默回退到平台配置
+	s.SetCredentialResolver(&stubResolver{err: errors.New("unknown key id")})
+	if _, err := s.channelAdapters(context.Background(), merchantID, model.ChannelStripe, model.ModeLive); err == nil {
+		t.Fatal("expected resolve error")
+	}
+
+	// 最新版本被渠道拒绝时换用旧版本
+	s.SetCredentialResolver(&stubResolver{secrets: []json.RawMessage{
+		json.RawMessage(`{"api_key":"new"}`),
+		json.RawMessage(`{"api_key":"old"}`),
+	}})
+	adapters, err = s.channelAdapters(context.Background(), merchantID, model.ChannelStripe, model.ModeLive)
+	if err != nil || len(adapters) != 2 {
+		t.Fatalf("expected 2 merchant adapters, got %v, %v", adapters, err)
+	}
+	err = callAdapters(adapters, func(adpt adapter.PaymentAdapter) error {
+		return adpt.CancelPayment(context.Background(), "pi_1")
+	})
+	if err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "new" || calls[1] != "old" {
+		t.Fatalf("unexpected call order: %v", calls)
+	}
+
+	// 非凭证错误（如拒付、余额不足）不换用其他版本，避免重复扣款
+	calls = nil
+	errs["new"] = &stripe.Error{HTTPStatusCode: 402, Msg: "card_declined"}
+	adapters, _ = s.channelAdapters(context.Background(), uuid.New(), model.ChannelStripe, model.ModeLive)
+	if err := callAdapters(adapters, func(adpt adapter.PaymentAdapter) error {
+		return adpt.CancelPayment(context.Background(), "pi_1")
+	}); err == nil || len(calls) != 1 {
+		t.Fatalf("expected single call with error, got %v, calls %v", err, calls)
+	}
+}
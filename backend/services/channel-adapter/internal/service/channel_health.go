@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/router"
+	"github.com/redis/go-redis/v9"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// HealthMonitorConfig 渠道健康监控配置
+type HealthMonitorConfig struct {
+	Window              time.Duration // 错误率统计窗口
+	MinRequests         int64         // 判定降级所需的最少请求数
+	DegradedErrorRate   float64       // 基础设施错误率达到该值判定为降级
+	DegradedTimeoutRate float64       // 超时率达到该值判定为降级
+	TripMinRequests     uint32        // 熔断器打开所需的最少请求数（熔断器统计周期内）
+	TripErrorRate       float64       // 熔断器打开的错误率阈值
+	OpenTimeout         time.Duration // 熔断器打开后多久进入半开
+	HalfOpenRequests    uint32        // 半开状态允许的探测请求数
+	PublishInterval     time.Duration // 健康状态上报间隔
+}
+
+// DefaultHealthMonitorConfig 默认渠道健康监控配置
+func DefaultHealthMonitorConfig() HealthMonitorConfig {
+	return HealthMonitorConfig{
+		Window:              5 * time.Minute,
+		MinRequests:         20,
+		DegradedErrorRate:   0.1,
+		DegradedTimeoutRate: 0.05,
+		TripMinRequests:     10,
+		TripErrorRate:       0.5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenRequests:    3,
+		PublishInterval:     10 * time.Second,
+	}
+}
+
+// ChannelHealthMonitor 渠道级熔断与健康监控
+//
+// 每个渠道一个熔断器，只有基础设施类错误（超时、连接失败、5xx、限流）计入失败，
+// 业务拒绝（余额不足、卡被拒等）视为渠道正常。健康状态定期上报到 Redis 供路由摘流。
+type ChannelHealthMonitor struct {
+	config      HealthMonitorConfig
+	redisClient *redis.Client
+	instance    string
+
+	mu       sync.Mutex
+	breakers map[string]*gobreaker.CircuitBreaker
+	windows  map[string]*healthWindow
+	now      func() time.Time
+}
+
+// healthWindow 按分钟分桶的调用统计
+type healthWindow struct {
+	buckets []healthBucket
+}
+
+type healthBucket struct {
+	start    time.Time
+	requests int64
+	failures int64
+	timeouts int64
+}
+
+// NewChannelHealthMonitor 创建渠道健康监控
+func NewChannelHealthMonitor(redisClient *redis.Client, config HealthMonitorConfig) *ChannelHealthMonitor {
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		instance = fmt.Sprintf("channel-adapter-%d", os.Getpid())
+	}
+	return &ChannelHealthMonitor{
+		config:      config,
+		redisClient: redisClient,
+		instance:    instance,
+		breakers:    make(map[string]*gobreaker.CircuitBreaker),
+		windows:     make(map[string]*healthWindow),
+		now:         time.Now,
+	}
+}
+
+// Execute 通过渠道熔断器调用渠道，熔断打开时直接返回错误
+func (m *ChannelHealthMonitor) Execute(ctx context.Context, channel string, fn func() error) error {
+	if m == nil {
+		return fn()
+	}
+
+	var callErr error
+	_, err := m.breaker(channel).Execute(func() (interface{}, error) {
+		callErr = fn()
+		return nil, callErr
+	})
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return fmt.Errorf("渠道 %s 熔断中（circuit breaker open），请稍后重试: %w", channel, err)
+	}
+
+	m.record(channel, callErr)
+	return callErr
+}
+
+// breaker 获取渠道熔断器（懒加载）
+func (m *ChannelHealthMonitor) breaker(channel string) *gobreaker.CircuitBreaker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cb, ok := m.breakers[channel]; ok {
+		return cb
+	}
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "channel:" + channel,
+		MaxRequests: m.config.HalfOpenRequests,
+		Interval:    m.config.Window,
+		Timeout:     m.config.OpenTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			if counts.Requests < m.config.TripMinRequests {
+				return false
+			}
+			return float64(counts.TotalFailures)/float64(counts.Requests) >= m.config.TripErrorRate
+		},
+		// 业务拒绝不计入熔断
+		IsSuccessful: func(err error) bool {
+			return !router.IsInfrastructureError(err)
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Warn("渠道熔断器状态变化",
+				zap.String("channel", channel),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()))
+			// 状态变化立即上报，缩短路由摘流延迟
+			go m.publishChannel(context.Background(), channel)
+		},
+	})
+	m.breakers[channel] = cb
+	return cb
+}
+
+// record 记录一次渠道调用结果
+func (m *ChannelHealthMonitor) record(channel string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window, ok := m.windows[channel]
+	if !ok {
+		window = &healthWindow{}
+		m.windows[channel] = window
+	}
+	bucket := window.current(m.now(), m.config.Window)
+	bucket.requests++
+	if router.IsInfrastructureError(err) {
+		bucket.failures++
+	}
+	if router.IsTimeoutError(err) {
+		bucket.timeouts++
+	}
+}
+
+// current 返回当前分钟的桶，并清理窗口外的桶
+func (w *healthWindow) current(now time.Time, window time.Duration) *healthBucket {
+	start := now.Truncate(time.Minute)
+	cutoff := now.Add(-window)
+	kept := w.buckets[:0]
+	for _, b := range w.buckets {
+		if b.start.After(cutoff) {
+			kept = append(kept, b)
+		}
+	}
+	w.buckets = kept
+	if n := len(w.buckets); n > 0 && w.buckets[n-1].start.Equal(start) {
+		return &w.buckets[n-1]
+	}
+	w.buckets = append(w.buckets, healthBucket{start: start})
+	return &w.buckets[len(w.buckets)-1]
+}
+
+// Health 计算渠道当前健康状态
+func (m *ChannelHealthMonitor) Health(channel string) *router.ChannelHealthReport {
+	cb := m.breaker(channel)
+
+	m.mu.Lock()
+	var requests, failures, timeouts int64
+	if window, ok := m.windows[channel]; ok {
+		cutoff := m.now().Add(-m.config.Window)
+		for _, b := range window.buckets {
+			if b.start.After(cutoff) {
+				requests += b.requests
+				failures += b.failures
+				timeouts += b.timeouts
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	now := m.now()
+	report := &router.ChannelHealthReport{
+		Channel:      channel,
+		Instance:     m.instance,
+		State:        router.ChannelHealthHealthy,
+		BreakerState: cb.State().String(),
+		Requests:     requests,
+		ReportedAt:   now,
+		// 连续 3 个上报周期未更新即视为失效，避免实例下线后残留 down 状态
+		ExpiresAt: now.Add(3 * m.config.PublishInterval),
+	}
+	if requests > 0 {
+		report.ErrorRate = float64(failures) / float64(requests)
+		report.TimeoutRate = float64(timeouts) / float64(requests)
+	}
+
+	switch cb.State() {
+	case gobreaker.StateOpen:
+		report.State = router.ChannelHealthDown
+		report.Reason = "熔断器打开"
+	case gobreaker.StateHalfOpen:
+		report.State = router.ChannelHealthDown
+		report.Probing = true
+		report.Reason = "熔断器半开，探测中"
+	default:
+		if requests < m.config.MinRequests {
+			break
+		}
+		if report.ErrorRate >= m.config.DegradedErrorRate {
+			report.State = router.ChannelHealthDegraded
+			report.Reason = fmt.Sprintf("基础设施错误率 %.1f%%", report.ErrorRate*100)
+		} else if report.TimeoutRate >= m.config.DegradedTimeoutRate {
+			report.State = router.ChannelHealthDegraded
+			report.Reason = fmt.Sprintf("超时率 %.1f%%", report.TimeoutRate*100)
+		}
+	}
+	return report
+}
+
+// Snapshot 返回本实例所有渠道的健康状态
+func (m *ChannelHealthMonitor) Snapshot() []*router.ChannelHealthReport {
+	m.mu.Lock()
+	channels := make([]string, 0, len(m.breakers))
+	for channel := range m.breakers {
+		channels = append(channels, channel)
+	}
+	m.mu.Unlock()
+	sort.Strings(channels)
+
+	reports := make([]*router.ChannelHealthReport, 0, len(channels))
+	for _, channel := range channels {
+		reports = append(reports, m.Health(channel))
+	}
+	return reports
+}
+
+// StartPublishing 定期上报渠道健康状态
+func (m *ChannelHealthMonitor) StartPublishing(ctx context.Context) {
+	ticker := time.NewTicker(m.config.PublishInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, report := range m.Snapshot() {
+					if err := router.PublishChannelHealth(ctx, m.redisClient, report); err != nil {
+						logger.Warn("上报渠道健康状态失败", zap.String("channel", report.Channel), zap.Error(err))
+					}
+				}
+			case <-ctx.Done():
+				logger.Info("渠道健康状态上报任务已停止")
+				return
+			}
+		}
+	}()
+
+	logger.Info("渠道健康状态上报任务已启动",
+		zap.String("instance", m.instance),
+		zap.Duration("interval", m.config.PublishInterval))
+}
+
+func (m *ChannelHealthMonitor) publishChannel(ctx context.Context, channel string) {
+	if err := router.PublishChannelHealth(ctx, m.redisClient, m.Health(channel)); err != nil {
+		logger.Warn("上报渠道健康状态失败", zap.String("channel", channel), zap.Error(err))
+	}
+}
+
+// LoadHealth 查询全部实例合并后的渠道健康状态（包含运营覆盖）
+func (m *ChannelHealthMonitor) LoadHealth(ctx context.Context) (map[string]*router.ChannelHealth, error) {
+	return router.LoadChannelHealth(ctx, m.redisClient)
+}
+
+// SetOverride 运营手动设置渠道健康状态
+func (m *ChannelHealthMonitor) SetOverride(ctx context.Context, override *router.ChannelHealthOverride) error {
+	override.CreatedAt = m.now()
+	return router.SetChannelHealthOverride(ctx, m.redisClient, override)
+}
+
+// ClearOverride 解除渠道健康状态覆盖
+func (m *ChannelHealthMonitor) ClearOverride(ctx context.Context, channel string) error {
+	return router.ClearChannelHealthOverride(ctx, m.redisClient, channel)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-platform/pkg/router"
+)
+
+func TestChannelHealthMonitorIgnoresBusinessDeclines(t *testing.T) {
+	monitor := NewChannelHealthMonitor(nil, DefaultHealthMonitorConfig())
+	decline := errors.New("card_declined: insufficient_funds")
+
+	for i := 0; i < 30; i++ {
+		err := monitor.Execute(context.Background(), "stripe", func() error { return decline })
+		if !errors.Is(err, decline) {
+			t.Fatalf("业务拒绝应原样返回, got %v", err)
+		}
+	}
+
+	report := monitor.Health("stripe")
+	if report.State != router.ChannelHealthHealthy {
+		t.Fatalf("业务拒绝不应影响渠道健康, got %s", report.State)
+	}
+	if report.Requests != 30 || report.ErrorRate != 0 {
+		t.Fatalf("unexpected stats: requests=%d error_rate=%.2f", report.Requests, report.ErrorRate)
+	}
+}
+
+func TestChannelHealthMonitorTripsOnInfrastructureErrors(t *testing.T) {
+	config := DefaultHealthMonitorConfig()
+	config.OpenTimeout = 50 * time.Millisecond
+	monitor := NewChannelHealthMonitor(nil, config)
+
+	// 少量超时：降级但未熔断
+	for i := 0; i < 20; i++ {
+		monitor.Execute(context.Background(), "paypal", func() error {
+			if i < 2 {
+				return context.DeadlineExceeded
+			}
+			return nil
+		})
+	}
+	if report := monitor.Health("paypal"); report.State != router.ChannelHealthDegraded {
+		t.Fatalf("expected degraded, got %s (%s)", report.State, report.Reason)
+	}
+
+	// 大量 5xx：熔断器打开，请求直接被拒绝
+	for i := 0; i < 40; i++ {
+		monitor.Execute(context.Background(), "paypal", func() error { return errors.New("503 service unavailable") })
+	}
+	report := monitor.Health("paypal")
+	if report.State != router.ChannelHealthDown || report.Probing {
+		t.Fatalf("expected down, got %s probing=%v", report.State, report.Probing)
+	}
+	called := false
+	err := monitor.Execute(context.Background(), "paypal", func() error { called = true; return nil })
+	if err == nil || called {
+		t.Fatalf("熔断打开时不应调用渠道")
+	}
+
+	// 超时后进入半开，允许探测
+	time.Sleep(60 * time.Millisecond)
+	report = monitor.Health("paypal")
+	if report.State != router.ChannelHealthDown || !report.Probing {
+		t.Fatalf("expected half-open probing, got %s probing=%v", report.State, report.Probing)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/decline"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/sca"
 	"go.uber.org/zap"
 	"payment-platform/channel-adapter/internal/adapter"
 	"payment-platform/channel-adapter/internal/model"
@@ -20,6 +22,7 @@ type ChannelService interface {
 	CreatePayment(ctx context.Context, req *CreatePaymentRequest) (*CreatePaymentResponse, error)
 	QueryPayment(ctx context.Context, paymentNo string) (*QueryPaymentResponse, error)
 	CancelPayment(ctx context.Context, paymentNo string) error
+	CompleteAuthentication(ctx context.Context, paymentNo string) (*CompleteAuthenticationResponse, error)
 
 	// 退款操作
 	CreateRefund(ctx context.Context, req *CreateRefundRequest) (*CreateRefundResponse, error)
@@ -30,6 +33,8 @@ type ChannelService interface {
 	CapturePreAuth(ctx context.Context, req *CapturePreAuthRequest) (*CapturePreAuthResponse, error)
 	CancelPreAuth(ctx context.Context, req *CancelPreAuthRequest) (*CancelPreAuthResponse, error)
 	QueryPreAuth(ctx context.Context, channelPreAuthNo string) (*QueryPreAuthResponse, error)
+	IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error)
+	ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error)
 
 	// Webhook 处理
 	HandleWebhook(ctx context.Context, channel string, signature string, body []byte, headers map[string]string) error
@@ -44,6 +49,8 @@ type channelService struct {
 	repo           repository.ChannelRepository
 	preAuthRepo    repository.PreAuthRepository
 	adapterFactory *adapter.AdapterFactory
+	healthMonitor  *ChannelHealthMonitor
+	credentials    CredentialResolver // 商户渠道凭证（可选）
 }
 
 // NewChannelService 创建渠道服务实例
@@ -55,6 +62,11 @@ func NewChannelService(repo repository.ChannelRepository, preAuthRepo repository
 	}
 }
 
+// SetHealthMonitor 设置渠道健康监控（依赖注入），未设置时不经过渠道熔断器
+func (s *channelService) SetHealthMonitor(monitor *ChannelHealthMonitor) {
+	s.healthMonitor = monitor
+}
+
 // CreatePaymentRequest 创建支付请求
 type CreatePaymentRequest struct {
 	MerchantID    uuid.UUID              `json:"merchant_id"`
@@ -69,6 +81,8 @@ type CreatePaymentRequest struct {
 	SuccessURL    string                 `json:"success_url"`
 	CancelURL     string                 `json:"cancel_url"`
 	CallbackURL   string                 `json:"callback_url"`
+	PayMethod     string                 `json:"pay_method"`
+	ThreeDS       *sca.Request           `json:"three_ds,omitempty"` // 3DS 要求（由 payment-gateway 决定）
 	Extra         map[string]interface{} `json:"extra"`
 }
 
@@ -80,9 +94,21 @@ type CreatePaymentResponse struct {
 	PaymentURL     string                 `json:"payment_url,omitempty"`
 	QRCodeURL      string                 `json:"qr_code_url,omitempty"`
 	Status         string                 `json:"status"`
+	NextAction     *sca.NextAction        `json:"next_action,omitempty"`    // 需要消费者完成的 3DS 验证动作
+	Authentication *sca.Result            `json:"authentication,omitempty"` // 3DS 认证结果
 	Extra          map[string]interface{} `json:"extra"`
 }
 
+// CompleteAuthenticationResponse 3DS 验证完成后继续支付响应
+type CompleteAuthenticationResponse struct {
+	PaymentNo      string           `json:"payment_no"`
+	ChannelTradeNo string           `json:"channel_trade_no"`
+	Status         string           `json:"status"`
+	NextAction     *sca.NextAction  `json:"next_action,omitempty"`
+	Authentication *sca.Result      `json:"authentication,omitempty"`
+	Decline        *decline.Decline `json:"decline,omitempty"`
+}
+
 // QueryPaymentResponse 查询支付响应
 type QueryPaymentResponse struct {
 	PaymentNo            string                 `json:"payment_no"`
@@ -133,10 +159,10 @@ func (s *channelService) CreatePayment(ctx context.Context, req *CreatePaymentRe
 		return nil, fmt.Errorf("渠道配置不存在或未启用")
 	}
 
-	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(req.Channel)
-	if !ok {
-		return nil, fmt.Errorf("不支持的支付渠道: %s", req.Channel)
+	// 获取适配器（商户渠道凭证优先）
+	adapters, err := s.channelAdapters(ctx, req.MerchantID, req.Channel, config.Mode)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建支付请求
@@ -151,15 +177,24 @@ func (s *channelService) CreatePayment(ctx context.Context, req *CreatePaymentRe
 		SuccessURL:    req.SuccessURL,
 		CancelURL:     req.CancelURL,
 		CallbackURL:   req.CallbackURL,
+		PayMethod:     req.PayMethod,
+		ThreeDS:       req.ThreeDS,
 		Extra:         req.Extra,
 	}
 
 	// 调用适配器创建支付
-	adapterResp, err := adpt.CreatePayment(ctx, adapterReq)
+	var adapterResp *adapter.CreatePaymentResponse
+	err = s.healthMonitor.Execute(ctx, req.Channel, func() error {
+		return callAdapters(adapters, func(adpt adapter.PaymentAdapter) (callErr error) {
+			adapterResp, callErr = adpt.CreatePayment(ctx, adapterReq)
+			return callErr
+		})
+	})
 	if err != nil {
-		// 记录失败的交易
-		s.createFailedTransaction(ctx, req, "", err.Error())
-		return nil, fmt.Errorf("创建支付失败: %w", err)
+		// 归一化拒绝原因并记录失败的交易
+		d := classifyDecline(req.Channel, err)
+		s.createFailedTransaction(ctx, req, "", d, err.Error())
+		return nil, decline.Wrap(d, fmt.Errorf("创建支付失败: %w", err))
 	}
 
 	// 保存交易记录
@@ -201,12 +236,71 @@ func (s *channelService) CreatePayment(ctx context.Context, req *CreatePaymentRe
 		PaymentURL:     adapterResp.PaymentURL,
 		QRCodeURL:      adapterResp.QRCodeURL,
 		Status:         adapterResp.Status,
+		NextAction:     adapterResp.NextAction,
+		Authentication: adapterResp.Authentication,
 		Extra:          adapterResp.Extra,
 	}
 
 	return response, nil
 }
 
+// CompleteAuthentication 消费者完成 3DS 挑战后继续支付
+func (s *channelService) CompleteAuthentication(ctx context.Context, paymentNo string) (*CompleteAuthenticationResponse, error) {
+	tx, err := s.repo.GetTransaction(ctx, paymentNo)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易记录失败: %w", err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("交易记录不存在")
+	}
+
+	adapters, err := s.channelAdapters(ctx, tx.MerchantID, tx.Channel, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var adapterResp *adapter.CompleteAuthenticationResponse
+	err = s.healthMonitor.Execute(ctx, tx.Channel, func() error {
+		return callAdapters(adapters, func(adpt adapter.PaymentAdapter) (callErr error) {
+			adapterResp, callErr = adpt.CompleteAuthentication(ctx, &adapter.CompleteAuthenticationRequest{
+				PaymentNo:      paymentNo,
+				ChannelTradeNo: tx.ChannelTradeNo,
+			})
+			return callErr
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("3DS 验证后继续支付失败: %w", err)
+	}
+
+	// 更新交易记录
+	if adapterResp.Status != tx.Status {
+		tx.Status = adapterResp.Status
+		if adapterResp.Decline != nil {
+			tx.ErrorCode = adapterResp.Decline.ChannelCode
+			tx.ErrorMessage = adapterResp.Decline.ChannelMessage
+		}
+		if adapterResp.Status == adapter.PaymentStatusSuccess || adapterResp.Status == adapter.PaymentStatusFailed {
+			now := time.Now()
+			tx.ProcessedAt = &now
+		}
+		if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
+			logger.Error("failed to update transaction after 3DS authentication",
+				zap.Error(err),
+				zap.String("payment_no", paymentNo))
+		}
+	}
+
+	return &CompleteAuthenticationResponse{
+		PaymentNo:      paymentNo,
+		ChannelTradeNo: adapterResp.ChannelTradeNo,
+		Status:         adapterResp.Status,
+		NextAction:     adapterResp.NextAction,
+		Authentication: adapterResp.Authentication,
+		Decline:        adapterResp.Decline,
+	}, nil
+}
+
 // QueryPayment 查询支付状态
 func (s *channelService) QueryPayment(ctx context.Context, paymentNo string) (*QueryPaymentResponse, error) {
 	// 获取交易记录
@@ -219,13 +313,19 @@ func (s *channelService) QueryPayment(ctx context.Context, paymentNo string) (*Q
 	}
 
 	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(tx.Channel)
-	if !ok {
-		return nil, fmt.Errorf("不支持的支付渠道: %s", tx.Channel)
+	adapters, err := s.channelAdapters(ctx, tx.MerchantID, tx.Channel, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// 查询支付状态
-	adapterResp, err := adpt.QueryPayment(ctx, tx.ChannelTradeNo)
+	var adapterResp *adapter.QueryPaymentResponse
+	err = s.healthMonitor.Execute(ctx, tx.Channel, func() error {
+		return callAdapters(adapters, func(adpt adapter.PaymentAdapter) (callErr error) {
+			adapterResp, callErr = adpt.QueryPayment(ctx, tx.ChannelTradeNo)
+			return callErr
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("查询支付状态失败: %w", err)
 	}
@@ -273,13 +373,15 @@ func (s *channelService) CancelPayment(ctx context.Context, paymentNo string) er
 	}
 
 	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(tx.Channel)
-	if !ok {
-		return fmt.Errorf("不支持的支付渠道: %s", tx.Channel)
+	adapters, err := s.channelAdapters(ctx, tx.MerchantID, tx.Channel, "")
+	if err != nil {
+		return err
 	}
 
 	// 取消支付
-	if err := adpt.CancelPayment(ctx, tx.ChannelTradeNo); err != nil {
+	if err := callAdapters(adapters, func(adpt adapter.PaymentAdapter) error {
+		return adpt.CancelPayment(ctx, tx.ChannelTradeNo)
+	}); err != nil {
 		return fmt.Errorf("取消支付失败: %w", err)
 	}
 
@@ -299,10 +401,10 @@ func (s *channelService) CreateRefund(ctx context.Context, req *CreateRefundRequ
 		return nil, fmt.Errorf("原交易记录不存在")
 	}
 
-	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(tx.Channel)
-	if !ok {
-		return nil, fmt.Errorf("不支持的支付渠道: %s", tx.Channel)
+	// 获取适配器（使用原交易商户的渠道凭证）
+	adapters, err := s.channelAdapters(ctx, tx.MerchantID, tx.Channel, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建退款请求
@@ -316,7 +418,13 @@ func (s *channelService) CreateRefund(ctx context.Context, req *CreateRefundRequ
 	}
 
 	// 调用适配器创建退款
-	adapterResp, err := adpt.CreateRefund(ctx, adapterReq)
+	var adapterResp *adapter.CreateRefundResponse
+	err = s.healthMonitor.Execute(ctx, tx.Channel, func() error {
+		return callAdapters(adapters, func(adpt adapter.PaymentAdapter) (callErr error) {
+			adapterResp, callErr = adpt.CreateRefund(ctx, adapterReq)
+			return callErr
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("创建退款失败: %w", err)
 	}
@@ -364,13 +472,17 @@ func (s *channelService) QueryRefund(ctx context.Context, refundNo string) (*Que
 	}
 
 	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(tx.Channel)
-	if !ok {
-		return nil, fmt.Errorf("不支持的支付渠道: %s", tx.Channel)
+	adapters, err := s.channelAdapters(ctx, tx.MerchantID, tx.Channel, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// 查询退款状态
-	adapterResp, err := adpt.QueryRefund(ctx, tx.ChannelTradeNo)
+	var adapterResp *adapter.QueryRefundResponse
+	err = callAdapters(adapters, func(adpt adapter.PaymentAdapter) (callErr error) {
+		adapterResp, callErr = adpt.QueryRefund(ctx, tx.ChannelTradeNo)
+		return callErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("查询退款状态失败: %w", err)
 	}
@@ -396,16 +508,10 @@ func (s *channelService) QueryRefund(ctx context.Context, refundNo string) (*Que
 
 // HandleWebhook 处理 Webhook 回调
 func (s *channelService) HandleWebhook(ctx context.Context, channel string, signature string, body []byte, headers map[string]string) error {
-	// 获取适配器
-	adpt, ok := s.adapterFactory.GetAdapter(channel)
-	if !ok {
-		return fmt.Errorf("不支持的支付渠道: %s", channel)
-	}
-
-	// 验证签名
-	verified, err := adpt.VerifyWebhook(ctx, signature, body)
-	if err != nil || !verified {
-		return fmt.Errorf("Webhook 签名验证失败: %w", err)
+	// 验证签名（平台配置或商户使用中的任一凭证版本）
+	adpt, err := s.verifyWebhook(ctx, channel, signature, body)
+	if err != nil {
+		return err
 	}
 
 	// 解析 Webhook 数据
@@ -471,6 +577,10 @@ func (s *channelService) processWebhookEvent(ctx context.Context, event *adapter
 
 	// 更新交易状态
 	tx.Status = event.Status
+	if event.Decline != nil {
+		tx.ErrorCode = event.Decline.ChannelCode
+		tx.ErrorMessage = event.Decline.ChannelMessage
+	}
 	webhookData, _ := json.Marshal(event)
 	tx.WebhookData = string(webhookData)
 	now := time.Now()
@@ -522,8 +632,16 @@ func (s *channelService) ListChannelConfigs(ctx context.Context, merchantID uuid
 	return s.repo.ListConfigs(ctx, merchantID)
 }
 
+// classifyDecline 取出适配器按渠道错误码归一化的拒绝原因，适配器未识别时（网络错误、熔断等）按错误信息兜底
+func classifyDecline(channel string, err error) *decline.Decline {
+	if d, ok := decline.FromError(err); ok {
+		return d
+	}
+	return decline.ClassifyMessage(channel, err.Error())
+}
+
 // createFailedTransaction 创建失败的交易记录
-func (s *channelService) createFailedTransaction(ctx context.Context, req *CreatePaymentRequest, channelTradeNo, errorMsg string) {
+func (s *channelService) createFailedTransaction(ctx context.Context, req *CreatePaymentRequest, channelTradeNo string, d *decline.Decline, errorMsg string) {
 	tx := &model.Transaction{
 		MerchantID:     req.MerchantID,
 		OrderNo:        req.OrderNo,
@@ -536,6 +654,7 @@ func (s *channelService) createFailedTransaction(ctx context.Context, req *Creat
 		Status:         model.TransactionStatusFailed,
 		CustomerEmail:  req.CustomerEmail,
 		CustomerName:   req.CustomerName,
+		ErrorCode:      d.ChannelCode,
 		ErrorMessage:   errorMsg,
 	}
 	s.repo.CreateTransaction(ctx, tx)
@@ -571,6 +690,7 @@ type CapturePreAuthRequest struct {
 	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"`
 	Amount           int64                  `json:"amount"`
 	Currency         string                 `json:"currency"`
+	FinalCapture     *bool                  `json:"final_capture"`
 	Description      string                 `json:"description"`
 	Extra            map[string]interface{} `json:"extra"`
 }
@@ -581,6 +701,8 @@ type CapturePreAuthResponse struct {
 	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"`
 	Status           string                 `json:"status"`
 	Amount           int64                  `json:"amount"`
+	CapturedAmount   int64                  `json:"captured_amount"`
+	CapturableAmount int64                  `json:"capturable_amount"`
 	Extra            map[string]interface{} `json:"extra"`
 }
 
@@ -611,6 +733,46 @@ type QueryPreAuthResponse struct {
 	Extra            map[string]interface{} `json:"extra"`
 }
 
+type IncrementPreAuthRequest struct {
+	Channel          string                 `json:"channel"`
+	PreAuthNo        string                 `json:"pre_auth_no"`
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"`
+	Amount           int64                  `json:"amount"` // 增量后的授权总金额
+	Currency         string                 `json:"currency"`
+	Description      string                 `json:"description"`
+	Extra            map[string]interface{} `json:"extra"`
+}
+
+type IncrementPreAuthResponse struct {
+	PreAuthNo        string                 `json:"pre_auth_no"`
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"`
+	Status           string                 `json:"status"`
+	Amount           int64                  `json:"amount"`
+	CapturableAmount int64                  `json:"capturable_amount"`
+	Extra            map[string]interface{} `json:"extra"`
+}
+
+type ReauthorizePreAuthRequest struct {
+	Channel          string                 `json:"channel"`
+	PreAuthNo        string                 `json:"pre_auth_no"`
+	OrderNo          string                 `json:"order_no"`
+	ChannelPreAuthNo string                 `json:"channel_pre_auth_no"`
+	Amount           int64                  `json:"amount"` // 重新冻结的金额
+	Currency         string                 `json:"currency"`
+	Description      string                 `json:"description"`
+	Extra            map[string]interface{} `json:"extra"`
+}
+
+type ReauthorizePreAuthResponse struct {
+	PreAuthNo                string                 `json:"pre_auth_no"`
+	ChannelPreAuthNo         string                 `json:"channel_pre_auth_no"`
+	PreviousChannelPreAuthNo string                 `json:"previous_channel_pre_auth_no"`
+	Status                   string                 `json:"status"`
+	Amount                   int64                  `json:"amount"`
+	ExpiresAt                *int64                 `json:"expires_at"`
+	Extra                    map[string]interface{} `json:"extra"`
+}
+
 // CreatePreAuth 创建预授权
 func (s *channelService) CreatePreAuth(ctx context.Context, req *CreatePreAuthRequest) (*CreatePreAuthResponse, error) {
 	// 1. 获取适配器
@@ -661,6 +823,7 @@ func (s *channelService) CreatePreAuth(ctx context.Context, req *CreatePreAuthRe
 		ChannelPreAuthNo: adapterResp.ChannelPreAuthNo,
 		Amount:           req.Amount,
 		Currency:         req.Currency,
+		ISOMinorUnits:    true,
 		Status:           adapterResp.Status,
 		CapturedAmount:   0,
 		ExpiresAt:        expiresAt,
@@ -693,12 +856,17 @@ func (s *channelService) CapturePreAuth(ctx context.Context, req *CapturePreAuth
 		return nil, fmt.Errorf("不支持的支付渠道: %s", req.Channel)
 	}
 
-	// 2. 调用适配器确认预授权
+	// 2. 调用适配器确认预授权（迁移前创建的预授权按旧约定换算金额）
+	legacy, err := s.legacyPreAuth(ctx, req.Channel, req.ChannelPreAuthNo)
+	if err != nil {
+		return nil, err
+	}
 	adapterReq := &adapter.CapturePreAuthRequest{
 		PreAuthNo:        req.PreAuthNo,
 		ChannelPreAuthNo: req.ChannelPreAuthNo,
-		Amount:           req.Amount,
+		Amount:           preAuthAmountToISO(legacy, req.Amount, req.Currency),
 		Currency:         req.Currency,
+		FinalCapture:     req.FinalCapture,
 		Description:      req.Description,
 		Extra:            req.Extra,
 	}
@@ -711,14 +879,25 @@ func (s *channelService) CapturePreAuth(ctx context.Context, req *CapturePreAuth
 			zap.Error(err))
 		return nil, fmt.Errorf("确认预授权失败: %w", err)
 	}
+	adapterResp.Amount = preAuthAmountFromISO(legacy, adapterResp.Amount, req.Currency)
+	adapterResp.CapturedAmount = preAuthAmountFromISO(legacy, adapterResp.CapturedAmount, req.Currency)
+	adapterResp.CapturableAmount = preAuthAmountFromISO(legacy, adapterResp.CapturableAmount, req.Currency)
 
-	// 3. 返回响应
+	// 3. 同步预授权记录的已确认金额
+	s.updatePreAuthRecord(ctx, req.ChannelPreAuthNo, func(record *model.PreAuthRecord) {
+		record.CapturedAmount = adapterResp.CapturedAmount
+		record.Status = adapterResp.Status
+	})
+
+	// 4. 返回响应
 	return &CapturePreAuthResponse{
 		PreAuthNo:        req.PreAuthNo,
 		ChannelTradeNo:   adapterResp.ChannelTradeNo,
 		ChannelPreAuthNo: adapterResp.ChannelPreAuthNo,
 		Status:           adapterResp.Status,
 		Amount:           adapterResp.Amount,
+		CapturedAmount:   adapterResp.CapturedAmount,
+		CapturableAmount: adapterResp.CapturableAmount,
 		Extra:            adapterResp.Extra,
 	}, nil
 }
@@ -757,6 +936,127 @@ func (s *channelService) CancelPreAuth(ctx context.Context, req *CancelPreAuthRe
 	}, nil
 }
 
+// IncrementPreAuth 增量授权（提高冻结金额）
+func (s *channelService) IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error) {
+	// 1. 获取适配器
+	adapterInstance, ok := s.adapterFactory.GetAdapter(req.Channel)
+	if !ok {
+		return nil, fmt.Errorf("不支持的支付渠道: %s", req.Channel)
+	}
+
+	// 2. 调用适配器增量授权（迁移前创建的预授权按旧约定换算金额）
+	legacy, err := s.legacyPreAuth(ctx, req.Channel, req.ChannelPreAuthNo)
+	if err != nil {
+		return nil, err
+	}
+	adapterResp, err := adapterInstance.IncrementPreAuth(ctx, &adapter.IncrementPreAuthRequest{
+		PreAuthNo:        req.PreAuthNo,
+		ChannelPreAuthNo: req.ChannelPreAuthNo,
+		Amount:           preAuthAmountToISO(legacy, req.Amount, req.Currency),
+		Currency:         req.Currency,
+		Description:      req.Description,
+		Extra:            req.Extra,
+	})
+	if err != nil {
+		logger.Error("增量授权失败",
+			zap.String("channel", req.Channel),
+			zap.String("channel_pre_auth_no", req.ChannelPreAuthNo),
+			zap.Int64("amount", req.Amount),
+			zap.Error(err))
+		return nil, fmt.Errorf("增量授权失败: %w", err)
+	}
+	adapterResp.Amount = preAuthAmountFromISO(legacy, adapterResp.Amount, req.Currency)
+	adapterResp.CapturableAmount = preAuthAmountFromISO(legacy, adapterResp.CapturableAmount, req.Currency)
+
+	// 3. 同步预授权记录的授权金额
+	s.updatePreAuthRecord(ctx, req.ChannelPreAuthNo, func(record *model.PreAuthRecord) {
+		record.Amount = adapterResp.Amount
+		record.Status = adapterResp.Status
+	})
+
+	// 4. 返回响应
+	return &IncrementPreAuthResponse{
+		PreAuthNo:        req.PreAuthNo,
+		ChannelPreAuthNo: adapterResp.ChannelPreAuthNo,
+		Status:           adapterResp.Status,
+		Amount:           adapterResp.Amount,
+		CapturableAmount: adapterResp.CapturableAmount,
+		Extra:            adapterResp.Extra,
+	}, nil
+}
+
+// ReauthorizePreAuth 重新授权（授权到期前重新冻结剩余金额）
+func (s *channelService) ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error) {
+	// 1. 获取适配器
+	adapterInstance, ok := s.adapterFactory.GetAdapter(req.Channel)
+	if !ok {
+		return nil, fmt.Errorf("不支持的支付渠道: %s", req.Channel)
+	}
+
+	// 2. 调用适配器重新授权（迁移前创建的预授权按旧约定换算金额）
+	legacy, err := s.legacyPreAuth(ctx, req.Channel, req.ChannelPreAuthNo)
+	if err != nil {
+		return nil, err
+	}
+	adapterResp, err := adapterInstance.ReauthorizePreAuth(ctx, &adapter.ReauthorizePreAuthRequest{
+		PreAuthNo:        req.PreAuthNo,
+		OrderNo:          req.OrderNo,
+		ChannelPreAuthNo: req.ChannelPreAuthNo,
+		Amount:           preAuthAmountToISO(legacy, req.Amount, req.Currency),
+		Currency:         req.Currency,
+		Description:      req.Description,
+		Extra:            req.Extra,
+	})
+	if err != nil {
+		logger.Error("重新授权失败",
+			zap.String("channel", req.Channel),
+			zap.String("channel_pre_auth_no", req.ChannelPreAuthNo),
+			zap.Error(err))
+		return nil, fmt.Errorf("重新授权失败: %w", err)
+	}
+	adapterResp.Amount = preAuthAmountFromISO(legacy, adapterResp.Amount, req.Currency)
+
+	// 3. 预授权记录切换到新的渠道预授权号
+	s.updatePreAuthRecord(ctx, req.ChannelPreAuthNo, func(record *model.PreAuthRecord) {
+		record.ChannelPreAuthNo = adapterResp.ChannelPreAuthNo
+		record.Amount = record.CapturedAmount + adapterResp.Amount
+		record.Status = adapterResp.Status
+		if adapterResp.ExpiresAt != nil {
+			t := time.Unix(*adapterResp.ExpiresAt, 0)
+			record.ExpiresAt = &t
+		}
+	})
+
+	// 4. 返回响应
+	return &ReauthorizePreAuthResponse{
+		PreAuthNo:                req.PreAuthNo,
+		ChannelPreAuthNo:         adapterResp.ChannelPreAuthNo,
+		PreviousChannelPreAuthNo: adapterResp.PreviousChannelPreAuthNo,
+		Status:                   adapterResp.Status,
+		Amount:                   adapterResp.Amount,
+		ExpiresAt:                adapterResp.ExpiresAt,
+		Extra:                    adapterResp.Extra,
+	}, nil
+}
+
+// updatePreAuthRecord 更新本地预授权记录（记录仅用于对账，更新失败不影响渠道操作结果）
+func (s *channelService) updatePreAuthRecord(ctx context.Context, channelPreAuthNo string, apply func(record *model.PreAuthRecord)) {
+	record, err := s.preAuthRepo.GetByChannelPreAuthNo(ctx, channelPreAuthNo)
+	if err != nil {
+		logger.Warn("预授权记录不存在，跳过更新",
+			zap.String("channel_pre_auth_no", channelPreAuthNo),
+			zap.Error(err))
+		return
+	}
+
+	apply(record)
+	if err := s.preAuthRepo.Update(ctx, record); err != nil {
+		logger.Error("更新预授权记录失败",
+			zap.String("channel_pre_auth_no", channelPreAuthNo),
+			zap.Error(err))
+	}
+}
+
 // QueryPreAuth 查询预授权状态
 func (s *channelService) QueryPreAuth(ctx context.Context, channelPreAuthNo string) (*QueryPreAuthResponse, error) {
 	// 1. 从数据库获取预授权记录以确定使用哪个渠道
@@ -780,7 +1080,11 @@ func (s *channelService) QueryPreAuthWithChannel(ctx context.Context, channel st
 		return nil, fmt.Errorf("不支持的支付渠道: %s", channel)
 	}
 
-	// 2. 调用适配器查询预授权
+	// 2. 调用适配器查询预授权（迁移前创建的预授权按旧约定换算金额）
+	legacy, err := s.legacyPreAuth(ctx, channel, channelPreAuthNo)
+	if err != nil {
+		return nil, err
+	}
 	adapterResp, err := adapterInstance.QueryPreAuth(ctx, channelPreAuthNo)
 	if err != nil {
 		logger.Error("查询预授权失败",
@@ -789,6 +1093,8 @@ func (s *channelService) QueryPreAuthWithChannel(ctx context.Context, channel st
 			zap.Error(err))
 		return nil, fmt.Errorf("查询预授权失败: %w", err)
 	}
+	adapterResp.Amount = preAuthAmountFromISO(legacy, adapterResp.Amount, adapterResp.Currency)
+	adapterResp.CapturedAmount = preAuthAmountFromISO(legacy, adapterResp.CapturedAmount, adapterResp.Currency)
 
 	// 3. 返回响应
 	return &QueryPreAuthResponse{
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+// plaintextMigrationBatch 每批迁移的渠道配置数量
+const plaintextMigrationBatch = 500
+
+// MigrationResult 明文凭证迁移结果
+type MigrationResult struct {
+	Migrated int `json:"migrated"` // 导入、校验并激活后清空明文
+	Cleared  int `json:"cleared"`  // 已有相同凭证的使用中版本，直接清空明文
+	Skipped  int `json:"skipped"`  // 导入或校验失败、或商户已有其他使用中的凭证，保留明文待人工处理
+}
+
+// MigratePlaintextConfigs 将渠道配置中的明文凭证导入为加密凭证版本
+//
+// 每个配置依次创建、校验、激活凭证版本，确认存在相同指纹的使用中版本后才清空明文，
+// 任一步失败时保留明文并记录日志，重复执行时复用已导入的版本。
+// 商户已有其他使用中的凭证时不导入，避免旧明文覆盖商户新提交的凭证。
+// 会向渠道发起校验调用，由 cmd/migrate-credentials 在单个实例上执行，不在服务启动时运行。
+func MigratePlaintextConfigs(ctx context.Context, channelRepo repository.ChannelRepository, credentials CredentialService) (*MigrationResult, error) {
+	result := &MigrationResult{}
+	afterID := uuid.Nil
+	for {
+		configs, err := channelRepo.ListPlaintextConfigs(ctx, afterID, plaintextMigrationBatch)
+		if err != nil {
+			return result, fmt.Errorf("查询明文渠道配置失败: %w", err)
+		}
+		if len(configs) == 0 {
+			return result, nil
+		}
+
+		for _, config := range configs {
+			afterID = config.ID
+			cleared, err := migratePlaintextConfig(ctx, config, credentials)
+			if err != nil {
+				result.Skipped++
+				logger.Warn("明文渠道配置迁移失败，保留明文",
+					zap.String("config_id", config.ID.String()),
+					zap.String("merchant_id", config.MerchantID.String()),
+					zap.String("channel", config.Channel),
+					zap.String("mode", config.Mode),
+					zap.Error(err))
+				continue
+			}
+
+			config.Config = "{}"
+			if err := channelRepo.UpdateConfig(ctx, config); err != nil {
+				return result, fmt.Errorf("清空明文渠道配置失败: %w", err)
+			}
+			if cleared {
+				result.Cleared++
+			} else {
+				result.Migrated++
+			}
+		}
+	}
+}
+
+// migratePlaintextConfig 确保配置中的明文凭证存在使用中的加密版本，
+// 返回 true 表示该凭证此前已激活（本次只需清空明文）
+func migratePlaintextConfig(ctx context.Context, config *model.ChannelConfig, credentials CredentialService) (bool, error) {
+	_, _, fingerprint, err := normalizeCredential(json.RawMessage(config.Config))
+	if err != nil {
+		return false, err
+	}
+
+	versions, err := credentials.List(ctx, config.MerchantID, config.Channel)
+	if err != nil {
+		return false, fmt.Errorf("查询凭证版本失败: %w", err)
+	}
+
+	var pending *model.ChannelCredential
+	for _, v := range versions {
+		if v.Mode != config.Mode {
+			continue
+		}
+		switch v.Status {
+		case model.CredentialStatusActive:
+			if v.Fingerprint == fingerprint {
+				return true, nil
+			}
+			return false, fmt.Errorf("商户已有使用中的凭证 v%d，明文配置需人工确认", v.Version)
+		case model.CredentialStatusPending:
+			if v.Fingerprint == fingerprint {
+				pending = v
+			}
+		}
+	}
+
+	// 上次迁移中断时复用已导入的版本
+	cred := pending
+	if cred == nil {
+		if cred, err = credentials.Create(ctx, &CreateCredentialInput{
+			MerchantID:  config.MerchantID,
+			Channel:     config.Channel,
+			Mode:        config.Mode,
+			Credentials: json.RawMessage(config.Config),
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	if cred.ValidatedAt == nil {
+		if cred, err = credentials.Validate(ctx, cred.ID, nil); err != nil {
+			return false, err
+		}
+		if cred.ValidatedAt == nil {
+			return false, fmt.Errorf("凭证 v%d 未通过渠道校验: %s", cred.Version, cred.ValidationError)
+		}
+	}
+
+	if cred, err = credentials.Activate(ctx, cred.ID, nil); err != nil {
+		return false, err
+	}
+
+	logger.Info("明文渠道配置已迁移为使用中的加密凭证",
+		zap.String("merchant_id", config.MerchantID.String()),
+		zap.String("channel", config.Channel),
+		zap.String("mode", config.Mode),
+		zap.Int("version", cred.Version))
+	return false, nil
+}
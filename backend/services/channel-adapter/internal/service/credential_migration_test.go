@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+// memoryChannelRepo 内存渠道配置仓储（只实现明文迁移用到的方法）
+type memoryChannelRepo struct {
+	repository.ChannelRepository
+	configs []*model.ChannelConfig
+}
+
+func (r *memoryChannelRepo) ListPlaintextConfigs(_ context.Context, afterID uuid.UUID, limit int) ([]*model.ChannelConfig, error) {
+	sorted := append([]*model.ChannelConfig(nil), r.configs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID.String() < sorted[j].ID.String() })
+
+	var configs []*model.ChannelConfig
+	for _, c := range sorted {
+		if c.Config != "{}" && c.ID.String() > afterID.String() && len(configs) < limit {
+			copied := *c
+			configs = append(configs, &copied)
+		}
+	}
+	return configs, nil
+}
+
+func (r *memoryChannelRepo) UpdateConfig(_ context.Context, config *model.ChannelConfig) error {
+	for i, c := range r.configs {
+		if c.ID == config.ID {
+			copied := *config
+			r.configs[i] = &copied
+		}
+	}
+	return nil
+}
+
+func TestMigratePlaintextConfigs(t *testing.T) {
+	ctx := context.Background()
+	svc, credRepo := newTestCredentialService(t)
+
+	good, bad, existing := uuid.New(), uuid.New(), uuid.New()
+	plaintext := func(merchantID uuid.UUID, apiKey string) *model.ChannelConfig {
+		return &model.ChannelConfig{
+			ID: uuid.New(), MerchantID: merchantID, Channel: model.ChannelStripe, Mode: model.ModeTest,
+			Config: `{"api_key":"` + apiKey + `","webhook_secret":"whsec_abc"}`,
+		}
+	}
+	channelRepo := &memoryChannelRepo{configs: []*model.ChannelConfig{
+		plaintext(good, "sk_test_good"),
+		plaintext(bad, "sk_test_bad"),
+		plaintext(existing, "sk_test_old"),
+	}}
+
+	// 商户已通过接口提交并激活了新凭证
+	cred := createTestCredential(t, svc, existing, "sk_test_new")
+	if _, err := svc.Validate(ctx, cred.ID, nil); err != nil {
+		t.Fatalf("校验凭证失败: %v", err)
+	}
+	if _, err := svc.Activate(ctx, cred.ID, nil); err != nil {
+		t.Fatalf("激活凭证失败: %v", err)
+	}
+
+	result, err := MigratePlaintextConfigs(ctx, channelRepo, svc)
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if result.Migrated != 1 || result.Cleared != 0 || result.Skipped != 2 {
+		t.Fatalf("迁移结果 = %+v，期望迁移 1 个、跳过 2 个", result)
+	}
+
+	// 校验通过的凭证激活后才清空明文，商户继续使用自己的渠道账号
+	if channelRepo.configs[0].Config != "{}" {
+		t.Fatal("迁移成功的明文配置应被清空")
+	}
+	if secrets, err := svc.ResolveCredentials(ctx, good, model.ChannelStripe, model.ModeTest); err != nil || len(secrets) != 1 {
+		t.Fatalf("迁移后应有 1 个使用中的凭证: %v, %v", secrets, err)
+	}
+
+	// 校验失败和已有其他使用中凭证的配置保留明文
+	if channelRepo.configs[1].Config == "{}" || channelRepo.configs[2].Config == "{}" {
+		t.Fatal("未激活的明文配置不应被清空")
+	}
+	if secrets, _ := svc.ResolveCredentials(ctx, existing, model.ChannelStripe, model.ModeTest); len(secrets) != 1 || string(secrets[0]) != `{"api_key":"sk_test_new","webhook_secret":"whsec_abc"}` {
+		t.Fatalf("旧明文不应覆盖商户新提交的凭证: %s", secrets)
+	}
+
+	// 修正渠道侧问题后重复执行：复用已导入的 pending 版本，不重复创建
+	svc.validator = func(context.Context, string, string, []byte) error { return nil }
+	versionsBefore := len(credRepo.creds)
+	result, err = MigratePlaintextConfigs(ctx, channelRepo, svc)
+	if err != nil {
+		t.Fatalf("重复迁移失败: %v", err)
+	}
+	if result.Migrated != 1 || result.Skipped != 1 || len(credRepo.creds) != versionsBefore {
+		t.Fatalf("重复迁移结果 = %+v，凭证版本 %d -> %d", result, versionsBefore, len(credRepo.creds))
+	}
+	if channelRepo.configs[1].Config != "{}" {
+		t.Fatal("重新校验通过后应清空明文")
+	}
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/crypto"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/adapter"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+// 渠道凭证相关错误
+var (
+	ErrCredentialNotFound      = errors.New("凭证不存在")
+	ErrCredentialNotValidated  = errors.New("凭证未通过渠道校验，不能激活")
+	ErrCredentialStatus        = errors.New("凭证当前状态不允许该操作")
+	ErrCredentialLastActive    = errors.New("不能停用唯一使用中的凭证版本，请先激活新版本")
+	ErrNoActiveCredential      = errors.New("没有使用中的凭证")
+	ErrCredentialInvalidFormat = errors.New("凭证格式错误")
+)
+
+// rewrapBatchSize 每次重新加密的凭证数量
+const rewrapBatchSize = 100
+
+// CredentialValidator 使用明文凭证向渠道发起校验调用（默认 adapter.ValidateCredentials）
+type CredentialValidator func(ctx context.Context, channel, mode string, secret []byte) error
+
+// CredentialService 渠道凭证管理服务接口
+type CredentialService interface {
+	// 凭证版本生命周期：create -> validate -> activate -> retire/revoke
+	Create(ctx context.Context, input *CreateCredentialInput) (*model.ChannelCredential, error)
+	Validate(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error)
+	Activate(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error)
+	Retire(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error)
+	Revoke(ctx context.Context, id uuid.UUID, reason string, op *CredentialOperator) (*model.ChannelCredential, error)
+	List(ctx context.Context, merchantID uuid.UUID, channel string) ([]*model.ChannelCredential, error)
+	ListAudits(ctx context.Context, merchantID uuid.UUID, channel string, limit int) ([]*model.ChannelCredentialAudit, error)
+
+	// Rewrap KEK 轮换后用当前 KEK 重新加密一批凭证的数据密钥，返回处理数量
+	Rewrap(ctx context.Context, op *CredentialOperator) (int, error)
+
+	// ResolveCredentials 解密使用中的凭证，按版本从新到旧排列：
+	// 调用渠道使用第一个，验证 Webhook 时依次尝试全部版本
+	ResolveCredentials(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]json.RawMessage, error)
+}
+
+type credentialService struct {
+	repo      repository.CredentialRepository
+	keyring   *crypto.Keyring
+	validator CredentialValidator
+	now       func() time.Time
+}
+
+// NewCredentialService 创建渠道凭证管理服务
+func NewCredentialService(repo repository.CredentialRepository, keyring *crypto.Keyring) CredentialService {
+	return &credentialService{
+		repo:      repo,
+		keyring:   keyring,
+		validator: adapter.ValidateCredentials,
+		now:       time.Now,
+	}
+}
+
+// CredentialOperator 操作人信息（写入审计日志，由 handler 从认证身份填充，不接受请求体传入）
+type CredentialOperator struct {
+	OperatorID uuid.UUID `json:"-"`
+	ClientIP   string    `json:"-"`
+}
+
+// CreateCredentialInput 提交凭证输入
+type CreateCredentialInput struct {
+	CredentialOperator
+	MerchantID  uuid.UUID       `json:"merchant_id" binding:"required"`
+	Channel     string          `json:"channel" binding:"required"`
+	Mode        string          `json:"mode" binding:"required,oneof=test live"`
+	Credentials json.RawMessage `json:"credentials" binding:"required"` // 渠道配置 JSON，如 StripeConfig
+}
+
+// Create 加密保存新版本凭证（pending 状态，需校验并激活后才会使用）
+func (s *credentialService) Create(ctx context.Context, input *CreateCredentialInput) (*model.ChannelCredential, error) {
+	switch input.Channel {
+	case model.ChannelStripe, model.ChannelPayPal, model.ChannelAlipay, model.ChannelCrypto:
+	default:
+		return nil, fmt.Errorf("不支持的支付渠道: %s", input.Channel)
+	}
+
+	secret, fields, fingerprint, err := normalizeCredential(input.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := s.keyring.Seal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("加密凭证失败: %w", err)
+	}
+
+	cred := &model.ChannelCredential{
+		MerchantID:  input.MerchantID,
+		Channel:     input.Channel,
+		Mode:        input.Mode,
+		Status:      model.CredentialStatusPending,
+		Fingerprint: fingerprint,
+		Hint:        credentialHint(fields),
+		CreatedBy:   input.OperatorID,
+	}
+	cred.SetEnvelope(env)
+
+	audit := s.newAudit(cred, model.CredentialActionCreate, model.CredentialAuditSuccess, "", &input.CredentialOperator)
+	if err := s.repo.CreateVersion(ctx, cred, audit); err != nil {
+		return nil, fmt.Errorf("保存凭证失败: %w", err)
+	}
+
+	logger.Info("渠道凭证已提交",
+		zap.String("merchant_id", cred.MerchantID.String()),
+		zap.String("channel", cred.Channel),
+		zap.String("mode", cred.Mode),
+		zap.Int("version", cred.Version),
+		zap.String("key_id", cred.KeyID))
+	return cred, nil
+}
+
+// Validate 解密凭证并向渠道发起校验调用，结果记录在凭证和审计日志上
+func (s *credentialService) Validate(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error) {
+	cred, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Status == model.CredentialStatusRetired || cred.Status == model.CredentialStatusRevoked {
+		return nil, ErrCredentialStatus
+	}
+
+	secret, err := s.keyring.Open(cred.Envelope())
+	if err != nil {
+		return nil, fmt.Errorf("解密凭证失败: %w", err)
+	}
+
+	var audit *model.ChannelCredentialAudit
+	if verr := s.validator(ctx, cred.Channel, cred.Mode, secret); verr != nil {
+		cred.ValidatedAt = nil
+		cred.ValidationError = verr.Error()
+		audit = s.newAudit(cred, model.CredentialActionValidate, model.CredentialAuditFailed, verr.Error(), op)
+	} else {
+		now := s.now()
+		cred.ValidatedAt = &now
+		cred.ValidationError = ""
+		audit = s.newAudit(cred, model.CredentialActionValidate, model.CredentialAuditSuccess, "", op)
+	}
+	if err := s.repo.Save(ctx, cred, audit); err != nil {
+		return nil, fmt.Errorf("保存校验结果失败: %w", err)
+	}
+	return cred, nil
+}
+
+// Activate 激活已通过校验的凭证，旧版本保持 active 直到显式停用
+func (s *credentialService) Activate(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error) {
+	cred, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Status != model.CredentialStatusPending {
+		return nil, ErrCredentialStatus
+	}
+	if cred.ValidatedAt == nil {
+		_ = s.repo.CreateAudit(ctx, s.newAudit(cred, model.CredentialActionActivate, model.CredentialAuditFailed, ErrCredentialNotValidated.Error(), op))
+		return nil, ErrCredentialNotValidated
+	}
+
+	now := s.now()
+	cred.Status = model.CredentialStatusActive
+	cred.ActivatedAt = &now
+	audit := s.newAudit(cred, model.CredentialActionActivate, model.CredentialAuditSuccess, "", op)
+	if err := s.repo.Save(ctx, cred, audit); err != nil {
+		return nil, fmt.Errorf("激活凭证失败: %w", err)
+	}
+
+	logger.Info("渠道凭证已激活",
+		zap.String("merchant_id", cred.MerchantID.String()),
+		zap.String("channel", cred.Channel),
+		zap.String("mode", cred.Mode),
+		zap.Int("version", cred.Version))
+	return cred, nil
+}
+
+// Retire 轮换完成后停用旧版本，至少保留一个使用中的版本
+func (s *credentialService) Retire(ctx context.Context, id uuid.UUID, op *CredentialOperator) (*model.ChannelCredential, error) {
+	cred, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Status != model.CredentialStatusActive {
+		return nil, ErrCredentialStatus
+	}
+	active, err := s.repo.ListActive(ctx, cred.MerchantID, cred.Channel, cred.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) <= 1 {
+		return nil, ErrCredentialLastActive
+	}
+
+	now := s.now()
+	cred.Status = model.CredentialStatusRetired
+	cred.RetiredAt = &now
+	audit := s.newAudit(cred, model.CredentialActionRetire, model.CredentialAuditSuccess, "", op)
+	if err := s.repo.Save(ctx, cred, audit); err != nil {
+		return nil, fmt.Errorf("停用凭证失败: %w", err)
+	}
+	return cred, nil
+}
+
+// Revoke 立即吊销凭证（如泄露），不检查是否为唯一使用中的版本
+func (s *credentialService) Revoke(ctx context.Context, id uuid.UUID, reason string, op *CredentialOperator) (*model.ChannelCredential, error) {
+	cred, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Status == model.CredentialStatusRevoked {
+		return nil, ErrCredentialStatus
+	}
+
+	now := s.now()
+	cred.Status = model.CredentialStatusRevoked
+	cred.RetiredAt = &now
+	audit := s.newAudit(cred, model.CredentialActionRevoke, model.CredentialAuditSuccess, reason, op)
+	if err := s.repo.Save(ctx, cred, audit); err != nil {
+		return nil, fmt.Errorf("吊销凭证失败: %w", err)
+	}
+
+	logger.Warn("渠道凭证已吊销",
+		zap.String("merchant_id", cred.MerchantID.String()),
+		zap.String("channel", cred.Channel),
+		zap.String("mode", cred.Mode),
+		zap.Int("version", cred.Version),
+		zap.String("reason", reason))
+	return cred, nil
+}
+
+// List 查询商户的凭证版本（不含密文）
+func (s *credentialService) List(ctx context.Context, merchantID uuid.UUID, channel string) ([]*model.ChannelCredential, error) {
+	return s.repo.List(ctx, merchantID, channel)
+}
+
+// ListAudits 查询凭证审计日志
+func (s *credentialService) ListAudits(ctx context.Context, merchantID uuid.UUID, channel string, limit int) ([]*model.ChannelCredentialAudit, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListAudits(ctx, merchantID, channel, limit)
+}
+
+// Rewrap 用当前 KEK 重新加密数据密钥，凭证密文本身不变
+func (s *credentialService) Rewrap(ctx context.Context, op *CredentialOperator) (int, error) {
+	creds, err := s.repo.ListNotEncryptedWith(ctx, s.keyring.ActiveKeyID(), rewrapBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, cred := range creds {
+		oldKeyID := cred.KeyID
+		env, err := s.keyring.Rewrap(cred.Envelope())
+		if err != nil {
+			logger.Error("重新加密凭证失败",
+				zap.String("credential_id", cred.ID.String()),
+				zap.String("key_id", oldKeyID),
+				zap.Error(err))
+			_ = s.repo.CreateAudit(ctx, s.newAudit(cred, model.CredentialActionRewrap, model.CredentialAuditFailed, err.Error(), op))
+			continue
+		}
+		cred.SetEnvelope(env)
+		audit := s.newAudit(cred, model.CredentialActionRewrap, model.CredentialAuditSuccess, oldKeyID+" -> "+env.KeyID, op)
+		if err := s.repo.Save(ctx, cred, audit); err != nil {
+			return count, fmt.Errorf("保存重新加密的凭证失败: %w", err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ResolveCredentials 解密使用中的凭证
+func (s *credentialService) ResolveCredentials(ctx context.Context, merchantID uuid.UUID, channel, mode string) ([]json.RawMessage, error) {
+	creds, err := s.repo.ListActive(ctx, merchantID, channel, mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(creds) == 0 {
+		return nil, ErrNoActiveCredential
+	}
+
+	secrets := make([]json.RawMessage, 0, len(creds))
+	for _, cred := range creds {
+		secret, err := s.keyring.Open(cred.Envelope())
+		if err != nil {
+			return nil, fmt.Errorf("解密凭证 v%d 失败: %w", cred.Version, err)
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// get 查询凭证，不存在时返回 ErrCredentialNotFound
+func (s *credentialService) get(ctx context.Context, id uuid.UUID) (*model.ChannelCredential, error) {
+	cred, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil {
+		return nil, ErrCredentialNotFound
+	}
+	return cred, nil
+}
+
+// newAudit 构造审计日志
+func (s *credentialService) newAudit(cred *model.ChannelCredential, action, result, detail string, op *CredentialOperator) *model.ChannelCredentialAudit {
+	audit := &model.ChannelCredentialAudit{
+		CredentialID: cred.ID,
+		MerchantID:   cred.MerchantID,
+		Channel:      cred.Channel,
+		Mode:         cred.Mode,
+		Version:      cred.Version,
+		Action:       action,
+		Result:       result,
+		Detail:       detail,
+		CreatedAt:    s.now(),
+	}
+	if op != nil {
+		audit.OperatorID = op.OperatorID
+		audit.ClientIP = op.ClientIP
+	}
+	return audit
+}
+
+// normalizeCredential 统一为紧凑 JSON（键有序），保证相同凭证得到相同指纹
+func normalizeCredential(raw json.RawMessage) ([]byte, map[string]interface{}, string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil || len(fields) == 0 {
+		return nil, nil, "", ErrCredentialInvalidFormat
+	}
+	secret, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	sum := sha256.Sum256(secret)
+	return secret, fields, hex.EncodeToString(sum[:])[:16], nil
+}
+
+// credentialHint 生成脱敏提示：保留主标识字段的前缀和末4位
+func credentialHint(fields map[string]interface{}) string {
+	for _, key := range []string{"api_key", "client_id", "app_id"} {
+		value, _ := fields[key].(string)
+		if value == "" {
+			continue
+		}
+		if len(value) <= 12 {
+			return "…" + value[len(value)-min(4, len(value)):]
+		}
+		return value[:8] + "…" + value[len(value)-4:]
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/crypto"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// memoryCredentialRepo 内存凭证仓储
+type memoryCredentialRepo struct {
+	creds  map[uuid.UUID]*model.ChannelCredential
+	audits []*model.ChannelCredentialAudit
+}
+
+func newMemoryCredentialRepo() *memoryCredentialRepo {
+	return &memoryCredentialRepo{creds: map[uuid.UUID]*model.ChannelCredential{}}
+}
+
+func (r *memoryCredentialRepo) CreateVersion(_ context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error {
+	cred.ID = uuid.New()
+	cred.Version = 1
+	for _, c := range r.creds {
+		if c.MerchantID == cred.MerchantID && c.Channel == cred.Channel && c.Mode == cred.Mode && c.Version >= cred.Version {
+			cred.Version = c.Version + 1
+		}
+	}
+	copied := *cred
+	r.creds[cred.ID] = &copied
+	audit.CredentialID = cred.ID
+	audit.Version = cred.Version
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+func (r *memoryCredentialRepo) Save(_ context.Context, cred *model.ChannelCredential, audit *model.ChannelCredentialAudit) error {
+	copied := *cred
+	r.creds[cred.ID] = &copied
+	if audit != nil {
+		r.audits = append(r.audits, audit)
+	}
+	return nil
+}
+
+func (r *memoryCredentialRepo) GetByID(_ context.Context, id uuid.UUID) (*model.ChannelCredential, error) {
+	cred, ok := r.creds[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *cred
+	return &copied, nil
+}
+
+func (r *memoryCredentialRepo) List(_ context.Context, merchantID uuid.UUID, channel string) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	for _, c := range r.creds {
+		if c.MerchantID == merchantID && (channel == "" || c.Channel == channel) {
+			copied := *c
+			creds = append(creds, &copied)
+		}
+	}
+	return creds, nil
+}
+
+func (r *memoryCredentialRepo) ListActive(_ context.Context, merchantID uuid.UUID, channel, mode string) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	for _, c := range r.creds {
+		if c.MerchantID == merchantID && c.Channel == channel && c.Mode == mode && c.Status == model.CredentialStatusActive {
+			copied := *c
+			creds = append(creds, &copied)
+		}
+	}
+	sort.Slice(creds, func(i, j int) bool { return creds[i].Version > creds[j].Version })
+	return creds, nil
+}
+
+func (r *memoryCredentialRepo) ListNotEncryptedWith(_ context.Context, keyID string, limit int) ([]*model.ChannelCredential, error) {
+	var creds []*model.ChannelCredential
+	for _, c := range r.creds {
+		if c.KeyID != keyID && c.Status != model.CredentialStatusRevoked && len(creds) < limit {
+			copied := *c
+			creds = append(creds, &copied)
+		}
+	}
+	return creds, nil
+}
+
+func (r *memoryCredentialRepo) CreateAudit(_ context.Context, audit *model.ChannelCredentialAudit) error {
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+func (r *memoryCredentialRepo) ListAudits(_ context.Context, merchantID uuid.UUID, channel string, limit int) ([]*model.ChannelCredentialAudit, error) {
+	return r.audits, nil
+}
+
+func newTestKeyring(t *testing.T, activeKeyID string, keys map[string][]byte) *crypto.Keyring {
+	t.Helper()
+	ring, err := crypto.NewKeyring(activeKeyID, keys)
+	if err != nil {
+		t.Fatalf("创建密钥环失败: %v", err)
+	}
+	return ring
+}
+
+func newTestCredentialService(t *testing.T) (*credentialService, *memoryCredentialRepo) {
+	key, _ := crypto.GenerateKey(32)
+	repo := newMemoryCredentialRepo()
+	svc := NewCredentialService(repo, newTestKeyring(t, "k1", map[string][]byte{"k1": key})).(*credentialService)
+	svc.validator = func(_ context.Context, _, _ string, secret []byte) error {
+		var cfg model.StripeConfig
+		_ = json.Unmarshal(secret, &cfg)
+		if cfg.APIKey == "sk_test_bad" {
+			return errors.New("Invalid API Key provided")
+		}
+		return nil
+	}
+	return svc, repo
+}
+
+func createTestCredential(t *testing.T, svc CredentialService, merchantID uuid.UUID, apiKey string) *model.ChannelCredential {
+	t.Helper()
+	cred, err := svc.Create(context.Background(), &CreateCredentialInput{
+		CredentialOperator: CredentialOperator{OperatorID: uuid.New(), ClientIP: "10.0.0.1"},
+		MerchantID:         merchantID,
+		Channel:            model.ChannelStripe,
+		Mode:               model.ModeTest,
+		Credentials:        json.RawMessage(`{"api_key":"` + apiKey + `","webhook_secret":"whsec_abc"}`),
+	})
+	if err != nil {
+		t.Fatalf("提交凭证失败: %v", err)
+	}
+	return cred
+}
+
+func TestCredentialLifecycleAndRotation(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestCredentialService(t)
+	merchantID := uuid.New()
+	op := &CredentialOperator{OperatorID: uuid.New()}
+
+	v1 := createTestCredential(t, svc, merchantID, "sk_test_4eC39HqLyjWDarjtT1zdp7dc")
+	if v1.Version != 1 || v1.Status != model.CredentialStatusPending || v1.KeyID != "k1" {
+		t.Fatalf("unexpected credential: %+v", v1)
+	}
+	if v1.Hint != "sk_test_…p7dc" {
+		t.Errorf("hint = %q", v1.Hint)
+	}
+
+	// 未校验不能激活
+	if _, err := svc.Activate(ctx, v1.ID, op); !errors.Is(err, ErrCredentialNotValidated) {
+		t.Fatalf("expected ErrCredentialNotValidated, got %v", err)
+	}
+	if _, err := svc.Validate(ctx, v1.ID, op); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Activate(ctx, v1.ID, op); err != nil {
+		t.Fatal(err)
+	}
+
+	// 唯一使用中的版本不能停用
+	if _, err := svc.Retire(ctx, v1.ID, op); !errors.Is(err, ErrCredentialLastActive) {
+		t.Fatalf("expected ErrCredentialLastActive, got %v", err)
+	}
+
+	// 校验失败的新版本记录失败原因，且不能激活
+	bad := createTestCredential(t, svc, merchantID, "sk_test_bad")
+	bad, err := svc.Validate(ctx, bad.ID, op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bad.ValidationError == "" || bad.ValidatedAt != nil {
+		t.Fatalf("expected validation failure, got %+v", bad)
+	}
+	if _, err := svc.Activate(ctx, bad.ID, op); !errors.Is(err, ErrCredentialNotValidated) {
+		t.Fatalf("expected ErrCredentialNotValidated, got %v", err)
+	}
+
+	// 轮换：新版本激活后两个版本同时可用，调用渠道优先使用新版本
+	v3 := createTestCredential(t, svc, merchantID, "sk_test_51NewKeyAbcdefgh")
+	if v3.Version != 3 {
+		t.Fatalf("version = %d, want 3", v3.Version)
+	}
+	if _, err := svc.Validate(ctx, v3.ID, op); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Activate(ctx, v3.ID, op); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err := svc.ResolveCredentials(ctx, merchantID, model.ChannelStripe, model.ModeTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 || !json.Valid(secrets[0]) {
+		t.Fatalf("expected 2 active credentials, got %d", len(secrets))
+	}
+	var newest model.StripeConfig
+	_ = json.Unmarshal(secrets[0], &newest)
+	if newest.APIKey != "sk_test_51NewKeyAbcdefgh" {
+		t.Errorf("newest api_key = %s", newest.APIKey)
+	}
+
+	if _, err := svc.Retire(ctx, v1.ID, op); err != nil {
+		t.Fatal(err)
+	}
+	secrets, _ = svc.ResolveCredentials(ctx, merchantID, model.ChannelStripe, model.ModeTest)
+	if len(secrets) != 1 {
+		t.Fatalf("expected 1 active credential after retire, got %d", len(secrets))
+	}
+
+	// 吊销立即生效，即使是唯一使用中的版本
+	if _, err := svc.Revoke(ctx, v3.ID, "密钥泄露", op); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ResolveCredentials(ctx, merchantID, model.ChannelStripe, model.ModeTest); !errors.Is(err, ErrNoActiveCredential) {
+		t.Fatalf("expected ErrNoActiveCredential, got %v", err)
+	}
+
+	actions := map[string]int{}
+	for _, a := range repo.audits {
+		actions[a.Action+":"+a.Result]++
+	}
+	if actions["activate:failed"] != 2 || actions["validate:failed"] != 1 || actions["revoke:success"] != 1 {
+		t.Errorf("unexpected audit trail: %v", actions)
+	}
+}
+
+func TestCredentialRewrap(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestCredentialService(t)
+	oldKey, _ := crypto.GenerateKey(32)
+	newKey, _ := crypto.GenerateKey(32)
+	svc.keyring = newTestKeyring(t, "k1", map[string][]byte{"k1": oldKey})
+
+	cred := createTestCredential(t, svc, uuid.New(), "sk_test_4eC39HqLyjWDarjtT1zdp7dc")
+	plaintext, err := svc.keyring.Open(cred.Envelope())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换 KEK：新密钥环同时持有 k1 和 k2，以 k2 加密
+	svc.keyring = newTestKeyring(t, "k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+	n, err := svc.Rewrap(ctx, &CredentialOperator{OperatorID: uuid.New()})
+	if err != nil || n != 1 {
+		t.Fatalf("Rewrap = %d, %v", n, err)
+	}
+	stored := repo.creds[cred.ID]
+	if stored.KeyID != "k2" || stored.Ciphertext != cred.Ciphertext {
+		t.Fatalf("unexpected envelope after rewrap: %+v", stored.Envelope())
+	}
+	if last := repo.audits[len(repo.audits)-1]; last.Action != model.CredentialActionRewrap || last.Detail != "k1 -> k2" {
+		t.Errorf("unexpected audit: %+v", last)
+	}
+
+	// 移除旧 KEK 后仍可解密
+	newOnly := newTestKeyring(t, "k2", map[string][]byte{"k2": newKey})
+	got, err := newOnly.Open(stored.Envelope())
+	if err != nil || string(got) != string(plaintext) {
+		t.Fatalf("Open after rewrap = %s, %v", got, err)
+	}
+}
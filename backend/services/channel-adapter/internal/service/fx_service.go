@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/money"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+// 报价相关错误
+var (
+	ErrFXQuoteNotFound = errors.New("报价不存在")
+	ErrFXQuoteMismatch = errors.New("报价与业务单不匹配")
+)
+
+// maxMarkupBps 加点上限（10%）
+const maxMarkupBps = 1000
+
+// rateScale 成交汇率保留的小数位数
+const rateScale = 10
+
+// RateSource 汇率快照来源（由 client.ExchangeRateClient 实现）
+type RateSource interface {
+	LatestSnapshot(ctx context.Context, baseCurrency string) (*model.ExchangeRateSnapshot, error)
+}
+
+// FXService 换汇报价服务接口
+type FXService interface {
+	// 报价
+	CreateQuote(ctx context.Context, input *CreateFXQuoteInput) (*model.FXQuote, error)
+	GetQuote(ctx context.Context, quoteID string) (*model.FXQuote, error)
+	LockQuote(ctx context.Context, input *LockFXQuoteInput) (*model.FXQuote, error)
+	ReleaseQuote(ctx context.Context, input *ReleaseFXQuoteInput) (*model.FXQuote, error)
+
+	// 市场中间价（带快照来源）
+	GetMidRate(ctx context.Context, baseCurrency, targetCurrency string) (*MidRate, error)
+
+	// 商户加点
+	SetMarkup(ctx context.Context, input *SetFXMarkupInput) (*model.FXMarkup, error)
+	ListMarkups(ctx context.Context, merchantID uuid.UUID) ([]*model.FXMarkup, error)
+}
+
+// FXConfig 换汇配置
+type FXConfig struct {
+	QuoteTTL         time.Duration // 报价有效期
+	DefaultMarkupBps int           // 未配置商户加点时的默认加点
+}
+
+type fxService struct {
+	repo   repository.FXQuoteRepository
+	rates  RateSource
+	config FXConfig
+	now    func() time.Time
+}
+
+// NewFXService 创建换汇报价服务
+func NewFXService(repo repository.FXQuoteRepository, rates RateSource, config FXConfig) FXService {
+	if config.QuoteTTL <= 0 {
+		config.QuoteTTL = 5 * time.Minute
+	}
+	return &fxService{
+		repo:   repo,
+		rates:  rates,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// CreateFXQuoteInput 创建报价输入
+type CreateFXQuoteInput struct {
+	MerchantID     uuid.UUID `json:"merchant_id" binding:"required"`
+	BaseCurrency   string    `json:"base_currency" binding:"required"`   // 卖出币种
+	TargetCurrency string    `json:"target_currency" binding:"required"` // 买入币种
+	BaseAmount     int64     `json:"base_amount"`                        // 金额（最小单位，可选）
+}
+
+// LockFXQuoteInput 锁定报价输入
+type LockFXQuoteInput struct {
+	QuoteID        string    `json:"-"`
+	MerchantID     uuid.UUID `json:"merchant_id" binding:"required"`
+	Reference      string    `json:"reference" binding:"required"` // 业务单号
+	BaseCurrency   string    `json:"base_currency"`                // 可选，校验报价币种
+	TargetCurrency string    `json:"target_currency"`              // 可选，校验报价币种
+}
+
+// ReleaseFXQuoteInput 释放报价输入
+type ReleaseFXQuoteInput struct {
+	QuoteID    string    `json:"-"`
+	MerchantID uuid.UUID `json:"merchant_id" binding:"required"`
+	Reference  string    `json:"reference" binding:"required"` // 锁定报价的业务单号
+}
+
+// SetFXMarkupInput 设置商户加点输入
+type SetFXMarkupInput struct {
+	MerchantID     uuid.UUID `json:"merchant_id" binding:"required"`
+	BaseCurrency   string    `json:"base_currency"`   // 为空表示所有币种对
+	TargetCurrency string    `json:"target_currency"` // 为空表示所有币种对
+	MarkupBps      int       `json:"markup_bps"`
+}
+
+// MidRate 市场中间价
+type MidRate struct {
+	BaseCurrency   string        `json:"base_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	Rate           money.Decimal `json:"rate"`
+	SnapshotID     uuid.UUID     `json:"snapshot_id"`
+	Source         string        `json:"source"`
+	SnapshotTime   time.Time     `json:"snapshot_time"`
+}
+
+// CreateQuote 创建报价：取最新快照的中间价，按商户加点计算成交汇率
+func (s *fxService) CreateQuote(ctx context.Context, input *CreateFXQuoteInput) (*model.FXQuote, error) {
+	base := money.NormalizeCurrency(input.BaseCurrency)
+	target := money.NormalizeCurrency(input.TargetCurrency)
+	if base == target {
+		return nil, fmt.Errorf("卖出币种和买入币种不能相同")
+	}
+	if input.BaseAmount < 0 {
+		return nil, fmt.Errorf("报价金额不能为负")
+	}
+
+	mid, err := s.GetMidRate(ctx, base, target)
+	if err != nil {
+		return nil, err
+	}
+
+	markupBps, err := s.markupFor(ctx, input.MerchantID, base, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// 成交汇率 = 中间价 × (1 - 加点)，商户卖出 base 时少得 target
+	rate := mid.Rate.Mul(money.NewDecimal(int64(10000-markupBps), 4)).Round(rateScale, money.RoundHalfEven)
+
+	now := s.now()
+	quote := &model.FXQuote{
+		QuoteID:        generateQuoteID(now),
+		MerchantID:     input.MerchantID,
+		BaseCurrency:   base,
+		TargetCurrency: target,
+		MidRate:        mid.Rate,
+		MarkupBps:      markupBps,
+		Rate:           rate,
+		BaseAmount:     input.BaseAmount,
+		SnapshotID:     mid.SnapshotID,
+		RateSource:     mid.Source,
+		RateTime:       mid.SnapshotTime,
+		ExpiresAt:      now.Add(s.config.QuoteTTL),
+		Status:         model.FXQuoteStatusOpen,
+	}
+	if input.BaseAmount > 0 {
+		converted, err := money.New(input.BaseAmount, base).Convert(rate, target, money.RoundHalfEven)
+		if err != nil {
+			return nil, fmt.Errorf("计算报价金额失败: %w", err)
+		}
+		quote.TargetAmount = converted.Amount
+	}
+
+	if err := s.repo.Create(ctx, quote); err != nil {
+		return nil, fmt.Errorf("保存报价失败: %w", err)
+	}
+
+	logger.Info("换汇报价已创建",
+		zap.String("quote_id", quote.QuoteID),
+		zap.String("merchant_id", input.MerchantID.String()),
+		zap.String("pair", base+"/"+target),
+		zap.String("mid_rate", mid.Rate.String()),
+		zap.Int("markup_bps", markupBps),
+		zap.String("snapshot_id", mid.SnapshotID.String()))
+
+	return quote, nil
+}
+
+// GetQuote 查询报价
+func (s *fxService) GetQuote(ctx context.Context, quoteID string) (*model.FXQuote, error) {
+	quote, err := s.repo.GetByQuoteID(ctx, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价失败: %w", err)
+	}
+	if quote == nil {
+		return nil, ErrFXQuoteNotFound
+	}
+	// 过期但尚未被锁定的报价按过期展示
+	if quote.Status == model.FXQuoteStatusOpen && quote.IsExpired(s.now()) {
+		quote.Status = model.FXQuoteStatusExpired
+	}
+	return quote, nil
+}
+
+// LockQuote 将报价锁定到支付或货币转换，锁定后该业务单按报价汇率成交
+func (s *fxService) LockQuote(ctx context.Context, input *LockFXQuoteInput) (*model.FXQuote, error) {
+	quote, err := s.repo.GetByQuoteID(ctx, input.QuoteID)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价失败: %w", err)
+	}
+	if quote == nil {
+		return nil, ErrFXQuoteNotFound
+	}
+
+	if quote.MerchantID != input.MerchantID {
+		return nil, fmt.Errorf("%w: 商户不一致", ErrFXQuoteMismatch)
+	}
+	if input.BaseCurrency != "" && money.NormalizeCurrency(input.BaseCurrency) != quote.BaseCurrency {
+		return nil, fmt.Errorf("%w: 报价卖出币种为 %s", ErrFXQuoteMismatch, quote.BaseCurrency)
+	}
+	if input.TargetCurrency != "" && money.NormalizeCurrency(input.TargetCurrency) != quote.TargetCurrency {
+		return nil, fmt.Errorf("%w: 报价买入币种为 %s", ErrFXQuoteMismatch, quote.TargetCurrency)
+	}
+
+	locked, err := s.repo.Lock(ctx, input.QuoteID, input.Reference, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if locked == nil {
+		return nil, ErrFXQuoteNotFound
+	}
+
+	logger.Info("换汇报价已锁定",
+		zap.String("quote_id", locked.QuoteID),
+		zap.String("reference", locked.Reference))
+
+	return locked, nil
+}
+
+// ReleaseQuote 释放业务单锁定的报价（支付或货币转换创建失败、取消时调用）
+func (s *fxService) ReleaseQuote(ctx context.Context, input *ReleaseFXQuoteInput) (*model.FXQuote, error) {
+	quote, err := s.repo.GetByQuoteID(ctx, input.QuoteID)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价失败: %w", err)
+	}
+	if quote == nil {
+		return nil, ErrFXQuoteNotFound
+	}
+	if quote.MerchantID != input.MerchantID {
+		return nil, fmt.Errorf("%w: 商户不一致", ErrFXQuoteMismatch)
+	}
+
+	released, err := s.repo.Release(ctx, input.QuoteID, input.Reference, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if released == nil {
+		return nil, ErrFXQuoteNotFound
+	}
+
+	logger.Info("换汇报价已释放",
+		zap.String("quote_id", released.QuoteID),
+		zap.String("reference", input.Reference),
+		zap.String("status", released.Status))
+
+	return released, nil
+}
+
+// GetMidRate 获取市场中间价，汇率过期时返回 client.ErrRateStale
+func (s *fxService) GetMidRate(ctx context.Context, baseCurrency, targetCurrency string) (*MidRate, error) {
+	base := money.NormalizeCurrency(baseCurrency)
+	target := money.NormalizeCurrency(targetCurrency)
+
+	snapshot, err := s.rates.LatestSnapshot(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := snapshot.Rates[target]
+	if !ok || value <= 0 {
+		return nil, fmt.Errorf("不支持的货币对: %s/%s", base, target)
+	}
+
+	return &MidRate{
+		BaseCurrency:   base,
+		TargetCurrency: target,
+		Rate:           money.DecimalFromFloat(value),
+		SnapshotID:     snapshot.ID,
+		Source:         snapshot.Source,
+		SnapshotTime:   snapshot.SnapshotTime,
+	}, nil
+}
+
+// SetMarkup 设置商户加点
+func (s *fxService) SetMarkup(ctx context.Context, input *SetFXMarkupInput) (*model.FXMarkup, error) {
+	if input.MarkupBps < 0 || input.MarkupBps > maxMarkupBps {
+		return nil, fmt.Errorf("加点必须在 0-%d 基点之间", maxMarkupBps)
+	}
+	if (input.BaseCurrency == "") != (input.TargetCurrency == "") {
+		return nil, fmt.Errorf("币种对必须同时指定或同时为空")
+	}
+
+	markup := &model.FXMarkup{
+		MerchantID:     input.MerchantID,
+		BaseCurrency:   money.NormalizeCurrency(input.BaseCurrency),
+		TargetCurrency: money.NormalizeCurrency(input.TargetCurrency),
+		MarkupBps:      input.MarkupBps,
+	}
+	if err := s.repo.SaveMarkup(ctx, markup); err != nil {
+		return nil, fmt.Errorf("保存加点配置失败: %w", err)
+	}
+	return markup, nil
+}
+
+// ListMarkups 查询商户加点配置
+func (s *fxService) ListMarkups(ctx context.Context, merchantID uuid.UUID) ([]*model.FXMarkup, error) {
+	return s.repo.ListMarkups(ctx, merchantID)
+}
+
+// markupFor 商户币种对加点 > 商户通用加点 > 默认加点
+func (s *fxService) markupFor(ctx context.Context, merchantID uuid.UUID, base, target string) (int, error) {
+	markup, err := s.repo.GetMarkup(ctx, merchantID, base, target)
+	if err != nil {
+		return 0, fmt.Errorf("查询加点配置失败: %w", err)
+	}
+	if markup != nil {
+		return markup.MarkupBps, nil
+	}
+	return s.config.DefaultMarkupBps, nil
+}
+
+// generateQuoteID 生成报价编号
+func generateQuoteID(now time.Time) string {
+	return fmt.Sprintf("FXQ%s%s", now.Format("20060102150405"), strings.ToUpper(uuid.New().String()[:8]))
+}
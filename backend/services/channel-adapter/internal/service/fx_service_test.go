@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/channel-adapter/internal/client"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+func init() {
+	logger.Log = zap.NewNop()
+}
+
+// memoryFXRepo 内存报价仓储
+type memoryFXRepo struct {
+	quotes  map[string]*model.FXQuote
+	markups []*model.FXMarkup
+}
+
+func newMemoryFXRepo() *memoryFXRepo {
+	return &memoryFXRepo{quotes: map[string]*model.FXQuote{}}
+}
+
+func (r *memoryFXRepo) Create(_ context.Context, quote *model.FXQuote) error {
+	copied := *quote
+	r.quotes[quote.QuoteID] = &copied
+	return nil
+}
+
+func (r *memoryFXRepo) GetByQuoteID(_ context.Context, quoteID string) (*model.FXQuote, error) {
+	quote, ok := r.quotes[quoteID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *quote
+	return &copied, nil
+}
+
+func (r *memoryFXRepo) Lock(_ context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error) {
+	quote, ok := r.quotes[quoteID]
+	if !ok {
+		return nil, nil
+	}
+	switch {
+	case quote.Status == model.FXQuoteStatusLocked && quote.Reference == reference:
+	case quote.Status != model.FXQuoteStatusOpen:
+		return nil, repository.ErrFXQuoteUnavailable
+	case quote.IsExpired(now):
+		quote.Status = model.FXQuoteStatusExpired
+		return nil, repository.ErrFXQuoteUnavailable
+	default:
+		quote.Status = model.FXQuoteStatusLocked
+		quote.Reference = reference
+		quote.LockedAt = &now
+	}
+	copied := *quote
+	return &copied, nil
+}
+
+func (r *memoryFXRepo) Release(_ context.Context, quoteID, reference string, now time.Time) (*model.FXQuote, error) {
+	quote, ok := r.quotes[quoteID]
+	if !ok {
+		return nil, nil
+	}
+	if quote.Status == model.FXQuoteStatusLocked && quote.Reference == reference {
+		quote.Status = model.FXQuoteStatusOpen
+		if quote.IsExpired(now) {
+			quote.Status = model.FXQuoteStatusExpired
+		}
+		quote.Reference = ""
+		quote.LockedAt = nil
+	}
+	copied := *quote
+	return &copied, nil
+}
+
+func (r *memoryFXRepo) GetMarkup(_ context.Context, merchantID uuid.UUID, base, target string) (*model.FXMarkup, error) {
+	var general *model.FXMarkup
+	for _, m := range r.markups {
+		if m.MerchantID != merchantID {
+			continue
+		}
+		if m.BaseCurrency == base && m.TargetCurrency == target {
+			return m, nil
+		}
+		if m.BaseCurrency == "" && m.TargetCurrency == "" {
+			general = m
+		}
+	}
+	return general, nil
+}
+
+func (r *memoryFXRepo) SaveMarkup(_ context.Context, markup *model.FXMarkup) error {
+	r.markups = append(r.markups, markup)
+	return nil
+}
+
+func (r *memoryFXRepo) ListMarkups(_ context.Context, merchantID uuid.UUID) ([]*model.FXMarkup, error) {
+	return r.markups, nil
+}
+
+// staticRates 固定快照
+type staticRates struct {
+	snapshot *model.ExchangeRateSnapshot
+	err      error
+}
+
+func (s *staticRates) LatestSnapshot(_ context.Context, _ string) (*model.ExchangeRateSnapshot, error) {
+	return s.snapshot, s.err
+}
+
+func newTestFXService(repo *memoryFXRepo, rates RateSource, now time.Time) *fxService {
+	svc := NewFXService(repo, rates, FXConfig{QuoteTTL: time.Minute, DefaultMarkupBps: 20}).(*fxService)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestCreateQuoteAppliesMerchantMarkup(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+	snapshotID := uuid.New()
+	repo := newMemoryFXRepo()
+	rates := &staticRates{snapshot: &model.ExchangeRateSnapshot{
+		ID:           snapshotID,
+		BaseCurrency: "USD",
+		Rates:        map[string]float64{"JPY": 150, "EUR": 0.92},
+		Source:       "exchangerate-api",
+		SnapshotTime: now.Add(-10 * time.Minute),
+	}}
+	svc := newTestFXService(repo, rates, now)
+
+	// 默认加点 20bp：150 × 0.998 = 149.7
+	quote, err := svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: merchantID, BaseCurrency: "usd", TargetCurrency: "JPY", BaseAmount: 1000,
+	})
+	if err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if quote.Rate.String() != "149.7000000000" || quote.MarkupBps != 20 {
+		t.Fatalf("unexpected rate %s markup %d", quote.Rate, quote.MarkupBps)
+	}
+	if quote.TargetAmount != 1497 { // 10.00 USD -> 1497 JPY
+		t.Fatalf("unexpected target amount %d", quote.TargetAmount)
+	}
+	if quote.SnapshotID != snapshotID || !quote.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("quote does not reference snapshot or ttl: %+v", quote)
+	}
+
+	// 商户币种对加点优先于默认加点
+	if _, err := svc.SetMarkup(context.Background(), &SetFXMarkupInput{
+		MerchantID: merchantID, BaseCurrency: "USD", TargetCurrency: "EUR", MarkupBps: 100,
+	}); err != nil {
+		t.Fatalf("SetMarkup: %v", err)
+	}
+	quote, err = svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: merchantID, BaseCurrency: "USD", TargetCurrency: "EUR",
+	})
+	if err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if quote.Rate.String() != "0.9108000000" {
+		t.Fatalf("unexpected rate %s", quote.Rate)
+	}
+}
+
+func TestCreateQuoteRejectsStaleRates(t *testing.T) {
+	svc := newTestFXService(newMemoryFXRepo(), &staticRates{err: client.ErrRateStale}, time.Now())
+	_, err := svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: uuid.New(), BaseCurrency: "USD", TargetCurrency: "EUR",
+	})
+	if !errors.Is(err, client.ErrRateStale) {
+		t.Fatalf("expected ErrRateStale, got %v", err)
+	}
+}
+
+func TestLockQuote(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+	repo := newMemoryFXRepo()
+	rates := &staticRates{snapshot: &model.ExchangeRateSnapshot{
+		ID: uuid.New(), BaseCurrency: "EUR", Rates: map[string]float64{"USD": 1.08}, SnapshotTime: now,
+	}}
+	svc := newTestFXService(repo, rates, now)
+
+	quote, err := svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: merchantID, BaseCurrency: "EUR", TargetCurrency: "USD",
+	})
+	if err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+
+	// 其他商户不能使用
+	_, err = svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: uuid.New(), Reference: "PAY1"})
+	if !errors.Is(err, ErrFXQuoteMismatch) {
+		t.Fatalf("expected mismatch, got %v", err)
+	}
+
+	locked, err := svc.LockQuote(context.Background(), &LockFXQuoteInput{
+		QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY1", BaseCurrency: "eur",
+	})
+	if err != nil || locked.Status != model.FXQuoteStatusLocked {
+		t.Fatalf("LockQuote: %v %+v", err, locked)
+	}
+
+	// 同一业务单重复锁定幂等，其他业务单不可再用
+	if _, err := svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY1"}); err != nil {
+		t.Fatalf("relock same reference: %v", err)
+	}
+	_, err = svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY2"})
+	if !errors.Is(err, repository.ErrFXQuoteUnavailable) {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+
+	// 过期报价不可锁定
+	expiring, _ := svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: merchantID, BaseCurrency: "EUR", TargetCurrency: "USD",
+	})
+	svc.now = func() time.Time { return now.Add(2 * time.Minute) }
+	_, err = svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: expiring.QuoteID, MerchantID: merchantID, Reference: "PAY3"})
+	if !errors.Is(err, repository.ErrFXQuoteUnavailable) {
+		t.Fatalf("expected expired quote to be unavailable, got %v", err)
+	}
+}
+
+func TestReleaseQuote(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+	repo := newMemoryFXRepo()
+	rates := &staticRates{snapshot: &model.ExchangeRateSnapshot{
+		ID: uuid.New(), BaseCurrency: "EUR", Rates: map[string]float64{"USD": 1.08}, SnapshotTime: now,
+	}}
+	svc := newTestFXService(repo, rates, now)
+
+	quote, err := svc.CreateQuote(context.Background(), &CreateFXQuoteInput{
+		MerchantID: merchantID, BaseCurrency: "EUR", TargetCurrency: "USD",
+	})
+	if err != nil {
+		t.Fatalf("CreateQuote: %v", err)
+	}
+	if _, err := svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY1"}); err != nil {
+		t.Fatalf("LockQuote: %v", err)
+	}
+
+	// 其他业务单不能释放
+	other, err := svc.ReleaseQuote(context.Background(), &ReleaseFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY2"})
+	if err != nil || other.Status != model.FXQuoteStatusLocked || other.Reference != "PAY1" {
+		t.Fatalf("release by other reference: %v %+v", err, other)
+	}
+
+	// 释放后可被其他业务单锁定
+	released, err := svc.ReleaseQuote(context.Background(), &ReleaseFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY1"})
+	if err != nil || released.Status != model.FXQuoteStatusOpen || released.Reference != "" {
+		t.Fatalf("ReleaseQuote: %v %+v", err, released)
+	}
+	if _, err := svc.LockQuote(context.Background(), &LockFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY2"}); err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+
+	// 过期后释放标记为过期
+	svc.now = func() time.Time { return now.Add(2 * time.Minute) }
+	expired, err := svc.ReleaseQuote(context.Background(), &ReleaseFXQuoteInput{QuoteID: quote.QuoteID, MerchantID: merchantID, Reference: "PAY2"})
+	if err != nil || expired.Status != model.FXQuoteStatusExpired {
+		t.Fatalf("release expired quote: %v %+v", err, expired)
+	}
+}
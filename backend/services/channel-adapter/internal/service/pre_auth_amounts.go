@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"payment-platform/channel-adapter/internal/adapter"
+	"payment-platform/channel-adapter/internal/model"
+)
+
+// legacyPreAuth 判断预授权是否为迁移前创建的 Stripe 预授权
+//
+// 旧版本中零小数位货币（如 JPY、KRW）的预授权金额按主单位×100 记录，报送 Stripe 时除以 100。
+// 这类在途预授权的确认、查询、增量和重新授权仍使用旧约定的金额，调用适配器前后按记录换算；
+// 新创建的预授权记录标记为 ISO 最小单位，找不到记录的预授权同样按 ISO 最小单位处理。
+func (s *channelService) legacyPreAuth(ctx context.Context, channel, channelPreAuthNo string) (bool, error) {
+	if channel != model.ChannelStripe || s.preAuthRepo == nil {
+		return false, nil
+	}
+	record, err := s.preAuthRepo.GetByChannelPreAuthNo(ctx, channelPreAuthNo)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("查询预授权记录失败: %w", err)
+	}
+	return !record.ISOMinorUnits, nil
+}
+
+// preAuthAmountToISO 将请求金额换算为适配器使用的 ISO 最小单位
+func preAuthAmountToISO(legacy bool, amount int64, currency string) int64 {
+	if legacy {
+		return adapter.LegacyAmountToISO(amount, currency)
+	}
+	return amount
+}
+
+// preAuthAmountFromISO 将适配器返回的 ISO 最小单位金额换算回预授权记录使用的单位
+func preAuthAmountFromISO(legacy bool, amount int64, currency string) int64 {
+	if legacy {
+		return adapter.ISOAmountToLegacy(amount, currency)
+	}
+	return amount
+}
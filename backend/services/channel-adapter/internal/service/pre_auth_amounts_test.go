@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+	"payment-platform/channel-adapter/internal/adapter"
+	"payment-platform/channel-adapter/internal/model"
+	"payment-platform/channel-adapter/internal/repository"
+)
+
+// memoryPreAuthRepo 内存预授权记录仓储
+type memoryPreAuthRepo struct {
+	repository.PreAuthRepository
+	records map[string]*model.PreAuthRecord
+}
+
+func (r *memoryPreAuthRepo) GetByChannelPreAuthNo(_ context.Context, channelPreAuthNo string) (*model.PreAuthRecord, error) {
+	record, ok := r.records[channelPreAuthNo]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *memoryPreAuthRepo) Update(_ context.Context, record *model.PreAuthRecord) error {
+	copied := *record
+	r.records[record.ChannelPreAuthNo] = &copied
+	return nil
+}
+
+// captureAdapter 记录确认金额并按 ISO 最小单位返回
+type captureAdapter struct {
+	adapter.PaymentAdapter
+	captured int64
+}
+
+func (a *captureAdapter) CapturePreAuth(_ context.Context, req *adapter.CapturePreAuthRequest) (*adapter.CapturePreAuthResponse, error) {
+	a.captured = req.Amount
+	return &adapter.CapturePreAuthResponse{
+		ChannelPreAuthNo: req.ChannelPreAuthNo,
+		Status:           model.PreAuthStatusCaptured,
+		Amount:           req.Amount,
+		CapturedAmount:   req.Amount,
+	}, nil
+}
+
+func TestCapturePreAuthLegacyAmounts(t *testing.T) {
+	stripeAdapter := &captureAdapter{}
+	factory := adapter.NewAdapterFactory()
+	factory.Register(model.ChannelStripe, stripeAdapter)
+	repo := &memoryPreAuthRepo{records: map[string]*model.PreAuthRecord{
+		// 迁移前创建：1000 日元记录为 100000
+		"pi_legacy": {ChannelPreAuthNo: "pi_legacy", Channel: model.ChannelStripe, Amount: 100000, Currency: "JPY"},
+		"pi_new":    {ChannelPreAuthNo: "pi_new", Channel: model.ChannelStripe, Amount: 1000, Currency: "JPY", ISOMinorUnits: true},
+	}}
+	s := &channelService{adapterFactory: factory, preAuthRepo: repo}
+
+	tests := []struct {
+		channelPreAuthNo string
+		amount           int64
+		wantChannel      int64
+	}{
+		{"pi_legacy", 100000, 1000}, // 在途预授权按旧约定换算
+		{"pi_new", 1000, 1000},      // 新预授权已是 ISO 最小单位
+		{"pi_unknown", 1000, 1000},  // 没有记录时按 ISO 最小单位处理
+	}
+	for _, tt := range tests {
+		resp, err := s.CapturePreAuth(context.Background(), &CapturePreAuthRequest{
+			Channel:          model.ChannelStripe,
+			ChannelPreAuthNo: tt.channelPreAuthNo,
+			Amount:           tt.amount,
+			Currency:         "JPY",
+		})
+		if err != nil {
+			t.Fatalf("%s: CapturePreAuth() error = %v", tt.channelPreAuthNo, err)
+		}
+		if stripeAdapter.captured != tt.wantChannel {
+			t.Errorf("%s: adapter amount = %d, want %d", tt.channelPreAuthNo, stripeAdapter.captured, tt.wantChannel)
+		}
+		// 返回和记录的金额与请求使用相同单位
+		if resp.CapturedAmount != tt.amount {
+			t.Errorf("%s: captured = %d, want %d", tt.channelPreAuthNo, resp.CapturedAmount, tt.amount)
+		}
+		if record, ok := repo.records[tt.channelPreAuthNo]; ok && record.CapturedAmount != tt.amount {
+			t.Errorf("%s: record captured = %d, want %d", tt.channelPreAuthNo, record.CapturedAmount, tt.amount)
+		}
+	}
+}
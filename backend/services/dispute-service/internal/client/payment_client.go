@@ -5,15 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/middleware"
 )
 
 // PaymentClient 支付网关客户端接口
 type PaymentClient interface {
 	GetPaymentByChannelTradeNo(ctx context.Context, channelTradeNo string) (*PaymentInfo, error)
+	// ReverseSplits 拒付败诉后通知支付网关按分账比例冲正各收款方（按拒付单号幂等）
+	ReverseSplits(ctx context.Context, paymentNo, disputeNo string, amount int64) error
 }
 
 // PaymentInfo 支付信息
@@ -28,8 +32,9 @@ type PaymentInfo struct {
 }
 
 type paymentClient struct {
-	baseURL    string
-	httpClient *httpclient.Client
+	baseURL      string
+	httpClient   *httpclient.Client
+	serviceToken string // 服务间调用令牌（INTERNAL_SERVICE_TOKEN），访问支付网关 /api/v1/service/* 路由
 }
 
 // NewPaymentClient 创建支付网关客户端
@@ -42,6 +47,7 @@ func NewPaymentClient(baseURL string) PaymentClient {
 			RetryDelay:    time.Second,
 			EnableLogging: false,
 		}),
+		serviceToken: os.Getenv("INTERNAL_SERVICE_TOKEN"),
 	}
 }
 
@@ -81,3 +87,30 @@ func (c *paymentClient) GetPaymentByChannelTradeNo(ctx context.Context, channelT
 
 	return result.Data, nil
 }
+
+// ReverseSplits 拒付败诉后冲正分账
+func (c *paymentClient) ReverseSplits(ctx context.Context, paymentNo, disputeNo string, amount int64) error {
+	url := fmt.Sprintf("%s/api/v1/service/payments/%s/split-reversals", c.baseURL, paymentNo)
+
+	headers := middleware.ServiceAuthHeaders("dispute-service", c.serviceToken)
+	headers["Content-Type"] = "application/json"
+	resp, err := c.httpClient.Do(&httpclient.Request{
+		Method: http.MethodPost,
+		URL:    url,
+		Body: map[string]interface{}{
+			"dispute_no": disputeNo,
+			"amount":     amount,
+		},
+		Headers: headers,
+		Ctx:     ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("request split reversal failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("payment service returned status: %d, body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
 	"github.com/stripe/stripe-go/v76"
+	"go.uber.org/zap"
 
 	"payment-platform/dispute-service/internal/client"
 	"payment-platform/dispute-service/internal/model"
@@ -261,9 +263,27 @@ func (s *disputeService) UpdateDisputeStatus(ctx context.Context, id uuid.UUID,
 	}
 	s.repo.CreateTimelineEvent(ctx, timeline)
 
+	if oldStatus != model.DisputeStatusLost && status == model.DisputeStatusLost {
+		s.reverseSplits(ctx, dispute)
+	}
+
 	return nil
 }
 
+// reverseSplits 拒付败诉后通知支付网关按分账比例冲正各收款方（非分账支付由支付网关忽略）
+func (s *disputeService) reverseSplits(ctx context.Context, dispute *model.Dispute) {
+	if s.paymentClient == nil || dispute.PaymentNo == "" {
+		return
+	}
+	if err := s.paymentClient.ReverseSplits(ctx, dispute.PaymentNo, dispute.DisputeNo, dispute.Amount); err != nil {
+		// 支付网关按拒付单号幂等，可重新同步或更新状态重试
+		logger.Error("reverse payment splits for lost dispute failed",
+			zap.String("dispute_no", dispute.DisputeNo),
+			zap.String("payment_no", dispute.PaymentNo),
+			zap.Error(err))
+	}
+}
+
 // AssignDispute 分配拒付给处理人员
 func (s *disputeService) AssignDispute(ctx context.Context, id, assignedTo uuid.UUID) error {
 	dispute, err := s.repo.GetDisputeByID(ctx, id)
@@ -454,6 +474,7 @@ func (s *disputeService) SyncFromStripe(ctx context.Context, channelDisputeID st
 
 	if existing != nil {
 		// Update existing dispute
+		oldStatus := existing.Status
 		existing.Status = mapStripeStatus(stripeDispute.Status)
 		existing.Reason = string(stripeDispute.Reason)
 		if stripeDispute.EvidenceDetails != nil && stripeDispute.EvidenceDetails.DueBy > 0 {
@@ -465,6 +486,10 @@ func (s *disputeService) SyncFromStripe(ctx context.Context, channelDisputeID st
 			return nil, fmt.Errorf("update dispute failed: %w", err)
 		}
 
+		if oldStatus != model.DisputeStatusLost && existing.Status == model.DisputeStatusLost {
+			s.reverseSplits(ctx, existing)
+		}
+
 		return existing, nil
 	}
 
@@ -60,6 +60,7 @@ func main() {
 			&model.PasswordHistory{},
 			&model.Session{},
 			&model.APIKey{},
+			&model.MerchantPublicKey{},
 		},
 
 		EnableTracing:     true,
@@ -88,14 +89,17 @@ func main() {
 	// 3. 初始化Repository
 	securityRepo := repository.NewSecurityRepository(application.DB)
 	apiKeyRepo := repository.NewAPIKeyRepository(application.DB)
+	publicKeyRepo := repository.NewPublicKeyRepository(application.DB)
 
 	// 4. 初始化Service
 	securityService := service.NewSecurityService(securityRepo, merchantClient)
 	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	publicKeyService := service.NewPublicKeyService(publicKeyRepo)
 
 	// 5. 初始化Handler
 	securityHandler := handler.NewSecurityHandler(securityService)
 	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	publicKeyHandler := handler.NewPublicKeyHandler(publicKeyService)
 
 	// 6. 初始化JWT Manager
 	// ⚠️ 安全要求: JWT_SECRET必须在生产环境中设置，不能使用默认值
@@ -124,6 +128,9 @@ func main() {
 	// 注册API Key路由（部分公开，部分需认证）
 	handler.RegisterAPIKeyRoutes(api, apiKeyHandler, authMiddleware)
 
+	// 注册签名公钥路由（v3 非对称签名，部分公开，部分需认证）
+	handler.RegisterPublicKeyRoutes(api, publicKeyHandler, authMiddleware)
+
 	// 9. 启动定时任务：清理过期会话
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"payment-platform/merchant-auth-service/internal/model"
+	"payment-platform/merchant-auth-service/internal/service"
+)
+
+// PublicKeyHandler 商户签名公钥处理器（签名算法 v3）
+type PublicKeyHandler struct {
+	service service.PublicKeyService
+}
+
+// NewPublicKeyHandler 创建商户签名公钥处理器
+func NewPublicKeyHandler(service service.PublicKeyService) *PublicKeyHandler {
+	return &PublicKeyHandler{service: service}
+}
+
+// RegisterPublicKey 登记签名公钥
+// @Summary		登记签名公钥
+// @Description	商户登记用于 v3 请求签名的公钥（Ed25519 或 RSA-PSS），私钥由商户自行保管
+// @Tags		Public Keys
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		request	body	RegisterPublicKeyRequest	true	"登记请求"
+// @Success		201		{object}	PublicKeyInfo
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Router		/public-keys [post]
+func (h *PublicKeyHandler) RegisterPublicKey(c *gin.Context) {
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid merchant_id"})
+		return
+	}
+
+	var req RegisterPublicKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	key, err := h.service.RegisterPublicKey(c.Request.Context(), merchantID, &service.RegisterPublicKeyInput{
+		Name:        req.Name,
+		Algorithm:   req.Algorithm,
+		PublicKey:   req.PublicKey,
+		Environment: req.Environment,
+		ExpiresAt:   req.ExpiresAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPublicKeyInfo(key))
+}
+
+// ListPublicKeys 获取签名公钥列表
+// @Summary		获取签名公钥列表
+// @Description	获取商户登记的所有签名公钥
+// @Tags		Public Keys
+// @Produce		json
+// @Security	BearerAuth
+// @Success		200	{array}		PublicKeyInfo
+// @Failure		401	{object}	ErrorResponse
+// @Router		/public-keys [get]
+func (h *PublicKeyHandler) ListPublicKeys(c *gin.Context) {
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid merchant_id"})
+		return
+	}
+
+	keys, err := h.service.ListPublicKeys(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result := make([]PublicKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, toPublicKeyInfo(key))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RevokePublicKey 吊销签名公钥
+// @Summary		吊销签名公钥
+// @Description	吊销指定的签名公钥，吊销后使用该公钥ID签名的请求将被拒绝
+// @Tags		Public Keys
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id	path	string	true	"公钥记录ID"
+// @Success		200	{object}	SuccessResponse
+// @Failure		401	{object}	ErrorResponse
+// @Failure		404	{object}	ErrorResponse
+// @Router		/public-keys/{id} [delete]
+func (h *PublicKeyHandler) RevokePublicKey(c *gin.Context) {
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid merchant_id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid public key id"})
+		return
+	}
+
+	if err := h.service.RevokePublicKey(c.Request.Context(), merchantID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Public key revoked successfully"})
+}
+
+// GetPublicKey 根据公钥ID获取公钥（供 payment-gateway 验证 v3 签名调用）
+// @Summary		获取签名公钥
+// @Description	根据 X-Key-Id 获取启用中的签名公钥
+// @Tags		Auth
+// @Produce		json
+// @Param		key_id	path	string	true	"公钥ID"
+// @Success		200		{object}	PublicKeyLookupResponse
+// @Failure		404		{object}	ErrorResponse
+// @Router		/auth/public-keys/{key_id} [get]
+func (h *PublicKeyHandler) GetPublicKey(c *gin.Context) {
+	key, err := h.service.GetActivePublicKey(c.Request.Context(), c.Param("key_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PublicKeyLookupResponse{
+		KeyID:       key.KeyID,
+		MerchantID:  key.MerchantID.String(),
+		Algorithm:   key.Algorithm,
+		PublicKey:   key.PublicKey,
+		Environment: key.Environment,
+		ExpiresAt:   key.ExpiresAt,
+	})
+}
+
+// RegisterPublicKeyRoutes 注册签名公钥路由
+func RegisterPublicKeyRoutes(r *gin.RouterGroup, handler *PublicKeyHandler, authMiddleware gin.HandlerFunc) {
+	// 公开路由（供payment-gateway调用）
+	r.GET("/auth/public-keys/:key_id", handler.GetPublicKey)
+
+	// 需要认证的路由（供merchant-portal调用）
+	publicKeys := r.Group("/public-keys")
+	publicKeys.Use(authMiddleware)
+	{
+		publicKeys.POST("", handler.RegisterPublicKey)
+		publicKeys.GET("", handler.ListPublicKeys)
+		publicKeys.DELETE("/:id", handler.RevokePublicKey)
+	}
+}
+
+func toPublicKeyInfo(key *model.MerchantPublicKey) PublicKeyInfo {
+	return PublicKeyInfo{
+		ID:          key.ID.String(),
+		KeyID:       key.KeyID,
+		Algorithm:   key.Algorithm,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		Name:        key.Name,
+		Environment: key.Environment,
+		IsActive:    key.IsActive,
+		LastUsedAt:  key.LastUsedAt,
+		ExpiresAt:   key.ExpiresAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
+
+// DTO定义
+
+type RegisterPublicKeyRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Algorithm   string     `json:"algorithm" binding:"required,oneof=ed25519 rsa-pss-sha256"`
+	PublicKey   string     `json:"public_key" binding:"required"` // PEM格式（-----BEGIN PUBLIC KEY-----）
+	Environment string     `json:"environment" binding:"required,oneof=test production"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type PublicKeyInfo struct {
+	ID          string     `json:"id"`
+	KeyID       string     `json:"key_id"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"public_key"`
+	Fingerprint string     `json:"fingerprint"`
+	Name        string     `json:"name"`
+	Environment string     `json:"environment"`
+	IsActive    bool       `json:"is_active"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type PublicKeyLookupResponse struct {
+	KeyID       string     `json:"key_id"`
+	MerchantID  string     `json:"merchant_id"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"public_key"`
+	Environment string     `json:"environment"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
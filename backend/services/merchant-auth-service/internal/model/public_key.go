@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantPublicKey 商户签名公钥表（签名算法 v3）
+// 商户自行保管私钥，平台只保存公钥，无法伪造商户请求
+type MerchantPublicKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	KeyID       string     `gorm:"type:varchar(64);unique;not null;index" json:"key_id"` // 公钥ID（请求头 X-Key-Id）
+	Algorithm   string     `gorm:"type:varchar(32);not null" json:"algorithm"`           // ed25519, rsa-pss-sha256
+	PublicKey   string     `gorm:"type:text;not null" json:"public_key"`                 // PEM格式公钥（SubjectPublicKeyInfo）
+	Fingerprint string     `gorm:"type:varchar(64);not null;index" json:"fingerprint"`   // 公钥指纹（用于去重和展示）
+	Name        string     `gorm:"type:varchar(100)" json:"name"`                        // 公钥名称
+	Environment string     `gorm:"type:varchar(20);not null;index" json:"environment"`   // test, production
+	IsActive    bool       `gorm:"default:true" json:"is_active"`                        // 是否启用
+	LastUsedAt  *time.Time `gorm:"type:timestamptz" json:"last_used_at"`                 // 最后使用时间
+	ExpiresAt   *time.Time `gorm:"type:timestamptz" json:"expires_at"`                   // 过期时间（null表示永不过期）
+	RevokedAt   *time.Time `gorm:"type:timestamptz" json:"revoked_at,omitempty"`         // 吊销时间
+	CreatedAt   time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MerchantPublicKey) TableName() string {
+	return "merchant_public_keys"
+}
+
+// MaxActivePublicKeysPerMerchant 每个商户同时启用的公钥数量上限（支持平滑轮换）
+const MaxActivePublicKeysPerMerchant = 5
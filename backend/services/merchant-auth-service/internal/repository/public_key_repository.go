@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-platform/merchant-auth-service/internal/model"
+)
+
+// PublicKeyRepository 商户签名公钥仓储接口
+type PublicKeyRepository interface {
+	Create(ctx context.Context, key *model.MerchantPublicKey) error
+	GetByKeyID(ctx context.Context, keyID string) (*model.MerchantPublicKey, error)
+	GetByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*model.MerchantPublicKey, error)
+	GetByIDAndMerchantID(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) (*model.MerchantPublicKey, error)
+	CountActiveByMerchantID(ctx context.Context, merchantID uuid.UUID) (int64, error)
+	ExistsFingerprint(ctx context.Context, merchantID uuid.UUID, fingerprint string) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	UpdateLastUsedAt(ctx context.Context, id uuid.UUID) error
+}
+
+type publicKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewPublicKeyRepository 创建商户签名公钥仓储
+func NewPublicKeyRepository(db *gorm.DB) PublicKeyRepository {
+	return &publicKeyRepository{db: db}
+}
+
+// Create 创建公钥
+func (r *publicKeyRepository) Create(ctx context.Context, key *model.MerchantPublicKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByKeyID 根据公钥ID查询（仅返回启用中的公钥）
+func (r *publicKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*model.MerchantPublicKey, error) {
+	var key model.MerchantPublicKey
+	err := r.db.WithContext(ctx).
+		Where("key_id = ? AND is_active = ?", keyID, true).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByMerchantID 获取商户的所有公钥
+func (r *publicKeyRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*model.MerchantPublicKey, error) {
+	var keys []*model.MerchantPublicKey
+	err := r.db.WithContext(ctx).
+		Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// GetByIDAndMerchantID 验证公钥是否属于指定商户
+func (r *publicKeyRepository) GetByIDAndMerchantID(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) (*model.MerchantPublicKey, error) {
+	var key model.MerchantPublicKey
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND merchant_id = ? AND is_active = ?", id, merchantID, true).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CountActiveByMerchantID 统计商户启用中的公钥数量
+func (r *publicKeyRepository) CountActiveByMerchantID(ctx context.Context, merchantID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.MerchantPublicKey{}).
+		Where("merchant_id = ? AND is_active = ?", merchantID, true).
+		Count(&count).Error
+	return count, err
+}
+
+// ExistsFingerprint 检查商户是否已登记相同的启用中公钥
+func (r *publicKeyRepository) ExistsFingerprint(ctx context.Context, merchantID uuid.UUID, fingerprint string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.MerchantPublicKey{}).
+		Where("merchant_id = ? AND fingerprint = ? AND is_active = ?", merchantID, fingerprint, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Revoke 吊销公钥（软删除）
+func (r *publicKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&model.MerchantPublicKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"revoked_at": now,
+		}).Error
+}
+
+// UpdateLastUsedAt 更新最后使用时间
+func (r *publicKeyRepository) UpdateLastUsedAt(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&model.MerchantPublicKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", now).Error
+}
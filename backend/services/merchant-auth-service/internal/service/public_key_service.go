@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgcrypto "github.com/payment-platform/pkg/crypto"
+	"gorm.io/gorm"
+	"payment-platform/merchant-auth-service/internal/model"
+	"payment-platform/merchant-auth-service/internal/repository"
+)
+
+// PublicKeyService 商户签名公钥服务接口（签名算法 v3）
+type PublicKeyService interface {
+	RegisterPublicKey(ctx context.Context, merchantID uuid.UUID, input *RegisterPublicKeyInput) (*model.MerchantPublicKey, error)
+	ListPublicKeys(ctx context.Context, merchantID uuid.UUID) ([]*model.MerchantPublicKey, error)
+	RevokePublicKey(ctx context.Context, merchantID uuid.UUID, id uuid.UUID) error
+	GetActivePublicKey(ctx context.Context, keyID string) (*model.MerchantPublicKey, error)
+}
+
+// RegisterPublicKeyInput 登记公钥参数
+type RegisterPublicKeyInput struct {
+	Name        string
+	Algorithm   string
+	PublicKey   string
+	Environment string
+	ExpiresAt   *time.Time
+}
+
+type publicKeyService struct {
+	repo repository.PublicKeyRepository
+}
+
+// NewPublicKeyService 创建商户签名公钥服务
+func NewPublicKeyService(repo repository.PublicKeyRepository) PublicKeyService {
+	return &publicKeyService{repo: repo}
+}
+
+// RegisterPublicKey 登记商户公钥
+// 同一商户可同时启用多个公钥（上限 MaxActivePublicKeysPerMerchant），用于零停机轮换
+func (s *publicKeyService) RegisterPublicKey(ctx context.Context, merchantID uuid.UUID, input *RegisterPublicKeyInput) (*model.MerchantPublicKey, error) {
+	algorithm := strings.ToLower(strings.TrimSpace(input.Algorithm))
+	if !pkgcrypto.IsSupportedSigningAlgorithm(algorithm) {
+		return nil, fmt.Errorf("不支持的签名算法: %s", input.Algorithm)
+	}
+
+	// 1. 校验公钥格式与算法
+	if _, err := pkgcrypto.ParseSigningPublicKey(algorithm, input.PublicKey); err != nil {
+		return nil, fmt.Errorf("公钥无效: %w", err)
+	}
+	fingerprint, err := pkgcrypto.PublicKeyFingerprint(input.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("公钥无效: %w", err)
+	}
+
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("过期时间不能早于当前时间")
+	}
+
+	// 2. 检查重复登记
+	exists, err := s.repo.ExistsFingerprint(ctx, merchantID, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("检查公钥失败: %w", err)
+	}
+	if exists {
+		return nil, errors.New("该公钥已登记")
+	}
+
+	// 3. 检查启用数量上限
+	count, err := s.repo.CountActiveByMerchantID(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("统计公钥数量失败: %w", err)
+	}
+	if count >= model.MaxActivePublicKeysPerMerchant {
+		return nil, fmt.Errorf("启用中的公钥数量已达上限(%d)，请先吊销旧公钥", model.MaxActivePublicKeysPerMerchant)
+	}
+
+	key := &model.MerchantPublicKey{
+		MerchantID:  merchantID,
+		KeyID:       "pk_" + generateRandomKey(12),
+		Algorithm:   algorithm,
+		PublicKey:   strings.TrimSpace(input.PublicKey) + "\n",
+		Fingerprint: fingerprint,
+		Name:        input.Name,
+		Environment: input.Environment,
+		IsActive:    true,
+		ExpiresAt:   input.ExpiresAt,
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListPublicKeys 获取商户的所有公钥
+func (s *publicKeyService) ListPublicKeys(ctx context.Context, merchantID uuid.UUID) ([]*model.MerchantPublicKey, error) {
+	return s.repo.GetByMerchantID(ctx, merchantID)
+}
+
+// RevokePublicKey 吊销公钥
+func (s *publicKeyService) RevokePublicKey(ctx context.Context, merchantID uuid.UUID, id uuid.UUID) error {
+	// 验证公钥是否属于该商户 (安全检查)
+	_, err := s.repo.GetByIDAndMerchantID(ctx, id, merchantID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("公钥不存在或不属于该商户")
+		}
+		return fmt.Errorf("验证公钥归属失败: %w", err)
+	}
+
+	return s.repo.Revoke(ctx, id)
+}
+
+// GetActivePublicKey 根据公钥ID获取启用中且未过期的公钥（供 payment-gateway 验签使用）
+func (s *publicKeyService) GetActivePublicKey(ctx context.Context, keyID string) (*model.MerchantPublicKey, error) {
+	key, err := s.repo.GetByKeyID(ctx, keyID)
+	if err != nil {
+		return nil, errors.New("invalid key id")
+	}
+
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, errors.New("public key expired")
+	}
+
+	// 更新最后使用时间（异步）
+	go func() {
+		ctx := context.Background()
+		s.repo.UpdateLastUsedAt(ctx, key.ID)
+	}()
+
+	return key, nil
+}
@@ -112,6 +112,22 @@ func (c *ServiceClient) Delete(ctx context.Context, path string) (map[string]int
 	return result, resp.StatusCode, nil
 }
 
+// Download 发送GET请求并返回原始响应体（用于 PDF 等文件下载）
+func (c *ServiceClient) Download(ctx context.Context, path string, queryParams map[string]string) ([]byte, string, int, error) {
+	fullURL := c.buildURL(path, queryParams)
+
+	resp, err := c.httpClient.Do(&httpclient.Request{
+		Method: "GET",
+		URL:    fullURL,
+		Ctx:    ctx,
+	})
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	return resp.Body, resp.Headers.Get("Content-Type"), resp.StatusCode, nil
+}
+
 // buildURL 构建完整URL
 func (c *ServiceClient) buildURL(path string, queryParams map[string]string) string {
 	fullURL := c.baseURL + path
@@ -24,6 +24,7 @@ func (h *AccountingBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware
 		merchant.GET("/balance", h.GetBalance)
 		merchant.GET("/transactions", h.ListTransactions)
 		merchant.GET("/invoices", h.ListInvoices)
+		merchant.GET("/invoices/:invoice_no/pdf", h.DownloadInvoicePDF)
 	}
 }
 
@@ -97,3 +98,18 @@ func (h *AccountingBFFHandler) ListInvoices(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// DownloadInvoicePDF 下载账单 PDF
+func (h *AccountingBFFHandler) DownloadInvoicePDF(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"lang":        c.Query("lang"),
+	}
+	proxyDownload(c, h.accountingClient, "/api/v1/invoices/"+c.Param("invoice_no")+"/pdf", queryParams)
+}
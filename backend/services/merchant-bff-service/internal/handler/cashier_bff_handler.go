@@ -24,6 +24,8 @@ func (h *CashierBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gi
 		cashier.GET("/templates", h.ListTemplates)
 		cashier.PUT("/preference", h.UpdatePreference)
 		cashier.GET("/preview", h.PreviewCashier)
+		cashier.GET("/analytics/funnel", h.GetFunnel)
+		cashier.GET("/analytics/templates/compare", h.CompareTemplates)
 	}
 }
 
@@ -93,3 +95,57 @@ func (h *CashierBFFHandler) PreviewCashier(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// GetFunnel 获取本商户收银台转化漏斗
+func (h *CashierBFFHandler) GetFunnel(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+	}
+	for _, key := range []string{"dimension", "template_id", "start_time", "end_time"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := h.cashierClient.Get(c.Request.Context(), "/api/v1/service/cashier/funnel", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// CompareTemplates 对比本商户两个收银台模板的转化效果
+func (h *CashierBFFHandler) CompareTemplates(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"template_a":  c.Query("template_a"),
+		"template_b":  c.Query("template_b"),
+	}
+	for _, key := range []string{"start_time", "end_time"} {
+		if value := c.Query(key); value != "" {
+			queryParams[key] = value
+		}
+	}
+
+	result, statusCode, err := h.cashierClient.Get(c.Request.Context(), "/api/v1/service/cashier/templates/compare", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
@@ -26,6 +26,11 @@ func (h *NotificationBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddlewa
 		notifications.GET("/webhooks", h.GetWebhookConfig)
 		notifications.PUT("/webhooks", h.UpdateWebhookConfig)
 		notifications.POST("/webhooks/test", h.TestWebhook)
+		notifications.GET("/webhooks/events", h.ListWebhookEvents)
+		notifications.PUT("/webhooks/endpoints/:id/subscriptions", h.UpdateWebhookSubscriptions)
+		notifications.GET("/webhooks/stats", h.GetWebhookStats)
+		notifications.GET("/webhooks/deliveries", h.ListWebhookDeliveries)
+		notifications.POST("/webhooks/deliveries/replay", h.ReplayWebhookDeliveries)
 	}
 }
 
@@ -146,3 +151,119 @@ func (h *NotificationBFFHandler) TestWebhook(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ListWebhookEvents 获取可订阅的 Webhook 事件目录（含 JSON Schema）
+func (h *NotificationBFFHandler) ListWebhookEvents(c *gin.Context) {
+	result, statusCode, err := h.notificationClient.Get(c.Request.Context(), "/api/v1/webhooks/events", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// UpdateWebhookSubscriptions 更新端点订阅的事件类型
+func (h *NotificationBFFHandler) UpdateWebhookSubscriptions(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	endpointID := c.Param("id")
+	if endpointID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "端点ID不能为空"})
+		return
+	}
+
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req["merchant_id"] = merchantID
+
+	result, statusCode, err := h.notificationClient.Put(c.Request.Context(), "/api/v1/webhooks/endpoints/"+endpointID+"/subscriptions", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// GetWebhookStats 获取各端点的投递成功率和耗时统计
+func (h *NotificationBFFHandler) GetWebhookStats(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"endpoint_id": c.DefaultQuery("endpoint_id", ""),
+		"hours":       c.DefaultQuery("hours", "24"),
+	}
+
+	result, statusCode, err := h.notificationClient.Get(c.Request.Context(), "/api/v1/webhooks/stats", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// ListWebhookDeliveries 列出 Webhook 投递记录
+func (h *NotificationBFFHandler) ListWebhookDeliveries(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"endpoint_id": c.DefaultQuery("endpoint_id", ""),
+		"event_type":  c.DefaultQuery("event_type", ""),
+		"status":      c.DefaultQuery("status", ""),
+		"page":        c.DefaultQuery("page", "1"),
+		"page_size":   c.DefaultQuery("page_size", "20"),
+	}
+
+	result, statusCode, err := h.notificationClient.Get(c.Request.Context(), "/api/v1/webhooks/deliveries", queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// ReplayWebhookDeliveries 按时间范围/事件类型批量重放投递
+func (h *NotificationBFFHandler) ReplayWebhookDeliveries(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	var req map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req["merchant_id"] = merchantID
+
+	result, statusCode, err := h.notificationClient.Post(c.Request.Context(), "/api/v1/webhooks/deliveries/replay", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(statusCode, result)
+}
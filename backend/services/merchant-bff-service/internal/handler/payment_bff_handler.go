@@ -24,6 +24,8 @@ func (h *PaymentBFFHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gi
 		merchant.GET("", h.ListPayments)
 		merchant.GET("/:payment_no", h.GetPayment)
 		merchant.POST("/:payment_no/refund", h.CreateRefund)
+		merchant.GET("/:payment_no/receipt", h.DownloadPaymentReceipt)
+		merchant.GET("/refunds/:refund_no/receipt", h.DownloadRefundReceipt)
 		merchant.GET("/statistics", h.GetStatistics)
 	}
 }
@@ -132,3 +134,44 @@ func (h *PaymentBFFHandler) GetStatistics(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// DownloadPaymentReceipt 下载支付收据 PDF
+func (h *PaymentBFFHandler) DownloadPaymentReceipt(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"lang":        c.Query("lang"),
+	}
+	proxyDownload(c, h.paymentClient, "/api/v1/payments/"+c.Param("payment_no")+"/receipt", queryParams)
+}
+
+// DownloadRefundReceipt 下载退款凭证 PDF
+func (h *PaymentBFFHandler) DownloadRefundReceipt(c *gin.Context) {
+	merchantID := c.GetString("merchant_id")
+	if merchantID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未找到商户ID"})
+		return
+	}
+
+	queryParams := map[string]string{
+		"merchant_id": merchantID,
+		"lang":        c.Query("lang"),
+	}
+	proxyDownload(c, h.paymentClient, "/api/v1/refunds/"+c.Param("refund_no")+"/receipt", queryParams)
+}
+
+// proxyDownload 透传下游服务的文件响应（错误时下游返回 JSON，同样原样透传）
+func proxyDownload(c *gin.Context, serviceClient *client.ServiceClient, path string, queryParams map[string]string) {
+	body, contentType, statusCode, err := serviceClient.Download(c.Request.Context(), path, queryParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(statusCode, contentType, body)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"github.com/stretchr/testify/assert"
 
 	"payment-platform/merchant-policy-service/internal/model"
@@ -118,7 +119,7 @@ func TestFeePolicyManagement(t *testing.T) {
 			}
 
 			assert.NotNil(t, matchedRule, "应找到匹配的阶梯规则")
-			assert.Equal(t, tc.expectedRate, matchedRule.Percentage)
+			assert.Equal(t, tc.expectedRate, matchedRule.Percentage.Float64())
 		}
 	})
 
@@ -455,7 +456,7 @@ func BenchmarkTieredRuleMatching(b *testing.B) {
 		rules[i] = service.TieredRule{
 			MinAmount:  minAmount,
 			MaxAmount:  &maxAmount,
-			Percentage: 0.029 - float64(i)*0.0001, // 费率递减
+			Percentage: money.NewDecimal(290-int64(i), 4), // 费率递减
 		}
 	}
 
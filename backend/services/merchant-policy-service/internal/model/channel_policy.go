@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -35,8 +36,8 @@ type ChannelPolicy struct {
 	// }
 
 	// 费率覆盖 (可选,覆盖全局费率策略)
-	CustomFeePercentage *float64 `gorm:"type:decimal(5,4)" json:"custom_fee_percentage,omitempty"`
-	CustomFeeFixed      *int64   `gorm:"type:bigint" json:"custom_fee_fixed,omitempty"`
+	CustomFeePercentage *money.Decimal `gorm:"type:decimal(5,4)" json:"custom_fee_percentage,omitempty"`
+	CustomFeeFixed      *int64         `gorm:"type:bigint" json:"custom_fee_fixed,omitempty"`
 
 	// 生效时间
 	EffectiveDate time.Time  `gorm:"type:timestamptz;not null;default:now()" json:"effective_date"`
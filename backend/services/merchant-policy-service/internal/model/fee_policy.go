@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -21,11 +22,11 @@ type MerchantFeePolicy struct {
 	Currency      string `gorm:"type:varchar(10);not null;default:'USD'" json:"currency"` // 币种
 
 	// 费率配置
-	FeeType       string  `gorm:"type:varchar(20);not null" json:"fee_type"`         // percentage, fixed, tiered
-	FeePercentage float64 `gorm:"type:decimal(5,4);default:0" json:"fee_percentage"` // 费率百分比（如0.029表示2.9%）
-	FeeFixed      int64   `gorm:"type:bigint;default:0" json:"fee_fixed"`            // 固定费用（分）
-	MinFee        int64   `gorm:"type:bigint;default:0" json:"min_fee"`              // 最小费用（分）
-	MaxFee        *int64  `gorm:"type:bigint" json:"max_fee,omitempty"`              // 最大费用（分，null表示无上限）
+	FeeType       string        `gorm:"type:varchar(20);not null" json:"fee_type"`         // percentage, fixed, tiered
+	FeePercentage money.Decimal `gorm:"type:decimal(5,4);default:0" json:"fee_percentage"` // 费率百分比（如0.029表示2.9%）
+	FeeFixed      int64         `gorm:"type:bigint;default:0" json:"fee_fixed"`            // 固定费用（分）
+	MinFee        int64         `gorm:"type:bigint;default:0" json:"min_fee"`              // 最小费用（分）
+	MaxFee        *int64        `gorm:"type:bigint" json:"max_fee,omitempty"`              // 最大费用（分，null表示无上限）
 
 	// 阶梯费率规则 (当FeeType=tiered时使用)
 	TieredRules string `gorm:"type:jsonb" json:"tiered_rules,omitempty"`
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"payment-platform/merchant-policy-service/internal/model"
 	"payment-platform/merchant-policy-service/internal/repository"
 )
@@ -52,13 +53,13 @@ func NewPolicyEngineService(
 type TieredRule struct {
 	MinAmount  int64    `json:"min_amount"`            // 最小金额（分）
 	MaxAmount  *int64   `json:"max_amount,omitempty"`  // 最大金额（分，null表示无上限）
-	Percentage float64  `json:"percentage"`            // 费率百分比
+	Percentage money.Decimal `json:"percentage"`           // 费率百分比
 }
 
 // FeeCalculationResult 费用计算结果
 type FeeCalculationResult struct {
 	FeeAmount        int64                      `json:"fee_amount"`         // 费用金额（分）
-	FeePercentage    float64                    `json:"fee_percentage"`     // 费率百分比
+	FeePercentage    money.Decimal              `json:"fee_percentage"`     // 费率百分比
 	FeeFixed         int64                      `json:"fee_fixed"`          // 固定费用（分）
 	FeeType          string                     `json:"fee_type"`           // 费率类型
 	AppliedPolicy    *model.MerchantFeePolicy   `json:"applied_policy"`     // 应用的策略
@@ -159,8 +160,12 @@ func (s *policyEngineService) CalculateFee(ctx context.Context, merchantID uuid.
 	// 根据费率类型计算费用
 	switch policy.FeeType {
 	case model.FeeTypePercentage:
-		result.FeeAmount = int64(float64(amount) * policy.FeePercentage)
-		result.CalculationNotes = fmt.Sprintf("百分比费率: %.2f%%", policy.FeePercentage*100)
+		fee, err := money.New(amount, currency).Mul(policy.FeePercentage, money.RoundHalfUp)
+		if err != nil {
+			return nil, fmt.Errorf("计算百分比费用失败: %w", err)
+		}
+		result.FeeAmount = fee.Amount
+		result.CalculationNotes = fmt.Sprintf("百分比费率: %s%%", formatPercent(policy.FeePercentage))
 
 	case model.FeeTypeFixed:
 		result.FeeAmount = policy.FeeFixed
@@ -183,14 +188,18 @@ func (s *policyEngineService) CalculateFee(ctx context.Context, merchantID uuid.
 			return nil, fmt.Errorf("未找到匹配的阶梯费率规则（金额: %d）", amount)
 		}
 
-		result.FeeAmount = int64(float64(amount) * matchedRule.Percentage)
+		fee, err := money.New(amount, currency).Mul(matchedRule.Percentage, money.RoundHalfUp)
+		if err != nil {
+			return nil, fmt.Errorf("计算阶梯费用失败: %w", err)
+		}
+		result.FeeAmount = fee.Amount
 		result.FeePercentage = matchedRule.Percentage
 		if matchedRule.MaxAmount != nil {
-			result.CalculationNotes = fmt.Sprintf("阶梯费率: 金额区间 [%d, %d], 费率 %.2f%%",
-				matchedRule.MinAmount, *matchedRule.MaxAmount, matchedRule.Percentage*100)
+			result.CalculationNotes = fmt.Sprintf("阶梯费率: 金额区间 [%d, %d], 费率 %s%%",
+				matchedRule.MinAmount, *matchedRule.MaxAmount, formatPercent(matchedRule.Percentage))
 		} else {
-			result.CalculationNotes = fmt.Sprintf("阶梯费率: 金额区间 [%d, +∞), 费率 %.2f%%",
-				matchedRule.MinAmount, matchedRule.Percentage*100)
+			result.CalculationNotes = fmt.Sprintf("阶梯费率: 金额区间 [%d, +∞), 费率 %s%%",
+				matchedRule.MinAmount, formatPercent(matchedRule.Percentage))
 		}
 
 	default:
@@ -259,6 +268,11 @@ func (s *policyEngineService) CheckLimit(ctx context.Context, merchantID uuid.UU
 	return result, nil
 }
 
+// formatPercent 将费率格式化为百分数，如 0.029 -> "2.90"
+func formatPercent(rate money.Decimal) string {
+	return rate.Mul(money.DecimalFromInt(100)).Round(2, money.RoundHalfUp).String()
+}
+
 // findMatchingTieredRule 根据金额找到匹配的阶梯费率规则
 func findMatchingTieredRule(rules []TieredRule, amount int64) *TieredRule {
 	for i := range rules {
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -690,6 +691,266 @@ func (h *NotificationHandler) DeleteWebhookEndpoint(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// RotateWebhookSecretRequest 轮换 Webhook 密钥请求
+type RotateWebhookSecretRequest struct {
+	OverlapHours int `json:"overlap_hours" binding:"min=0,max=168"` // 新旧密钥同时生效的小时数（0表示旧密钥立即失效）
+}
+
+// RotateWebhookSecret 轮换 Webhook 签名密钥
+// @Summary 轮换 Webhook 签名密钥
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "端点ID"
+// @Param request body RotateWebhookSecretRequest true "轮换参数"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/webhooks/endpoints/{id}/rotate-secret [post]
+func (h *NotificationHandler) RotateWebhookSecret(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的端点ID", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	var req RotateWebhookSecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	// 从上下文获取商户ID
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeUnauthorized, "未认证", "").WithTraceID(traceID)
+		c.JSON(http.StatusUnauthorized, resp)
+		return
+	}
+
+	endpoint, err := h.notificationService.RotateWebhookSecret(c.Request.Context(), merchantID.(uuid.UUID), id, time.Duration(req.OverlapHours)*time.Hour)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "轮换 Webhook 密钥失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(gin.H{
+		"endpoint_id":                endpoint.ID,
+		"secret":                     endpoint.Secret,
+		"previous_secret_expires_at": endpoint.PreviousSecretExpiresAt,
+	}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListWebhookEvents 获取 Webhook 事件目录
+// @Summary 获取 Webhook 事件目录
+// @Tags Webhook
+// @Produce json
+// @Success 200 {array} model.WebhookEventDefinition
+// @Router /api/v1/webhooks/events [get]
+func (h *NotificationHandler) ListWebhookEvents(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(model.ListWebhookEvents()).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateWebhookSubscriptionsRequest 更新端点订阅请求
+type UpdateWebhookSubscriptionsRequest struct {
+	Events []string `json:"events" binding:"required,min=1"` // 订阅的事件类型，支持 "*" 和 "payment.*"
+}
+
+// UpdateWebhookSubscriptions 更新端点订阅的事件类型
+// @Summary 更新端点订阅的事件类型
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "端点ID"
+// @Param request body UpdateWebhookSubscriptionsRequest true "订阅事件"
+// @Success 200 {object} model.WebhookEndpoint
+// @Router /api/v1/webhooks/endpoints/{id}/subscriptions [put]
+func (h *NotificationHandler) UpdateWebhookSubscriptions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的端点ID", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	var req UpdateWebhookSubscriptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	// 从上下文获取商户ID
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeUnauthorized, "未认证", "").WithTraceID(traceID)
+		c.JSON(http.StatusUnauthorized, resp)
+		return
+	}
+
+	endpoint, err := h.notificationService.UpdateWebhookSubscriptions(c.Request.Context(), merchantID.(uuid.UUID), id, req.Events)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "更新订阅事件失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(endpoint).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWebhookEndpointStats 获取端点投递统计
+// @Summary 获取端点投递统计（成功率、平均/P95耗时）
+// @Tags Webhook
+// @Produce json
+// @Param endpoint_id query string false "端点ID（为空表示所有端点）"
+// @Param hours query int false "统计最近多少小时（默认24，最大720）"
+// @Success 200 {array} repository.EndpointDeliveryStats
+// @Router /api/v1/webhooks/stats [get]
+func (h *NotificationHandler) GetWebhookEndpointStats(c *gin.Context) {
+	// 从上下文获取商户ID
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeUnauthorized, "未认证", "").WithTraceID(traceID)
+		c.JSON(http.StatusUnauthorized, resp)
+		return
+	}
+
+	var endpointID *uuid.UUID
+	if endpointIDStr := c.Query("endpoint_id"); endpointIDStr != "" {
+		id, err := uuid.Parse(endpointIDStr)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的端点ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		endpointID = &id
+	}
+
+	hours := 24
+	if hoursStr := c.Query("hours"); hoursStr != "" {
+		if v, err := strconv.Atoi(hoursStr); err == nil && v > 0 && v <= 720 {
+			hours = v
+		}
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	stats, err := h.notificationService.GetWebhookEndpointStats(c.Request.Context(), merchantID.(uuid.UUID), endpointID, since)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "查询端点投递统计失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(gin.H{
+		"since": since,
+		"hours": hours,
+		"stats": stats,
+	}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReplayWebhookDeliveriesRequest 批量重放请求
+type ReplayWebhookDeliveriesRequest struct {
+	EndpointID string    `json:"endpoint_id"`                   // 端点ID（可选）
+	EventType  string    `json:"event_type"`                    // 事件类型（可选）
+	Status     string    `json:"status"`                        // 投递状态（可选，默认 failed）
+	StartTime  time.Time `json:"start_time" binding:"required"` // 开始时间（RFC3339）
+	EndTime    time.Time `json:"end_time" binding:"required"`   // 结束时间（RFC3339）
+}
+
+// ReplayWebhookDeliveries 批量重放 Webhook 投递
+// @Summary 按时间范围/事件类型批量重放 Webhook 投递
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body ReplayWebhookDeliveriesRequest true "重放条件"
+// @Success 200 {object} service.ReplayWebhookDeliveriesResult
+// @Router /api/v1/webhooks/deliveries/replay [post]
+func (h *NotificationHandler) ReplayWebhookDeliveries(c *gin.Context) {
+	var req ReplayWebhookDeliveriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的请求参数", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	// 从上下文获取商户ID
+	merchantID, exists := c.Get("merchant_id")
+	if !exists {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeUnauthorized, "未认证", "").WithTraceID(traceID)
+		c.JSON(http.StatusUnauthorized, resp)
+		return
+	}
+
+	replayReq := &service.ReplayWebhookDeliveriesRequest{
+		MerchantID: merchantID.(uuid.UUID),
+		EventType:  req.EventType,
+		Status:     req.Status,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+	}
+	if req.EndpointID != "" {
+		endpointID, err := uuid.Parse(req.EndpointID)
+		if err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的端点ID", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		replayReq.EndpointID = &endpointID
+	}
+
+	result, err := h.notificationService.ReplayWebhookDeliveries(c.Request.Context(), replayReq)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "重放 Webhook 投递失败", err.Error()).WithTraceID(traceID)
+			c.JSON(http.StatusBadRequest, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	resp := errors.NewSuccessResponse(result).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
 // ListWebhookDeliveries 列出 Webhook 投递记录
 // @Summary 列出 Webhook 投递记录
 // @Tags Webhook
@@ -1024,9 +1285,14 @@ func (h *NotificationHandler) RegisterRoutes(router *gin.Engine, authMiddleware
 		api.GET("/webhooks/endpoints", h.ListWebhookEndpoints)
 		api.PUT("/webhooks/endpoints/:id", h.UpdateWebhookEndpoint)
 		api.DELETE("/webhooks/endpoints/:id", h.DeleteWebhookEndpoint)
+		api.POST("/webhooks/endpoints/:id/rotate-secret", h.RotateWebhookSecret)
+		api.PUT("/webhooks/endpoints/:id/subscriptions", h.UpdateWebhookSubscriptions)
+		api.GET("/webhooks/events", h.ListWebhookEvents)
+		api.GET("/webhooks/stats", h.GetWebhookEndpointStats)
 
 		// Webhook 投递记录
 		api.GET("/webhooks/deliveries", h.ListWebhookDeliveries)
+		api.POST("/webhooks/deliveries/replay", h.ReplayWebhookDeliveries)
 
 		// 通知偏好设置
 		api.POST("/preferences", h.CreatePreference)
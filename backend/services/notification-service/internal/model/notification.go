@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -65,21 +67,27 @@ func (NotificationTemplate) TableName() string {
 
 // WebhookEndpoint Webhook 端点配置表
 type WebhookEndpoint struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	MerchantID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`   // 商户ID
-	Name        string         `gorm:"type:varchar(200)" json:"name"`                 // 端点名称
-	URL         string         `gorm:"type:varchar(500);not null" json:"url"`         // Webhook URL
-	Secret      string         `gorm:"type:varchar(200)" json:"secret"`               // 签名密钥（加密存储）
-	Events      string         `gorm:"type:jsonb" json:"events"`                      // 订阅的事件列表
-	IsEnabled   bool           `gorm:"default:true" json:"is_enabled"`                // 是否启用
-	Version     string         `gorm:"type:varchar(20);default:'v1'" json:"version"`  // API版本
-	Timeout     int            `gorm:"default:30" json:"timeout"`                     // 超时时间（秒）
-	MaxRetry    int            `gorm:"default:3" json:"max_retry"`                    // 最大重试次数
-	Description string         `gorm:"type:text" json:"description"`                  // 描述
-	Extra       string         `gorm:"type:jsonb" json:"extra"`                       // 扩展信息
-	CreatedAt   time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
-	UpdatedAt   time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                      uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID              uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`        // 商户ID
+	Name                    string         `gorm:"type:varchar(200)" json:"name"`                      // 端点名称
+	URL                     string         `gorm:"type:varchar(500);not null" json:"url"`              // Webhook URL
+	Secret                  string         `gorm:"type:varchar(200)" json:"secret"`                    // 签名密钥（加密存储）
+	Events                  string         `gorm:"type:jsonb" json:"events"`                           // 订阅的事件列表
+	IsEnabled               bool           `gorm:"default:true" json:"is_enabled"`                     // 是否启用
+	Version                 string         `gorm:"type:varchar(20);default:'v1'" json:"version"`       // 固定的负载版本（v1, v2），见 pkg/webhook
+	PreviousSecret          string         `gorm:"type:varchar(200)" json:"-"`                         // 轮换前的旧密钥（轮换过渡期内同时签名）
+	PreviousSecretExpiresAt *time.Time     `gorm:"type:timestamptz" json:"previous_secret_expires_at"` // 旧密钥失效时间
+	ConsecutiveFailures     int            `gorm:"default:0" json:"consecutive_failures"`              // 连续投递失败次数（投递成功后清零）
+	FailingSince            *time.Time     `gorm:"type:timestamptz" json:"failing_since"`              // 本轮连续失败的开始时间
+	DisabledAt              *time.Time     `gorm:"type:timestamptz" json:"disabled_at"`                // 被自动停用的时间
+	DisabledReason          string         `gorm:"type:varchar(500)" json:"disabled_reason"`           // 停用原因
+	Timeout                 int            `gorm:"default:30" json:"timeout"`                          // 超时时间（秒）
+	MaxRetry                int            `gorm:"default:3" json:"max_retry"`                         // 最大重试次数
+	Description             string         `gorm:"type:text" json:"description"`                       // 描述
+	Extra                   string         `gorm:"type:jsonb" json:"extra"`                            // 扩展信息
+	CreatedAt               time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt               time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+	DeletedAt               gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
@@ -87,23 +95,62 @@ func (WebhookEndpoint) TableName() string {
 	return "webhook_endpoints"
 }
 
+// SigningSecrets 返回当前用于签名的密钥（当前密钥在前，轮换过渡期内包含旧密钥）
+func (e *WebhookEndpoint) SigningSecrets(now time.Time) []string {
+	secrets := []string{e.Secret}
+	if e.PreviousSecret != "" && e.PreviousSecretExpiresAt != nil && now.Before(*e.PreviousSecretExpiresAt) {
+		secrets = append(secrets, e.PreviousSecret)
+	}
+	return secrets
+}
+
+// SubscribedEvents 返回端点订阅的事件类型列表
+func (e *WebhookEndpoint) SubscribedEvents() []string {
+	var events []string
+	if e.Events != "" {
+		json.Unmarshal([]byte(e.Events), &events)
+	}
+	return events
+}
+
+// IsSubscribed 检查端点是否订阅了指定事件
+// 支持 "*"（全部事件）和 "payment.*"（某一类事件）两种通配
+func (e *WebhookEndpoint) IsSubscribed(eventType string) bool {
+	for _, event := range e.SubscribedEvents() {
+		if event == "*" || event == eventType {
+			return true
+		}
+		if strings.HasSuffix(event, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(event, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// 端点自动停用阈值：连续失败次数达到阈值且持续失败超过时间窗口时自动停用
+const (
+	WebhookDisableFailureThreshold = 20
+	WebhookDisableFailureWindow    = 24 * time.Hour
+)
+
 // WebhookDelivery Webhook 投递记录表
 type WebhookDelivery struct {
-	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	EndpointID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"endpoint_id"`    // 端点ID
-	MerchantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"merchant_id"`    // 商户ID
-	EventType     string     `gorm:"type:varchar(100);not null" json:"event_type"`   // 事件类型
-	EventID       string     `gorm:"type:varchar(200);index" json:"event_id"`        // 事件ID
-	Payload       string     `gorm:"type:jsonb;not null" json:"payload"`             // 事件数据
-	Status        string     `gorm:"type:varchar(20);not null;index" json:"status"`  // 状态
-	HTTPStatus    int        `gorm:"default:0" json:"http_status"`                   // HTTP状态码
-	ResponseBody  string     `gorm:"type:text" json:"response_body"`                 // 响应内容
-	ErrorMessage  string     `gorm:"type:text" json:"error_message"`                 // 错误信息
-	RetryCount    int        `gorm:"default:0" json:"retry_count"`                   // 重试次数
-	Duration      int        `gorm:"default:0" json:"duration"`                      // 请求耗时（毫秒）
-	NextRetryAt   *time.Time `gorm:"type:timestamptz" json:"next_retry_at"`          // 下次重试时间
-	DeliveredAt   *time.Time `gorm:"type:timestamptz" json:"delivered_at"`           // 投递时间
-	CreatedAt     time.Time  `gorm:"type:timestamptz;default:now();index" json:"created_at"`
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EndpointID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"endpoint_id"`   // 端点ID
+	MerchantID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"merchant_id"`   // 商户ID
+	EventType    string     `gorm:"type:varchar(100);not null" json:"event_type"`  // 事件类型
+	EventID      string     `gorm:"type:varchar(200);index" json:"event_id"`       // 事件ID
+	Payload      string     `gorm:"type:jsonb;not null" json:"payload"`            // 事件数据
+	Status       string     `gorm:"type:varchar(20);not null;index" json:"status"` // 状态
+	HTTPStatus   int        `gorm:"default:0" json:"http_status"`                  // HTTP状态码
+	ResponseBody string     `gorm:"type:text" json:"response_body"`                // 响应内容
+	ErrorMessage string     `gorm:"type:text" json:"error_message"`                // 错误信息
+	RetryCount   int        `gorm:"default:0" json:"retry_count"`                  // 重试次数
+	Duration     int        `gorm:"default:0" json:"duration"`                     // 请求耗时（毫秒）
+	ReplayOf     *uuid.UUID `gorm:"type:uuid;index" json:"replay_of,omitempty"`    // 重放来源投递ID（重放生成的投递才有）
+	NextRetryAt  *time.Time `gorm:"type:timestamptz" json:"next_retry_at"`         // 下次重试时间
+	DeliveredAt  *time.Time `gorm:"type:timestamptz" json:"delivered_at"`          // 投递时间
+	CreatedAt    time.Time  `gorm:"type:timestamptz;default:now();index" json:"created_at"`
 }
 
 // TableName 指定表名
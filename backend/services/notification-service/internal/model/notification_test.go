@@ -0,0 +1,48 @@
+package model
+
+import "testing"
+
+func TestWebhookEndpointIsSubscribed(t *testing.T) {
+	tests := []struct {
+		events    string
+		eventType string
+		want      bool
+	}{
+		{`["*"]`, WebhookEventRefundSuccess, true},
+		{`["payment.success"]`, WebhookEventPaymentSuccess, true},
+		{`["payment.success"]`, WebhookEventPaymentFailed, false},
+		{`["payment.*"]`, WebhookEventPaymentExpired, true},
+		{`["payment.*"]`, WebhookEventRefundSuccess, false},
+		// 分类通配必须匹配到 "." 为止，不能误匹配前缀相同的其他分类
+		{`["order.*"]`, "orders.created", false},
+		{`["refund.failed", "settlement.*"]`, WebhookEventSettlementCompleted, true},
+		{``, WebhookEventPaymentSuccess, false},
+	}
+
+	for _, tt := range tests {
+		endpoint := &WebhookEndpoint{Events: tt.events}
+		if got := endpoint.IsSubscribed(tt.eventType); got != tt.want {
+			t.Errorf("IsSubscribed(%s) with events %s = %v, want %v", tt.eventType, tt.events, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidWebhookSubscription(t *testing.T) {
+	tests := []struct {
+		event string
+		want  bool
+	}{
+		{"*", true},
+		{WebhookEventPaymentSuccess, true},
+		{"refund.*", true},
+		{"payment.unknown", false},
+		{"payout.*", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValidWebhookSubscription(tt.event); got != tt.want {
+			t.Errorf("IsValidWebhookSubscription(%q) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// WebhookEventDefinition Webhook 事件目录中的一个事件
+type WebhookEventDefinition struct {
+	Type        string                 `json:"type"`        // 事件类型
+	Category    string                 `json:"category"`    // 事件分类（payment, refund, order, settlement）
+	Description string                 `json:"description"` // 事件说明
+	Schema      map[string]interface{} `json:"schema"`      // 业务对象的 JSON Schema（v1 中为 data，v2 中为 data.object）
+}
+
+// Webhook 事件分类
+const (
+	WebhookEventCategoryPayment    = "payment"
+	WebhookEventCategoryRefund     = "refund"
+	WebhookEventCategoryOrder      = "order"
+	WebhookEventCategorySettlement = "settlement"
+)
+
+// 商户可订阅的 Webhook 事件类型（与 payment-gateway 等服务发出的事件类型保持一致）
+const (
+	WebhookEventPaymentCreated      = "payment.created"
+	WebhookEventPaymentSuccess      = "payment.success"
+	WebhookEventPaymentFailed       = "payment.failed"
+	WebhookEventPaymentCancelled    = "payment.cancelled"
+	WebhookEventPaymentExpired      = "payment.expired"
+	WebhookEventRefundSuccess       = "refund.success"
+	WebhookEventRefundFailed        = "refund.failed"
+	WebhookEventRefundCompleted     = "refund.completed"
+	WebhookEventOrderCreated        = "order.created"
+	WebhookEventOrderCancelled      = "order.cancelled"
+	WebhookEventSettlementCompleted = "settlement.completed"
+)
+
+// paymentObjectSchema 支付对象结构（payment-gateway notifyMerchant 发出的字段）
+func paymentObjectSchema() map[string]interface{} {
+	return objectSchema([]string{"payment_no", "order_no", "merchant_id", "amount", "currency", "status"}, map[string]interface{}{
+		"payment_no":       stringSchema("支付流水号"),
+		"order_no":         stringSchema("商户订单号"),
+		"merchant_id":      uuidSchema("商户ID"),
+		"amount":           amountSchema(),
+		"currency":         currencySchema(),
+		"status":           enumSchema("支付状态", "pending", "processing", "success", "failed", "cancelled", "expired"),
+		"channel":          stringSchema("支付渠道"),
+		"channel_order_no": stringSchema("渠道订单号"),
+		"paid_at":          nullableTimeSchema("支付时间"),
+		"error_code":       stringSchema("错误码（失败时）"),
+		"error_msg":        stringSchema("错误信息（失败时）"),
+	})
+}
+
+// refundObjectSchema 退款对象结构
+func refundObjectSchema() map[string]interface{} {
+	return objectSchema([]string{"refund_no", "payment_no", "merchant_id", "amount", "currency", "status"}, map[string]interface{}{
+		"refund_no":   stringSchema("退款流水号"),
+		"payment_no":  stringSchema("原支付流水号"),
+		"order_no":    stringSchema("商户订单号"),
+		"merchant_id": uuidSchema("商户ID"),
+		"amount":      amountSchema(),
+		"currency":    currencySchema(),
+		"status":      enumSchema("退款状态", "pending", "processing", "success", "failed"),
+		"reason":      stringSchema("退款原因"),
+		"refunded_at": nullableTimeSchema("退款完成时间"),
+		"error_code":  stringSchema("错误码（失败时）"),
+		"error_msg":   stringSchema("错误信息（失败时）"),
+	})
+}
+
+// orderObjectSchema 订单对象结构
+func orderObjectSchema() map[string]interface{} {
+	return objectSchema([]string{"order_no", "merchant_id", "amount", "currency", "status"}, map[string]interface{}{
+		"order_no":    stringSchema("订单号"),
+		"merchant_id": uuidSchema("商户ID"),
+		"amount":      amountSchema(),
+		"currency":    currencySchema(),
+		"status":      stringSchema("订单状态"),
+		"created_at":  nullableTimeSchema("创建时间"),
+	})
+}
+
+// settlementObjectSchema 结算对象结构
+func settlementObjectSchema() map[string]interface{} {
+	return objectSchema([]string{"settlement_no", "merchant_id", "amount", "currency", "status"}, map[string]interface{}{
+		"settlement_no": stringSchema("结算单号"),
+		"merchant_id":   uuidSchema("商户ID"),
+		"amount":        amountSchema(),
+		"fee":           map[string]interface{}{"type": "integer", "description": "手续费（最小货币单位）"},
+		"currency":      currencySchema(),
+		"status":        stringSchema("结算状态"),
+		"period_start":  nullableTimeSchema("结算周期开始"),
+		"period_end":    nullableTimeSchema("结算周期结束"),
+		"settled_at":    nullableTimeSchema("结算完成时间"),
+	})
+}
+
+// webhookEventCatalog 事件目录（按事件类型索引）
+var webhookEventCatalog = map[string]*WebhookEventDefinition{
+	WebhookEventPaymentCreated:      {Category: WebhookEventCategoryPayment, Description: "支付单已创建", Schema: paymentObjectSchema()},
+	WebhookEventPaymentSuccess:      {Category: WebhookEventCategoryPayment, Description: "支付成功", Schema: paymentObjectSchema()},
+	WebhookEventPaymentFailed:       {Category: WebhookEventCategoryPayment, Description: "支付失败", Schema: paymentObjectSchema()},
+	WebhookEventPaymentCancelled:    {Category: WebhookEventCategoryPayment, Description: "支付已取消", Schema: paymentObjectSchema()},
+	WebhookEventPaymentExpired:      {Category: WebhookEventCategoryPayment, Description: "支付已过期", Schema: paymentObjectSchema()},
+	WebhookEventRefundSuccess:       {Category: WebhookEventCategoryRefund, Description: "退款成功", Schema: refundObjectSchema()},
+	WebhookEventRefundFailed:        {Category: WebhookEventCategoryRefund, Description: "退款失败", Schema: refundObjectSchema()},
+	WebhookEventRefundCompleted:     {Category: WebhookEventCategoryRefund, Description: "退款已到账", Schema: refundObjectSchema()},
+	WebhookEventOrderCreated:        {Category: WebhookEventCategoryOrder, Description: "订单已创建", Schema: orderObjectSchema()},
+	WebhookEventOrderCancelled:      {Category: WebhookEventCategoryOrder, Description: "订单已取消", Schema: orderObjectSchema()},
+	WebhookEventSettlementCompleted: {Category: WebhookEventCategorySettlement, Description: "结算完成", Schema: settlementObjectSchema()},
+}
+
+func init() {
+	for eventType, def := range webhookEventCatalog {
+		def.Type = eventType
+	}
+}
+
+// ListWebhookEvents 列出事件目录（按事件类型排序）
+func ListWebhookEvents() []*WebhookEventDefinition {
+	events := make([]*WebhookEventDefinition, 0, len(webhookEventCatalog))
+	for _, def := range webhookEventCatalog {
+		events = append(events, def)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Type < events[j].Type })
+	return events
+}
+
+// GetWebhookEvent 获取事件定义，不存在时返回 nil
+func GetWebhookEvent(eventType string) *WebhookEventDefinition {
+	return webhookEventCatalog[eventType]
+}
+
+// IsValidWebhookSubscription 检查订阅项是否有效
+// 订阅项可以是具体事件类型、"*"（全部事件）或 "<分类>.*"（某一类事件）
+func IsValidWebhookSubscription(event string) bool {
+	if event == "*" {
+		return true
+	}
+	if strings.HasSuffix(event, ".*") {
+		category := strings.TrimSuffix(event, ".*")
+		for _, def := range webhookEventCatalog {
+			if def.Category == category {
+				return true
+			}
+		}
+		return false
+	}
+	return webhookEventCatalog[event] != nil
+}
+
+func objectSchema(required []string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+}
+
+func stringSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func uuidSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "format": "uuid", "description": description}
+}
+
+func amountSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": "金额（最小货币单位，如分）"}
+}
+
+func currencySchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string", "pattern": "^[A-Z]{3}$", "description": "货币代码（ISO 4217）"}
+}
+
+func enumSchema(description string, values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": values, "description": description}
+}
+
+func nullableTimeSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": []string{"string", "null"}, "format": "date-time", "description": description}
+}
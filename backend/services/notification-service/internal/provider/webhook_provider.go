@@ -3,14 +3,12 @@ package provider
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/payment-platform/pkg/webhook"
 )
 
 // WebhookProvider Webhook 提供商
@@ -29,13 +27,16 @@ func NewWebhookProvider() *WebhookProvider {
 
 // WebhookRequest Webhook 请求
 type WebhookRequest struct {
-	URL       string                 `json:"url"`        // Webhook URL
-	Secret    string                 `json:"secret"`     // 签名密钥
-	EventType string                 `json:"event_type"` // 事件类型
-	EventID   string                 `json:"event_id"`   // 事件ID
-	Timestamp int64                  `json:"timestamp"`  // 时间戳
-	Data      map[string]interface{} `json:"data"`       // 事件数据
-	Timeout   int                    `json:"timeout"`    // 超时时间（秒）
+	URL        string                 `json:"url"`         // Webhook URL
+	Secret     string                 `json:"secret"`      // 签名密钥
+	Secrets    []string               `json:"-"`           // 轮换过渡期内仍有效的旧密钥（可选）
+	EventType  string                 `json:"event_type"`  // 事件类型
+	EventID    string                 `json:"event_id"`    // 事件ID
+	APIVersion string                 `json:"api_version"` // 负载版本（为空时使用最新版本）
+	Timestamp  int64                  `json:"timestamp"`   // 事件创建时间戳
+	Data       map[string]interface{} `json:"data"`        // 事件数据
+	Timeout    int                    `json:"timeout"`     // 超时时间（秒）
+	Attempt    int                    `json:"attempt"`     // 第几次投递
 }
 
 // WebhookResponse Webhook 响应
@@ -48,18 +49,22 @@ type WebhookResponse struct {
 }
 
 // Send 发送 Webhook
+// 请求体为统一的事件信封（pkg/webhook.Event），签名头格式为 t=<unix秒>,v1=<hex>
 func (p *WebhookProvider) Send(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
 	startTime := time.Now()
 
-	// 构造请求体
-	payload := map[string]interface{}{
-		"event_type": req.EventType,
-		"event_id":   req.EventID,
-		"timestamp":  req.Timestamp,
-		"data":       req.Data,
+	created := startTime
+	if req.Timestamp > 0 {
+		created = time.Unix(req.Timestamp, 0)
 	}
 
-	payloadBytes, err := json.Marshal(payload)
+	// 构造事件信封
+	event, err := webhook.NewEvent(req.EventID, req.EventType, req.APIVersion, created, req.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("构造 Webhook 事件失败: %w", err)
+	}
+
+	payloadBytes, err := event.Marshal()
 	if err != nil {
 		return nil, fmt.Errorf("序列化 Webhook 数据失败: %w", err)
 	}
@@ -70,17 +75,17 @@ func (p *WebhookProvider) Send(ctx context.Context, req *WebhookRequest) (*Webho
 		return nil, fmt.Errorf("创建 HTTP 请求失败: %w", err)
 	}
 
-	// 设置请求头
-	httpReq.Header.Set("Content-Type", "application/json")
+	// 设置请求头（含签名）
+	attempt := req.Attempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	secrets := append([]string{req.Secret}, req.Secrets...)
+	for k, v := range webhook.SignedHeaders(event, payloadBytes, secrets, attempt) {
+		httpReq.Header.Set(k, v)
+	}
 	httpReq.Header.Set("User-Agent", "PaymentPlatform-Webhook/1.0")
 
-	// 计算签名
-	signature := p.calculateSignature(payloadBytes, req.Secret)
-	httpReq.Header.Set("X-Webhook-Signature", signature)
-	httpReq.Header.Set("X-Webhook-Timestamp", fmt.Sprintf("%d", req.Timestamp))
-	httpReq.Header.Set("X-Webhook-Event-Type", req.EventType)
-	httpReq.Header.Set("X-Webhook-Event-ID", req.EventID)
-
 	// 设置超时
 	if req.Timeout > 0 {
 		p.client.Timeout = time.Duration(req.Timeout) * time.Second
@@ -121,15 +126,7 @@ func (p *WebhookProvider) Send(ctx context.Context, req *WebhookRequest) (*Webho
 	return response, nil
 }
 
-// calculateSignature 计算签名
-func (p *WebhookProvider) calculateSignature(payload []byte, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	return hex.EncodeToString(mac.Sum(nil))
-}
-
-// VerifySignature 验证签名
+// VerifySignature 验证签名头（t=...,v1=...）
 func (p *WebhookProvider) VerifySignature(payload []byte, signature, secret string) bool {
-	expectedSignature := p.calculateSignature(payload, secret)
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+	return webhook.VerifySignature(signature, payload, secret, webhook.DefaultSignatureTolerance) == nil
 }
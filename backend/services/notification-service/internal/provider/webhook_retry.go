@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"payment-platform/notification-service/internal/model"
+)
+
+// webhookNotificationContent Webhook 通知记录中保存的投递内容
+type webhookNotificationContent struct {
+	URL        string                 `json:"url"`
+	Secret     string                 `json:"secret"`
+	EventType  string                 `json:"event_type"`
+	EventID    string                 `json:"event_id"`
+	APIVersion string                 `json:"api_version"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// NewRetryRequest 根据 Webhook 通知记录构造重试请求
+// 事件类型、事件ID和创建时间沿用原事件，保证商户可按事件ID去重且请求体不变；
+// 密钥取商户当前端点配置（签名在 Send 时按发送时间生成），找不到端点时使用记录中的密钥；
+// 负载版本优先使用记录中的版本，否则使用端点固定的版本
+func NewRetryRequest(notification *model.Notification, endpoints []*model.WebhookEndpoint, now time.Time) (*WebhookRequest, error) {
+	var content webhookNotificationContent
+	if err := json.Unmarshal([]byte(notification.Content), &content); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook config: %w", err)
+	}
+
+	req := &WebhookRequest{
+		URL:        content.URL,
+		Secret:     content.Secret,
+		EventType:  content.EventType,
+		EventID:    content.EventID,
+		APIVersion: content.APIVersion,
+		Data:       content.Data,
+		Timestamp:  notification.CreatedAt.Unix(),
+	}
+	if req.EventType == "" {
+		req.EventType = notification.Type
+	}
+	if req.EventID == "" {
+		req.EventID = notification.ID.String()
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.URL != content.URL {
+			continue
+		}
+		secrets := endpoint.SigningSecrets(now)
+		req.Secret, req.Secrets = secrets[0], secrets[1:]
+		if req.APIVersion == "" {
+			req.APIVersion = endpoint.Version
+		}
+		break
+	}
+
+	if req.Secret == "" {
+		return nil, fmt.Errorf("no signing secret for webhook %s", content.URL)
+	}
+	return req, nil
+}
@@ -32,12 +32,17 @@ type NotificationRepository interface {
 	ListEndpoints(ctx context.Context, merchantID uuid.UUID) ([]*model.WebhookEndpoint, error)
 	UpdateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error
 	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	RecordEndpointFailure(ctx context.Context, id uuid.UUID, at time.Time) (*model.WebhookEndpoint, error)
+	ResetEndpointFailures(ctx context.Context, id uuid.UUID) error
+	DisableEndpoint(ctx context.Context, id uuid.UUID, reason string, at time.Time) (bool, error)
 
 	// Webhook 投递记录
 	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
 	GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
 	ListDeliveries(ctx context.Context, query *DeliveryQuery) ([]*model.WebhookDelivery, int64, error)
 	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListDeliveriesForReplay(ctx context.Context, query *DeliveryQuery, limit int) ([]*model.WebhookDelivery, error)
+	GetEndpointDeliveryStats(ctx context.Context, merchantID uuid.UUID, endpointID *uuid.UUID, since time.Time) ([]*EndpointDeliveryStats, error)
 
 	// 查询待处理的通知
 	ListPendingNotifications(ctx context.Context, limit int) ([]*model.Notification, error)
@@ -88,6 +93,18 @@ type DeliveryQuery struct {
 	PageSize   int
 }
 
+// EndpointDeliveryStats 端点投递统计
+type EndpointDeliveryStats struct {
+	EndpointID   uuid.UUID `json:"endpoint_id"`
+	Total        int64     `json:"total"`          // 投递总数
+	Delivered    int64     `json:"delivered"`      // 投递成功数
+	Failed       int64     `json:"failed"`         // 最终失败数
+	Pending      int64     `json:"pending"`        // 待投递/重试中
+	SuccessRate  float64   `json:"success_rate"`   // 成功率（已完结的投递中成功的比例，0-1）
+	AvgLatencyMs float64   `json:"avg_latency_ms"` // 平均耗时（毫秒）
+	P95LatencyMs float64   `json:"p95_latency_ms"` // P95耗时（毫秒）
+}
+
 // Create 创建通知
 func (r *notificationRepository) Create(ctx context.Context, notification *model.Notification) error {
 	return r.db.WithContext(ctx).Create(notification).Error
@@ -272,6 +289,42 @@ func (r *notificationRepository) DeleteEndpoint(ctx context.Context, id uuid.UUI
 	return r.db.WithContext(ctx).Delete(&model.WebhookEndpoint{}, "id = ?", id).Error
 }
 
+// RecordEndpointFailure 记录一次投递失败（原子递增连续失败次数），返回更新后的端点
+func (r *notificationRepository) RecordEndpointFailure(ctx context.Context, id uuid.UUID, at time.Time) (*model.WebhookEndpoint, error) {
+	err := r.db.WithContext(ctx).Model(&model.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"failing_since":        gorm.Expr("COALESCE(failing_since, ?)", at),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetEndpoint(ctx, id)
+}
+
+// ResetEndpointFailures 投递成功后清零连续失败次数
+func (r *notificationRepository) ResetEndpointFailures(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		Updates(map[string]interface{}{
+			"consecutive_failures": 0,
+			"failing_since":        nil,
+		}).Error
+}
+
+// DisableEndpoint 停用端点，端点已是停用状态时返回 false（保证只停用和通知一次）
+func (r *notificationRepository) DisableEndpoint(ctx context.Context, id uuid.UUID, reason string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND is_enabled = ?", id, true).
+		Updates(map[string]interface{}{
+			"is_enabled":      false,
+			"disabled_at":     at,
+			"disabled_reason": reason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // CreateDelivery 创建投递记录
 func (r *notificationRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
 	return r.db.WithContext(ctx).Create(delivery).Error
@@ -344,6 +397,68 @@ func (r *notificationRepository) UpdateDelivery(ctx context.Context, delivery *m
 	return r.db.WithContext(ctx).Save(delivery).Error
 }
 
+// ListDeliveriesForReplay 按条件列出需要重放的投递记录（不分页，按创建时间升序，最多 limit 条）
+func (r *notificationRepository) ListDeliveriesForReplay(ctx context.Context, query *DeliveryQuery, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+
+	db := r.db.WithContext(ctx).Model(&model.WebhookDelivery{})
+	if query.EndpointID != nil {
+		db = db.Where("endpoint_id = ?", *query.EndpointID)
+	}
+	if query.MerchantID != nil {
+		db = db.Where("merchant_id = ?", *query.MerchantID)
+	}
+	if query.EventType != "" {
+		db = db.Where("event_type = ?", query.EventType)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.StartTime != nil {
+		db = db.Where("created_at >= ?", *query.StartTime)
+	}
+	if query.EndTime != nil {
+		db = db.Where("created_at <= ?", *query.EndTime)
+	}
+
+	err := db.Order("created_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// GetEndpointDeliveryStats 按端点统计投递成功率和耗时
+func (r *notificationRepository) GetEndpointDeliveryStats(ctx context.Context, merchantID uuid.UUID, endpointID *uuid.UUID, since time.Time) ([]*EndpointDeliveryStats, error) {
+	var stats []*EndpointDeliveryStats
+
+	db := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Select(`endpoint_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS delivered,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(*) FILTER (WHERE status IN (?, ?)) AS pending,
+			COALESCE(AVG(duration) FILTER (WHERE duration > 0), 0) AS avg_latency_ms,
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration) FILTER (WHERE duration > 0), 0) AS p95_latency_ms`,
+			model.DeliveryStatusDelivered, model.DeliveryStatusFailed,
+			model.DeliveryStatusPending, model.DeliveryStatusRetrying).
+		Where("merchant_id = ? AND created_at >= ?", merchantID, since)
+	if endpointID != nil {
+		db = db.Where("endpoint_id = ?", *endpointID)
+	}
+
+	if err := db.Group("endpoint_id").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	for _, s := range stats {
+		if finished := s.Delivered + s.Failed; finished > 0 {
+			s.SuccessRate = float64(s.Delivered) / float64(finished)
+		}
+	}
+	return stats, nil
+}
+
 // ListPendingNotifications 列出待处理的通知
 func (r *notificationRepository) ListPendingNotifications(ctx context.Context, limit int) ([]*model.Notification, error) {
 	var notifications []*model.Notification
@@ -3,6 +3,8 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -12,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/kafka"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/webhook"
 	"go.uber.org/zap"
 	"payment-platform/notification-service/internal/model"
 	"payment-platform/notification-service/internal/provider"
@@ -46,9 +49,13 @@ type NotificationService interface {
 	ListWebhookEndpoints(ctx context.Context, merchantID uuid.UUID) ([]*model.WebhookEndpoint, error)
 	UpdateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error
 	DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error
+	RotateWebhookSecret(ctx context.Context, merchantID, id uuid.UUID, overlap time.Duration) (*model.WebhookEndpoint, error)
+	UpdateWebhookSubscriptions(ctx context.Context, merchantID, id uuid.UUID, events []string) (*model.WebhookEndpoint, error)
 
 	// Webhook 投递记录
 	ListWebhookDeliveries(ctx context.Context, query *repository.DeliveryQuery) ([]*model.WebhookDelivery, int64, error)
+	ReplayWebhookDeliveries(ctx context.Context, req *ReplayWebhookDeliveriesRequest) (*ReplayWebhookDeliveriesResult, error)
+	GetWebhookEndpointStats(ctx context.Context, merchantID uuid.UUID, endpointID *uuid.UUID, since time.Time) ([]*repository.EndpointDeliveryStats, error)
 
 	// 通知偏好管理
 	CreatePreference(ctx context.Context, preference *model.NotificationPreference) error
@@ -143,6 +150,30 @@ type SendWebhookRequest struct {
 	Data       map[string]interface{} `json:"data"`
 }
 
+// ReplayWebhookDeliveriesRequest 批量重放 Webhook 投递请求
+type ReplayWebhookDeliveriesRequest struct {
+	MerchantID uuid.UUID  `json:"merchant_id"`
+	EndpointID *uuid.UUID `json:"endpoint_id"` // 端点ID（可选，为空表示商户的所有端点）
+	EventType  string     `json:"event_type"`  // 事件类型（可选）
+	Status     string     `json:"status"`      // 投递状态（可选，为空时只重放失败的投递）
+	StartTime  time.Time  `json:"start_time"`  // 投递创建时间范围（必填）
+	EndTime    time.Time  `json:"end_time"`
+}
+
+// ReplayWebhookDeliveriesResult 批量重放结果
+type ReplayWebhookDeliveriesResult struct {
+	Matched   int  `json:"matched"`   // 匹配的投递数
+	Replayed  int  `json:"replayed"`  // 已创建重放投递数
+	Skipped   int  `json:"skipped"`   // 跳过数（端点已删除、已停用或不再订阅该事件）
+	Truncated bool `json:"truncated"` // 匹配数超过单次上限，需要缩小时间范围后再次重放
+}
+
+// 批量重放限制
+const (
+	maxReplayDeliveries = 1000
+	maxReplayWindow     = 31 * 24 * time.Hour
+)
+
 // SendEmailByTemplateRequest 使用模板发送邮件请求
 type SendEmailByTemplateRequest struct {
 	MerchantID   uuid.UUID              `json:"merchant_id"`
@@ -346,33 +377,20 @@ func (s *notificationService) sendSMSSync(ctx context.Context, notification *mod
 
 // SendWebhook 发送 Webhook
 func (s *notificationService) SendWebhook(ctx context.Context, req *SendWebhookRequest) error {
+	// 事件ID在所有端点和所有重试间保持一致，商户据此去重
+	if req.EventID == "" {
+		req.EventID = webhook.NewEventID()
+	}
+
 	// 获取商户的 Webhook 端点列表
 	endpoints, err := s.repo.ListEndpoints(ctx, req.MerchantID)
 	if err != nil {
 		return fmt.Errorf("获取 Webhook 端点失败: %w", err)
 	}
 
-	// 遍历端点，发送 Webhook
+	// 遍历端点，发送 Webhook（只投递给启用且订阅了该事件的端点）
 	for _, endpoint := range endpoints {
-		if !endpoint.IsEnabled {
-			continue
-		}
-
-		// 检查端点是否订阅了该事件
-		var events []string
-		if endpoint.Events != "" {
-			json.Unmarshal([]byte(endpoint.Events), &events)
-		}
-
-		subscribed := false
-		for _, event := range events {
-			if event == req.EventType || event == "*" {
-				subscribed = true
-				break
-			}
-		}
-
-		if !subscribed {
+		if !endpoint.IsEnabled || !endpoint.IsSubscribed(req.EventType) {
 			continue
 		}
 
@@ -409,15 +427,19 @@ func (s *notificationService) deliverWebhook(ctx context.Context, delivery *mode
 	var data map[string]interface{}
 	json.Unmarshal([]byte(delivery.Payload), &data)
 
-	// 构造 Webhook 请求
+	// 构造 Webhook 请求（负载版本按端点固定的版本生成）
+	secrets := endpoint.SigningSecrets(time.Now())
 	webhookReq := &provider.WebhookRequest{
-		URL:       endpoint.URL,
-		Secret:    endpoint.Secret,
-		EventType: delivery.EventType,
-		EventID:   delivery.EventID,
-		Timestamp: time.Now().Unix(),
-		Data:      data,
-		Timeout:   endpoint.Timeout,
+		URL:        endpoint.URL,
+		Secret:     secrets[0],
+		Secrets:    secrets[1:],
+		EventType:  delivery.EventType,
+		EventID:    delivery.EventID,
+		APIVersion: endpoint.Version,
+		Timestamp:  delivery.CreatedAt.Unix(),
+		Data:       data,
+		Timeout:    endpoint.Timeout,
+		Attempt:    delivery.RetryCount + 1,
 	}
 
 	// 发送 Webhook
@@ -430,6 +452,16 @@ func (s *notificationService) deliverWebhook(ctx context.Context, delivery *mode
 	if err != nil {
 		delivery.Status = model.DeliveryStatusFailed
 		delivery.ErrorMessage = err.Error()
+	} else {
+		delivery.Status = resp.Status
+		delivery.HTTPStatus = resp.HTTPStatus
+		delivery.ResponseBody = resp.ResponseBody
+		delivery.Duration = int(resp.Duration)
+		delivery.ErrorMessage = resp.ErrorMessage
+	}
+
+	succeeded := delivery.Status == model.DeliveryStatusDelivered
+	if !succeeded {
 		delivery.RetryCount++
 
 		// 计算下次重试时间（指数退避）
@@ -438,16 +470,87 @@ func (s *notificationService) deliverWebhook(ctx context.Context, delivery *mode
 			nextRetry := time.Now().Add(retryDelay)
 			delivery.NextRetryAt = &nextRetry
 			delivery.Status = model.DeliveryStatusRetrying
+		} else {
+			delivery.Status = model.DeliveryStatusFailed
+			delivery.NextRetryAt = nil
 		}
-	} else {
-		delivery.Status = resp.Status
-		delivery.HTTPStatus = resp.HTTPStatus
-		delivery.ResponseBody = resp.ResponseBody
-		delivery.Duration = int(resp.Duration)
-		delivery.ErrorMessage = resp.ErrorMessage
 	}
 
 	s.repo.UpdateDelivery(ctx, delivery)
+	s.trackEndpointHealth(ctx, endpoint, succeeded, now)
+}
+
+// trackEndpointHealth 跟踪端点健康状况
+// 投递成功时清零连续失败次数；连续失败达到阈值且持续超过时间窗口时自动停用端点并通知商户
+func (s *notificationService) trackEndpointHealth(ctx context.Context, endpoint *model.WebhookEndpoint, succeeded bool, now time.Time) {
+	if succeeded {
+		if err := s.repo.ResetEndpointFailures(ctx, endpoint.ID); err != nil {
+			logger.Error("failed to reset endpoint failures",
+				zap.Error(err),
+				zap.String("endpoint_id", endpoint.ID.String()))
+		}
+		return
+	}
+
+	updated, err := s.repo.RecordEndpointFailure(ctx, endpoint.ID, now)
+	if err != nil || updated == nil {
+		logger.Error("failed to record endpoint failure",
+			zap.Error(err),
+			zap.String("endpoint_id", endpoint.ID.String()))
+		return
+	}
+
+	if updated.ConsecutiveFailures < model.WebhookDisableFailureThreshold ||
+		updated.FailingSince == nil || now.Sub(*updated.FailingSince) < model.WebhookDisableFailureWindow {
+		return
+	}
+
+	reason := fmt.Sprintf("连续 %d 次投递失败（自 %s 起）", updated.ConsecutiveFailures, updated.FailingSince.Format(time.RFC3339))
+	disabled, err := s.repo.DisableEndpoint(ctx, endpoint.ID, reason, now)
+	if err != nil {
+		logger.Error("failed to disable webhook endpoint",
+			zap.Error(err),
+			zap.String("endpoint_id", endpoint.ID.String()))
+		return
+	}
+	if !disabled {
+		return
+	}
+
+	logger.Info("webhook endpoint auto-disabled",
+		zap.String("endpoint_id", endpoint.ID.String()),
+		zap.String("merchant_id", endpoint.MerchantID.String()),
+		zap.Int("consecutive_failures", updated.ConsecutiveFailures))
+
+	s.notifyEndpointDisabled(ctx, updated, reason)
+}
+
+// notifyEndpointDisabled 通过站内通知告知商户端点已被停用
+func (s *notificationService) notifyEndpointDisabled(ctx context.Context, endpoint *model.WebhookEndpoint, reason string) {
+	now := time.Now()
+	notification := &model.Notification{
+		MerchantID: endpoint.MerchantID,
+		Type:       model.NotificationTypeSystem,
+		Channel:    model.ChannelInApp,
+		Recipient:  endpoint.MerchantID.String(),
+		Subject:    "Webhook 端点已自动停用",
+		Content: fmt.Sprintf("您的 Webhook 端点 %s（%s）因%s已被自动停用，期间的事件可在修复后通过投递重放补发。修复后请在控制台重新启用该端点。",
+			endpoint.Name, endpoint.URL, reason),
+		Status: model.StatusSent,
+		SentAt: &now,
+	}
+
+	extra, _ := json.Marshal(map[string]interface{}{
+		"endpoint_id": endpoint.ID,
+		"event_type":  "webhook_endpoint.disabled",
+	})
+	notification.Extra = string(extra)
+
+	if err := s.repo.Create(ctx, notification); err != nil {
+		logger.Error("failed to create endpoint disabled notification",
+			zap.Error(err),
+			zap.String("endpoint_id", endpoint.ID.String()))
+	}
 }
 
 // SendEmailByTemplate 使用模板发送邮件
@@ -569,7 +672,28 @@ func (s *notificationService) DeleteTemplate(ctx context.Context, id uuid.UUID)
 }
 
 // CreateWebhookEndpoint 创建 Webhook 端点
+// 未指定负载版本时固定为当前最新版本，未指定密钥时自动生成
 func (s *notificationService) CreateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	// 未指定订阅时默认订阅全部事件
+	if endpoint.Events == "" {
+		endpoint.Events = `["*"]`
+	}
+	if err := validateWebhookSubscriptions(endpoint.SubscribedEvents()); err != nil {
+		return err
+	}
+	if endpoint.Version == "" {
+		endpoint.Version = webhook.LatestAPIVersion
+	}
+	if !webhook.IsSupportedAPIVersion(endpoint.Version) {
+		return fmt.Errorf("不支持的 Webhook 负载版本: %s", endpoint.Version)
+	}
+	if endpoint.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return fmt.Errorf("生成 Webhook 密钥失败: %w", err)
+		}
+		endpoint.Secret = secret
+	}
 	return s.repo.CreateEndpoint(ctx, endpoint)
 }
 
@@ -580,9 +704,135 @@ func (s *notificationService) ListWebhookEndpoints(ctx context.Context, merchant
 
 // UpdateWebhookEndpoint 更新 Webhook 端点
 func (s *notificationService) UpdateWebhookEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	if endpoint.Version != "" && !webhook.IsSupportedAPIVersion(endpoint.Version) {
+		return fmt.Errorf("不支持的 Webhook 负载版本: %s", endpoint.Version)
+	}
+
+	// 保留轮换状态（旧密钥不通过API暴露，更新时不能被覆盖）
+	existing, err := s.repo.GetEndpoint(ctx, endpoint.ID)
+	if err != nil {
+		return fmt.Errorf("获取 Webhook 端点失败: %w", err)
+	}
+	if existing != nil {
+		endpoint.PreviousSecret = existing.PreviousSecret
+		endpoint.PreviousSecretExpiresAt = existing.PreviousSecretExpiresAt
+		if endpoint.Secret == "" {
+			endpoint.Secret = existing.Secret
+		}
+		if endpoint.Version == "" {
+			endpoint.Version = existing.Version
+		}
+		if endpoint.Events == "" {
+			endpoint.Events = existing.Events
+		}
+
+		// 失败统计由投递流程维护；重新启用已停用的端点时清零
+		if endpoint.IsEnabled && !existing.IsEnabled {
+			endpoint.ConsecutiveFailures = 0
+			endpoint.FailingSince = nil
+			endpoint.DisabledAt = nil
+			endpoint.DisabledReason = ""
+		} else {
+			endpoint.ConsecutiveFailures = existing.ConsecutiveFailures
+			endpoint.FailingSince = existing.FailingSince
+			endpoint.DisabledAt = existing.DisabledAt
+			endpoint.DisabledReason = existing.DisabledReason
+		}
+	}
+
+	if err := validateWebhookSubscriptions(endpoint.SubscribedEvents()); err != nil {
+		return err
+	}
+
 	return s.repo.UpdateEndpoint(ctx, endpoint)
 }
 
+// RotateWebhookSecret 轮换 Webhook 签名密钥
+// 过渡期（overlap）内同时使用新旧密钥签名，商户在过渡期内切换到新密钥即可零停机完成轮换
+func (s *notificationService) RotateWebhookSecret(ctx context.Context, merchantID, id uuid.UUID, overlap time.Duration) (*model.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Webhook 端点失败: %w", err)
+	}
+	if endpoint == nil || endpoint.MerchantID != merchantID {
+		return nil, fmt.Errorf("Webhook 端点不存在")
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成 Webhook 密钥失败: %w", err)
+	}
+
+	if overlap > 0 && endpoint.Secret != "" {
+		expiresAt := time.Now().Add(overlap)
+		endpoint.PreviousSecret = endpoint.Secret
+		endpoint.PreviousSecretExpiresAt = &expiresAt
+	} else {
+		endpoint.PreviousSecret = ""
+		endpoint.PreviousSecretExpiresAt = nil
+	}
+	endpoint.Secret = secret
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("保存 Webhook 密钥失败: %w", err)
+	}
+
+	logger.Info("webhook secret rotated",
+		zap.String("endpoint_id", endpoint.ID.String()),
+		zap.String("merchant_id", merchantID.String()),
+		zap.Duration("overlap", overlap))
+
+	return endpoint, nil
+}
+
+// UpdateWebhookSubscriptions 更新端点订阅的事件类型
+func (s *notificationService) UpdateWebhookSubscriptions(ctx context.Context, merchantID, id uuid.UUID, events []string) (*model.WebhookEndpoint, error) {
+	if err := validateWebhookSubscriptions(events); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Webhook 端点失败: %w", err)
+	}
+	if endpoint == nil || endpoint.MerchantID != merchantID {
+		return nil, fmt.Errorf("Webhook 端点不存在")
+	}
+
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("序列化订阅事件失败: %w", err)
+	}
+	endpoint.Events = string(eventsJSON)
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("保存订阅事件失败: %w", err)
+	}
+	return endpoint, nil
+}
+
+// validateWebhookSubscriptions 校验订阅的事件类型（必须在事件目录中）
+func validateWebhookSubscriptions(events []string) error {
+	if len(events) == 0 {
+		return fmt.Errorf("至少需要订阅一个事件类型")
+	}
+	for _, event := range events {
+		if !model.IsValidWebhookSubscription(event) {
+			return fmt.Errorf("未知的 Webhook 事件类型: %s", event)
+		}
+	}
+	return nil
+}
+
+// generateWebhookSecret 生成 Webhook 签名密钥（whsec_ 前缀 + 32字节随机数）
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
 // DeleteWebhookEndpoint 删除 Webhook 端点
 func (s *notificationService) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
 	return s.repo.DeleteEndpoint(ctx, id)
@@ -593,6 +843,94 @@ func (s *notificationService) ListWebhookDeliveries(ctx context.Context, query *
 	return s.repo.ListDeliveries(ctx, query)
 }
 
+// ReplayWebhookDeliveries 批量重放 Webhook 投递
+// 为每条匹配的投递创建一条新的待投递记录（事件ID和数据不变，商户按事件ID去重），
+// 由后台任务按正常投递流程发送，避免一次性并发打满商户端点
+func (s *notificationService) ReplayWebhookDeliveries(ctx context.Context, req *ReplayWebhookDeliveriesRequest) (*ReplayWebhookDeliveriesResult, error) {
+	if req.StartTime.IsZero() || req.EndTime.IsZero() || !req.EndTime.After(req.StartTime) {
+		return nil, fmt.Errorf("无效的时间范围")
+	}
+	if req.EndTime.Sub(req.StartTime) > maxReplayWindow {
+		return nil, fmt.Errorf("时间范围不能超过 %d 天", int(maxReplayWindow.Hours()/24))
+	}
+	if req.EventType != "" && model.GetWebhookEvent(req.EventType) == nil {
+		return nil, fmt.Errorf("未知的 Webhook 事件类型: %s", req.EventType)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = model.DeliveryStatusFailed
+	}
+
+	merchantID := req.MerchantID
+	query := &repository.DeliveryQuery{
+		EndpointID: req.EndpointID,
+		MerchantID: &merchantID,
+		EventType:  req.EventType,
+		Status:     status,
+		StartTime:  &req.StartTime,
+		EndTime:    &req.EndTime,
+	}
+
+	// 多取一条用于判断是否超过单次上限
+	deliveries, err := s.repo.ListDeliveriesForReplay(ctx, query, maxReplayDeliveries+1)
+	if err != nil {
+		return nil, fmt.Errorf("查询投递记录失败: %w", err)
+	}
+
+	result := &ReplayWebhookDeliveriesResult{}
+	if len(deliveries) > maxReplayDeliveries {
+		deliveries = deliveries[:maxReplayDeliveries]
+		result.Truncated = true
+	}
+	result.Matched = len(deliveries)
+
+	endpoints := make(map[uuid.UUID]*model.WebhookEndpoint)
+	for _, original := range deliveries {
+		endpoint, ok := endpoints[original.EndpointID]
+		if !ok {
+			endpoint, err = s.repo.GetEndpoint(ctx, original.EndpointID)
+			if err != nil {
+				return nil, fmt.Errorf("获取 Webhook 端点失败: %w", err)
+			}
+			endpoints[original.EndpointID] = endpoint
+		}
+		if endpoint == nil || !endpoint.IsEnabled || !endpoint.IsSubscribed(original.EventType) {
+			result.Skipped++
+			continue
+		}
+
+		originalID := original.ID
+		replay := &model.WebhookDelivery{
+			EndpointID: original.EndpointID,
+			MerchantID: original.MerchantID,
+			EventType:  original.EventType,
+			EventID:    original.EventID,
+			Payload:    original.Payload,
+			Status:     model.DeliveryStatusPending,
+			ReplayOf:   &originalID,
+		}
+		if err := s.repo.CreateDelivery(ctx, replay); err != nil {
+			return nil, fmt.Errorf("创建重放投递失败: %w", err)
+		}
+		result.Replayed++
+	}
+
+	logger.Info("webhook deliveries replayed",
+		zap.String("merchant_id", req.MerchantID.String()),
+		zap.String("event_type", req.EventType),
+		zap.Int("matched", result.Matched),
+		zap.Int("replayed", result.Replayed),
+		zap.Int("skipped", result.Skipped))
+
+	return result, nil
+}
+
+// GetWebhookEndpointStats 获取端点投递统计（成功率、耗时）
+func (s *notificationService) GetWebhookEndpointStats(ctx context.Context, merchantID uuid.UUID, endpointID *uuid.UUID, since time.Time) ([]*repository.EndpointDeliveryStats, error) {
+	return s.repo.GetEndpointDeliveryStats(ctx, merchantID, endpointID, since)
+}
+
 // CreatePreference 创建通知偏好
 func (s *notificationService) CreatePreference(ctx context.Context, preference *model.NotificationPreference) error {
 	return s.repo.CreatePreference(ctx, preference)
@@ -722,7 +1060,7 @@ func (s *notificationService) ProcessPendingWebhookDeliveries(ctx context.Contex
 	for _, delivery := range deliveries {
 		// 获取端点配置
 		endpoint, err := s.repo.GetEndpoint(ctx, delivery.EndpointID)
-		if err != nil || endpoint == nil {
+		if err != nil || endpoint == nil || !endpoint.IsEnabled {
 			continue
 		}
 
@@ -751,25 +1089,15 @@ func (s *notificationService) RetryWebhookDelivery(ctx context.Context, notifica
 		return fmt.Errorf("notification exceeded max retries: %d", notification.RetryCount)
 	}
 
-	// 解析Webhook配置
-	var webhookConfig struct {
-		URL    string                 `json:"url"`
-		Secret string                 `json:"secret"`
-		Data   map[string]interface{} `json:"data"`
-	}
-
-	if err := json.Unmarshal([]byte(notification.Content), &webhookConfig); err != nil {
-		return fmt.Errorf("failed to parse webhook config: %w", err)
+	// 按商户当前端点配置构造重试请求
+	endpoints, err := s.repo.ListEndpoints(ctx, notification.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
 	}
 
-	// 发送Webhook
-	req := &provider.WebhookRequest{
-		URL:       webhookConfig.URL,
-		Secret:    webhookConfig.Secret,
-		EventType: "webhook_retry",
-		EventID:   notification.ID.String(),
-		Data:      webhookConfig.Data,
-		Timestamp: time.Now().Unix(),
+	req, err := provider.NewRetryRequest(notification, endpoints, time.Now())
+	if err != nil {
+		return err
 	}
 
 	response, err := s.webhookProvider.Send(ctx, req)
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/notification-service/internal/model"
+	"payment-platform/notification-service/internal/repository"
+)
+
+func init() {
+	logger.Log = zap.NewNop()
+}
+
+// replayRepo 只实现批量重放用到的仓储方法
+type replayRepo struct {
+	repository.NotificationRepository
+	endpoints  map[uuid.UUID]*model.WebhookEndpoint
+	deliveries []*model.WebhookDelivery
+	query      *repository.DeliveryQuery
+	limit      int
+	created    []*model.WebhookDelivery
+}
+
+func (r *replayRepo) ListDeliveriesForReplay(_ context.Context, query *repository.DeliveryQuery, limit int) ([]*model.WebhookDelivery, error) {
+	r.query, r.limit = query, limit
+	if len(r.deliveries) > limit {
+		return r.deliveries[:limit], nil
+	}
+	return r.deliveries, nil
+}
+
+func (r *replayRepo) GetEndpoint(_ context.Context, id uuid.UUID) (*model.WebhookEndpoint, error) {
+	return r.endpoints[id], nil
+}
+
+func (r *replayRepo) CreateDelivery(_ context.Context, delivery *model.WebhookDelivery) error {
+	r.created = append(r.created, delivery)
+	return nil
+}
+
+func TestReplayWebhookDeliveries(t *testing.T) {
+	merchantID := uuid.New()
+	active := &model.WebhookEndpoint{ID: uuid.New(), MerchantID: merchantID, IsEnabled: true, Events: `["payment.*"]`}
+	disabled := &model.WebhookEndpoint{ID: uuid.New(), MerchantID: merchantID, IsEnabled: false, Events: `["*"]`}
+	deletedID := uuid.New()
+
+	delivery := func(endpointID uuid.UUID, eventType string) *model.WebhookDelivery {
+		return &model.WebhookDelivery{
+			ID:         uuid.New(),
+			EndpointID: endpointID,
+			MerchantID: merchantID,
+			EventType:  eventType,
+			EventID:    "evt_" + uuid.NewString(),
+			Payload:    `{"payment_no":"PAY001"}`,
+			Status:     model.DeliveryStatusFailed,
+		}
+	}
+	replayable := delivery(active.ID, model.WebhookEventPaymentSuccess)
+	repo := &replayRepo{
+		endpoints: map[uuid.UUID]*model.WebhookEndpoint{active.ID: active, disabled.ID: disabled},
+		deliveries: []*model.WebhookDelivery{
+			replayable,
+			delivery(active.ID, model.WebhookEventRefundSuccess), // 端点已不再订阅退款事件
+			delivery(disabled.ID, model.WebhookEventPaymentSuccess),
+			delivery(deletedID, model.WebhookEventPaymentSuccess),
+		},
+	}
+	svc := &notificationService{repo: repo}
+
+	end := time.Now()
+	result, err := svc.ReplayWebhookDeliveries(context.Background(), &ReplayWebhookDeliveriesRequest{
+		MerchantID: merchantID,
+		StartTime:  end.Add(-24 * time.Hour),
+		EndTime:    end,
+	})
+	if err != nil {
+		t.Fatalf("ReplayWebhookDeliveries() error = %v", err)
+	}
+
+	if result.Matched != 4 || result.Replayed != 1 || result.Skipped != 3 || result.Truncated {
+		t.Fatalf("result = %+v, want matched 4, replayed 1, skipped 3", result)
+	}
+	// 未指定状态时只重放失败的投递，并限定在商户范围内
+	if repo.query.Status != model.DeliveryStatusFailed || repo.query.MerchantID == nil || *repo.query.MerchantID != merchantID {
+		t.Fatalf("query = %+v, want failed deliveries of merchant %s", repo.query, merchantID)
+	}
+
+	// 重放投递沿用原事件ID和数据，并记录来源投递
+	replay := repo.created[0]
+	if replay.EventID != replayable.EventID || replay.Payload != replayable.Payload ||
+		replay.Status != model.DeliveryStatusPending || replay.ReplayOf == nil || *replay.ReplayOf != replayable.ID {
+		t.Fatalf("replay = %+v, want pending copy of %s", replay, replayable.ID)
+	}
+}
+
+func TestReplayWebhookDeliveriesTruncated(t *testing.T) {
+	merchantID := uuid.New()
+	endpoint := &model.WebhookEndpoint{ID: uuid.New(), MerchantID: merchantID, IsEnabled: true, Events: `["*"]`}
+	repo := &replayRepo{endpoints: map[uuid.UUID]*model.WebhookEndpoint{endpoint.ID: endpoint}}
+	for i := 0; i < maxReplayDeliveries+5; i++ {
+		repo.deliveries = append(repo.deliveries, &model.WebhookDelivery{
+			ID:         uuid.New(),
+			EndpointID: endpoint.ID,
+			MerchantID: merchantID,
+			EventType:  model.WebhookEventPaymentSuccess,
+		})
+	}
+	svc := &notificationService{repo: repo}
+
+	end := time.Now()
+	result, err := svc.ReplayWebhookDeliveries(context.Background(), &ReplayWebhookDeliveriesRequest{
+		MerchantID: merchantID,
+		StartTime:  end.Add(-time.Hour),
+		EndTime:    end,
+	})
+	if err != nil {
+		t.Fatalf("ReplayWebhookDeliveries() error = %v", err)
+	}
+	if !result.Truncated || result.Matched != maxReplayDeliveries || len(repo.created) != maxReplayDeliveries {
+		t.Fatalf("result = %+v, created %d, want truncated at %d", result, len(repo.created), maxReplayDeliveries)
+	}
+}
+
+func TestReplayWebhookDeliveriesValidation(t *testing.T) {
+	svc := &notificationService{repo: &replayRepo{}}
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		req  *ReplayWebhookDeliveriesRequest
+	}{
+		{"missing range", &ReplayWebhookDeliveriesRequest{}},
+		{"end before start", &ReplayWebhookDeliveriesRequest{StartTime: now, EndTime: now.Add(-time.Hour)}},
+		{"window too large", &ReplayWebhookDeliveriesRequest{StartTime: now.Add(-maxReplayWindow - time.Hour), EndTime: now}},
+		{"unknown event", &ReplayWebhookDeliveriesRequest{StartTime: now.Add(-time.Hour), EndTime: now, EventType: "payment.unknown"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := svc.ReplayWebhookDeliveries(context.Background(), tt.req); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
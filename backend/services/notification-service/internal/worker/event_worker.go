@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 
 	"github.com/payment-platform/pkg/events"
 	"github.com/payment-platform/pkg/kafka"
@@ -157,11 +158,12 @@ func (w *EventWorker) handlePaymentSuccess(ctx context.Context, message []byte)
 		Subject: "支付成功 - Payment Successful",
 		Template: "payment_success",
 		Data: map[string]interface{}{
-			"payment_no": event.Payload.PaymentNo,
-			"order_no":   event.Payload.OrderNo,
-			"amount":     float64(event.Payload.Amount) / 100,
-			"currency":   event.Payload.Currency,
-			"paid_at":    event.Payload.PaidAt,
+			"payment_no":  event.Payload.PaymentNo,
+			"order_no":    event.Payload.OrderNo,
+			"amount":      float64(event.Payload.Amount) / 100,
+			"currency":    event.Payload.Currency,
+			"paid_at":     event.Payload.PaidAt,
+			"receipt_url": event.Payload.Extra["receipt_url"],
 		},
 	})
 }
@@ -214,10 +216,11 @@ func (w *EventWorker) handleRefundSuccess(ctx context.Context, message []byte) e
 		Subject: "退款成功 - Refund Successful",
 		Template: "refund_success",
 		Data: map[string]interface{}{
-			"refund_no":  event.Payload.RefundNo,
-			"payment_no": event.Payload.PaymentNo,
-			"amount":     float64(event.Payload.Amount) / 100,
-			"currency":   event.Payload.Currency,
+			"refund_no":   event.Payload.RefundNo,
+			"payment_no":  event.Payload.PaymentNo,
+			"amount":      float64(event.Payload.Amount) / 100,
+			"currency":    event.Payload.Currency,
+			"receipt_url": event.Payload.Extra["receipt_url"],
 		},
 	})
 }
@@ -425,10 +428,11 @@ func (w *EventWorker) renderSimpleTemplate(template string, data map[string]inte
 					<li>金额: %v %v</li>
 					<li>支付时间: %v</li>
 				</ul>
+				%s
 				<p>感谢您的购买！</p>
 			</body>
 			</html>
-		`, data["payment_no"], data["order_no"], data["amount"], data["currency"], data["paid_at"])
+		`, data["payment_no"], data["order_no"], data["amount"], data["currency"], data["paid_at"], receiptLinkHTML(data))
 
 	case "payment_failed":
 		return fmt.Sprintf(`
@@ -516,10 +520,11 @@ func (w *EventWorker) renderSimpleTemplate(template string, data map[string]inte
 					<li>支付流水号: %v</li>
 					<li>退款金额: %v %v</li>
 				</ul>
+				%s
 				<p>退款将在1-3个工作日内到账。</p>
 			</body>
 			</html>
-		`, data["refund_no"], data["payment_no"], data["amount"], data["currency"])
+		`, data["refund_no"], data["payment_no"], data["amount"], data["currency"], receiptLinkHTML(data))
 
 	case "refund_failed":
 		return fmt.Sprintf(`
@@ -541,3 +546,12 @@ func (w *EventWorker) renderSimpleTemplate(template string, data map[string]inte
 		return "<html><body><p>通知内容</p></body></html>"
 	}
 }
+
+// receiptLinkHTML 收据下载链接（事件中带有 receipt_url 时展示）
+func receiptLinkHTML(data map[string]interface{}) string {
+	receiptURL, ok := data["receipt_url"].(string)
+	if !ok || receiptURL == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<p><a href="%s">下载电子收据 / Download receipt (PDF)</a></p>`, html.EscapeString(receiptURL))
+}
@@ -2,7 +2,6 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -115,28 +114,21 @@ func (w *WebhookRetryWorker) retryWebhook(ctx context.Context, notification *mod
 		zap.String("notification_id", notification.ID.String()),
 		zap.Int("retry_count", notification.RetryCount))
 
-	// 解析Webhook配置
-	var webhookConfig struct {
-		URL    string                 `json:"url"`
-		Secret string                 `json:"secret"`
-		Data   map[string]interface{} `json:"data"`
-	}
-
-	if err := json.Unmarshal([]byte(notification.Content), &webhookConfig); err != nil {
-		w.logger.Error("Failed to parse webhook config",
+	// 按商户当前端点配置构造重试请求
+	endpoints, err := w.repo.ListEndpoints(ctx, notification.MerchantID)
+	if err != nil {
+		w.logger.Error("Failed to list webhook endpoints",
 			zap.String("notification_id", notification.ID.String()),
 			zap.Error(err))
 		return
 	}
 
-	// 发送Webhook
-	req := &provider.WebhookRequest{
-		URL:       webhookConfig.URL,
-		Secret:    webhookConfig.Secret,
-		EventType: "webhook_retry",
-		EventID:   notification.ID.String(),
-		Data:      webhookConfig.Data,
-		Timestamp: time.Now().Unix(),
+	req, err := provider.NewRetryRequest(notification, endpoints, time.Now())
+	if err != nil {
+		w.logger.Error("Failed to build webhook retry request",
+			zap.String("notification_id", notification.ID.String()),
+			zap.Error(err))
+		return
 	}
 
 	response, err := w.webhookProvider.Send(ctx, req)
@@ -769,7 +769,8 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "amount",
-                "reason"
+                "reason",
+                "refund_id"
             ],
             "properties": {
                 "amount": {
@@ -777,6 +778,10 @@ const docTemplate = `{
                 },
                 "reason": {
                     "type": "string"
+                },
+                "refund_id": {
+                    "description": "调用方生成的退款请求ID，重试时须沿用",
+                    "type": "string"
                 }
             }
         },
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -22,6 +23,7 @@ import (
 	"payment-platform/order-service/internal/service"
 	"github.com/payment-platform/pkg/idempotency"
 	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/tax"
 
 	_ "payment-platform/order-service/api-docs" // Import generated swagger docs
 )
@@ -94,6 +96,13 @@ func main() {
 			&model.OrderItem{},
 			&model.OrderLog{},
 			&model.OrderStatistics{},
+			&model.ReturnRequest{},
+			&model.ReturnItem{},
+			&model.OrderTaxLine{},
+			&model.OrderTaxRefund{},
+			&model.TaxRule{},
+			&model.OrderPayment{},
+			&model.OrderPaymentRefund{},
 		},
 		EnableTracing:     true,
 		EnableMetrics:     true,
@@ -130,8 +139,34 @@ func main() {
 	notificationClient := client.NewNotificationClient(notificationServiceURL)
 	logger.Info(fmt.Sprintf("通知服务客户端初始化: %s", notificationServiceURL))
 
+	// 初始化 Payment Gateway 客户端（退货审核通过后自动退款）
+	paymentGatewayURL := getConfig("PAYMENT_GATEWAY_URL", "http://localhost:40003")
+	paymentClient := client.NewPaymentClient(paymentGatewayURL)
+	logger.Info(fmt.Sprintf("支付网关客户端初始化: %s", paymentGatewayURL))
+
 	repo := repository.NewOrderRepository(application.DB)
 	svc := service.NewOrderService(application.DB, repo, application.Redis, notificationClient, eventPublisher)
+	returnRepo := repository.NewReturnRepository(application.DB)
+	returnService := service.NewReturnService(application.DB, repo, returnRepo, paymentClient, eventPublisher)
+	returnHandler := handler.NewReturnHandler(returnService)
+
+	// 组合支付（一笔订单多笔支付），退款分摊策略：reverse（后付先退）、sequential（先付先退）、proportional（按比例）
+	tenderService := service.NewTenderService(application.DB, repo, paymentClient, eventPublisher, getConfig("TENDER_REFUND_STRATEGY", model.RefundStrategyReverse))
+	if ts, ok := svc.(interface{ SetTenderService(service.TenderService) }); ok {
+		ts.SetTenderService(tenderService)
+	}
+	if ts, ok := returnService.(interface{ SetTenderService(service.TenderService) }); ok {
+		ts.SetTenderService(tenderService)
+	}
+	tenderHandler := handler.NewTenderHandler(tenderService)
+
+	// 计税引擎（数据库税率规则优先，内置税率表兜底），创建订单时传入计税参数才计税
+	taxRepo := repository.NewTaxRepository(application.DB)
+	taxService := service.NewTaxService(taxRepo)
+	if ts, ok := svc.(interface{ SetTaxEngine(tax.Engine) }); ok {
+		ts.SetTaxEngine(taxService.Engine())
+	}
+	taxHandler := handler.NewTaxHandler(taxService)
 	handler := handler.NewOrderHandler(svc)
 
 	idempotencyManager := idempotency.NewIdempotencyManager(application.Redis, "order-service", 24*time.Hour)
@@ -139,6 +174,12 @@ func main() {
 
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	handler.RegisterRoutes(application.Router)
+	returnHandler.RegisterRoutes(application.Router)
+	taxHandler.RegisterRoutes(application.Router)
+	tenderHandler.RegisterRoutes(application.Router)
+
+	// 定时关闭超时订单并释放组合支付
+	startScheduledTasks(tenderService, returnService)
 
 	// JWT 认证中间件（优先从配置中心获取）
 	// ⚠️ 安全要求: JWT_SECRET必须在生产环境中设置，不能使用默认值
@@ -160,3 +201,41 @@ func main() {
 		logger.Fatal(fmt.Sprintf("服务启动失败: %v", err))
 	}
 }
+
+// startScheduledTasks 启动定时任务
+func startScheduledTasks(tenderService service.TenderService, returnService service.ReturnService) {
+	// 每分钟关闭超时未付清的订单，并撤销/退回已关闭订单的组合支付（含之前释放失败的订单）
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx := context.Background()
+			expired, err := tenderService.ExpireOrders(ctx, 100)
+			if err != nil {
+				logger.Error("关闭超时订单失败", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				logger.Info(fmt.Sprintf("已关闭 %d 个超时订单", expired))
+			}
+		}
+	}()
+
+	// 每分钟重新处理停留在退款中的退货（网关已退款但本地状态未更新，或退款中途进程退出）
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			reconciled, err := returnService.ReconcileRefunding(context.Background(), 50)
+			if err != nil {
+				logger.Error("处理退款中的退货失败", zap.Error(err))
+				continue
+			}
+			if reconciled > 0 {
+				logger.Info(fmt.Sprintf("已完成 %d 个退款中的退货", reconciled))
+			}
+		}
+	}()
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/middleware"
+)
+
+// PaymentClient Payment Gateway HTTP客户端
+type PaymentClient struct {
+	baseURL      string
+	breaker      *httpclient.BreakerClient
+	serviceToken string // 服务间调用令牌（INTERNAL_SERVICE_TOKEN），访问 payment-gateway /api/v1/service/* 路由
+}
+
+// NewPaymentClient 创建Payment Gateway客户端实例（带熔断器）
+func NewPaymentClient(baseURL string) *PaymentClient {
+	config := &httpclient.Config{
+		Timeout:    30 * time.Second,
+		MaxRetries: 3,
+		RetryDelay: time.Second,
+	}
+
+	breakerConfig := httpclient.DefaultBreakerConfig("payment-gateway")
+
+	return &PaymentClient{
+		baseURL:      baseURL,
+		breaker:      httpclient.NewBreakerClient(config, breakerConfig),
+		serviceToken: os.Getenv("INTERNAL_SERVICE_TOKEN"),
+	}
+}
+
+// CreateRefundRequest 创建退款请求
+type CreateRefundRequest struct {
+	PaymentNo    string    `json:"payment_no"`
+	Amount       int64     `json:"amount"`
+	Reason       string    `json:"reason"`
+	Description  string    `json:"description,omitempty"`
+	OperatorID   uuid.UUID `json:"operator_id"` // 退货申请ID，同一退货重试时命中网关幂等
+	OperatorType string    `json:"operator_type"`
+}
+
+// RefundResult 退款结果
+type RefundResult struct {
+	RefundNo string `json:"refund_no"`
+	Status   string `json:"status"` // pending, processing, success, failed
+	ErrorMsg string `json:"error_msg"`
+}
+
+// createRefundResponse 创建退款响应
+type createRefundResponse struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Details string        `json:"details"`
+	Data    *RefundResult `json:"data"`
+}
+
+// CreateRefund 通过 payment-gateway 发起退款（使用熔断器）
+func (c *PaymentClient) CreateRefund(ctx context.Context, req *CreateRefundRequest) (*RefundResult, error) {
+	url := fmt.Sprintf("%s/api/v1/service/refunds", c.baseURL)
+
+	resp, err := c.breaker.Do(&httpclient.Request{
+		Method:  "POST",
+		URL:     url,
+		Body:    req,
+		Headers: middleware.ServiceAuthHeaders("order-service", c.serviceToken),
+		Ctx:     ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+
+	var result createRefundResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "SUCCESS" {
+		return nil, fmt.Errorf("业务错误: %s %s", result.Message, result.Details)
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("退款响应为空")
+	}
+	return result.Data, nil
+}
+
+// cancelPaymentResponse 取消支付响应
+type cancelPaymentResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details"`
+}
+
+// CancelPayment 通过 payment-gateway 取消未完成的支付（订单取消或超时后撤销组合支付中待支付的部分）
+func (c *PaymentClient) CancelPayment(ctx context.Context, paymentNo string, reason string) error {
+	url := fmt.Sprintf("%s/api/v1/service/payments/%s/cancel", c.baseURL, paymentNo)
+
+	resp, err := c.breaker.Do(&httpclient.Request{
+		Method:  "POST",
+		URL:     url,
+		Body:    map[string]string{"reason": reason},
+		Headers: middleware.ServiceAuthHeaders("order-service", c.serviceToken),
+		Ctx:     ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	var result cancelPaymentResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "SUCCESS" {
+		return fmt.Errorf("业务错误: %s %s", result.Message, result.Details)
+	}
+	return nil
+}
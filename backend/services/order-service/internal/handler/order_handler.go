@@ -451,7 +451,7 @@ func (h *OrderHandler) RefundOrder(c *gin.Context) {
 		return
 	}
 
-	err := h.orderService.RefundOrder(c.Request.Context(), orderNo, req.Amount, req.Reason)
+	err := h.orderService.RefundOrder(c.Request.Context(), orderNo, req.RefundID, req.Amount, req.Reason)
 	if err != nil {
 		traceID := middleware.GetRequestID(c)
 		if bizErr, ok := errors.GetBusinessError(err); ok {
@@ -761,8 +761,9 @@ type PayOrderRequest struct {
 }
 
 type RefundOrderRequest struct {
-	Amount int64  `json:"amount" binding:"required,gt=0"`
-	Reason string `json:"reason" binding:"required"`
+	RefundID uuid.UUID `json:"refund_id" binding:"required"` // 调用方生成的退款请求ID，重试时须沿用
+	Amount   int64     `json:"amount" binding:"required,gt=0"`
+	Reason   string    `json:"reason" binding:"required"`
 }
 
 type ShipOrderRequest struct {
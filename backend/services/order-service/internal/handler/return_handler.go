@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"payment-platform/order-service/internal/repository"
+	"payment-platform/order-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+)
+
+// ReturnHandler 退货（RMA）处理器
+type ReturnHandler struct {
+	returnService service.ReturnService
+}
+
+// NewReturnHandler 创建退货处理器实例
+func NewReturnHandler(returnService service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *ReturnHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		// 订单下的退货申请
+		v1.POST("/orders/:orderNo/returns", h.CreateReturn)
+		v1.GET("/orders/:orderNo/returns", h.ListOrderReturns)
+
+		returns := v1.Group("/returns")
+		{
+			returns.GET("", h.ListReturns)
+			returns.GET("/:returnNo", h.GetReturn)
+			returns.POST("/:returnNo/approve", h.ApproveReturn)
+			returns.POST("/:returnNo/reject", h.RejectReturn)
+			returns.PUT("/:returnNo/shipment", h.ShipReturn)
+			returns.POST("/:returnNo/receive", h.ReceiveReturn)
+			returns.POST("/:returnNo/cancel", h.CancelReturn)
+			returns.POST("/:returnNo/refund", h.RetryRefund) // 退款失败后重试
+		}
+	}
+}
+
+// RejectReturnRequest 拒绝退货请求
+type RejectReturnRequest struct {
+	MerchantID uuid.UUID `json:"merchant_id" binding:"required"`
+	ReviewerID uuid.UUID `json:"reviewer_id"`
+	Reason     string    `json:"reason" binding:"required"`
+}
+
+// ShipReturnRequest 退货物流请求
+type ShipReturnRequest struct {
+	Carrier    string `json:"carrier" binding:"required"`
+	TrackingNo string `json:"tracking_no" binding:"required"`
+}
+
+// ReceiveReturnRequest 签收退货请求
+type ReceiveReturnRequest struct {
+	MerchantID uuid.UUID `json:"merchant_id" binding:"required"`
+	ReviewerID uuid.UUID `json:"reviewer_id"`
+}
+
+// CancelReturnRequest 取消退货请求
+type CancelReturnRequest struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+}
+
+// RetryRefundRequest 重试退款请求
+type RetryRefundRequest struct {
+	MerchantID uuid.UUID `json:"merchant_id" binding:"required"`
+}
+
+// CreateReturn 申请退货
+//
+//	@Summary		申请退货
+//	@Description	按订单项和数量申请退货，等待商户审核
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderNo	path		string						true	"订单号"
+//	@Param			request	body		service.CreateReturnInput	true	"退货申请"
+//	@Success		200		{object}	Response
+//	@Failure		400		{object}	Response
+//	@Router			/orders/{orderNo}/returns [post]
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	var input service.CreateReturnInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	ret, err := h.returnService.CreateReturn(c.Request.Context(), c.Param("orderNo"), &input)
+	if err != nil {
+		h.fail(c, err, "申请退货失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// ListOrderReturns 查询订单的退货申请
+//
+//	@Summary	查询订单的退货申请
+//	@Tags		Returns
+//	@Produce	json
+//	@Param		orderNo	path		string	true	"订单号"
+//	@Success	200		{object}	Response
+//	@Router		/orders/{orderNo}/returns [get]
+func (h *ReturnHandler) ListOrderReturns(c *gin.Context) {
+	returns, err := h.returnService.ListOrderReturns(c.Request.Context(), c.Param("orderNo"))
+	if err != nil {
+		h.fail(c, err, "查询退货申请失败")
+		return
+	}
+	h.ok(c, returns)
+}
+
+// ListReturns 分页查询退货申请
+//
+//	@Summary	分页查询退货申请
+//	@Tags		Returns
+//	@Produce	json
+//	@Param		merchant_id	query		string	false	"商户ID"
+//	@Param		status		query		string	false	"退货状态"
+//	@Param		page		query		int		false	"页码"
+//	@Param		page_size	query		int		false	"每页数量"
+//	@Success	200			{object}	Response
+//	@Router		/returns [get]
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	query := &repository.ReturnQuery{
+		Status: c.Query("status"),
+	}
+	if merchantIDStr := c.Query("merchant_id"); merchantIDStr != "" {
+		merchantID, err := uuid.Parse(merchantIDStr)
+		if err != nil {
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).
+				WithTraceID(middleware.GetRequestID(c))
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		query.MerchantID = &merchantID
+	}
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	returns, total, err := h.returnService.ListReturns(c.Request.Context(), query)
+	if err != nil {
+		h.fail(c, err, "查询退货申请失败")
+		return
+	}
+	h.ok(c, PageResponse{
+		List:     returns,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	})
+}
+
+// GetReturn 获取退货申请详情
+//
+//	@Summary	获取退货申请详情
+//	@Tags		Returns
+//	@Produce	json
+//	@Param		returnNo	path		string	true	"退货单号"
+//	@Success	200			{object}	Response
+//	@Failure	404			{object}	Response
+//	@Router		/returns/{returnNo} [get]
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	ret, err := h.returnService.GetReturn(c.Request.Context(), c.Param("returnNo"))
+	if err != nil {
+		h.fail(c, err, "获取退货申请失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// ApproveReturn 商户审核通过退货
+//
+//	@Summary		审核通过退货
+//	@Description	可设置重新上架费；require_shipping=false 时为仅退款，审核通过后立即退款
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			returnNo	path		string						true	"退货单号"
+//	@Param			request		body		service.ApproveReturnInput	true	"审核信息"
+//	@Success		200			{object}	Response
+//	@Failure		400			{object}	Response
+//	@Router			/returns/{returnNo}/approve [post]
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	var input service.ApproveReturnInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	ret, err := h.returnService.ApproveReturn(c.Request.Context(), c.Param("returnNo"), &input)
+	if err != nil {
+		h.fail(c, err, "审核退货失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// RejectReturn 商户拒绝退货
+//
+//	@Summary	拒绝退货
+//	@Tags		Returns
+//	@Accept		json
+//	@Produce	json
+//	@Param		returnNo	path		string				true	"退货单号"
+//	@Param		request		body		RejectReturnRequest	true	"拒绝原因"
+//	@Success	200			{object}	Response
+//	@Router		/returns/{returnNo}/reject [post]
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	var req RejectReturnRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	if err := h.returnService.RejectReturn(c.Request.Context(), c.Param("returnNo"), req.MerchantID, req.ReviewerID, req.Reason); err != nil {
+		h.fail(c, err, "拒绝退货失败")
+		return
+	}
+	h.ok(c, nil)
+}
+
+// ShipReturn 填写退货物流
+//
+//	@Summary	填写退货物流
+//	@Tags		Returns
+//	@Accept		json
+//	@Produce	json
+//	@Param		returnNo	path		string				true	"退货单号"
+//	@Param		request		body		ShipReturnRequest	true	"物流信息"
+//	@Success	200			{object}	Response
+//	@Router		/returns/{returnNo}/shipment [put]
+func (h *ReturnHandler) ShipReturn(c *gin.Context) {
+	var req ShipReturnRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	ret, err := h.returnService.ShipReturn(c.Request.Context(), c.Param("returnNo"), req.Carrier, req.TrackingNo)
+	if err != nil {
+		h.fail(c, err, "更新退货物流失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// ReceiveReturn 商户签收退货并自动退款
+//
+//	@Summary	签收退货
+//	@Tags		Returns
+//	@Accept		json
+//	@Produce	json
+//	@Param		returnNo	path		string					true	"退货单号"
+//	@Param		request		body		ReceiveReturnRequest	true	"签收信息"
+//	@Success	200			{object}	Response
+//	@Router		/returns/{returnNo}/receive [post]
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	var req ReceiveReturnRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	ret, err := h.returnService.ReceiveReturn(c.Request.Context(), c.Param("returnNo"), req.MerchantID, req.ReviewerID)
+	if err != nil {
+		h.fail(c, err, "签收退货失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// CancelReturn 取消退货申请
+//
+//	@Summary	取消退货申请
+//	@Tags		Returns
+//	@Accept		json
+//	@Produce	json
+//	@Param		returnNo	path		string				true	"退货单号"
+//	@Param		request		body		CancelReturnRequest	false	"申请人"
+//	@Success	200			{object}	Response
+//	@Router		/returns/{returnNo}/cancel [post]
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	var req CancelReturnRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.returnService.CancelReturn(c.Request.Context(), c.Param("returnNo"), req.RequesterID); err != nil {
+		h.fail(c, err, "取消退货失败")
+		return
+	}
+	h.ok(c, nil)
+}
+
+// RetryRefund 重试退货退款
+//
+//	@Summary	重试退货退款
+//	@Tags		Returns
+//	@Accept		json
+//	@Produce	json
+//	@Param		returnNo	path		string				true	"退货单号"
+//	@Param		request		body		RetryRefundRequest	true	"商户信息"
+//	@Success	200			{object}	Response
+//	@Router		/returns/{returnNo}/refund [post]
+func (h *ReturnHandler) RetryRefund(c *gin.Context) {
+	var req RetryRefundRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	ret, err := h.returnService.RetryRefund(c.Request.Context(), c.Param("returnNo"), req.MerchantID)
+	if err != nil {
+		h.fail(c, err, "退款失败")
+		return
+	}
+	h.ok(c, ret)
+}
+
+// bind 绑定请求参数，失败时直接返回 400
+func (h *ReturnHandler) bind(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return false
+	}
+	return true
+}
+
+// fail 业务错误使用对应的 HTTP 状态码，其他错误返回 500
+func (h *ReturnHandler) fail(c *gin.Context, err error, message string) {
+	traceID := middleware.GetRequestID(c)
+	if bizErr, ok := errors.GetBusinessError(err); ok {
+		resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+		c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		return
+	}
+	resp := errors.NewErrorResponse(errors.ErrCodeInternalError, message, err.Error()).WithTraceID(traceID)
+	c.JSON(http.StatusInternalServerError, resp)
+}
+
+// ok 返回成功响应
+func (h *ReturnHandler) ok(c *gin.Context, data interface{}) {
+	resp := errors.NewSuccessResponse(data).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"payment-platform/order-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/tax"
+)
+
+// TaxHandler 税费处理器
+type TaxHandler struct {
+	taxService service.TaxService
+}
+
+// NewTaxHandler 创建税费处理器实例
+func NewTaxHandler(taxService service.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *TaxHandler) RegisterRoutes(router *gin.Engine) {
+	taxGroup := router.Group("/api/v1/tax")
+	{
+		taxGroup.GET("/rules", h.ListRules)
+		taxGroup.POST("/rules", h.SaveRule)
+		taxGroup.DELETE("/rules/:id", h.DeleteRule)
+		taxGroup.POST("/calculate", h.Calculate)
+		taxGroup.GET("/report", h.GetTaxReport)
+	}
+}
+
+// ListRules 查询税率规则
+//
+//	@Summary	查询税率规则
+//	@Tags		Tax
+//	@Produce	json
+//	@Param		country	query		string	false	"国家代码"
+//	@Success	200		{object}	Response
+//	@Router		/tax/rules [get]
+func (h *TaxHandler) ListRules(c *gin.Context) {
+	rules, err := h.taxService.ListRules(c.Request.Context(), c.Query("country"))
+	if err != nil {
+		h.fail(c, err, "查询税率规则失败")
+		return
+	}
+	h.ok(c, rules)
+}
+
+// SaveRule 新增或覆盖税率规则
+//
+//	@Summary		新增或覆盖税率规则
+//	@Description	按 国家+地区+类别 覆盖内置税率表；某国家配置规则后不再使用该国家的内置税率
+//	@Tags			Tax
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		service.TaxRuleInput	true	"税率规则"
+//	@Success		200		{object}	Response
+//	@Router			/tax/rules [post]
+func (h *TaxHandler) SaveRule(c *gin.Context) {
+	var input service.TaxRuleInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	rule, err := h.taxService.SaveRule(c.Request.Context(), &input)
+	if err != nil {
+		h.fail(c, err, "保存税率规则失败")
+		return
+	}
+	h.ok(c, rule)
+}
+
+// DeleteRule 删除税率规则
+//
+//	@Summary	删除税率规则
+//	@Tags		Tax
+//	@Produce	json
+//	@Param		id	path		string	true	"规则ID"
+//	@Success	200	{object}	Response
+//	@Router		/tax/rules/{id} [delete]
+func (h *TaxHandler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的规则ID", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	if err := h.taxService.DeleteRule(c.Request.Context(), id); err != nil {
+		h.fail(c, err, "删除税率规则失败")
+		return
+	}
+	h.ok(c, nil)
+}
+
+// Calculate 计税试算
+//
+//	@Summary	计税试算
+//	@Tags		Tax
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		tax.Request	true	"计税请求"
+//	@Success	200		{object}	Response
+//	@Router		/tax/calculate [post]
+func (h *TaxHandler) Calculate(c *gin.Context) {
+	var req tax.Request
+	if !h.bind(c, &req) {
+		return
+	}
+
+	result, err := h.taxService.Calculate(c.Request.Context(), &req)
+	if err != nil {
+		h.fail(c, err, "计税失败")
+		return
+	}
+	h.ok(c, result)
+}
+
+// GetTaxReport 商户税务报表
+//
+//	@Summary		商户税务报表
+//	@Description	按支付时间统计商户订单的计税金额、税额和退货退回税额
+//	@Tags			Tax
+//	@Produce		json
+//	@Param			merchant_id	query		string	true	"商户ID"
+//	@Param			start_time	query		string	true	"开始时间 (RFC3339)"
+//	@Param			end_time	query		string	true	"结束时间 (RFC3339)"
+//	@Success		200			{object}	Response
+//	@Router			/tax/report [get]
+func (h *TaxHandler) GetTaxReport(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+
+	merchantID, err := uuid.Parse(c.Query("merchant_id"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, c.Query("start_time"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的开始时间", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, c.Query("end_time"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的结束时间", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	report, err := h.taxService.GetTaxReport(c.Request.Context(), merchantID, startTime, endTime)
+	if err != nil {
+		h.fail(c, err, "查询税务报表失败")
+		return
+	}
+	h.ok(c, report)
+}
+
+func (h *TaxHandler) bind(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return false
+	}
+	return true
+}
+
+// fail 业务错误使用对应的 HTTP 状态码，其他错误返回 500
+func (h *TaxHandler) fail(c *gin.Context, err error, message string) {
+	traceID := middleware.GetRequestID(c)
+	if bizErr, ok := errors.GetBusinessError(err); ok {
+		resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+		c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		return
+	}
+	resp := errors.NewErrorResponse(errors.ErrCodeInternalError, message, err.Error()).WithTraceID(traceID)
+	c.JSON(http.StatusInternalServerError, resp)
+}
+
+// ok 返回成功响应
+func (h *TaxHandler) ok(c *gin.Context, data interface{}) {
+	resp := errors.NewSuccessResponse(data).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+
+	"payment-platform/order-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+)
+
+// TenderHandler 组合支付处理器
+type TenderHandler struct {
+	tenderService service.TenderService
+}
+
+// NewTenderHandler 创建组合支付处理器实例
+func NewTenderHandler(tenderService service.TenderService) *TenderHandler {
+	return &TenderHandler{
+		tenderService: tenderService,
+	}
+}
+
+// RegisterRoutes 注册路由
+func (h *TenderHandler) RegisterRoutes(router *gin.Engine) {
+	payments := router.Group("/api/v1/orders/:orderNo/payments")
+	{
+		payments.POST("", h.AddPayment)
+		payments.GET("", h.ListPayments)
+		payments.POST("/:paymentNo/confirm", h.ConfirmPayment)
+		payments.POST("/:paymentNo/fail", h.FailPayment)
+		payments.POST("/release", h.ReleasePayments) // 释放失败后手动重试
+	}
+}
+
+// FailPaymentRequest 支付失败请求
+type FailPaymentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReleasePaymentsRequest 释放组合支付请求
+type ReleasePaymentsRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AddPayment 登记一笔支付
+//
+//	@Summary		登记一笔支付
+//	@Description	组合支付：按支付方式逐笔登记，已付与待支付金额合计不能超过订单实付金额
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderNo	path		string					true	"订单号"
+//	@Param			request	body		service.AddPaymentInput	true	"支付信息"
+//	@Success		200		{object}	Response
+//	@Router			/orders/{orderNo}/payments [post]
+func (h *TenderHandler) AddPayment(c *gin.Context) {
+	var input service.AddPaymentInput
+	if !h.bind(c, &input) {
+		return
+	}
+
+	payment, err := h.tenderService.AddPayment(c.Request.Context(), c.Param("orderNo"), &input)
+	if err != nil {
+		h.fail(c, err, "登记支付失败")
+		return
+	}
+	h.ok(c, payment)
+}
+
+// ListPayments 查询订单的支付记录
+//
+//	@Summary	查询订单的支付记录
+//	@Tags		Payments
+//	@Produce	json
+//	@Param		orderNo	path		string	true	"订单号"
+//	@Success	200		{object}	Response
+//	@Router		/orders/{orderNo}/payments [get]
+func (h *TenderHandler) ListPayments(c *gin.Context) {
+	payments, err := h.tenderService.ListPayments(c.Request.Context(), c.Param("orderNo"))
+	if err != nil {
+		h.fail(c, err, "查询支付记录失败")
+		return
+	}
+	h.ok(c, payments)
+}
+
+// ConfirmPayment 确认支付成功
+//
+//	@Summary		确认支付成功
+//	@Description	只能确认已登记的支付；已付金额达到实付金额时订单变为已支付，否则为部分支付
+//	@Tags			Payments
+//	@Produce		json
+//	@Param			orderNo		path		string	true	"订单号"
+//	@Param			paymentNo	path		string	true	"支付流水号"
+//	@Success		200			{object}	Response
+//	@Failure		404			{object}	Response
+//	@Router			/orders/{orderNo}/payments/{paymentNo}/confirm [post]
+func (h *TenderHandler) ConfirmPayment(c *gin.Context) {
+	order, err := h.tenderService.ConfirmPayment(c.Request.Context(), c.Param("orderNo"), c.Param("paymentNo"))
+	if err != nil {
+		h.fail(c, err, "确认支付失败")
+		return
+	}
+	h.ok(c, order)
+}
+
+// FailPayment 标记支付失败
+//
+//	@Summary	标记支付失败
+//	@Tags		Payments
+//	@Accept		json
+//	@Produce	json
+//	@Param		orderNo		path		string				true	"订单号"
+//	@Param		paymentNo	path		string				true	"支付流水号"
+//	@Param		request		body		FailPaymentRequest	true	"失败原因"
+//	@Success	200			{object}	Response
+//	@Router		/orders/{orderNo}/payments/{paymentNo}/fail [post]
+func (h *TenderHandler) FailPayment(c *gin.Context) {
+	var req FailPaymentRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	if err := h.tenderService.FailPayment(c.Request.Context(), c.Param("orderNo"), c.Param("paymentNo"), req.Reason); err != nil {
+		h.fail(c, err, "更新支付状态失败")
+		return
+	}
+	h.ok(c, nil)
+}
+
+// ReleasePayments 撤销/退回已关闭订单的支付
+//
+//	@Summary		撤销/退回已关闭订单的支付
+//	@Description	订单取消或超时后自动释放失败时手动重试
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			orderNo	path		string					true	"订单号"
+//	@Param			request	body		ReleasePaymentsRequest	true	"原因"
+//	@Success		200		{object}	Response
+//	@Router			/orders/{orderNo}/payments/release [post]
+func (h *TenderHandler) ReleasePayments(c *gin.Context) {
+	var req ReleasePaymentsRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	if err := h.tenderService.ReleaseTenders(c.Request.Context(), c.Param("orderNo"), req.Reason); err != nil {
+		h.fail(c, err, "释放支付失败")
+		return
+	}
+	h.ok(c, nil)
+}
+
+func (h *TenderHandler) bind(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return false
+	}
+	return true
+}
+
+// fail 业务错误使用对应的 HTTP 状态码，其他错误返回 500
+func (h *TenderHandler) fail(c *gin.Context, err error, message string) {
+	traceID := middleware.GetRequestID(c)
+	if bizErr, ok := errors.GetBusinessError(err); ok {
+		resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+		c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		return
+	}
+	resp := errors.NewErrorResponse(errors.ErrCodeInternalError, message, err.Error()).WithTraceID(traceID)
+	c.JSON(http.StatusInternalServerError, resp)
+}
+
+// ok 返回成功响应
+func (h *TenderHandler) ok(c *gin.Context, data interface{}) {
+	resp := errors.NewSuccessResponse(data).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
@@ -16,6 +16,12 @@ type Order struct {
 	TotalAmount     int64          `gorm:"type:bigint;not null" json:"total_amount"`                 // 订单总金额（分）
 	PayAmount       int64          `gorm:"type:bigint;not null" json:"pay_amount"`                   // 实付金额（分）
 	DiscountAmount  int64          `gorm:"type:bigint;default:0" json:"discount_amount"`             // 优惠金额（分）
+	RefundedAmount  int64          `gorm:"type:bigint;default:0" json:"refunded_amount"`             // 累计退款金额（分）
+	PaidAmount      int64          `gorm:"type:bigint;default:0" json:"paid_amount"`                 // 已支付金额（分，组合支付时逐笔累计）
+	TaxAmount       int64          `gorm:"type:bigint;default:0" json:"tax_amount"`                  // 税额（分）
+	TaxInclusive    bool           `gorm:"default:false" json:"tax_inclusive"`                       // 商品价格是否含税（含税时税额不再加到实付金额）
+	CustomerTaxID   string         `gorm:"type:varchar(50)" json:"customer_tax_id"`                  // 客户税号（B2B）
+	ReverseCharge   bool           `gorm:"default:false" json:"reverse_charge"`                      // 是否适用反向征收
 	ShippingFee     int64          `gorm:"type:bigint;default:0" json:"shipping_fee"`                // 运费（分）
 	Currency        string         `gorm:"type:varchar(10);not null" json:"currency"`                // 货币类型
 	Status          string         `gorm:"type:varchar(20);not null;index;index:idx_merchant_status_created,priority:2" json:"status"`  // 订单状态
@@ -42,7 +48,9 @@ type Order struct {
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 关联
-	Items []*OrderItem `gorm:"foreignKey:OrderID" json:"items,omitempty"`
+	Items    []*OrderItem    `gorm:"foreignKey:OrderID" json:"items,omitempty"`
+	TaxLines []*OrderTaxLine `gorm:"foreignKey:OrderID" json:"tax_lines,omitempty"`
+	Payments []*OrderPayment `gorm:"foreignKey:OrderID" json:"payments,omitempty"`
 }
 
 // TableName 指定表名
@@ -62,6 +70,10 @@ type OrderItem struct {
 	Quantity      int       `gorm:"type:integer;not null" json:"quantity"`             // 数量
 	TotalPrice    int64     `gorm:"type:bigint;not null" json:"total_price"`           // 小计（分）
 	DiscountPrice int64     `gorm:"type:bigint;default:0" json:"discount_price"`       // 优惠金额（分）
+	RefundedQuantity int    `gorm:"type:integer;default:0" json:"refunded_quantity"`  // 已退款数量
+	RefundedAmount int64    `gorm:"type:bigint;default:0" json:"refunded_amount"`     // 已退款金额（分，未扣除重新上架费）
+	TaxCategory   string    `gorm:"type:varchar(20)" json:"tax_category"`              // 税务类别
+	TaxAmount     int64     `gorm:"type:bigint;default:0" json:"tax_amount"`           // 税额（分）
 	Attributes    string    `gorm:"type:jsonb" json:"attributes"`                      // 商品属性（JSON）
 	Extra         string    `gorm:"type:jsonb" json:"extra"`                           // 扩展信息（JSON）
 	CreatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
@@ -130,6 +142,7 @@ const (
 const (
 	PayStatusPending = "pending" // 待支付
 	PayStatusPaid    = "paid"    // 已支付
+	PayStatusPartialPaid = "partial_paid" // 部分支付（组合支付未付清）
 	PayStatusFailed  = "failed"  // 支付失败
 	PayStatusRefunded = "refunded" // 已退款
 	PayStatusPartialRefunded = "partial_refunded" // 部分退款
@@ -154,6 +167,8 @@ const (
 	OrderActionComplete   = "complete"    // 完成
 	OrderActionRefund     = "refund"      // 退款
 	OrderActionUpdateStatus = "update_status" // 更新状态
+	OrderActionExpire     = "expire"      // 超时关闭
+	OrderActionTenderRelease = "tender_release" // 撤销/退回组合支付
 )
 
 // 配送方式常量
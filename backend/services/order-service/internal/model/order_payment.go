@@ -0,0 +1,174 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderPayment 订单支付记录（组合支付：一笔订单可由礼品卡、钱包、银行卡等多笔支付共同完成）
+type OrderPayment struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`                // 订单ID
+	PaymentNo      string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"payment_no"` // 支付流水号
+	TenderType     string     `gorm:"type:varchar(30);not null" json:"tender_type"`            // 支付方式：card、wallet、gift_card 等
+	Sequence       int        `gorm:"type:integer;not null" json:"sequence"`                   // 支付顺序（从 1 开始）
+	Amount         int64      `gorm:"type:bigint;not null" json:"amount"`                      // 支付金额（分）
+	RefundedAmount int64      `gorm:"type:bigint;default:0" json:"refunded_amount"`            // 已退款金额（分）
+	Status         string     `gorm:"type:varchar(20);not null;index" json:"status"`           // 支付状态
+	FailureReason  string     `gorm:"type:text" json:"failure_reason,omitempty"`               // 失败/撤销原因
+	PaidAt         *time.Time `gorm:"type:timestamptz" json:"paid_at,omitempty"`               // 支付成功时间
+	VoidedAt       *time.Time `gorm:"type:timestamptz" json:"voided_at,omitempty"`             // 撤销时间
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (OrderPayment) TableName() string {
+	return "order_payments"
+}
+
+// Refundable 可退款金额
+func (p *OrderPayment) Refundable() int64 {
+	if p.Status != TenderStatusSucceeded {
+		return 0
+	}
+	return p.Amount - p.RefundedAmount
+}
+
+// OrderPaymentRefund 订单退款在各笔支付间的分摊明细（同一来源重试时沿用首次分摊结果）
+type OrderPaymentRefund struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID        uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`                                                    // 订单ID
+	OrderPaymentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_refund_source,priority:2" json:"order_payment_id"` // 支付记录ID
+	PaymentNo      string    `gorm:"type:varchar(64);not null" json:"payment_no"`                                                 // 支付流水号
+	SourceID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_refund_source,priority:1" json:"source_id"`        // 退款来源ID（退货单ID、退款请求ID、被释放的支付记录ID）
+	Amount         int64     `gorm:"type:bigint;not null" json:"amount"`                                                          // 退款金额（分）
+	RefundNo       string    `gorm:"type:varchar(64)" json:"refund_no"`                                                           // payment-gateway 退款单号
+	Status         string    `gorm:"type:varchar(20);not null" json:"status"`                                                     // 退款状态
+	FailureReason  string    `gorm:"type:text" json:"failure_reason,omitempty"`                                                   // 失败原因
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (OrderPaymentRefund) TableName() string {
+	return "order_payment_refunds"
+}
+
+// 支付记录状态常量
+const (
+	TenderStatusPending   = "pending"   // 待支付
+	TenderStatusSucceeded = "succeeded" // 支付成功
+	TenderStatusFailed    = "failed"    // 支付失败
+	TenderStatusVoided    = "voided"    // 已撤销（订单取消或超时）
+)
+
+// 分摊退款状态常量
+const (
+	TenderRefundStatusPending = "pending" // 待退款
+	TenderRefundStatusSuccess = "success" // 退款成功
+	TenderRefundStatusFailed  = "failed"  // 退款失败
+)
+
+// 退款分摊策略常量
+const (
+	RefundStrategyReverse      = "reverse"      // 后付先退（默认）
+	RefundStrategySequential   = "sequential"   // 先付先退
+	RefundStrategyProportional = "proportional" // 按各笔可退金额比例分摊
+)
+
+// TenderAllocation 单笔支付分摊的退款金额
+type TenderAllocation struct {
+	Payment *OrderPayment
+	Amount  int64
+}
+
+// DueAmount 待支付金额（实付金额减去已成功支付的金额）
+func (o *Order) DueAmount() int64 {
+	if due := o.PayAmount - o.PaidAmount; due > 0 {
+		return due
+	}
+	return 0
+}
+
+// AllocateRefund 按策略将退款金额分摊到支付成功的各笔支付
+func AllocateRefund(payments []*OrderPayment, amount int64, strategy string) ([]TenderAllocation, error) {
+	var tenders []*OrderPayment
+	refundable := int64(0)
+	for _, p := range payments {
+		if p.Refundable() > 0 {
+			tenders = append(tenders, p)
+			refundable += p.Refundable()
+		}
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("退款金额必须大于 0")
+	}
+	if amount > refundable {
+		return nil, fmt.Errorf("退款金额 %d 超过可退金额 %d", amount, refundable)
+	}
+
+	sort.Slice(tenders, func(i, j int) bool { return tenders[i].Sequence < tenders[j].Sequence })
+
+	switch strategy {
+	case RefundStrategySequential:
+		// 按支付顺序依次退
+	case RefundStrategyProportional:
+		return allocateProportional(tenders, amount, refundable), nil
+	case RefundStrategyReverse, "":
+		for i, j := 0, len(tenders)-1; i < j; i, j = i+1, j-1 {
+			tenders[i], tenders[j] = tenders[j], tenders[i]
+		}
+	default:
+		return nil, fmt.Errorf("不支持的退款分摊策略: %s", strategy)
+	}
+
+	var allocations []TenderAllocation
+	remaining := amount
+	for _, p := range tenders {
+		if remaining == 0 {
+			break
+		}
+		part := p.Refundable()
+		if part > remaining {
+			part = remaining
+		}
+		allocations = append(allocations, TenderAllocation{Payment: p, Amount: part})
+		remaining -= part
+	}
+	return allocations, nil
+}
+
+// allocateProportional 按可退金额比例向下取整分摊，取整差额按支付顺序补到仍有余额的支付上
+func allocateProportional(tenders []*OrderPayment, amount, refundable int64) []TenderAllocation {
+	parts := make([]int64, len(tenders))
+	allocated := int64(0)
+	for i, p := range tenders {
+		parts[i] = amount * p.Refundable() / refundable
+		allocated += parts[i]
+	}
+	for i, p := range tenders {
+		if allocated == amount {
+			break
+		}
+		if spare := p.Refundable() - parts[i]; spare > 0 {
+			add := amount - allocated
+			if add > spare {
+				add = spare
+			}
+			parts[i] += add
+			allocated += add
+		}
+	}
+
+	var allocations []TenderAllocation
+	for i, p := range tenders {
+		if parts[i] > 0 {
+			allocations = append(allocations, TenderAllocation{Payment: p, Amount: parts[i]})
+		}
+	}
+	return allocations
+}
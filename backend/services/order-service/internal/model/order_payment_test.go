@@ -0,0 +1,46 @@
+package model
+
+import "testing"
+
+func TestAllocateRefundStrategies(t *testing.T) {
+	// 礼品卡 3000 + 钱包 2000 + 银行卡 5000，银行卡已退 1000，钱包支付失败不参与分摊
+	giftCard := &OrderPayment{PaymentNo: "P1", Sequence: 1, Amount: 3000, Status: TenderStatusSucceeded}
+	wallet := &OrderPayment{PaymentNo: "P2", Sequence: 2, Amount: 2000, Status: TenderStatusFailed}
+	card := &OrderPayment{PaymentNo: "P3", Sequence: 3, Amount: 5000, RefundedAmount: 1000, Status: TenderStatusSucceeded}
+	payments := []*OrderPayment{card, wallet, giftCard}
+
+	tests := []struct {
+		strategy string
+		amount   int64
+		want     map[string]int64
+	}{
+		{RefundStrategyReverse, 5000, map[string]int64{"P3": 4000, "P1": 1000}},
+		{RefundStrategySequential, 5000, map[string]int64{"P1": 3000, "P3": 2000}},
+		// 1000 按 3000:4000 分摊 -> 428 + 571，取整差额 1 补到第一笔
+		{RefundStrategyProportional, 1000, map[string]int64{"P1": 429, "P3": 571}},
+	}
+	for _, tt := range tests {
+		allocations, err := AllocateRefund(payments, tt.amount, tt.strategy)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.strategy, err)
+		}
+		got := make(map[string]int64)
+		total := int64(0)
+		for _, a := range allocations {
+			got[a.Payment.PaymentNo] = a.Amount
+			total += a.Amount
+		}
+		if total != tt.amount {
+			t.Fatalf("%s: allocated %d, want %d", tt.strategy, total, tt.amount)
+		}
+		for paymentNo, want := range tt.want {
+			if got[paymentNo] != want {
+				t.Fatalf("%s: %s = %d, want %d (%v)", tt.strategy, paymentNo, got[paymentNo], want, got)
+			}
+		}
+	}
+
+	if _, err := AllocateRefund(payments, 7001, RefundStrategyReverse); err == nil {
+		t.Fatal("expected error when refund exceeds refundable amount")
+	}
+}
@@ -0,0 +1,168 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReturnRequest 退货申请（RMA）
+type ReturnRequest struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReturnNo        string     `gorm:"type:varchar(64);unique;not null" json:"return_no"`                        // 退货单号
+	OrderID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`                                 // 订单ID
+	OrderNo         string     `gorm:"type:varchar(64);not null;index" json:"order_no"`                          // 订单号
+	MerchantID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_return_merchant_status" json:"merchant_id"`   // 商户ID
+	PaymentNo       string     `gorm:"type:varchar(64)" json:"payment_no"`                                       // 原支付流水号
+	Status          string     `gorm:"type:varchar(20);not null;index:idx_return_merchant_status" json:"status"` // 退货状态
+	Reason          string     `gorm:"type:varchar(200);not null" json:"reason"`                                 // 退货原因
+	Description     string     `gorm:"type:text" json:"description"`                                             // 退货说明
+	RequireShipping bool       `gorm:"default:true" json:"require_shipping"`                                     // 是否需要寄回商品（仅退款为 false）
+	Carrier         string     `gorm:"type:varchar(50)" json:"carrier"`                                          // 退货物流公司
+	TrackingNo      string     `gorm:"type:varchar(100)" json:"tracking_no"`                                     // 退货物流单号
+	ItemAmount      int64      `gorm:"type:bigint;not null;default:0" json:"item_amount"`                        // 退货商品金额（分）
+	RestockingFee   int64      `gorm:"type:bigint;not null;default:0" json:"restocking_fee"`                     // 重新上架费（分，从退款中扣除）
+	RefundAmount    int64      `gorm:"type:bigint;not null;default:0" json:"refund_amount"`                      // 实际退款金额（分）
+	Currency        string     `gorm:"type:varchar(10);not null" json:"currency"`                                // 货币类型
+	RefundNo        string     `gorm:"type:varchar(64);index" json:"refund_no"`                                  // payment-gateway 退款单号
+	RefundError     string     `gorm:"type:text" json:"refund_error"`                                            // 退款失败原因
+	RejectReason    string     `gorm:"type:varchar(200)" json:"reject_reason"`                                   // 拒绝原因
+	RequesterID     uuid.UUID  `gorm:"type:uuid" json:"requester_id"`                                            // 申请人ID
+	RequesterType   string     `gorm:"type:varchar(20)" json:"requester_type"`                                   // 申请人类型
+	ReviewerID      uuid.UUID  `gorm:"type:uuid" json:"reviewer_id"`                                             // 审核人ID
+	ApprovedAt      *time.Time `gorm:"type:timestamptz" json:"approved_at"`                                      // 审核通过时间
+	ShippedAt       *time.Time `gorm:"type:timestamptz" json:"shipped_at"`                                       // 客户寄回时间
+	ReceivedAt      *time.Time `gorm:"type:timestamptz" json:"received_at"`                                      // 商户签收时间
+	RefundStartedAt *time.Time `gorm:"type:timestamptz" json:"refund_started_at"`                                // 首次发起退款时间（退款金额此时确定，重试沿用）
+	RefundedAt      *time.Time `gorm:"type:timestamptz" json:"refunded_at"`                                      // 退款完成时间
+	ClosedAt        *time.Time `gorm:"type:timestamptz" json:"closed_at"`                                        // 拒绝或取消时间
+	CreatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+
+	// 关联
+	Items []*ReturnItem `gorm:"foreignKey:ReturnID" json:"items,omitempty"`
+}
+
+// TableName 指定表名
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem 退货商品明细
+type ReturnItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReturnID    uuid.UUID `gorm:"type:uuid;not null;index" json:"return_id"`     // 退货申请ID
+	OrderItemID uuid.UUID `gorm:"type:uuid;not null;index" json:"order_item_id"` // 订单项ID
+	ProductID   string    `gorm:"type:varchar(64)" json:"product_id"`            // 商品ID
+	ProductName string    `gorm:"type:varchar(200)" json:"product_name"`         // 商品名称
+	ProductSKU  string    `gorm:"type:varchar(100)" json:"product_sku"`          // 商品SKU
+	Quantity    int       `gorm:"type:integer;not null" json:"quantity"`         // 退货数量
+	Amount      int64     `gorm:"type:bigint;not null;default:0" json:"amount"`  // 退货金额（分，未扣除重新上架费）
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
+
+// 退货状态常量
+const (
+	ReturnStatusRequested    = "requested"     // 待审核
+	ReturnStatusApproved     = "approved"      // 已同意，等待客户寄回
+	ReturnStatusRejected     = "rejected"      // 已拒绝
+	ReturnStatusShipped      = "shipped"       // 客户已寄回
+	ReturnStatusReceived     = "received"      // 商户已签收
+	ReturnStatusRefunding    = "refunding"     // 退款中
+	ReturnStatusRefunded     = "refunded"      // 已退款
+	ReturnStatusRefundFailed = "refund_failed" // 退款失败，可重试
+	ReturnStatusCancelled    = "cancelled"     // 已取消
+)
+
+// ActiveReturnStatuses 占用可退数量的退货状态（未关闭且未完成退款）
+var ActiveReturnStatuses = []string{
+	ReturnStatusRequested,
+	ReturnStatusApproved,
+	ReturnStatusShipped,
+	ReturnStatusReceived,
+	ReturnStatusRefunding,
+	ReturnStatusRefundFailed,
+}
+
+// 退货相关的订单操作类型
+const (
+	OrderActionReturnRequest = "return_request" // 申请退货
+	OrderActionReturnApprove = "return_approve" // 同意退货
+	OrderActionReturnReject  = "return_reject"  // 拒绝退货
+	OrderActionReturnReceive = "return_receive" // 签收退货
+)
+
+// ItemNetAmount 订单项分摊订单级优惠后的金额（计税基数，不含运费）
+func ItemNetAmount(order *Order, item *OrderItem) int64 {
+	itemsTotal := int64(0)
+	for _, it := range order.Items {
+		itemsTotal += it.TotalPrice
+	}
+	if itemsTotal <= 0 {
+		return 0
+	}
+
+	discount := order.DiscountAmount
+	if discount > itemsTotal {
+		discount = itemsTotal
+	}
+	return item.TotalPrice - discount*item.TotalPrice/itemsTotal
+}
+
+// ItemPaidAmount 订单项的实付金额（价外税计入实付金额）
+func ItemPaidAmount(order *Order, item *OrderItem) int64 {
+	paid := ItemNetAmount(order, item)
+	if !order.TaxInclusive {
+		paid += item.TaxAmount
+	}
+	return paid
+}
+
+// ItemReturnAmount 计算退回 quantity 件商品的退款金额
+//
+// 按件数比例向下取整；退回最后几件时返还该订单项剩余的全部实付金额，保证全部退回时金额合计与实付一致。
+func ItemReturnAmount(order *Order, item *OrderItem, quantity int) int64 {
+	if quantity <= 0 || item.Quantity <= 0 {
+		return 0
+	}
+
+	paid := ItemPaidAmount(order, item)
+	if item.RefundedQuantity+quantity >= item.Quantity {
+		return paid - item.RefundedAmount
+	}
+	return paid * int64(quantity) / int64(item.Quantity)
+}
+
+// RefundPayStatus 根据累计退款金额和已退数量计算订单支付状态
+//
+// 退款金额达到实付金额或全部商品均已退回时为全额退款，否则有退款即为部分退款。
+func RefundPayStatus(order *Order) string {
+	if order.RefundedAmount >= order.PayAmount && order.RefundedAmount > 0 {
+		return PayStatusRefunded
+	}
+
+	allRefunded := len(order.Items) > 0
+	anyRefunded := order.RefundedAmount > 0
+	for _, item := range order.Items {
+		if item.RefundedQuantity < item.Quantity {
+			allRefunded = false
+		}
+		if item.RefundedQuantity > 0 {
+			anyRefunded = true
+		}
+	}
+
+	switch {
+	case allRefunded:
+		return PayStatusRefunded
+	case anyRefunded:
+		return PayStatusPartialRefunded
+	default:
+		return order.PayStatus
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import "testing"
+
+func TestItemReturnAmountSumsToPaidAmount(t *testing.T) {
+	// 两件商品共 10000，运费 500 不退，订单优惠 1000 按商品金额分摊
+	shirt := &OrderItem{UnitPrice: 1000, Quantity: 3, TotalPrice: 3000}
+	shoes := &OrderItem{UnitPrice: 7000, Quantity: 1, TotalPrice: 7000}
+	order := &Order{
+		TotalAmount:    10500,
+		ShippingFee:    500,
+		DiscountAmount: 1000,
+		PayAmount:      9500,
+		PayStatus:      PayStatusPaid,
+		Items:          []*OrderItem{shirt, shoes},
+	}
+
+	if got := ItemPaidAmount(order, shirt); got != 2700 {
+		t.Fatalf("ItemPaidAmount(shirt) = %d, want 2700", got)
+	}
+
+	// 逐件退回，最后一件补齐取整差额
+	refunded := int64(0)
+	for _, want := range []int64{900, 900, 900} {
+		got := ItemReturnAmount(order, shirt, 1)
+		if got != want {
+			t.Fatalf("ItemReturnAmount(shirt, 1) = %d, want %d", got, want)
+		}
+		shirt.RefundedQuantity++
+		shirt.RefundedAmount += got
+		refunded += got
+	}
+	order.RefundedAmount = refunded
+
+	if got := RefundPayStatus(order); got != PayStatusPartialRefunded {
+		t.Fatalf("RefundPayStatus after shirts = %s, want %s", got, PayStatusPartialRefunded)
+	}
+
+	// 全部商品退回后即使运费未退也视为全额退款
+	order.RefundedAmount += ItemReturnAmount(order, shoes, 1)
+	shoes.RefundedQuantity = 1
+	if order.RefundedAmount != 9000 {
+		t.Fatalf("refunded amount = %d, want 9000", order.RefundedAmount)
+	}
+	if got := RefundPayStatus(order); got != PayStatusRefunded {
+		t.Fatalf("RefundPayStatus after all items = %s, want %s", got, PayStatusRefunded)
+	}
+}
+
+func TestItemReturnAmountLastUnitTakesRemainder(t *testing.T) {
+	item := &OrderItem{UnitPrice: 333, Quantity: 3, TotalPrice: 1000}
+	order := &Order{PayAmount: 1000, Items: []*OrderItem{item}}
+
+	first := ItemReturnAmount(order, item, 2)
+	item.RefundedQuantity, item.RefundedAmount = 2, first
+	last := ItemReturnAmount(order, item, 1)
+
+	if first != 666 || last != 334 {
+		t.Fatalf("got %d + %d, want 666 + 334", first, last)
+	}
+}
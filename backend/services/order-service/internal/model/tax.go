@@ -0,0 +1,155 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/tax"
+)
+
+// OrderTaxLine 订单税费明细（每个订单项一行，运费单独一行）
+type OrderTaxLine struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"order_id"`         // 订单ID
+	OrderItemID   *uuid.UUID    `gorm:"type:uuid;index" json:"order_item_id,omitempty"`   // 订单项ID（运费为空）
+	MerchantID    uuid.UUID     `gorm:"type:uuid;not null;index" json:"merchant_id"`      // 商户ID（税务报表）
+	Category      string        `gorm:"type:varchar(20);not null" json:"category"`        // 税务类别
+	Country       string        `gorm:"type:varchar(2)" json:"country"`                   // 税务管辖国家
+	Region        string        `gorm:"type:varchar(10)" json:"region"`                   // 税务管辖州/省
+	TaxName       string        `gorm:"type:varchar(50)" json:"tax_name"`                 // 税种名称
+	Rate          money.Decimal `gorm:"type:decimal(8,4);default:0" json:"rate"`          // 税率（百分比）
+	TaxableAmount int64         `gorm:"type:bigint;not null" json:"taxable_amount"`       // 计税金额（不含税，分）
+	TaxAmount     int64         `gorm:"type:bigint;not null;default:0" json:"tax_amount"` // 税额（分）
+	ReverseCharge bool          `gorm:"default:false" json:"reverse_charge"`              // 是否反向征收
+	Currency      string        `gorm:"type:varchar(10);not null" json:"currency"`        // 货币类型
+	CreatedAt     time.Time     `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (OrderTaxLine) TableName() string {
+	return "order_tax_lines"
+}
+
+// OrderTaxRefund 退款退回税额明细（每笔退款按税费明细一行，税务报表按退款时间冲减销项税额）
+type OrderTaxRefund struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID    uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`         // 订单ID
+	TaxLineID  uuid.UUID `gorm:"type:uuid;not null;index" json:"tax_line_id"`      // 税费明细ID
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`      // 商户ID（税务报表）
+	RefundNo   string    `gorm:"type:varchar(64);not null;index" json:"refund_no"` // 退款单号（退货退款单号或退款请求ID）
+	TaxAmount  int64     `gorm:"type:bigint;not null" json:"tax_amount"`           // 退回税额（分）
+	Currency   string    `gorm:"type:varchar(10);not null" json:"currency"`        // 货币类型
+	CreatedAt  time.Time `gorm:"type:timestamptz;default:now();index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (OrderTaxRefund) TableName() string {
+	return "order_tax_refunds"
+}
+
+// ReturnTaxRefunds 计算退货退回的税额（订单项已退数量更新后调用），返回 税费明细ID -> 本次退回税额
+//
+// 退回订单项的税费明细累计应退税额按已退数量折算（全部退回时为全部税额），扣除已退税额后即为本次退回税额；
+// 运费税额不随退货退回。refunded 为各税费明细已退税额。
+func ReturnTaxRefunds(order *Order, itemIDs []uuid.UUID, refunded map[uuid.UUID]int64) map[uuid.UUID]int64 {
+	items := make(map[uuid.UUID]*OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+	returned := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		returned[id] = true
+	}
+
+	result := make(map[uuid.UUID]int64)
+	for _, line := range order.TaxLines {
+		if line.OrderItemID == nil || !returned[*line.OrderItemID] {
+			continue
+		}
+		item := items[*line.OrderItemID]
+		if item == nil || item.Quantity <= 0 {
+			continue
+		}
+		target := line.TaxAmount
+		if item.RefundedQuantity < item.Quantity {
+			target = line.TaxAmount * int64(item.RefundedQuantity) / int64(item.Quantity)
+		}
+		if amount := target - refunded[line.ID]; amount > 0 {
+			result[line.ID] = amount
+		}
+	}
+	return result
+}
+
+// AmountTaxRefunds 计算按金额退款退回的税额，返回 税费明细ID -> 本次退回税额
+//
+// 按本次退款金额占剩余可退金额（refundable，本次退款计入前）的比例分摊各税费明细的剩余税额，向下取整；
+// 退完剩余可退金额时退回全部剩余税额，保证全额退款后退回税额合计等于原税额。
+func AmountTaxRefunds(lines []*OrderTaxLine, amount, refundable int64, refunded map[uuid.UUID]int64) map[uuid.UUID]int64 {
+	result := make(map[uuid.UUID]int64)
+	if amount <= 0 || refundable <= 0 {
+		return result
+	}
+
+	for _, line := range lines {
+		remaining := line.TaxAmount - refunded[line.ID]
+		if remaining <= 0 {
+			continue
+		}
+		share := remaining
+		if amount < refundable {
+			share = remaining * amount / refundable
+		}
+		if share > 0 {
+			result[line.ID] = share
+		}
+	}
+	return result
+}
+
+// TaxRule 税率规则（覆盖内置税率表，按国家整体生效：某国家配置了规则后不再使用该国家的内置税率）
+type TaxRule struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Country       string        `gorm:"type:varchar(2);not null;uniqueIndex:idx_tax_rule" json:"country"`            // ISO 3166-1 二位国家代码
+	Region        string        `gorm:"type:varchar(10);not null;default:'';uniqueIndex:idx_tax_rule" json:"region"` // 州/省代码，为空表示全国
+	Category      string        `gorm:"type:varchar(20);not null;uniqueIndex:idx_tax_rule" json:"category"`          // 税务类别
+	TaxName       string        `gorm:"type:varchar(50);not null" json:"tax_name"`                                   // 税种名称
+	Rate          money.Decimal `gorm:"type:decimal(8,4);not null" json:"rate"`                                      // 税率（百分比）
+	ReverseCharge bool          `gorm:"default:false" json:"reverse_charge"`                                         // 跨境 B2B 反向征收
+	Enabled       bool          `gorm:"default:true" json:"enabled"`                                                 // 是否启用
+	CreatedAt     time.Time     `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt     time.Time     `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (TaxRule) TableName() string {
+	return "tax_rules"
+}
+
+// ToRule 转换为计税引擎规则
+func (r *TaxRule) ToRule() tax.Rule {
+	return tax.Rule{
+		Country:       r.Country,
+		Region:        r.Region,
+		Category:      r.Category,
+		TaxName:       r.TaxName,
+		Rate:          r.Rate,
+		ReverseCharge: r.ReverseCharge,
+	}
+}
+
+// TaxReportRow 税务报表行（按管辖地、税种、税率汇总）
+type TaxReportRow struct {
+	Country           string        `json:"country"`
+	Region            string        `json:"region"`
+	TaxName           string        `json:"tax_name"`
+	Rate              money.Decimal `json:"rate"`
+	ReverseCharge     bool          `json:"reverse_charge"`
+	Currency          string        `json:"currency"`
+	OrderCount        int64         `json:"order_count"`
+	TaxableAmount     int64         `json:"taxable_amount"`      // 计税金额（分）
+	TaxAmount         int64         `json:"tax_amount"`          // 销项税额（分）
+	RefundedTaxAmount int64         `json:"refunded_tax_amount"` // 退款退回税额（分，按退款时间统计）
+	NetTaxAmount      int64         `json:"net_tax_amount"`      // 应申报税额（分）
+}
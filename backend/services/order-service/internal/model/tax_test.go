@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReturnTaxRefundsSumsToLineTax(t *testing.T) {
+	shirt := &OrderItem{ID: uuid.New(), Quantity: 3}
+	shoes := &OrderItem{ID: uuid.New(), Quantity: 1}
+	shirtLine := &OrderTaxLine{ID: uuid.New(), OrderItemID: &shirt.ID, TaxAmount: 100}
+	shoesLine := &OrderTaxLine{ID: uuid.New(), OrderItemID: &shoes.ID, TaxAmount: 700}
+	shippingLine := &OrderTaxLine{ID: uuid.New(), TaxAmount: 50}
+	order := &Order{
+		Items:    []*OrderItem{shirt, shoes},
+		TaxLines: []*OrderTaxLine{shirtLine, shoesLine, shippingLine},
+	}
+
+	// 逐件退回衬衫，最后一件补齐取整差额；鞋子和运费税额不受影响
+	refunded := map[uuid.UUID]int64{}
+	for _, want := range []int64{33, 33, 34} {
+		shirt.RefundedQuantity++
+		got := ReturnTaxRefunds(order, []uuid.UUID{shirt.ID}, refunded)
+		if len(got) != 1 || got[shirtLine.ID] != want {
+			t.Fatalf("ReturnTaxRefunds(shirt) = %v, want %d", got, want)
+		}
+		refunded[shirtLine.ID] += got[shirtLine.ID]
+	}
+	if refunded[shirtLine.ID] != shirtLine.TaxAmount {
+		t.Fatalf("refunded shirt tax = %d, want %d", refunded[shirtLine.ID], shirtLine.TaxAmount)
+	}
+
+	// 已按金额退款退回部分税额时只补足差额
+	refunded[shoesLine.ID] = 300
+	shoes.RefundedQuantity = 1
+	if got := ReturnTaxRefunds(order, []uuid.UUID{shoes.ID}, refunded); got[shoesLine.ID] != 400 || len(got) != 1 {
+		t.Fatalf("ReturnTaxRefunds(shoes) = %v, want 400", got)
+	}
+}
+
+func TestAmountTaxRefunds(t *testing.T) {
+	itemLine := &OrderTaxLine{ID: uuid.New(), TaxAmount: 1000}
+	shippingLine := &OrderTaxLine{ID: uuid.New(), TaxAmount: 99}
+	lines := []*OrderTaxLine{itemLine, shippingLine}
+
+	// 退回一半可退金额时按比例向下取整
+	refunded := map[uuid.UUID]int64{}
+	got := AmountTaxRefunds(lines, 5000, 10000, refunded)
+	if got[itemLine.ID] != 500 || got[shippingLine.ID] != 49 {
+		t.Fatalf("first refund = %v, want 500 + 49", got)
+	}
+	for id, amount := range got {
+		refunded[id] += amount
+	}
+
+	// 退完剩余金额时退回全部剩余税额
+	got = AmountTaxRefunds(lines, 5000, 5000, refunded)
+	if got[itemLine.ID] != 500 || got[shippingLine.ID] != 50 {
+		t.Fatalf("final refund = %v, want 500 + 50", got)
+	}
+
+	if got := AmountTaxRefunds(lines, 0, 5000, refunded); len(got) != 0 {
+		t.Fatalf("zero refund = %v, want empty", got)
+	}
+}
@@ -74,6 +74,8 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Ord
 	var order model.Order
 	err := r.db.WithContext(ctx).
 		Preload("Items").
+		Preload("TaxLines").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("sequence ASC") }).
 		First(&order, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -89,6 +91,8 @@ func (r *orderRepository) GetByOrderNo(ctx context.Context, orderNo string) (*mo
 	var order model.Order
 	err := r.db.WithContext(ctx).
 		Preload("Items").
+		Preload("TaxLines").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("sequence ASC") }).
 		First(&order, "order_no = ?", orderNo).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -99,12 +103,14 @@ func (r *orderRepository) GetByOrderNo(ctx context.Context, orderNo string) (*mo
 	return &order, nil
 }
 
-// GetByPaymentNo 根据支付流水号获取订单
+// GetByPaymentNo 根据支付流水号获取订单（包括组合支付中的任一笔支付）
 func (r *orderRepository) GetByPaymentNo(ctx context.Context, paymentNo string) (*model.Order, error) {
 	var order model.Order
 	err := r.db.WithContext(ctx).
 		Preload("Items").
-		First(&order, "payment_no = ?", paymentNo).Error
+		Preload("TaxLines").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("sequence ASC") }).
+		First(&order, "payment_no = ? OR id IN (SELECT order_id FROM order_payments WHERE payment_no = ?)", paymentNo, paymentNo).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-platform/order-service/internal/model"
+)
+
+// ReturnRepository 退货申请仓储接口
+type ReturnRepository interface {
+	// GetByReturnNo 根据退货单号获取退货申请（含明细），不存在时返回 nil
+	GetByReturnNo(ctx context.Context, returnNo string) (*model.ReturnRequest, error)
+	ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error)
+	List(ctx context.Context, query *ReturnQuery) ([]*model.ReturnRequest, int64, error)
+	// ListStaleRefunding 获取在 before 之前进入退款中且未再更新的退货申请
+	ListStaleRefunding(ctx context.Context, before time.Time, limit int) ([]*model.ReturnRequest, error)
+}
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+// NewReturnRepository 创建退货申请仓储实例
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+// ReturnQuery 退货申请查询参数
+type ReturnQuery struct {
+	MerchantID *uuid.UUID
+	Status     string
+	Page       int
+	PageSize   int
+}
+
+// GetByReturnNo 根据退货单号获取退货申请
+func (r *returnRepository) GetByReturnNo(ctx context.Context, returnNo string) (*model.ReturnRequest, error) {
+	var ret model.ReturnRequest
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("return_no = ?", returnNo).
+		First(&ret).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ListByOrderID 获取订单的全部退货申请
+func (r *returnRepository) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error) {
+	var returns []*model.ReturnRequest
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&returns).Error
+	return returns, err
+}
+
+// ListStaleRefunding 获取停留在退款中的退货申请
+func (r *returnRepository) ListStaleRefunding(ctx context.Context, before time.Time, limit int) ([]*model.ReturnRequest, error) {
+	var returns []*model.ReturnRequest
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", model.ReturnStatusRefunding, before).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&returns).Error
+	return returns, err
+}
+
+// List 分页查询退货申请
+func (r *returnRepository) List(ctx context.Context, query *ReturnQuery) ([]*model.ReturnRequest, int64, error) {
+	var returns []*model.ReturnRequest
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.ReturnRequest{})
+	if query.MerchantID != nil {
+		db = db.Where("merchant_id = ?", *query.MerchantID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = 20
+	}
+
+	err := db.Preload("Items").
+		Order("created_at DESC").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&returns).Error
+	return returns, total, err
+}
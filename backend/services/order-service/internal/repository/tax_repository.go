@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/order-service/internal/model"
+)
+
+// TaxRepository 税率规则与税务报表仓储接口
+type TaxRepository interface {
+	// ListRules 查询税率规则，country 为空时返回全部
+	ListRules(ctx context.Context, country string) ([]*model.TaxRule, error)
+	// SaveRule 按 国家+地区+类别 新增或覆盖税率规则
+	SaveRule(ctx context.Context, rule *model.TaxRule) error
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	// GetTaxReport 按支付时间统计商户已支付订单的税额
+	GetTaxReport(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) ([]*model.TaxReportRow, error)
+}
+
+type taxRepository struct {
+	db *gorm.DB
+}
+
+// NewTaxRepository 创建税务仓储实例
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+// ListRules 查询税率规则
+func (r *taxRepository) ListRules(ctx context.Context, country string) ([]*model.TaxRule, error) {
+	var rules []*model.TaxRule
+	db := r.db.WithContext(ctx)
+	if country != "" {
+		db = db.Where("country = ?", strings.ToUpper(country))
+	}
+	err := db.Order("country ASC, region ASC, category ASC").Find(&rules).Error
+	return rules, err
+}
+
+// SaveRule 新增或覆盖税率规则
+func (r *taxRepository) SaveRule(ctx context.Context, rule *model.TaxRule) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "country"}, {Name: "region"}, {Name: "category"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tax_name", "rate", "reverse_charge", "enabled", "updated_at"}),
+		}).
+		Create(rule).Error
+}
+
+// DeleteRule 删除税率规则
+func (r *taxRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&model.TaxRule{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTaxReport 按管辖地、税种、税率汇总税额
+//
+// 销项税额按订单支付时间统计，退回税额按退款时间统计（每笔退款的退回税额见 order_tax_refunds）。
+func (r *taxRepository) GetTaxReport(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) ([]*model.TaxReportRow, error) {
+	var rows []*model.TaxReportRow
+	err := r.db.WithContext(ctx).
+		Table("order_tax_lines AS l").
+		Select(`l.country, l.region, l.tax_name, l.rate, l.reverse_charge, l.currency,
+			COUNT(DISTINCT l.order_id) AS order_count,
+			COALESCE(SUM(l.taxable_amount), 0) AS taxable_amount,
+			COALESCE(SUM(l.tax_amount), 0) AS tax_amount`).
+		Joins("JOIN orders o ON o.id = l.order_id AND o.deleted_at IS NULL").
+		Where("o.merchant_id = ? AND o.paid_at >= ? AND o.paid_at < ?", merchantID, startTime, endTime).
+		Where("o.pay_status IN ?", []string{model.PayStatusPaid, model.PayStatusPartialRefunded, model.PayStatusRefunded}).
+		Group("l.country, l.region, l.tax_name, l.rate, l.reverse_charge, l.currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []*model.TaxReportRow
+	err = r.db.WithContext(ctx).
+		Table("order_tax_refunds AS t").
+		Select(`l.country, l.region, l.tax_name, l.rate, l.reverse_charge, l.currency,
+			COALESCE(SUM(t.tax_amount), 0) AS refunded_tax_amount`).
+		Joins("JOIN order_tax_lines l ON l.id = t.tax_line_id").
+		Where("t.merchant_id = ? AND t.created_at >= ? AND t.created_at < ?", merchantID, startTime, endTime).
+		Group("l.country, l.region, l.tax_name, l.rate, l.reverse_charge, l.currency").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并退回税额（退款对应的订单可能在统计区间之前支付）
+	byKey := make(map[string]*model.TaxReportRow, len(rows))
+	for _, row := range rows {
+		byKey[taxReportKey(row)] = row
+	}
+	for _, refund := range refunds {
+		if row, ok := byKey[taxReportKey(refund)]; ok {
+			row.RefundedTaxAmount = refund.RefundedTaxAmount
+			continue
+		}
+		rows = append(rows, refund)
+	}
+
+	for _, row := range rows {
+		row.NetTaxAmount = row.TaxAmount - row.RefundedTaxAmount
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Rate.Cmp(b.Rate) > 0
+	})
+	return rows, nil
+}
+
+// taxReportKey 税务报表行的汇总维度
+func taxReportKey(row *model.TaxReportRow) string {
+	return strings.Join([]string{row.Currency, row.Country, row.Region, row.TaxName, row.Rate.String(), strconv.FormatBool(row.ReverseCharge)}, "|")
+}
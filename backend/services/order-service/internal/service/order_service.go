@@ -13,6 +13,7 @@ import (
 	"github.com/payment-platform/pkg/idempotent"
 	"github.com/payment-platform/pkg/kafka"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/tax"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -35,7 +36,8 @@ type OrderService interface {
 
 	// 支付相关
 	PayOrder(ctx context.Context, orderNo string, paymentNo string) error
-	RefundOrder(ctx context.Context, orderNo string, amount int64, reason string) error
+	// RefundOrder 退款订单，refundID 为调用方生成的退款请求ID，重试时沿用以命中组合支付的首次分摊
+	RefundOrder(ctx context.Context, orderNo string, refundID uuid.UUID, amount int64, reason string) error
 
 	// 配送相关
 	ShipOrder(ctx context.Context, orderNo string, shippingInfo map[string]interface{}) error
@@ -54,6 +56,8 @@ type orderService struct {
 	idempotentService  idempotent.Service
 	notificationClient *client.NotificationClient // 保留作为降级方案
 	eventPublisher     *kafka.EventPublisher      // 事件发布器
+	taxEngine          tax.Engine                 // 计税引擎（可选）
+	tenderService      TenderService              // 组合支付服务（可选）
 }
 
 // NewOrderService 创建订单服务实例
@@ -89,6 +93,14 @@ type CreateOrderInput struct {
 	Remark          string                `json:"remark"`
 	Extra           map[string]interface{} `json:"extra"`
 	ExpireMinutes   int                   `json:"expire_minutes"` // 订单过期时间（分钟）
+	Tax             *OrderTaxInput        `json:"tax"`            // 计税参数（可选，为空时不计税）
+}
+
+// OrderTaxInput 订单计税参数
+type OrderTaxInput struct {
+	PricesIncludeTax bool   `json:"prices_include_tax"` // 商品价格是否含税
+	SellerCountry    string `json:"seller_country"`     // 卖方所在国家（判断跨境反向征收）
+	CustomerTaxID    string `json:"customer_tax_id"`    // 买方税号（B2B）
 }
 
 // OrderItemInput 订单项输入
@@ -101,6 +113,17 @@ type OrderItemInput struct {
 	Quantity     int                    `json:"quantity" binding:"required,gt=0"`
 	Attributes   map[string]interface{} `json:"attributes"`
 	Extra        map[string]interface{} `json:"extra"`
+	TaxCategory  string                 `json:"tax_category"` // 税务类别（默认 standard）
+}
+
+// SetTaxEngine 设置计税引擎
+func (s *orderService) SetTaxEngine(engine tax.Engine) {
+	s.taxEngine = engine
+}
+
+// SetTenderService 设置组合支付服务
+func (s *orderService) SetTenderService(tenderService TenderService) {
+	s.tenderService = tenderService
 }
 
 // CreateOrder 创建订单（使用事务保护订单和订单项的完整性 + 幂等性保护）
@@ -292,10 +315,24 @@ func (s *orderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 			TotalPrice:   totalPrice,
 			Attributes:   attributesJSON,
 			Extra:        itemExtraJSON,
+			TaxCategory:  itemInput.TaxCategory,
 		}
 		items = append(items, item)
 	}
 
+	// 计算税费（价外税计入实付金额）：仅在提供计税参数时计税，未接入计税的商户金额不变
+	var taxResult *tax.Result
+	if input.Tax != nil && s.taxEngine != nil {
+		result, taxErr := s.calculateOrderTax(ctx, order, items, input)
+		if taxErr != nil {
+			logger.Error("failed to calculate order tax",
+				zap.Error(taxErr),
+				zap.String("order_no", orderNo))
+			return nil, taxErr
+		}
+		taxResult = result
+	}
+
 	// 在事务中创建订单、订单项和日志，确保原子性
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. 创建订单
@@ -318,7 +355,20 @@ func (s *orderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 			}
 		}
 
-		// 3. 创建订单日志
+		// 3. 创建税费明细
+		if taxResult != nil {
+			order.TaxLines = buildOrderTaxLines(order, items, taxResult)
+			if len(order.TaxLines) > 0 {
+				if err := tx.Create(order.TaxLines).Error; err != nil {
+					logger.Error("failed to create order tax lines",
+						zap.Error(err),
+						zap.String("order_no", orderNo))
+					return fmt.Errorf("创建税费明细失败: %w", err)
+				}
+			}
+		}
+
+		// 4. 创建订单日志
 		log := &model.OrderLog{
 			OrderID:      order.ID,
 			Action:       model.OrderActionCreate,
@@ -352,7 +402,7 @@ func (s *orderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 	logger.Info("order created successfully",
 		zap.String("order_no", orderNo),
 		zap.Int64("total_amount", totalAmount),
-		zap.Int64("pay_amount", payAmount),
+		zap.Int64("pay_amount", order.PayAmount),
 		zap.String("currency", input.Currency))
 
 	// 发布订单创建事件 (异步,不阻塞主流程)
@@ -551,6 +601,15 @@ func (s *orderService) CancelOrder(ctx context.Context, orderNo string, reason s
 		zap.String("order_no", orderNo),
 		zap.String("old_status", oldStatus))
 
+	// 撤销/退回组合支付（失败时由定时任务重试，不影响取消结果）
+	if s.tenderService != nil && len(order.Payments) > 0 {
+		if err := s.tenderService.ReleaseTenders(ctx, orderNo, reason); err != nil {
+			logger.Error("failed to release order payments after cancellation",
+				zap.Error(err),
+				zap.String("order_no", orderNo))
+		}
+	}
+
 	return nil
 }
 
@@ -675,12 +734,19 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, orderNo string, st
 }
 
 // PayOrder 支付订单（使用事务保护状态更新的原子性）
+//
+// 已登记组合支付的订单按单笔支付确认，付清后才变为已支付。
 func (s *orderService) PayOrder(ctx context.Context, orderNo string, paymentNo string) error {
 	order, err := s.GetOrder(ctx, orderNo)
 	if err != nil {
 		return err
 	}
 
+	if s.tenderService != nil && len(order.Payments) > 0 {
+		_, err := s.tenderService.ConfirmPayment(ctx, orderNo, paymentNo)
+		return err
+	}
+
 	if order.Status != model.OrderStatusPending {
 		return fmt.Errorf("订单状态不正确，无法支付")
 	}
@@ -694,6 +760,7 @@ func (s *orderService) PayOrder(ctx context.Context, orderNo string, paymentNo s
 			Where("id = ?", order.ID).
 			Updates(map[string]interface{}{
 				"pay_status":  model.PayStatusPaid,
+				"paid_amount": order.PayAmount,
 				"paid_at":     &paidAt,
 				"payment_no":  paymentNo,
 				"status":      model.OrderStatusPaid,
@@ -729,15 +796,17 @@ func (s *orderService) PayOrder(ctx context.Context, orderNo string, paymentNo s
 	order.PayStatus = model.PayStatusPaid
 	order.PaidAt = &paidAt
 	order.PaymentNo = paymentNo
+	order.PaidAmount = order.PayAmount
 	s.publishOrderEvent(ctx, events.OrderPaid, order)
 
 	return nil
 }
 
 // RefundOrder 退款订单（使用事务保证退款状态更新的原子性）
-func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount int64, reason string) error {
+func (s *orderService) RefundOrder(ctx context.Context, orderNo string, refundID uuid.UUID, amount int64, reason string) error {
 	logger.Info("refunding order",
 		zap.String("order_no", orderNo),
+		zap.String("refund_id", refundID.String()),
 		zap.Int64("amount", amount),
 		zap.String("reason", reason))
 
@@ -749,22 +818,59 @@ func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount i
 		return err
 	}
 
-	if order.PayStatus != model.PayStatusPaid {
+	if order.PayStatus != model.PayStatusPaid && order.PayStatus != model.PayStatusPartialRefunded {
 		logger.Warn("order not paid, cannot refund",
 			zap.String("order_no", orderNo),
 			zap.String("pay_status", order.PayStatus))
 		return fmt.Errorf("订单未支付，无法退款")
 	}
 
-	// 部分退款还是全额退款
-	var newPayStatus string
-	var newOrderStatus string
-	if amount >= order.PayAmount {
-		newPayStatus = model.PayStatusRefunded
+	// 组合支付订单按配置的策略分摊到各笔支付，通过 payment-gateway 逐笔退款；
+	// 以退款请求ID作为分摊来源，重试时沿用首次分摊并只重新退款未成功的部分。
+	// 部分支付退款失败时只记录已退成功的金额，剩余部分需使用同一退款请求ID重新发起退款
+	var tenderErr error
+	if s.tenderService != nil && len(order.Payments) > 0 {
+		// 同一退款请求之前已退成功并计入订单的金额
+		applied, err := s.tenderRefundedAmount(ctx, refundID)
+		if err != nil {
+			return err
+		}
+		if remaining := order.PayAmount - order.RefundedAmount + applied; amount > remaining {
+			return fmt.Errorf("退款金额 %d 超过可退金额 %d", amount, remaining)
+		}
+
+		refunds, err := s.tenderService.RefundTenders(ctx, order.ID, refundID, amount, reason)
+		succeeded := int64(0)
+		for _, r := range refunds {
+			if r.Status == model.TenderRefundStatusSuccess {
+				succeeded += r.Amount
+			}
+		}
+		if err != nil {
+			logger.Error("failed to refund order tenders",
+				zap.Error(err),
+				zap.String("order_no", orderNo),
+				zap.String("refund_id", refundID.String()))
+			tenderErr = err
+		}
+		amount = succeeded - applied
+		if amount <= 0 {
+			if tenderErr != nil {
+				return tenderErr
+			}
+			return nil // 重试时全部分摊已在之前退款成功并计入订单
+		}
+	} else if remaining := order.PayAmount - order.RefundedAmount; amount > remaining {
+		return fmt.Errorf("退款金额 %d 超过可退金额 %d", amount, remaining)
+	}
+
+	// 按累计退款金额判断部分退款还是全额退款（与退货退款共用同一累计金额）
+	refundable := order.PayAmount - order.RefundedAmount
+	order.RefundedAmount += amount
+	newPayStatus := model.RefundPayStatus(order)
+	newOrderStatus := order.Status // 部分退款不改变订单状态
+	if newPayStatus == model.PayStatusRefunded {
 		newOrderStatus = model.OrderStatusRefunded
-	} else {
-		newPayStatus = model.PayStatusPartialRefunded
-		newOrderStatus = order.Status // 部分退款不改变订单状态
 	}
 
 	oldStatus := order.Status
@@ -773,8 +879,9 @@ func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount i
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. 更新支付状态
 		updates := map[string]interface{}{
-			"pay_status": newPayStatus,
-			"updated_at": time.Now(),
+			"pay_status":      newPayStatus,
+			"refunded_amount": order.RefundedAmount,
+			"updated_at":      time.Now(),
 		}
 		if newPayStatus == model.PayStatusRefunded {
 			updates["status"] = newOrderStatus
@@ -796,7 +903,7 @@ func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount i
 			NewStatus:    newOrderStatus,
 			OperatorID:   uuid.Nil,
 			OperatorType: model.OperatorTypeSystem,
-			Remark:       fmt.Sprintf("退款金额: %d, 原因: %s", amount, reason),
+			Remark:       fmt.Sprintf("退款请求: %s, 退款金额: %d, 原因: %s", refundID, amount, reason),
 		}
 		if err := tx.Create(log).Error; err != nil {
 			logger.Error("failed to create refund log",
@@ -805,6 +912,16 @@ func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount i
 			return fmt.Errorf("创建退款日志失败: %w", err)
 		}
 
+		// 3. 记录退回税额（按本次退款占剩余可退金额的比例）
+		if err := recordTaxRefunds(tx, order, refundID.String(), func(refunded map[uuid.UUID]int64) map[uuid.UUID]int64 {
+			return model.AmountTaxRefunds(order.TaxLines, amount, refundable, refunded)
+		}); err != nil {
+			logger.Error("failed to record refunded tax",
+				zap.Error(err),
+				zap.String("order_no", orderNo))
+			return err
+		}
+
 		return nil
 	})
 
@@ -820,7 +937,19 @@ func (s *orderService) RefundOrder(ctx context.Context, orderNo string, amount i
 		zap.Int64("refund_amount", amount),
 		zap.String("pay_status", newPayStatus))
 
-	return nil
+	return tenderErr
+}
+
+// tenderRefundedAmount 统计退款请求已退成功的组合支付分摊金额
+func (s *orderService) tenderRefundedAmount(ctx context.Context, refundID uuid.UUID) (int64, error) {
+	var amount int64
+	if err := s.db.WithContext(ctx).Model(&model.OrderPaymentRefund{}).
+		Where("source_id = ? AND status = ?", refundID, model.TenderRefundStatusSuccess).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&amount).Error; err != nil {
+		return 0, fmt.Errorf("查询退款分摊失败: %w", err)
+	}
+	return amount, nil
 }
 
 // ShipOrder 发货（使用事务保证发货状态更新的原子性）
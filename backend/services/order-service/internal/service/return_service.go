@@ -0,0 +1,782 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/events"
+	"github.com/payment-platform/pkg/kafka"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/order-service/internal/client"
+	"payment-platform/order-service/internal/model"
+	"payment-platform/order-service/internal/repository"
+)
+
+// ReturnService 退货（RMA）服务接口
+//
+// 流程：客户按商品和数量申请退货 -> 商户审核（可设置重新上架费）-> 客户寄回并填写物流单号 ->
+// 商户签收后自动通过 payment-gateway 退款；仅退款（无需寄回）的申请在审核通过后立即退款。
+type ReturnService interface {
+	CreateReturn(ctx context.Context, orderNo string, input *CreateReturnInput) (*model.ReturnRequest, error)
+	GetReturn(ctx context.Context, returnNo string) (*model.ReturnRequest, error)
+	ListOrderReturns(ctx context.Context, orderNo string) ([]*model.ReturnRequest, error)
+	ListReturns(ctx context.Context, query *repository.ReturnQuery) ([]*model.ReturnRequest, int64, error)
+
+	// 商户审核
+	ApproveReturn(ctx context.Context, returnNo string, input *ApproveReturnInput) (*model.ReturnRequest, error)
+	RejectReturn(ctx context.Context, returnNo string, merchantID, reviewerID uuid.UUID, reason string) error
+
+	// 退货物流
+	ShipReturn(ctx context.Context, returnNo string, carrier, trackingNo string) (*model.ReturnRequest, error)
+	ReceiveReturn(ctx context.Context, returnNo string, merchantID, reviewerID uuid.UUID) (*model.ReturnRequest, error)
+	CancelReturn(ctx context.Context, returnNo string, requesterID uuid.UUID) error
+
+	// RetryRefund 退款失败或长时间停留在退款中时重新发起退款
+	RetryRefund(ctx context.Context, returnNo string, merchantID uuid.UUID) (*model.ReturnRequest, error)
+	// ReconcileRefunding 重新处理停留在退款中的退货（网关已退款但本地更新失败、或进程在退款中途退出），返回处理成功的数量
+	ReconcileRefunding(ctx context.Context, limit int) (int, error)
+}
+
+// refundingStaleAfter 退货停留在退款中超过该时长视为中断，允许重新发起退款
+//
+// 网关退款以退货单ID作为操作人ID（组合支付以退货单ID作为分摊来源），重新发起时命中网关幂等，不会重复退款。
+const refundingStaleAfter = 5 * time.Minute
+
+type returnService struct {
+	db             *gorm.DB
+	orderRepo      repository.OrderRepository
+	returnRepo     repository.ReturnRepository
+	paymentClient  *client.PaymentClient
+	eventPublisher *kafka.EventPublisher
+	tenderService  TenderService // 组合支付服务（可选）
+}
+
+// NewReturnService 创建退货服务实例
+func NewReturnService(db *gorm.DB, orderRepo repository.OrderRepository, returnRepo repository.ReturnRepository, paymentClient *client.PaymentClient, eventPublisher *kafka.EventPublisher) ReturnService {
+	return &returnService{
+		db:             db,
+		orderRepo:      orderRepo,
+		returnRepo:     returnRepo,
+		paymentClient:  paymentClient,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// SetTenderService 设置组合支付服务（组合支付订单的退货退款分摊到各笔支付）
+func (s *returnService) SetTenderService(tenderService TenderService) {
+	s.tenderService = tenderService
+}
+
+// CreateReturnInput 申请退货输入
+type CreateReturnInput struct {
+	Items         []ReturnItemInput `json:"items" binding:"required,min=1,dive"`
+	Reason        string            `json:"reason" binding:"required"`
+	Description   string            `json:"description"`
+	RequesterID   uuid.UUID         `json:"requester_id"`
+	RequesterType string            `json:"requester_type"` // customer, merchant, admin
+}
+
+// ReturnItemInput 退货商品输入
+type ReturnItemInput struct {
+	OrderItemID uuid.UUID `json:"order_item_id" binding:"required"`
+	Quantity    int       `json:"quantity" binding:"required,gt=0"`
+}
+
+// ApproveReturnInput 审核通过输入
+type ApproveReturnInput struct {
+	MerchantID      uuid.UUID `json:"merchant_id" binding:"required"`
+	ReviewerID      uuid.UUID `json:"reviewer_id"`
+	RestockingFee   int64     `json:"restocking_fee" binding:"gte=0"` // 重新上架费（分）
+	RequireShipping *bool     `json:"require_shipping"`               // 是否需要寄回商品，默认需要
+}
+
+// CreateReturn 申请退货（锁定订单行，校验可退数量后创建退货单）
+func (s *returnService) CreateReturn(ctx context.Context, orderNo string, input *CreateReturnInput) (*model.ReturnRequest, error) {
+	logger.Info("creating return request",
+		zap.String("order_no", orderNo),
+		zap.Int("item_count", len(input.Items)))
+
+	var ret *model.ReturnRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := s.lockOrder(tx, orderNo)
+		if err != nil {
+			return err
+		}
+
+		if order.PayStatus != model.PayStatusPaid && order.PayStatus != model.PayStatusPartialRefunded {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("订单支付状态为 %s，无法申请退货", order.PayStatus))
+		}
+
+		pending, err := s.pendingReturnQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		itemsByID := make(map[uuid.UUID]*model.OrderItem, len(order.Items))
+		for _, item := range order.Items {
+			itemsByID[item.ID] = item
+		}
+
+		returnItems := make([]*model.ReturnItem, 0, len(input.Items))
+		seen := make(map[uuid.UUID]bool, len(input.Items))
+		itemAmount := int64(0)
+		for _, in := range input.Items {
+			item, ok := itemsByID[in.OrderItemID]
+			if !ok {
+				return pkgerrors.NewInvalidRequestError(fmt.Sprintf("订单项不存在: %s", in.OrderItemID))
+			}
+			if seen[in.OrderItemID] {
+				return pkgerrors.NewInvalidRequestError(fmt.Sprintf("订单项重复: %s", in.OrderItemID))
+			}
+			seen[in.OrderItemID] = true
+
+			available := item.Quantity - item.RefundedQuantity - pending[item.ID]
+			if in.Quantity > available {
+				return pkgerrors.NewInvalidRequestError(fmt.Sprintf("商品 %s 可退数量为 %d，申请数量 %d", item.ProductName, available, in.Quantity))
+			}
+
+			amount := model.ItemReturnAmount(order, item, in.Quantity)
+			itemAmount += amount
+			returnItems = append(returnItems, &model.ReturnItem{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				ProductSKU:  item.ProductSKU,
+				Quantity:    in.Quantity,
+				Amount:      amount,
+			})
+		}
+
+		ret = &model.ReturnRequest{
+			ReturnNo:        s.generateReturnNo(),
+			OrderID:         order.ID,
+			OrderNo:         order.OrderNo,
+			MerchantID:      order.MerchantID,
+			PaymentNo:       order.PaymentNo,
+			Status:          model.ReturnStatusRequested,
+			Reason:          input.Reason,
+			Description:     input.Description,
+			RequireShipping: true,
+			ItemAmount:      itemAmount,
+			Currency:        order.Currency,
+			RequesterID:     input.RequesterID,
+			RequesterType:   input.RequesterType,
+			Items:           returnItems,
+		}
+		if err := tx.Create(ret).Error; err != nil {
+			return fmt.Errorf("创建退货申请失败: %w", err)
+		}
+
+		return tx.Create(&model.OrderLog{
+			OrderID:      order.ID,
+			Action:       model.OrderActionReturnRequest,
+			OldStatus:    order.Status,
+			NewStatus:    order.Status,
+			OperatorID:   input.RequesterID,
+			OperatorType: input.RequesterType,
+			Remark:       fmt.Sprintf("退货单号: %s, 退货金额: %d, 原因: %s", ret.ReturnNo, itemAmount, input.Reason),
+		}).Error
+	})
+	if err != nil {
+		logger.Error("failed to create return request",
+			zap.Error(err),
+			zap.String("order_no", orderNo))
+		return nil, err
+	}
+
+	logger.Info("return request created",
+		zap.String("order_no", orderNo),
+		zap.String("return_no", ret.ReturnNo),
+		zap.Int64("item_amount", ret.ItemAmount))
+
+	return ret, nil
+}
+
+// GetReturn 获取退货申请
+func (s *returnService) GetReturn(ctx context.Context, returnNo string) (*model.ReturnRequest, error) {
+	ret, err := s.returnRepo.GetByReturnNo(ctx, returnNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询退货申请失败: %w", err)
+	}
+	if ret == nil {
+		return nil, pkgerrors.NewNotFoundError("退货申请不存在")
+	}
+	return ret, nil
+}
+
+// ListOrderReturns 获取订单的退货申请
+func (s *returnService) ListOrderReturns(ctx context.Context, orderNo string) ([]*model.ReturnRequest, error) {
+	order, err := s.orderRepo.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	if order == nil {
+		return nil, pkgerrors.NewNotFoundError("订单不存在")
+	}
+	return s.returnRepo.ListByOrderID(ctx, order.ID)
+}
+
+// ListReturns 分页查询退货申请
+func (s *returnService) ListReturns(ctx context.Context, query *repository.ReturnQuery) ([]*model.ReturnRequest, int64, error) {
+	return s.returnRepo.List(ctx, query)
+}
+
+// ApproveReturn 商户审核通过（仅退款的申请审核通过后立即退款）
+func (s *returnService) ApproveReturn(ctx context.Context, returnNo string, input *ApproveReturnInput) (*model.ReturnRequest, error) {
+	ret, err := s.getMerchantReturn(ctx, returnNo, input.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if input.RestockingFee > ret.ItemAmount {
+		return nil, pkgerrors.NewInvalidRequestError(fmt.Sprintf("重新上架费 %d 不能超过退货金额 %d", input.RestockingFee, ret.ItemAmount))
+	}
+
+	requireShipping := true
+	if input.RequireShipping != nil {
+		requireShipping = *input.RequireShipping
+	}
+
+	now := time.Now()
+	if err := s.transition(ctx, ret, []string{model.ReturnStatusRequested}, model.ReturnStatusApproved, map[string]interface{}{
+		"restocking_fee":   input.RestockingFee,
+		"require_shipping": requireShipping,
+		"reviewer_id":      input.ReviewerID,
+		"approved_at":      now,
+	}, model.OrderActionReturnApprove, fmt.Sprintf("退货单号: %s, 重新上架费: %d", returnNo, input.RestockingFee)); err != nil {
+		return nil, err
+	}
+
+	logger.Info("return request approved",
+		zap.String("return_no", returnNo),
+		zap.Int64("restocking_fee", input.RestockingFee),
+		zap.Bool("require_shipping", requireShipping))
+
+	if !requireShipping {
+		return s.refund(ctx, returnNo)
+	}
+	return s.GetReturn(ctx, returnNo)
+}
+
+// RejectReturn 商户拒绝退货
+func (s *returnService) RejectReturn(ctx context.Context, returnNo string, merchantID, reviewerID uuid.UUID, reason string) error {
+	ret, err := s.getMerchantReturn(ctx, returnNo, merchantID)
+	if err != nil {
+		return err
+	}
+
+	return s.transition(ctx, ret, []string{model.ReturnStatusRequested}, model.ReturnStatusRejected, map[string]interface{}{
+		"reject_reason": reason,
+		"reviewer_id":   reviewerID,
+		"closed_at":     time.Now(),
+	}, model.OrderActionReturnReject, fmt.Sprintf("退货单号: %s, 拒绝原因: %s", returnNo, reason))
+}
+
+// ShipReturn 客户寄回商品并填写退货物流（寄出后仍可更正物流单号）
+func (s *returnService) ShipReturn(ctx context.Context, returnNo string, carrier, trackingNo string) (*model.ReturnRequest, error) {
+	ret, err := s.GetReturn(ctx, returnNo)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.RequireShipping {
+		return nil, pkgerrors.NewInvalidRequestError("该退货申请为仅退款，无需寄回商品")
+	}
+
+	updates := map[string]interface{}{
+		"carrier":     carrier,
+		"tracking_no": trackingNo,
+	}
+	if ret.ShippedAt == nil {
+		updates["shipped_at"] = time.Now()
+	}
+	if err := s.transition(ctx, ret, []string{model.ReturnStatusApproved, model.ReturnStatusShipped}, model.ReturnStatusShipped, updates, "", ""); err != nil {
+		return nil, err
+	}
+
+	logger.Info("return shipment updated",
+		zap.String("return_no", returnNo),
+		zap.String("carrier", carrier),
+		zap.String("tracking_no", trackingNo))
+
+	return s.GetReturn(ctx, returnNo)
+}
+
+// ReceiveReturn 商户签收退货商品并自动退款
+func (s *returnService) ReceiveReturn(ctx context.Context, returnNo string, merchantID, reviewerID uuid.UUID) (*model.ReturnRequest, error) {
+	ret, err := s.getMerchantReturn(ctx, returnNo, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.RequireShipping {
+		return nil, pkgerrors.NewInvalidRequestError("该退货申请为仅退款，无需签收")
+	}
+
+	// 未填写物流单号（如线下退回）时允许商户直接签收
+	if err := s.transition(ctx, ret, []string{model.ReturnStatusApproved, model.ReturnStatusShipped}, model.ReturnStatusReceived, map[string]interface{}{
+		"reviewer_id": reviewerID,
+		"received_at": time.Now(),
+	}, model.OrderActionReturnReceive, fmt.Sprintf("退货单号: %s", returnNo)); err != nil {
+		return nil, err
+	}
+
+	return s.refund(ctx, returnNo)
+}
+
+// CancelReturn 取消退货申请（寄回商品前可取消）
+func (s *returnService) CancelReturn(ctx context.Context, returnNo string, requesterID uuid.UUID) error {
+	ret, err := s.GetReturn(ctx, returnNo)
+	if err != nil {
+		return err
+	}
+	if requesterID != uuid.Nil && ret.RequesterID != uuid.Nil && ret.RequesterID != requesterID {
+		return pkgerrors.NewForbiddenError("只能取消自己的退货申请")
+	}
+
+	return s.transition(ctx, ret, []string{model.ReturnStatusRequested, model.ReturnStatusApproved}, model.ReturnStatusCancelled, map[string]interface{}{
+		"closed_at": time.Now(),
+	}, "", "")
+}
+
+// RetryRefund 退款失败或长时间停留在退款中时重新发起退款
+func (s *returnService) RetryRefund(ctx context.Context, returnNo string, merchantID uuid.UUID) (*model.ReturnRequest, error) {
+	ret, err := s.getMerchantReturn(ctx, returnNo, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ret.Status == model.ReturnStatusRefundFailed:
+	case ret.Status == model.ReturnStatusRefunding && time.Since(ret.UpdatedAt) >= refundingStaleAfter:
+	case ret.Status == model.ReturnStatusRefunding:
+		return nil, pkgerrors.NewConflictError("退货正在退款中，请稍后重试")
+	default:
+		return nil, pkgerrors.NewInvalidRequestError(fmt.Sprintf("退货状态为 %s，无需重试退款", ret.Status))
+	}
+	return s.refund(ctx, returnNo)
+}
+
+// ReconcileRefunding 重新处理停留在退款中的退货
+func (s *returnService) ReconcileRefunding(ctx context.Context, limit int) (int, error) {
+	returns, err := s.returnRepo.ListStaleRefunding(ctx, time.Now().Add(-refundingStaleAfter), limit)
+	if err != nil {
+		return 0, fmt.Errorf("查询退款中的退货申请失败: %w", err)
+	}
+
+	reconciled := 0
+	for _, ret := range returns {
+		if _, err := s.refund(ctx, ret.ReturnNo); err != nil {
+			logger.Warn("failed to reconcile refunding return",
+				zap.Error(err),
+				zap.String("return_no", ret.ReturnNo))
+			continue
+		}
+		reconciled++
+	}
+	return reconciled, nil
+}
+
+// refund 为退货单发起退款
+//
+//  1. 锁定订单，按当前已退数量计算各商品退款金额并将退货单置为退款中（同一订单同时只处理一笔退货退款）；
+//     停留在退款中超过 refundingStaleAfter 的退货单可重新进入该流程。
+//     退款金额只在首次发起时计算并保存，重试沿用保存的金额（网关幂等键包含金额，金额变化会产生新的退款）
+//  2. 事务外调用 payment-gateway 退款，以退货单ID作为操作人ID，重试时命中网关幂等；
+//     组合支付订单以退货单ID作为分摊来源，按策略分摊到各笔支付逐笔退款
+//  3. 退款成功后累加订单项已退数量/金额和订单累计退款金额，并重新计算订单支付状态
+func (s *returnService) refund(ctx context.Context, returnNo string) (*model.ReturnRequest, error) {
+	var ret *model.ReturnRequest
+	var order *model.Order
+	var tenders int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ret, err = s.returnRepo.GetByReturnNo(ctx, returnNo)
+		if err != nil {
+			return fmt.Errorf("查询退货申请失败: %w", err)
+		}
+		if ret == nil {
+			return pkgerrors.NewNotFoundError("退货申请不存在")
+		}
+
+		order, err = s.lockOrder(tx, ret.OrderNo)
+		if err != nil {
+			return err
+		}
+
+		var refunding int64
+		if err := tx.Model(&model.ReturnRequest{}).
+			Where("order_id = ? AND status = ? AND id <> ?", order.ID, model.ReturnStatusRefunding, ret.ID).
+			Count(&refunding).Error; err != nil {
+			return fmt.Errorf("查询退款中的退货申请失败: %w", err)
+		}
+		if refunding > 0 {
+			return pkgerrors.NewConflictError("该订单有其他退货正在退款，请稍后重试")
+		}
+		if err := tx.Model(&model.OrderPayment{}).Where("order_id = ?", order.ID).Count(&tenders).Error; err != nil {
+			return fmt.Errorf("查询订单支付记录失败: %w", err)
+		}
+
+		updates := map[string]interface{}{
+			"status":       model.ReturnStatusRefunding,
+			"refund_error": "",
+			"updated_at":   time.Now(),
+		}
+		itemAmount, refundAmount := ret.ItemAmount, ret.RefundAmount
+		if ret.RefundStartedAt == nil {
+			if itemAmount, refundAmount, err = s.calculateRefund(tx, order, ret); err != nil {
+				return err
+			}
+			updates["item_amount"] = itemAmount
+			updates["refund_amount"] = refundAmount
+			updates["refund_started_at"] = time.Now()
+		}
+
+		result := tx.Model(&model.ReturnRequest{}).
+			Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))", ret.ID,
+				[]string{model.ReturnStatusApproved, model.ReturnStatusReceived, model.ReturnStatusRefundFailed},
+				model.ReturnStatusRefunding, time.Now().Add(-refundingStaleAfter)).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("更新退货状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return pkgerrors.NewConflictError(fmt.Sprintf("退货状态为 %s，无法退款", ret.Status))
+		}
+
+		ret.ItemAmount = itemAmount
+		ret.RefundAmount = refundAmount
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed to prepare return refund",
+			zap.Error(err),
+			zap.String("return_no", returnNo))
+		return nil, err
+	}
+
+	// 全部被重新上架费抵扣时无需调用网关
+	refundNo := ""
+	if ret.RefundAmount > 0 {
+		var err error
+		if s.tenderService != nil && tenders > 0 {
+			refundNo, err = s.refundTenders(ctx, order.ID, ret)
+		} else {
+			var result *client.RefundResult
+			result, err = s.paymentClient.CreateRefund(ctx, &client.CreateRefundRequest{
+				PaymentNo:    ret.PaymentNo,
+				Amount:       ret.RefundAmount,
+				Reason:       ret.Reason,
+				Description:  fmt.Sprintf("退货单 %s", ret.ReturnNo),
+				OperatorID:   ret.ID,
+				OperatorType: model.OperatorTypeSystem,
+			})
+			if err == nil && result.Status == "failed" {
+				err = fmt.Errorf("退款失败: %s", result.ErrorMsg)
+			}
+			if err == nil {
+				refundNo = result.RefundNo
+			}
+		}
+		if err != nil {
+			logger.Error("return refund failed",
+				zap.Error(err),
+				zap.String("return_no", returnNo),
+				zap.String("payment_no", ret.PaymentNo),
+				zap.Int64("refund_amount", ret.RefundAmount))
+
+			if updateErr := s.db.WithContext(ctx).Model(&model.ReturnRequest{}).
+				Where("id = ? AND status = ?", ret.ID, model.ReturnStatusRefunding).
+				Updates(map[string]interface{}{
+					"status":       model.ReturnStatusRefundFailed,
+					"refund_error": err.Error(),
+					"updated_at":   time.Now(),
+				}).Error; updateErr != nil {
+				logger.Error("failed to mark return refund failed",
+					zap.Error(updateErr),
+					zap.String("return_no", returnNo))
+			}
+			return nil, fmt.Errorf("退货退款失败: %w", err)
+		}
+	}
+
+	if err := s.applyRefund(ctx, ret, refundNo); err != nil {
+		// 网关已退款但本地状态未更新，退货单停留在退款中，由 ReconcileRefunding 或商户重试时重新处理（网关幂等）
+		logger.Error("return refunded but local state update failed",
+			zap.Error(err),
+			zap.String("return_no", returnNo),
+			zap.String("refund_no", refundNo))
+		return nil, err
+	}
+
+	logger.Info("return refunded successfully",
+		zap.String("return_no", returnNo),
+		zap.String("refund_no", refundNo),
+		zap.Int64("refund_amount", ret.RefundAmount),
+		zap.Int64("restocking_fee", ret.RestockingFee))
+
+	if order, err = s.orderRepo.GetByOrderNo(ctx, ret.OrderNo); err == nil && order != nil {
+		s.publishReturnRefundedEvent(ctx, order, ret, refundNo)
+	}
+
+	return s.GetReturn(ctx, returnNo)
+}
+
+// calculateRefund 按订单当前已退数量计算并保存各商品退款金额，返回退货商品金额和实际退款金额
+func (s *returnService) calculateRefund(tx *gorm.DB, order *model.Order, ret *model.ReturnRequest) (int64, int64, error) {
+	itemsByID := make(map[uuid.UUID]*model.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		itemsByID[item.ID] = item
+	}
+
+	itemAmount := int64(0)
+	for _, ri := range ret.Items {
+		item, ok := itemsByID[ri.OrderItemID]
+		if !ok {
+			return 0, 0, fmt.Errorf("订单项不存在: %s", ri.OrderItemID)
+		}
+		ri.Amount = model.ItemReturnAmount(order, item, ri.Quantity)
+		itemAmount += ri.Amount
+		if err := tx.Model(&model.ReturnItem{}).Where("id = ?", ri.ID).
+			Update("amount", ri.Amount).Error; err != nil {
+			return 0, 0, fmt.Errorf("更新退货明细金额失败: %w", err)
+		}
+	}
+
+	refundAmount := itemAmount - ret.RestockingFee
+	if refundAmount < 0 {
+		refundAmount = 0
+	}
+	if remaining := order.PayAmount - order.RefundedAmount; refundAmount > remaining {
+		refundAmount = remaining
+	}
+	return itemAmount, refundAmount, nil
+}
+
+// refundTenders 组合支付订单的退货退款，返回首笔退款单号（各笔退款单号见支付退款分摊明细）
+func (s *returnService) refundTenders(ctx context.Context, orderID uuid.UUID, ret *model.ReturnRequest) (string, error) {
+	refunds, err := s.tenderService.RefundTenders(ctx, orderID, ret.ID, ret.RefundAmount, ret.Reason)
+	if err != nil {
+		return "", err
+	}
+
+	refundNo := ""
+	if len(refunds) > 0 {
+		refundNo = refunds[0].RefundNo
+	}
+	return refundNo, nil
+}
+
+// applyRefund 退款成功后更新退货单、订单项和订单（同一事务；退货单已不在退款中时跳过，避免重复累加）
+func (s *returnService) applyRefund(ctx context.Context, ret *model.ReturnRequest, refundNo string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := s.lockOrder(tx, ret.OrderNo)
+		if err != nil {
+			return err
+		}
+
+		var statuses []string
+		if err := tx.Model(&model.ReturnRequest{}).Where("id = ?", ret.ID).
+			Pluck("status", &statuses).Error; err != nil {
+			return fmt.Errorf("查询退货状态失败: %w", err)
+		}
+		if len(statuses) == 0 || statuses[0] != model.ReturnStatusRefunding {
+			return nil
+		}
+
+		itemsByID := make(map[uuid.UUID]*model.OrderItem, len(order.Items))
+		for _, item := range order.Items {
+			itemsByID[item.ID] = item
+		}
+		for _, ri := range ret.Items {
+			item := itemsByID[ri.OrderItemID]
+			item.RefundedQuantity += ri.Quantity
+			item.RefundedAmount += ri.Amount
+			if err := tx.Model(&model.OrderItem{}).Where("id = ?", item.ID).
+				Updates(map[string]interface{}{
+					"refunded_quantity": item.RefundedQuantity,
+					"refunded_amount":   item.RefundedAmount,
+					"updated_at":        time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("更新订单项退款数量失败: %w", err)
+			}
+		}
+
+		// 记录退回税额（按订单项累计已退数量折算）
+		itemIDs := make([]uuid.UUID, 0, len(ret.Items))
+		for _, ri := range ret.Items {
+			itemIDs = append(itemIDs, ri.OrderItemID)
+		}
+		if err := recordTaxRefunds(tx, order, refundNo, func(refunded map[uuid.UUID]int64) map[uuid.UUID]int64 {
+			return model.ReturnTaxRefunds(order, itemIDs, refunded)
+		}); err != nil {
+			return err
+		}
+
+		oldStatus := order.Status
+		order.RefundedAmount += ret.RefundAmount
+		order.PayStatus = model.RefundPayStatus(order)
+
+		updates := map[string]interface{}{
+			"refunded_amount": order.RefundedAmount,
+			"pay_status":      order.PayStatus,
+			"updated_at":      time.Now(),
+		}
+		if order.PayStatus == model.PayStatusRefunded {
+			order.Status = model.OrderStatusRefunded
+			updates["status"] = order.Status
+			if ret.RequireShipping && order.ShippingStatus != model.ShippingStatusPending {
+				updates["shipping_status"] = model.ShippingStatusReturned
+			}
+		}
+		if err := tx.Model(&model.Order{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新订单退款状态失败: %w", err)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.ReturnRequest{}).Where("id = ?", ret.ID).
+			Updates(map[string]interface{}{
+				"status":      model.ReturnStatusRefunded,
+				"refund_no":   refundNo,
+				"refunded_at": now,
+				"updated_at":  now,
+			}).Error; err != nil {
+			return fmt.Errorf("更新退货状态失败: %w", err)
+		}
+
+		return tx.Create(&model.OrderLog{
+			OrderID:      order.ID,
+			Action:       model.OrderActionRefund,
+			OldStatus:    oldStatus,
+			NewStatus:    order.Status,
+			OperatorID:   uuid.Nil,
+			OperatorType: model.OperatorTypeSystem,
+			Remark: fmt.Sprintf("退货单号: %s, 退款单号: %s, 退款金额: %d, 重新上架费: %d",
+				ret.ReturnNo, refundNo, ret.RefundAmount, ret.RestockingFee),
+		}).Error
+	})
+}
+
+// transition 按当前状态条件更新退货单状态，并可选记录订单日志
+func (s *returnService) transition(ctx context.Context, ret *model.ReturnRequest, from []string, to string, updates map[string]interface{}, action, remark string) error {
+	updates["status"] = to
+	updates["updated_at"] = time.Now()
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.ReturnRequest{}).
+			Where("id = ? AND status IN ?", ret.ID, from).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("更新退货状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("退货状态为 %s，无法变更为 %s", ret.Status, to))
+		}
+
+		if action == "" {
+			return nil
+		}
+		return tx.Create(&model.OrderLog{
+			OrderID:      ret.OrderID,
+			Action:       action,
+			OldStatus:    ret.Status,
+			NewStatus:    to,
+			OperatorID:   uuid.Nil,
+			OperatorType: model.OperatorTypeMerchant,
+			Remark:       remark,
+		}).Error
+	})
+}
+
+// getMerchantReturn 获取退货申请并校验归属商户
+func (s *returnService) getMerchantReturn(ctx context.Context, returnNo string, merchantID uuid.UUID) (*model.ReturnRequest, error) {
+	ret, err := s.GetReturn(ctx, returnNo)
+	if err != nil {
+		return nil, err
+	}
+	if ret.MerchantID != merchantID {
+		return nil, pkgerrors.NewNotFoundError("退货申请不存在")
+	}
+	return ret, nil
+}
+
+// lockOrder 在事务中锁定订单行并加载订单项
+func (s *returnService) lockOrder(tx *gorm.DB, orderNo string) (*model.Order, error) {
+	var order model.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_no = ?", orderNo).
+		First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgerrors.NewNotFoundError("订单不存在")
+		}
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	if err := tx.Where("order_id = ?", order.ID).Find(&order.Items).Error; err != nil {
+		return nil, fmt.Errorf("查询订单项失败: %w", err)
+	}
+	return &order, nil
+}
+
+// pendingReturnQuantities 统计订单各商品在未完成退货申请中占用的数量
+func (s *returnService) pendingReturnQuantities(tx *gorm.DB, orderID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		OrderItemID uuid.UUID
+		Quantity    int
+	}
+	err := tx.Model(&model.ReturnItem{}).
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_id").
+		Where("return_requests.order_id = ? AND return_requests.status IN ?", orderID, model.ActiveReturnStatuses).
+		Group("return_items.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计退货中数量失败: %w", err)
+	}
+
+	pending := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		pending[row.OrderItemID] = row.Quantity
+	}
+	return pending, nil
+}
+
+// generateReturnNo 生成退货单号
+func (s *returnService) generateReturnNo() string {
+	// 格式：RT + 时间戳 + 随机字符
+	timestamp := time.Now().Format("20060102150405")
+	randomBytes := make([]byte, 8)
+	rand.Read(randomBytes)
+	randomStr := base64.URLEncoding.EncodeToString(randomBytes)[:10]
+	return fmt.Sprintf("RT%s%s", timestamp, randomStr)
+}
+
+// publishReturnRefundedEvent 发布退货退款的订单事件
+func (s *returnService) publishReturnRefundedEvent(ctx context.Context, order *model.Order, ret *model.ReturnRequest, refundNo string) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	payload := events.OrderEventPayload{
+		OrderNo:       order.OrderNo,
+		MerchantID:    order.MerchantID.String(),
+		PaymentNo:     order.PaymentNo,
+		TotalAmount:   order.TotalAmount,
+		Currency:      order.Currency,
+		Status:        order.Status,
+		CustomerEmail: order.CustomerEmail,
+		PaidAt:        order.PaidAt,
+		Extra: map[string]interface{}{
+			"pay_status":      order.PayStatus,
+			"refunded_amount": order.RefundedAmount,
+			"return_no":       ret.ReturnNo,
+			"refund_no":       refundNo,
+			"refund_amount":   ret.RefundAmount,
+			"restocking_fee":  ret.RestockingFee,
+		},
+	}
+	s.eventPublisher.PublishOrderEventAsync(ctx, events.NewOrderEvent(events.OrderRefunded, payload))
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/tax"
+	"gorm.io/gorm"
+	"payment-platform/order-service/internal/model"
+	"payment-platform/order-service/internal/repository"
+)
+
+// shippingTaxReference 运费计税明细标识
+const shippingTaxReference = "shipping"
+
+// TaxService 税费服务接口（税率规则维护、计税试算、税务报表）
+type TaxService interface {
+	// Engine 计税引擎（数据库税率规则优先，内置税率表兜底）
+	Engine() tax.Engine
+	Calculate(ctx context.Context, req *tax.Request) (*tax.Result, error)
+
+	ListRules(ctx context.Context, country string) ([]*model.TaxRule, error)
+	SaveRule(ctx context.Context, input *TaxRuleInput) (*model.TaxRule, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	GetTaxReport(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) (*TaxReport, error)
+}
+
+type taxService struct {
+	taxRepo repository.TaxRepository
+	engine  tax.Engine
+}
+
+// NewTaxService 创建税费服务实例
+func NewTaxService(taxRepo repository.TaxRepository) TaxService {
+	return &taxService{
+		taxRepo: taxRepo,
+		engine:  tax.NewRuleEngine(tax.ChainSource{&dbRuleSource{taxRepo: taxRepo}, tax.DefaultRules()}),
+	}
+}
+
+// TaxRuleInput 税率规则输入
+type TaxRuleInput struct {
+	Country       string        `json:"country" binding:"required,len=2"`
+	Region        string        `json:"region"`
+	Category      string        `json:"category"`
+	TaxName       string        `json:"tax_name" binding:"required"`
+	Rate          money.Decimal `json:"rate"` // 税率（百分比）
+	ReverseCharge bool          `json:"reverse_charge"`
+	Enabled       *bool         `json:"enabled"` // 默认启用
+}
+
+// TaxReport 商户税务报表
+type TaxReport struct {
+	MerchantID uuid.UUID             `json:"merchant_id"`
+	StartTime  time.Time             `json:"start_time"`
+	EndTime    time.Time             `json:"end_time"`
+	Rows       []*model.TaxReportRow `json:"rows"`
+	Totals     []*TaxReportTotal     `json:"totals"` // 按币种合计
+}
+
+// TaxReportTotal 税务报表币种合计
+type TaxReportTotal struct {
+	Currency          string `json:"currency"`
+	TaxableAmount     int64  `json:"taxable_amount"`
+	TaxAmount         int64  `json:"tax_amount"`
+	RefundedTaxAmount int64  `json:"refunded_tax_amount"`
+	NetTaxAmount      int64  `json:"net_tax_amount"`
+}
+
+// Engine 返回计税引擎
+func (s *taxService) Engine() tax.Engine {
+	return s.engine
+}
+
+// Calculate 计税试算
+func (s *taxService) Calculate(ctx context.Context, req *tax.Request) (*tax.Result, error) {
+	result, err := s.engine.Calculate(ctx, req)
+	if err != nil {
+		return nil, pkgerrors.NewInvalidRequestError(err.Error())
+	}
+	return result, nil
+}
+
+// ListRules 查询税率规则
+func (s *taxService) ListRules(ctx context.Context, country string) ([]*model.TaxRule, error) {
+	return s.taxRepo.ListRules(ctx, country)
+}
+
+// SaveRule 新增或覆盖税率规则
+func (s *taxService) SaveRule(ctx context.Context, input *TaxRuleInput) (*model.TaxRule, error) {
+	if input.Rate.Sign() < 0 || input.Rate.Cmp(money.DecimalFromInt(100)) > 0 {
+		return nil, pkgerrors.NewInvalidRequestError("税率必须在 0-100 之间")
+	}
+
+	category := input.Category
+	if category == "" {
+		category = tax.CategoryStandard
+	}
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	rule := &model.TaxRule{
+		Country:       strings.ToUpper(input.Country),
+		Region:        strings.ToUpper(input.Region),
+		Category:      category,
+		TaxName:       input.TaxName,
+		Rate:          input.Rate,
+		ReverseCharge: input.ReverseCharge,
+		Enabled:       enabled,
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.taxRepo.SaveRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("保存税率规则失败: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除税率规则
+func (s *taxService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if err := s.taxRepo.DeleteRule(ctx, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return pkgerrors.NewNotFoundError("税率规则不存在")
+		}
+		return fmt.Errorf("删除税率规则失败: %w", err)
+	}
+	return nil
+}
+
+// GetTaxReport 商户税务报表（按支付时间统计，[startTime, endTime)）
+func (s *taxService) GetTaxReport(ctx context.Context, merchantID uuid.UUID, startTime, endTime time.Time) (*TaxReport, error) {
+	if !endTime.After(startTime) {
+		return nil, pkgerrors.NewInvalidRequestError("结束时间必须晚于开始时间")
+	}
+
+	rows, err := s.taxRepo.GetTaxReport(ctx, merchantID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("查询税务报表失败: %w", err)
+	}
+
+	totals := make([]*TaxReportTotal, 0)
+	byCurrency := make(map[string]*TaxReportTotal)
+	for _, row := range rows {
+		total, ok := byCurrency[row.Currency]
+		if !ok {
+			total = &TaxReportTotal{Currency: row.Currency}
+			byCurrency[row.Currency] = total
+			totals = append(totals, total)
+		}
+		total.TaxableAmount += row.TaxableAmount
+		total.TaxAmount += row.TaxAmount
+		total.RefundedTaxAmount += row.RefundedTaxAmount
+		total.NetTaxAmount += row.NetTaxAmount
+	}
+
+	return &TaxReport{
+		MerchantID: merchantID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Rows:       rows,
+		Totals:     totals,
+	}, nil
+}
+
+// dbRuleSource 数据库税率规则来源（仅启用的规则）
+type dbRuleSource struct {
+	taxRepo repository.TaxRepository
+}
+
+// Rules 实现 tax.RuleSource
+func (s *dbRuleSource) Rules(ctx context.Context, country string) ([]tax.Rule, error) {
+	rules, err := s.taxRepo.ListRules(ctx, country)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]tax.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled {
+			result = append(result, rule.ToRule())
+		}
+	}
+	return result, nil
+}
+
+// taxDestination 订单的税务管辖地：取收货地址，无收货地址时取账单地址，均无国家时返回 nil
+func taxDestination(input *CreateOrderInput) *model.Address {
+	destination := input.ShippingAddress
+	if destination == nil || destination.Country == "" {
+		destination = input.BillingAddress
+	}
+	if destination == nil || destination.Country == "" {
+		return nil
+	}
+	return destination
+}
+
+// calculateOrderTax 计算订单税费并回填订单和订单项的税额
+//
+// 订单级优惠按商品金额比例分摊后作为计税基数，运费单独一行计税；
+// 税务管辖地取收货地址，无收货地址时取账单地址。价外税计入实付金额。
+func (s *orderService) calculateOrderTax(ctx context.Context, order *model.Order, items []*model.OrderItem, input *CreateOrderInput) (*tax.Result, error) {
+	destination := taxDestination(input)
+	if destination == nil {
+		return nil, pkgerrors.NewInvalidRequestError("计税需要提供收货地址或账单地址的国家")
+	}
+
+	netOrder := &model.Order{DiscountAmount: order.DiscountAmount, Items: items}
+	req := &tax.Request{
+		Currency:         order.Currency,
+		SellerCountry:    input.Tax.SellerCountry,
+		Destination:      tax.Location{Country: destination.Country, Region: destination.Province},
+		BuyerTaxID:       input.Tax.CustomerTaxID,
+		PricesIncludeTax: input.Tax.PricesIncludeTax,
+		Lines:            make([]tax.LineInput, 0, len(items)+1),
+	}
+	for i, item := range items {
+		req.Lines = append(req.Lines, tax.LineInput{
+			Reference: strconv.Itoa(i),
+			Category:  item.TaxCategory,
+			Amount:    model.ItemNetAmount(netOrder, item),
+		})
+	}
+	if order.ShippingFee > 0 {
+		req.Lines = append(req.Lines, tax.LineInput{
+			Reference: shippingTaxReference,
+			Category:  tax.CategoryShipping,
+			Amount:    order.ShippingFee,
+		})
+	}
+
+	result, err := s.taxEngine.Calculate(ctx, req)
+	if err != nil {
+		return nil, pkgerrors.NewInvalidRequestError(fmt.Sprintf("计算税费失败: %v", err))
+	}
+
+	for _, line := range result.Lines {
+		if idx, err := strconv.Atoi(line.Reference); err == nil {
+			items[idx].TaxCategory = line.Category
+			items[idx].TaxAmount = line.TaxAmount
+		}
+	}
+
+	order.TaxAmount = result.TaxAmount
+	order.TaxInclusive = result.PricesIncludeTax
+	order.CustomerTaxID = input.Tax.CustomerTaxID
+	order.ReverseCharge = result.ReverseCharge
+	if !order.TaxInclusive {
+		order.PayAmount += result.TaxAmount
+	}
+	return result, nil
+}
+
+// buildOrderTaxLines 将计税结果转换为订单税费明细（订单项创建后调用）
+func buildOrderTaxLines(order *model.Order, items []*model.OrderItem, result *tax.Result) []*model.OrderTaxLine {
+	lines := make([]*model.OrderTaxLine, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		taxLine := &model.OrderTaxLine{
+			OrderID:       order.ID,
+			MerchantID:    order.MerchantID,
+			Category:      line.Category,
+			Country:       line.Country,
+			Region:        line.Region,
+			TaxName:       line.TaxName,
+			Rate:          line.Rate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+			ReverseCharge: line.ReverseCharge,
+			Currency:      order.Currency,
+		}
+		if idx, err := strconv.Atoi(line.Reference); err == nil {
+			itemID := items[idx].ID
+			taxLine.OrderItemID = &itemID
+		}
+		lines = append(lines, taxLine)
+	}
+	return lines
+}
+
+// recordTaxRefunds 在退款事务内记录本次退款退回的税额
+//
+// allocate 根据订单税费明细（已加载到 order.TaxLines）和各明细已退税额计算本次退回税额，见 model.ReturnTaxRefunds / model.AmountTaxRefunds。
+func recordTaxRefunds(tx *gorm.DB, order *model.Order, refundNo string, allocate func(refunded map[uuid.UUID]int64) map[uuid.UUID]int64) error {
+	if err := tx.Where("order_id = ?", order.ID).Find(&order.TaxLines).Error; err != nil {
+		return fmt.Errorf("查询税费明细失败: %w", err)
+	}
+	if len(order.TaxLines) == 0 {
+		return nil
+	}
+
+	var sums []struct {
+		TaxLineID uuid.UUID
+		TaxAmount int64
+	}
+	if err := tx.Model(&model.OrderTaxRefund{}).
+		Select("tax_line_id, COALESCE(SUM(tax_amount), 0) AS tax_amount").
+		Where("order_id = ?", order.ID).
+		Group("tax_line_id").
+		Scan(&sums).Error; err != nil {
+		return fmt.Errorf("查询已退税额失败: %w", err)
+	}
+	refunded := make(map[uuid.UUID]int64, len(sums))
+	for _, sum := range sums {
+		refunded[sum.TaxLineID] = sum.TaxAmount
+	}
+
+	amounts := allocate(refunded)
+	rows := make([]*model.OrderTaxRefund, 0, len(amounts))
+	for _, line := range order.TaxLines {
+		if amount := amounts[line.ID]; amount > 0 {
+			rows = append(rows, &model.OrderTaxRefund{
+				OrderID:    order.ID,
+				TaxLineID:  line.ID,
+				MerchantID: order.MerchantID,
+				RefundNo:   refundNo,
+				TaxAmount:  amount,
+				Currency:   line.Currency,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("记录退回税额失败: %w", err)
+	}
+	return nil
+}
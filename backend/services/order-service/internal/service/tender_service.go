@@ -0,0 +1,692 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/events"
+	"github.com/payment-platform/pkg/kafka"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/order-service/internal/client"
+	"payment-platform/order-service/internal/model"
+	"payment-platform/order-service/internal/repository"
+)
+
+// TenderService 组合支付服务接口
+//
+// 一笔订单可由多笔支付（礼品卡、钱包、银行卡等）共同完成：逐笔登记并确认，已付金额达到实付金额后订单才变为已支付；
+// 订单取消或超时关闭时自动撤销待支付的部分并退回已支付的部分；订单退款按配置的策略分摊到各笔支付。
+type TenderService interface {
+	AddPayment(ctx context.Context, orderNo string, input *AddPaymentInput) (*model.OrderPayment, error)
+	// ConfirmPayment 确认已登记的支付成功（未登记的支付流水号返回不存在）
+	ConfirmPayment(ctx context.Context, orderNo, paymentNo string) (*model.Order, error)
+	FailPayment(ctx context.Context, orderNo, paymentNo, reason string) error
+	ListPayments(ctx context.Context, orderNo string) ([]*model.OrderPayment, error)
+
+	// RefundTenders 按策略将退款分摊到各笔支付并通过 payment-gateway 退款（同一 sourceID 重试时沿用首次分摊）
+	RefundTenders(ctx context.Context, orderID, sourceID uuid.UUID, amount int64, reason string) ([]*model.OrderPaymentRefund, error)
+	// ReleaseTenders 撤销已关闭订单中待支付的部分，并全额退回已支付的部分
+	ReleaseTenders(ctx context.Context, orderNo, reason string) error
+	// ExpireOrders 关闭超时未付清的订单，并释放已关闭订单中尚未撤销或退回的支付
+	ExpireOrders(ctx context.Context, batchSize int) (int, error)
+}
+
+type tenderService struct {
+	db             *gorm.DB
+	orderRepo      repository.OrderRepository
+	paymentClient  *client.PaymentClient
+	eventPublisher *kafka.EventPublisher
+	refundStrategy string // 退款分摊策略
+}
+
+// NewTenderService 创建组合支付服务实例
+func NewTenderService(db *gorm.DB, orderRepo repository.OrderRepository, paymentClient *client.PaymentClient, eventPublisher *kafka.EventPublisher, refundStrategy string) TenderService {
+	if refundStrategy == "" {
+		refundStrategy = model.RefundStrategyReverse
+	}
+	return &tenderService{
+		db:             db,
+		orderRepo:      orderRepo,
+		paymentClient:  paymentClient,
+		eventPublisher: eventPublisher,
+		refundStrategy: refundStrategy,
+	}
+}
+
+// AddPaymentInput 登记支付输入
+type AddPaymentInput struct {
+	PaymentNo  string `json:"payment_no" binding:"required"`
+	TenderType string `json:"tender_type" binding:"required"` // card, wallet, gift_card ...
+	Amount     int64  `json:"amount" binding:"required,gt=0"`
+}
+
+// closedOrderStatuses 需要释放组合支付的订单状态
+var closedOrderStatuses = []string{model.OrderStatusCancelled, model.OrderStatusExpired}
+
+// AddPayment 登记一笔待支付的支付（已付金额 + 待支付金额不能超过订单实付金额）
+func (s *tenderService) AddPayment(ctx context.Context, orderNo string, input *AddPaymentInput) (*model.OrderPayment, error) {
+	var payment *model.OrderPayment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := s.lockOrder(tx, orderNo)
+		if err != nil {
+			return err
+		}
+		payments, err := s.listPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range payments {
+			if p.PaymentNo != input.PaymentNo {
+				continue
+			}
+			// 重复登记同一笔支付
+			if p.Amount != input.Amount || p.TenderType != input.TenderType {
+				return pkgerrors.NewConflictError("支付流水号已登记，支付方式或金额不一致")
+			}
+			payment = p
+			return nil
+		}
+
+		if order.Status != model.OrderStatusPending {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("订单状态为 %s，无法继续支付", order.Status))
+		}
+
+		var used int64
+		if err := tx.Model(&model.OrderPayment{}).Where("payment_no = ?", input.PaymentNo).Count(&used).Error; err != nil {
+			return fmt.Errorf("查询支付记录失败: %w", err)
+		}
+		if used > 0 {
+			return pkgerrors.NewConflictError("支付流水号已被其他订单使用")
+		}
+
+		if due := order.DueAmount() - pendingAmount(payments); input.Amount > due {
+			return pkgerrors.NewInvalidRequestError(fmt.Sprintf("支付金额 %d 超过待支付金额 %d", input.Amount, due))
+		}
+
+		payment = &model.OrderPayment{
+			OrderID:    order.ID,
+			PaymentNo:  input.PaymentNo,
+			TenderType: input.TenderType,
+			Sequence:   len(payments) + 1,
+			Amount:     input.Amount,
+			Status:     model.TenderStatusPending,
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return fmt.Errorf("登记支付失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed to add order payment",
+			zap.Error(err),
+			zap.String("order_no", orderNo),
+			zap.String("payment_no", input.PaymentNo))
+		return nil, err
+	}
+	return payment, nil
+}
+
+// ConfirmPayment 确认支付成功
+//
+// 已付金额达到实付金额时订单变为已支付并发布支付成功事件，否则为部分支付；
+// 订单取消或超时关闭后才到账的支付照常记账，随后自动退回。
+func (s *tenderService) ConfirmPayment(ctx context.Context, orderNo, paymentNo string) (*model.Order, error) {
+	var order *model.Order
+	var payment *model.OrderPayment
+	var fullyPaid, release bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = s.lockOrder(tx, orderNo)
+		if err != nil {
+			return err
+		}
+		payments, err := s.listPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range payments {
+			if p.PaymentNo == paymentNo {
+				payment = p
+				break
+			}
+		}
+		// 只确认已登记的支付，金额以登记时为准，避免任意流水号结清订单剩余金额
+		if payment == nil {
+			return pkgerrors.NewNotFoundError("支付流水号未登记")
+		}
+
+		switch payment.Status {
+		case model.TenderStatusSucceeded:
+			return nil // 重复确认
+		case model.TenderStatusPending:
+		default:
+			return pkgerrors.NewConflictError(fmt.Sprintf("支付状态为 %s，无法确认", payment.Status))
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.OrderPayment{}).Where("id = ?", payment.ID).
+			Updates(map[string]interface{}{
+				"status":     model.TenderStatusSucceeded,
+				"paid_at":    now,
+				"updated_at": now,
+			}).Error; err != nil {
+			return fmt.Errorf("更新支付状态失败: %w", err)
+		}
+		payment.Status = model.TenderStatusSucceeded
+		payment.PaidAt = &now
+
+		oldStatus := order.Status
+		order.PaidAmount += payment.Amount
+		updates := map[string]interface{}{
+			"paid_amount": order.PaidAmount,
+			"updated_at":  now,
+		}
+		if order.PaymentNo == "" {
+			order.PaymentNo = paymentNo
+			updates["payment_no"] = paymentNo
+		}
+		switch {
+		case order.Status != model.OrderStatusPending:
+			release = true
+		case order.PaidAmount >= order.PayAmount:
+			fullyPaid = true
+			order.Status = model.OrderStatusPaid
+			order.PayStatus = model.PayStatusPaid
+			order.PaidAt = &now
+			updates["status"] = order.Status
+			updates["pay_status"] = order.PayStatus
+			updates["paid_at"] = now
+		default:
+			order.PayStatus = model.PayStatusPartialPaid
+			updates["pay_status"] = order.PayStatus
+		}
+		if err := tx.Model(&model.Order{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新订单支付状态失败: %w", err)
+		}
+
+		return tx.Create(&model.OrderLog{
+			OrderID:      order.ID,
+			Action:       model.OrderActionPay,
+			OldStatus:    oldStatus,
+			NewStatus:    order.Status,
+			OperatorID:   uuid.Nil,
+			OperatorType: model.OperatorTypeSystem,
+			Remark: fmt.Sprintf("支付成功，支付流水号: %s, 支付方式: %s, 金额: %d, 已付: %d/%d",
+				paymentNo, payment.TenderType, payment.Amount, order.PaidAmount, order.PayAmount),
+		}).Error
+	})
+	if err != nil {
+		logger.Error("failed to confirm order payment",
+			zap.Error(err),
+			zap.String("order_no", orderNo),
+			zap.String("payment_no", paymentNo))
+		return nil, err
+	}
+
+	if release {
+		logger.Warn("payment succeeded after order closed, releasing",
+			zap.String("order_no", orderNo),
+			zap.String("payment_no", paymentNo),
+			zap.String("status", order.Status))
+		if err := s.ReleaseTenders(ctx, orderNo, "订单已关闭，退回关闭后到账的支付"); err != nil {
+			// 由定时任务重试
+			logger.Error("failed to release late payment",
+				zap.Error(err),
+				zap.String("order_no", orderNo))
+		}
+	}
+	if fullyPaid {
+		s.publishOrderPaidEvent(ctx, order)
+	}
+	return order, nil
+}
+
+// FailPayment 标记待支付的支付失败（释放其占用的待支付金额）
+func (s *tenderService) FailPayment(ctx context.Context, orderNo, paymentNo, reason string) error {
+	order, err := s.getOrder(ctx, orderNo)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Model(&model.OrderPayment{}).
+		Where("order_id = ? AND payment_no = ? AND status = ?", order.ID, paymentNo, model.TenderStatusPending).
+		Updates(map[string]interface{}{
+			"status":         model.TenderStatusFailed,
+			"failure_reason": reason,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("更新支付状态失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return pkgerrors.NewNotFoundError("待支付的支付记录不存在")
+	}
+	return nil
+}
+
+// ListPayments 查询订单的支付记录
+func (s *tenderService) ListPayments(ctx context.Context, orderNo string) ([]*model.OrderPayment, error) {
+	order, err := s.getOrder(ctx, orderNo)
+	if err != nil {
+		return nil, err
+	}
+	return order.Payments, nil
+}
+
+// RefundTenders 按策略分摊退款并逐笔调用 payment-gateway 退款
+//
+// 分摊结果先落库，再在事务外逐笔退款，以分摊明细ID作为操作人ID，重试时命中网关幂等；
+// 其他来源正在退款中的金额不参与分摊。
+func (s *tenderService) RefundTenders(ctx context.Context, orderID, sourceID uuid.UUID, amount int64, reason string) ([]*model.OrderPaymentRefund, error) {
+	return s.refund(ctx, orderID, sourceID, reason, func(payments []*model.OrderPayment) ([]model.TenderAllocation, error) {
+		return model.AllocateRefund(payments, amount, s.refundStrategy)
+	})
+}
+
+// ReleaseTenders 撤销已关闭订单中待支付的部分，并全额退回已支付的部分
+func (s *tenderService) ReleaseTenders(ctx context.Context, orderNo, reason string) error {
+	order, err := s.getOrder(ctx, orderNo)
+	if err != nil {
+		return err
+	}
+	if order.Status != model.OrderStatusCancelled && order.Status != model.OrderStatusExpired {
+		return pkgerrors.NewInvalidRequestError(fmt.Sprintf("订单状态为 %s，无需释放支付", order.Status))
+	}
+
+	var failures []string
+	for _, payment := range order.Payments {
+		switch {
+		case payment.Status == model.TenderStatusPending:
+			if err := s.voidPayment(ctx, payment, reason); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", payment.PaymentNo, err))
+			}
+		case payment.Refundable() > 0:
+			// 以支付记录ID作为退款来源，整笔退回
+			paymentID := payment.ID
+			_, err := s.refund(ctx, order.ID, paymentID, reason, func(payments []*model.OrderPayment) ([]model.TenderAllocation, error) {
+				for _, p := range payments {
+					if p.ID == paymentID && p.Refundable() > 0 {
+						return []model.TenderAllocation{{Payment: p, Amount: p.Refundable()}}, nil
+					}
+				}
+				return nil, fmt.Errorf("支付 %s 无可退金额", payment.PaymentNo)
+			})
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", payment.PaymentNo, err))
+			}
+		}
+	}
+
+	if err := s.syncReleasedAmount(ctx, orderNo, reason); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("释放组合支付失败: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// ExpireOrders 关闭超时未付清的订单，并释放已关闭订单中尚未撤销或退回的支付（含之前释放失败的订单）
+func (s *tenderService) ExpireOrders(ctx context.Context, batchSize int) (int, error) {
+	var orders []*model.Order
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND expired_at IS NOT NULL AND expired_at < ?", model.OrderStatusPending, time.Now()).
+		Order("expired_at ASC").
+		Limit(batchSize).
+		Find(&orders).Error; err != nil {
+		return 0, fmt.Errorf("查询超时订单失败: %w", err)
+	}
+
+	expired := 0
+	for _, order := range orders {
+		changed := false
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&model.Order{}).
+				Where("id = ? AND status = ?", order.ID, model.OrderStatusPending).
+				Updates(map[string]interface{}{
+					"status":     model.OrderStatusExpired,
+					"updated_at": time.Now(),
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			changed = true
+			return tx.Create(&model.OrderLog{
+				OrderID:      order.ID,
+				Action:       model.OrderActionExpire,
+				OldStatus:    model.OrderStatusPending,
+				NewStatus:    model.OrderStatusExpired,
+				OperatorID:   uuid.Nil,
+				OperatorType: model.OperatorTypeSystem,
+				Remark:       fmt.Sprintf("订单超时未付清，已付: %d/%d", order.PaidAmount, order.PayAmount),
+			}).Error
+		})
+		if err != nil {
+			logger.Error("failed to expire order",
+				zap.Error(err),
+				zap.String("order_no", order.OrderNo))
+			continue
+		}
+		if changed {
+			expired++
+		}
+	}
+
+	var orderNos []string
+	if err := s.db.WithContext(ctx).
+		Table("order_payments AS p").
+		Joins("JOIN orders o ON o.id = p.order_id").
+		Where("o.status IN ?", closedOrderStatuses).
+		Where("p.status = ? OR (p.status = ? AND p.refunded_amount < p.amount)", model.TenderStatusPending, model.TenderStatusSucceeded).
+		Distinct("o.order_no").
+		Limit(batchSize).
+		Pluck("o.order_no", &orderNos).Error; err != nil {
+		return expired, fmt.Errorf("查询待释放支付的订单失败: %w", err)
+	}
+	for _, orderNo := range orderNos {
+		if err := s.ReleaseTenders(ctx, orderNo, "订单已关闭，自动撤销/退回支付"); err != nil {
+			logger.Warn("failed to release order payments",
+				zap.Error(err),
+				zap.String("order_no", orderNo))
+		}
+	}
+
+	return expired, nil
+}
+
+// refund 分摊退款并逐笔执行
+func (s *tenderService) refund(ctx context.Context, orderID, sourceID uuid.UUID, reason string, allocate func([]*model.OrderPayment) ([]model.TenderAllocation, error)) ([]*model.OrderPaymentRefund, error) {
+	var refunds []*model.OrderPaymentRefund
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定订单，避免并发分摊
+		var order model.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return pkgerrors.NewNotFoundError("订单不存在")
+			}
+			return fmt.Errorf("查询订单失败: %w", err)
+		}
+
+		if err := tx.Where("source_id = ?", sourceID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+			return fmt.Errorf("查询退款分摊失败: %w", err)
+		}
+		if len(refunds) > 0 {
+			return nil // 重试沿用首次分摊
+		}
+
+		payments, err := s.listPayments(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := s.reserveInflight(tx, orderID, payments); err != nil {
+			return err
+		}
+
+		allocations, err := allocate(payments)
+		if err != nil {
+			return pkgerrors.NewInvalidRequestError(err.Error())
+		}
+		for _, a := range allocations {
+			refunds = append(refunds, &model.OrderPaymentRefund{
+				OrderID:        orderID,
+				OrderPaymentID: a.Payment.ID,
+				PaymentNo:      a.Payment.PaymentNo,
+				SourceID:       sourceID,
+				Amount:         a.Amount,
+				Status:         model.TenderRefundStatusPending,
+			})
+		}
+		if len(refunds) == 0 {
+			return nil
+		}
+		if err := tx.Create(&refunds).Error; err != nil {
+			return fmt.Errorf("保存退款分摊失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	for _, r := range refunds {
+		if r.Status == model.TenderRefundStatusSuccess {
+			continue
+		}
+		if err := s.executeRefund(ctx, r, reason); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", r.PaymentNo, err))
+		}
+	}
+	if len(failures) > 0 {
+		return refunds, fmt.Errorf("退款失败: %s", strings.Join(failures, "; "))
+	}
+	return refunds, nil
+}
+
+// executeRefund 调用 payment-gateway 退款并更新分摊明细和支付记录的已退金额
+func (s *tenderService) executeRefund(ctx context.Context, r *model.OrderPaymentRefund, reason string) error {
+	result, err := s.paymentClient.CreateRefund(ctx, &client.CreateRefundRequest{
+		PaymentNo:    r.PaymentNo,
+		Amount:       r.Amount,
+		Reason:       reason,
+		Description:  fmt.Sprintf("组合支付退款 %s", r.SourceID),
+		OperatorID:   r.ID,
+		OperatorType: model.OperatorTypeSystem,
+	})
+	if err == nil && result.Status == "failed" {
+		err = fmt.Errorf("退款失败: %s", result.ErrorMsg)
+	}
+	if err != nil {
+		logger.Error("tender refund failed",
+			zap.Error(err),
+			zap.String("payment_no", r.PaymentNo),
+			zap.Int64("amount", r.Amount))
+		r.Status = model.TenderRefundStatusFailed
+		r.FailureReason = err.Error()
+		if updateErr := s.db.WithContext(ctx).Model(&model.OrderPaymentRefund{}).Where("id = ?", r.ID).
+			Updates(map[string]interface{}{
+				"status":         r.Status,
+				"failure_reason": r.FailureReason,
+				"updated_at":     time.Now(),
+			}).Error; updateErr != nil {
+			logger.Error("failed to mark tender refund failed", zap.Error(updateErr), zap.String("refund_id", r.ID.String()))
+		}
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.OrderPaymentRefund{}).
+			Where("id = ? AND status <> ?", r.ID, model.TenderRefundStatusSuccess).
+			Updates(map[string]interface{}{
+				"status":         model.TenderRefundStatusSuccess,
+				"refund_no":      result.RefundNo,
+				"failure_reason": "",
+				"updated_at":     time.Now(),
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&model.OrderPayment{}).Where("id = ?", r.OrderPaymentID).
+			Updates(map[string]interface{}{
+				"refunded_amount": gorm.Expr("refunded_amount + ?", r.Amount),
+				"updated_at":      time.Now(),
+			}).Error
+	})
+	if err != nil {
+		// 网关已退款但本地状态未更新，需人工核对
+		logger.Error("tender refunded but local state update failed",
+			zap.Error(err),
+			zap.String("payment_no", r.PaymentNo),
+			zap.String("refund_no", result.RefundNo))
+		return fmt.Errorf("更新退款分摊失败: %w", err)
+	}
+
+	r.Status = model.TenderRefundStatusSuccess
+	r.RefundNo = result.RefundNo
+	r.FailureReason = ""
+	return nil
+}
+
+// voidPayment 通过 payment-gateway 取消待支付的支付（取消失败时保持待支付，到账后按关闭订单的迟到支付退回）
+func (s *tenderService) voidPayment(ctx context.Context, payment *model.OrderPayment, reason string) error {
+	if err := s.paymentClient.CancelPayment(ctx, payment.PaymentNo, reason); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.db.WithContext(ctx).Model(&model.OrderPayment{}).
+		Where("id = ? AND status = ?", payment.ID, model.TenderStatusPending).
+		Updates(map[string]interface{}{
+			"status":         model.TenderStatusVoided,
+			"failure_reason": reason,
+			"voided_at":      now,
+			"updated_at":     now,
+		}).Error
+}
+
+// syncReleasedAmount 按各笔支付的已退金额更新已关闭订单的累计退款金额和支付状态
+func (s *tenderService) syncReleasedAmount(ctx context.Context, orderNo, reason string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := s.lockOrder(tx, orderNo)
+		if err != nil {
+			return err
+		}
+		payments, err := s.listPayments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refunded := int64(0)
+		for _, p := range payments {
+			refunded += p.RefundedAmount
+		}
+		if refunded == order.RefundedAmount {
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"refunded_amount": refunded,
+			"updated_at":      time.Now(),
+		}
+		if order.PaidAmount > 0 && refunded >= order.PaidAmount {
+			updates["pay_status"] = model.PayStatusRefunded
+		}
+		if err := tx.Model(&model.Order{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新订单退款金额失败: %w", err)
+		}
+
+		return tx.Create(&model.OrderLog{
+			OrderID:      order.ID,
+			Action:       model.OrderActionTenderRelease,
+			OldStatus:    order.Status,
+			NewStatus:    order.Status,
+			OperatorID:   uuid.Nil,
+			OperatorType: model.OperatorTypeSystem,
+			Remark:       fmt.Sprintf("退回已付金额: %d/%d, 原因: %s", refunded, order.PaidAmount, reason),
+		}).Error
+	})
+}
+
+// reserveInflight 将其他来源正在退款中的金额计入已退金额，避免并发分摊超退
+func (s *tenderService) reserveInflight(tx *gorm.DB, orderID uuid.UUID, payments []*model.OrderPayment) error {
+	var rows []struct {
+		OrderPaymentID uuid.UUID
+		Amount         int64
+	}
+	if err := tx.Model(&model.OrderPaymentRefund{}).
+		Select("order_payment_id, SUM(amount) AS amount").
+		Where("order_id = ? AND status = ?", orderID, model.TenderRefundStatusPending).
+		Group("order_payment_id").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("查询退款中金额失败: %w", err)
+	}
+
+	for _, row := range rows {
+		for _, p := range payments {
+			if p.ID == row.OrderPaymentID {
+				p.RefundedAmount += row.Amount
+			}
+		}
+	}
+	return nil
+}
+
+// getOrder 获取订单（含支付记录）
+func (s *tenderService) getOrder(ctx context.Context, orderNo string) (*model.Order, error) {
+	order, err := s.orderRepo.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	if order == nil {
+		return nil, pkgerrors.NewNotFoundError("订单不存在")
+	}
+	return order, nil
+}
+
+// lockOrder 在事务中锁定订单行
+func (s *tenderService) lockOrder(tx *gorm.DB, orderNo string) (*model.Order, error) {
+	var order model.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_no = ?", orderNo).
+		First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgerrors.NewNotFoundError("订单不存在")
+		}
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	return &order, nil
+}
+
+// listPayments 按支付顺序查询订单的支付记录
+func (s *tenderService) listPayments(tx *gorm.DB, orderID uuid.UUID) ([]*model.OrderPayment, error) {
+	var payments []*model.OrderPayment
+	if err := tx.Where("order_id = ?", orderID).Order("sequence ASC").Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("查询支付记录失败: %w", err)
+	}
+	return payments, nil
+}
+
+// pendingAmount 待支付的支付记录占用的金额
+func pendingAmount(payments []*model.OrderPayment) int64 {
+	total := int64(0)
+	for _, p := range payments {
+		if p.Status == model.TenderStatusPending {
+			total += p.Amount
+		}
+	}
+	return total
+}
+
+// publishOrderPaidEvent 发布订单付清事件
+func (s *tenderService) publishOrderPaidEvent(ctx context.Context, order *model.Order) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	payload := events.OrderEventPayload{
+		OrderNo:       order.OrderNo,
+		MerchantID:    order.MerchantID.String(),
+		PaymentNo:     order.PaymentNo,
+		TotalAmount:   order.TotalAmount,
+		Currency:      order.Currency,
+		Status:        order.Status,
+		CustomerEmail: order.CustomerEmail,
+		PaidAt:        order.PaidAt,
+		Extra: map[string]interface{}{
+			"pay_amount":      order.PayAmount,
+			"discount_amount": order.DiscountAmount,
+			"shipping_fee":    order.ShippingFee,
+			"paid_amount":     order.PaidAmount,
+		},
+	}
+	s.eventPublisher.PublishOrderEventAsync(ctx, events.NewOrderEvent(events.OrderPaid, payload))
+}
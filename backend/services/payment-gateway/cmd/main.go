@@ -29,6 +29,7 @@ import (
 	"github.com/payment-platform/pkg/middleware"
 	"github.com/payment-platform/pkg/router"
 	"github.com/payment-platform/pkg/saga"
+	"github.com/payment-platform/pkg/sca"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -66,10 +67,15 @@ func main() {
 			&model.PaymentCallback{},
 			&model.PaymentRoute{},
 			&model.PreAuthPayment{},         // 预授权支付
+			&model.PreAuthCapture{},         // 预授权确认记录（多次确认）
 			&model.WebhookNotification{},    // Webhook 通知
 			&saga.Saga{},                    // Saga 分布式事务
 			&saga.SagaStep{},                // Saga 步骤
 			&exportpkg.ExportTask{},         // 数据导出任务
+			&model.Receipt{},                // 电子收据
+			&model.ConnectedAccount{},       // 平台子商户
+			&model.PaymentSplit{},           // 支付分账明细
+			&model.PaymentSplitReversal{},   // 分账冲正明细（退款/拒付）
 		},
 
 		// 启用企业级功能(gRPC 默认关闭,使用 HTTP/REST)
@@ -149,6 +155,8 @@ func main() {
 	apiKeyRepo := repository.NewAPIKeyRepository(application.DB)
 	preAuthRepo := repository.NewPreAuthRepository(application.DB)
 	webhookNotificationRepo := repository.NewWebhookNotificationRepository(application.DB)
+	receiptRepo := repository.NewReceiptRepository(application.DB)
+	marketplaceRepo := repository.NewMarketplaceRepository(application.DB)
 
 	// 4. 初始化微服务客户端
 	orderServiceURL := getConfig("ORDER_SERVICE_URL", "http://localhost:40004")
@@ -157,6 +165,7 @@ func main() {
 	notificationServiceURL := getConfig("NOTIFICATION_SERVICE_URL", "http://localhost:40008")
 	analyticsServiceURL := getConfig("ANALYTICS_SERVICE_URL", "http://localhost:40009")
 	merchantConfigServiceURL := getConfig("MERCHANT_CONFIG_SERVICE_URL", "http://localhost:40012")
+	cashierServiceURL := getConfig("CASHIER_SERVICE_URL", "http://localhost:40016")
 
 	orderClient := client.NewOrderClient(orderServiceURL)
 	channelClient := client.NewChannelClient(channelServiceURL)
@@ -164,6 +173,7 @@ func main() {
 	notificationClient := client.NewNotificationClient(notificationServiceURL)
 	analyticsClient := client.NewAnalyticsClient(analyticsServiceURL)
 	merchantConfigClient := client.NewMerchantConfigClient(merchantConfigServiceURL)
+	cashierClient := client.NewCashierClient(cashierServiceURL)
 
 	logger.Info(fmt.Sprintf("Order Service URL: %s", orderServiceURL))
 	logger.Info(fmt.Sprintf("Channel Service URL: %s", channelServiceURL))
@@ -171,6 +181,7 @@ func main() {
 	logger.Info(fmt.Sprintf("Notification Service URL: %s", notificationServiceURL))
 	logger.Info(fmt.Sprintf("Analytics Service URL: %s", analyticsServiceURL))
 	logger.Info(fmt.Sprintf("Merchant Config Service URL: %s", merchantConfigServiceURL))
+	logger.Info(fmt.Sprintf("Cashier Service URL: %s", cashierServiceURL))
 
 	// 5. 初始化Kafka Brokers（可选，如果未配置则为nil）
 	var kafkaBrokers []string
@@ -201,14 +212,12 @@ func main() {
 	)
 	logger.Info("Saga Orchestrator 初始化完成（带 Prometheus 指标）")
 
-	// 启动Saga恢复工作器（自动重试失败的Saga）
+	// Saga恢复工作器（自动续跑中断的Saga、重试失败的补偿），需在各 Saga 服务注册步骤定义后启动
 	recoveryWorker := saga.NewRecoveryWorker(
 		sagaOrchestrator,
 		5*time.Minute, // 每5分钟扫描一次失败的Saga
 		10,            // 每次处理10个失败Saga
 	)
-	go recoveryWorker.Start(context.Background())
-	logger.Info("Saga Recovery Worker 已启动")
 
 	// 初始化超时处理服务
 	timeoutService := service.NewTimeoutService(
@@ -254,6 +263,10 @@ func main() {
 	)
 	logger.Info("Callback Saga Service 初始化完成")
 
+	// 启动Saga恢复工作器（payment / refund / payment_callback 的步骤定义已注册）
+	go recoveryWorker.Start(context.Background())
+	logger.Info("Saga Recovery Worker 已启动", zap.Strings("business_types", sagaOrchestrator.Registry().BusinessTypes()))
+
 	// 7. Webhook基础URL配置（用于渠道回调）
 	webhookBaseURL := config.GetEnv("WEBHOOK_BASE_URL", "http://payment-gateway:40003")
 
@@ -286,18 +299,38 @@ func main() {
 
 	// 初始化智能路由服务
 	routerService := router.NewRouterService(application.Redis)
-	routingStrategyMode := config.GetEnv("ROUTING_STRATEGY", "balanced") // balanced, cost, success, geographic
+	routingStrategyMode := config.GetEnv("ROUTING_STRATEGY", "balanced") // balanced, cost, success, geographic, smart
+	// 智能路由评分权重（百分比）
+	routerService.SetScoringWeights(router.ScoringWeights{
+		SuccessRate: float64(config.GetEnvInt("ROUTING_WEIGHT_SUCCESS_RATE", 60)) / 100,
+		Latency:     float64(config.GetEnvInt("ROUTING_WEIGHT_LATENCY", 20)) / 100,
+		Cost:        float64(config.GetEnvInt("ROUTING_WEIGHT_COST", 20)) / 100,
+	})
+	// 数据库维护的商户/平台路由规则先于策略执行
+	routerService.SetRuleProvider(service.NewRouteRuleProvider(paymentRepo))
+	routingRuleService := service.NewRoutingRuleService(paymentRepo, nil)
 	if err := routerService.Initialize(context.Background(), routingStrategyMode); err != nil {
 		logger.Warn("智能路由服务初始化失败，将使用降级方案",
 			zap.Error(err),
 			zap.String("strategy_mode", routingStrategyMode))
 	} else {
+		routingRuleService = service.NewRoutingRuleService(paymentRepo, routerService)
+
 		// 注入到 Payment Service
 		if ps, ok := paymentService.(interface{ SetRouterService(*router.RouterService) }); ok {
 			ps.SetRouterService(routerService)
 			logger.Info("智能路由服务已注入到 PaymentService",
 				zap.String("strategy_mode", routingStrategyMode))
 		}
+		if ps, ok := paymentService.(interface{ SetCascadeConfig(router.CascadeConfig) }); ok {
+			cascade := router.DefaultCascadeConfig()
+			cascade.Enabled = config.GetEnvBool("ROUTING_CASCADE_ENABLED", cascade.Enabled)
+			cascade.MaxAttempts = config.GetEnvInt("ROUTING_CASCADE_MAX_ATTEMPTS", cascade.MaxAttempts)
+			ps.SetCascadeConfig(cascade)
+		}
+
+		// 定期将实时成功率/耗时回写渠道配置（多实例通过 Redis 共享）
+		go routerService.StartMetricsSync(context.Background(), time.Duration(config.GetEnvInt("ROUTING_METRICS_SYNC_SECONDS", 60))*time.Second)
 	}
 
 	// 8. 初始化导出服务和Handler
@@ -306,6 +339,63 @@ func main() {
 	exportHandler := handler.NewExportHandler(paymentExportService)
 	logger.Info(fmt.Sprintf("导出服务已初始化，存储目录: %s", exportStorageDir))
 
+	// 初始化电子收据服务（PDF 保存在导出存储中，邮件中的下载链接使用带过期时间的 HMAC 令牌签名）
+	// ⚠️ 安全要求: 收据链接使用独立密钥，不能复用 JWT_SECRET
+	receiptSigningKey := getConfig("RECEIPT_SIGNING_SECRET", "")
+	if receiptSigningKey == "" {
+		logger.Fatal("RECEIPT_SIGNING_SECRET environment variable is required and cannot be empty")
+	}
+	if len(receiptSigningKey) < 32 {
+		logger.Fatal("RECEIPT_SIGNING_SECRET must be at least 32 characters for security",
+			zap.Int("current_length", len(receiptSigningKey)),
+			zap.Int("minimum_length", 32))
+	}
+	if receiptSigningKey == getConfig("JWT_SECRET", "") {
+		logger.Fatal("RECEIPT_SIGNING_SECRET must not reuse JWT_SECRET")
+	}
+	receiptLinkTTL := time.Duration(config.GetEnvInt("RECEIPT_LINK_TTL_HOURS", 720)) * time.Hour
+	receiptService := service.NewReceiptService(
+		paymentRepo,
+		receiptRepo,
+		exportpkg.NewExportService(application.DB, application.Redis, exportStorageDir),
+		cashierClient,
+		getConfig("RECEIPT_PUBLIC_BASE_URL", "http://localhost:40003"),
+		receiptSigningKey,
+		receiptLinkTTL,
+	)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	if ps, ok := paymentService.(interface{ SetReceiptService(service.ReceiptService) }); ok {
+		ps.SetReceiptService(receiptService)
+		logger.Info("电子收据服务已注入到 PaymentService")
+	}
+
+	// 初始化平台分账服务（子商户分账、退款/拒付按比例冲正）
+	marketplaceService := service.NewMarketplaceService(paymentRepo, marketplaceRepo, eventPublisher)
+	marketplaceHandler := handler.NewMarketplaceHandler(marketplaceService)
+	if ps, ok := paymentService.(interface{ SetMarketplaceService(service.MarketplaceService) }); ok {
+		ps.SetMarketplaceService(marketplaceService)
+		logger.Info("平台分账服务已注入到 PaymentService")
+	}
+
+	// 3DS / SCA：商户在收银台启用 3DS 时按风险评分申请豁免或强制挑战
+	if ps, ok := paymentService.(interface{ SetCashierClient(client.CashierClient) }); ok {
+		ps.SetCashierClient(cashierClient)
+	}
+	// Webhook 负载版本：按商户固定的版本构造支付通知事件
+	if ps, ok := paymentService.(interface {
+		SetMerchantConfigClient(client.MerchantConfigClient)
+	}); ok {
+		ps.SetMerchantConfigClient(merchantConfigClient)
+	}
+	if ps, ok := paymentService.(interface {
+		SetSCAConfig(sca.Policy, string)
+	}); ok {
+		scaPolicy := sca.DefaultPolicy()
+		scaPolicy.TRAMaxScore = config.GetEnvInt("SCA_TRA_MAX_SCORE", scaPolicy.TRAMaxScore)
+		scaPolicy.ChallengeMinScore = config.GetEnvInt("SCA_CHALLENGE_MIN_SCORE", scaPolicy.ChallengeMinScore)
+		ps.SetSCAConfig(scaPolicy, config.GetEnv("SCA_RETURN_BASE_URL", ""))
+	}
+
 	// 初始化预授权服务
 	preAuthService := service.NewPreAuthService(
 		application.DB,
@@ -359,9 +449,30 @@ func main() {
 	}()
 	logger.Info(fmt.Sprintf("Webhook 重试工作器已启动，扫描间隔: %v", webhookRetryInterval))
 
+	// 启动分账事件重试工作器（分账明细加载或冲正失败而推迟发布的支付/退款事件）
+	if retrier, ok := paymentService.(interface {
+		RetryPendingSplitEvents(ctx context.Context) (int, error)
+	}); ok {
+		splitEventRetryInterval := time.Duration(config.GetEnvInt("SPLIT_EVENT_RETRY_INTERVAL", 60)) * time.Second
+		go func() {
+			ticker := time.NewTicker(splitEventRetryInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				count, err := retrier.RetryPendingSplitEvents(context.Background())
+				if err != nil {
+					logger.Error("分账事件重试任务失败", zap.Error(err))
+				} else if count > 0 {
+					logger.Info("分账事件重试任务完成", zap.Int("published_count", count))
+				}
+			}
+		}()
+		logger.Info(fmt.Sprintf("分账事件重试工作器已启动，扫描间隔: %v", splitEventRetryInterval))
+	}
+
 	// 9. 初始化Handler
 	paymentHandler := handler.NewPaymentHandler(paymentService)
 	preAuthHandler := handler.NewPreAuthHandler(preAuthService)
+	routingHandler := handler.NewRoutingHandler(routingRuleService)
 
 	// 10. 初始化签名验证中间件（渐进式迁移：支持本地验证和远程验证）
 	useAuthService := config.GetEnv("USE_AUTH_SERVICE", "false") == "true"
@@ -405,6 +516,29 @@ func main() {
 
 		// 设置API Key更新器（用于更新last_used_at）
 		signatureMW.SetAPIKeyUpdater(apiKeyRepo)
+
+		// 设置v3签名公钥查询（公钥由 merchant-auth-service 管理）
+		authServiceURL := config.GetEnv("MERCHANT_AUTH_SERVICE_URL", "http://localhost:40011")
+		authClient := client.NewMerchantAuthClient(authServiceURL)
+		signatureMW.SetPublicKeyLookup(func(keyID string) (*localMiddleware.PublicKeyData, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			key, err := authClient.GetPublicKey(ctx, keyID)
+			if err != nil {
+				return nil, err
+			}
+
+			return &localMiddleware.PublicKeyData{
+				KeyID:       key.KeyID,
+				MerchantID:  key.MerchantID,
+				Algorithm:   key.Algorithm,
+				PublicKey:   key.PublicKey,
+				Environment: key.Environment,
+				IsActive:    true, // merchant-auth-service 只返回启用中的公钥
+				ExpiresAt:   key.ExpiresAt,
+			}, nil
+		})
 		signatureMiddlewareFunc = signatureMW.Verify()
 	}
 
@@ -447,6 +581,36 @@ func main() {
 		webhooks.POST("/paypal", paymentHandler.HandlePayPalWebhook)
 	}
 
+	// 公开路由（通知邮件中的收据下载链接，使用链接令牌校验）
+	application.Router.GET("/api/v1/receipts/:type/:no", receiptHandler.DownloadPublicReceipt)
+
+	// 公开路由（消费者从渠道 3DS 验证页面返回，继续支付后跳转到商户 return_url）
+	application.Router.GET("/api/v1/payments/:paymentNo/3ds/return", paymentHandler.HandleThreeDSReturn)
+
+	// 服务间调用认证（/api/v1/service/*）：X-Service-Token 共享令牌，启用 mTLS 时也接受已验证的客户端证书
+	internalServiceToken := getConfig("INTERNAL_SERVICE_TOKEN", "")
+	if internalServiceToken == "" {
+		logger.Warn("INTERNAL_SERVICE_TOKEN 未配置，服务间调用路由仅接受 mTLS 客户端证书")
+	}
+	serviceAPI := func(allowedServices ...string) *gin.RouterGroup {
+		return application.Router.Group("/api/v1", middleware.ServiceAuthMiddleware(internalServiceToken, allowedServices...))
+	}
+
+	// 服务间调用路由（admin-bff 路由规则管理、路由解释与模拟）
+	routingHandler.RegisterServiceRoutes(serviceAPI("admin-bff-service"))
+
+	// 服务间调用路由（admin-bff Saga 查询与人工干预）
+	saga.NewAdminHandler(sagaOrchestrator, recoveryWorker).RegisterServiceRoutes(serviceAPI("admin-bff-service"))
+
+	// 服务间调用路由（dispute-service 拒付败诉后冲正分账）
+	marketplaceHandler.RegisterServiceRoutes(serviceAPI("dispute-service"))
+
+	// 服务间调用路由（order-service 退货审核通过后自动退款）
+	serviceAPI("order-service").POST("/service/refunds", paymentHandler.CreateRefund)
+
+	// 服务间调用路由（order-service 订单取消或超时后撤销组合支付中未完成的支付）
+	serviceAPI("order-service").POST("/service/payments/:paymentNo/cancel", paymentHandler.CancelPayment)
+
 	// 需要签名验证的路由（API Key认证 - 用于商户API调用）
 	api := application.Router.Group("/api/v1")
 	api.Use(signatureMiddlewareFunc)
@@ -458,6 +622,8 @@ func main() {
 			payments.GET("/:paymentNo", paymentHandler.GetPayment)
 			payments.GET("", paymentHandler.QueryPayments)
 			payments.POST("/:paymentNo/cancel", paymentHandler.CancelPayment)
+			payments.POST("/:paymentNo/3ds/complete", paymentHandler.CompleteAuthentication)
+			payments.GET("/:paymentNo/receipt", receiptHandler.DownloadPaymentReceipt)
 		}
 
 		// 退款管理
@@ -466,6 +632,7 @@ func main() {
 			refunds.POST("", paymentHandler.CreateRefund)
 			refunds.GET("/:refundNo", paymentHandler.GetRefund)
 			refunds.GET("", paymentHandler.QueryRefunds)
+			refunds.GET("/:refundNo/receipt", receiptHandler.DownloadRefundReceipt)
 		}
 	}
 
@@ -495,6 +662,8 @@ func main() {
 			merchantPayments.GET("", paymentHandler.QueryPayments)
 			merchantPayments.GET("/:paymentNo", paymentHandler.GetPayment)
 			merchantPayments.POST("/export", exportHandler.CreatePaymentExport) // 导出支付记录
+			merchantPayments.GET("/:paymentNo/receipt", receiptHandler.DownloadPaymentReceipt) // 下载支付收据
+			merchantPayments.GET("/:paymentNo/splits", marketplaceHandler.GetPaymentSplits)     // 查询分账明细
 			// 支付统计（暂时返回空数据，等待实现）
 			merchantPayments.GET("/stats", func(c *gin.Context) {
 				c.JSON(200, gin.H{
@@ -520,16 +689,28 @@ func main() {
 			preAuth.POST("", preAuthHandler.CreatePreAuth)                   // 创建预授权
 			preAuth.POST("/capture", preAuthHandler.CapturePreAuth)          // 确认预授权（扣款）
 			preAuth.POST("/cancel", preAuthHandler.CancelPreAuth)            // 取消预授权
+			preAuth.POST("/increment", preAuthHandler.IncrementPreAuth)      // 增量授权（追加冻结金额）
+			preAuth.POST("/reauthorize", preAuthHandler.ReauthorizePreAuth)  // 重新授权（过期前延长有效期）
 			preAuth.GET("/:pre_auth_no", preAuthHandler.GetPreAuth)          // 查询预授权详情
+			preAuth.GET("/:pre_auth_no/breakdown", preAuthHandler.GetPreAuthBreakdown) // 查询金额明细（可确认/已确认/已释放）
 			preAuth.GET("", preAuthHandler.ListPreAuths)                     // 查询预授权列表
 		}
 
+		// 平台子商户管理（分账收款方）
+		connectedAccounts := merchantAPI.Group("/connected-accounts")
+		{
+			connectedAccounts.POST("", marketplaceHandler.CreateConnectedAccount)                               // 添加子商户
+			connectedAccounts.GET("", marketplaceHandler.ListConnectedAccounts)                                 // 查询子商户列表
+			connectedAccounts.PUT("/:sub_merchant_id/status", marketplaceHandler.UpdateConnectedAccountStatus) // 启用/暂停子商户
+		}
+
 		// 商户后台退款查询
 		merchantRefunds := merchantAPI.Group("/refunds")
 		{
 			merchantRefunds.GET("", paymentHandler.QueryRefunds)
 			merchantRefunds.GET("/:refundNo", paymentHandler.GetRefund)
 			merchantRefunds.POST("/export", exportHandler.CreateRefundExport) // 导出退款记录
+			merchantRefunds.GET("/:refundNo/receipt", receiptHandler.DownloadRefundReceipt) // 下载退款凭证
 		}
 
 		// 导出任务管理
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MerchantBranding 商户品牌信息（收银台外观配置）
+type MerchantBranding struct {
+	BrandName        string   `json:"brand_name"`
+	ThemeColor       string   `json:"theme_color"`
+	LogoURL          string   `json:"logo_url"`
+	DefaultLanguage  string   `json:"default_language"`
+	EnabledLanguages []string `json:"enabled_languages"`
+}
+
+// SecuritySettings 商户支付安全设置
+type SecuritySettings struct {
+	Enable3DSecure bool `json:"enable_3d_secure"`
+	RequireCVV     bool `json:"require_cvv"`
+}
+
+// CashierClient 收银台服务客户端
+type CashierClient interface {
+	GetBranding(ctx context.Context, merchantID uuid.UUID) (*MerchantBranding, error)
+	GetSecuritySettings(ctx context.Context, merchantID uuid.UUID) (*SecuritySettings, error)
+}
+
+type cashierClient struct {
+	baseURL string
+	client  *httpclient.BreakerClient
+}
+
+// NewCashierClient 创建收银台服务客户端
+func NewCashierClient(baseURL string) CashierClient {
+	config := &httpclient.Config{
+		Timeout:       5 * time.Second,
+		MaxRetries:    2,
+		RetryDelay:    500 * time.Millisecond,
+		EnableLogging: true,
+	}
+
+	breakerConfig := httpclient.DefaultBreakerConfig("cashier-service")
+
+	return &cashierClient{
+		baseURL: baseURL,
+		client:  httpclient.NewBreakerClient(config, breakerConfig),
+	}
+}
+
+// GetBranding 获取商户品牌信息
+func (c *cashierClient) GetBranding(ctx context.Context, merchantID uuid.UUID) (*MerchantBranding, error) {
+	url := fmt.Sprintf("%s/api/v1/cashier/branding/%s", c.baseURL, merchantID.String())
+
+	req := &httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Ctx: ctx,
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("Failed to get merchant branding from cashier-service",
+			zap.Error(err),
+			zap.String("url", url),
+			zap.String("merchant_id", merchantID.String()))
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(resp.Body, &errResp)
+		return nil, fmt.Errorf("get merchant branding failed: %s (status %d)", errResp.Error, resp.StatusCode)
+	}
+
+	var result struct {
+		Code int               `json:"code"`
+		Data *MerchantBranding `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("get merchant branding failed: empty response")
+	}
+
+	return result.Data, nil
+}
+
+// GetSecuritySettings 获取商户支付安全设置（是否启用 3DS 等）
+func (c *cashierClient) GetSecuritySettings(ctx context.Context, merchantID uuid.UUID) (*SecuritySettings, error) {
+	url := fmt.Sprintf("%s/api/v1/cashier/security/%s", c.baseURL, merchantID.String())
+
+	req := &httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Ctx: ctx,
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("Failed to get merchant security settings from cashier-service",
+			zap.Error(err),
+			zap.String("url", url),
+			zap.String("merchant_id", merchantID.String()))
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(resp.Body, &errResp)
+		return nil, fmt.Errorf("get merchant security settings failed: %s (status %d)", errResp.Error, resp.StatusCode)
+	}
+
+	var result struct {
+		Code int               `json:"code"`
+		Data *SecuritySettings `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("get merchant security settings failed: empty response")
+	}
+
+	return result.Data, nil
+}
@@ -2,18 +2,30 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/sca"
 )
 
 // ChannelClient Channel服务客户端
 type ChannelClient struct {
 	*ServiceClient
+	serviceToken string // 服务间调用令牌（INTERNAL_SERVICE_TOKEN），访问 channel-adapter /api/v1/fx 路由
 }
 
 // NewChannelClient 创建Channel服务客户端（带熔断器）
 func NewChannelClient(baseURL string) *ChannelClient {
 	return &ChannelClient{
 		ServiceClient: NewServiceClientWithBreaker(baseURL, "channel-adapter"),
+		serviceToken:  os.Getenv("INTERNAL_SERVICE_TOKEN"),
 	}
 }
 
@@ -30,6 +42,7 @@ type CreatePaymentRequest struct {
 	Description   string                 `json:"description"`
 	ReturnURL     string                 `json:"return_url"`
 	NotifyURL     string                 `json:"notify_url"`
+	ThreeDS       *sca.Request           `json:"three_ds,omitempty"` // 3DS 要求
 	Extra         map[string]interface{} `json:"extra"`
 }
 
@@ -47,6 +60,8 @@ type PaymentResult struct {
 	PaymentURL     string                 `json:"payment_url"`      // 支付URL（跳转）
 	QRCodeURL      string                 `json:"qr_code_url"`      // 二维码URL
 	Status         string                 `json:"status"`           // 支付状态
+	NextAction     *sca.NextAction        `json:"next_action"`      // 3DS 挑战动作（status=requires_action 时）
+	Authentication *sca.Result            `json:"authentication"`   // 3DS 认证结果
 	Extra          map[string]interface{} `json:"extra"`            // 扩展信息
 }
 
@@ -88,6 +103,11 @@ func (c *ChannelClient) CreatePayment(ctx context.Context, req *CreatePaymentReq
 		return nil, fmt.Errorf("调用Channel服务失败: %w", err)
 	}
 
+	// 渠道拒绝时 channel-adapter 返回 402 和归一化的拒绝原因
+	if d, message, ok := parseDeclineResponse(resp); ok {
+		return nil, decline.Wrap(d, fmt.Errorf("创建支付失败: %s", message))
+	}
+
 	var result CreatePaymentResponse
 	if err := resp.ParseResponse(&result); err != nil {
 		return nil, err
@@ -100,6 +120,64 @@ func (c *ChannelClient) CreatePayment(ctx context.Context, req *CreatePaymentReq
 	return result.Data, nil
 }
 
+// AuthenticationResult 3DS 验证完成后的支付结果
+type AuthenticationResult struct {
+	PaymentNo      string           `json:"payment_no"`
+	ChannelTradeNo string           `json:"channel_trade_no"`
+	Status         string           `json:"status"`
+	NextAction     *sca.NextAction  `json:"next_action"`
+	Authentication *sca.Result      `json:"authentication"`
+	Decline        *decline.Decline `json:"decline"` // 认证失败或授权被拒绝时的归一化原因
+}
+
+// CompleteAuthentication 消费者完成 3DS 挑战后通知渠道继续支付
+func (c *ChannelClient) CompleteAuthentication(ctx context.Context, paymentNo string) (*AuthenticationResult, error) {
+	path := fmt.Sprintf("/api/v1/channel/payments/%s/authenticate", paymentNo)
+
+	resp, err := c.http.Post(ctx, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用Channel服务失败: %w", err)
+	}
+
+	if d, message, ok := parseDeclineResponse(resp); ok {
+		return nil, decline.Wrap(d, fmt.Errorf("3DS 验证后继续支付失败: %s", message))
+	}
+
+	var result struct {
+		Code    int                   `json:"code"`
+		Message string                `json:"message"`
+		Data    *AuthenticationResult `json:"data"`
+	}
+	if err := resp.ParseResponse(&result); err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, fmt.Errorf("3DS 验证后继续支付失败: %s", result.Message)
+	}
+
+	return result.Data, nil
+}
+
+// declineResponse channel-adapter 的渠道拒绝响应
+type declineResponse struct {
+	Code    string           `json:"code"`
+	Message string           `json:"message"`
+	Data    *decline.Decline `json:"data"`
+}
+
+// parseDeclineResponse 解析渠道拒绝响应，非拒绝响应返回 false
+func parseDeclineResponse(resp *Response) (*decline.Decline, string, bool) {
+	if resp.StatusCode != http.StatusPaymentRequired {
+		return nil, "", false
+	}
+	var result declineResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil || result.Code != errors.ErrCodePaymentDeclined || result.Data == nil {
+		return nil, "", false
+	}
+	return result.Data, result.Message, true
+}
+
 // CreateRefund 创建退款
 func (c *ChannelClient) CreateRefund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
 	resp, err := c.http.Post(ctx, "/api/v1/channel/refund", req, nil)
@@ -195,9 +273,11 @@ type PreAuthResult struct {
 // CapturePreAuthRequest 确认预授权请求
 type CapturePreAuthRequest struct {
 	PreAuthNo      string `json:"pre_auth_no"`
+	Channel        string `json:"channel"`
 	ChannelTradeNo string `json:"channel_trade_no"`
 	Amount         int64  `json:"amount"`
 	Currency       string `json:"currency"`
+	FinalCapture   *bool  `json:"final_capture"` // nil 视为最后一次确认
 }
 
 // CapturePreAuthResponse 确认预授权响应
@@ -209,8 +289,10 @@ type CapturePreAuthResponse struct {
 
 // CaptureResult 确认结果
 type CaptureResult struct {
-	PaymentTradeNo string `json:"payment_trade_no"` // 支付交易号
-	Status         string `json:"status"`           // 状态
+	PaymentTradeNo   string `json:"payment_trade_no"`  // 支付交易号
+	Status           string `json:"status"`            // 状态
+	CapturedAmount   int64  `json:"captured_amount"`   // 渠道累计已确认金额
+	CapturableAmount int64  `json:"capturable_amount"` // 渠道剩余可确认金额
 }
 
 // CancelPreAuthRequest 取消预授权请求
@@ -269,3 +351,159 @@ func (c *ChannelClient) CancelPreAuth(ctx context.Context, req *CancelPreAuthReq
 
 	return &result, nil
 }
+
+// IncrementPreAuthRequest 增量授权请求
+type IncrementPreAuthRequest struct {
+	PreAuthNo        string `json:"pre_auth_no"`
+	Channel          string `json:"channel"`
+	ChannelPreAuthNo string `json:"channel_pre_auth_no"`
+	Amount           int64  `json:"amount"` // 增量后的授权总金额
+	Currency         string `json:"currency"`
+}
+
+// IncrementPreAuthResponse 增量授权响应
+type IncrementPreAuthResponse struct {
+	Code    int                     `json:"code"`
+	Message string                  `json:"message"`
+	Data    *IncrementPreAuthResult `json:"data"`
+}
+
+// IncrementPreAuthResult 增量授权结果
+type IncrementPreAuthResult struct {
+	Status           string `json:"status"`            // 状态
+	Amount           int64  `json:"amount"`            // 渠道当前授权总金额
+	CapturableAmount int64  `json:"capturable_amount"` // 渠道剩余可确认金额
+}
+
+// ReauthorizePreAuthRequest 重新授权请求
+type ReauthorizePreAuthRequest struct {
+	PreAuthNo        string `json:"pre_auth_no"`
+	OrderNo          string `json:"order_no"`
+	Channel          string `json:"channel"`
+	ChannelPreAuthNo string `json:"channel_pre_auth_no"`
+	Amount           int64  `json:"amount"` // 重新冻结的金额
+	Currency         string `json:"currency"`
+	Description      string `json:"description"`
+}
+
+// ReauthorizePreAuthResponse 重新授权响应
+type ReauthorizePreAuthResponse struct {
+	Code    int                       `json:"code"`
+	Message string                    `json:"message"`
+	Data    *ReauthorizePreAuthResult `json:"data"`
+}
+
+// ReauthorizePreAuthResult 重新授权结果
+type ReauthorizePreAuthResult struct {
+	ChannelPreAuthNo         string `json:"channel_pre_auth_no"`          // 新的渠道预授权号
+	PreviousChannelPreAuthNo string `json:"previous_channel_pre_auth_no"` // 原渠道预授权号
+	Status                   string `json:"status"`                       // 状态
+	Amount                   int64  `json:"amount"`                       // 重新冻结的金额
+	ExpiresAt                *int64 `json:"expires_at"`                   // 新授权的过期时间（Unix时间戳）
+}
+
+// IncrementPreAuth 增量授权（提高冻结金额）
+func (c *ChannelClient) IncrementPreAuth(ctx context.Context, req *IncrementPreAuthRequest) (*IncrementPreAuthResponse, error) {
+	resp, err := c.http.Post(ctx, "/api/v1/channel/pre-auth/increment", req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用Channel服务增量授权失败: %w", err)
+	}
+
+	var result IncrementPreAuthResponse
+	if err := resp.ParseResponse(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ReauthorizePreAuth 重新授权（授权到期前重新冻结剩余金额）
+func (c *ChannelClient) ReauthorizePreAuth(ctx context.Context, req *ReauthorizePreAuthRequest) (*ReauthorizePreAuthResponse, error) {
+	resp, err := c.http.Post(ctx, "/api/v1/channel/pre-auth/reauthorize", req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用Channel服务重新授权失败: %w", err)
+	}
+
+	var result ReauthorizePreAuthResponse
+	if err := resp.ParseResponse(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FXQuote 换汇报价（channel-adapter 返回）
+type FXQuote struct {
+	QuoteID        string        `json:"quote_id"`
+	BaseCurrency   string        `json:"base_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	MidRate        money.Decimal `json:"mid_rate"`
+	MarkupBps      int           `json:"markup_bps"`
+	Rate           money.Decimal `json:"rate"`
+	SnapshotID     string        `json:"snapshot_id"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	Status         string        `json:"status"`
+	Reference      string        `json:"reference"`
+}
+
+// LockFXQuoteRequest 锁定换汇报价请求
+type LockFXQuoteRequest struct {
+	MerchantID   string `json:"merchant_id"`
+	Reference    string `json:"reference"`     // 支付流水号
+	BaseCurrency string `json:"base_currency"` // 支付币种，须与报价卖出币种一致
+}
+
+// ReleaseFXQuoteRequest 释放换汇报价请求
+type ReleaseFXQuoteRequest struct {
+	MerchantID string `json:"merchant_id"`
+	Reference  string `json:"reference"` // 锁定报价的支付流水号
+}
+
+// ReleaseFXQuote 释放支付锁定的换汇报价（支付未创建成功时调用）
+func (c *ChannelClient) ReleaseFXQuote(ctx context.Context, quoteID string, req *ReleaseFXQuoteRequest) error {
+	path := fmt.Sprintf("/api/v1/fx/quotes/%s/release", quoteID)
+
+	resp, err := c.http.Post(ctx, path, req, middleware.ServiceAuthHeaders("payment-gateway", c.serviceToken))
+	if err != nil {
+		return fmt.Errorf("调用Channel服务释放报价失败: %w", err)
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := resp.ParseResponse(&result); err != nil {
+		return err
+	}
+
+	if result.Code != 0 {
+		return fmt.Errorf("释放报价失败: %s", result.Message)
+	}
+
+	return nil
+}
+
+// LockFXQuote 将换汇报价锁定到支付，报价过期或已被使用时返回错误
+func (c *ChannelClient) LockFXQuote(ctx context.Context, quoteID string, req *LockFXQuoteRequest) (*FXQuote, error) {
+	path := fmt.Sprintf("/api/v1/fx/quotes/%s/lock", quoteID)
+
+	resp, err := c.http.Post(ctx, path, req, middleware.ServiceAuthHeaders("payment-gateway", c.serviceToken))
+	if err != nil {
+		return nil, fmt.Errorf("调用Channel服务锁定报价失败: %w", err)
+	}
+
+	var result struct {
+		Code    int      `json:"code"`
+		Message string   `json:"message"`
+		Data    *FXQuote `json:"data"`
+	}
+	if err := resp.ParseResponse(&result); err != nil {
+		return nil, err
+	}
+
+	if result.Code != 0 || result.Data == nil {
+		return nil, fmt.Errorf("锁定报价失败: %s", result.Message)
+	}
+
+	return result.Data, nil
+}
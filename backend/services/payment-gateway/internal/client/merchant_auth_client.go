@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	neturl "net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ import (
 // MerchantAuthClient 商户认证服务客户端
 type MerchantAuthClient interface {
 	ValidateSignature(ctx context.Context, apiKey, signature, payload string) (*ValidateSignatureResponse, error)
+	GetPublicKey(ctx context.Context, keyID string) (*PublicKeyResponse, error)
 }
 
 type merchantAuthClient struct {
@@ -111,3 +113,49 @@ func (c *merchantAuthClient) ValidateSignature(ctx context.Context, apiKey, sign
 		Environment: result.Environment,
 	}, nil
 }
+
+// PublicKeyResponse 商户签名公钥响应（v3签名）
+type PublicKeyResponse struct {
+	KeyID       string     `json:"key_id"`
+	MerchantID  uuid.UUID  `json:"merchant_id"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"public_key"`
+	Environment string     `json:"environment"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// GetPublicKey 根据公钥ID获取商户签名公钥
+func (c *merchantAuthClient) GetPublicKey(ctx context.Context, keyID string) (*PublicKeyResponse, error) {
+	url := c.baseURL + "/api/v1/auth/public-keys/" + neturl.PathEscape(keyID)
+	req := &httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Ctx:    ctx,
+	}
+
+	// 通过熔断器发送请求
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("Failed to get public key via merchant-auth-service",
+			zap.Error(err),
+			zap.String("url", url),
+			zap.String("circuit_breaker", "merchant-auth-service"),
+			zap.String("breaker_state", c.client.State().String()))
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(resp.Body, &errResp)
+		return nil, fmt.Errorf("public key lookup failed: %s (status %d)", errResp.Error, resp.StatusCode)
+	}
+
+	var result PublicKeyResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
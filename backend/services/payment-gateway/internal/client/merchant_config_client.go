@@ -15,6 +15,13 @@ import (
 // MerchantConfigClient 商户配置服务客户端
 type MerchantConfigClient interface {
 	GetWebhookSecret(ctx context.Context, merchantID uuid.UUID) (string, error)
+	GetWebhookConfig(ctx context.Context, merchantID uuid.UUID) (*WebhookConfig, error)
+}
+
+// WebhookConfig 商户 Webhook 配置
+type WebhookConfig struct {
+	Secret     string `json:"secret"`      // 签名密钥
+	APIVersion string `json:"api_version"` // 商户固定的负载版本（为空表示未固定）
 }
 
 type merchantConfigClient struct {
@@ -41,6 +48,15 @@ func NewMerchantConfigClient(baseURL string) MerchantConfigClient {
 
 // GetWebhookSecret 获取商户的Webhook密钥
 func (c *merchantConfigClient) GetWebhookSecret(ctx context.Context, merchantID uuid.UUID) (string, error) {
+	config, err := c.GetWebhookConfig(ctx, merchantID)
+	if err != nil {
+		return "", err
+	}
+	return config.Secret, nil
+}
+
+// GetWebhookConfig 获取商户的Webhook配置（密钥和固定的负载版本）
+func (c *merchantConfigClient) GetWebhookConfig(ctx context.Context, merchantID uuid.UUID) (*WebhookConfig, error) {
 	url := fmt.Sprintf("%s/api/v1/merchants/%s/webhook-secret", c.baseURL, merchantID.String())
 	
 	req := &httpclient.Request{
@@ -58,7 +74,7 @@ func (c *merchantConfigClient) GetWebhookSecret(ctx context.Context, merchantID
 			zap.Error(err),
 			zap.String("url", url),
 			zap.String("merchant_id", merchantID.String()))
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	if resp.StatusCode != 200 {
@@ -66,15 +82,13 @@ func (c *merchantConfigClient) GetWebhookSecret(ctx context.Context, merchantID
 			Error string `json:"error"`
 		}
 		json.Unmarshal(resp.Body, &errResp)
-		return "", fmt.Errorf("get webhook secret failed: %s (status %d)", errResp.Error, resp.StatusCode)
+		return nil, fmt.Errorf("get webhook secret failed: %s (status %d)", errResp.Error, resp.StatusCode)
 	}
 
-	var result struct {
-		Secret string `json:"secret"`
-	}
+	var result WebhookConfig
 	if err := json.Unmarshal(resp.Body, &result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Secret, nil
+	return &result, nil
 }
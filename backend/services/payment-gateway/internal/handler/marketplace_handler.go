@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"payment-platform/payment-gateway/internal/service"
+)
+
+// MarketplaceHandler 平台分账处理器（子商户管理、分账查询、拒付冲正）
+type MarketplaceHandler struct {
+	marketplaceService service.MarketplaceService
+}
+
+// NewMarketplaceHandler 创建平台分账处理器
+func NewMarketplaceHandler(marketplaceService service.MarketplaceService) *MarketplaceHandler {
+	return &MarketplaceHandler{marketplaceService: marketplaceService}
+}
+
+// RegisterServiceRoutes 注册服务间调用的路由（dispute-service 拒付败诉后冲正分账）
+func (h *MarketplaceHandler) RegisterServiceRoutes(r *gin.RouterGroup) {
+	r.POST("/service/payments/:paymentNo/split-reversals", h.ReverseDisputeSplits)
+}
+
+// UpdateConnectedAccountStatusRequest 更新子商户状态请求
+type UpdateConnectedAccountStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active suspended"`
+}
+
+// ReverseDisputeSplitsRequest 拒付冲正请求
+type ReverseDisputeSplitsRequest struct {
+	DisputeNo string `json:"dispute_no" binding:"required"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateConnectedAccount 添加子商户
+// @Summary 添加子商户
+// @Description 将已入驻的商户添加为平台商户的子商户，之后可在创建支付时作为分账收款方
+// @Tags 平台分账
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body service.ConnectedAccountInput true "添加子商户请求"
+// @Success 200 {object} SuccessResponse{data=model.ConnectedAccount}
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/merchant/connected-accounts [post]
+func (h *MarketplaceHandler) CreateConnectedAccount(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var input service.ConnectedAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		return
+	}
+
+	account, err := h.marketplaceService.CreateConnectedAccount(c.Request.Context(), merchantID, &input)
+	if err != nil {
+		c.JSON(h.errorStatus(err), ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(account))
+}
+
+// ListConnectedAccounts 查询子商户列表
+// @Summary 查询子商户列表
+// @Tags 平台分账
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} SuccessResponse{data=[]model.ConnectedAccount}
+// @Router /api/v1/merchant/connected-accounts [get]
+func (h *MarketplaceHandler) ListConnectedAccounts(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.marketplaceService.ListConnectedAccounts(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(accounts))
+}
+
+// UpdateConnectedAccountStatus 启用或暂停子商户
+// @Summary 启用或暂停子商户
+// @Description 暂停后子商户不能再作为新支付的分账收款方，已有分账的退款冲正不受影响
+// @Tags 平台分账
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param sub_merchant_id path string true "子商户ID"
+// @Param request body UpdateConnectedAccountStatusRequest true "状态"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/merchant/connected-accounts/{sub_merchant_id}/status [put]
+func (h *MarketplaceHandler) UpdateConnectedAccountStatus(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	subMerchantID, err := uuid.Parse(c.Param("sub_merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("无效的子商户ID"))
+		return
+	}
+
+	var req UpdateConnectedAccountStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.marketplaceService.UpdateConnectedAccountStatus(c.Request.Context(), merchantID, subMerchantID, req.Status); err != nil {
+		c.JSON(h.errorStatus(err), ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(nil))
+}
+
+// GetPaymentSplits 查询支付分账明细
+// @Summary 查询支付分账明细
+// @Description 返回各收款方的分账金额、已冲正金额以及退款/拒付的冲正记录
+// @Tags 平台分账
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param paymentNo path string true "支付流水号"
+// @Success 200 {object} SuccessResponse{data=service.PaymentSplitDetail}
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/merchant/payments/{paymentNo}/splits [get]
+func (h *MarketplaceHandler) GetPaymentSplits(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.marketplaceService.GetPaymentSplits(c.Request.Context(), merchantID, c.Param("paymentNo"))
+	if err != nil {
+		c.JSON(h.errorStatus(err), ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(detail))
+}
+
+// ReverseDisputeSplits 拒付败诉后按比例冲正分账
+//
+//	@Summary	拒付冲正分账
+//	@Tags		Marketplace
+//	@Accept		json
+//	@Produce	json
+//	@Param		paymentNo	path		string						true	"支付流水号"
+//	@Param		request		body		ReverseDisputeSplitsRequest	true	"拒付单号与金额"
+//	@Success	200			{object}	Response
+//	@Router		/service/payments/{paymentNo}/split-reversals [post]
+func (h *MarketplaceHandler) ReverseDisputeSplits(c *gin.Context) {
+	traceID := middleware.GetRequestID(c)
+
+	var req ReverseDisputeSplitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	postings, err := h.marketplaceService.ReverseDisputeSplits(c.Request.Context(), c.Param("paymentNo"), req.DisputeNo, req.Amount)
+	if err != nil {
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+			return
+		}
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "拒付冲正失败", err.Error()).WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp := errors.NewSuccessResponse(gin.H{"reversals": postings}).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// merchantID 从 JWT 中获取商户ID
+func (h *MarketplaceHandler) merchantID(c *gin.Context) (uuid.UUID, bool) {
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("未授权"))
+		return uuid.Nil, false
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("无效的商户ID"))
+		return uuid.Nil, false
+	}
+	return merchantID, true
+}
+
+// errorStatus 业务错误使用对应的 HTTP 状态码
+func (h *MarketplaceHandler) errorStatus(err error) int {
+	if bizErr, ok := errors.GetBusinessError(err); ok {
+		return errors.GetHTTPStatus(bizErr.Code)
+	}
+	return http.StatusInternalServerError
+}
@@ -2,16 +2,20 @@ package handler
 
 import (
 	"fmt"
+	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/decline"
 	"github.com/payment-platform/pkg/errors"
 	"github.com/payment-platform/pkg/logger"
 	"github.com/payment-platform/pkg/middleware"
 	"go.uber.org/zap"
+	"payment-platform/payment-gateway/internal/model"
 	"payment-platform/payment-gateway/internal/repository"
 	"payment-platform/payment-gateway/internal/service"
 )
@@ -40,8 +44,12 @@ func (h *PaymentHandler) RegisterRoutes(router *gin.Engine) {
 			payments.GET("", h.QueryPayments)
 			payments.POST("/batch", h.BatchGetPayments) // 批量查询支付
 			payments.POST("/:paymentNo/cancel", h.CancelPayment)
+			payments.POST("/:paymentNo/3ds/complete", h.CompleteAuthentication)
 		}
 
+		// 3DS 返回地址（消费者浏览器从渠道验证页面跳回，无签名）
+		v1.GET("/payments/:paymentNo/3ds/return", h.HandleThreeDSReturn)
+
 		// 退款管理（外部API，需要API Key）
 		refunds := v1.Group("/refunds")
 		{
@@ -105,6 +113,10 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		if bizErr, ok := errors.GetBusinessError(err); ok {
 			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
 			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else if d, ok := decline.FromError(err); ok {
+			// 渠道拒绝：返回归一化的拒绝原因，商户可按 message_key 展示文案、按 retryable 决定是否重试
+			resp := (&errors.Response{Code: errors.ErrCodePaymentDeclined, Message: err.Error(), Data: d}).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(errors.ErrCodePaymentDeclined), resp)
 		} else {
 			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "内部服务错误", err.Error()).
 				WithTraceID(traceID)
@@ -331,6 +343,130 @@ func (h *PaymentHandler) CancelPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// CompleteAuthentication 3DS 验证完成后继续支付
+//
+//	@Summary		3DS 验证完成后继续支付
+//	@Description	消费者在前端 SDK 中完成 3DS 挑战后调用，返回支付最新状态和认证结果
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			paymentNo	path		string	true	"支付流水号"
+//	@Success		200			{object}	Response
+//	@Failure		402			{object}	Response
+//	@Failure		500			{object}	Response
+//	@Router			/payments/{paymentNo}/3ds/complete [post]
+func (h *PaymentHandler) CompleteAuthentication(c *gin.Context) {
+	paymentNo := c.Param("paymentNo")
+
+	payment, err := h.paymentService.CompleteAuthentication(c.Request.Context(), paymentNo)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "3DS 验证后继续支付失败", err.Error()).
+				WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	traceID := middleware.GetRequestID(c)
+	if d := payment.Decline(); d != nil && payment.Status == model.PaymentStatusFailed {
+		// 认证失败或授权被拒绝，与创建支付时的渠道拒绝响应一致
+		resp := (&errors.Response{Code: errors.ErrCodePaymentDeclined, Message: payment.ErrorMsg, Data: d}).WithTraceID(traceID)
+		c.JSON(errors.GetHTTPStatus(errors.ErrCodePaymentDeclined), resp)
+		return
+	}
+	resp := errors.NewSuccessResponse(payment).WithTraceID(traceID)
+	c.JSON(http.StatusOK, resp)
+}
+
+// threeDSResultPage 3DS 返回后未配置商户返回地址时的结果页，仅展示支付流水号和状态
+var threeDSResultPage = template.Must(template.New("3ds_result").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+<p>支付流水号：{{.PaymentNo}}</p>
+</body>
+</html>
+`))
+
+// renderThreeDSResultPage 渲染 3DS 结果页
+func renderThreeDSResultPage(c *gin.Context, payment *model.Payment) {
+	title, message := "支付处理中", "支付结果确认中，请返回商户页面查看订单状态。"
+	switch payment.Status {
+	case model.PaymentStatusSuccess:
+		title, message = "支付成功", "您可以关闭此页面并返回商户页面。"
+	case model.PaymentStatusFailed, model.PaymentStatusCancelled, model.PaymentStatusExpired:
+		title, message = "支付未完成", "请返回商户页面重新发起支付。"
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := threeDSResultPage.Execute(c.Writer, map[string]string{
+		"Title":     title,
+		"Message":   message,
+		"PaymentNo": payment.PaymentNo,
+	}); err != nil {
+		logger.Error("渲染 3DS 结果页失败",
+			zap.String("payment_no", payment.PaymentNo),
+			zap.Error(err))
+	}
+}
+
+// HandleThreeDSReturn 消费者从渠道 3DS 验证页面返回，继续支付后跳转回商户页面
+//
+//	@Summary		3DS 返回地址
+//	@Description	渠道验证页面的返回地址，继续支付后 302 跳转到商户 return_url（附带 payment_no 和 status），未配置 return_url 时返回结果页
+//	@Tags			Payments
+//	@Param			paymentNo	path	string	true	"支付流水号"
+//	@Success		302
+//	@Router			/payments/{paymentNo}/3ds/return [get]
+func (h *PaymentHandler) HandleThreeDSReturn(c *gin.Context) {
+	paymentNo := c.Param("paymentNo")
+
+	payment, err := h.paymentService.CompleteAuthentication(c.Request.Context(), paymentNo)
+	if err != nil {
+		logger.Warn("3DS 返回后继续支付失败",
+			zap.String("payment_no", paymentNo),
+			zap.Error(err))
+		// 继续支付失败时仍跳转回商户页面，由商户查询支付状态
+		if payment, err = h.paymentService.GetPayment(c.Request.Context(), paymentNo); err != nil {
+			traceID := middleware.GetRequestID(c)
+			resp := errors.NewErrorResponse(errors.ErrCodeResourceNotFound, "支付记录不存在", err.Error()).
+				WithTraceID(traceID)
+			c.JSON(http.StatusNotFound, resp)
+			return
+		}
+	}
+
+	// 商户未提供返回地址时展示最简结果页（消费者浏览器访问，不返回支付详情 JSON）
+	if payment.ReturnURL == "" {
+		renderThreeDSResultPage(c, payment)
+		return
+	}
+
+	returnURL, err := url.Parse(payment.ReturnURL)
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "商户返回地址无效", err.Error()).
+			WithTraceID(traceID)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+	query := returnURL.Query()
+	query.Set("payment_no", payment.PaymentNo)
+	query.Set("status", payment.Status)
+	returnURL.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, returnURL.String())
+}
+
 // CreateRefund 创建退款
 //
 //	@Summary		创建退款
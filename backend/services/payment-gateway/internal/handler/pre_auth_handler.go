@@ -35,8 +35,20 @@ type CreatePreAuthRequest struct {
 
 // CapturePreAuthRequest 确认预授权请求
 type CapturePreAuthRequest struct {
+	PreAuthNo    string `json:"pre_auth_no" binding:"required"`
+	Amount       *int64 `json:"amount"`        // 可选，不传则确认全部剩余金额
+	FinalCapture *bool  `json:"final_capture"` // 可选，默认 true；false 时剩余金额继续冻结，可再次确认
+}
+
+// IncrementPreAuthRequest 增量授权请求
+type IncrementPreAuthRequest struct {
+	PreAuthNo string `json:"pre_auth_no" binding:"required"`
+	Amount    int64  `json:"amount" binding:"required,min=1"` // 追加冻结的金额（分）
+}
+
+// ReauthorizePreAuthRequest 重新授权请求
+type ReauthorizePreAuthRequest struct {
 	PreAuthNo string `json:"pre_auth_no" binding:"required"`
-	Amount    *int64 `json:"amount"` // 可选，不传则全额确认
 }
 
 // CancelPreAuthRequest 取消预授权请求
@@ -109,7 +121,7 @@ func (h *PreAuthHandler) CreatePreAuth(c *gin.Context) {
 
 // CapturePreAuth 确认预授权（扣款）
 // @Summary 确认预授权
-// @Description 确认预授权并扣款，可以部分确认或全额确认
+// @Description 确认预授权并扣款，可以部分确认或全额确认；final_capture=false 时可对剩余金额多次确认
 // @Tags 预授权
 // @Accept json
 // @Produce json
@@ -141,7 +153,7 @@ func (h *PreAuthHandler) CapturePreAuth(c *gin.Context) {
 	}
 
 	// 调用服务层
-	payment, err := h.preAuthService.CapturePreAuth(c.Request.Context(), merchantID, req.PreAuthNo, req.Amount)
+	payment, err := h.preAuthService.CapturePreAuth(c.Request.Context(), merchantID, req.PreAuthNo, req.Amount, req.FinalCapture)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 		return
@@ -150,6 +162,92 @@ func (h *PreAuthHandler) CapturePreAuth(c *gin.Context) {
 	c.JSON(http.StatusOK, SuccessResponse(payment))
 }
 
+// IncrementPreAuth 增量授权
+// @Summary 增量授权
+// @Description 在已授权（未确认）的预授权上追加冻结金额，如酒店加收押金、租车延期；发卡行可能拒绝
+// @Tags 预授权
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body IncrementPreAuthRequest true "增量授权请求"
+// @Success 200 {object} SuccessResponse{data=model.PreAuthPayment}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/merchant/pre-auth/increment [post]
+func (h *PreAuthHandler) IncrementPreAuth(c *gin.Context) {
+	// 获取商户ID
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("未授权"))
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("无效的商户ID"))
+		return
+	}
+
+	// 解析请求
+	var req IncrementPreAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		return
+	}
+
+	// 调用服务层
+	preAuth, err := h.preAuthService.IncrementPreAuth(c.Request.Context(), merchantID, req.PreAuthNo, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(preAuth))
+}
+
+// ReauthorizePreAuth 重新授权
+// @Summary 重新授权
+// @Description 授权过期前重新冻结剩余可确认金额并延长有效期，渠道交易号可能变化
+// @Tags 预授权
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param request body ReauthorizePreAuthRequest true "重新授权请求"
+// @Success 200 {object} SuccessResponse{data=model.PreAuthPayment}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/merchant/pre-auth/reauthorize [post]
+func (h *PreAuthHandler) ReauthorizePreAuth(c *gin.Context) {
+	// 获取商户ID
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("未授权"))
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("无效的商户ID"))
+		return
+	}
+
+	// 解析请求
+	var req ReauthorizePreAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		return
+	}
+
+	// 调用服务层
+	preAuth, err := h.preAuthService.ReauthorizePreAuth(c.Request.Context(), merchantID, req.PreAuthNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(preAuth))
+}
+
 // CancelPreAuth 取消预授权
 // @Summary 取消预授权
 // @Description 取消预授权，释放冻结的金额
@@ -242,6 +340,55 @@ func (h *PreAuthHandler) GetPreAuth(c *gin.Context) {
 	c.JSON(http.StatusOK, SuccessResponse(preAuth))
 }
 
+// GetPreAuthBreakdown 查询预授权金额明细
+// @Summary 查询预授权金额明细
+// @Description 返回授权金额的可确认/已确认/已释放明细及每次确认记录
+// @Tags 预授权
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param pre_auth_no path string true "预授权单号"
+// @Success 200 {object} SuccessResponse{data=model.PreAuthBreakdown}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/merchant/pre-auth/{pre_auth_no}/breakdown [get]
+func (h *PreAuthHandler) GetPreAuthBreakdown(c *gin.Context) {
+	// 获取商户ID
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("未授权"))
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("无效的商户ID"))
+		return
+	}
+
+	// 获取预授权单号
+	preAuthNo := c.Param("pre_auth_no")
+	if preAuthNo == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse("预授权单号不能为空"))
+		return
+	}
+
+	// 调用服务层
+	breakdown, err := h.preAuthService.GetPreAuthBreakdown(c.Request.Context(), merchantID, preAuthNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+		return
+	}
+
+	if breakdown == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse("预授权不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(breakdown))
+}
+
 // ListPreAuths 查询预授权列表
 // @Summary 查询预授权列表
 // @Description 查询商户的预授权列表，支持分页和状态筛选
@@ -249,7 +396,7 @@ func (h *PreAuthHandler) GetPreAuth(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
-// @Param status query string false "状态筛选: pending, authorized, captured, cancelled, expired"
+// @Param status query string false "状态筛选: pending, authorized, partially_captured, captured, cancelled, expired"
 // @Param page query int false "页码（默认1）"
 // @Param page_size query int false "每页数量（默认20，最大100）"
 // @Success 200 {object} SuccessResponse{data=[]model.PreAuthPayment}
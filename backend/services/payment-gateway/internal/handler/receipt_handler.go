@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"payment-platform/payment-gateway/internal/model"
+	"payment-platform/payment-gateway/internal/service"
+)
+
+// ReceiptHandler 电子收据处理器
+type ReceiptHandler struct {
+	receiptService service.ReceiptService
+}
+
+// NewReceiptHandler 创建电子收据处理器
+func NewReceiptHandler(receiptService service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// DownloadPaymentReceipt 下载支付收据
+//
+//	@Summary		下载支付收据
+//	@Description	下载支付成功的 PDF 收据，首次下载时生成
+//	@Tags			Receipts
+//	@Produce		application/pdf
+//	@Security		BearerAuth
+//	@Param			paymentNo	path		string	true	"支付流水号"
+//	@Param			merchant_id	query		string	false	"商户ID"
+//	@Param			lang		query		string	false	"语言（en, zh-CN, zh-TW, ja, ko），默认使用支付时的语言"
+//	@Success		200			{file}		binary
+//	@Failure		404			{object}	Response
+//	@Router			/payments/{paymentNo}/receipt [get]
+func (h *ReceiptHandler) DownloadPaymentReceipt(c *gin.Context) {
+	h.downloadForMerchant(c, model.ReceiptSourcePayment, c.Param("paymentNo"))
+}
+
+// DownloadRefundReceipt 下载退款凭证
+//
+//	@Summary		下载退款凭证
+//	@Description	下载退款成功的 PDF 退款凭证，首次下载时生成
+//	@Tags			Receipts
+//	@Produce		application/pdf
+//	@Security		BearerAuth
+//	@Param			refundNo	path		string	true	"退款单号"
+//	@Param			merchant_id	query		string	false	"商户ID"
+//	@Param			lang		query		string	false	"语言（en, zh-CN, zh-TW, ja, ko），默认使用支付时的语言"
+//	@Success		200			{file}		binary
+//	@Failure		404			{object}	Response
+//	@Router			/refunds/{refundNo}/receipt [get]
+func (h *ReceiptHandler) DownloadRefundReceipt(c *gin.Context) {
+	h.downloadForMerchant(c, model.ReceiptSourceRefund, c.Param("refundNo"))
+}
+
+// DownloadPublicReceipt 通过签名链接下载收据（通知邮件中的链接，无需登录）
+//
+//	@Summary		通过签名链接下载收据
+//	@Tags			Receipts
+//	@Produce		application/pdf
+//	@Param			type	path		string	true	"收据类型（payment, refund）"
+//	@Param			no		path		string	true	"支付流水号或退款单号"
+//	@Param			token	query		string	true	"链接令牌"
+//	@Param			lang	query		string	false	"语言"
+//	@Success		200		{file}		binary
+//	@Failure		404		{object}	Response
+//	@Router			/receipts/{type}/{no} [get]
+func (h *ReceiptHandler) DownloadPublicReceipt(c *gin.Context) {
+	sourceType, sourceNo := c.Param("type"), c.Param("no")
+	if !h.receiptService.VerifyReceiptToken(sourceType, sourceNo, c.Query("token")) {
+		// 令牌无效时不暴露单据是否存在
+		resp := errors.NewErrorResponse(errors.ErrCodeResourceNotFound, "收据不存在", "").
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusNotFound, resp)
+		return
+	}
+	h.download(c, uuid.Nil, sourceType, sourceNo)
+}
+
+func (h *ReceiptHandler) downloadForMerchant(c *gin.Context, sourceType, sourceNo string) {
+	merchantID := receiptMerchantID(c)
+	if merchantID == uuid.Nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeUnauthorized, "未授权", "").
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusUnauthorized, resp)
+		return
+	}
+	h.download(c, merchantID, sourceType, sourceNo)
+}
+
+// receiptMerchantID 获取商户ID：优先使用认证中间件写入的身份（签名或JWT），
+// 未经认证的内部调用（如 BFF）使用 query 参数
+func receiptMerchantID(c *gin.Context) uuid.UUID {
+	for _, key := range []string{"merchant_id", "user_id"} {
+		value, exists := c.Get(key)
+		if !exists {
+			continue
+		}
+		switch v := value.(type) {
+		case uuid.UUID:
+			return v
+		case string:
+			if merchantID, err := uuid.Parse(v); err == nil {
+				return merchantID
+			}
+		}
+	}
+	merchantID, _ := uuid.Parse(c.Query("merchant_id"))
+	return merchantID
+}
+
+func (h *ReceiptHandler) download(c *gin.Context, merchantID uuid.UUID, sourceType, sourceNo string) {
+	file, err := h.receiptService.GetReceipt(c.Request.Context(), merchantID, sourceType, sourceNo, c.Query("lang"))
+	if err != nil {
+		traceID := middleware.GetRequestID(c)
+		if bizErr, ok := errors.GetBusinessError(err); ok {
+			resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+			c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		} else {
+			resp := errors.NewErrorResponse(errors.ErrCodeInternalError, "生成收据失败", err.Error()).
+				WithTraceID(traceID)
+			c.JSON(http.StatusInternalServerError, resp)
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", file.FileName))
+	c.Data(http.StatusOK, "application/pdf", file.Content)
+}
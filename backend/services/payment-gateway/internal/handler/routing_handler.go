@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/middleware"
+	"github.com/payment-platform/pkg/router"
+	"payment-platform/payment-gateway/internal/service"
+)
+
+// RoutingHandler 路由规则管理、路由解释与模拟处理器
+type RoutingHandler struct {
+	routingService service.RoutingRuleService
+}
+
+// NewRoutingHandler 创建路由处理器
+func NewRoutingHandler(routingService service.RoutingRuleService) *RoutingHandler {
+	return &RoutingHandler{routingService: routingService}
+}
+
+// RegisterServiceRoutes 注册服务间调用的路由（由 admin-bff 完成认证与权限校验，生产环境通过 mTLS 保护）
+func (h *RoutingHandler) RegisterServiceRoutes(r *gin.RouterGroup) {
+	routing := r.Group("/service/routing")
+	{
+		routing.GET("/rules", h.ListRules)
+		routing.POST("/rules", h.CreateRule)
+		routing.PUT("/rules/:id", h.UpdateRule)
+		routing.DELETE("/rules/:id", h.DeleteRule)
+		routing.POST("/explain", h.Explain)
+		routing.POST("/simulate", h.Simulate)
+	}
+}
+
+// ListRules 查询路由规则
+//
+//	@Summary	查询路由规则
+//	@Tags		Routing
+//	@Produce	json
+//	@Param		merchant_id	query		string	false	"商户ID（为空返回全部规则）"
+//	@Success	200			{object}	Response
+//	@Router		/service/routing/rules [get]
+func (h *RoutingHandler) ListRules(c *gin.Context) {
+	var merchantID *uuid.UUID
+	if value := c.Query("merchant_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的商户ID", err.Error()).
+				WithTraceID(middleware.GetRequestID(c))
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		merchantID = &id
+	}
+
+	rules, err := h.routingService.ListRules(c.Request.Context(), merchantID)
+	if err != nil {
+		h.respondError(c, err, "查询路由规则失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(rules).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateRule 创建路由规则
+//
+//	@Summary	创建路由规则
+//	@Tags		Routing
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		service.RoutingRuleInput	true	"路由规则"
+//	@Success	200		{object}	Response
+//	@Router		/service/routing/rules [post]
+func (h *RoutingHandler) CreateRule(c *gin.Context) {
+	var input service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	rule, err := h.routingService.CreateRule(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, err, "创建路由规则失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(rule).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateRule 更新路由规则
+//
+//	@Summary	更新路由规则
+//	@Tags		Routing
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string						true	"规则ID"
+//	@Param		request	body		service.RoutingRuleInput	true	"路由规则"
+//	@Success	200		{object}	Response
+//	@Router		/service/routing/rules/{id} [put]
+func (h *RoutingHandler) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的规则ID", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	var input service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	rule, err := h.routingService.UpdateRule(c.Request.Context(), id, &input)
+	if err != nil {
+		h.respondError(c, err, "更新路由规则失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(rule).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRule 删除路由规则
+//
+//	@Summary	删除路由规则
+//	@Tags		Routing
+//	@Produce	json
+//	@Param		id	path		string	true	"规则ID"
+//	@Success	200	{object}	Response
+//	@Router		/service/routing/rules/{id} [delete]
+func (h *RoutingHandler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "无效的规则ID", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	if err := h.routingService.DeleteRule(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, "删除路由规则失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(nil).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// Explain 解释路由决策
+//
+//	@Summary		解释路由决策
+//	@Description	返回规则求值、候选渠道评估（实时成功率、耗时、手续费、综合评分）以及最终选择的渠道和策略
+//	@Tags			Routing
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		router.RoutingRequest	true	"路由请求"
+//	@Success		200		{object}	Response
+//	@Router			/service/routing/explain [post]
+func (h *RoutingHandler) Explain(c *gin.Context) {
+	var req router.RoutingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	explanation, err := h.routingService.Explain(c.Request.Context(), &req)
+	if err != nil {
+		h.respondError(c, err, "解释路由决策失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(explanation).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+// Simulate 路由模拟
+//
+//	@Summary		路由模拟（what-if）
+//	@Description	用时间范围内的历史支付回放路由决策，可传入候选规则，对比实际渠道、手续费与预期成功率
+//	@Tags			Routing
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		service.RoutingSimulationInput	true	"模拟参数"
+//	@Success		200		{object}	Response
+//	@Router			/service/routing/simulate [post]
+func (h *RoutingHandler) Simulate(c *gin.Context) {
+	var input service.RoutingSimulationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		resp := errors.NewErrorResponse(errors.ErrCodeInvalidRequest, "请求参数错误", err.Error()).
+			WithTraceID(middleware.GetRequestID(c))
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	report, err := h.routingService.Simulate(c.Request.Context(), &input)
+	if err != nil {
+		h.respondError(c, err, "路由模拟失败")
+		return
+	}
+
+	resp := errors.NewSuccessResponse(report).WithTraceID(middleware.GetRequestID(c))
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RoutingHandler) respondError(c *gin.Context, err error, message string) {
+	traceID := middleware.GetRequestID(c)
+	if bizErr, ok := errors.GetBusinessError(err); ok {
+		resp := errors.NewErrorResponseFromBusinessError(bizErr).WithTraceID(traceID)
+		c.JSON(errors.GetHTTPStatus(bizErr.Code), resp)
+		return
+	}
+	resp := errors.NewErrorResponse(errors.ErrCodeInternalError, message, err.Error()).WithTraceID(traceID)
+	c.JSON(http.StatusInternalServerError, resp)
+}
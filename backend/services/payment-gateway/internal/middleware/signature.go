@@ -43,6 +43,7 @@ type APIKeyUpdater interface {
 type SignatureMiddleware struct {
 	// 获取商户API Secret的函数（返回完整数据）
 	getAPISecret    func(apiKey string) (*APIKeyData, error)
+	getPublicKey    func(keyID string) (*PublicKeyData, error) // v3签名公钥查询（可选）
 	redis           *redis.Client
 	apiKeyUpdater   APIKeyUpdater // API Key更新器（用于更新last_used_at）
 	maxBodySize     int64         // 最大请求体大小（默认10MB）
@@ -76,7 +77,7 @@ func (m *SignatureMiddleware) SetAPIKeyUpdater(updater APIKeyUpdater) {
 // 4. API Key验证（活跃状态、过期时间）
 // 5. Nonce去重验证（防止重放攻击）
 // 6. 请求体大小限制（防止DoS）
-// 7. 签名验证（HMAC-SHA256）
+// 7. 签名验证（v1/v2: HMAC-SHA256，v3: Ed25519/RSA-PSS 公钥验签）
 // 8. 记录安全事件日志
 func (m *SignatureMiddleware) Verify() gin.HandlerFunc {
 	const authErrorMsg = "Authentication failed"
@@ -90,6 +91,7 @@ func (m *SignatureMiddleware) Verify() gin.HandlerFunc {
 		timestamp := c.GetHeader("X-Timestamp")
 		nonce := c.GetHeader("X-Nonce")
 		signatureVersion := c.GetHeader("X-Signature-Version") // 签名算法版本（可选，默认v1）
+		keyID := c.GetHeader("X-Key-Id")                       // 签名公钥ID（仅v3）
 		clientIP := c.ClientIP()
 
 		// 默认签名版本为v1（向后兼容）
@@ -249,27 +251,53 @@ func (m *SignatureMiddleware) Verify() gin.HandlerFunc {
 		// 重置请求体供后续处理使用
 		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
 
-		// 11. 计算并验证签名（使用常量时间比较）
-		// 根据签名版本使用不同的签名算法
-		expectedSignature := m.calculateSignatureByVersion(
-			signatureVersion,
-			keyData.Secret,
-			c.Request.Method,
-			c.Request.URL.Path,
-			timestamp,
-			nonce,
-			string(body),
-		)
-		if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-			logger.Warn("Signature verification failed",
-				zap.String("api_key", maskAPIKey(apiKey)),
-				zap.String("merchant_id", keyData.MerchantID.String()),
-				zap.String("signature_version", signatureVersion),
-				zap.Int64("failed_attempts", failedCount),
-				zap.String("client_ip", clientIP))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": authErrorMsg})
-			c.Abort()
-			return
+		// 11. 验证签名
+		if signatureVersion == "v3" {
+			// v3: 商户私钥签名，平台使用登记的公钥验签（平台不持有可伪造签名的密钥）
+			canonical := BuildCanonicalRequestV3(
+				c.Request.Method,
+				c.Request.URL.Path,
+				c.Request.URL.RawQuery,
+				body,
+				timestamp,
+				nonce,
+				keyID,
+			)
+			if err := m.verifySignatureV3(keyData.MerchantID, keyData.Environment, keyID, signature, canonical); err != nil {
+				logger.Warn("Signature verification failed",
+					zap.Error(err),
+					zap.String("api_key", maskAPIKey(apiKey)),
+					zap.String("merchant_id", keyData.MerchantID.String()),
+					zap.String("key_id", keyID),
+					zap.String("signature_version", signatureVersion),
+					zap.Int64("failed_attempts", failedCount),
+					zap.String("client_ip", clientIP))
+				c.JSON(http.StatusUnauthorized, gin.H{"error": authErrorMsg})
+				c.Abort()
+				return
+			}
+		} else {
+			// v1/v2: 计算HMAC并使用常量时间比较
+			expectedSignature := m.calculateSignatureByVersion(
+				signatureVersion,
+				keyData.Secret,
+				c.Request.Method,
+				c.Request.URL.Path,
+				timestamp,
+				nonce,
+				string(body),
+			)
+			if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+				logger.Warn("Signature verification failed",
+					zap.String("api_key", maskAPIKey(apiKey)),
+					zap.String("merchant_id", keyData.MerchantID.String()),
+					zap.String("signature_version", signatureVersion),
+					zap.Int64("failed_attempts", failedCount),
+					zap.String("client_ip", clientIP))
+				c.JSON(http.StatusUnauthorized, gin.H{"error": authErrorMsg})
+				c.Abort()
+				return
+			}
 		}
 
 		// 12. 签名验证成功 - 存储nonce（10分钟TTL，比时间戳窗口长）
@@ -307,6 +335,10 @@ func (m *SignatureMiddleware) Verify() gin.HandlerFunc {
 		c.Set("api_key", apiKey)
 		c.Set("merchant_id", keyData.MerchantID)
 		c.Set("environment", keyData.Environment)
+		c.Set("signature_version", signatureVersion)
+		if signatureVersion == "v3" {
+			c.Set("signing_key_id", keyID)
+		}
 
 		c.Next()
 	}
@@ -317,6 +349,7 @@ func isSupportedSignatureVersion(version string) bool {
 	supportedVersions := map[string]bool{
 		"v1": true, // 原始版本: timestamp + nonce + body
 		"v2": true, // 增强版本: method + path + timestamp + nonce + body
+		"v3": true, // 非对称签名: Ed25519/RSA-PSS 对规范请求串签名（见 BuildCanonicalRequestV3）
 	}
 	return supportedVersions[version]
 }
@@ -340,6 +373,9 @@ func (m *SignatureMiddleware) calculateSignatureByVersion(
 	case "v2":
 		// v2: 增强安全性，包含 HTTP method + path
 		signString = method + path + timestamp + nonce + body
+	case "v3":
+		// v3: 非对称签名，不使用共享密钥，由 verifySignatureV3 验证
+		return ""
 	default:
 		// 不支持的版本，返回空字符串（验证会失败）
 		if logger.Log != nil {
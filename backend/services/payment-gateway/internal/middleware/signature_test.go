@@ -1,10 +1,18 @@
 package middleware
 
 import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	pkgcrypto "github.com/payment-platform/pkg/crypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSignatureVersionSupport 测试签名版本支持
@@ -16,7 +24,8 @@ func TestSignatureVersionSupport(t *testing.T) {
 	}{
 		{"v1 should be supported", "v1", true},
 		{"v2 should be supported", "v2", true},
-		{"v3 should not be supported", "v3", false},
+		{"v3 should be supported", "v3", true},
+		{"v4 should not be supported", "v4", false},
 		{"empty should be supported (defaults to v1)", "", true},
 	}
 
@@ -161,6 +170,120 @@ func TestSignRequestV2(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// signatureV3Vector v3签名测试向量（testdata/signature_v3_vectors.json，供SDK作者对照实现）
+type signatureV3Vector struct {
+	Name             string `json:"name"`
+	Algorithm        string `json:"algorithm"`
+	PrivateKey       string `json:"private_key"`
+	PublicKey        string `json:"public_key"`
+	APIKey           string `json:"api_key"`
+	KeyID            string `json:"key_id"`
+	Method           string `json:"method"`
+	Path             string `json:"path"`
+	Query            string `json:"query"`
+	Body             string `json:"body"`
+	Timestamp        string `json:"timestamp"`
+	Nonce            string `json:"nonce"`
+	CanonicalRequest string `json:"canonical_request"`
+	Signature        string `json:"signature"`
+	Deterministic    bool   `json:"deterministic"` // Ed25519签名是确定性的，RSA-PSS每次签名结果不同
+}
+
+// TestSignatureV3Vectors 测试v3签名测试向量
+func TestSignatureV3Vectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/signature_v3_vectors.json")
+	require.NoError(t, err)
+
+	var vectors []signatureV3Vector
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	require.NotEmpty(t, vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			canonical := BuildCanonicalRequestV3(v.Method, v.Path, v.Query, []byte(v.Body), v.Timestamp, v.Nonce, v.KeyID)
+			assert.Equal(t, v.CanonicalRequest, canonical)
+
+			err := pkgcrypto.VerifyAsymmetricSignature(v.Algorithm, v.PublicKey, []byte(canonical), v.Signature)
+			assert.NoError(t, err)
+
+			if v.Deterministic {
+				block, _ := pem.Decode([]byte(v.PrivateKey))
+				require.NotNil(t, block)
+				priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				require.NoError(t, err)
+
+				sig, err := pkgcrypto.SignAsymmetric(v.Algorithm, priv.(ed25519.PrivateKey), []byte(canonical))
+				require.NoError(t, err)
+				assert.Equal(t, v.Signature, sig)
+			}
+		})
+	}
+}
+
+// TestVerifySignatureV3 测试v3验签流程（公钥归属、状态、篡改）
+func TestVerifySignatureV3(t *testing.T) {
+	data, err := os.ReadFile("testdata/signature_v3_vectors.json")
+	require.NoError(t, err)
+	var vectors []signatureV3Vector
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	v := vectors[0]
+
+	merchantID := uuid.New()
+	keyData := &PublicKeyData{
+		KeyID:       v.KeyID,
+		MerchantID:  merchantID,
+		Algorithm:   v.Algorithm,
+		PublicKey:   v.PublicKey,
+		Environment: "test",
+		IsActive:    true,
+	}
+	middleware := &SignatureMiddleware{}
+	middleware.SetPublicKeyLookup(func(keyID string) (*PublicKeyData, error) {
+		return keyData, nil
+	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, middleware.verifySignatureV3(merchantID, "test", v.KeyID, v.Signature, v.CanonicalRequest))
+	})
+
+	t.Run("tampered body rejected", func(t *testing.T) {
+		canonical := BuildCanonicalRequestV3(v.Method, v.Path, v.Query, []byte(`{"amount":1}`), v.Timestamp, v.Nonce, v.KeyID)
+		assert.Error(t, middleware.verifySignatureV3(merchantID, "test", v.KeyID, v.Signature, canonical))
+	})
+
+	t.Run("key of another merchant rejected", func(t *testing.T) {
+		assert.Error(t, middleware.verifySignatureV3(uuid.New(), "test", v.KeyID, v.Signature, v.CanonicalRequest))
+	})
+
+	t.Run("missing key id rejected", func(t *testing.T) {
+		assert.Error(t, middleware.verifySignatureV3(merchantID, "test", "", v.Signature, v.CanonicalRequest))
+	})
+
+	t.Run("expired key rejected", func(t *testing.T) {
+		expired := time.Now().Add(-time.Hour)
+		keyData.ExpiresAt = &expired
+		defer func() { keyData.ExpiresAt = nil }()
+		assert.Error(t, middleware.verifySignatureV3(merchantID, "test", v.KeyID, v.Signature, v.CanonicalRequest))
+	})
+
+	t.Run("environment mismatch rejected", func(t *testing.T) {
+		assert.Error(t, middleware.verifySignatureV3(merchantID, "production", v.KeyID, v.Signature, v.CanonicalRequest))
+	})
+
+	t.Run("lookup not configured rejected", func(t *testing.T) {
+		assert.Error(t, (&SignatureMiddleware{}).verifySignatureV3(merchantID, "test", v.KeyID, v.Signature, v.CanonicalRequest))
+	})
+}
+
+// TestCanonicalQueryString 测试规范查询串
+func TestCanonicalQueryString(t *testing.T) {
+	assert.Equal(t, "", canonicalQueryString(""))
+	assert.Equal(t, "a=1&b=2", canonicalQueryString("b=2&a=1"))
+	assert.Equal(t, "a=1&a=2", canonicalQueryString("a=2&a=1"))
+	assert.Equal(t, "q=a%20b", canonicalQueryString("q=a+b"))
+	assert.Equal(t, "q=a%20b", canonicalQueryString("q=a%20b"))
+}
+
 // TestTimestampValidation 测试时间戳验证
 func TestTimestampValidation(t *testing.T) {
 	middleware := &SignatureMiddleware{
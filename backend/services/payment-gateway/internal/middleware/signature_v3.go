@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgcrypto "github.com/payment-platform/pkg/crypto"
+)
+
+// SignatureV3Prefix v3 规范请求串的首行，用于区分签名方案，防止跨协议复用签名
+const SignatureV3Prefix = "PAYMENT-SIGNATURE-V3"
+
+// PublicKeyData 商户签名公钥数据（v3 非对称签名）
+type PublicKeyData struct {
+	KeyID       string
+	MerchantID  uuid.UUID
+	Algorithm   string // ed25519, rsa-pss-sha256
+	PublicKey   string // PEM格式公钥
+	Environment string // test, production（必须与 X-API-Key 的环境一致）
+	IsActive    bool
+	ExpiresAt   *time.Time
+}
+
+// SetPublicKeyLookup 设置 v3 签名公钥查询函数（可选，未设置时 v3 请求一律拒绝）
+func (m *SignatureMiddleware) SetPublicKeyLookup(getPublicKey func(keyID string) (*PublicKeyData, error)) {
+	m.getPublicKey = getPublicKey
+}
+
+// BuildCanonicalRequestV3 构建 v3 规范请求串
+//
+// 格式（各字段以 \n 分隔，末尾无换行）：
+//
+//	PAYMENT-SIGNATURE-V3
+//	<HTTP方法，大写>
+//	<请求路径，不含查询串>
+//	<规范查询串>
+//	<请求体SHA-256，小写十六进制>
+//	<X-Timestamp>
+//	<X-Nonce>
+//	<X-Key-Id>
+//
+// 规范查询串：参数按名称排序，同名参数按值排序，名称和值按 RFC 3986 编码（空格编码为 %20），
+// 以 & 连接；无查询参数时为空字符串
+func BuildCanonicalRequestV3(method, path, rawQuery string, body []byte, timestamp, nonce, keyID string) string {
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		SignatureV3Prefix,
+		strings.ToUpper(method),
+		path,
+		canonicalQueryString(rawQuery),
+		hex.EncodeToString(bodyHash[:]),
+		timestamp,
+		nonce,
+		keyID,
+	}, "\n")
+}
+
+// canonicalQueryString 构建规范查询串
+func canonicalQueryString(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// 无法解析的查询串按原样参与签名（验签会因双方不一致而失败）
+		return rawQuery
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(values))
+	for _, k := range keys {
+		vs := values[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, rfc3986Escape(k)+"="+rfc3986Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// rfc3986Escape 按 RFC 3986 编码（url.QueryEscape 会把空格编码为 +）
+func rfc3986Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// verifySignatureV3 验证 v3 非对称签名
+//
+// environment 为 X-API-Key 所属环境，公钥环境必须与之一致，防止测试公钥用于生产请求（或反之）
+func (m *SignatureMiddleware) verifySignatureV3(merchantID uuid.UUID, environment, keyID, signature, canonical string) error {
+	if m.getPublicKey == nil {
+		return errors.New("public key lookup not configured")
+	}
+	if keyID == "" {
+		return errors.New("missing key id")
+	}
+
+	keyData, err := m.getPublicKey(keyID)
+	if err != nil {
+		return fmt.Errorf("public key lookup failed: %w", err)
+	}
+
+	// 公钥必须属于 X-API-Key 对应的商户，防止用其他商户的公钥冒签
+	if keyData.MerchantID != merchantID {
+		return errors.New("public key does not belong to merchant")
+	}
+	if keyData.Environment != environment {
+		return fmt.Errorf("public key environment %q does not match api key environment %q", keyData.Environment, environment)
+	}
+	if !keyData.IsActive {
+		return errors.New("public key inactive")
+	}
+	if keyData.ExpiresAt != nil && time.Now().After(*keyData.ExpiresAt) {
+		return errors.New("public key expired")
+	}
+
+	return pkgcrypto.VerifyAsymmetricSignature(keyData.Algorithm, keyData.PublicKey, []byte(canonical), signature)
+}
+
+// SignRequestV3 为请求签名（v3版本，供SDK使用）
+// privateKey 为 ed25519.PrivateKey 或 *rsa.PrivateKey，algorithm 需与之匹配
+func SignRequestV3(apiKey, keyID, algorithm string, privateKey crypto.Signer, method, path, rawQuery string, body []byte) (map[string]string, error) {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	nonce := generateNonce()
+
+	canonical := BuildCanonicalRequestV3(method, path, rawQuery, body, timestamp, nonce, keyID)
+	signature, err := pkgcrypto.SignAsymmetric(algorithm, privateKey, []byte(canonical))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"X-API-Key":           apiKey,
+		"X-Key-Id":            keyID,
+		"X-Signature":         signature,
+		"X-Timestamp":         timestamp,
+		"X-Nonce":             nonce,
+		"X-Signature-Version": "v3",
+	}, nil
+}
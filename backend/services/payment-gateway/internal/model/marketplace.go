@@ -0,0 +1,211 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConnectedAccount 平台商户下挂的子商户（分账收款方）
+type ConnectedAccount struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PlatformMerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_connected_account" json:"platform_merchant_id"`  // 平台商户ID
+	SubMerchantID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_connected_account;index" json:"sub_merchant_id"` // 子商户ID（独立结算的商户）
+	Name               string    `gorm:"type:varchar(100)" json:"name"`                                                     // 子商户展示名称
+	Status             string    `gorm:"type:varchar(20);not null;default:'active'" json:"status"`                          // active, suspended
+	CreatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ConnectedAccount) TableName() string {
+	return "connected_accounts"
+}
+
+// PaymentSplit 支付分账明细（每个收款方一条，平台自身的分成也记为一条）
+type PaymentSplit struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentNo          string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_payment_split_recipient" json:"payment_no"`
+	PlatformMerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"platform_merchant_id"`
+	RecipientID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_split_recipient;index" json:"recipient_id"` // 收款方商户ID
+	Role               string    `gorm:"type:varchar(20);not null" json:"role"`                                                // platform, sub_merchant
+	RuleType           string    `gorm:"type:varchar(20);not null" json:"rule_type"`                                           // fixed, percentage, remainder
+	RuleValue          int64     `gorm:"type:bigint;default:0" json:"rule_value"`                                              // fixed 为金额（分），percentage 为万分比，平台为平台费
+	Amount             int64     `gorm:"type:bigint;not null" json:"amount"`                                                   // 分账金额（分）
+	ReversedAmount     int64     `gorm:"type:bigint;not null;default:0" json:"reversed_amount"`                                // 已冲正金额（退款/拒付）
+	Currency           string    `gorm:"type:varchar(10);not null" json:"currency"`
+	CreatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"type:timestamptz;default:now()" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PaymentSplit) TableName() string {
+	return "payment_splits"
+}
+
+// RemainingAmount 尚未冲正的分账金额
+func (s *PaymentSplit) RemainingAmount() int64 {
+	return s.Amount - s.ReversedAmount
+}
+
+// PaymentSplitReversal 分账冲正明细（退款或拒付按原分账比例冲回各收款方）
+type PaymentSplitReversal struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentNo     string    `gorm:"type:varchar(64);not null;index" json:"payment_no"`
+	ReferenceType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_split_reversal_ref" json:"reference_type"` // refund, dispute
+	ReferenceNo   string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_split_reversal_ref" json:"reference_no"`   // 退款单号或拒付单号
+	RecipientID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_split_reversal_ref" json:"recipient_id"`
+	Role          string    `gorm:"type:varchar(20);not null" json:"role"`
+	Amount        int64     `gorm:"type:bigint;not null" json:"amount"`
+	Currency      string    `gorm:"type:varchar(10);not null" json:"currency"`
+	CreatedAt     time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PaymentSplitReversal) TableName() string {
+	return "payment_split_reversals"
+}
+
+// 子商户状态
+const (
+	ConnectedAccountStatusActive    = "active"
+	ConnectedAccountStatusSuspended = "suspended"
+)
+
+// 分账收款方角色
+const (
+	SplitRolePlatform    = "platform"
+	SplitRoleSubMerchant = "sub_merchant"
+)
+
+// 分账规则类型
+const (
+	SplitRuleFixed      = "fixed"      // 固定金额（分）
+	SplitRulePercentage = "percentage" // 按比例（万分比）
+	SplitRuleRemainder  = "remainder"  // 剩余金额（平台）
+)
+
+// 分账冲正来源
+const (
+	SplitReversalRefund  = "refund"
+	SplitReversalDispute = "dispute"
+)
+
+// SplitRule 单个子商户的分账规则
+type SplitRule struct {
+	RecipientID uuid.UUID
+	Type        string
+	Value       int64
+}
+
+// AllocateSplits 按分账规则计算各收款方金额
+//
+// 按比例分账向下取整，分配剩余的金额（含取整差额）归平台商户，且不得低于平台费。
+func AllocateSplits(platformID uuid.UUID, amount int64, currency string, rules []SplitRule, platformFee int64) ([]*PaymentSplit, error) {
+	if platformFee < 0 {
+		return nil, fmt.Errorf("平台费不能为负数")
+	}
+
+	splits := make([]*PaymentSplit, 0, len(rules)+1)
+	seen := make(map[uuid.UUID]bool, len(rules))
+	allocated := int64(0)
+	for _, rule := range rules {
+		if rule.RecipientID == platformID {
+			return nil, fmt.Errorf("分账收款方不能是平台商户本身")
+		}
+		if seen[rule.RecipientID] {
+			return nil, fmt.Errorf("分账收款方重复: %s", rule.RecipientID)
+		}
+		seen[rule.RecipientID] = true
+
+		var share int64
+		switch rule.Type {
+		case SplitRuleFixed:
+			if rule.Value <= 0 {
+				return nil, fmt.Errorf("固定分账金额必须大于0")
+			}
+			share = rule.Value
+		case SplitRulePercentage:
+			if rule.Value <= 0 || rule.Value > 10000 {
+				return nil, fmt.Errorf("分账比例必须在 1-10000 万分比之间")
+			}
+			share = amount * rule.Value / 10000
+		default:
+			return nil, fmt.Errorf("不支持的分账规则类型: %s", rule.Type)
+		}
+
+		allocated += share
+		splits = append(splits, &PaymentSplit{
+			PlatformMerchantID: platformID,
+			RecipientID:        rule.RecipientID,
+			Role:               SplitRoleSubMerchant,
+			RuleType:           rule.Type,
+			RuleValue:          rule.Value,
+			Amount:             share,
+			Currency:           currency,
+		})
+	}
+
+	remainder := amount - allocated
+	if remainder < platformFee {
+		return nil, fmt.Errorf("分账金额超出可分配金额: 支付金额 %d，子商户分账 %d，平台费 %d", amount, allocated, platformFee)
+	}
+
+	splits = append(splits, &PaymentSplit{
+		PlatformMerchantID: platformID,
+		RecipientID:        platformID,
+		Role:               SplitRolePlatform,
+		RuleType:           SplitRuleRemainder,
+		RuleValue:          platformFee,
+		Amount:             remainder,
+		Currency:           currency,
+	})
+	return splits, nil
+}
+
+// AllocateReversal 按各收款方剩余分账金额的比例分摊冲正金额（最大余数法，保证合计等于冲正金额）
+//
+// 返回与 splits 一一对应的冲正金额；冲正金额超过剩余分账合计时返回错误。
+func AllocateReversal(splits []*PaymentSplit, amount int64) ([]int64, error) {
+	total := int64(0)
+	for _, split := range splits {
+		total += split.RemainingAmount()
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("冲正金额必须大于0")
+	}
+	if amount > total {
+		return nil, fmt.Errorf("冲正金额 %d 超过剩余分账金额 %d", amount, total)
+	}
+
+	shares := make([]int64, len(splits))
+	remainders := make([]int64, len(splits))
+	assigned := int64(0)
+	for i, split := range splits {
+		remaining := split.RemainingAmount()
+		shares[i] = amount * remaining / total
+		remainders[i] = amount * remaining % total
+		assigned += shares[i]
+	}
+
+	// 取整差额按余数从大到小逐分分配，余数相同时按原顺序
+	order := make([]int, len(splits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for _, i := range order {
+		if assigned == amount {
+			break
+		}
+		if shares[i] < splits[i].RemainingAmount() {
+			shares[i]++
+			assigned++
+		}
+	}
+	return shares, nil
+}
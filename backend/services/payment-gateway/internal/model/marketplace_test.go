@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateSplitsRemainderGoesToPlatform(t *testing.T) {
+	platform := uuid.New()
+	sellerA, sellerB := uuid.New(), uuid.New()
+
+	splits, err := AllocateSplits(platform, 10001, "USD", []SplitRule{
+		{RecipientID: sellerA, Type: SplitRulePercentage, Value: 3333},
+		{RecipientID: sellerB, Type: SplitRuleFixed, Value: 5000},
+	}, 1000)
+	require.NoError(t, err)
+	require.Len(t, splits, 3)
+
+	assert.Equal(t, int64(3333), splits[0].Amount) // 10001 * 33.33% 向下取整
+	assert.Equal(t, int64(5000), splits[1].Amount)
+	assert.Equal(t, SplitRolePlatform, splits[2].Role)
+	assert.Equal(t, int64(1668), splits[2].Amount)
+
+	// 子商户分账挤占平台费时拒绝
+	_, err = AllocateSplits(platform, 10000, "USD", []SplitRule{
+		{RecipientID: sellerA, Type: SplitRuleFixed, Value: 9500},
+	}, 1000)
+	assert.Error(t, err)
+}
+
+func TestAllocateReversalIsProportionalAndExact(t *testing.T) {
+	splits := []*PaymentSplit{
+		{Amount: 3333},
+		{Amount: 5000},
+		{Amount: 1668},
+	}
+
+	shares, err := AllocateReversal(splits, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{333, 500, 167}, shares)
+
+	// 部分冲正后按剩余金额分摊，全部冲正时与剩余金额一致
+	for i, share := range shares {
+		splits[i].ReversedAmount = share
+	}
+	shares, err = AllocateReversal(splits, 9001)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3000, 4500, 1501}, shares)
+
+	_, err = AllocateReversal(splits, 9002)
+	assert.Error(t, err)
+}
@@ -1,9 +1,14 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/decline"
+	"github.com/payment-platform/pkg/money"
+	"github.com/payment-platform/pkg/sca"
 	"gorm.io/gorm"
 )
 
@@ -23,6 +28,8 @@ type Payment struct {
 	CustomerName    string         `gorm:"type:varchar(100)" json:"customer_name"`                  // 客户姓名
 	CustomerPhone   string         `gorm:"type:varchar(20)" json:"customer_phone"`                  // 客户手机
 	CustomerIP      string         `gorm:"type:varchar(50)" json:"customer_ip"`                     // 客户IP
+	Country         string         `gorm:"type:varchar(2);index" json:"country,omitempty"`          // 客户国家/地区（GeoIP，ISO 3166-1）
+	ChannelLatencyMs int64         `gorm:"type:bigint;default:0" json:"channel_latency_ms,omitempty"` // 渠道下单耗时（毫秒）
 	Description     string         `gorm:"type:text" json:"description"`                            // 商品描述
 	NotifyURL       string         `gorm:"type:varchar(500)" json:"notify_url"`                     // 异步通知URL
 	ReturnURL       string         `gorm:"type:varchar(500)" json:"return_url"`                     // 同步跳转URL
@@ -34,6 +41,19 @@ type Payment struct {
 	LastNotifyAt    *time.Time     `gorm:"type:timestamptz" json:"last_notify_at"`                  // 最后通知时间
 	PaidAt          *time.Time     `gorm:"type:timestamptz" json:"paid_at"`                         // 支付完成时间
 	ExpiredAt       *time.Time     `gorm:"type:timestamptz" json:"expired_at"`                      // 过期时间
+	FXQuoteID          string         `gorm:"type:varchar(64);index" json:"fx_quote_id,omitempty"`           // 锁定的换汇报价编号
+	SettlementCurrency string         `gorm:"type:varchar(10)" json:"settlement_currency,omitempty"`         // 结算币种（换汇时）
+	SettlementAmount   int64          `gorm:"type:bigint;default:0" json:"settlement_amount,omitempty"`      // 按锁定汇率换算的结算金额
+	FXRate             *money.Decimal `gorm:"type:decimal(24,10)" json:"fx_rate,omitempty"`                  // 锁定汇率
+	RoutingAttempts    PaymentAttempts `gorm:"type:jsonb" json:"routing_attempts,omitempty"`                 // 渠道尝试记录（级联重试时有多条）
+	DeclineCategory    string         `gorm:"type:varchar(40);index" json:"decline_category,omitempty"`       // 拒绝原因分类（见 pkg/decline）
+	DeclineType        string         `gorm:"type:varchar(10)" json:"decline_type,omitempty"`                // 拒绝类型：hard, soft
+	DeclineRetryable   bool           `gorm:"default:false" json:"decline_retryable,omitempty"`              // 是否可原样重试
+	DeclineMessageKey  string         `gorm:"type:varchar(80)" json:"decline_message_key,omitempty"`         // 面向消费者的文案 key
+	ThreeDSStatus      string         `gorm:"type:varchar(20)" json:"three_ds_status,omitempty"`             // 3DS 状态：required 或 pkg/sca 认证结果
+	ThreeDSVersion     string         `gorm:"type:varchar(10)" json:"three_ds_version,omitempty"`            // 3DS 协议版本
+	SCAExemption       string         `gorm:"type:varchar(40)" json:"sca_exemption,omitempty"`               // 申请或生效的 SCA 豁免
+	LiabilityShift     bool           `gorm:"default:false" json:"liability_shift"`                          // 欺诈拒付责任是否已转移给发卡行
 	CreatedAt       time.Time      `gorm:"type:timestamptz;default:now();index:idx_merchant_status_created,priority:3,sort:desc" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"type:timestamptz;default:now()" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -44,6 +64,91 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
+// SetDecline 记录归一化的拒绝原因，渠道原始错误码写入 ErrorCode
+func (p *Payment) SetDecline(d *decline.Decline) {
+	if d == nil {
+		return
+	}
+	p.DeclineCategory = string(d.Category)
+	p.DeclineType = string(d.Type)
+	p.DeclineRetryable = d.Retryable
+	p.DeclineMessageKey = d.MessageKey
+	if d.ChannelCode != "" {
+		p.ErrorCode = d.ChannelCode
+	}
+}
+
+// Decline 返回支付的拒绝原因，未被拒绝时返回 nil
+func (p *Payment) Decline() *decline.Decline {
+	if p.DeclineCategory == "" {
+		return nil
+	}
+	return &decline.Decline{
+		Category:       decline.Category(p.DeclineCategory),
+		Type:           decline.Type(p.DeclineType),
+		Retryable:      p.DeclineRetryable,
+		MessageKey:     p.DeclineMessageKey,
+		Channel:        p.Channel,
+		ChannelCode:    p.ErrorCode,
+		ChannelMessage: p.ErrorMsg,
+	}
+}
+
+// SetAuthentication 记录 3DS 认证结果，nil 表示渠道未返回认证信息
+func (p *Payment) SetAuthentication(result *sca.Result) {
+	if result == nil {
+		return
+	}
+	p.ThreeDSStatus = string(result.Status)
+	p.ThreeDSVersion = result.Version
+	if result.Exemption != sca.ExemptionNone {
+		p.SCAExemption = string(result.Exemption)
+	}
+	p.LiabilityShift = result.LiabilityShift
+}
+
+// PaymentAttempt 单次渠道尝试
+type PaymentAttempt struct {
+	Channel   string    `json:"channel"`
+	Reason    string    `json:"reason,omitempty"` // 路由原因
+	Status    string    `json:"status"`           // accepted, failed
+	ErrorMsg  string    `json:"error_msg,omitempty"`
+	Decline   string    `json:"decline,omitempty"` // 拒绝原因分类
+	Cascade   bool      `json:"cascade"` // 失败后是否级联到了下一渠道
+	LatencyMs int64     `json:"latency_ms"`
+	At        time.Time `json:"at"`
+}
+
+// PaymentAttempts 渠道尝试记录，用于 GORM JSON 字段
+type PaymentAttempts []PaymentAttempt
+
+// Scan 实现 sql.Scanner 接口
+func (a *PaymentAttempts) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// Value 实现 driver.Valuer 接口
+func (a PaymentAttempts) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// 渠道尝试状态
+const (
+	AttemptStatusAccepted = "accepted" // 渠道已受理
+	AttemptStatusFailed   = "failed"
+)
+
 // Refund 退款记录表
 type Refund struct {
 	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -98,8 +203,10 @@ type PaymentRoute struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string         `gorm:"type:varchar(100);not null" json:"name"`              // 规则名称
 	Priority    int            `gorm:"type:integer;not null;default:0" json:"priority"`     // 优先级（越大越优先）
+	MerchantID  *uuid.UUID     `gorm:"type:uuid;index" json:"merchant_id,omitempty"`        // 商户ID（为空表示平台规则）
 	Channel     string         `gorm:"type:varchar(50);not null" json:"channel"`            // 目标渠道
-	Conditions  string         `gorm:"type:jsonb;not null" json:"conditions"`               // 路由条件（JSON）
+	Channels    StringArray    `gorm:"type:jsonb" json:"channels,omitempty"`                // 其他候选渠道（与 Channel 一起由策略评分选择）
+	Conditions  string         `gorm:"type:jsonb;not null" json:"conditions"`               // 路由条件（JSON）：min_amount, max_amount, currencies, countries, pay_methods, merchant_ids, time_ranges, timezone
 	IsEnabled   bool           `gorm:"default:true" json:"is_enabled"`                      // 是否启用
 	Description string         `gorm:"type:text" json:"description"`                        // 规则描述
 	CreatedAt   time.Time      `gorm:"type:timestamptz;default:now()" json:"created_at"`
@@ -112,16 +219,62 @@ func (PaymentRoute) TableName() string {
 	return "payment_routes"
 }
 
+// CandidateChannels 规则命中后的候选渠道（去重，Channel 在前）
+func (r *PaymentRoute) CandidateChannels() []string {
+	channels := []string{r.Channel}
+	for _, ch := range r.Channels {
+		duplicate := false
+		for _, existing := range channels {
+			if existing == ch {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate && ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// StringArray 字符串数组类型，用于 GORM JSON 字段
+type StringArray []string
+
+// Scan 实现 sql.Scanner 接口
+func (s *StringArray) Scan(value interface{}) error {
+	if value == nil {
+		*s = []string{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Value 实现 driver.Valuer 接口
+func (s StringArray) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
 // 支付状态常量
 const (
-	PaymentStatusPending    = "pending"    // 待支付
-	PaymentStatusProcessing = "processing" // 处理中
-	PaymentStatusSuccess    = "success"    // 支付成功
-	PaymentStatusFailed     = "failed"     // 支付失败
-	PaymentStatusCancelled  = "cancelled"  // 已取消
-	PaymentStatusExpired    = "expired"    // 已过期
+	PaymentStatusPending        = "pending"         // 待支付
+	PaymentStatusProcessing     = "processing"      // 处理中
+	PaymentStatusRequiresAction = "requires_action" // 等待消费者完成 3DS 验证
+	PaymentStatusSuccess        = "success"         // 支付成功
+	PaymentStatusFailed         = "failed"          // 支付失败
+	PaymentStatusCancelled      = "cancelled"       // 已取消
+	PaymentStatusExpired        = "expired"         // 已过期
 )
 
+// ThreeDSStatusRequired 已发起 3DS 挑战，等待消费者完成验证
+const ThreeDSStatusRequired = "required"
+
 // 退款状态常量
 const (
 	RefundStatusPending    = "pending"    // 待退款
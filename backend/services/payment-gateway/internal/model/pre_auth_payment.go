@@ -13,15 +13,23 @@ type PreAuthPayment struct {
 	OrderNo        string     `gorm:"type:varchar(100);not null;unique;index" json:"order_no"`             // 订单号
 	PreAuthNo      string     `gorm:"type:varchar(100);not null;unique;index" json:"pre_auth_no"`          // 预授权单号
 	PaymentNo      *string    `gorm:"type:varchar(100);index" json:"payment_no"`                           // 确认后的支付单号
-	Amount         int64      `gorm:"type:bigint;not null" json:"amount"`                                  // 预授权金额（分）
+	Amount         int64      `gorm:"type:bigint;not null" json:"amount"`                                  // 当前授权金额（分，增量授权后提高）
 	CapturedAmount int64      `gorm:"type:bigint;default:0" json:"captured_amount"`                        // 已确认金额（分）
+	ReleasedAmount int64      `gorm:"type:bigint;default:0" json:"released_amount"`                        // 已释放金额（分，最后一次确认/取消/过期后未确认的部分）
+	PendingCaptureAmount   int64 `gorm:"type:bigint;default:0" json:"pending_capture_amount"`   // 渠道确认中预留的金额（分，最后一次确认预留全部剩余金额）
+	PendingIncrementAmount int64 `gorm:"type:bigint;default:0" json:"pending_increment_amount"` // 渠道增量授权中预留的增量金额（分）
+	PendingOperation       string `gorm:"type:varchar(20);not null;default:''" json:"pending_operation"` // 进行中的渠道取消或重新授权（cancel/reauthorize）
+	CaptureCount   int        `gorm:"type:int;default:0" json:"capture_count"`                             // 确认次数
+	IncrementCount int        `gorm:"type:int;default:0" json:"increment_count"`                           // 增量授权次数
+	ReauthCount    int        `gorm:"type:int;default:0" json:"reauth_count"`                              // 重新授权次数
 	Currency       string     `gorm:"type:varchar(10);not null;default:'USD'" json:"currency"`             // 币种
 	Channel        string     `gorm:"type:varchar(50);not null" json:"channel"`                            // 支付渠道
 	ChannelTradeNo string     `gorm:"type:varchar(200);index" json:"channel_trade_no"`                     // 渠道交易号
-	Status         string     `gorm:"type:varchar(20);not null;index" json:"status"`                       // pending, authorized, captured, cancelled, expired
+	Status         string     `gorm:"type:varchar(20);not null;index" json:"status"`                       // pending, authorized, partially_captured, captured, cancelled, expired
 	ExpiresAt      time.Time  `gorm:"type:timestamptz;not null;index" json:"expires_at"`                   // 过期时间
 	AuthorizedAt   *time.Time `gorm:"type:timestamptz" json:"authorized_at"`                               // 授权时间
-	CapturedAt     *time.Time `gorm:"type:timestamptz" json:"captured_at"`                                 // 确认时间
+	CapturedAt     *time.Time `gorm:"type:timestamptz" json:"captured_at"`                                 // 最近一次确认时间
+	ReauthorizedAt *time.Time `gorm:"type:timestamptz" json:"reauthorized_at"`                             // 最近一次重新授权时间
 	CancelledAt    *time.Time `gorm:"type:timestamptz" json:"cancelled_at"`                                // 取消时间
 	Subject        string     `gorm:"type:varchar(255)" json:"subject"`                                    // 商品标题
 	Body           string     `gorm:"type:text" json:"body"`                                               // 商品描述
@@ -44,35 +52,118 @@ func (PreAuthPayment) TableName() string {
 const (
 	PreAuthStatusPending    = "pending"    // 待授权
 	PreAuthStatusAuthorized = "authorized" // 已授权（待确认）
-	PreAuthStatusCaptured   = "captured"   // 已确认（已扣款）
+	PreAuthStatusPartiallyCaptured = "partially_captured" // 已部分确认（剩余金额仍冻结，可继续确认）
+	PreAuthStatusCaptured   = "captured"   // 已确认（已扣款，未确认部分已释放）
 	PreAuthStatusCancelled  = "cancelled"  // 已取消
 	PreAuthStatusExpired    = "expired"    // 已过期
 )
 
+// 预授权渠道操作常量（取消、重新授权调用渠道前预留，期间不允许确认、增量授权等其他操作）
+const (
+	PreAuthOperationCancel      = "cancel"      // 取消中
+	PreAuthOperationReauthorize = "reauthorize" // 重新授权中
+)
+
 // IsExpired 检查是否已过期
 func (p *PreAuthPayment) IsExpired() bool {
 	return time.Now().After(p.ExpiresAt)
 }
 
-// CanCapture 检查是否可以确认
+// IsHolding 资金是否仍处于冻结状态（已授权或已部分确认）
+func (p *PreAuthPayment) IsHolding() bool {
+	return p.Status == PreAuthStatusAuthorized || p.Status == PreAuthStatusPartiallyCaptured
+}
+
+// HasPendingOperation 是否有进行中的渠道确认、增量授权、取消或重新授权（预留未确认/释放）
+func (p *PreAuthPayment) HasPendingOperation() bool {
+	return p.PendingCaptureAmount != 0 || p.PendingIncrementAmount != 0 || p.PendingOperation != ""
+}
+
+// CanCapture 检查是否可以确认（同一预授权同时只允许一笔渠道确认或增量授权）
 func (p *PreAuthPayment) CanCapture() bool {
-	return p.Status == PreAuthStatusAuthorized && !p.IsExpired()
+	return p.IsHolding() && !p.IsExpired() && p.GetRemainingAmount() > 0 && !p.HasPendingOperation()
+}
+
+// CanIncrement 检查是否可以增量授权（部分确认后渠道不再允许提高授权金额）
+func (p *PreAuthPayment) CanIncrement() bool {
+	return p.Status == PreAuthStatusAuthorized && !p.IsExpired() && !p.HasPendingOperation()
+}
+
+// CanReauthorize 检查是否可以重新授权（只能在原授权过期前进行）
+func (p *PreAuthPayment) CanReauthorize() bool {
+	return p.IsHolding() && !p.IsExpired() && p.GetRemainingAmount() > 0 && !p.HasPendingOperation()
 }
 
-// CanCancel 检查是否可以取消
+// CanCancel 检查是否可以取消（渠道确认、增量授权或重新授权进行中时不允许取消）
 func (p *PreAuthPayment) CanCancel() bool {
-	return (p.Status == PreAuthStatusPending || p.Status == PreAuthStatusAuthorized) && !p.IsExpired()
+	return (p.Status == PreAuthStatusPending || p.IsHolding()) && !p.IsExpired() && !p.HasPendingOperation()
 }
 
-// GetRemainingAmount 获取剩余可确认金额
+// GetRemainingAmount 获取剩余可确认金额（不含渠道确认中预留的金额）
 func (p *PreAuthPayment) GetRemainingAmount() int64 {
-	return p.Amount - p.CapturedAmount
+	return p.Amount - p.CapturedAmount - p.ReleasedAmount - p.PendingCaptureAmount
+}
+
+// Breakdown 授权金额明细
+func (p *PreAuthPayment) Breakdown() *PreAuthBreakdown {
+	capturable := int64(0)
+	if p.IsHolding() && !p.IsExpired() {
+		capturable = p.GetRemainingAmount()
+	}
+	return &PreAuthBreakdown{
+		PreAuthNo:        p.PreAuthNo,
+		Status:           p.Status,
+		Currency:         p.Currency,
+		AuthorizedAmount: p.Amount,
+		CapturableAmount: capturable,
+		CapturedAmount:   p.CapturedAmount,
+		ReleasedAmount:   p.ReleasedAmount,
+		PendingAmount:    p.PendingCaptureAmount,
+		CaptureCount:     p.CaptureCount,
+		ExpiresAt:        p.ExpiresAt,
+	}
+}
+
+// PreAuthBreakdown 预授权金额明细：授权金额 = 可确认 + 已确认 + 已释放 + 确认中（冻结中的剩余金额过期前计入可确认）
+type PreAuthBreakdown struct {
+	PreAuthNo        string            `json:"pre_auth_no"`
+	Status           string            `json:"status"`
+	Currency         string            `json:"currency"`
+	AuthorizedAmount int64             `json:"authorized_amount"` // 当前授权金额
+	CapturableAmount int64             `json:"capturable_amount"` // 可确认金额
+	CapturedAmount   int64             `json:"captured_amount"`   // 已确认金额
+	ReleasedAmount   int64             `json:"released_amount"`   // 已释放金额
+	PendingAmount    int64             `json:"pending_amount"`    // 渠道确认中的预留金额
+	CaptureCount     int               `json:"capture_count"`     // 确认次数
+	ExpiresAt        time.Time         `json:"expires_at"`        // 授权过期时间
+	Captures         []*PreAuthCapture `json:"captures"`          // 确认明细
+}
+
+// PreAuthCapture 预授权确认记录（一次预授权可以多次部分确认，每次确认生成一笔支付）
+type PreAuthCapture struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PreAuthID      uuid.UUID `gorm:"type:uuid;not null;index" json:"pre_auth_id"`
+	PreAuthNo      string    `gorm:"type:varchar(100);not null;index" json:"pre_auth_no"`   // 预授权单号
+	PaymentNo      string    `gorm:"type:varchar(100);not null;unique" json:"payment_no"`   // 本次确认生成的支付单号
+	Sequence       int       `gorm:"type:int;not null" json:"sequence"`                     // 第几次确认（从1开始）
+	Amount         int64     `gorm:"type:bigint;not null" json:"amount"`                    // 本次确认金额（分）
+	Currency       string    `gorm:"type:varchar(10);not null" json:"currency"`             // 币种
+	IsFinal        bool      `gorm:"not null;default:false" json:"is_final"`                // 是否最后一次确认
+	ReleasedAmount int64     `gorm:"type:bigint;default:0" json:"released_amount"`          // 最后一次确认时释放的剩余金额（分）
+	ChannelTradeNo string    `gorm:"type:varchar(200)" json:"channel_trade_no"`             // 渠道交易号
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PreAuthCapture) TableName() string {
+	return "pre_auth_captures"
 }
 
 // PreAuthCaptureRequest 确认预授权请求
 type PreAuthCaptureRequest struct {
-	PreAuthNo string `json:"pre_auth_no" binding:"required"`
-	Amount    *int64 `json:"amount"` // 可选，如果不传则全额确认
+	PreAuthNo    string `json:"pre_auth_no" binding:"required"`
+	Amount       *int64 `json:"amount"`        // 可选，如果不传则全额确认
+	FinalCapture *bool  `json:"final_capture"` // 可选，默认 true；false 时保留剩余金额继续冻结
 }
 
 // PreAuthCancelRequest 取消预授权请求
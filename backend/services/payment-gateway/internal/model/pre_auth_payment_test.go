@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreAuthBreakdownAcrossMultipleCaptures(t *testing.T) {
+	preAuth := &PreAuthPayment{
+		PreAuthNo: "PA1",
+		Amount:    10000,
+		Currency:  "USD",
+		Status:    PreAuthStatusAuthorized,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	assert.True(t, preAuth.CanIncrement())
+
+	// 第一次部分确认后剩余金额仍可确认，但不能再增量授权
+	preAuth.Status = PreAuthStatusPartiallyCaptured
+	preAuth.CapturedAmount = 3000
+	preAuth.CaptureCount = 1
+	assert.True(t, preAuth.CanCapture())
+	assert.False(t, preAuth.CanIncrement())
+
+	breakdown := preAuth.Breakdown()
+	assert.Equal(t, int64(7000), breakdown.CapturableAmount)
+	assert.Equal(t, int64(3000), breakdown.CapturedAmount)
+	assert.Equal(t, int64(0), breakdown.ReleasedAmount)
+
+	// 最后一次确认 5000，剩余 2000 释放
+	preAuth.Status = PreAuthStatusCaptured
+	preAuth.CapturedAmount = 8000
+	preAuth.ReleasedAmount = 2000
+	preAuth.CaptureCount = 2
+	assert.False(t, preAuth.CanCapture())
+
+	breakdown = preAuth.Breakdown()
+	assert.Equal(t, int64(0), breakdown.CapturableAmount)
+	assert.Equal(t, preAuth.Amount, breakdown.CapturableAmount+breakdown.CapturedAmount+breakdown.ReleasedAmount)
+}
+
+func TestPreAuthBreakdownExpiredHoldIsNotCapturable(t *testing.T) {
+	preAuth := &PreAuthPayment{
+		Amount:         5000,
+		CapturedAmount: 1000,
+		Status:         PreAuthStatusPartiallyCaptured,
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	}
+
+	assert.False(t, preAuth.CanCapture())
+	assert.False(t, preAuth.CanReauthorize())
+	assert.Equal(t, int64(0), preAuth.Breakdown().CapturableAmount)
+}
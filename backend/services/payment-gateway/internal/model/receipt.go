@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipt 电子收据（PDF 文件保存在导出存储中，按来源单号和语言缓存）
+type Receipt struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID   uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	ReceiptNo    string    `gorm:"type:varchar(80);not null;index" json:"receipt_no"`                           // 收据编号（同一来源不同语言共用）
+	SourceType   string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_receipt_source" json:"source_type"` // payment, refund
+	SourceNo     string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_receipt_source" json:"source_no"`   // 支付流水号或退款单号
+	Language     string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_receipt_source" json:"language"`    // 收据语言
+	ExportTaskID uuid.UUID `gorm:"type:uuid;not null" json:"export_task_id"`                                    // 导出任务ID（文件登记）
+	FileName     string    `gorm:"type:varchar(255)" json:"file_name"`
+	FileSize     int64     `json:"file_size"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 收据来源类型
+const (
+	ReceiptSourcePayment = "payment" // 支付收据
+	ReceiptSourceRefund  = "refund"  // 退款凭证
+)
+
+// TableName 表名
+func (Receipt) TableName() string {
+	return "receipts"
+}
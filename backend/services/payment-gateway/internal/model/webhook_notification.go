@@ -8,22 +8,24 @@ import (
 
 // WebhookNotification Webhook 通知记录
 type WebhookNotification struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	MerchantID  uuid.UUID `gorm:"type:uuid;not null;index:idx_webhook_merchant" json:"merchant_id"`
-	PaymentNo   string    `gorm:"type:varchar(64);not null;index:idx_webhook_payment" json:"payment_no"`
-	OrderNo     string    `gorm:"type:varchar(128);not null;index:idx_webhook_order" json:"order_no"`
-	Event       string    `gorm:"type:varchar(50);not null" json:"event"` // payment.success, payment.failed, refund.success
-	URL         string    `gorm:"type:varchar(500);not null" json:"url"`
-	Payload     string    `gorm:"type:jsonb" json:"payload"`               // JSON 格式的通知内容
-	Status      string    `gorm:"type:varchar(20);not null;index" json:"status"` // pending, success, failed, retrying
-	Attempts    int       `gorm:"default:0" json:"attempts"`               // 尝试次数
-	MaxAttempts int       `gorm:"default:5" json:"max_attempts"`           // 最大尝试次数
-	StatusCode  int       `gorm:"default:0" json:"status_code"`            // HTTP 状态码
-	Response    string    `gorm:"type:text" json:"response"`               // 响应内容
-	Error       string    `gorm:"type:text" json:"error"`                  // 错误信息
-	NextRetryAt *time.Time `gorm:"index:idx_webhook_retry" json:"next_retry_at"` // 下次重试时间
-	SucceededAt *time.Time `json:"succeeded_at"`                           // 成功时间
-	FailedAt    *time.Time `json:"failed_at"`                              // 最终失败时间
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MerchantID  uuid.UUID  `gorm:"type:uuid;not null;index:idx_webhook_merchant" json:"merchant_id"`
+	PaymentNo   string     `gorm:"type:varchar(64);not null;index:idx_webhook_payment" json:"payment_no"`
+	OrderNo     string     `gorm:"type:varchar(128);not null;index:idx_webhook_order" json:"order_no"`
+	Event       string     `gorm:"type:varchar(50);not null" json:"event"` // payment.success, payment.failed, refund.success
+	EventID     string     `gorm:"type:varchar(64);index" json:"event_id"` // 事件ID（evt_ 前缀，重试时不变，商户用于去重）
+	APIVersion  string     `gorm:"type:varchar(20)" json:"api_version"`    // 负载版本（v1, v2）
+	URL         string     `gorm:"type:varchar(500);not null" json:"url"`
+	Payload     string     `gorm:"type:jsonb" json:"payload"`                     // JSON 格式的通知内容
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"` // pending, success, failed, retrying
+	Attempts    int        `gorm:"default:0" json:"attempts"`                     // 尝试次数
+	MaxAttempts int        `gorm:"default:5" json:"max_attempts"`                 // 最大尝试次数
+	StatusCode  int        `gorm:"default:0" json:"status_code"`                  // HTTP 状态码
+	Response    string     `gorm:"type:text" json:"response"`                     // 响应内容
+	Error       string     `gorm:"type:text" json:"error"`                        // 错误信息
+	NextRetryAt *time.Time `gorm:"index:idx_webhook_retry" json:"next_retry_at"`  // 下次重试时间
+	SucceededAt *time.Time `json:"succeeded_at"`                                  // 成功时间
+	FailedAt    *time.Time `json:"failed_at"`                                     // 最终失败时间
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
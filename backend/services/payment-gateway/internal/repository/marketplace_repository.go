@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-platform/payment-gateway/internal/model"
+)
+
+// MarketplaceRepository 平台分账仓储接口（子商户、分账明细与冲正明细）
+type MarketplaceRepository interface {
+	// 子商户管理
+	CreateConnectedAccount(ctx context.Context, account *model.ConnectedAccount) error
+	// GetConnectedAccount 获取平台商户下的子商户，不存在时返回 nil
+	GetConnectedAccount(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID) (*model.ConnectedAccount, error)
+	ListConnectedAccounts(ctx context.Context, platformMerchantID uuid.UUID) ([]*model.ConnectedAccount, error)
+	UpdateConnectedAccountStatus(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID, status string) error
+
+	// 分账明细
+	ListSplits(ctx context.Context, paymentNo string) ([]*model.PaymentSplit, error)
+	ListReversals(ctx context.Context, paymentNo string) ([]*model.PaymentSplitReversal, error)
+	// ListReversalsByReference 按退款单号或拒付单号查询冲正明细
+	ListReversalsByReference(ctx context.Context, referenceType, referenceNo string) ([]*model.PaymentSplitReversal, error)
+	// CreateReversals 保存冲正明细并累加各分账的已冲正金额（同一事务）
+	CreateReversals(ctx context.Context, reversals []*model.PaymentSplitReversal) error
+}
+
+type marketplaceRepository struct {
+	db *gorm.DB
+}
+
+// NewMarketplaceRepository 创建平台分账仓储
+func NewMarketplaceRepository(db *gorm.DB) MarketplaceRepository {
+	return &marketplaceRepository{db: db}
+}
+
+func (r *marketplaceRepository) CreateConnectedAccount(ctx context.Context, account *model.ConnectedAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+func (r *marketplaceRepository) GetConnectedAccount(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID) (*model.ConnectedAccount, error) {
+	var account model.ConnectedAccount
+	err := r.db.WithContext(ctx).
+		Where("platform_merchant_id = ? AND sub_merchant_id = ?", platformMerchantID, subMerchantID).
+		First(&account).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *marketplaceRepository) ListConnectedAccounts(ctx context.Context, platformMerchantID uuid.UUID) ([]*model.ConnectedAccount, error) {
+	var accounts []*model.ConnectedAccount
+	err := r.db.WithContext(ctx).
+		Where("platform_merchant_id = ?", platformMerchantID).
+		Order("created_at ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *marketplaceRepository) UpdateConnectedAccountStatus(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID, status string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.ConnectedAccount{}).
+		Where("platform_merchant_id = ? AND sub_merchant_id = ?", platformMerchantID, subMerchantID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *marketplaceRepository) ListSplits(ctx context.Context, paymentNo string) ([]*model.PaymentSplit, error) {
+	var splits []*model.PaymentSplit
+	err := r.db.WithContext(ctx).
+		Where("payment_no = ?", paymentNo).
+		Order("role DESC, created_at ASC").
+		Find(&splits).Error
+	return splits, err
+}
+
+func (r *marketplaceRepository) ListReversals(ctx context.Context, paymentNo string) ([]*model.PaymentSplitReversal, error) {
+	var reversals []*model.PaymentSplitReversal
+	err := r.db.WithContext(ctx).
+		Where("payment_no = ?", paymentNo).
+		Order("created_at ASC").
+		Find(&reversals).Error
+	return reversals, err
+}
+
+func (r *marketplaceRepository) ListReversalsByReference(ctx context.Context, referenceType, referenceNo string) ([]*model.PaymentSplitReversal, error) {
+	var reversals []*model.PaymentSplitReversal
+	err := r.db.WithContext(ctx).
+		Where("reference_type = ? AND reference_no = ?", referenceType, referenceNo).
+		Find(&reversals).Error
+	return reversals, err
+}
+
+func (r *marketplaceRepository) CreateReversals(ctx context.Context, reversals []*model.PaymentSplitReversal) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, reversal := range reversals {
+			if err := tx.Create(reversal).Error; err != nil {
+				return err
+			}
+
+			// 条件更新防止并发冲正超过分账金额
+			result := tx.Model(&model.PaymentSplit{}).
+				Where("payment_no = ? AND recipient_id = ? AND amount - reversed_amount >= ?",
+					reversal.PaymentNo, reversal.RecipientID, reversal.Amount).
+				Updates(map[string]interface{}{
+					"reversed_amount": gorm.Expr("reversed_amount + ?", reversal.Amount),
+					"updated_at":      gorm.Expr("NOW()"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("分账 %s/%s 剩余金额不足，冲正失败", reversal.PaymentNo, reversal.RecipientID)
+			}
+		}
+		return nil
+	})
+}
@@ -41,6 +41,7 @@ type PaymentRepository interface {
 	CreateRoute(ctx context.Context, route *model.PaymentRoute) error
 	GetRouteByID(ctx context.Context, id uuid.UUID) (*model.PaymentRoute, error)
 	ListActiveRoutes(ctx context.Context) ([]*model.PaymentRoute, error)
+	ListRoutes(ctx context.Context, merchantID *uuid.UUID) ([]*model.PaymentRoute, error)
 	UpdateRoute(ctx context.Context, route *model.PaymentRoute) error
 	DeleteRoute(ctx context.Context, id uuid.UUID) error
 }
@@ -392,6 +393,17 @@ func (r *paymentRepository) ListActiveRoutes(ctx context.Context) ([]*model.Paym
 	return routes, err
 }
 
+// ListRoutes 获取路由规则（含已停用），merchantID 非空时仅返回该商户的规则
+func (r *paymentRepository) ListRoutes(ctx context.Context, merchantID *uuid.UUID) ([]*model.PaymentRoute, error) {
+	var routes []*model.PaymentRoute
+	db := r.db.WithContext(ctx)
+	if merchantID != nil {
+		db = db.Where("merchant_id = ?", *merchantID)
+	}
+	err := db.Order("priority DESC, created_at ASC").Find(&routes).Error
+	return routes, err
+}
+
 // UpdateRoute 更新路由规则
 func (r *paymentRepository) UpdateRoute(ctx context.Context, route *model.PaymentRoute) error {
 	return r.db.WithContext(ctx).Save(route).Error
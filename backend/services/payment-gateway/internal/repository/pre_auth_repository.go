@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,15 +22,45 @@ type PreAuthRepository interface {
 	// 状态更新
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
 	UpdateToAuthorized(ctx context.Context, id uuid.UUID, channelTradeNo string, authorizedAt time.Time) error
-	UpdateToCaptured(ctx context.Context, id uuid.UUID, capturedAmount int64, paymentNo string, capturedAt time.Time) error
 	UpdateToCancelled(ctx context.Context, id uuid.UUID, cancelledAt time.Time, reason string) error
 	UpdateToExpired(ctx context.Context, id uuid.UUID) error
+	UpdateToReauthorized(ctx context.Context, id uuid.UUID, channelTradeNo string, expiresAt, reauthorizedAt time.Time) error
+
+	// 取消、重新授权：调用渠道前预留操作，更新为已取消/已重新授权时清除，重新授权失败后释放
+	ReserveOperation(ctx context.Context, preAuth *model.PreAuthPayment, operation string) error
+	ReleaseOperation(ctx context.Context, id uuid.UUID, operation string) error
+
+	// 增量授权：调用渠道前预留增量，渠道成功后确认，失败后释放
+	ReserveIncrement(ctx context.Context, preAuth *model.PreAuthPayment, incrementAmount int64) error
+	ConfirmIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error
+	ReleaseIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error
+
+	// 多次确认：调用渠道前预留确认金额，渠道成功后确认（写入确认记录和支付记录），失败后释放
+	ReserveCapture(ctx context.Context, preAuth *model.PreAuthPayment, amount int64, final bool) (int64, error)
+	ConfirmCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64, capture *model.PreAuthCapture, payment *model.Payment) error
+	ReleaseCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64) error
+	ListCaptures(ctx context.Context, preAuthID uuid.UUID) ([]*model.PreAuthCapture, error)
 
 	// 批量操作
 	GetExpiredPreAuths(ctx context.Context, limit int) ([]*model.PreAuthPayment, error)
 	ListByMerchant(ctx context.Context, merchantID uuid.UUID, status string, offset, limit int) ([]*model.PreAuthPayment, error)
 }
 
+// ErrPreAuthConcurrentUpdate 预授权已被并发修改（状态、金额或确认次数与读取时不一致）
+var ErrPreAuthConcurrentUpdate = errors.New("预授权已被并发修改，请重新查询后重试")
+
+// holdingStatuses 资金仍处于冻结状态的预授权状态
+var holdingStatuses = []string{model.PreAuthStatusAuthorized, model.PreAuthStatusPartiallyCaptured}
+
+// noPendingOperation 没有进行中的渠道确认、增量授权、取消或重新授权
+const noPendingOperation = "pending_capture_amount = 0 AND pending_increment_amount = 0 AND pending_operation = ''"
+
+// operationStatuses 各渠道操作允许预留的预授权状态
+var operationStatuses = map[string][]string{
+	model.PreAuthOperationCancel:      {model.PreAuthStatusPending, model.PreAuthStatusAuthorized, model.PreAuthStatusPartiallyCaptured},
+	model.PreAuthOperationReauthorize: holdingStatuses,
+}
+
 // preAuthRepository 仓库实现
 type preAuthRepository struct {
 	db *gorm.DB
@@ -118,50 +149,254 @@ func (r *preAuthRepository) UpdateToAuthorized(ctx context.Context, id uuid.UUID
 		}).Error
 }
 
-// UpdateToCaptured 更新为已确认状态
-func (r *preAuthRepository) UpdateToCaptured(ctx context.Context, id uuid.UUID, capturedAmount int64, paymentNo string, capturedAt time.Time) error {
-	return r.db.WithContext(ctx).
+// UpdateToCancelled 更新为已取消状态，释放未确认的金额并清除取消预留
+//
+// 已部分确认的预授权取消剩余冻结后视为确认完成（captured），已确认的支付不受影响。
+// 只更新已通过 ReserveOperation 预留取消的记录，否则返回 ErrPreAuthConcurrentUpdate。
+func (r *preAuthRepository) UpdateToCancelled(ctx context.Context, id uuid.UUID, cancelledAt time.Time, reason string) error {
+	result := r.db.WithContext(ctx).
 		Model(&model.PreAuthPayment{}).
-		Where("id = ?", id).
+		Where("id = ? AND pending_operation = ?", id, model.PreAuthOperationCancel).
+		Updates(map[string]interface{}{
+			"status": gorm.Expr("CASE WHEN captured_amount > 0 THEN ? ELSE ? END",
+				model.PreAuthStatusCaptured, model.PreAuthStatusCancelled),
+			"released_amount":   gorm.Expr("amount - captured_amount"),
+			"pending_operation": "",
+			"cancelled_at":      cancelledAt,
+			"error_message":     reason,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// UpdateToExpired 更新为已过期状态，释放未确认的金额（有进行中的操作时返回 ErrPreAuthConcurrentUpdate）
+func (r *preAuthRepository) UpdateToExpired(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND status IN (?) AND "+noPendingOperation,
+			id, operationStatuses[model.PreAuthOperationCancel]).
 		Updates(map[string]interface{}{
-			"status":          model.PreAuthStatusCaptured,
-			"captured_amount": gorm.Expr("captured_amount + ?", capturedAmount),
-			"payment_no":      paymentNo,
-			"captured_at":     capturedAt,
+			"status":          model.PreAuthStatusExpired,
+			"released_amount": gorm.Expr("amount - captured_amount"),
 			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// ReserveOperation 调用渠道取消或重新授权前预留操作
+//
+// 与 ReserveCapture 相同，以读取时的授权金额和确认次数做乐观锁，预留期间不允许确认、增量授权或其他操作，
+// 状态不允许、金额与读取时不一致或已有进行中的操作时返回 ErrPreAuthConcurrentUpdate。
+func (r *preAuthRepository) ReserveOperation(ctx context.Context, preAuth *model.PreAuthPayment, operation string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND status IN (?) AND capture_count = ? AND amount = ? AND "+noPendingOperation,
+			preAuth.ID, operationStatuses[operation], preAuth.CaptureCount, preAuth.Amount).
+		Updates(map[string]interface{}{
+			"pending_operation": operation,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// ReleaseOperation 渠道操作失败后释放预留的操作
+func (r *preAuthRepository) ReleaseOperation(ctx context.Context, id uuid.UUID, operation string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND pending_operation = ?", id, operation).
+		Updates(map[string]interface{}{
+			"pending_operation": "",
+			"updated_at":        time.Now(),
 		}).Error
 }
 
-// UpdateToCancelled 更新为已取消状态
-func (r *preAuthRepository) UpdateToCancelled(ctx context.Context, id uuid.UUID, cancelledAt time.Time, reason string) error {
+// ReserveIncrement 调用渠道增量授权前预留增量金额
+//
+// 以读取时的授权金额做乐观锁，预留期间不允许确认、取消或再次增量授权，
+// 授权金额与读取时不一致或已有进行中的操作时返回 ErrPreAuthConcurrentUpdate。
+func (r *preAuthRepository) ReserveIncrement(ctx context.Context, preAuth *model.PreAuthPayment, incrementAmount int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND status = ? AND amount = ? AND "+noPendingOperation,
+			preAuth.ID, model.PreAuthStatusAuthorized, preAuth.Amount).
+		Updates(map[string]interface{}{
+			"pending_increment_amount": incrementAmount,
+			"updated_at":               time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// ConfirmIncrement 渠道增量授权成功后将预留的增量计入授权金额
+func (r *preAuthRepository) ConfirmIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND pending_increment_amount = ?", id, incrementAmount).
+		Updates(map[string]interface{}{
+			"amount":                   gorm.Expr("amount + ?", incrementAmount),
+			"pending_increment_amount": 0,
+			"increment_count":          gorm.Expr("increment_count + 1"),
+			"updated_at":               time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// ReleaseIncrement 渠道增量授权失败后释放预留的增量
+func (r *preAuthRepository) ReleaseIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error {
 	return r.db.WithContext(ctx).
 		Model(&model.PreAuthPayment{}).
-		Where("id = ?", id).
+		Where("id = ? AND pending_increment_amount = ?", id, incrementAmount).
 		Updates(map[string]interface{}{
-			"status":        model.PreAuthStatusCancelled,
-			"cancelled_at":  cancelledAt,
-			"error_message": reason,
-			"updated_at":    time.Now(),
+			"pending_increment_amount": 0,
+			"updated_at":               time.Now(),
 		}).Error
 }
 
-// UpdateToExpired 更新为已过期状态
-func (r *preAuthRepository) UpdateToExpired(ctx context.Context, id uuid.UUID) error {
+// UpdateToReauthorized 更新重新授权后的渠道交易号与过期时间，并清除重新授权预留
+func (r *preAuthRepository) UpdateToReauthorized(ctx context.Context, id uuid.UUID, channelTradeNo string, expiresAt, reauthorizedAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND pending_operation = ?", id, model.PreAuthOperationReauthorize).
+		Updates(map[string]interface{}{
+			"channel_trade_no":  channelTradeNo,
+			"expires_at":        expiresAt,
+			"reauthorized_at":   reauthorizedAt,
+			"reauth_count":      gorm.Expr("reauth_count + 1"),
+			"pending_operation": "",
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreAuthConcurrentUpdate
+	}
+	return nil
+}
+
+// ReserveCapture 调用渠道确认前预留确认金额，返回预留金额
+//
+// 以读取时的确认次数做乐观锁，同一预授权同时只允许一笔确认，并发确认或已有进行中的操作时返回
+// ErrPreAuthConcurrentUpdate。最后一次确认预留全部剩余金额，确认成功后未确认部分计入已释放金额。
+// 渠道调用期间预留金额不可再确认，避免同一笔冻结资金被重复扣款。
+func (r *preAuthRepository) ReserveCapture(ctx context.Context, preAuth *model.PreAuthPayment, amount int64, final bool) (int64, error) {
+	remaining := preAuth.Amount - preAuth.CapturedAmount - preAuth.ReleasedAmount
+	reserved := amount
+	if final {
+		reserved = remaining
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&model.PreAuthPayment{}).
+		Where("id = ? AND status IN (?) AND capture_count = ? AND amount = ? AND captured_amount + released_amount + ? <= amount AND "+noPendingOperation,
+			preAuth.ID, holdingStatuses, preAuth.CaptureCount, preAuth.Amount, reserved).
+		Updates(map[string]interface{}{
+			"pending_capture_amount": reserved,
+			"updated_at":             time.Now(),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrPreAuthConcurrentUpdate
+	}
+	return reserved, nil
+}
+
+// ConfirmCapture 渠道确认成功后在同一事务中结转预留金额、写入确认记录和支付记录
+//
+// 最后一次确认会把预留中未确认的金额计入已释放金额并将状态置为 captured。
+func (r *preAuthRepository) ConfirmCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64, capture *model.PreAuthCapture, payment *model.Payment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		status := model.PreAuthStatusPartiallyCaptured
+		releasedAmount := gorm.Expr("released_amount")
+		if capture.IsFinal {
+			status = model.PreAuthStatusCaptured
+			releasedAmount = gorm.Expr("released_amount + ?", reserved-capture.Amount)
+		}
+
+		result := tx.Model(&model.PreAuthPayment{}).
+			Where("id = ? AND pending_capture_amount = ?", preAuthID, reserved).
+			Updates(map[string]interface{}{
+				"status":                 status,
+				"captured_amount":        gorm.Expr("captured_amount + ?", capture.Amount),
+				"released_amount":        releasedAmount,
+				"pending_capture_amount": 0,
+				"capture_count":          gorm.Expr("capture_count + 1"),
+				"payment_no":             payment.PaymentNo,
+				"captured_at":            capture.CreatedAt,
+				"updated_at":             time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPreAuthConcurrentUpdate
+		}
+
+		if err := tx.Create(capture).Error; err != nil {
+			return err
+		}
+		return tx.Create(payment).Error
+	})
+}
+
+// ReleaseCapture 渠道确认失败后释放预留的确认金额
+func (r *preAuthRepository) ReleaseCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64) error {
 	return r.db.WithContext(ctx).
 		Model(&model.PreAuthPayment{}).
-		Where("id = ?", id).
+		Where("id = ? AND pending_capture_amount = ?", preAuthID, reserved).
 		Updates(map[string]interface{}{
-			"status":     model.PreAuthStatusExpired,
-			"updated_at": time.Now(),
+			"pending_capture_amount": 0,
+			"updated_at":             time.Now(),
 		}).Error
 }
 
+// ListCaptures 获取预授权的确认记录
+func (r *preAuthRepository) ListCaptures(ctx context.Context, preAuthID uuid.UUID) ([]*model.PreAuthCapture, error) {
+	var captures []*model.PreAuthCapture
+	err := r.db.WithContext(ctx).
+		Where("pre_auth_id = ?", preAuthID).
+		Order("sequence ASC").
+		Find(&captures).Error
+	return captures, err
+}
+
 // GetExpiredPreAuths 获取过期的预授权记录
 func (r *preAuthRepository) GetExpiredPreAuths(ctx context.Context, limit int) ([]*model.PreAuthPayment, error) {
 	var preAuths []*model.PreAuthPayment
 	err := r.db.WithContext(ctx).
-		Where("status IN (?) AND expires_at < ?",
-			[]string{model.PreAuthStatusPending, model.PreAuthStatusAuthorized},
+		Where("status IN (?) AND expires_at < ? AND "+noPendingOperation,
+			[]string{model.PreAuthStatusPending, model.PreAuthStatusAuthorized, model.PreAuthStatusPartiallyCaptured},
 			time.Now()).
 		Order("expires_at ASC").
 		Limit(limit).
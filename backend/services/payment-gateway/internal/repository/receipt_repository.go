@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"payment-platform/payment-gateway/internal/model"
+)
+
+// ReceiptRepository 电子收据仓储接口
+type ReceiptRepository interface {
+	// GetBySource 按来源单号和语言获取收据，不存在时返回 nil
+	GetBySource(ctx context.Context, sourceType, sourceNo, language string) (*model.Receipt, error)
+	// Save 保存收据（同一来源和语言已存在时覆盖文件信息）
+	Save(ctx context.Context, receipt *model.Receipt) error
+}
+
+type receiptRepository struct {
+	db *gorm.DB
+}
+
+// NewReceiptRepository 创建电子收据仓储
+func NewReceiptRepository(db *gorm.DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+func (r *receiptRepository) GetBySource(ctx context.Context, sourceType, sourceNo, language string) (*model.Receipt, error) {
+	var receipt model.Receipt
+	err := r.db.WithContext(ctx).
+		Where("source_type = ? AND source_no = ? AND language = ?", sourceType, sourceNo, language).
+		First(&receipt).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (r *receiptRepository) Save(ctx context.Context, receipt *model.Receipt) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "source_type"}, {Name: "source_no"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"export_task_id", "file_name", "file_size", "updated_at"}),
+		}).
+		Create(receipt).Error
+}
@@ -34,14 +34,19 @@ func NewCallbackSagaService(
 	orderClient *client.OrderClient,
 	kafkaProducer KafkaProducer,
 ) *CallbackSagaService {
-	return &CallbackSagaService{
+	s := &CallbackSagaService{
 		orchestrator:  orchestrator,
 		paymentRepo:   paymentRepo,
 		orderClient:   orderClient,
 		kafkaProducer: kafkaProducer,
 	}
+	orchestrator.RegisterStepDefinitions(SagaTypePaymentCallback, s.recoverStepDefinitions)
+	return s
 }
 
+// SagaTypePaymentCallback 支付回调 Saga 业务类型
+const SagaTypePaymentCallback = "payment_callback"
+
 // CallbackData 回调数据
 type CallbackData struct {
 	PaymentNo      string
@@ -52,25 +57,20 @@ type CallbackData struct {
 	RawData        string
 }
 
-// ExecuteCallbackSaga 执行支付回调 Saga
-func (s *CallbackSagaService) ExecuteCallbackSaga(
-	ctx context.Context,
-	payment *model.Payment,
-	callbackData *CallbackData,
-) error {
-	// 1. 构建 Saga
-	sagaBuilder := s.orchestrator.NewSagaBuilder(payment.PaymentNo, "payment_callback")
-	sagaBuilder.SetMetadata(map[string]interface{}{
-		"payment_no":       payment.PaymentNo,
-		"merchant_id":      payment.MerchantID.String(),
-		"channel_order_no": callbackData.ChannelOrderNo,
-		"status":           callbackData.Status,
-		"amount":           payment.Amount,
-		"currency":         payment.Currency,
-	})
+// callbackMetadata 回调 Saga 元数据（恢复时据此还原回调数据）
+type callbackMetadata struct {
+	ChannelOrderNo string     `json:"channel_order_no"`
+	Status         string     `json:"status"`
+	PaidAt         *time.Time `json:"paid_at"`
+	FailureReason  string     `json:"failure_reason"`
+	RawData        string     `json:"raw_data"`
+}
 
-	// 2. 定义步骤
-	stepDefs := []saga.StepDefinition{
+// stepDefinitions 支付回调 Saga 步骤定义
+//
+// 记录回调 → 更新支付状态后，更新订单状态与发布事件互不依赖，并行执行。
+func (s *CallbackSagaService) stepDefinitions(payment *model.Payment, callbackData *CallbackData) []saga.StepDefinition {
+	return []saga.StepDefinition{
 		{
 			Name: "RecordCallback",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
@@ -83,7 +83,8 @@ func (s *CallbackSagaService) ExecuteCallbackSaga(
 			Timeout:       10 * time.Second,
 		},
 		{
-			Name: "UpdatePaymentStatus",
+			Name:      "UpdatePaymentStatus",
+			DependsOn: []string{"RecordCallback"},
 			Execute: func(ctx context.Context, executeData string) (string, error) {
 				return s.executeUpdatePaymentStatus(ctx, payment, callbackData)
 			},
@@ -94,7 +95,8 @@ func (s *CallbackSagaService) ExecuteCallbackSaga(
 			Timeout:       10 * time.Second,
 		},
 		{
-			Name: "UpdateOrderStatus",
+			Name:      "UpdateOrderStatus",
+			DependsOn: []string{"UpdatePaymentStatus"},
 			Execute: func(ctx context.Context, executeData string) (string, error) {
 				return s.executeUpdateOrderStatus(ctx, payment, callbackData)
 			},
@@ -105,7 +107,8 @@ func (s *CallbackSagaService) ExecuteCallbackSaga(
 			Timeout:       30 * time.Second,
 		},
 		{
-			Name: "PublishEvent",
+			Name:      "PublishEvent",
+			DependsOn: []string{"UpdatePaymentStatus"},
 			Execute: func(ctx context.Context, executeData string) (string, error) {
 				return s.executePublishEvent(ctx, payment, callbackData)
 			},
@@ -116,10 +119,60 @@ func (s *CallbackSagaService) ExecuteCallbackSaga(
 			Timeout:       10 * time.Second,
 		},
 	}
+}
+
+// recoverStepDefinitions 恢复时根据 payment_no 和元数据中的回调数据重建步骤定义
+func (s *CallbackSagaService) recoverStepDefinitions(ctx context.Context, sagaInstance *saga.Saga) ([]saga.StepDefinition, error) {
+	payment, err := s.paymentRepo.GetByPaymentNo(ctx, sagaInstance.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("payment not found: %s", sagaInstance.BusinessID)
+	}
+
+	var metadata callbackMetadata
+	if err := json.Unmarshal([]byte(sagaInstance.Metadata), &metadata); err != nil {
+		return nil, fmt.Errorf("invalid callback saga metadata: %w", err)
+	}
+
+	callbackData := &CallbackData{
+		PaymentNo:      payment.PaymentNo,
+		ChannelOrderNo: metadata.ChannelOrderNo,
+		Status:         metadata.Status,
+		PaidAt:         metadata.PaidAt,
+		FailureReason:  metadata.FailureReason,
+		RawData:        metadata.RawData,
+	}
+	return s.stepDefinitions(payment, callbackData), nil
+}
+
+// ExecuteCallbackSaga 执行支付回调 Saga
+func (s *CallbackSagaService) ExecuteCallbackSaga(
+	ctx context.Context,
+	payment *model.Payment,
+	callbackData *CallbackData,
+) error {
+	// 1. 构建 Saga
+	sagaBuilder := s.orchestrator.NewSagaBuilder(payment.PaymentNo, SagaTypePaymentCallback)
+	sagaBuilder.SetMetadata(map[string]interface{}{
+		"payment_no":       payment.PaymentNo,
+		"merchant_id":      payment.MerchantID.String(),
+		"channel_order_no": callbackData.ChannelOrderNo,
+		"status":           callbackData.Status,
+		"paid_at":          callbackData.PaidAt,
+		"failure_reason":   callbackData.FailureReason,
+		"raw_data":         callbackData.RawData,
+		"amount":           payment.Amount,
+		"currency":         payment.Currency,
+	})
+
+	// 2. 定义步骤
+	stepDefs := s.stepDefinitions(payment, callbackData)
 
-	// 添加步骤到构建器
+	// 添加步骤到构建器（保留依赖关系）
 	for _, def := range stepDefs {
-		sagaBuilder.AddStepWithTimeout(def.Name, def.Execute, def.Compensate, def.MaxRetryCount, def.Timeout)
+		sagaBuilder.AddStepDefinition(def)
 	}
 
 	// 3. 构建并执行 Saga
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/events"
+	"github.com/payment-platform/pkg/kafka"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"payment-platform/payment-gateway/internal/model"
+	"payment-platform/payment-gateway/internal/repository"
+)
+
+// MarketplaceService 平台分账服务（子商户管理、分账计算与按比例冲正）
+type MarketplaceService interface {
+	// 子商户管理
+	CreateConnectedAccount(ctx context.Context, platformMerchantID uuid.UUID, input *ConnectedAccountInput) (*model.ConnectedAccount, error)
+	ListConnectedAccounts(ctx context.Context, platformMerchantID uuid.UUID) ([]*model.ConnectedAccount, error)
+	UpdateConnectedAccountStatus(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID, status string) error
+
+	// BuildSplits 校验收款方并计算分账明细（创建支付时调用，明细随支付记录一起保存）
+	BuildSplits(ctx context.Context, payment *model.Payment, splits []SplitInput, platformFee int64) ([]*model.PaymentSplit, error)
+	// GetPaymentSplits 查询支付的分账与冲正明细
+	GetPaymentSplits(ctx context.Context, merchantID uuid.UUID, paymentNo string) (*PaymentSplitDetail, error)
+	// SplitPostings 支付成功时各收款方的入账明细，非分账支付返回 nil
+	SplitPostings(ctx context.Context, paymentNo string) ([]events.SplitPosting, error)
+	// ReverseSplits 按各收款方剩余分账比例冲正（同一退款/拒付单号重复调用返回已有结果），非分账支付返回 nil
+	ReverseSplits(ctx context.Context, paymentNo, referenceType, referenceNo string, amount int64) ([]events.SplitPosting, error)
+	// ReverseDisputeSplits 拒付败诉时冲正分账并发布拒付事件（由 dispute-service 调用）
+	ReverseDisputeSplits(ctx context.Context, paymentNo, disputeNo string, amount int64) ([]events.SplitPosting, error)
+}
+
+// ConnectedAccountInput 添加子商户输入
+type ConnectedAccountInput struct {
+	SubMerchantID uuid.UUID `json:"sub_merchant_id" binding:"required"`
+	Name          string    `json:"name"`
+}
+
+// SplitInput 单个子商户的分账规则
+type SplitInput struct {
+	RecipientID uuid.UUID `json:"recipient_id" binding:"required"`                // 子商户ID（须已添加为平台商户的子商户）
+	Type        string    `json:"type" binding:"required,oneof=fixed percentage"` // fixed: 固定金额；percentage: 按比例
+	Value       int64     `json:"value" binding:"required,gt=0"`                  // fixed 为金额（分），percentage 为万分比（1000 = 10%）
+}
+
+// PaymentSplitDetail 支付分账详情
+type PaymentSplitDetail struct {
+	PaymentNo string                        `json:"payment_no"`
+	Amount    int64                         `json:"amount"`
+	Currency  string                        `json:"currency"`
+	Splits    []*model.PaymentSplit         `json:"splits"`
+	Reversals []*model.PaymentSplitReversal `json:"reversals"`
+}
+
+type marketplaceService struct {
+	paymentRepo     repository.PaymentRepository
+	marketplaceRepo repository.MarketplaceRepository
+	eventPublisher  *kafka.EventPublisher
+}
+
+// NewMarketplaceService 创建平台分账服务
+func NewMarketplaceService(
+	paymentRepo repository.PaymentRepository,
+	marketplaceRepo repository.MarketplaceRepository,
+	eventPublisher *kafka.EventPublisher,
+) MarketplaceService {
+	return &marketplaceService{
+		paymentRepo:     paymentRepo,
+		marketplaceRepo: marketplaceRepo,
+		eventPublisher:  eventPublisher,
+	}
+}
+
+// CreateConnectedAccount 添加子商户
+func (s *marketplaceService) CreateConnectedAccount(ctx context.Context, platformMerchantID uuid.UUID, input *ConnectedAccountInput) (*model.ConnectedAccount, error) {
+	if input.SubMerchantID == platformMerchantID {
+		return nil, pkgerrors.NewInvalidRequestError("子商户不能是平台商户本身")
+	}
+
+	existing, err := s.marketplaceRepo.GetConnectedAccount(ctx, platformMerchantID, input.SubMerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, pkgerrors.NewBusinessError(pkgerrors.ErrCodeBadRequest, "该子商户已添加")
+	}
+
+	account := &model.ConnectedAccount{
+		PlatformMerchantID: platformMerchantID,
+		SubMerchantID:      input.SubMerchantID,
+		Name:               input.Name,
+		Status:             model.ConnectedAccountStatusActive,
+	}
+	if err := s.marketplaceRepo.CreateConnectedAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	logger.Info("connected account created",
+		zap.String("platform_merchant_id", platformMerchantID.String()),
+		zap.String("sub_merchant_id", input.SubMerchantID.String()))
+	return account, nil
+}
+
+// ListConnectedAccounts 查询子商户列表
+func (s *marketplaceService) ListConnectedAccounts(ctx context.Context, platformMerchantID uuid.UUID) ([]*model.ConnectedAccount, error) {
+	return s.marketplaceRepo.ListConnectedAccounts(ctx, platformMerchantID)
+}
+
+// UpdateConnectedAccountStatus 启用或暂停子商户（暂停后不能再作为新支付的分账收款方，已有分账不受影响）
+func (s *marketplaceService) UpdateConnectedAccountStatus(ctx context.Context, platformMerchantID, subMerchantID uuid.UUID, status string) error {
+	if status != model.ConnectedAccountStatusActive && status != model.ConnectedAccountStatusSuspended {
+		return pkgerrors.NewInvalidRequestError("不支持的子商户状态: " + status)
+	}
+	err := s.marketplaceRepo.UpdateConnectedAccountStatus(ctx, platformMerchantID, subMerchantID, status)
+	if err == gorm.ErrRecordNotFound {
+		return pkgerrors.NewNotFoundError("子商户不存在")
+	}
+	return err
+}
+
+// BuildSplits 校验收款方并计算分账明细
+func (s *marketplaceService) BuildSplits(ctx context.Context, payment *model.Payment, splits []SplitInput, platformFee int64) ([]*model.PaymentSplit, error) {
+	rules := make([]model.SplitRule, 0, len(splits))
+	for _, split := range splits {
+		account, err := s.marketplaceRepo.GetConnectedAccount(ctx, payment.MerchantID, split.RecipientID)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, pkgerrors.NewInvalidRequestError("分账收款方不是该平台商户的子商户: " + split.RecipientID.String())
+		}
+		if account.Status != model.ConnectedAccountStatusActive {
+			return nil, pkgerrors.NewInvalidRequestError("分账收款方已暂停: " + split.RecipientID.String())
+		}
+		rules = append(rules, model.SplitRule{RecipientID: split.RecipientID, Type: split.Type, Value: split.Value})
+	}
+
+	result, err := model.AllocateSplits(payment.MerchantID, payment.Amount, payment.Currency, rules, platformFee)
+	if err != nil {
+		return nil, pkgerrors.NewInvalidRequestError(err.Error())
+	}
+	for _, split := range result {
+		split.PaymentNo = payment.PaymentNo
+	}
+	return result, nil
+}
+
+// GetPaymentSplits 查询支付的分账与冲正明细
+func (s *marketplaceService) GetPaymentSplits(ctx context.Context, merchantID uuid.UUID, paymentNo string) (*PaymentSplitDetail, error) {
+	payment, err := s.paymentRepo.GetByPaymentNo(ctx, paymentNo)
+	if err != nil || payment == nil {
+		return nil, pkgerrors.NewNotFoundError("支付记录不存在")
+	}
+	if payment.MerchantID != merchantID {
+		return nil, pkgerrors.NewNotFoundError("支付记录不存在")
+	}
+
+	splits, err := s.marketplaceRepo.ListSplits(ctx, paymentNo)
+	if err != nil {
+		return nil, err
+	}
+	reversals, err := s.marketplaceRepo.ListReversals(ctx, paymentNo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentSplitDetail{
+		PaymentNo: payment.PaymentNo,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Splits:    splits,
+		Reversals: reversals,
+	}, nil
+}
+
+// SplitPostings 支付成功时各收款方的入账明细
+func (s *marketplaceService) SplitPostings(ctx context.Context, paymentNo string) ([]events.SplitPosting, error) {
+	splits, err := s.marketplaceRepo.ListSplits(ctx, paymentNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	postings := make([]events.SplitPosting, 0, len(splits))
+	for _, split := range splits {
+		if split.Amount == 0 {
+			continue
+		}
+		postings = append(postings, events.SplitPosting{
+			MerchantID: split.RecipientID.String(),
+			Role:       split.Role,
+			Amount:     split.Amount,
+		})
+	}
+	return postings, nil
+}
+
+// ReverseSplits 按各收款方剩余分账比例冲正
+func (s *marketplaceService) ReverseSplits(ctx context.Context, paymentNo, referenceType, referenceNo string, amount int64) ([]events.SplitPosting, error) {
+	// 幂等：同一退款/拒付单号只冲正一次
+	existing, err := s.marketplaceRepo.ListReversalsByReference(ctx, referenceType, referenceNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return reversalPostings(existing), nil
+	}
+
+	splits, err := s.marketplaceRepo.ListSplits(ctx, paymentNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	shares, err := model.AllocateReversal(splits, amount)
+	if err != nil {
+		return nil, pkgerrors.NewInvalidRequestError(err.Error())
+	}
+
+	reversals := make([]*model.PaymentSplitReversal, 0, len(splits))
+	for i, split := range splits {
+		if shares[i] == 0 {
+			continue
+		}
+		reversals = append(reversals, &model.PaymentSplitReversal{
+			PaymentNo:     paymentNo,
+			ReferenceType: referenceType,
+			ReferenceNo:   referenceNo,
+			RecipientID:   split.RecipientID,
+			Role:          split.Role,
+			Amount:        shares[i],
+			Currency:      split.Currency,
+		})
+	}
+	if err := s.marketplaceRepo.CreateReversals(ctx, reversals); err != nil {
+		return nil, err
+	}
+
+	logger.Info("payment splits reversed",
+		zap.String("payment_no", paymentNo),
+		zap.String("reference_type", referenceType),
+		zap.String("reference_no", referenceNo),
+		zap.Int64("amount", amount),
+		zap.Int("recipients", len(reversals)))
+	return reversalPostings(reversals), nil
+}
+
+// ReverseDisputeSplits 拒付败诉时冲正分账并发布拒付事件
+func (s *marketplaceService) ReverseDisputeSplits(ctx context.Context, paymentNo, disputeNo string, amount int64) ([]events.SplitPosting, error) {
+	payment, err := s.paymentRepo.GetByPaymentNo(ctx, paymentNo)
+	if err != nil || payment == nil {
+		return nil, pkgerrors.NewNotFoundError("支付记录不存在")
+	}
+
+	postings, err := s.ReverseSplits(ctx, paymentNo, model.SplitReversalDispute, disputeNo, amount)
+	if err != nil {
+		return nil, err
+	}
+	// 非分账支付的拒付不在此处记账
+	if len(postings) == 0 || s.eventPublisher == nil {
+		return postings, nil
+	}
+
+	event := events.NewPaymentEvent(events.PaymentChargeback, events.PaymentEventPayload{
+		PaymentNo:  payment.PaymentNo,
+		MerchantID: payment.MerchantID.String(),
+		OrderNo:    payment.OrderNo,
+		Amount:     amount,
+		Currency:   payment.Currency,
+		Channel:    payment.Channel,
+		PayMethod:  payment.PayMethod,
+		Country:    payment.Country,
+		Status:     payment.Status,
+		PaidAt:     payment.PaidAt,
+		Splits:     postings,
+		Extra: map[string]interface{}{
+			"dispute_no": disputeNo,
+		},
+	})
+	event.AddMetadata("service", "payment-gateway")
+
+	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := s.eventPublisher.Publish(publishCtx, events.TopicPaymentEvents, event); err != nil {
+		// 冲正明细已保存，dispute-service 重试时按拒付单号幂等返回并重新发布
+		return nil, err
+	}
+	return postings, nil
+}
+
+// reversalPostings 冲正明细转换为记账明细
+func reversalPostings(reversals []*model.PaymentSplitReversal) []events.SplitPosting {
+	postings := make([]events.SplitPosting, 0, len(reversals))
+	for _, reversal := range reversals {
+		postings = append(postings, events.SplitPosting{
+			MerchantID: reversal.RecipientID.String(),
+			Role:       reversal.Role,
+			Amount:     reversal.Amount,
+		})
+	}
+	return postings
+}
@@ -66,7 +66,9 @@ type NotificationMessage struct {
 	MerchantID      string                 `json:"merchant_id"`       // 商户ID
 	NotifyURL       string                 `json:"notify_url"`        // 通知URL
 	NotifyData      map[string]interface{} `json:"notify_data"`       // 通知数据
-	Signature       string                 `json:"signature"`         // 签名
+	EventID         string                 `json:"event_id"`          // 事件ID（重试时不变）
+	APIVersion      string                 `json:"api_version"`       // 商户固定的负载版本
+	Payload         string                 `json:"payload"`           // 事件信封（原样作为请求体发送，每次投递时按商户当前密钥用 webhook.SignedHeaders 签名）
 	RetryCount      int                    `json:"retry_count"`       // 重试次数
 	MaxRetries      int                    `json:"max_retries"`       // 最大重试次数（默认5次）
 	NextRetryTime   time.Time              `json:"next_retry_time"`   // 下次重试时间
@@ -10,13 +10,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/decline"
 	"github.com/payment-platform/pkg/events"
 	"github.com/payment-platform/pkg/idempotent"
 	"github.com/payment-platform/pkg/kafka"
 	"github.com/payment-platform/pkg/logger"
 	"github.com/payment-platform/pkg/metrics"
+	"github.com/payment-platform/pkg/money"
 	"github.com/payment-platform/pkg/router"
+	"github.com/payment-platform/pkg/sca"
 	"github.com/payment-platform/pkg/tracing"
+	"github.com/payment-platform/pkg/webhook"
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -36,6 +40,7 @@ type PaymentService interface {
 	QueryPayment(ctx context.Context, query *repository.PaymentQuery) ([]*model.Payment, int64, error)
 	BatchGetPayments(ctx context.Context, paymentNos []string, merchantID uuid.UUID) (map[string]*model.Payment, []string, error)
 	CancelPayment(ctx context.Context, paymentNo string, reason string) error
+	CompleteAuthentication(ctx context.Context, paymentNo string) (*model.Payment, error)
 
 	// 回调处理
 	HandleCallback(ctx context.Context, channel string, data map[string]interface{}) error
@@ -68,6 +73,13 @@ type paymentService struct {
 	refundSagaService   *RefundSagaService    // Refund Saga 分布式事务服务
 	callbackSagaService *CallbackSagaService  // Callback Saga 分布式事务服务
 	routerService       *router.RouterService // 智能路由服务
+	cascade             router.CascadeConfig  // 软拒绝级联重试配置
+	receiptService      ReceiptService        // 电子收据服务（用于在事件中附带收据链接）
+	marketplaceService  MarketplaceService    // 平台分账服务（分账支付、退款按比例冲正）
+	cashierClient       client.CashierClient  // 收银台服务（商户 3DS 开关）
+	merchantConfig      client.MerchantConfigClient // 商户配置服务（Webhook 固定的负载版本）
+	scaPolicy           sca.Policy            // 3DS 豁免与挑战策略
+	scaReturnBaseURL    string                // 3DS 返回地址基础URL
 }
 
 // NewPaymentService 创建支付服务实例
@@ -104,6 +116,7 @@ func NewPaymentService(
 		refundSagaService:   nil, // 通过 setter 注入
 		callbackSagaService: nil, // 通过 setter 注入
 		routerService:       nil, // 通过 setter 注入
+		scaPolicy:           sca.DefaultPolicy(),
 	}
 }
 
@@ -122,6 +135,37 @@ func (s *paymentService) SetRouterService(routerService *router.RouterService) {
 	s.routerService = routerService
 }
 
+// SetCascadeConfig 设置级联重试配置
+func (s *paymentService) SetCascadeConfig(cascade router.CascadeConfig) {
+	s.cascade = cascade
+}
+
+// SetReceiptService 设置电子收据服务（依赖注入）
+func (s *paymentService) SetReceiptService(receiptService ReceiptService) {
+	s.receiptService = receiptService
+}
+
+// SetMarketplaceService 设置平台分账服务（依赖注入）
+func (s *paymentService) SetMarketplaceService(marketplaceService MarketplaceService) {
+	s.marketplaceService = marketplaceService
+}
+
+// SetCashierClient 设置收银台服务客户端（依赖注入，用于读取商户 3DS 开关）
+func (s *paymentService) SetCashierClient(cashierClient client.CashierClient) {
+	s.cashierClient = cashierClient
+}
+
+// SetMerchantConfigClient 设置商户配置服务客户端（依赖注入，用于读取商户固定的 Webhook 负载版本）
+func (s *paymentService) SetMerchantConfigClient(merchantConfigClient client.MerchantConfigClient) {
+	s.merchantConfig = merchantConfigClient
+}
+
+// SetSCAConfig 设置 3DS 豁免与挑战策略，returnBaseURL 为消费者浏览器可访问的 3DS 返回地址前缀（为空时使用 webhookBaseURL）
+func (s *paymentService) SetSCAConfig(policy sca.Policy, returnBaseURL string) {
+	s.scaPolicy = policy
+	s.scaReturnBaseURL = returnBaseURL
+}
+
 // CreatePaymentInput 创建支付输入
 type CreatePaymentInput struct {
 	MerchantID    uuid.UUID `json:"merchant_id" binding:"required"`
@@ -140,6 +184,9 @@ type CreatePaymentInput struct {
 	ExpireMinutes int       `json:"expire_minutes"`                      // 过期时间（分钟，默认30分钟）
 	Extra         map[string]interface{} `json:"extra"`                // 扩展信息
 	Language      string    `json:"language"`                            // 语言（en, zh-CN, zh-TW, ja等）
+	FXQuoteID     string    `json:"fx_quote_id"`                         // 换汇报价编号（可选，按锁定汇率结算）
+	Splits        []SplitInput `json:"splits" binding:"omitempty,dive"`  // 分账规则（可选，平台商户按子商户拆分收款）
+	PlatformFee   int64     `json:"platform_fee" binding:"gte=0"`        // 平台费（分，可选，分账后平台至少保留的金额）
 }
 
 // CreateRefundInput 创建退款输入
@@ -237,6 +284,8 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 	}
 
 	// 2. 风控检查（在事务外执行，减少事务持有时间）
+	var customerCountry string
+	riskScore := -1 // 未取得风控评分时不申请 3DS 豁免
 	if s.riskClient != nil {
 		// 创建 span 追踪风控检查
 		ctx, riskSpan := tracing.StartSpan(ctx, "payment-gateway", "RiskCheck")
@@ -268,6 +317,11 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 				zap.Int64("amount", input.Amount),
 				zap.String("currency", input.Currency))
 		} else if riskResult != nil {
+			// 记录风控返回的 GeoIP 国家，用于统计分析维度
+			if countryCode, ok := riskResult.Extra["geo_country_code"].(string); ok {
+				customerCountry = strings.ToUpper(countryCode)
+			}
+			riskScore = riskResult.Score
 			riskSpan.SetAttributes(
 				attribute.String("risk.decision", riskResult.Decision),
 				attribute.Int("risk.score", riskResult.Score),
@@ -332,6 +386,7 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 		CustomerName:  input.CustomerName,
 		CustomerPhone: input.CustomerPhone,
 		CustomerIP:    input.CustomerIP,
+		Country:       customerCountry,
 		Description:   input.Description,
 		NotifyURL:     input.NotifyURL,
 		ReturnURL:     input.ReturnURL,
@@ -341,8 +396,39 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 		NotifyTimes:   0,
 	}
 
-	// 7. 选择支付渠道
-	if input.Channel != "" {
+	// 6.1 锁定换汇报价（可选）：按报价汇率确定结算币种金额，报价过期或已被使用时拒绝创建
+	if input.FXQuoteID != "" {
+		if err := s.applyFXQuote(ctx, payment, input.FXQuoteID); err != nil {
+			finalStatus = "failed"
+			return nil, err
+		}
+	}
+	// 支付记录落库前任一步骤失败时释放已锁定的报价，避免报价被未创建的支付占用
+	paymentPersisted := false
+	defer func() {
+		if payment.FXQuoteID != "" && !paymentPersisted {
+			s.releaseFXQuote(ctx, payment.MerchantID, payment.FXQuoteID, payment.PaymentNo)
+		}
+	}()
+
+	// 6.2 计算分账明细（可选）：收款方须为平台商户下启用的子商户，剩余金额归平台
+	var splits []*model.PaymentSplit
+	if len(input.Splits) > 0 {
+		if s.marketplaceService == nil {
+			finalStatus = "failed"
+			return nil, fmt.Errorf("分账服务未启用")
+		}
+		splits, err = s.marketplaceService.BuildSplits(ctx, payment, input.Splits, input.PlatformFee)
+		if err != nil {
+			finalStatus = "failed"
+			return nil, err
+		}
+	}
+
+	// 7. 选择支付渠道（商户未指定渠道时由路由选择，仅此时允许级联重试）
+	routed := input.Channel == ""
+	routingReason := "商户指定渠道"
+	if !routed {
 		payment.Channel = input.Channel
 	} else {
 		channel, err := s.SelectChannel(ctx, payment)
@@ -351,6 +437,7 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 			return nil, fmt.Errorf("选择支付渠道失败: %w", err)
 		}
 		payment.Channel = channel
+		routingReason = "路由选择"
 	}
 
 	// 记录最终选择的渠道
@@ -377,6 +464,13 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 			return fmt.Errorf("创建支付记录失败: %w", err)
 		}
 
+		// 8.3 保存分账明细
+		if len(splits) > 0 {
+			if err := tx.Create(&splits).Error; err != nil {
+				return fmt.Errorf("保存分账明细失败: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -385,6 +479,7 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 		}
 		return nil, err
 	}
+	paymentPersisted = true
 
 	// 10. 调用Order-Service创建订单（事务外，使用补偿机制）
 	var orderCreated bool
@@ -447,30 +542,84 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 			}
 		}
 
-		channelResult, err := s.channelClient.CreatePayment(ctx, &client.CreatePaymentRequest{
-			PaymentNo:     payment.PaymentNo,
-			MerchantID:    payment.MerchantID.String(),
-			Channel:       payment.Channel,
-			Amount:        payment.Amount,
-			Currency:      payment.Currency,
-			PayMethod:     payment.PayMethod,
-			CustomerEmail: payment.CustomerEmail,
-			CustomerName:  payment.CustomerName,
-			Description:   payment.Description,
-			ReturnURL:     payment.ReturnURL,
-			NotifyURL:     fmt.Sprintf("%s/api/v1/webhooks/%s", s.webhookBaseURL, payment.Channel),
-			Extra:         extraMap,
-		})
+		// 商户启用 3DS 时按风险评分决定挑战或豁免
+		threeDS := s.threeDSRequest(ctx, payment, riskScore)
+
+		// 11.1 智能路由选择的渠道遇到软拒绝时，在同一笔支付内级联到次优渠道
+		var channelResult *client.PaymentResult
+		var err error
+		var tried []string
+		for {
+			channelStart := time.Now()
+			channelResult, err = s.channelClient.CreatePayment(ctx, &client.CreatePaymentRequest{
+				PaymentNo:     payment.PaymentNo,
+				MerchantID:    payment.MerchantID.String(),
+				Channel:       payment.Channel,
+				Amount:        payment.Amount,
+				Currency:      payment.Currency,
+				PayMethod:     payment.PayMethod,
+				CustomerEmail: payment.CustomerEmail,
+				CustomerName:  payment.CustomerName,
+				Description:   payment.Description,
+				ReturnURL:     payment.ReturnURL,
+				NotifyURL:     fmt.Sprintf("%s/api/v1/webhooks/%s", s.webhookBaseURL, payment.Channel),
+				ThreeDS:       threeDS,
+				Extra:         extraMap,
+			})
+			payment.ChannelLatencyMs = time.Since(channelStart).Milliseconds()
+			tried = append(tried, payment.Channel)
+
+			attempt := model.PaymentAttempt{
+				Channel:   payment.Channel,
+				Reason:    routingReason,
+				Status:    model.AttemptStatusAccepted,
+				LatencyMs: payment.ChannelLatencyMs,
+				At:        channelStart,
+			}
+			if err == nil {
+				payment.RoutingAttempts = append(payment.RoutingAttempts, attempt)
+				break
+			}
+
+			d := channelDecline(payment.Channel, err)
+			attempt.Status = model.AttemptStatusFailed
+			attempt.ErrorMsg = err.Error()
+			attempt.Decline = string(d.Category)
+			s.recordRoutingOutcome(payment, false)
+
+			var next *router.RoutingResult
+			if routed && s.cascade.Enabled && len(tried) < s.cascade.MaxAttempts && router.IsDeclineCascadeEligible(d) {
+				next = s.selectCascadeChannel(ctx, payment, tried)
+			}
+			attempt.Cascade = next != nil
+			payment.RoutingAttempts = append(payment.RoutingAttempts, attempt)
+			if next == nil {
+				break
+			}
+
+			logger.Warn("渠道软拒绝，级联到次优渠道",
+				zap.String("payment_no", payment.PaymentNo),
+				zap.String("failed_channel", payment.Channel),
+				zap.String("next_channel", next.Channel),
+				zap.Int("attempt", len(tried)+1),
+				zap.Error(err))
+			payment.Channel = next.Channel
+			routingReason = next.Reason
+			finalChannel = next.Channel
+		}
 		if err != nil {
 			// 渠道调用失败，更新支付状态为失败
 			payment.Status = model.PaymentStatusFailed
 			payment.ErrorMsg = fmt.Sprintf("发起支付失败: %v", err)
+			payment.SetDecline(channelDecline(payment.Channel, err))
 			if updateErr := s.paymentRepo.Update(ctx, payment); updateErr != nil {
 				logger.Error("failed to update payment status after channel payment failed",
 					zap.Error(updateErr),
 					zap.String("payment_no", payment.PaymentNo),
 					zap.String("channel", payment.Channel))
 			}
+			// 同步拒绝同样发布支付失败事件，供分析服务统计拒绝原因
+			s.publishPaymentStatusEvent(payment, model.PaymentStatusPending, payment.Channel)
 
 			// 如果订单已创建，发送补偿消息到消息队列
 			if orderCreated && s.messageService != nil {
@@ -494,12 +643,18 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 			}
 
 			finalStatus = "failed"
-			return nil, fmt.Errorf("发起支付失败: %w", err)
+			return nil, decline.Wrap(payment.Decline(), fmt.Errorf("发起支付失败: %w", err))
 		}
 
 		// 在事务中更新支付记录（包括渠道订单号）
 		payment.ChannelOrderNo = channelResult.ChannelOrderNo
 		payment.Status = model.PaymentStatusProcessing
+		if channelResult.Status == model.PaymentStatusRequiresAction {
+			// 发卡行要求挑战，等待消费者完成验证后通过 3DS 返回/完成接口继续支付
+			payment.Status = model.PaymentStatusRequiresAction
+			payment.ThreeDSStatus = model.ThreeDSStatusRequired
+		}
+		payment.SetAuthentication(channelResult.Authentication)
 		if err := s.paymentRepo.Update(ctx, payment); err != nil {
 			logger.Error("failed to update payment record after channel success",
 				zap.Error(err),
@@ -514,6 +669,9 @@ func (s *paymentService) CreatePayment(ctx context.Context, input *CreatePayment
 		}
 		extraMap["payment_url"] = channelResult.PaymentURL
 		extraMap["qr_code_url"] = channelResult.QRCodeURL
+		if channelResult.NextAction != nil {
+			extraMap["next_action"] = channelResult.NextAction
+		}
 		extraBytes, err := json.Marshal(extraMap)
 		if err != nil {
 			logger.Error("failed to marshal extra data with payment URL",
@@ -628,8 +786,9 @@ func (s *paymentService) CancelPayment(ctx context.Context, paymentNo string, re
 		return err
 	}
 
-	// 只有pending或processing状态的支付可以取消
-	if payment.Status != model.PaymentStatusPending && payment.Status != model.PaymentStatusProcessing {
+	// 只有pending、processing或等待3DS验证的支付可以取消
+	if payment.Status != model.PaymentStatusPending && payment.Status != model.PaymentStatusProcessing &&
+		payment.Status != model.PaymentStatusRequiresAction {
 		return fmt.Errorf("当前状态不允许取消: %s", payment.Status)
 	}
 
@@ -640,6 +799,140 @@ func (s *paymentService) CancelPayment(ctx context.Context, paymentNo string, re
 	return s.paymentRepo.Update(ctx, payment)
 }
 
+// CompleteAuthentication 消费者完成 3DS 挑战后继续支付
+//
+// 由 3DS 返回地址（渠道跳转）或商户在前端 SDK 完成挑战后调用；重复调用时直接返回当前支付状态。
+// 认证成功或失败的最终状态沿用回调处理流程（更新订单、发布事件、通知商户），渠道随后的 Webhook 按重复回调处理。
+func (s *paymentService) CompleteAuthentication(ctx context.Context, paymentNo string) (*model.Payment, error) {
+	payment, err := s.GetPayment(ctx, paymentNo)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != model.PaymentStatusRequiresAction {
+		if payment.ThreeDSStatus != "" {
+			return payment, nil
+		}
+		return nil, fmt.Errorf("当前状态不需要 3DS 验证: %s", payment.Status)
+	}
+	if s.channelClient == nil {
+		return nil, fmt.Errorf("渠道服务未配置")
+	}
+
+	result, err := s.channelClient.CompleteAuthentication(ctx, paymentNo)
+	if err != nil {
+		d, ok := decline.FromError(err)
+		if !ok {
+			return nil, err
+		}
+		result = &client.AuthenticationResult{Status: model.PaymentStatusFailed, Decline: d}
+	}
+
+	payment.SetAuthentication(result.Authentication)
+	if result.ChannelTradeNo != "" && payment.ChannelOrderNo == "" {
+		payment.ChannelOrderNo = result.ChannelTradeNo
+	}
+
+	callbackData := map[string]interface{}{
+		"payment_no":       payment.PaymentNo,
+		"channel_order_no": payment.ChannelOrderNo,
+		"status":           result.Status,
+	}
+	switch result.Status {
+	case model.PaymentStatusSuccess:
+		// 认证通过且渠道已完成授权
+	case model.PaymentStatusFailed:
+		d := result.Decline
+		if d == nil {
+			d = decline.New(decline.CategoryAuthenticationRequired, payment.Channel, "", "3DS 验证未通过")
+		}
+		payment.SetDecline(d)
+		payment.ErrorMsg = "3DS 验证失败"
+		if d.ChannelMessage != "" {
+			payment.ErrorMsg = fmt.Sprintf("3DS 验证失败: %s", d.ChannelMessage)
+		}
+		callbackData["error_code"] = payment.ErrorCode
+		callbackData["error_msg"] = payment.ErrorMsg
+		callbackData["decline"] = d
+	case model.PaymentStatusRequiresAction:
+		// 发卡行要求再次挑战
+		if result.Authentication == nil {
+			payment.ThreeDSStatus = model.ThreeDSStatusRequired
+		}
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return nil, fmt.Errorf("更新支付记录失败: %w", err)
+		}
+		attachNextAction(payment, result.NextAction)
+		return payment, nil
+	default:
+		// 渠道仍在处理（如异步授权），最终结果由 Webhook 回调更新
+		payment.Status = model.PaymentStatusProcessing
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return nil, fmt.Errorf("更新支付记录失败: %w", err)
+		}
+		return payment, nil
+	}
+
+	// 先保存认证结果和拒绝原因，再按回调流程更新最终状态
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		return nil, fmt.Errorf("更新支付记录失败: %w", err)
+	}
+	if err := s.HandleCallback(ctx, payment.Channel, callbackData); err != nil {
+		return nil, err
+	}
+
+	logger.Info("3DS 验证完成",
+		zap.String("payment_no", payment.PaymentNo),
+		zap.String("status", result.Status),
+		zap.String("three_ds_status", payment.ThreeDSStatus),
+		zap.Bool("liability_shift", payment.LiabilityShift))
+	return s.GetPayment(ctx, paymentNo)
+}
+
+// threeDSRequest 商户启用 3DS 时按风险评分生成本次支付的 3DS 要求，未启用或非卡支付返回 nil
+func (s *paymentService) threeDSRequest(ctx context.Context, payment *model.Payment, riskScore int) *sca.Request {
+	if s.cashierClient == nil || (payment.PayMethod != "" && payment.PayMethod != "card") {
+		return nil
+	}
+	settings, err := s.cashierClient.GetSecuritySettings(ctx, payment.MerchantID)
+	if err != nil {
+		// 收银台服务不可用时不阻塞支付，由渠道/发卡行按需要求挑战
+		logger.Warn("failed to get merchant security settings, skip 3DS request",
+			zap.Error(err),
+			zap.String("payment_no", payment.PaymentNo),
+			zap.String("merchant_id", payment.MerchantID.String()))
+		return nil
+	}
+	if !settings.Enable3DSecure {
+		return nil
+	}
+
+	req := s.scaPolicy.Decide(payment.Amount, payment.Currency, riskScore)
+	baseURL := s.scaReturnBaseURL
+	if baseURL == "" {
+		baseURL = s.webhookBaseURL
+	}
+	req.ReturnURL = fmt.Sprintf("%s/api/v1/payments/%s/3ds/return", baseURL, payment.PaymentNo)
+	payment.SCAExemption = string(req.Exemption)
+	return req
+}
+
+// attachNextAction 将 3DS 挑战动作附加到返回的扩展信息中（不落库）
+func attachNextAction(payment *model.Payment, action *sca.NextAction) {
+	if action == nil {
+		return
+	}
+	extraMap := make(map[string]interface{})
+	if payment.Extra != "" {
+		if err := json.Unmarshal([]byte(payment.Extra), &extraMap); err != nil {
+			extraMap = make(map[string]interface{})
+		}
+	}
+	extraMap["next_action"] = action
+	if extraBytes, err := json.Marshal(extraMap); err == nil {
+		payment.Extra = string(extraBytes)
+	}
+}
+
 // HandleCallback 处理支付回调（完整流程）
 func (s *paymentService) HandleCallback(ctx context.Context, channel string, data map[string]interface{}) error {
 	// 1. 记录原始回调数据
@@ -672,6 +965,8 @@ func (s *paymentService) HandleCallback(ctx context.Context, channel string, dat
 	if err != nil {
 		return err
 	}
+	// 重复回调不重复计入路由指标
+	alreadyFinal := payment.Status == model.PaymentStatusSuccess || payment.Status == model.PaymentStatusFailed
 
 	// ========== Saga 模式（推荐）==========
 	if s.callbackSagaService != nil {
@@ -716,6 +1011,9 @@ func (s *paymentService) HandleCallback(ctx context.Context, channel string, dat
 
 		logger.Info("Callback Saga 执行成功",
 			zap.String("payment_no", payment.PaymentNo))
+		if !alreadyFinal {
+			s.recordCallbackOutcome(payment, status)
+		}
 		return nil
 	}
 
@@ -773,6 +1071,7 @@ func (s *paymentService) HandleCallback(ctx context.Context, channel string, dat
 		if errorCode, ok := data["error_code"].(string); ok {
 			payment.ErrorCode = errorCode
 		}
+		payment.SetDecline(callbackDecline(channel, data, payment.ErrorCode, payment.ErrorMsg))
 	case "cancelled", "canceled":
 		payment.Status = model.PaymentStatusCancelled
 	default:
@@ -793,6 +1092,10 @@ func (s *paymentService) HandleCallback(ctx context.Context, channel string, dat
 	callback.IsProcessed = true
 	s.paymentRepo.UpdateCallback(ctx, callback)
 
+	if !alreadyFinal {
+		s.recordCallbackOutcome(payment, status)
+	}
+
 	// 12. 通知Order-Service更新订单状态
 	if s.orderClient != nil && oldStatus != payment.Status {
 		s.orderClient.UpdateOrderStatus(ctx, payment.PaymentNo, &client.UpdateOrderStatusRequest{
@@ -833,6 +1136,89 @@ func (s *paymentService) HandleCallback(ctx context.Context, channel string, dat
 	return nil
 }
 
+// selectCascadeChannel 排除已尝试的渠道后重新路由，无可用渠道时返回 nil
+func (s *paymentService) selectCascadeChannel(ctx context.Context, payment *model.Payment, tried []string) *router.RoutingResult {
+	if s.routerService == nil {
+		return nil
+	}
+
+	country := payment.Country
+	if country == "" {
+		country = s.getCountryFromIP(ctx, payment.CustomerIP)
+	}
+	result, err := s.routerService.SelectChannel(ctx, &router.RoutingRequest{
+		MerchantID:      payment.MerchantID.String(),
+		Amount:          payment.Amount,
+		Currency:        payment.Currency,
+		Country:         country,
+		PayMethod:       payment.PayMethod,
+		ExcludeChannels: tried,
+	})
+	if err != nil {
+		logger.Info("没有可级联的渠道",
+			zap.String("payment_no", payment.PaymentNo),
+			zap.Strings("tried_channels", tried),
+			zap.Error(err))
+		return nil
+	}
+	for _, channel := range tried {
+		if channel == result.Channel {
+			return nil
+		}
+	}
+	return result
+}
+
+// recordCallbackOutcome 将回调的最终结果计入路由指标
+func (s *paymentService) recordCallbackOutcome(payment *model.Payment, status string) {
+	switch status {
+	case "success", "paid":
+		s.recordRoutingOutcome(payment, true)
+	case "failed", "error":
+		s.recordRoutingOutcome(payment, false)
+	}
+}
+
+// recordRoutingOutcome 记录渠道结果，实时更新智能路由的成功率与耗时
+func (s *paymentService) recordRoutingOutcome(payment *model.Payment, success bool) {
+	if s.routerService == nil {
+		return
+	}
+	s.routerService.RecordOutcome(&router.PaymentOutcome{
+		Channel:   payment.Channel,
+		Currency:  payment.Currency,
+		Country:   payment.Country,
+		PayMethod: payment.PayMethod,
+		Success:   success,
+		LatencyMs: payment.ChannelLatencyMs,
+		At:        time.Now(),
+	})
+}
+
+// channelDecline 取出渠道错误携带的拒绝原因，channel-adapter 未归一化时（网络错误、熔断等）按错误信息识别
+func channelDecline(channel string, err error) *decline.Decline {
+	if d, ok := decline.FromError(err); ok {
+		return d
+	}
+	return decline.ClassifyMessage(channel, err.Error())
+}
+
+// callbackDecline 解析失败回调中的拒绝原因，回调未携带时按错误码和错误信息识别
+func callbackDecline(channel string, data map[string]interface{}, errorCode, errorMsg string) *decline.Decline {
+	if raw, ok := data["decline"]; ok && raw != nil {
+		if b, err := json.Marshal(raw); err == nil {
+			var d decline.Decline
+			if json.Unmarshal(b, &d) == nil && decline.IsValidCategory(string(d.Category)) {
+				return decline.New(d.Category, channel, d.ChannelCode, d.ChannelMessage)
+			}
+		}
+	}
+	d := decline.ClassifyMessage(channel, strings.TrimSpace(errorCode+" "+errorMsg))
+	d.ChannelCode = errorCode
+	d.ChannelMessage = errorMsg
+	return d
+}
+
 // notifyMerchant 通知商户（异步）
 func (s *paymentService) notifyMerchant(ctx context.Context, payment *model.Payment) {
 	if payment.NotifyURL == "" {
@@ -853,27 +1239,41 @@ func (s *paymentService) notifyMerchant(ctx context.Context, payment *model.Paym
 		"error_code":       payment.ErrorCode,
 		"error_msg":        payment.ErrorMsg,
 	}
+	if d := payment.Decline(); d != nil {
+		notifyData["decline"] = d
+	}
+	if payment.ThreeDSStatus != "" {
+		notifyData["three_ds_status"] = payment.ThreeDSStatus
+		notifyData["liability_shift"] = payment.LiabilityShift
+	}
 
 	// 使用消息队列实现可靠通知和重试机制
 	if s.messageService != nil {
-		// 计算签名（使用商户的API Secret）
-		apiKey, err := s.apiKeyRepo.GetByMerchantID(ctx, payment.MerchantID)
+		// 使用统一的事件信封，负载版本取商户固定的版本，创建时间在此固定
+		// 签名（t=...,v1=...）不随消息保存，由投递方每次发送时按发送时间生成，避免重试超出时间戳容差
+		apiVersion := resolveWebhookAPIVersion(ctx, s.merchantConfig, payment.MerchantID)
+		event, err := webhook.NewEvent("", "payment."+payment.Status, apiVersion, time.Now(), notifyData, nil)
 		if err != nil {
-			logger.Error("failed to get merchant API secret for notification",
+			logger.Error("failed to build notification event",
+				zap.Error(err),
+				zap.String("payment_no", payment.PaymentNo))
+			return
+		}
+		body, err := event.Marshal()
+		if err != nil {
+			logger.Error("failed to marshal notification event",
 				zap.Error(err),
-				zap.String("merchant_id", payment.MerchantID.String()),
 				zap.String("payment_no", payment.PaymentNo))
-			// 无法获取密钥时,不发送通知,避免签名错误
 			return
 		}
-		signature := calculateNotifySignature(notifyData, apiKey.APISecret)
-
 		notifyMsg := &NotificationMessage{
 			PaymentNo:     payment.PaymentNo,
 			MerchantID:    payment.MerchantID.String(),
 			NotifyURL:     payment.NotifyURL,
 			NotifyData:    notifyData,
-			Signature:     signature,
+			EventID:       event.ID,
+			APIVersion:    event.APIVersion,
+			Payload:       string(body),
 			RetryCount:    0,
 			MaxRetries:    5, // 最多重试5次
 			NextRetryTime: time.Now().Add(5 * time.Second),
@@ -1175,6 +1575,13 @@ func (s *paymentService) CreateRefund(ctx context.Context, input *CreateRefundIn
 			// 执行通知
 			s.notifyMerchantRefund(notifyCtx, p, r)
 		}(payment, refund)
+
+		if splits, err := s.reverseRefundSplits(ctx, payment, refund); err != nil {
+			// 冲正失败时不发布不带分账明细的退款事件（财务会按非分账支付整笔冲减平台商户），推迟到冲正成功后再发布
+			s.deferSplitEvent(ctx, &pendingSplitEvent{Kind: splitEventRefund, PaymentNo: payment.PaymentNo, RefundNo: refund.RefundNo}, err)
+		} else {
+			s.publishRefundSuccessEvent(payment, refund, splits)
+		}
 	}
 
 	// 退款成功
@@ -1298,13 +1705,18 @@ func (s *paymentService) SelectChannel(ctx context.Context, payment *model.Payme
 
 	// 优先使用智能路由服务（如果已配置）
 	if s.routerService != nil {
-		// 提取客户国家/地区（从IP地理位置）
-		country := s.getCountryFromIP(ctx, payment.CustomerIP)
+		// 客户国家/地区（风控已返回时直接使用，否则从IP地理位置获取）
+		country := payment.Country
+		if country == "" {
+			country = s.getCountryFromIP(ctx, payment.CustomerIP)
+		}
 
 		routingReq := &router.RoutingRequest{
-			Amount:   payment.Amount,
-			Currency: payment.Currency,
-			Country:  country,
+			MerchantID: payment.MerchantID.String(),
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			Country:    country,
+			PayMethod:  payment.PayMethod,
 		}
 
 		result, err := s.routerService.SelectChannel(ctx, routingReq)
@@ -1346,89 +1758,21 @@ func (s *paymentService) SelectChannel(ctx context.Context, payment *model.Payme
 	return model.ChannelStripe, nil
 }
 
-// matchRoute 匹配路由规则
+// matchRoute 匹配路由规则（智能路由未启用时的降级方案，与路由引擎使用相同的条件语义）
 func (s *paymentService) matchRoute(payment *model.Payment, route *model.PaymentRoute) bool {
-	var conditions map[string]interface{}
-	if err := json.Unmarshal([]byte(route.Conditions), &conditions); err != nil {
+	rule, err := toRoutingRule(route)
+	if err != nil {
 		return false
 	}
-
-	// 金额范围
-	if minAmount, ok := conditions["min_amount"].(float64); ok {
-		if payment.Amount < int64(minAmount) {
-			return false
-		}
-	}
-	if maxAmount, ok := conditions["max_amount"].(float64); ok {
-		if payment.Amount > int64(maxAmount) {
-			return false
-		}
-	}
-
-	// 货币类型
-	if currencies, ok := conditions["currencies"].([]interface{}); ok {
-		matched := false
-		for _, c := range currencies {
-			if currency, ok := c.(string); ok && currency == payment.Currency {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-
-	// 国家/地区
-	if countries, ok := conditions["countries"].([]interface{}); ok && len(countries) > 0 {
-		// 如果有国家限制,需要从payment中获取客户IP并判断国家
-		if payment.CustomerIP != "" {
-			customerCountry := getCountryFromIP(payment.CustomerIP)
-			if customerCountry == "" {
-				// 无法获取国家信息,不匹配此路由
-				return false
-			}
-
-			matched := false
-			for _, c := range countries {
-				if country, ok := c.(string); ok && country == customerCountry {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				return false
-			}
-		} else {
-			// 没有IP信息,无法判断国家,不匹配
-			return false
-		}
-	}
-
-	return true
-}
-
-// getCountryFromIP 根据IP地址获取国家代码
-// 这是一个简化实现,实际生产环境应该使用GeoIP库(如MaxMind GeoLite2)
-func getCountryFromIP(ip string) string {
-	// TODO: 集成 GeoIP 库实现真实的IP地理位置查询
-	// 推荐使用: github.com/oschwald/geoip2-golang
-
-	// 临时实现:根据IP前缀做简单判断(仅供示例)
-	if strings.HasPrefix(ip, "127.") || strings.HasPrefix(ip, "localhost") {
-		return "CN" // 本地测试默认为中国
-	}
-
-	// 生产环境应该:
-	// 1. 使用 GeoIP2 数据库查询
-	// 2. 添加缓存层减少查询开销
-	// 3. 处理IPv6地址
-	// 4. 定期更新GeoIP数据库
-
-	logger.Warn("使用简化的国家判断逻辑,生产环境应集成GeoIP库",
-		zap.String("ip", ip))
-
-	return "" // 无法判断时返回空字符串
+	matched, _ := rule.Match(&router.RoutingRequest{
+		MerchantID:  payment.MerchantID.String(),
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Country:     payment.Country,
+		PayMethod:   payment.PayMethod,
+		RequestTime: time.Now(),
+	})
+	return matched
 }
 
 // 工具函数
@@ -1453,6 +1797,62 @@ func (s *paymentService) generateRefundNo() string {
 	return fmt.Sprintf("RF%s%s", timestamp, randomStr)
 }
 
+// applyFXQuote 将换汇报价锁定到支付并记录结算币种金额
+func (s *paymentService) applyFXQuote(ctx context.Context, payment *model.Payment, quoteID string) error {
+	if s.channelClient == nil {
+		return fmt.Errorf("未配置Channel服务，无法使用换汇报价")
+	}
+
+	quote, err := s.channelClient.LockFXQuote(ctx, quoteID, &client.LockFXQuoteRequest{
+		MerchantID:   payment.MerchantID.String(),
+		Reference:    payment.PaymentNo,
+		BaseCurrency: payment.Currency,
+	})
+	if err != nil {
+		return fmt.Errorf("锁定换汇报价失败: %w", err)
+	}
+
+	settlement, err := money.New(payment.Amount, payment.Currency).Convert(quote.Rate, quote.TargetCurrency, money.RoundHalfEven)
+	if err != nil {
+		s.releaseFXQuote(ctx, payment.MerchantID, quote.QuoteID, payment.PaymentNo)
+		return fmt.Errorf("计算结算金额失败: %w", err)
+	}
+
+	payment.FXQuoteID = quote.QuoteID
+	payment.SettlementCurrency = settlement.Currency
+	payment.SettlementAmount = settlement.Amount
+	payment.FXRate = &quote.Rate
+
+	logger.Info("payment locked fx quote",
+		zap.String("payment_no", payment.PaymentNo),
+		zap.String("quote_id", quote.QuoteID),
+		zap.String("rate", quote.Rate.String()),
+		zap.String("settlement", settlement.String()))
+
+	return nil
+}
+
+// releaseFXQuote 释放支付锁定的换汇报价（失败只记录日志）
+func (s *paymentService) releaseFXQuote(ctx context.Context, merchantID uuid.UUID, quoteID, paymentNo string) {
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := s.channelClient.ReleaseFXQuote(releaseCtx, quoteID, &client.ReleaseFXQuoteRequest{
+		MerchantID: merchantID.String(),
+		Reference:  paymentNo,
+	}); err != nil {
+		logger.Error("failed to release fx quote",
+			zap.Error(err),
+			zap.String("payment_no", paymentNo),
+			zap.String("quote_id", quoteID))
+		return
+	}
+
+	logger.Info("payment released fx quote",
+		zap.String("payment_no", paymentNo),
+		zap.String("quote_id", quoteID))
+}
+
 // isValidCurrency 验证货币类型（支持全球主流货币）
 func (s *paymentService) isValidCurrency(currency string) bool {
 	// 支持的货币列表（与用户偏好设置中的货币列表一致）
@@ -1472,42 +1872,6 @@ func (s *paymentService) isValidCurrency(currency string) bool {
 	return false
 }
 
-// calculateNotifySignature 计算通知签名
-func calculateNotifySignature(data map[string]interface{}, secret string) string {
-	// 1. 对参数按key排序
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
-	}
-
-	// 简单排序（生产环境应使用 sort.Strings）
-	for i := 0; i < len(keys); i++ {
-		for j := i + 1; j < len(keys); j++ {
-			if keys[i] > keys[j] {
-				keys[i], keys[j] = keys[j], keys[i]
-			}
-		}
-	}
-
-	// 2. 拼接参数: key1=value1&key2=value2&...
-	var sb strings.Builder
-	for i, k := range keys {
-		if i > 0 {
-			sb.WriteString("&")
-		}
-		sb.WriteString(fmt.Sprintf("%s=%v", k, data[k]))
-	}
-
-	// 3. 追加secret
-	sb.WriteString("&key=")
-	sb.WriteString(secret)
-
-	// 4. 计算MD5（生产环境建议使用HMAC-SHA256）
-	// 这里简化处理，实际应使用 crypto/md5 或 crypto/sha256
-	signStr := sb.String()
-	return fmt.Sprintf("SIGN_%s", base64.StdEncoding.EncodeToString([]byte(signStr))[:32])
-}
-
 // verifyCallbackSignature 验证不同渠道的回调签名
 func (s *paymentService) verifyCallbackSignature(ctx context.Context, channel string, data map[string]interface{}, rawData []byte) bool {
 	switch strings.ToLower(channel) {
@@ -1658,20 +2022,70 @@ func (s *paymentService) publishPaymentStatusEvent(payment *model.Payment, oldSt
 		return
 	}
 
-	// 确定事件类型
-	var eventType string
-	switch payment.Status {
+	eventType := paymentStatusEventType(payment.Status)
+	if eventType == "" {
+		// 其他状态不发布事件
+		return
+	}
+
+	var splits []events.SplitPosting
+	if eventType == events.PaymentSuccess && s.marketplaceService != nil {
+		// 分账支付按收款方分别入账；分账明细加载失败时推迟发布，避免财务按非分账支付整笔入账平台商户
+		splitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		var err error
+		splits, err = s.marketplaceService.SplitPostings(splitCtx, payment.PaymentNo)
+		cancel()
+		if err != nil {
+			s.deferSplitEvent(context.Background(), &pendingSplitEvent{
+				Kind:      splitEventPayment,
+				PaymentNo: payment.PaymentNo,
+				OldStatus: oldStatus,
+				Channel:   channel,
+			}, err)
+			return
+		}
+	}
+	event := s.buildPaymentStatusEvent(payment, eventType, oldStatus, channel, splits)
+
+	// 异步发布事件 (不阻塞主流程)
+	go func() {
+		publishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := s.eventPublisher.Publish(publishCtx, events.TopicPaymentEvents, event); err != nil {
+			logger.Error("failed to publish payment event to kafka",
+				zap.String("payment_no", payment.PaymentNo),
+				zap.String("event_type", eventType),
+				zap.Error(err))
+
+			// 失败降级: 使用HTTP调用
+			logger.Info("fallback to HTTP clients due to kafka publish failure")
+			s.fallbackToHTTPClients(payment, oldStatus, channel)
+		} else {
+			logger.Info("payment event published successfully",
+				zap.String("payment_no", payment.PaymentNo),
+				zap.String("event_type", eventType),
+				zap.String("topic", events.TopicPaymentEvents))
+		}
+	}()
+}
+
+// paymentStatusEventType 支付状态对应的事件类型，不发布事件的状态返回空字符串
+func paymentStatusEventType(status string) string {
+	switch status {
 	case model.PaymentStatusSuccess:
-		eventType = events.PaymentSuccess
+		return events.PaymentSuccess
 	case model.PaymentStatusFailed:
-		eventType = events.PaymentFailed
+		return events.PaymentFailed
 	case model.PaymentStatusCancelled:
-		eventType = events.PaymentCancelled
+		return events.PaymentCancelled
 	default:
-		// 其他状态不发布事件
-		return
+		return ""
 	}
+}
 
+// buildPaymentStatusEvent 构造支付状态变更事件
+func (s *paymentService) buildPaymentStatusEvent(payment *model.Payment, eventType, oldStatus, channel string, splits []events.SplitPosting) *events.PaymentEvent {
 	// 构造事件载荷
 	payload := events.PaymentEventPayload{
 		PaymentNo:     payment.PaymentNo,
@@ -1680,9 +2094,13 @@ func (s *paymentService) publishPaymentStatusEvent(payment *model.Payment, oldSt
 		Amount:        payment.Amount,
 		Currency:      payment.Currency,
 		Channel:       payment.Channel,
+		PayMethod:     payment.PayMethod,
+		Country:       payment.Country,
 		Status:        payment.Status,
 		CustomerEmail: payment.CustomerEmail,
 		PaidAt:        payment.PaidAt,
+		LatencyMs:     payment.ChannelLatencyMs,
+		Decline:       payment.Decline(),
 		Extra: map[string]interface{}{
 			"old_status":       oldStatus,
 			"callback_channel": channel,
@@ -1690,6 +2108,10 @@ func (s *paymentService) publishPaymentStatusEvent(payment *model.Payment, oldSt
 			"error_msg":        payment.ErrorMsg,
 		},
 	}
+	if eventType == events.PaymentSuccess && s.receiptService != nil {
+		payload.Extra["receipt_url"] = s.receiptService.ReceiptURL(model.ReceiptSourcePayment, payment.PaymentNo)
+	}
+	payload.Splits = splits
 
 	// 创建事件
 	event := events.NewPaymentEvent(eventType, payload)
@@ -1698,30 +2120,65 @@ func (s *paymentService) publishPaymentStatusEvent(payment *model.Payment, oldSt
 	event.AddMetadata("service", "payment-gateway")
 	event.AddMetadata("old_status", oldStatus)
 	event.AddMetadata("callback_channel", channel)
+	return event
+}
 
-	// 异步发布事件 (不阻塞主流程)
+// reverseRefundSplits 退款成功后按原分账比例冲正各收款方（非分账支付返回 nil；同一退款单号重复调用返回已有结果）
+func (s *paymentService) reverseRefundSplits(ctx context.Context, payment *model.Payment, refund *model.Refund) ([]events.SplitPosting, error) {
+	if s.marketplaceService == nil {
+		return nil, nil
+	}
+	return s.marketplaceService.ReverseSplits(ctx, payment.PaymentNo, model.SplitReversalRefund, refund.RefundNo, refund.Amount)
+}
+
+// publishRefundSuccessEvent 发布退款成功事件到Kafka（通知服务据此向客户发送退款邮件，财务服务据此冲正记账）
+// 发布失败时进入推迟发布队列重试
+func (s *paymentService) publishRefundSuccessEvent(payment *model.Payment, refund *model.Refund, splits []events.SplitPosting) {
+	if s.eventPublisher == nil {
+		return
+	}
+	event := s.buildRefundSuccessEvent(payment, refund, splits)
+
+	// 通知服务在支付事件 Topic 上消费退款事件
 	go func() {
 		publishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
 		if err := s.eventPublisher.Publish(publishCtx, events.TopicPaymentEvents, event); err != nil {
-			logger.Error("failed to publish payment event to kafka",
-				zap.String("payment_no", payment.PaymentNo),
-				zap.String("event_type", eventType),
-				zap.Error(err))
-
-			// 失败降级: 使用HTTP调用
-			logger.Info("fallback to HTTP clients due to kafka publish failure")
-			s.fallbackToHTTPClients(payment, oldStatus, channel)
-		} else {
-			logger.Info("payment event published successfully",
-				zap.String("payment_no", payment.PaymentNo),
-				zap.String("event_type", eventType),
-				zap.String("topic", events.TopicPaymentEvents))
+			s.deferSplitEvent(context.Background(), &pendingSplitEvent{Kind: splitEventRefund, PaymentNo: payment.PaymentNo, RefundNo: refund.RefundNo}, err)
 		}
 	}()
 }
 
+// buildRefundSuccessEvent 构造退款成功事件
+func (s *paymentService) buildRefundSuccessEvent(payment *model.Payment, refund *model.Refund, splits []events.SplitPosting) *events.RefundEvent {
+	payload := events.RefundEventPayload{
+		RefundNo:   refund.RefundNo,
+		PaymentNo:  payment.PaymentNo,
+		MerchantID: payment.MerchantID.String(),
+		OrderNo:    payment.OrderNo,
+		Amount:     refund.Amount,
+		Currency:   refund.Currency,
+		Channel:    payment.Channel,
+		PayMethod:  payment.PayMethod,
+		Country:    payment.Country,
+		Reason:     refund.Reason,
+		Status:     refund.Status,
+		RefundedAt: refund.RefundedAt,
+		Splits:     splits,
+		Extra: map[string]interface{}{
+			"customer_email": payment.CustomerEmail,
+		},
+	}
+	if s.receiptService != nil {
+		payload.Extra["receipt_url"] = s.receiptService.ReceiptURL(model.ReceiptSourceRefund, refund.RefundNo)
+	}
+
+	event := events.NewRefundEvent(events.RefundSuccess, payload)
+	event.AddMetadata("service", "payment-gateway")
+	return event
+}
+
 // fallbackToHTTPClients 降级到HTTP客户端调用 (保持向后兼容)
 func (s *paymentService) fallbackToHTTPClients(payment *model.Payment, oldStatus, channel string) {
 	// 12.1 发送通知（支付成功/失败通知）
@@ -20,14 +20,21 @@ type PreAuthService interface {
 	// 创建预授权
 	CreatePreAuth(ctx context.Context, input *CreatePreAuthInput) (*model.PreAuthPayment, error)
 
-	// 确认预授权（扣款）
-	CapturePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, amount *int64) (*model.Payment, error)
+	// 确认预授权（扣款），finalCapture 为 false 时可继续对剩余金额多次确认
+	CapturePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, amount *int64, finalCapture *bool) (*model.Payment, error)
+
+	// 增量授权（在原授权基础上追加冻结金额）
+	IncrementPreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, incrementAmount int64) (*model.PreAuthPayment, error)
+
+	// 重新授权（授权过期前重新冻结剩余可确认金额并延长有效期）
+	ReauthorizePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthPayment, error)
 
 	// 取消预授权
 	CancelPreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, reason string) error
 
 	// 查询预授权
 	GetPreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthPayment, error)
+	GetPreAuthBreakdown(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthBreakdown, error)
 	ListPreAuths(ctx context.Context, merchantID uuid.UUID, status string, page, pageSize int) ([]*model.PreAuthPayment, error)
 
 	// 定时任务：扫描并过期超时的预授权
@@ -184,7 +191,10 @@ func (s *preAuthService) CreatePreAuth(ctx context.Context, input *CreatePreAuth
 }
 
 // CapturePreAuth 确认预授权（扣款）
-func (s *preAuthService) CapturePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, amount *int64) (*model.Payment, error) {
+//
+// 每次确认生成一笔独立的支付。finalCapture 为 nil 或 true 时为最后一次确认，渠道释放剩余冻结金额；
+// 为 false 时剩余金额继续冻结，可在过期前再次确认（需要渠道支持多次确认）。
+func (s *preAuthService) CapturePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, amount *int64, finalCapture *bool) (*model.Payment, error) {
 	// 1. 查询预授权记录
 	preAuth, err := s.preAuthRepo.GetByPreAuthNo(ctx, merchantID, preAuthNo)
 	if err != nil {
@@ -199,80 +209,110 @@ func (s *preAuthService) CapturePreAuth(ctx context.Context, merchantID uuid.UUI
 		return nil, fmt.Errorf("预授权状态不允许确认: status=%s, expired=%v", preAuth.Status, preAuth.IsExpired())
 	}
 
-	// 3. 确定确认金额
-	captureAmount := preAuth.Amount
+	// 3. 确定确认金额（默认确认全部剩余金额）
+	remaining := preAuth.GetRemainingAmount()
+	captureAmount := remaining
 	if amount != nil {
 		captureAmount = *amount
+		if captureAmount <= 0 {
+			return nil, fmt.Errorf("确认金额必须大于0")
+		}
 		// 检查金额是否超过剩余可确认金额
-		if captureAmount > preAuth.GetRemainingAmount() {
+		if captureAmount > remaining {
 			return nil, fmt.Errorf("确认金额超过剩余可确认金额: requested=%d, remaining=%d",
-				captureAmount, preAuth.GetRemainingAmount())
+				captureAmount, remaining)
 		}
 	}
 
-	// 4. 调用渠道适配器确认预授权
+	// 确认全部剩余金额时无论是否声明都视为最后一次确认
+	final := finalCapture == nil || *finalCapture || captureAmount == remaining
+
+	// 4. 预留确认金额，渠道调用期间其他确认、增量授权和取消都会被拒绝
+	reserved, err := s.preAuthRepo.ReserveCapture(ctx, preAuth, captureAmount, final)
+	if err != nil {
+		return nil, fmt.Errorf("预留预授权确认金额失败: %w", err)
+	}
+
+	// 5. 调用渠道适配器确认预授权
 	channelResp, err := s.channelClient.CapturePreAuth(ctx, &client.CapturePreAuthRequest{
 		PreAuthNo:      preAuthNo,
+		Channel:        preAuth.Channel,
 		ChannelTradeNo: preAuth.ChannelTradeNo,
 		Amount:         captureAmount,
 		Currency:       preAuth.Currency,
+		FinalCapture:   &final,
 	})
 
+	if err == nil && channelResp.Code != 0 {
+		err = fmt.Errorf("渠道返回错误: %s", channelResp.Message)
+	}
 	if err != nil {
+		// 渠道确认失败，释放预留金额
+		if releaseErr := s.preAuthRepo.ReleaseCapture(ctx, preAuth.ID, reserved); releaseErr != nil {
+			logger.Error("释放预授权确认预留失败",
+				zap.String("pre_auth_no", preAuthNo),
+				zap.Int64("reserved", reserved),
+				zap.Error(releaseErr))
+		}
 		return nil, fmt.Errorf("调用渠道确认预授权失败: %w", err)
 	}
 
-	if channelResp.Code != 0 {
-		return nil, fmt.Errorf("渠道返回错误: %s", channelResp.Message)
-	}
-
-	// 5. 生成支付单号
+	// 6. 生成支付单号
 	paymentNo := generatePaymentNo()
+	now := time.Now()
+	sequence := preAuth.CaptureCount + 1
 
-	// 6. 开始事务：创建支付记录 + 更新预授权记录
-	var payment *model.Payment
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// 创建支付记录
-		payment = &model.Payment{
-			MerchantID:     merchantID,
-			OrderNo:        preAuth.OrderNo,
-			PaymentNo:      paymentNo,
-			Amount:         captureAmount,
-			Currency:       preAuth.Currency,
-			Channel:        preAuth.Channel,
-			ChannelOrderNo: channelResp.Data.PaymentTradeNo,
-			Status:         model.PaymentStatusSuccess,
-			Description:    fmt.Sprintf("%s (预授权确认)", preAuth.Subject),
-			CustomerIP:     preAuth.ClientIP,
-			ReturnURL:      preAuth.ReturnURL,
-			NotifyURL:      preAuth.NotifyURL,
-			PaidAt:         timePtr(time.Now()),
-			Extra:          fmt.Sprintf(`{"pre_auth_no": "%s", "type": "pre_auth_capture"}`, preAuthNo),
-		}
-
-		if err := tx.Create(payment).Error; err != nil {
-			return fmt.Errorf("创建支付记录失败: %w", err)
-		}
-
-		// 更新预授权记录
-		if err := s.preAuthRepo.UpdateToCaptured(ctx, preAuth.ID, captureAmount, paymentNo, time.Now()); err != nil {
-			return fmt.Errorf("更新预授权记录失败: %w", err)
-		}
+	capture := &model.PreAuthCapture{
+		PreAuthID:      preAuth.ID,
+		PreAuthNo:      preAuthNo,
+		PaymentNo:      paymentNo,
+		Sequence:       sequence,
+		Amount:         captureAmount,
+		Currency:       preAuth.Currency,
+		IsFinal:        final,
+		ChannelTradeNo: channelResp.Data.PaymentTradeNo,
+		CreatedAt:      now,
+	}
+	if final {
+		capture.ReleasedAmount = reserved - captureAmount
+	}
 
-		return nil
-	})
+	payment := &model.Payment{
+		MerchantID:     merchantID,
+		OrderNo:        preAuth.OrderNo,
+		PaymentNo:      paymentNo,
+		Amount:         captureAmount,
+		Currency:       preAuth.Currency,
+		Channel:        preAuth.Channel,
+		ChannelOrderNo: channelResp.Data.PaymentTradeNo,
+		Status:         model.PaymentStatusSuccess,
+		Description:    fmt.Sprintf("%s (预授权确认 #%d)", preAuth.Subject, sequence),
+		CustomerIP:     preAuth.ClientIP,
+		ReturnURL:      preAuth.ReturnURL,
+		NotifyURL:      preAuth.NotifyURL,
+		PaidAt:         timePtr(now),
+		Extra: fmt.Sprintf(`{"pre_auth_no": "%s", "type": "pre_auth_capture", "capture_sequence": %d, "final_capture": %t}`,
+			preAuthNo, sequence, final),
+	}
 
-	if err != nil {
-		return nil, err
+	// 7. 同一事务内：结转预留金额 + 写入确认记录 + 创建支付记录
+	if err := s.preAuthRepo.ConfirmCapture(ctx, preAuth.ID, reserved, capture, payment); err != nil {
+		// 渠道已扣款但本地记录失败，预留金额保持冻结，需要人工对账
+		logger.Error("预授权渠道确认成功但本地记录失败",
+			zap.String("pre_auth_no", preAuthNo),
+			zap.String("payment_no", paymentNo),
+			zap.Int64("amount", captureAmount),
+			zap.Error(err))
+		return nil, fmt.Errorf("记录预授权确认失败: %w", err)
 	}
 
-	// 7. 通知订单服务
+	// 8. 通知订单服务
 	if s.orderClient != nil {
 		go func() {
 			_ = s.orderClient.UpdateOrderStatus(context.Background(), paymentNo, &client.UpdateOrderStatusRequest{
 				Status:         "paid",
 				ChannelOrderNo: channelResp.Data.PaymentTradeNo,
-				PaidAt:         time.Now().Format(time.RFC3339),
+				PaidAt:         now.Format(time.RFC3339),
 			})
 		}()
 	}
@@ -280,11 +320,163 @@ func (s *preAuthService) CapturePreAuth(ctx context.Context, merchantID uuid.UUI
 	logger.Info("预授权确认成功",
 		zap.String("pre_auth_no", preAuthNo),
 		zap.String("payment_no", paymentNo),
-		zap.Int64("amount", captureAmount))
+		zap.Int("sequence", sequence),
+		zap.Bool("final", final),
+		zap.Int64("amount", captureAmount),
+		zap.Int64("released", capture.ReleasedAmount))
 
 	return payment, nil
 }
 
+// IncrementPreAuth 增量授权
+func (s *preAuthService) IncrementPreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, incrementAmount int64) (*model.PreAuthPayment, error) {
+	if incrementAmount <= 0 {
+		return nil, fmt.Errorf("增量金额必须大于0")
+	}
+
+	// 1. 查询预授权记录
+	preAuth, err := s.preAuthRepo.GetByPreAuthNo(ctx, merchantID, preAuthNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询预授权失败: %w", err)
+	}
+	if preAuth == nil {
+		return nil, fmt.Errorf("预授权不存在")
+	}
+
+	// 2. 检查状态（部分确认后不允许再增量授权）
+	if !preAuth.CanIncrement() {
+		return nil, fmt.Errorf("预授权状态不允许增量授权: status=%s, expired=%v", preAuth.Status, preAuth.IsExpired())
+	}
+
+	newAmount := preAuth.Amount + incrementAmount
+
+	// 3. 预留增量金额，渠道调用期间确认、取消和其他增量授权都会被拒绝
+	if err := s.preAuthRepo.ReserveIncrement(ctx, preAuth, incrementAmount); err != nil {
+		return nil, fmt.Errorf("预留增量授权金额失败: %w", err)
+	}
+
+	// 4. 调用渠道适配器增量授权（渠道接收增量后的授权总金额）
+	channelResp, err := s.channelClient.IncrementPreAuth(ctx, &client.IncrementPreAuthRequest{
+		PreAuthNo:        preAuthNo,
+		Channel:          preAuth.Channel,
+		ChannelPreAuthNo: preAuth.ChannelTradeNo,
+		Amount:           newAmount,
+		Currency:         preAuth.Currency,
+	})
+	if err == nil && channelResp.Code != 0 {
+		err = fmt.Errorf("渠道返回错误: %s", channelResp.Message)
+	}
+	if err != nil {
+		// 渠道增量授权失败，释放预留
+		if releaseErr := s.preAuthRepo.ReleaseIncrement(ctx, preAuth.ID, incrementAmount); releaseErr != nil {
+			logger.Error("释放增量授权预留失败",
+				zap.String("pre_auth_no", preAuthNo),
+				zap.Int64("increment", incrementAmount),
+				zap.Error(releaseErr))
+		}
+		return nil, fmt.Errorf("调用渠道增量授权失败: %w", err)
+	}
+
+	// 5. 将预留的增量计入授权金额
+	if err := s.preAuthRepo.ConfirmIncrement(ctx, preAuth.ID, incrementAmount); err != nil {
+		logger.Error("预授权渠道增量成功但本地更新失败",
+			zap.String("pre_auth_no", preAuthNo),
+			zap.Int64("amount", newAmount),
+			zap.Error(err))
+		return nil, fmt.Errorf("更新预授权金额失败: %w", err)
+	}
+
+	logger.Info("预授权增量授权成功",
+		zap.String("pre_auth_no", preAuthNo),
+		zap.Int64("previous_amount", preAuth.Amount),
+		zap.Int64("amount", newAmount))
+
+	preAuth.Amount = newAmount
+	preAuth.IncrementCount++
+	return preAuth, nil
+}
+
+// ReauthorizePreAuth 重新授权
+//
+// 渠道授权有效期有限（Stripe 线上卡授权为 7 天），住宿、租车等长周期场景在过期前重新冻结剩余可确认金额，
+// 渠道可能返回新的渠道预授权号，后续确认、取消都使用新的预授权。
+func (s *preAuthService) ReauthorizePreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthPayment, error) {
+	// 1. 查询预授权记录
+	preAuth, err := s.preAuthRepo.GetByPreAuthNo(ctx, merchantID, preAuthNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询预授权失败: %w", err)
+	}
+	if preAuth == nil {
+		return nil, fmt.Errorf("预授权不存在")
+	}
+
+	// 2. 检查状态（已过期的授权无法重新授权，需要重新创建预授权）
+	if !preAuth.CanReauthorize() {
+		return nil, fmt.Errorf("预授权状态不允许重新授权: status=%s, expired=%v", preAuth.Status, preAuth.IsExpired())
+	}
+
+	// 3. 预留重新授权，渠道调用期间确认、取消和增量授权都会被拒绝
+	if err := s.preAuthRepo.ReserveOperation(ctx, preAuth, model.PreAuthOperationReauthorize); err != nil {
+		return nil, fmt.Errorf("预留重新授权失败: %w", err)
+	}
+
+	// 4. 调用渠道适配器重新冻结剩余可确认金额
+	channelResp, err := s.channelClient.ReauthorizePreAuth(ctx, &client.ReauthorizePreAuthRequest{
+		PreAuthNo:        preAuthNo,
+		OrderNo:          preAuth.OrderNo,
+		Channel:          preAuth.Channel,
+		ChannelPreAuthNo: preAuth.ChannelTradeNo,
+		Amount:           preAuth.GetRemainingAmount(),
+		Currency:         preAuth.Currency,
+		Description:      preAuth.Subject,
+	})
+	if err == nil && channelResp.Code != 0 {
+		err = fmt.Errorf("渠道返回错误: %s", channelResp.Message)
+	}
+	if err != nil {
+		// 渠道重新授权失败，释放预留
+		if releaseErr := s.preAuthRepo.ReleaseOperation(ctx, preAuth.ID, model.PreAuthOperationReauthorize); releaseErr != nil {
+			logger.Error("释放重新授权预留失败",
+				zap.String("pre_auth_no", preAuthNo),
+				zap.Error(releaseErr))
+		}
+		return nil, fmt.Errorf("调用渠道重新授权失败: %w", err)
+	}
+
+	// 5. 更新渠道交易号与过期时间
+	now := time.Now()
+	channelTradeNo := preAuth.ChannelTradeNo
+	expiresAt := now.Add(7 * 24 * time.Hour)
+	if channelResp.Data != nil {
+		if channelResp.Data.ChannelPreAuthNo != "" {
+			channelTradeNo = channelResp.Data.ChannelPreAuthNo
+		}
+		if channelResp.Data.ExpiresAt != nil {
+			expiresAt = time.Unix(*channelResp.Data.ExpiresAt, 0)
+		}
+	}
+
+	if err := s.preAuthRepo.UpdateToReauthorized(ctx, preAuth.ID, channelTradeNo, expiresAt, now); err != nil {
+		logger.Error("预授权渠道重新授权成功但本地更新失败",
+			zap.String("pre_auth_no", preAuthNo),
+			zap.String("channel_trade_no", channelTradeNo),
+			zap.Error(err))
+		return nil, fmt.Errorf("更新预授权记录失败: %w", err)
+	}
+
+	logger.Info("预授权重新授权成功",
+		zap.String("pre_auth_no", preAuthNo),
+		zap.String("previous_channel_trade_no", preAuth.ChannelTradeNo),
+		zap.String("channel_trade_no", channelTradeNo),
+		zap.Time("expires_at", expiresAt))
+
+	preAuth.ChannelTradeNo = channelTradeNo
+	preAuth.ExpiresAt = expiresAt
+	preAuth.ReauthorizedAt = &now
+	preAuth.ReauthCount++
+	return preAuth, nil
+}
+
 // CancelPreAuth 取消预授权
 func (s *preAuthService) CancelPreAuth(ctx context.Context, merchantID uuid.UUID, preAuthNo string, reason string) error {
 	// 1. 查询预授权记录
@@ -301,7 +493,12 @@ func (s *preAuthService) CancelPreAuth(ctx context.Context, merchantID uuid.UUID
 		return fmt.Errorf("预授权状态不允许取消: status=%s", preAuth.Status)
 	}
 
-	// 3. 调用渠道适配器取消预授权
+	// 3. 预留取消，渠道调用期间确认、增量授权和重新授权都会被拒绝
+	if err := s.preAuthRepo.ReserveOperation(ctx, preAuth, model.PreAuthOperationCancel); err != nil {
+		return fmt.Errorf("预留取消失败: %w", err)
+	}
+
+	// 4. 调用渠道适配器取消预授权
 	if preAuth.ChannelTradeNo != "" {
 		channelResp, err := s.channelClient.CancelPreAuth(ctx, &client.CancelPreAuthRequest{
 			PreAuthNo:      preAuthNo,
@@ -315,7 +512,7 @@ func (s *preAuthService) CancelPreAuth(ctx context.Context, merchantID uuid.UUID
 		}
 	}
 
-	// 4. 更新预授权记录
+	// 5. 更新预授权记录（同时清除取消预留）
 	err = s.preAuthRepo.UpdateToCancelled(ctx, preAuth.ID, time.Now(), reason)
 	if err != nil {
 		return fmt.Errorf("更新预授权记录失败: %w", err)
@@ -333,6 +530,26 @@ func (s *preAuthService) GetPreAuth(ctx context.Context, merchantID uuid.UUID, p
 	return s.preAuthRepo.GetByPreAuthNo(ctx, merchantID, preAuthNo)
 }
 
+// GetPreAuthBreakdown 查询预授权金额明细（可确认/已确认/已释放及每次确认记录）
+func (s *preAuthService) GetPreAuthBreakdown(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthBreakdown, error) {
+	preAuth, err := s.preAuthRepo.GetByPreAuthNo(ctx, merchantID, preAuthNo)
+	if err != nil {
+		return nil, fmt.Errorf("查询预授权失败: %w", err)
+	}
+	if preAuth == nil {
+		return nil, nil
+	}
+
+	captures, err := s.preAuthRepo.ListCaptures(ctx, preAuth.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询预授权确认记录失败: %w", err)
+	}
+
+	breakdown := preAuth.Breakdown()
+	breakdown.Captures = captures
+	return breakdown, nil
+}
+
 // ListPreAuths 获取预授权列表
 func (s *preAuthService) ListPreAuths(ctx context.Context, merchantID uuid.UUID, status string, page, pageSize int) ([]*model.PreAuthPayment, error) {
 	if page < 1 {
@@ -355,7 +572,7 @@ func (s *preAuthService) ScanAndExpirePreAuths(ctx context.Context) (int, error)
 
 	expiredCount := 0
 	for _, preAuth := range preAuths {
-		// 取消渠道的预授权
+		// 取消渠道的预授权（已部分确认的只释放剩余冻结金额，已确认的支付不受影响）
 		if preAuth.ChannelTradeNo != "" {
 			_, _ = s.channelClient.CancelPreAuth(ctx, &client.CancelPreAuthRequest{
 				PreAuthNo:      preAuth.PreAuthNo,
@@ -375,7 +592,9 @@ func (s *preAuthService) ScanAndExpirePreAuths(ctx context.Context) (int, error)
 		expiredCount++
 		logger.Info("预授权已自动过期",
 			zap.String("pre_auth_no", preAuth.PreAuthNo),
-			zap.Time("expires_at", preAuth.ExpiresAt))
+			zap.Time("expires_at", preAuth.ExpiresAt),
+			zap.Int64("captured", preAuth.CapturedAmount),
+			zap.Int64("released", preAuth.Amount-preAuth.CapturedAmount))
 	}
 
 	if expiredCount > 0 {
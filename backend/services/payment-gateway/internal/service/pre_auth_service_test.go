@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"payment-platform/payment-gateway/internal/client"
+	"payment-platform/payment-gateway/internal/model"
+	"payment-platform/payment-gateway/internal/repository"
+)
+
+// memPreAuthRepo 内存预授权仓库，按 SQL 实现的条件模拟预留、确认与释放
+type memPreAuthRepo struct {
+	repository.PreAuthRepository
+
+	mu       sync.Mutex
+	preAuth  model.PreAuthPayment
+	captures []*model.PreAuthCapture
+	payments []*model.Payment
+}
+
+func (r *memPreAuthRepo) snapshot() model.PreAuthPayment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.preAuth
+}
+
+func (r *memPreAuthRepo) GetByPreAuthNo(ctx context.Context, merchantID uuid.UUID, preAuthNo string) (*model.PreAuthPayment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.preAuth.MerchantID != merchantID || r.preAuth.PreAuthNo != preAuthNo {
+		return nil, nil
+	}
+	preAuth := r.preAuth
+	return &preAuth, nil
+}
+
+func (r *memPreAuthRepo) ReserveCapture(ctx context.Context, preAuth *model.PreAuthPayment, amount int64, final bool) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	reserved := amount
+	if final {
+		reserved = preAuth.Amount - preAuth.CapturedAmount - preAuth.ReleasedAmount
+	}
+	if !p.IsHolding() || p.CaptureCount != preAuth.CaptureCount || p.Amount != preAuth.Amount ||
+		p.CapturedAmount+p.ReleasedAmount+reserved > p.Amount || p.HasPendingOperation() {
+		return 0, repository.ErrPreAuthConcurrentUpdate
+	}
+	p.PendingCaptureAmount = reserved
+	return reserved, nil
+}
+
+func (r *memPreAuthRepo) ConfirmCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64, capture *model.PreAuthCapture, payment *model.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	if p.PendingCaptureAmount != reserved {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.Status = model.PreAuthStatusPartiallyCaptured
+	if capture.IsFinal {
+		p.Status = model.PreAuthStatusCaptured
+		p.ReleasedAmount += reserved - capture.Amount
+	}
+	p.CapturedAmount += capture.Amount
+	p.PendingCaptureAmount = 0
+	p.CaptureCount++
+	r.captures = append(r.captures, capture)
+	r.payments = append(r.payments, payment)
+	return nil
+}
+
+func (r *memPreAuthRepo) ReleaseCapture(ctx context.Context, preAuthID uuid.UUID, reserved int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.preAuth.PendingCaptureAmount == reserved {
+		r.preAuth.PendingCaptureAmount = 0
+	}
+	return nil
+}
+
+func (r *memPreAuthRepo) ReserveIncrement(ctx context.Context, preAuth *model.PreAuthPayment, incrementAmount int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	if p.Status != model.PreAuthStatusAuthorized || p.Amount != preAuth.Amount || p.HasPendingOperation() {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.PendingIncrementAmount = incrementAmount
+	return nil
+}
+
+func (r *memPreAuthRepo) ConfirmIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	if p.PendingIncrementAmount != incrementAmount {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.Amount += incrementAmount
+	p.PendingIncrementAmount = 0
+	p.IncrementCount++
+	return nil
+}
+
+func (r *memPreAuthRepo) ReleaseIncrement(ctx context.Context, id uuid.UUID, incrementAmount int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.preAuth.PendingIncrementAmount == incrementAmount {
+		r.preAuth.PendingIncrementAmount = 0
+	}
+	return nil
+}
+
+func (r *memPreAuthRepo) ReserveOperation(ctx context.Context, preAuth *model.PreAuthPayment, operation string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	allowed := p.IsHolding() || (operation == model.PreAuthOperationCancel && p.Status == model.PreAuthStatusPending)
+	if !allowed || p.CaptureCount != preAuth.CaptureCount || p.Amount != preAuth.Amount || p.HasPendingOperation() {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.PendingOperation = operation
+	return nil
+}
+
+func (r *memPreAuthRepo) ReleaseOperation(ctx context.Context, id uuid.UUID, operation string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.preAuth.PendingOperation == operation {
+		r.preAuth.PendingOperation = ""
+	}
+	return nil
+}
+
+func (r *memPreAuthRepo) UpdateToCancelled(ctx context.Context, id uuid.UUID, cancelledAt time.Time, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	if p.PendingOperation != model.PreAuthOperationCancel {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.Status = model.PreAuthStatusCancelled
+	if p.CapturedAmount > 0 {
+		p.Status = model.PreAuthStatusCaptured
+	}
+	p.ReleasedAmount = p.Amount - p.CapturedAmount
+	p.PendingOperation = ""
+	p.CancelledAt = &cancelledAt
+	return nil
+}
+
+func (r *memPreAuthRepo) UpdateToReauthorized(ctx context.Context, id uuid.UUID, channelTradeNo string, expiresAt, reauthorizedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &r.preAuth
+	if p.PendingOperation != model.PreAuthOperationReauthorize {
+		return repository.ErrPreAuthConcurrentUpdate
+	}
+	p.ChannelTradeNo = channelTradeNo
+	p.ExpiresAt = expiresAt
+	p.ReauthorizedAt = &reauthorizedAt
+	p.ReauthCount++
+	p.PendingOperation = ""
+	return nil
+}
+
+// fakeChannel 模拟 channel-adapter 预授权接口，记录渠道调用时预授权的本地状态
+type fakeChannel struct {
+	repo     *memPreAuthRepo
+	code     int
+	calls    int
+	observed []model.PreAuthPayment
+}
+
+func (f *fakeChannel) server(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls++
+		f.observed = append(f.observed, f.repo.snapshot())
+
+		resp := map[string]interface{}{"code": f.code, "message": "ok"}
+		if f.code != 0 {
+			resp["message"] = "card_declined"
+		}
+		switch r.URL.Path {
+		case "/api/v1/channel/pre-auth/capture":
+			resp["data"] = map[string]interface{}{"payment_trade_no": "ch_capture", "status": "succeeded"}
+		case "/api/v1/channel/pre-auth/increment":
+			resp["data"] = map[string]interface{}{"status": "requires_capture"}
+		case "/api/v1/channel/pre-auth/cancel":
+			resp["data"] = map[string]interface{}{"status": "canceled"}
+		case "/api/v1/channel/pre-auth/reauthorize":
+			resp["data"] = map[string]interface{}{"channel_pre_auth_no": "pi_2", "status": "requires_capture"}
+		default:
+			t.Errorf("unexpected channel path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func newPreAuthTestService(t *testing.T, code int) (PreAuthService, *memPreAuthRepo, *fakeChannel) {
+	logger.Log = zap.NewNop()
+
+	repo := &memPreAuthRepo{preAuth: model.PreAuthPayment{
+		ID:             uuid.New(),
+		MerchantID:     uuid.New(),
+		OrderNo:        "ORDER1",
+		PreAuthNo:      "PA1",
+		Amount:         10000,
+		Currency:       "USD",
+		Channel:        "stripe",
+		ChannelTradeNo: "pi_1",
+		Status:         model.PreAuthStatusAuthorized,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}}
+	channel := &fakeChannel{repo: repo, code: code}
+	srv := channel.server(t)
+	t.Cleanup(srv.Close)
+
+	svc := NewPreAuthService(nil, repo, nil, nil, client.NewChannelClient(srv.URL), nil, nil, nil)
+	return svc, repo, channel
+}
+
+func TestCapturePreAuthReservesBeforeChannelCapture(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 0)
+	preAuth := repo.snapshot()
+
+	amount := int64(3000)
+	final := false
+	payment, err := svc.CapturePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, &amount, &final)
+	require.NoError(t, err)
+
+	// 渠道调用时确认金额已预留
+	require.Equal(t, 1, channel.calls)
+	assert.Equal(t, int64(3000), channel.observed[0].PendingCaptureAmount)
+	assert.Equal(t, int64(7000), channel.observed[0].GetRemainingAmount())
+
+	after := repo.snapshot()
+	assert.Equal(t, model.PreAuthStatusPartiallyCaptured, after.Status)
+	assert.Equal(t, int64(3000), after.CapturedAmount)
+	assert.Equal(t, int64(0), after.PendingCaptureAmount)
+	assert.Equal(t, int64(0), after.ReleasedAmount)
+	assert.Equal(t, int64(3000), payment.Amount)
+	require.Len(t, repo.captures, 1)
+	assert.Equal(t, 1, repo.captures[0].Sequence)
+}
+
+func TestCapturePreAuthFinalReservesRemaining(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 0)
+	preAuth := repo.snapshot()
+
+	amount := int64(6000)
+	_, err := svc.CapturePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, &amount, nil)
+	require.NoError(t, err)
+
+	// 最后一次确认预留全部剩余金额，确认后未确认部分释放
+	assert.Equal(t, int64(10000), channel.observed[0].PendingCaptureAmount)
+
+	after := repo.snapshot()
+	assert.Equal(t, model.PreAuthStatusCaptured, after.Status)
+	assert.Equal(t, int64(6000), after.CapturedAmount)
+	assert.Equal(t, int64(4000), after.ReleasedAmount)
+	assert.Equal(t, int64(0), after.PendingCaptureAmount)
+	assert.Equal(t, int64(4000), repo.captures[0].ReleasedAmount)
+}
+
+func TestCapturePreAuthReleasesReservationOnChannelFailure(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 1)
+	preAuth := repo.snapshot()
+
+	amount := int64(3000)
+	_, err := svc.CapturePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, &amount, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, channel.calls)
+
+	after := repo.snapshot()
+	assert.Equal(t, model.PreAuthStatusAuthorized, after.Status)
+	assert.Equal(t, int64(0), after.CapturedAmount)
+	assert.Equal(t, int64(0), after.PendingCaptureAmount)
+	assert.Equal(t, int64(10000), after.GetRemainingAmount())
+	assert.Empty(t, repo.captures)
+	assert.Empty(t, repo.payments)
+}
+
+func TestCapturePreAuthRejectedWhileOperationPending(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 0)
+	repo.preAuth.PendingCaptureAmount = 2000
+	preAuth := repo.snapshot()
+
+	amount := int64(1000)
+	_, err := svc.CapturePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, &amount, nil)
+	require.Error(t, err)
+	assert.Equal(t, 0, channel.calls)
+
+	_, err = svc.IncrementPreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, 500)
+	require.Error(t, err)
+	assert.Equal(t, 0, channel.calls)
+
+	err = svc.CancelPreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, "test")
+	require.Error(t, err)
+	assert.Equal(t, 0, channel.calls)
+
+	_, err = svc.ReauthorizePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo)
+	require.Error(t, err)
+	assert.Equal(t, 0, channel.calls)
+}
+
+func TestIncrementPreAuthReservesBeforeChannelIncrement(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 0)
+	preAuth := repo.snapshot()
+
+	updated, err := svc.IncrementPreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, 2500)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, channel.calls)
+	assert.Equal(t, int64(2500), channel.observed[0].PendingIncrementAmount)
+	assert.Equal(t, int64(10000), channel.observed[0].Amount)
+
+	after := repo.snapshot()
+	assert.Equal(t, int64(12500), after.Amount)
+	assert.Equal(t, int64(0), after.PendingIncrementAmount)
+	assert.Equal(t, 1, after.IncrementCount)
+	assert.Equal(t, int64(12500), updated.Amount)
+}
+
+func TestIncrementPreAuthReleasesReservationOnChannelFailure(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 1)
+	preAuth := repo.snapshot()
+
+	_, err := svc.IncrementPreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, 2500)
+	require.Error(t, err)
+	assert.Equal(t, 1, channel.calls)
+
+	after := repo.snapshot()
+	assert.Equal(t, int64(10000), after.Amount)
+	assert.Equal(t, int64(0), after.PendingIncrementAmount)
+	assert.Equal(t, 0, after.IncrementCount)
+	assert.True(t, after.CanIncrement())
+}
+
+func TestCancelPreAuthReservesBeforeChannelCancel(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 0)
+	preAuth := repo.snapshot()
+
+	err := svc.CancelPreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo, "test")
+	require.NoError(t, err)
+
+	// 渠道调用时取消已预留，此时的确认会被拒绝
+	require.Equal(t, 1, channel.calls)
+	assert.Equal(t, model.PreAuthOperationCancel, channel.observed[0].PendingOperation)
+	assert.False(t, channel.observed[0].CanCapture())
+
+	after := repo.snapshot()
+	assert.Equal(t, model.PreAuthStatusCancelled, after.Status)
+	assert.Equal(t, int64(10000), after.ReleasedAmount)
+	assert.Empty(t, after.PendingOperation)
+}
+
+func TestReauthorizePreAuthReleasesReservationOnChannelFailure(t *testing.T) {
+	svc, repo, channel := newPreAuthTestService(t, 1)
+	preAuth := repo.snapshot()
+
+	_, err := svc.ReauthorizePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo)
+	require.Error(t, err)
+	require.Equal(t, 1, channel.calls)
+	assert.Equal(t, model.PreAuthOperationReauthorize, channel.observed[0].PendingOperation)
+
+	after := repo.snapshot()
+	assert.Equal(t, "pi_1", after.ChannelTradeNo)
+	assert.Empty(t, after.PendingOperation)
+	assert.True(t, after.CanReauthorize())
+}
+
+func TestReauthorizePreAuthClearsReservation(t *testing.T) {
+	svc, repo, _ := newPreAuthTestService(t, 0)
+	preAuth := repo.snapshot()
+
+	updated, err := svc.ReauthorizePreAuth(context.Background(), preAuth.MerchantID, preAuth.PreAuthNo)
+	require.NoError(t, err)
+
+	after := repo.snapshot()
+	assert.Equal(t, "pi_2", after.ChannelTradeNo)
+	assert.Equal(t, 1, after.ReauthCount)
+	assert.Empty(t, after.PendingOperation)
+	assert.Equal(t, "pi_2", updated.ChannelTradeNo)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/document"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	exportpkg "github.com/payment-platform/pkg/export"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+	"payment-platform/payment-gateway/internal/client"
+	"payment-platform/payment-gateway/internal/model"
+	"payment-platform/payment-gateway/internal/repository"
+)
+
+// ReceiptService 电子收据服务
+type ReceiptService interface {
+	// GetReceipt 获取支付收据或退款凭证 PDF（首次访问时生成并保存）
+	// merchantID 为 uuid.Nil 时不校验归属（用于带签名令牌的公开链接）
+	GetReceipt(ctx context.Context, merchantID uuid.UUID, sourceType, sourceNo, language string) (*ReceiptFile, error)
+	// ReceiptURL 生成可直接下载收据的公开链接（用于通知邮件）
+	ReceiptURL(sourceType, sourceNo string) string
+	// VerifyReceiptToken 校验公开链接中的令牌
+	VerifyReceiptToken(sourceType, sourceNo, token string) bool
+}
+
+// ReceiptFile 收据文件
+type ReceiptFile struct {
+	ReceiptNo string
+	FileName  string
+	Content   []byte
+}
+
+type receiptService struct {
+	paymentRepo   repository.PaymentRepository
+	receiptRepo   repository.ReceiptRepository
+	exportService *exportpkg.ExportService
+	cashierClient client.CashierClient
+	publicBaseURL string
+	signingKey    []byte
+	tokenTTL      time.Duration
+}
+
+// NewReceiptService 创建电子收据服务
+// publicBaseURL 为对外可访问的网关地址，signingKey 用于签发公开下载链接，tokenTTL 为链接有效期
+func NewReceiptService(
+	paymentRepo repository.PaymentRepository,
+	receiptRepo repository.ReceiptRepository,
+	exportService *exportpkg.ExportService,
+	cashierClient client.CashierClient,
+	publicBaseURL string,
+	signingKey string,
+	tokenTTL time.Duration,
+) ReceiptService {
+	return &receiptService{
+		paymentRepo:   paymentRepo,
+		receiptRepo:   receiptRepo,
+		exportService: exportService,
+		cashierClient: cashierClient,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+		signingKey:    []byte(signingKey),
+		tokenTTL:      tokenTTL,
+	}
+}
+
+// GetReceipt 获取收据
+func (s *receiptService) GetReceipt(ctx context.Context, merchantID uuid.UUID, sourceType, sourceNo, language string) (*ReceiptFile, error) {
+	var (
+		receipt   *document.Receipt
+		ownerID   uuid.UUID
+		extraLang string
+	)
+
+	switch sourceType {
+	case model.ReceiptSourcePayment:
+		payment, err := s.paymentRepo.GetByPaymentNo(ctx, sourceNo)
+		if err != nil {
+			return nil, fmt.Errorf("查询支付记录失败: %w", err)
+		}
+		if payment == nil || (merchantID != uuid.Nil && payment.MerchantID != merchantID) {
+			return nil, pkgerrors.NewNotFoundError("支付记录不存在")
+		}
+		if payment.Status != model.PaymentStatusSuccess {
+			return nil, pkgerrors.NewBusinessError(pkgerrors.ErrCodeBadRequest, "支付未成功，无法开具收据")
+		}
+		receipt, extraLang = buildPaymentReceipt(payment)
+		ownerID = payment.MerchantID
+
+	case model.ReceiptSourceRefund:
+		refund, err := s.paymentRepo.GetRefundByRefundNo(ctx, sourceNo)
+		if err != nil {
+			return nil, fmt.Errorf("查询退款记录失败: %w", err)
+		}
+		if refund == nil || (merchantID != uuid.Nil && refund.MerchantID != merchantID) {
+			return nil, pkgerrors.NewNotFoundError("退款记录不存在")
+		}
+		if refund.Status != model.RefundStatusSuccess {
+			return nil, pkgerrors.NewBusinessError(pkgerrors.ErrCodeBadRequest, "退款未成功，无法开具退款凭证")
+		}
+		payment := refund.Payment
+		if payment == nil {
+			if payment, err = s.paymentRepo.GetByID(ctx, refund.PaymentID); err != nil {
+				return nil, fmt.Errorf("查询原支付记录失败: %w", err)
+			}
+		}
+		receipt, extraLang = buildRefundReceipt(refund, payment)
+		ownerID = refund.MerchantID
+
+	default:
+		return nil, pkgerrors.NewInvalidRequestError("不支持的收据类型: " + sourceType)
+	}
+
+	// 语言优先级：请求参数 > 支付时的语言 > 收银台默认语言
+	branding := s.getBranding(ctx, ownerID)
+	lang := language
+	if lang == "" {
+		lang = extraLang
+	}
+	if lang == "" && branding != nil {
+		lang = branding.DefaultLanguage
+	}
+	lang = document.NormalizeLanguage(lang)
+
+	// 已生成过则直接读取文件（文件被导出清理任务删除时重新生成）
+	existing, err := s.receiptRepo.GetBySource(ctx, sourceType, sourceNo, lang)
+	if err != nil {
+		return nil, fmt.Errorf("查询收据失败: %w", err)
+	}
+	if existing != nil {
+		if task, err := s.exportService.GetExportTask(ctx, existing.ExportTaskID, ownerID); err == nil {
+			if content, err := s.exportService.ReadFile(task); err == nil {
+				return &ReceiptFile{ReceiptNo: existing.ReceiptNo, FileName: existing.FileName, Content: content}, nil
+			}
+		}
+		logger.Info("收据文件已失效，重新生成",
+			zap.String("source_type", sourceType),
+			zap.String("source_no", sourceNo),
+			zap.String("language", lang))
+	}
+
+	receipt.Language = lang
+	receipt.IssuedAt = time.Now()
+	if branding != nil {
+		receipt.Branding = document.Branding{Name: branding.BrandName, ThemeColor: branding.ThemeColor}
+	}
+
+	content, err := document.RenderReceipt(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("生成收据失败: %w", err)
+	}
+
+	fileName := fmt.Sprintf("receipt_%s_%s_%s.pdf", sourceType, sourceNo, lang)
+	task, err := s.exportService.StoreFile(ctx, ownerID, "receipt", "pdf", fileName, content)
+	if err != nil {
+		return nil, fmt.Errorf("保存收据失败: %w", err)
+	}
+
+	if err := s.receiptRepo.Save(ctx, &model.Receipt{
+		MerchantID:   ownerID,
+		ReceiptNo:    receipt.ReceiptNo,
+		SourceType:   sourceType,
+		SourceNo:     sourceNo,
+		Language:     lang,
+		ExportTaskID: task.ID,
+		FileName:     task.FileName,
+		FileSize:     task.FileSize,
+	}); err != nil {
+		// 记录保存失败不影响本次下载，下次访问会重新生成
+		logger.Warn("保存收据记录失败",
+			zap.Error(err),
+			zap.String("source_type", sourceType),
+			zap.String("source_no", sourceNo))
+	}
+
+	logger.Info("收据已生成",
+		zap.String("receipt_no", receipt.ReceiptNo),
+		zap.String("language", lang),
+		zap.Int("size", len(content)))
+
+	return &ReceiptFile{ReceiptNo: receipt.ReceiptNo, FileName: task.FileName, Content: content}, nil
+}
+
+// ReceiptURL 生成收据公开链接（令牌在 tokenTTL 后过期）
+func (s *receiptService) ReceiptURL(sourceType, sourceNo string) string {
+	expiresAt := time.Now().Add(s.tokenTTL).Unix()
+	return fmt.Sprintf("%s/api/v1/receipts/%s/%s?token=%s",
+		s.publicBaseURL, sourceType, url.PathEscape(sourceNo), s.receiptToken(sourceType, sourceNo, expiresAt))
+}
+
+// VerifyReceiptToken 校验收据令牌（格式为 "过期时间戳.签名"，过期时间参与签名）
+func (s *receiptService) VerifyReceiptToken(sourceType, sourceNo, token string) bool {
+	if len(s.signingKey) == 0 || token == "" {
+		return false
+	}
+	exp, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(s.receiptToken(sourceType, sourceNo, expiresAt)))
+}
+
+func (s *receiptService) receiptToken(sourceType, sourceNo string, expiresAt int64) string {
+	exp := strconv.FormatInt(expiresAt, 10)
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("receipt:" + sourceType + ":" + sourceNo + ":" + exp))
+	return exp + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// getBranding 获取商户品牌（失败时使用默认外观）
+func (s *receiptService) getBranding(ctx context.Context, merchantID uuid.UUID) *client.MerchantBranding {
+	if s.cashierClient == nil {
+		return nil
+	}
+	branding, err := s.cashierClient.GetBranding(ctx, merchantID)
+	if err != nil {
+		logger.Warn("获取商户品牌信息失败，使用默认外观",
+			zap.Error(err),
+			zap.String("merchant_id", merchantID.String()))
+		return nil
+	}
+	return branding
+}
+
+// receiptExtra 支付扩展信息中与收据相关的字段
+type receiptExtra struct {
+	Language  string `json:"language"`
+	LineItems []struct {
+		Description string `json:"description"`
+		Quantity    int    `json:"quantity"`
+		UnitPrice   int64  `json:"unit_price"`
+		Amount      int64  `json:"amount"`
+	} `json:"line_items"`
+	TaxLines []struct {
+		Name    string `json:"name"`
+		RateBps int    `json:"rate_bps"`
+		Amount  int64  `json:"amount"`
+	} `json:"tax_lines"`
+}
+
+func parseReceiptExtra(extra string) *receiptExtra {
+	var e receiptExtra
+	if extra != "" {
+		if err := json.Unmarshal([]byte(extra), &e); err != nil {
+			logger.Warn("解析支付扩展信息失败", zap.Error(err))
+		}
+	}
+	return &e
+}
+
+func buildPaymentReceipt(payment *model.Payment) (*document.Receipt, string) {
+	extra := parseReceiptExtra(payment.Extra)
+
+	r := &document.Receipt{
+		Kind:          document.ReceiptKindPayment,
+		ReceiptNo:     "RCP-" + payment.PaymentNo,
+		PaymentNo:     payment.PaymentNo,
+		OrderNo:       payment.OrderNo,
+		Description:   payment.Description,
+		PayMethod:     payment.PayMethod,
+		CustomerName:  payment.CustomerName,
+		CustomerEmail: payment.CustomerEmail,
+		Currency:      payment.Currency,
+		Total:         payment.Amount,
+	}
+	if payment.PaidAt != nil {
+		r.OccurredAt = *payment.PaidAt
+	}
+	for _, item := range extra.LineItems {
+		r.Items = append(r.Items, document.LineItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+	for _, t := range extra.TaxLines {
+		r.TaxLines = append(r.TaxLines, document.TaxLine{Name: t.Name, RateBps: t.RateBps, Amount: t.Amount})
+	}
+	return r, extra.Language
+}
+
+func buildRefundReceipt(refund *model.Refund, payment *model.Payment) (*document.Receipt, string) {
+	r := &document.Receipt{
+		Kind:        document.ReceiptKindRefund,
+		ReceiptNo:   "RFD-" + refund.RefundNo,
+		RefundNo:    refund.RefundNo,
+		Description: refund.Description,
+		Currency:    refund.Currency,
+		Total:       refund.Amount,
+		Reason:      refund.Reason,
+	}
+	if refund.RefundedAt != nil {
+		r.OccurredAt = *refund.RefundedAt
+	}
+
+	var lang string
+	if payment != nil {
+		extra := parseReceiptExtra(payment.Extra)
+		lang = extra.Language
+		r.PaymentNo = payment.PaymentNo
+		r.OrderNo = payment.OrderNo
+		r.PayMethod = payment.PayMethod
+		r.CustomerName = payment.CustomerName
+		r.CustomerEmail = payment.CustomerEmail
+		if r.Description == "" {
+			r.Description = payment.Description
+		}
+
+		// 按退款比例折算原支付的税费
+		if payment.Amount > 0 {
+			for _, t := range extra.TaxLines {
+				r.TaxLines = append(r.TaxLines, document.TaxLine{
+					Name:    t.Name,
+					RateBps: t.RateBps,
+					Amount:  t.Amount * refund.Amount / payment.Amount,
+				})
+			}
+		}
+	}
+	return r, lang
+}
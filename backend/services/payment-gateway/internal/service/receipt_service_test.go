@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"payment-platform/payment-gateway/internal/model"
+)
+
+func receiptTokenFromURL(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func TestReceiptTokenExpiry(t *testing.T) {
+	key := strings.Repeat("k", 32)
+	svc := NewReceiptService(nil, nil, nil, nil, "https://pay.example.com/", key, time.Hour)
+
+	link := svc.ReceiptURL(model.ReceiptSourcePayment, "PAY001")
+	assert.True(t, strings.HasPrefix(link, "https://pay.example.com/api/v1/receipts/payment/PAY001?token="))
+	token := receiptTokenFromURL(t, link)
+	assert.True(t, svc.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY001", token))
+
+	// 令牌绑定单据类型和单号
+	assert.False(t, svc.VerifyReceiptToken(model.ReceiptSourceRefund, "PAY001", token))
+	assert.False(t, svc.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY002", token))
+
+	// 篡改过期时间后签名失效
+	exp, sig, ok := strings.Cut(token, ".")
+	require.True(t, ok)
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	require.NoError(t, err)
+	extended := strconv.FormatInt(expiresAt+86400, 10) + "." + sig
+	assert.False(t, svc.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY001", extended))
+
+	// 过期的令牌无效
+	expired := NewReceiptService(nil, nil, nil, nil, "", key, -time.Minute)
+	token = receiptTokenFromURL(t, expired.ReceiptURL(model.ReceiptSourcePayment, "PAY001"))
+	assert.False(t, expired.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY001", token))
+
+	// 旧格式（不含过期时间）的令牌和其他密钥签发的令牌均无效
+	assert.False(t, svc.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY001", sig))
+	other := NewReceiptService(nil, nil, nil, nil, "", strings.Repeat("x", 32), time.Hour)
+	token = receiptTokenFromURL(t, other.ReceiptURL(model.ReceiptSourcePayment, "PAY001"))
+	assert.False(t, svc.VerifyReceiptToken(model.ReceiptSourcePayment, "PAY001", token))
+}
@@ -30,32 +30,22 @@ func NewRefundSagaService(
 	orderClient *client.OrderClient,
 	accountingClient interface{}, // 保留参数兼容性，但不使用
 ) *RefundSagaService {
-	return &RefundSagaService{
+	s := &RefundSagaService{
 		orchestrator:  orchestrator,
 		paymentRepo:   paymentRepo,
 		channelClient: channelClient,
 		orderClient:   orderClient,
 	}
+	orchestrator.RegisterStepDefinitions(SagaTypeRefund, s.recoverStepDefinitions)
+	return s
 }
 
-// ExecuteRefundSaga 执行退款 Saga
-func (s *RefundSagaService) ExecuteRefundSaga(
-	ctx context.Context,
-	refund *model.Refund,
-	payment *model.Payment,
-) error {
-	// 1. 构建 Saga
-	sagaBuilder := s.orchestrator.NewSagaBuilder(refund.RefundNo, "refund")
-	sagaBuilder.SetMetadata(map[string]interface{}{
-		"refund_no":   refund.RefundNo,
-		"payment_no":  payment.PaymentNo,
-		"merchant_id": payment.MerchantID.String(),
-		"amount":      refund.Amount,
-		"currency":    payment.Currency,
-	})
+// SagaTypeRefund 退款 Saga 业务类型
+const SagaTypeRefund = "refund"
 
-	// 2. 定义步骤
-	stepDefs := []saga.StepDefinition{
+// stepDefinitions 退款 Saga 步骤定义
+func (s *RefundSagaService) stepDefinitions(refund *model.Refund, payment *model.Payment) []saga.StepDefinition {
+	return []saga.StepDefinition{
 		{
 			Name: "CallChannelRefund",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
@@ -90,6 +80,38 @@ func (s *RefundSagaService) ExecuteRefundSaga(
 			Timeout:       10 * time.Second,
 		},
 	}
+}
+
+// recoverStepDefinitions 恢复时根据 refund_no 重新加载退款及其支付记录并重建步骤定义
+func (s *RefundSagaService) recoverStepDefinitions(ctx context.Context, sagaInstance *saga.Saga) ([]saga.StepDefinition, error) {
+	refund, err := s.paymentRepo.GetRefundByRefundNo(ctx, sagaInstance.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	if refund == nil || refund.Payment == nil {
+		return nil, fmt.Errorf("refund or payment not found: %s", sagaInstance.BusinessID)
+	}
+	return s.stepDefinitions(refund, refund.Payment), nil
+}
+
+// ExecuteRefundSaga 执行退款 Saga
+func (s *RefundSagaService) ExecuteRefundSaga(
+	ctx context.Context,
+	refund *model.Refund,
+	payment *model.Payment,
+) error {
+	// 1. 构建 Saga
+	sagaBuilder := s.orchestrator.NewSagaBuilder(refund.RefundNo, SagaTypeRefund)
+	sagaBuilder.SetMetadata(map[string]interface{}{
+		"refund_no":   refund.RefundNo,
+		"payment_no":  payment.PaymentNo,
+		"merchant_id": payment.MerchantID.String(),
+		"amount":      refund.Amount,
+		"currency":    payment.Currency,
+	})
+
+	// 2. 定义步骤
+	stepDefs := s.stepDefinitions(refund, payment)
 
 	// 添加步骤到构建器
 	for _, def := range stepDefs {
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkgerrors "github.com/payment-platform/pkg/errors"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/router"
+	"go.uber.org/zap"
+	"payment-platform/payment-gateway/internal/model"
+	"payment-platform/payment-gateway/internal/repository"
+)
+
+// 模拟样本数量限制
+const (
+	defaultSimulationLimit = 1000
+	maxSimulationLimit     = 5000
+	maxSimulationSamples   = 50
+)
+
+// RoutingRuleService 路由规则管理、路由解释与 what-if 模拟
+type RoutingRuleService interface {
+	ListRules(ctx context.Context, merchantID *uuid.UUID) ([]*model.PaymentRoute, error)
+	CreateRule(ctx context.Context, input *RoutingRuleInput) (*model.PaymentRoute, error)
+	UpdateRule(ctx context.Context, id uuid.UUID, input *RoutingRuleInput) (*model.PaymentRoute, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	// Explain 解释请求在当前规则与策略下会选择哪个渠道
+	Explain(ctx context.Context, req *router.RoutingRequest) (*router.RoutingExplanation, error)
+	// Simulate 用历史支付回放路由决策，对比实际渠道（可传入候选规则做 what-if）
+	Simulate(ctx context.Context, input *RoutingSimulationInput) (*RoutingSimulationReport, error)
+}
+
+// RoutingRuleInput 路由规则创建/更新参数
+type RoutingRuleInput struct {
+	Name        string                `json:"name" binding:"required"`
+	Priority    int                   `json:"priority"`
+	MerchantID  *uuid.UUID            `json:"merchant_id"`
+	Channel     string                `json:"channel" binding:"required"`
+	Channels    []string              `json:"channels"`
+	Conditions  router.RuleConditions `json:"conditions"`
+	IsEnabled   *bool                 `json:"is_enabled"`
+	Description string                `json:"description"`
+}
+
+// RoutingSimulationInput 路由模拟参数
+type RoutingSimulationInput struct {
+	MerchantID *uuid.UUID            `json:"merchant_id"`
+	StartTime  time.Time             `json:"start_time" binding:"required"`
+	EndTime    time.Time             `json:"end_time" binding:"required"`
+	Limit      int                   `json:"limit"`
+	Rules      []*router.RoutingRule `json:"rules"` // 候选规则，为空时使用当前生效规则
+}
+
+// RoutingSimulationReport 路由模拟报告
+type RoutingSimulationReport struct {
+	Sampled             int                        `json:"sampled"`
+	Changed             int                        `json:"changed"`    // 模拟渠道与实际渠道不同的笔数
+	Unroutable          int                        `json:"unroutable"` // 模拟时无可用渠道的笔数
+	ActualFee           int64                      `json:"actual_fee"`
+	SimulatedFee        int64                      `json:"simulated_fee"`
+	ActualSuccessRate   float64                    `json:"actual_success_rate"`
+	ExpectedSuccessRate float64                    `json:"expected_success_rate"` // 按模拟渠道当前实时成功率估算
+	RuleHits            map[string]int             `json:"rule_hits"`
+	Channels            []*ChannelSimulationStat   `json:"channels"`
+	Samples             []*RoutingSimulationSample `json:"samples"` // 渠道变化的样例
+}
+
+// ChannelSimulationStat 单渠道模拟对比
+type ChannelSimulationStat struct {
+	Channel        string `json:"channel"`
+	ActualCount    int    `json:"actual_count"`
+	SimulatedCount int    `json:"simulated_count"`
+}
+
+// RoutingSimulationSample 模拟样例
+type RoutingSimulationSample struct {
+	PaymentNo        string    `json:"payment_no"`
+	Amount           int64     `json:"amount"`
+	Currency         string    `json:"currency"`
+	Country          string    `json:"country"`
+	PayMethod        string    `json:"pay_method"`
+	ActualChannel    string    `json:"actual_channel"`
+	SimulatedChannel string    `json:"simulated_channel"`
+	Rule             string    `json:"rule,omitempty"`
+	Strategy         string    `json:"strategy,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type routingRuleService struct {
+	paymentRepo   repository.PaymentRepository
+	routerService *router.RouterService
+}
+
+// NewRoutingRuleService 创建路由规则服务，routerService 为 nil 时仅支持规则管理
+func NewRoutingRuleService(paymentRepo repository.PaymentRepository, routerService *router.RouterService) RoutingRuleService {
+	return &routingRuleService{
+		paymentRepo:   paymentRepo,
+		routerService: routerService,
+	}
+}
+
+// NewRouteRuleProvider 以数据库中启用的路由规则作为智能路由的规则来源
+func NewRouteRuleProvider(paymentRepo repository.PaymentRepository) router.RuleProvider {
+	return &routeRuleProvider{paymentRepo: paymentRepo}
+}
+
+type routeRuleProvider struct {
+	paymentRepo repository.PaymentRepository
+}
+
+func (p *routeRuleProvider) ListRules(ctx context.Context) ([]*router.RoutingRule, error) {
+	routes, err := p.paymentRepo.ListActiveRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*router.RoutingRule, 0, len(routes))
+	for _, route := range routes {
+		rule, err := toRoutingRule(route)
+		if err != nil {
+			logger.Warn("路由规则条件无效，已跳过",
+				zap.String("route_id", route.ID.String()),
+				zap.String("name", route.Name),
+				zap.Error(err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// toRoutingRule 将数据库路由规则转换为路由引擎规则
+func toRoutingRule(route *model.PaymentRoute) (*router.RoutingRule, error) {
+	var conditions router.RuleConditions
+	if route.Conditions != "" {
+		if err := json.Unmarshal([]byte(route.Conditions), &conditions); err != nil {
+			return nil, fmt.Errorf("解析路由条件失败: %w", err)
+		}
+	}
+	rule := &router.RoutingRule{
+		ID:         route.ID.String(),
+		Name:       route.Name,
+		Priority:   route.Priority,
+		Channels:   route.CandidateChannels(),
+		Conditions: conditions,
+	}
+	if route.MerchantID != nil {
+		rule.MerchantID = route.MerchantID.String()
+	}
+	return rule, nil
+}
+
+func (s *routingRuleService) ListRules(ctx context.Context, merchantID *uuid.UUID) ([]*model.PaymentRoute, error) {
+	return s.paymentRepo.ListRoutes(ctx, merchantID)
+}
+
+func (s *routingRuleService) CreateRule(ctx context.Context, input *RoutingRuleInput) (*model.PaymentRoute, error) {
+	route := &model.PaymentRoute{IsEnabled: true}
+	if err := applyRoutingRuleInput(route, input); err != nil {
+		return nil, err
+	}
+	if err := s.paymentRepo.CreateRoute(ctx, route); err != nil {
+		return nil, fmt.Errorf("创建路由规则失败: %w", err)
+	}
+	s.invalidate()
+	return route, nil
+}
+
+func (s *routingRuleService) UpdateRule(ctx context.Context, id uuid.UUID, input *RoutingRuleInput) (*model.PaymentRoute, error) {
+	route, err := s.paymentRepo.GetRouteByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	if route == nil {
+		return nil, pkgerrors.NewNotFoundError("路由规则不存在")
+	}
+	if err := applyRoutingRuleInput(route, input); err != nil {
+		return nil, err
+	}
+	if err := s.paymentRepo.UpdateRoute(ctx, route); err != nil {
+		return nil, fmt.Errorf("更新路由规则失败: %w", err)
+	}
+	s.invalidate()
+	return route, nil
+}
+
+func (s *routingRuleService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	route, err := s.paymentRepo.GetRouteByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("查询路由规则失败: %w", err)
+	}
+	if route == nil {
+		return pkgerrors.NewNotFoundError("路由规则不存在")
+	}
+	if err := s.paymentRepo.DeleteRoute(ctx, id); err != nil {
+		return fmt.Errorf("删除路由规则失败: %w", err)
+	}
+	s.invalidate()
+	return nil
+}
+
+// invalidate 规则变更后让本实例立即生效（其他实例在缓存过期后生效）
+func (s *routingRuleService) invalidate() {
+	if s.routerService != nil {
+		s.routerService.InvalidateRules()
+	}
+}
+
+func applyRoutingRuleInput(route *model.PaymentRoute, input *RoutingRuleInput) error {
+	route.Name = input.Name
+	route.Priority = input.Priority
+	route.MerchantID = input.MerchantID
+	route.Channel = input.Channel
+	route.Channels = model.StringArray(input.Channels)
+	route.Description = input.Description
+	if input.IsEnabled != nil {
+		route.IsEnabled = *input.IsEnabled
+	}
+
+	conditions := input.Conditions
+	for i := range conditions.Currencies {
+		conditions.Currencies[i] = strings.ToUpper(conditions.Currencies[i])
+	}
+	for i := range conditions.Countries {
+		conditions.Countries[i] = strings.ToUpper(conditions.Countries[i])
+	}
+
+	rule := &router.RoutingRule{Name: route.Name, Channels: route.CandidateChannels(), Conditions: conditions}
+	if err := rule.Validate(); err != nil {
+		return pkgerrors.NewInvalidRequestError(err.Error())
+	}
+
+	data, err := json.Marshal(conditions)
+	if err != nil {
+		return fmt.Errorf("序列化路由条件失败: %w", err)
+	}
+	route.Conditions = string(data)
+	return nil
+}
+
+func (s *routingRuleService) Explain(ctx context.Context, req *router.RoutingRequest) (*router.RoutingExplanation, error) {
+	if s.routerService == nil {
+		return nil, pkgerrors.NewBusinessError(pkgerrors.ErrCodeBadRequest, "智能路由未启用")
+	}
+	req.Currency = strings.ToUpper(req.Currency)
+	req.Country = strings.ToUpper(req.Country)
+	return s.routerService.Explain(ctx, req), nil
+}
+
+func (s *routingRuleService) Simulate(ctx context.Context, input *RoutingSimulationInput) (*RoutingSimulationReport, error) {
+	if s.routerService == nil {
+		return nil, pkgerrors.NewBusinessError(pkgerrors.ErrCodeBadRequest, "智能路由未启用")
+	}
+	if !input.EndTime.After(input.StartTime) {
+		return nil, pkgerrors.NewInvalidRequestError("结束时间必须晚于开始时间")
+	}
+	for _, rule := range input.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, pkgerrors.NewInvalidRequestError(fmt.Sprintf("规则 %s 无效: %s", rule.Name, err.Error()))
+		}
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSimulationLimit
+	}
+	if limit > maxSimulationLimit {
+		limit = maxSimulationLimit
+	}
+
+	startTime, endTime := input.StartTime, input.EndTime
+	payments, _, err := s.paymentRepo.List(ctx, &repository.PaymentQuery{
+		MerchantID: input.MerchantID,
+		StartTime:  &startTime,
+		EndTime:    &endTime,
+		Page:       1,
+		PageSize:   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询历史支付失败: %w", err)
+	}
+
+	report := &RoutingSimulationReport{RuleHits: make(map[string]int)}
+	stats := make(map[string]*ChannelSimulationStat)
+	channelStat := func(channel string) *ChannelSimulationStat {
+		if stat, ok := stats[channel]; ok {
+			return stat
+		}
+		stat := &ChannelSimulationStat{Channel: channel}
+		stats[channel] = stat
+		return stat
+	}
+
+	var actualSuccess int
+	var expectedSuccess float64
+	for _, payment := range payments {
+		report.Sampled++
+		channelStat(payment.Channel).ActualCount++
+		if payment.Status == model.PaymentStatusSuccess {
+			actualSuccess++
+		}
+		if fee, err := s.routerService.EstimateFee(ctx, payment.Channel, payment.Amount); err == nil {
+			report.ActualFee += fee
+		}
+
+		req := &router.RoutingRequest{
+			MerchantID:  payment.MerchantID.String(),
+			Amount:      payment.Amount,
+			Currency:    payment.Currency,
+			Country:     payment.Country,
+			PayMethod:   payment.PayMethod,
+			RequestTime: payment.CreatedAt,
+		}
+		result, err := s.routerService.Simulate(ctx, req, input.Rules)
+		if err != nil {
+			report.Unroutable++
+			continue
+		}
+
+		channelStat(result.Channel).SimulatedCount++
+		report.SimulatedFee += result.EstimatedFee
+		if result.EstimatedFee == 0 {
+			if fee, err := s.routerService.EstimateFee(ctx, result.Channel, payment.Amount); err == nil {
+				report.SimulatedFee += fee
+			}
+		}
+		if result.Rule != "" {
+			report.RuleHits[result.Rule]++
+		}
+		if channelStats, err := s.routerService.GetChannelStats(result.Channel, payment.Currency, payment.Country, payment.PayMethod); err == nil {
+			expectedSuccess += channelStats.SuccessRate
+		}
+
+		if result.Channel != payment.Channel {
+			report.Changed++
+			if len(report.Samples) < maxSimulationSamples {
+				report.Samples = append(report.Samples, &RoutingSimulationSample{
+					PaymentNo:        payment.PaymentNo,
+					Amount:           payment.Amount,
+					Currency:         payment.Currency,
+					Country:          payment.Country,
+					PayMethod:        payment.PayMethod,
+					ActualChannel:    payment.Channel,
+					SimulatedChannel: result.Channel,
+					Rule:             result.Rule,
+					Strategy:         result.Strategy,
+					CreatedAt:        payment.CreatedAt,
+				})
+			}
+		}
+	}
+
+	if report.Sampled > 0 {
+		report.ActualSuccessRate = float64(actualSuccess) / float64(report.Sampled)
+	}
+	if routed := report.Sampled - report.Unroutable; routed > 0 {
+		report.ExpectedSuccessRate = expectedSuccess / float64(routed)
+	}
+	for _, stat := range stats {
+		report.Channels = append(report.Channels, stat)
+	}
+	sort.Slice(report.Channels, func(i, j int) bool {
+		return report.Channels[i].Channel < report.Channels[j].Channel
+	})
+	return report, nil
+}
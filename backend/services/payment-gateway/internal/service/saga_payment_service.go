@@ -29,58 +29,22 @@ func NewSagaPaymentService(
 	orderClient *client.OrderClient,
 	channelClient *client.ChannelClient,
 ) *SagaPaymentService {
-	return &SagaPaymentService{
+	s := &SagaPaymentService{
 		orchestrator:  orchestrator,
 		paymentRepo:   paymentRepo,
 		orderClient:   orderClient,
 		channelClient: channelClient,
 	}
+	orchestrator.RegisterStepDefinitions(SagaTypePayment, s.recoverStepDefinitions)
+	return s
 }
 
-// ExecutePaymentSaga 执行支付 Saga
-func (s *SagaPaymentService) ExecutePaymentSaga(
-	ctx context.Context,
-	payment *model.Payment,
-) error {
-	// 1. 构建 Saga
-	sagaBuilder := s.orchestrator.NewSagaBuilder(payment.PaymentNo, "payment")
-	sagaBuilder.SetMetadata(map[string]interface{}{
-		"payment_no":  payment.PaymentNo,
-		"merchant_id": payment.MerchantID.String(),
-		"order_no":    payment.OrderNo,
-		"amount":      payment.Amount,
-		"currency":    payment.Currency,
-	})
-
-	// 2. 定义步骤（使用带超时的构建方法）
-	// 步骤1: 创建订单（30秒超时）
-	sagaBuilder.AddStepWithTimeout(
-		"CreateOrder",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executeCreateOrder(ctx, payment)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensateCreateOrder(ctx, payment)
-		},
-		3,               // 最多重试3次
-		30*time.Second,  // 30秒超时
-	)
-
-	// 步骤2: 调用支付渠道（60秒超时，因为需要调用外部API）
-	sagaBuilder.AddStepWithTimeout(
-		"CallPaymentChannel",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executeCallPaymentChannel(ctx, payment)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensateCallPaymentChannel(ctx, payment, executeResult)
-		},
-		3,               // 最多重试3次
-		60*time.Second,  // 60秒超时
-	)
+// SagaTypePayment 支付 Saga 业务类型
+const SagaTypePayment = "payment"
 
-	// 获取步骤定义（用于执行）
-	stepDefs := []saga.StepDefinition{
+// stepDefinitions 支付 Saga 步骤定义
+func (s *SagaPaymentService) stepDefinitions(payment *model.Payment) []saga.StepDefinition {
+	return []saga.StepDefinition{
 		{
 			Name: "CreateOrder",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
@@ -104,6 +68,40 @@ func (s *SagaPaymentService) ExecutePaymentSaga(
 			Timeout:       60 * time.Second,
 		},
 	}
+}
+
+// recoverStepDefinitions 恢复时根据 payment_no 重新加载支付记录并重建步骤定义
+func (s *SagaPaymentService) recoverStepDefinitions(ctx context.Context, sagaInstance *saga.Saga) ([]saga.StepDefinition, error) {
+	payment, err := s.paymentRepo.GetByPaymentNo(ctx, sagaInstance.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("payment not found: %s", sagaInstance.BusinessID)
+	}
+	return s.stepDefinitions(payment), nil
+}
+
+// ExecutePaymentSaga 执行支付 Saga
+func (s *SagaPaymentService) ExecutePaymentSaga(
+	ctx context.Context,
+	payment *model.Payment,
+) error {
+	// 1. 构建 Saga
+	sagaBuilder := s.orchestrator.NewSagaBuilder(payment.PaymentNo, SagaTypePayment)
+	sagaBuilder.SetMetadata(map[string]interface{}{
+		"payment_no":  payment.PaymentNo,
+		"merchant_id": payment.MerchantID.String(),
+		"order_no":    payment.OrderNo,
+		"amount":      payment.Amount,
+		"currency":    payment.Currency,
+	})
+
+	// 2. 定义步骤：创建订单（30秒超时）→ 调用支付渠道（60秒超时，因为需要调用外部API）
+	stepDefs := s.stepDefinitions(payment)
+	for _, def := range stepDefs {
+		sagaBuilder.AddStepWithTimeout(def.Name, def.Execute, def.Compensate, def.MaxRetryCount, def.Timeout)
+	}
 
 	// 3. 构建并执行 Saga
 	sagaInstance, err := sagaBuilder.Build(ctx)
@@ -315,26 +313,10 @@ func (s *SagaPaymentService) CompensatePayment(ctx context.Context, paymentNo st
 		return fmt.Errorf("failed to get saga: %w", err)
 	}
 
-	// 获取支付记录
-	payment, err := s.paymentRepo.GetByPaymentNo(ctx, paymentNo)
+	// 通过注册表重建步骤定义（与 ExecutePaymentSaga 相同）
+	stepDefs, err := s.orchestrator.StepDefinitionsFor(ctx, sagaInstance)
 	if err != nil {
-		return fmt.Errorf("failed to get payment: %w", err)
-	}
-
-	// 定义步骤（与 ExecutePaymentSaga 相同）
-	stepDefs := []saga.StepDefinition{
-		{
-			Name: "CreateOrder",
-			Compensate: func(ctx context.Context, compensateData string, executeResult string) error {
-				return s.compensateCreateOrder(ctx, payment)
-			},
-		},
-		{
-			Name: "CallPaymentChannel",
-			Compensate: func(ctx context.Context, compensateData string, executeResult string) error {
-				return s.compensateCallPaymentChannel(ctx, payment, executeResult)
-			},
-		},
+		return err
 	}
 
 	// 执行补偿
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/payment-platform/pkg/events"
+	"github.com/payment-platform/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 推迟发布的分账事件队列
+//
+// 分账支付的成功/退款事件必须携带分账明细，否则财务服务会按非分账支付整笔记入（或冲减）平台商户，
+// 与各收款方的分账账本不一致。分账明细加载或冲正失败时事件进入该队列，由后台任务重新计算后发布。
+const (
+	pendingSplitEventsKey   = "payment-gateway:split-events:pending"
+	deadSplitEventsKey      = "payment-gateway:split-events:dead"
+	maxSplitEventAttempts   = 20
+	splitEventRetryPerBatch = 100
+)
+
+// 推迟发布的事件类型
+const (
+	splitEventPayment = "payment"
+	splitEventRefund  = "refund"
+)
+
+// pendingSplitEvent 待重新发布的分账事件（只保存单号，重试时重新加载支付/退款并计算分账明细）
+type pendingSplitEvent struct {
+	Kind      string `json:"kind"`
+	PaymentNo string `json:"payment_no"`
+	RefundNo  string `json:"refund_no,omitempty"`
+	OldStatus string `json:"old_status,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// deferSplitEvent 将事件放入推迟发布队列
+func (s *paymentService) deferSplitEvent(ctx context.Context, pending *pendingSplitEvent, cause error) {
+	pending.LastError = cause.Error()
+	logger.Warn("split event deferred",
+		zap.String("kind", pending.Kind),
+		zap.String("payment_no", pending.PaymentNo),
+		zap.String("refund_no", pending.RefundNo),
+		zap.Int("attempts", pending.Attempts),
+		zap.Error(cause))
+
+	key := pendingSplitEventsKey
+	if pending.Attempts >= maxSplitEventAttempts {
+		key = deadSplitEventsKey
+		logger.Error("split event exceeded max attempts, manual reconciliation required",
+			zap.String("kind", pending.Kind),
+			zap.String("payment_no", pending.PaymentNo),
+			zap.String("refund_no", pending.RefundNo),
+			zap.Error(cause))
+	}
+
+	if s.redisClient == nil {
+		logger.Error("redis unavailable, split event dropped",
+			zap.String("payment_no", pending.PaymentNo),
+			zap.String("refund_no", pending.RefundNo))
+		return
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		logger.Error("failed to marshal pending split event", zap.Error(err))
+		return
+	}
+	pushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*time.Second)
+	defer cancel()
+	if err := s.redisClient.RPush(pushCtx, key, data).Err(); err != nil {
+		logger.Error("failed to enqueue pending split event",
+			zap.String("payment_no", pending.PaymentNo),
+			zap.String("refund_no", pending.RefundNo),
+			zap.Error(err))
+	}
+}
+
+// RetryPendingSplitEvents 重新计算分账明细并发布推迟的事件，返回本轮发布成功的数量
+func (s *paymentService) RetryPendingSplitEvents(ctx context.Context) (int, error) {
+	if s.redisClient == nil || s.eventPublisher == nil {
+		return 0, nil
+	}
+
+	// 只处理本轮开始时已在队列中的事件，本轮失败重新入队的事件留到下一轮
+	n, err := s.redisClient.LLen(ctx, pendingSplitEventsKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("读取待发布分账事件失败: %w", err)
+	}
+	if n > splitEventRetryPerBatch {
+		n = splitEventRetryPerBatch
+	}
+
+	published := 0
+	for i := int64(0); i < n; i++ {
+		data, err := s.redisClient.LPop(ctx, pendingSplitEventsKey).Bytes()
+		if err != nil {
+			break
+		}
+		var pending pendingSplitEvent
+		if err := json.Unmarshal(data, &pending); err != nil {
+			logger.Error("invalid pending split event", zap.ByteString("data", data), zap.Error(err))
+			continue
+		}
+		if err := s.publishPendingSplitEvent(ctx, &pending); err != nil {
+			pending.Attempts++
+			s.deferSplitEvent(ctx, &pending, err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publishPendingSplitEvent 同步发布一条推迟的事件
+func (s *paymentService) publishPendingSplitEvent(ctx context.Context, pending *pendingSplitEvent) error {
+	payment, err := s.paymentRepo.GetByPaymentNo(ctx, pending.PaymentNo)
+	if err != nil {
+		return fmt.Errorf("加载支付记录失败: %w", err)
+	}
+	if payment == nil {
+		return fmt.Errorf("支付记录不存在: %s", pending.PaymentNo)
+	}
+
+	var event events.Event
+	switch pending.Kind {
+	case splitEventPayment:
+		eventType := paymentStatusEventType(payment.Status)
+		if eventType == "" {
+			return nil
+		}
+		var splits []events.SplitPosting
+		if eventType == events.PaymentSuccess && s.marketplaceService != nil {
+			if splits, err = s.marketplaceService.SplitPostings(ctx, payment.PaymentNo); err != nil {
+				return fmt.Errorf("加载分账明细失败: %w", err)
+			}
+		}
+		event = s.buildPaymentStatusEvent(payment, eventType, pending.OldStatus, pending.Channel, splits)
+	case splitEventRefund:
+		refund, err := s.paymentRepo.GetRefundByRefundNo(ctx, pending.RefundNo)
+		if err != nil {
+			return fmt.Errorf("加载退款记录失败: %w", err)
+		}
+		if refund == nil {
+			return fmt.Errorf("退款记录不存在: %s", pending.RefundNo)
+		}
+		// 冲正按退款单号幂等，已冲正时直接返回已有分账明细
+		splits, err := s.reverseRefundSplits(ctx, payment, refund)
+		if err != nil {
+			return fmt.Errorf("分账冲正失败: %w", err)
+		}
+		event = s.buildRefundSuccessEvent(payment, refund, splits)
+	default:
+		logger.Error("unknown pending split event kind", zap.String("kind", pending.Kind))
+		return nil
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := s.eventPublisher.Publish(publishCtx, events.TopicPaymentEvents, event); err != nil {
+		return fmt.Errorf("发布事件失败: %w", err)
+	}
+	logger.Info("deferred split event published",
+		zap.String("kind", pending.Kind),
+		zap.String("payment_no", pending.PaymentNo),
+		zap.String("refund_no", pending.RefundNo))
+	return nil
+}
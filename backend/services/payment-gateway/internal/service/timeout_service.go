@@ -44,12 +44,12 @@ func NewTimeoutService(
 func (s *TimeoutService) ScanExpiredPayments(ctx context.Context) error {
 	logger.Info("开始扫描过期支付...")
 
-	// 查询所有过期且仍处于pending状态的支付
+	// 查询所有过期且仍处于pending状态（或消费者未完成 3DS 验证）的支付
 	var expiredPayments []model.Payment
 	now := time.Now()
 
 	err := s.db.WithContext(ctx).
-		Where("status = ? AND expired_at < ? AND expired_at IS NOT NULL", "pending", now).
+		Where("status IN ? AND expired_at < ? AND expired_at IS NOT NULL", []string{"pending", model.PaymentStatusRequiresAction}, now).
 		Limit(100). // 每次处理100条,避免一次性处理过多
 		Find(&expiredPayments).Error
 
@@ -81,6 +81,8 @@ func (s *webhookNotificationService) SendPaymentNotification(
 		PaymentNo:   payment.PaymentNo,
 		OrderNo:     payment.OrderNo,
 		Event:       event,
+		EventID:     webhook.NewEventID(),
+		APIVersion:  resolveWebhookAPIVersion(ctx, s.merchantConfigClient, payment.MerchantID),
 		URL:         notifyURL,
 		Payload:     string(payloadBytes),
 		Status:      model.WebhookStatusPending,
@@ -98,6 +100,25 @@ func (s *webhookNotificationService) SendPaymentNotification(
 	return nil
 }
 
+// resolveWebhookAPIVersion 返回商户固定的 Webhook 负载版本
+// 商户未固定或查询失败时使用 v1（与存量端点的默认版本一致），不随 LatestAPIVersion 升级
+func resolveWebhookAPIVersion(ctx context.Context, configClient client.MerchantConfigClient, merchantID uuid.UUID) string {
+	if configClient == nil {
+		return webhook.APIVersionV1
+	}
+	config, err := configClient.GetWebhookConfig(ctx, merchantID)
+	if err != nil {
+		logger.Warn("获取商户 Webhook 负载版本失败，使用 v1",
+			zap.Error(err),
+			zap.String("merchant_id", merchantID.String()))
+		return webhook.APIVersionV1
+	}
+	if config.APIVersion == "" || !webhook.IsSupportedAPIVersion(config.APIVersion) {
+		return webhook.APIVersionV1
+	}
+	return config.APIVersion
+}
+
 // sendAsync 异步发送通知
 func (s *webhookNotificationService) sendAsync(
 	ctx context.Context,
@@ -111,6 +132,8 @@ func (s *webhookNotificationService) sendAsync(
 		Secret:     secret,
 		Payload:    payload,
 		MerchantID: notification.MerchantID.String(),
+		EventID:    notification.EventID,
+		APIVersion: notification.APIVersion,
 	}
 
 	// 发送（带重试）
@@ -207,6 +230,8 @@ func (s *webhookNotificationService) RetryFailedNotifications(ctx context.Contex
 			Secret:     secret,
 			Payload:    &payload,
 			MerchantID: notification.MerchantID.String(),
+			EventID:    notification.EventID,
+			APIVersion: notification.APIVersion,
 		}
 
 		resp, err := s.retrier.Send(ctx, req)
@@ -2,20 +2,24 @@ package main
 
 import (
 	"log"
+	"strings"
 	"time"
 
 	"github.com/payment-platform/pkg/app"
 	"github.com/payment-platform/pkg/auth"
 	"github.com/payment-platform/pkg/config"
 	"github.com/payment-platform/pkg/configclient"
+	"github.com/payment-platform/pkg/email"
 	"go.uber.org/zap"
 
 	"payment-platform/reconciliation-service/internal/client"
 	"payment-platform/reconciliation-service/internal/downloader"
 	"payment-platform/reconciliation-service/internal/handler"
 	"payment-platform/reconciliation-service/internal/model"
+	"payment-platform/reconciliation-service/internal/notifier"
 	"payment-platform/reconciliation-service/internal/report"
 	"payment-platform/reconciliation-service/internal/repository"
+	"payment-platform/reconciliation-service/internal/scheduler"
 	"payment-platform/reconciliation-service/internal/service"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -93,8 +97,8 @@ func main() {
 	// Create platform data fetcher
 	platformClient := client.NewPlatformClient(paymentGatewayURL)
 
-	// Create report generator
-	reportGenerator := report.NewPDFGenerator(reconRepo, reportPath)
+	// Create report generator (PDF summary + XLSX details)
+	reportGenerator := report.NewGenerator(reconRepo, reportPath, config.GetEnv("REPORT_BRAND_NAME", "Payment Platform"))
 
 	// Create service
 	reconService := service.NewReconciliationService(
@@ -132,30 +136,42 @@ func main() {
 
 	application.Logger.Info("Swagger documentation enabled", zap.String("url", "http://localhost:40020/swagger/index.html"))
 
-	// ✅ Automation Infrastructure Ready:
-	// - AlertNotifier: Fully implemented (email alerts for differences, critical alerts, daily reports)
-	// - DailyScheduler: Implemented in internal/scheduler/daily_scheduler.go
-	// - Extended models: ReconciliationDifference, ReconciliationReport, transactions ready
-	//
-	// To enable automated reconciliation:
-	// 1. Uncomment the scheduler initialization code below
-	// 2. Set ENABLE_AUTO_RECONCILIATION=true in environment
-	// 3. Configure ALERT_EMAIL_RECIPIENTS in environment (comma-separated emails)
-	//
-	// Example:
-	// if config.GetEnv("ENABLE_AUTO_RECONCILIATION", "false") == "true" {
-	//     emailClient := email.NewClient(...)
-	//     recipients := strings.Split(config.GetEnv("ALERT_EMAIL_RECIPIENTS", ""), ",")
-	//     alerter := notifier.NewAlertNotifier(emailClient, application.Logger, recipients)
-	//     scheduler := scheduler.NewDailyScheduler(reconService, alerter, application.Logger)
-	//     scheduler.Start()
-	//     defer scheduler.Stop()
-	// }
+	// 自动对账：每天凌晨2点对前一日各渠道对账，差异告警和每日报告（附 PDF/XLSX 报告）发送到 ALERT_EMAIL_RECIPIENTS
+	automationEnabled := config.GetEnvBool("ENABLE_AUTO_RECONCILIATION", false)
+	if automationEnabled {
+		emailClient, err := email.NewClient(&email.Config{
+			Provider:     "smtp",
+			SMTPHost:     getConfig("SMTP_HOST", "smtp.gmail.com"),
+			SMTPPort:     config.GetEnvInt("SMTP_PORT", 587),
+			SMTPUsername: getConfig("SMTP_USERNAME", ""),
+			SMTPPassword: getConfig("SMTP_PASSWORD", ""),
+			SMTPFrom:     getConfig("SMTP_FROM", "noreply@payment-platform.com"),
+			SMTPFromName: getConfig("SMTP_FROM_NAME", "Payment Platform"),
+		})
+		if err != nil {
+			application.Logger.Fatal("Failed to initialize email client for reconciliation alerts", zap.Error(err))
+		}
+
+		recipients := make([]string, 0)
+		for _, recipient := range strings.Split(config.GetEnv("ALERT_EMAIL_RECIPIENTS", ""), ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				recipients = append(recipients, recipient)
+			}
+		}
+		if len(recipients) == 0 {
+			application.Logger.Warn("ALERT_EMAIL_RECIPIENTS is empty, reconciliation alerts and daily reports will not be delivered")
+		}
+
+		alerter := notifier.NewAlertNotifier(emailClient, application.Logger, recipients)
+		dailyScheduler := scheduler.NewDailyScheduler(reconService, alerter, application.Logger)
+		dailyScheduler.Start()
+		defer dailyScheduler.Stop()
+	}
 
 	application.Logger.Info("Reconciliation service initialized successfully",
 		zap.String("version", "1.0.0"),
 		zap.Bool("automation_ready", true),
-		zap.Bool("automation_enabled", false))
+		zap.Bool("automation_enabled", automationEnabled))
 
 	// Start service with graceful shutdown
 	application.RunWithGracefulShutdown()
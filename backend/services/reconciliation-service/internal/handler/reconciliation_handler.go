@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -47,6 +48,9 @@ func (h *ReconciliationHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 		// Report generation
 		reconciliation.GET("/reports/:task_id", h.GenerateReport)
+		reconciliation.GET("/reports/:task_id/download", h.DownloadReport)
+		reconciliation.POST("/daily-reports", h.GenerateDailyReport)
+		reconciliation.GET("/daily-reports/:report_date/download", h.DownloadDailyReport)
 	}
 }
 
@@ -505,18 +509,100 @@ func (h *ReconciliationHandler) GenerateReport(c *gin.Context) {
 		return
 	}
 
-	reportURL, err := h.service.GenerateReport(c.Request.Context(), taskID)
+	result, err := h.service.GenerateReport(c.Request.Context(), taskID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse("GENERATE_FAILED", err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse(gin.H{
-		"report_url": reportURL,
-		"message":    "Report generated successfully",
+		"report_url":      result.PDFPath,
+		"workbook_url":    result.XLSXPath,
+		"severity_counts": result.SeverityCounts,
+		"message":         "Report generated successfully",
 	}))
 }
 
+// DownloadReport 下载对账报告文件
+// @Summary 下载对账报告文件
+// @Tags Reconciliation
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param task_id path string true "任务ID"
+// @Param format query string false "文件格式（pdf, xlsx），默认 pdf"
+// @Success 200 {file} binary
+// @Router /reconciliation/reports/{task_id}/download [get]
+func (h *ReconciliationHandler) DownloadReport(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_TASK_ID", "Invalid task ID format"))
+		return
+	}
+
+	path, err := h.service.GetReportFile(c.Request.Context(), taskID, c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse("DOWNLOAD_FAILED", err.Error()))
+		return
+	}
+
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+// GenerateDailyReport 生成每日对账报告（汇总当日所有渠道）
+// @Summary 生成每日对账报告
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param body body GenerateDailyReportRequest true "生成每日报告请求"
+// @Success 200 {object} Response{data=model.ReconciliationReport}
+// @Router /reconciliation/daily-reports [post]
+func (h *ReconciliationHandler) GenerateDailyReport(c *gin.Context) {
+	var req GenerateDailyReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	reportDate, err := time.Parse("2006-01-02", req.ReportDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_DATE", "Invalid report_date format, expected YYYY-MM-DD"))
+		return
+	}
+
+	report, err := h.service.GenerateDailyReport(c.Request.Context(), reportDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse("GENERATE_FAILED", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(report))
+}
+
+// DownloadDailyReport 下载每日对账报告文件
+// @Summary 下载每日对账报告文件
+// @Tags Reconciliation
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param report_date path string true "报告日期（YYYY-MM-DD）"
+// @Param format query string false "文件格式（pdf, xlsx），默认 pdf"
+// @Success 200 {file} binary
+// @Router /reconciliation/daily-reports/{report_date}/download [get]
+func (h *ReconciliationHandler) DownloadDailyReport(c *gin.Context) {
+	reportDate, err := time.Parse("2006-01-02", c.Param("report_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_DATE", "Invalid report_date format, expected YYYY-MM-DD"))
+		return
+	}
+
+	path, err := h.service.GetDailyReportFile(c.Request.Context(), reportDate, c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse("DOWNLOAD_FAILED", err.Error()))
+		return
+	}
+
+	c.FileAttachment(path, filepath.Base(path))
+}
+
 // Request DTOs
 
 type CreateTaskRequest struct {
@@ -530,6 +616,10 @@ type ResolveRecordRequest struct {
 	Note       string `json:"note"`
 }
 
+type GenerateDailyReportRequest struct {
+	ReportDate string `json:"report_date" binding:"required"`
+}
+
 type DownloadFileRequest struct {
 	Channel        string `json:"channel" binding:"required"`
 	SettlementDate string `json:"settlement_date" binding:"required"`
@@ -31,8 +31,9 @@ type ReconciliationTask struct {
 	ErrorMessage string `gorm:"type:text" json:"error_message,omitempty"`
 
 	// 文件信息
-	ChannelFileURL string `gorm:"type:varchar(500)" json:"channel_file_url,omitempty"`
-	ReportFileURL  string `gorm:"type:varchar(500)" json:"report_file_url,omitempty"`
+	ChannelFileURL  string `gorm:"type:varchar(500)" json:"channel_file_url,omitempty"`
+	ReportFileURL   string `gorm:"type:varchar(500)" json:"report_file_url,omitempty"`   // PDF 汇总报告
+	WorkbookFileURL string `gorm:"type:varchar(500)" json:"workbook_file_url,omitempty"` // XLSX 明细
 
 	// 时间戳
 	StartedAt   *time.Time     `gorm:"type:timestamptz" json:"started_at,omitempty"`
@@ -115,6 +116,38 @@ const (
 	DiffTypeStatusDiff    = "status_diff"    // 状态不一致
 )
 
+// 差异严重程度常量
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// Severity 按差异类型和差异金额（最小货币单位）评估严重程度，匹配记录返回空字符串
+//
+// 单边记录至少为 high；金额差异 >= 10,000.00 为 critical，>= 1,000.00 为 high，
+// >= 10.00 或状态不一致为 medium，其余为 low。
+func (r *ReconciliationRecord) Severity() string {
+	if r.DiffType == DiffTypeMatched {
+		return ""
+	}
+	amount := r.DiffAmount
+	if amount < 0 {
+		amount = -amount
+	}
+	switch {
+	case amount >= 1000000:
+		return SeverityCritical
+	case amount >= 100000, r.DiffType == DiffTypePlatformOnly, r.DiffType == DiffTypeChannelOnly:
+		return SeverityHigh
+	case amount >= 1000, r.DiffType == DiffTypeStatusDiff:
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
 // ChannelSettlementFile 渠道账单文件表
 type ChannelSettlementFile struct {
 	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -94,6 +94,8 @@ type ReconciliationReport struct {
 	MediumDiffs      int        `gorm:"type:int;default:0" json:"medium_diffs"`
 	LowDiffs         int        `gorm:"type:int;default:0" json:"low_diffs"`
 	TotalAmountDiff  int64      `gorm:"type:bigint;default:0" json:"total_amount_diff"`
+	ReportFileURL    string     `gorm:"type:varchar(500)" json:"report_file_url,omitempty"`   // PDF 汇总报告
+	WorkbookFileURL  string     `gorm:"type:varchar(500)" json:"workbook_file_url,omitempty"` // XLSX 明细
 	ReportSent       bool       `gorm:"type:boolean;default:false" json:"report_sent"`
 	SentAt           *time.Time `gorm:"type:timestamptz" json:"sent_at,omitempty"`
 	CreatedAt        time.Time  `gorm:"type:timestamptz;default:now()" json:"created_at"`
@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -99,20 +102,21 @@ func (n *AlertNotifier) SendCriticalAlert(ctx context.Context, task *model.Recon
 	return nil
 }
 
-// SendDailyReport 发送每日对账报告
-func (n *AlertNotifier) SendDailyReport(ctx context.Context, report *model.ReconciliationReport) error {
+// SendDailyReport 发送每日对账报告，attachments 通常为 ReportAttachments 读取的 PDF/XLSX 报告
+func (n *AlertNotifier) SendDailyReport(ctx context.Context, report *model.ReconciliationReport, attachments ...email.Attachment) error {
 	subject := fmt.Sprintf("【每日对账报告】%s", report.ReportDate.Format("2006-01-02"))
 
-	body, err := n.generateDailyReportEmail(report)
+	body, err := n.generateDailyReportEmail(report, len(attachments) > 0)
 	if err != nil {
 		return fmt.Errorf("failed to generate daily report email: %w", err)
 	}
 
 	// 发送邮件
 	msg := &email.EmailMessage{
-		To:       n.alertRecipients,
-		Subject:  subject,
-		HTMLBody: body,
+		To:          n.alertRecipients,
+		Subject:     subject,
+		HTMLBody:    body,
+		Attachments: attachments,
 	}
 
 	if err := n.emailClient.Send(msg); err != nil {
@@ -122,11 +126,43 @@ func (n *AlertNotifier) SendDailyReport(ctx context.Context, report *model.Recon
 
 	n.logger.Info("Daily report sent",
 		zap.Time("report_date", report.ReportDate),
-		zap.Int("total_tasks", report.TotalTasks))
+		zap.Int("total_tasks", report.TotalTasks),
+		zap.Int("attachments", len(attachments)))
 
 	return nil
 }
 
+// ReportAttachments 读取报告文件作为邮件附件，跳过空路径
+func ReportAttachments(paths ...string) ([]email.Attachment, error) {
+	attachments := make([]email.Attachment, 0, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report file %s: %w", path, err)
+		}
+		attachments = append(attachments, email.Attachment{
+			Filename: filepath.Base(path),
+			Content:  content,
+			MimeType: reportMimeType(path),
+		})
+	}
+	return attachments, nil
+}
+
+func reportMimeType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		return "application/pdf"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
 // filterBySeverity 按严重程度过滤差异
 func (n *AlertNotifier) filterBySeverity(differences []*model.ReconciliationDifference, severity string) []*model.ReconciliationDifference {
 	filtered := make([]*model.ReconciliationDifference, 0)
@@ -335,7 +371,7 @@ func (n *AlertNotifier) generateCriticalAlertEmail(task *model.ReconciliationTas
 }
 
 // generateDailyReportEmail 生成每日报告邮件
-func (n *AlertNotifier) generateDailyReportEmail(report *model.ReconciliationReport) (string, error) {
+func (n *AlertNotifier) generateDailyReportEmail(report *model.ReconciliationReport, hasAttachments bool) (string, error) {
 	tmpl := `
 <!DOCTYPE html>
 <html>
@@ -393,6 +429,10 @@ func (n *AlertNotifier) generateDailyReportEmail(report *model.ReconciliationRep
         </div>
         {{end}}
 
+        {{if .HasAttachments}}
+        <p>完整的 PDF 汇总报告和 XLSX 对账明细见附件。</p>
+        {{end}}
+
         <p style="margin-top: 30px; color: #666; font-size: 12px;">
             报告生成时间: {{.GeneratedAt}}<br>
             此邮件由对账系统自动发送
@@ -424,6 +464,7 @@ func (n *AlertNotifier) generateDailyReportEmail(report *model.ReconciliationRep
 		"HighDiffs":        report.HighDiffs,
 		"MediumDiffs":      report.MediumDiffs,
 		"LowDiffs":         report.LowDiffs,
+		"HasAttachments":   hasAttachments,
 		"GeneratedAt":      time.Now().Format("2006-01-02 15:04:05"),
 	}
 
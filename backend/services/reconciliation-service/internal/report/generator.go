@@ -0,0 +1,123 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"payment-platform/reconciliation-service/internal/model"
+	"payment-platform/reconciliation-service/internal/repository"
+	"payment-platform/reconciliation-service/internal/service"
+)
+
+// recordPageSize 分页读取差异记录的每页条数
+const recordPageSize = 500
+
+// Generator 对账报告生成器（PDF 汇总 + XLSX 全量明细）
+type Generator struct {
+	repo      repository.ReconciliationRepository
+	basePath  string
+	brandName string
+}
+
+// NewGenerator 创建报告生成器
+func NewGenerator(repo repository.ReconciliationRepository, basePath, brandName string) *Generator {
+	return &Generator{
+		repo:      repo,
+		basePath:  basePath,
+		brandName: brandName,
+	}
+}
+
+// Generate 生成单个任务的对账报告
+func (g *Generator) Generate(ctx context.Context, task *model.ReconciliationTask) (*service.ReportResult, error) {
+	records, err := g.listRecords(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pack{
+		title:   "对账报告",
+		date:    task.TaskDate,
+		tasks:   []*model.ReconciliationTask{task},
+		records: records,
+	}
+	baseName := fmt.Sprintf("report-%s-%s", task.TaskNo, time.Now().Format("20060102150405"))
+	return g.write(p, baseName)
+}
+
+// GenerateDaily 生成指定日期所有渠道的对账报告
+func (g *Generator) GenerateDaily(ctx context.Context, reportDate time.Time, tasks []*model.ReconciliationTask) (*service.ReportResult, error) {
+	var records []*model.ReconciliationRecord
+	for _, task := range tasks {
+		taskRecords, err := g.listRecords(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, taskRecords...)
+	}
+
+	p := &pack{
+		title:   "每日对账报告",
+		date:    reportDate,
+		daily:   true,
+		tasks:   tasks,
+		records: records,
+	}
+	baseName := fmt.Sprintf("daily-report-%s-%s", reportDate.Format("20060102"), time.Now().Format("20060102150405"))
+	return g.write(p, baseName)
+}
+
+// listRecords 分页读取任务的全部对账记录（含匹配记录）
+func (g *Generator) listRecords(ctx context.Context, taskID uuid.UUID) ([]*model.ReconciliationRecord, error) {
+	filters := repository.RecordFilters{TaskID: &taskID}
+
+	var records []*model.ReconciliationRecord
+	for page := 1; ; page++ {
+		batch, total, err := g.repo.ListRecords(ctx, filters, page, recordPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("get records failed: %w", err)
+		}
+		records = append(records, batch...)
+		if len(batch) < recordPageSize || int64(len(records)) >= total {
+			return records, nil
+		}
+	}
+}
+
+// write 渲染并写入 PDF 和 XLSX 文件
+func (g *Generator) write(p *pack, baseName string) (*service.ReportResult, error) {
+	// Create directory if not exists
+	if err := os.MkdirAll(g.basePath, 0755); err != nil {
+		return nil, fmt.Errorf("create directory failed: %w", err)
+	}
+
+	s := summarize(p.tasks, p.records)
+
+	pdf, err := g.renderPDF(p, s)
+	if err != nil {
+		return nil, fmt.Errorf("render pdf failed: %w", err)
+	}
+	workbook, err := renderWorkbook(p, s)
+	if err != nil {
+		return nil, fmt.Errorf("render xlsx failed: %w", err)
+	}
+
+	result := &service.ReportResult{
+		PDFPath:        filepath.Join(g.basePath, baseName+".pdf"),
+		XLSXPath:       filepath.Join(g.basePath, baseName+".xlsx"),
+		SeverityCounts: s.bySeverity,
+	}
+	if err := os.WriteFile(result.PDFPath, pdf, 0644); err != nil {
+		return nil, fmt.Errorf("write file failed: %w", err)
+	}
+	if err := os.WriteFile(result.XLSXPath, workbook, 0644); err != nil {
+		return nil, fmt.Errorf("write file failed: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,197 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/payment-platform/pkg/document"
+
+	"payment-platform/reconciliation-service/internal/model"
+)
+
+// maxPDFDiffRows PDF 中展示的未解决差异条数上限，完整明细见 XLSX
+const maxPDFDiffRows = 200
+
+var severityColors = map[string]document.Color{
+	model.SeverityCritical: {R: 0xd3, G: 0x2f, B: 0x2f},
+	model.SeverityHigh:     {R: 0xf5, G: 0x7c, B: 0x00},
+	model.SeverityMedium:   {R: 0xfb, G: 0xc0, B: 0x2d},
+	model.SeverityLow:      {R: 0x8c, G: 0x8c, B: 0x8c},
+}
+
+// renderPDF 渲染 PDF 汇总报告
+func (g *Generator) renderPDF(p *pack, s *summary) ([]byte, error) {
+	r := &document.Report{
+		Language: document.LangZhCN,
+		Location: time.Local,
+		Branding: document.Branding{Name: g.brandName},
+		Title:    p.title,
+		Footer:   fmt.Sprintf("报告生成时间: %s", time.Now().Format("2006-01-02 15:04:05")),
+	}
+
+	if p.daily {
+		completed, failed := 0, 0
+		for _, task := range p.tasks {
+			switch task.Status {
+			case model.TaskStatusCompleted:
+				completed++
+			case model.TaskStatusFailed:
+				failed++
+			}
+		}
+		r.Fields = []document.Field{
+			{Label: "对账日期", Value: p.date.Format("2006-01-02")},
+			{Label: "对账任务数", Value: strconv.Itoa(len(p.tasks))},
+			{Label: "已完成", Value: strconv.Itoa(completed)},
+			{Label: "失败", Value: strconv.Itoa(failed)},
+		}
+	} else {
+		task := p.tasks[0]
+		r.Fields = []document.Field{
+			{Label: "任务编号", Value: task.TaskNo},
+			{Label: "对账日期", Value: task.TaskDate.Format("2006-01-02")},
+			{Label: "支付渠道", Value: task.Channel},
+			{Label: "任务状态", Value: translateTaskStatus(task.Status)},
+			{Label: "创建时间", Value: task.CreatedAt.Format("2006-01-02 15:04:05")},
+			{Label: "完成时间", Value: formatTime(task.CompletedAt)},
+		}
+	}
+
+	r.Sections = append(r.Sections, document.ReportSection{
+		Heading: "统计摘要",
+		Fields: []document.Field{
+			{Label: "平台记录数", Value: strconv.Itoa(s.platformCount)},
+			{Label: "平台总金额", Value: formatAmount(s.platformAmount)},
+			{Label: "渠道记录数", Value: strconv.Itoa(s.channelCount)},
+			{Label: "渠道总金额", Value: formatAmount(s.channelAmount)},
+			{Label: "匹配记录数", Value: strconv.Itoa(s.matchedCount)},
+			{Label: "匹配总金额", Value: formatAmount(s.matchedAmount)},
+			{Label: "差异记录数", Value: strconv.Itoa(s.diffCount)},
+			{Label: "差异总金额", Value: formatAmount(s.diffAmount)},
+			{Label: "匹配率", Value: fmt.Sprintf("%.2f%%", matchRate(s.matchedCount, s.platformCount)*100)},
+			{Label: "未解决差异", Value: strconv.Itoa(s.unresolved)},
+		},
+		Chart: &document.BarChart{Bars: []document.Bar{
+			{Label: "匹配", Value: float64(s.matchedCount), Display: strconv.Itoa(s.matchedCount), Color: &document.Color{R: 0x38, G: 0x8e, B: 0x3c}},
+			{Label: "差异", Value: float64(s.diffCount), Display: strconv.Itoa(s.diffCount), Color: &document.Color{R: 0xf5, G: 0x7c, B: 0x00}},
+		}},
+	})
+
+	if p.daily {
+		rows := make([][]string, 0, len(p.tasks))
+		for _, task := range p.tasks {
+			rows = append(rows, []string{
+				task.Channel,
+				translateTaskStatus(task.Status),
+				strconv.Itoa(task.PlatformCount),
+				strconv.Itoa(task.ChannelCount),
+				strconv.Itoa(task.MatchedCount),
+				strconv.Itoa(task.DiffCount),
+				formatAmount(task.DiffAmount),
+				fmt.Sprintf("%.2f%%", matchRate(task.MatchedCount, task.PlatformCount)*100),
+			})
+		}
+		r.Sections = append(r.Sections, document.ReportSection{
+			Heading: "渠道对账结果",
+			Table: &document.Table{
+				Columns: []document.Column{
+					{Title: "渠道", Width: 0.14},
+					{Title: "状态", Width: 0.1},
+					{Title: "平台笔数", Width: 0.12, AlignRight: true},
+					{Title: "渠道笔数", Width: 0.12, AlignRight: true},
+					{Title: "匹配", Width: 0.12, AlignRight: true},
+					{Title: "差异", Width: 0.1, AlignRight: true},
+					{Title: "差异金额", Width: 0.16, AlignRight: true},
+					{Title: "匹配率", Width: 0.14, AlignRight: true},
+				},
+				Rows: rows,
+			},
+		})
+	}
+
+	typeBars := make([]document.Bar, 0, len(diffTypes))
+	for _, t := range diffTypes {
+		b := s.byType[t]
+		typeBars = append(typeBars, document.Bar{
+			Label:   translateDiffType(t),
+			Value:   float64(b.count),
+			Display: fmt.Sprintf("%d 笔 / %s", b.count, formatAmount(b.amount)),
+		})
+	}
+	severityBars := make([]document.Bar, 0, len(severities))
+	for _, sev := range severities {
+		color := severityColors[sev]
+		severityBars = append(severityBars, document.Bar{
+			Label:   translateSeverity(sev),
+			Value:   float64(s.bySeverity[sev]),
+			Display: fmt.Sprintf("%d 笔", s.bySeverity[sev]),
+			Color:   &color,
+		})
+	}
+	r.Sections = append(r.Sections,
+		document.ReportSection{Heading: "差异类型分布", Chart: &document.BarChart{Bars: typeBars}},
+		document.ReportSection{Heading: "差异严重程度分布", Chart: &document.BarChart{Bars: severityBars}},
+	)
+
+	// 未解决差异按严重程度、差异金额降序排列
+	var unresolved []*model.ReconciliationRecord
+	for _, record := range p.records {
+		if record.DiffType != model.DiffTypeMatched && !record.IsResolved {
+			unresolved = append(unresolved, record)
+		}
+	}
+	sort.SliceStable(unresolved, func(i, j int) bool {
+		ri, rj := severityRank(unresolved[i].Severity()), severityRank(unresolved[j].Severity())
+		if ri != rj {
+			return ri < rj
+		}
+		return absAmount(unresolved[i].DiffAmount) > absAmount(unresolved[j].DiffAmount)
+	})
+
+	note := fmt.Sprintf("完整的匹配与差异明细见 XLSX 附件（共 %d 条记录）。", len(p.records))
+	if len(unresolved) > maxPDFDiffRows {
+		note = fmt.Sprintf("仅展示前 %d 笔未解决差异（共 %d 笔），", maxPDFDiffRows, len(unresolved)) + note
+		unresolved = unresolved[:maxPDFDiffRows]
+	}
+	rows := make([][]string, 0, len(unresolved))
+	for _, record := range unresolved {
+		rows = append(rows, []string{
+			record.PaymentNo,
+			record.ChannelTradeNo,
+			translateDiffType(record.DiffType),
+			translateSeverity(record.Severity()),
+			document.FormatAmount(record.PlatformAmount, record.Currency),
+			document.FormatAmount(record.ChannelAmount, record.Currency),
+			document.FormatAmount(record.DiffAmount, record.Currency),
+		})
+	}
+	section := document.ReportSection{Heading: "未解决差异", Note: note}
+	if len(rows) > 0 {
+		section.Table = &document.Table{
+			Columns: []document.Column{
+				{Title: "支付单号", Width: 0.17},
+				{Title: "渠道交易号", Width: 0.19},
+				{Title: "差异类型", Width: 0.14},
+				{Title: "严重程度", Width: 0.08},
+				{Title: "平台金额", Width: 0.14, AlignRight: true},
+				{Title: "渠道金额", Width: 0.14, AlignRight: true},
+				{Title: "差异金额", Width: 0.14, AlignRight: true},
+			},
+			Rows: rows,
+		}
+	}
+	r.Sections = append(r.Sections, section)
+
+	return document.RenderReport(r)
+}
+
+func severityRank(severity string) int {
+	for i, sev := range severities {
+		if sev == severity {
+			return i
+		}
+	}
+	return len(severities)
+}
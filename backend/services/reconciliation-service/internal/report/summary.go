@@ -0,0 +1,178 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"payment-platform/reconciliation-service/internal/model"
+)
+
+// pack 一份报告的数据：单个任务或某一天所有渠道的任务
+type pack struct {
+	title   string
+	date    time.Time
+	daily   bool
+	tasks   []*model.ReconciliationTask
+	records []*model.ReconciliationRecord
+}
+
+// bucket 分组统计
+type bucket struct {
+	count  int
+	amount int64 // 差异金额绝对值之和
+}
+
+// summary 报告统计
+type summary struct {
+	platformCount  int
+	platformAmount int64
+	channelCount   int
+	channelAmount  int64
+	matchedCount   int
+	matchedAmount  int64
+	diffCount      int
+	diffAmount     int64
+	unresolved     int
+	byType         map[string]*bucket
+	bySeverity     map[string]int
+}
+
+// diffTypes 差异类型的展示顺序
+var diffTypes = []string{
+	model.DiffTypePlatformOnly,
+	model.DiffTypeChannelOnly,
+	model.DiffTypeAmountDiff,
+	model.DiffTypeStatusDiff,
+}
+
+// severities 严重程度的展示顺序
+var severities = []string{
+	model.SeverityCritical,
+	model.SeverityHigh,
+	model.SeverityMedium,
+	model.SeverityLow,
+}
+
+// summarize 汇总任务统计，并按差异类型和严重程度统计差异记录
+func summarize(tasks []*model.ReconciliationTask, records []*model.ReconciliationRecord) *summary {
+	s := &summary{
+		byType:     make(map[string]*bucket),
+		bySeverity: make(map[string]int),
+	}
+	for _, t := range diffTypes {
+		s.byType[t] = &bucket{}
+	}
+	for _, sev := range severities {
+		s.bySeverity[sev] = 0
+	}
+
+	for _, task := range tasks {
+		s.platformCount += task.PlatformCount
+		s.platformAmount += task.PlatformAmount
+		s.channelCount += task.ChannelCount
+		s.channelAmount += task.ChannelAmount
+		s.matchedCount += task.MatchedCount
+		s.matchedAmount += task.MatchedAmount
+		s.diffCount += task.DiffCount
+		s.diffAmount += task.DiffAmount
+	}
+
+	for _, record := range records {
+		if record.DiffType == model.DiffTypeMatched {
+			continue
+		}
+		if !record.IsResolved {
+			s.unresolved++
+		}
+		b, ok := s.byType[record.DiffType]
+		if !ok {
+			b = &bucket{}
+			s.byType[record.DiffType] = b
+		}
+		b.count++
+		b.amount += absAmount(record.DiffAmount)
+		s.bySeverity[record.Severity()]++
+	}
+	return s
+}
+
+// matchRate 匹配率（匹配笔数 / 平台记录数）
+func matchRate(matched, platform int) float64 {
+	if platform == 0 {
+		return 0
+	}
+	return float64(matched) / float64(platform)
+}
+
+func absAmount(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// formatAmount 任务统计金额未区分币种，按两位小数展示
+func formatAmount(amount int64) string {
+	return fmt.Sprintf("%.2f", float64(amount)/100.0)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func translateDiffType(diffType string) string {
+	switch diffType {
+	case model.DiffTypeMatched:
+		return "完全匹配"
+	case model.DiffTypePlatformOnly:
+		return "仅平台有记录"
+	case model.DiffTypeChannelOnly:
+		return "仅渠道有记录"
+	case model.DiffTypeAmountDiff:
+		return "金额不一致"
+	case model.DiffTypeStatusDiff:
+		return "状态不一致"
+	default:
+		return diffType
+	}
+}
+
+func translateSeverity(severity string) string {
+	switch severity {
+	case model.SeverityCritical:
+		return "严重"
+	case model.SeverityHigh:
+		return "高"
+	case model.SeverityMedium:
+		return "中"
+	case model.SeverityLow:
+		return "低"
+	default:
+		return "-"
+	}
+}
+
+func translateResolvedStatus(isResolved bool) string {
+	if isResolved {
+		return "已解决"
+	}
+	return "未解决"
+}
+
+func translateTaskStatus(status string) string {
+	switch status {
+	case model.TaskStatusPending:
+		return "待执行"
+	case model.TaskStatusProcessing:
+		return "执行中"
+	case model.TaskStatusCompleted:
+		return "已完成"
+	case model.TaskStatusFailed:
+		return "失败"
+	default:
+		return status
+	}
+}
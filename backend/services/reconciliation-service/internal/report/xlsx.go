@@ -0,0 +1,128 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/payment-platform/pkg/document"
+
+	"payment-platform/reconciliation-service/internal/model"
+)
+
+// maxSheetRows 单个工作表的数据行上限（XLSX 限制为 1,048,576 行），超出时拆分到新工作表
+const maxSheetRows = 1000000
+
+// renderWorkbook 渲染 XLSX 明细：汇总、差异明细、匹配明细
+func renderWorkbook(p *pack, s *summary) ([]byte, error) {
+	wb := document.NewWorkbook()
+
+	overview := wb.AddSheet("汇总")
+	overview.SetColumnWidths(24, 14, 14, 14, 14, 14, 16, 12)
+	overview.AddRow(document.HeaderCell(p.title), document.TextCell(p.date.Format("2006-01-02")))
+	overview.AddRow()
+	overview.AddRow(document.HeaderCell("项目"), document.HeaderCell("笔数"), document.HeaderCell("金额"))
+	overview.AddRow(document.TextCell("平台记录"), document.NumberCell(float64(s.platformCount)), document.AmountCell(s.platformAmount, ""))
+	overview.AddRow(document.TextCell("渠道记录"), document.NumberCell(float64(s.channelCount)), document.AmountCell(s.channelAmount, ""))
+	overview.AddRow(document.TextCell("匹配记录"), document.NumberCell(float64(s.matchedCount)), document.AmountCell(s.matchedAmount, ""))
+	overview.AddRow(document.TextCell("差异记录"), document.NumberCell(float64(s.diffCount)), document.AmountCell(s.diffAmount, ""))
+	overview.AddRow(document.TextCell("未解决差异"), document.NumberCell(float64(s.unresolved)))
+	overview.AddRow(document.TextCell("匹配率"), document.PercentCell(matchRate(s.matchedCount, s.platformCount)))
+	overview.AddRow()
+
+	overview.AddRow(document.HeaderCell("差异类型"), document.HeaderCell("笔数"), document.HeaderCell("差异金额（绝对值）"))
+	for _, t := range diffTypes {
+		b := s.byType[t]
+		overview.AddRow(document.TextCell(translateDiffType(t)), document.NumberCell(float64(b.count)), document.AmountCell(b.amount, ""))
+	}
+	overview.AddRow()
+
+	overview.AddRow(document.HeaderCell("严重程度"), document.HeaderCell("笔数"))
+	for _, sev := range severities {
+		overview.AddRow(document.TextCell(translateSeverity(sev)), document.NumberCell(float64(s.bySeverity[sev])))
+	}
+	overview.AddRow()
+
+	overview.AddRow(
+		document.HeaderCell("任务编号"), document.HeaderCell("渠道"), document.HeaderCell("状态"),
+		document.HeaderCell("平台笔数"), document.HeaderCell("渠道笔数"), document.HeaderCell("匹配笔数"),
+		document.HeaderCell("差异金额"), document.HeaderCell("匹配率"),
+	)
+	for _, task := range p.tasks {
+		overview.AddRow(
+			document.TextCell(task.TaskNo),
+			document.TextCell(task.Channel),
+			document.TextCell(translateTaskStatus(task.Status)),
+			document.NumberCell(float64(task.PlatformCount)),
+			document.NumberCell(float64(task.ChannelCount)),
+			document.NumberCell(float64(task.MatchedCount)),
+			document.AmountCell(task.DiffAmount, ""),
+			document.PercentCell(matchRate(task.MatchedCount, task.PlatformCount)),
+		)
+	}
+
+	var matched, diffs []*model.ReconciliationRecord
+	for _, record := range p.records {
+		if record.DiffType == model.DiffTypeMatched {
+			matched = append(matched, record)
+		} else {
+			diffs = append(diffs, record)
+		}
+	}
+	addRecordSheets(wb, "差异明细", diffs)
+	addRecordSheets(wb, "匹配明细", matched)
+
+	return wb.Bytes()
+}
+
+var recordHeaders = []string{
+	"任务编号", "支付单号", "渠道交易号", "订单号", "商户ID", "币种",
+	"平台金额", "渠道金额", "差异金额", "平台状态", "渠道状态",
+	"差异类型", "严重程度", "差异原因", "处理状态", "处理备注",
+}
+
+// addRecordSheets 写入记录明细，超过单表行数上限时拆分为多个工作表
+func addRecordSheets(wb *document.Workbook, name string, records []*model.ReconciliationRecord) {
+	for part := 0; part == 0 || part*maxSheetRows < len(records); part++ {
+		sheetName := name
+		if part > 0 {
+			sheetName = fmt.Sprintf("%s (%d)", name, part+1)
+		}
+		sheet := wb.AddSheet(sheetName)
+		sheet.FreezeHeader()
+		sheet.SetColumnWidths(28, 24, 28, 24, 38, 8, 14, 14, 14, 12, 12, 14, 10, 40, 10, 30)
+
+		header := make([]document.Cell, 0, len(recordHeaders))
+		for _, h := range recordHeaders {
+			header = append(header, document.HeaderCell(h))
+		}
+		sheet.AddRow(header...)
+
+		end := (part + 1) * maxSheetRows
+		if end > len(records) {
+			end = len(records)
+		}
+		for _, record := range records[part*maxSheetRows : end] {
+			merchantID := ""
+			if record.MerchantID != nil {
+				merchantID = record.MerchantID.String()
+			}
+			sheet.AddRow(
+				document.TextCell(record.TaskNo),
+				document.TextCell(record.PaymentNo),
+				document.TextCell(record.ChannelTradeNo),
+				document.TextCell(record.OrderNo),
+				document.TextCell(merchantID),
+				document.TextCell(record.Currency),
+				document.AmountCell(record.PlatformAmount, record.Currency),
+				document.AmountCell(record.ChannelAmount, record.Currency),
+				document.AmountCell(record.DiffAmount, record.Currency),
+				document.TextCell(record.PlatformStatus),
+				document.TextCell(record.ChannelStatus),
+				document.TextCell(translateDiffType(record.DiffType)),
+				document.TextCell(translateSeverity(record.Severity())),
+				document.TextCell(record.DiffReason),
+				document.TextCell(translateResolvedStatus(record.IsResolved)),
+				document.TextCell(record.ResolutionNote),
+			)
+		}
+	}
+}
@@ -35,6 +35,10 @@ type ReconciliationRepository interface {
 	GetFileByDateAndChannel(ctx context.Context, settlementDate time.Time, channel string) (*model.ChannelSettlementFile, error)
 	UpdateFile(ctx context.Context, file *model.ChannelSettlementFile) error
 	ListFiles(ctx context.Context, filters FileFilters, page, pageSize int) ([]*model.ChannelSettlementFile, int64, error)
+
+	// 每日报告管理
+	GetReportByDate(ctx context.Context, reportDate time.Time) (*model.ReconciliationReport, error)
+	SaveReport(ctx context.Context, report *model.ReconciliationReport) error
 }
 
 // TaskFilters 任务查询过滤条件
@@ -207,8 +211,9 @@ func (r *reconciliationRepository) ListRecords(ctx context.Context, filters Reco
 
 	// 分页查询
 	offset := (page - 1) * pageSize
+	// 批量写入的记录 created_at 相同，追加 id 保证分页顺序稳定
 	if err := query.
-		Order("created_at DESC").
+		Order("created_at DESC, id").
 		Limit(pageSize).
 		Offset(offset).
 		Find(&records).Error; err != nil {
@@ -320,3 +325,30 @@ func (r *reconciliationRepository) ListFiles(ctx context.Context, filters FileFi
 
 	return files, total, nil
 }
+
+// GetReportByDate 根据日期查询每日报告
+func (r *reconciliationRepository) GetReportByDate(ctx context.Context, reportDate time.Time) (*model.ReconciliationReport, error) {
+	var report model.ReconciliationReport
+	err := r.db.WithContext(ctx).
+		Where("report_date = ?", reportDate.Format("2006-01-02")).
+		First(&report).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &report, err
+}
+
+// SaveReport 保存每日报告（同一日期重复生成时覆盖）
+func (r *reconciliationRepository) SaveReport(ctx context.Context, report *model.ReconciliationReport) error {
+	if report.ID == uuid.Nil {
+		existing, err := r.GetReportByDate(ctx, report.ReportDate)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			report.ID = existing.ID
+			report.CreatedAt = existing.CreatedAt
+		}
+	}
+	return r.db.WithContext(ctx).Save(report).Error
+}
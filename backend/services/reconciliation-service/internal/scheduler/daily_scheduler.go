@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"payment-platform/reconciliation-service/internal/model"
@@ -39,15 +38,13 @@ func (s *DailyScheduler) Start() {
 	s.logger.Info("Starting daily reconciliation scheduler")
 
 	go func() {
+		// 等待下一个凌晨2点，先执行一次再按天触发
+		s.waitUntil(2, 0)
+		s.runDailyReconciliation()
+
 		ticker := time.NewTicker(24 * time.Hour)
 		defer ticker.Stop()
 
-		// 立即执行一次检查（用于测试）
-		// s.runDailyReconciliation()
-
-		// 等待下一个凌晨2点
-		s.waitUntil(2, 0)
-
 		for {
 			select {
 			case <-ticker.C:
@@ -80,9 +77,37 @@ func (s *DailyScheduler) runDailyReconciliation() {
 		s.reconcileChannel(ctx, channel, yesterday)
 	}
 
+	// 汇总各渠道结果生成每日报告，附带 PDF/XLSX 报告发送给告警接收人
+	s.sendDailyReport(ctx, yesterday)
+
 	s.logger.Info("Daily reconciliation completed")
 }
 
+// sendDailyReport 生成并发送每日对账报告（报告文件读取失败时仍发送正文）
+func (s *DailyScheduler) sendDailyReport(ctx context.Context, date time.Time) {
+	report, err := s.reconService.GenerateDailyReport(ctx, date)
+	if err != nil {
+		s.logger.Error("Failed to generate daily report",
+			zap.Time("report_date", date),
+			zap.Error(err))
+		return
+	}
+
+	attachments, err := notifier.ReportAttachments(report.ReportFileURL, report.WorkbookFileURL)
+	if err != nil {
+		s.logger.Warn("Failed to attach daily report files",
+			zap.Time("report_date", date),
+			zap.Error(err))
+		attachments = nil
+	}
+
+	if err := s.alerter.SendDailyReport(ctx, report, attachments...); err != nil {
+		s.logger.Error("Failed to send daily report",
+			zap.Time("report_date", date),
+			zap.Error(err))
+	}
+}
+
 // reconcileChannel 对单个渠道执行对账
 func (s *DailyScheduler) reconcileChannel(ctx context.Context, channel string, date time.Time) {
 	s.logger.Info("Reconciling channel",
@@ -91,10 +116,9 @@ func (s *DailyScheduler) reconcileChannel(ctx context.Context, channel string, d
 
 	// 创建对账任务
 	input := &service.CreateTaskInput{
-		TaskDate:    date,
-		Channel:     channel,
-		TaskType:    "automated_daily",
-		Description: "自动每日对账",
+		TaskDate: date,
+		Channel:  channel,
+		TaskType: "daily",
 	}
 
 	task, err := s.reconService.CreateTask(ctx, input)
@@ -105,35 +129,38 @@ func (s *DailyScheduler) reconcileChannel(ctx context.Context, channel string, d
 		return
 	}
 
-	// 执行对账任务
+	// 执行对账任务（失败时任务状态由 ExecuteTask 置为 failed）
 	if err := s.reconService.ExecuteTask(ctx, task.ID); err != nil {
 		s.logger.Error("Failed to execute reconciliation task",
 			zap.String("task_id", task.ID.String()),
 			zap.Error(err))
-
-		// 更新任务状态为失败
-		s.updateTaskStatus(ctx, task.ID, model.ReconciliationStatusFailed)
 		return
 	}
 
 	// 查询任务结果
-	completedTask, err := s.reconService.GetTask(ctx, task.ID)
+	details, err := s.reconService.GetTaskDetails(ctx, task.ID)
 	if err != nil {
 		s.logger.Error("Failed to get task result",
 			zap.String("task_id", task.ID.String()),
 			zap.Error(err))
 		return
 	}
+	completedTask := details.Task
 
 	// 如果有差异，发送告警
-	if completedTask.DifferenceCount > 0 {
-		differences, err := s.reconService.GetTaskDifferences(ctx, task.ID, 0, 100)
+	if completedTask.DiffCount > 0 {
+		unresolved := false
+		records, err := s.reconService.ListRecords(ctx, &service.RecordFilters{
+			TaskID:     &task.ID,
+			IsResolved: &unresolved,
+		}, 1, 100)
 		if err != nil {
 			s.logger.Error("Failed to get task differences",
 				zap.String("task_id", task.ID.String()),
 				zap.Error(err))
 			return
 		}
+		differences := toDifferences(records.Records)
 
 		// 发送差异告警
 		if err := s.alerter.SendDifferenceAlert(ctx, completedTask, differences); err != nil {
@@ -156,24 +183,41 @@ func (s *DailyScheduler) reconcileChannel(ctx context.Context, channel string, d
 	s.logger.Info("Channel reconciliation completed",
 		zap.String("channel", channel),
 		zap.String("status", completedTask.Status),
-		zap.Int("differences", completedTask.DifferenceCount))
+		zap.Int("differences", completedTask.DiffCount))
 }
 
-// updateTaskStatus 更新任务状态
-func (s *DailyScheduler) updateTaskStatus(ctx context.Context, taskID uuid.UUID, status string) {
-	if err := s.reconService.UpdateTaskStatus(ctx, taskID, status); err != nil {
-		s.logger.Error("Failed to update task status",
-			zap.String("task_id", taskID.String()),
-			zap.String("status", status),
-			zap.Error(err))
+// toDifferences 将对账差异记录转换为告警使用的差异明细（跳过已匹配记录）
+func toDifferences(records []*model.ReconciliationRecord) []*model.ReconciliationDifference {
+	differences := make([]*model.ReconciliationDifference, 0, len(records))
+	for _, record := range records {
+		severity := record.Severity()
+		if severity == "" {
+			continue
+		}
+		differences = append(differences, &model.ReconciliationDifference{
+			ID:             record.ID,
+			TaskID:         record.TaskID,
+			DifferenceType: record.DiffType,
+			OrderNo:        record.OrderNo,
+			InternalAmount: record.PlatformAmount,
+			ChannelAmount:  record.ChannelAmount,
+			AmountDiff:     record.DiffAmount,
+			InternalStatus: record.PlatformStatus,
+			ChannelStatus:  record.ChannelStatus,
+			Severity:       severity,
+			Description:    record.DiffReason,
+			DetectedAt:     record.CreatedAt,
+			CreatedAt:      record.CreatedAt,
+		})
 	}
+	return differences
 }
 
 // filterCriticalDifferences 过滤严重差异
 func (s *DailyScheduler) filterCriticalDifferences(differences []*model.ReconciliationDifference) []*model.ReconciliationDifference {
 	critical := make([]*model.ReconciliationDifference, 0)
 	for _, diff := range differences {
-		if diff.Severity == "critical" {
+		if diff.Severity == model.SeverityCritical {
 			critical = append(critical, diff)
 		}
 	}
@@ -25,8 +25,18 @@ type PlatformDataFetcher interface {
 
 // ReportGenerator 报告生成器接口
 type ReportGenerator interface {
-	// Generate 生成对账报告
-	Generate(ctx context.Context, task *model.ReconciliationTask) (string, error)
+	// Generate 生成单个任务的对账报告（PDF 汇总 + XLSX 全量明细）
+	Generate(ctx context.Context, task *model.ReconciliationTask) (*ReportResult, error)
+
+	// GenerateDaily 生成指定日期所有渠道的对账报告
+	GenerateDaily(ctx context.Context, reportDate time.Time, tasks []*model.ReconciliationTask) (*ReportResult, error)
+}
+
+// ReportResult 报告生成结果
+type ReportResult struct {
+	PDFPath        string         `json:"pdf_path"`        // PDF 汇总报告
+	XLSXPath       string         `json:"xlsx_path"`       // XLSX 全量明细
+	SeverityCounts map[string]int `json:"severity_counts"` // 各严重程度的差异笔数
 }
 
 // PlatformPayment 平台支付记录
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,9 +33,18 @@ type ReconciliationService interface {
 	ListFiles(ctx context.Context, filters *FileFilters, page, pageSize int) (*FileListResult, error)
 
 	// Report generation
-	GenerateReport(ctx context.Context, taskID uuid.UUID) (string, error)
+	GenerateReport(ctx context.Context, taskID uuid.UUID) (*ReportResult, error)
+	GetReportFile(ctx context.Context, taskID uuid.UUID, format string) (string, error)
+	GenerateDailyReport(ctx context.Context, reportDate time.Time) (*model.ReconciliationReport, error)
+	GetDailyReportFile(ctx context.Context, reportDate time.Time, format string) (string, error)
 }
 
+// 报告文件格式
+const (
+	ReportFormatPDF  = "pdf"
+	ReportFormatXLSX = "xlsx"
+)
+
 // Input/Output DTOs
 type CreateTaskInput struct {
 	TaskDate time.Time `json:"task_date" binding:"required"`
@@ -633,32 +643,168 @@ func (s *reconciliationService) ListFiles(ctx context.Context, filters *FileFilt
 }
 
 // GenerateReport 生成对账报告
-func (s *reconciliationService) GenerateReport(ctx context.Context, taskID uuid.UUID) (string, error) {
+func (s *reconciliationService) GenerateReport(ctx context.Context, taskID uuid.UUID) (*ReportResult, error) {
 	task, err := s.repo.GetTaskByID(ctx, taskID)
 	if err != nil {
-		return "", fmt.Errorf("get task failed: %w", err)
+		return nil, fmt.Errorf("get task failed: %w", err)
 	}
 	if task == nil {
-		return "", fmt.Errorf("task not found")
+		return nil, fmt.Errorf("task not found")
 	}
 
 	if task.Status != model.TaskStatusCompleted {
-		return "", fmt.Errorf("only completed tasks can generate reports")
+		return nil, fmt.Errorf("only completed tasks can generate reports")
 	}
 
 	// Generate report (delegated to report generator)
-	reportURL, err := s.reportGenerator.Generate(ctx, task)
+	result, err := s.reportGenerator.Generate(ctx, task)
 	if err != nil {
-		return "", fmt.Errorf("generate report failed: %w", err)
+		return nil, fmt.Errorf("generate report failed: %w", err)
 	}
 
-	// Update task with report URL
-	task.ReportFileURL = reportURL
+	// Update task with report URLs
+	task.ReportFileURL = result.PDFPath
+	task.WorkbookFileURL = result.XLSXPath
 	if err := s.repo.UpdateTask(ctx, task); err != nil {
-		return "", fmt.Errorf("update task report URL failed: %w", err)
+		return nil, fmt.Errorf("update task report URL failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetReportFile 获取任务报告文件路径，文件不存在时重新生成
+func (s *reconciliationService) GetReportFile(ctx context.Context, taskID uuid.UUID, format string) (string, error) {
+	if _, err := selectReportFile("", "", format); err != nil {
+		return "", err
+	}
+
+	task, err := s.repo.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return "", fmt.Errorf("get task failed: %w", err)
+	}
+	if task == nil {
+		return "", fmt.Errorf("task not found")
+	}
+
+	if path, ok := existingReportFile(task.ReportFileURL, task.WorkbookFileURL, format); ok {
+		return path, nil
+	}
+
+	result, err := s.GenerateReport(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	return selectReportFile(result.PDFPath, result.XLSXPath, format)
+}
+
+// GenerateDailyReport 生成指定日期所有渠道的每日对账报告
+func (s *reconciliationService) GenerateDailyReport(ctx context.Context, reportDate time.Time) (*model.ReconciliationReport, error) {
+	tasks, err := s.listTasksByDate(ctx, reportDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no reconciliation tasks for date %s", reportDate.Format("2006-01-02"))
+	}
+
+	result, err := s.reportGenerator.GenerateDaily(ctx, reportDate, tasks)
+	if err != nil {
+		return nil, fmt.Errorf("generate daily report failed: %w", err)
+	}
+
+	report := &model.ReconciliationReport{
+		ReportDate:      reportDate,
+		TotalTasks:      len(tasks),
+		CriticalDiffs:   result.SeverityCounts[model.SeverityCritical],
+		HighDiffs:       result.SeverityCounts[model.SeverityHigh],
+		MediumDiffs:     result.SeverityCounts[model.SeverityMedium],
+		LowDiffs:        result.SeverityCounts[model.SeverityLow],
+		ReportFileURL:   result.PDFPath,
+		WorkbookFileURL: result.XLSXPath,
+	}
+	for _, task := range tasks {
+		switch task.Status {
+		case model.TaskStatusCompleted:
+			report.CompletedTasks++
+		case model.TaskStatusFailed:
+			report.FailedTasks++
+		}
+		report.TotalInternal += task.PlatformCount
+		report.TotalChannel += task.ChannelCount
+		report.TotalMatched += task.MatchedCount
+		report.TotalDifferences += task.DiffCount
+		report.TotalAmountDiff += task.DiffAmount
+	}
+
+	if err := s.repo.SaveReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("save daily report failed: %w", err)
 	}
 
-	return reportURL, nil
+	return report, nil
+}
+
+// GetDailyReportFile 获取每日报告文件路径，报告或文件不存在时重新生成
+func (s *reconciliationService) GetDailyReportFile(ctx context.Context, reportDate time.Time, format string) (string, error) {
+	if _, err := selectReportFile("", "", format); err != nil {
+		return "", err
+	}
+
+	report, err := s.repo.GetReportByDate(ctx, reportDate)
+	if err != nil {
+		return "", fmt.Errorf("get daily report failed: %w", err)
+	}
+	if report != nil {
+		if path, ok := existingReportFile(report.ReportFileURL, report.WorkbookFileURL, format); ok {
+			return path, nil
+		}
+	}
+
+	report, err = s.GenerateDailyReport(ctx, reportDate)
+	if err != nil {
+		return "", err
+	}
+	return selectReportFile(report.ReportFileURL, report.WorkbookFileURL, format)
+}
+
+// listTasksByDate 查询指定日期的全部对账任务
+func (s *reconciliationService) listTasksByDate(ctx context.Context, taskDate time.Time) ([]*model.ReconciliationTask, error) {
+	const pageSize = 100
+	filters := repository.TaskFilters{TaskDate: &taskDate}
+
+	var tasks []*model.ReconciliationTask
+	for page := 1; ; page++ {
+		batch, total, err := s.repo.ListTasks(ctx, filters, page, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("list tasks failed: %w", err)
+		}
+		tasks = append(tasks, batch...)
+		if len(batch) < pageSize || int64(len(tasks)) >= total {
+			return tasks, nil
+		}
+	}
+}
+
+// existingReportFile 报告文件已生成且仍在磁盘上时返回其路径
+func existingReportFile(pdfPath, xlsxPath, format string) (string, bool) {
+	path, err := selectReportFile(pdfPath, xlsxPath, format)
+	if err != nil || path == "" {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func selectReportFile(pdfPath, xlsxPath, format string) (string, error) {
+	switch format {
+	case "", ReportFormatPDF:
+		return pdfPath, nil
+	case ReportFormatXLSX:
+		return xlsxPath, nil
+	default:
+		return "", fmt.Errorf("unsupported report format: %s", format)
+	}
 }
 
 // Helper functions
@@ -91,8 +91,11 @@ func main() {
 			&model.Settlement{},
 			&model.SettlementItem{},
 			&model.SettlementApproval{},
+			&model.SettlementFXLeg{},
 			&model.SettlementAccount{},
 			&scheduler.ScheduledTask{}, // 定时任务记录表
+			&saga.Saga{},               // Saga 实例表
+			&saga.SagaStep{},           // Saga 步骤表
 		},
 
 		EnableTracing:     true,
@@ -140,14 +143,12 @@ func main() {
 	)
 	logger.Info("Saga Orchestrator 初始化完成（带 Prometheus 指标）")
 
-	// 启动Saga恢复工作器（自动重试失败的结算Saga）
+	// Saga恢复工作器（自动续跑中断的结算Saga、重试失败的补偿），需在结算 Saga 服务注册步骤定义后启动
 	recoveryWorker := saga.NewRecoveryWorker(
 		sagaOrchestrator,
 		5*time.Minute, // 每5分钟扫描一次失败的Saga
 		10,            // 每次处理10个失败Saga
 	)
-	go recoveryWorker.Start(context.Background())
-	logger.Info("Saga Recovery Worker 已启动")
 
 	// 初始化定时任务调度器
 	taskScheduler := scheduler.NewScheduler(application.DB, application.Redis)
@@ -184,9 +185,12 @@ func main() {
 	}
 
 	// 6. 初始化Service
+	settlementFXService := service.NewSettlementFXService(settlementRepo, accountingClient)
 	settlementService := service.NewSettlementService(
 		application.DB,
 		settlementRepo,
+		settlementAccountRepo,
+		settlementFXService,
 		accountingClient,
 		withdrawalClient,
 		merchantClient,
@@ -200,6 +204,8 @@ func main() {
 	settlementSagaService := service.NewSettlementSagaService(
 		sagaOrchestrator,
 		settlementRepo,
+		settlementAccountRepo,
+		settlementFXService,
 		merchantClient,
 		withdrawalClient,
 	)
@@ -211,6 +217,10 @@ func main() {
 	}
 	logger.Info("Settlement Saga Service 初始化完成")
 
+	// 启动Saga恢复工作器（settlement 步骤定义已注册）
+	go recoveryWorker.Start(context.Background())
+	logger.Info("Saga Recovery Worker 已启动")
+
 	// 7. 初始化 JWT Manager（用于认证，优先从配置中心获取）
 	// ⚠️ 安全要求: JWT_SECRET必须在生产环境中设置，不能使用默认值
 	jwtSecret := getConfig("JWT_SECRET", "")
@@ -243,6 +253,10 @@ func main() {
 		authMiddleware,
 	)
 
+	// 服务间调用路由（admin-bff Saga 查询与人工干预）
+	serviceAuth := middleware.ServiceAuthMiddleware(getConfig("INTERNAL_SERVICE_TOKEN", ""), "admin-bff-service")
+	saga.NewAdminHandler(sagaOrchestrator, recoveryWorker).RegisterServiceRoutes(application.Router.Group("/api/v1", serviceAuth))
+
 	// 9. 启动HTTP服务（gRPC已禁用）
 	if err := application.RunWithGracefulShutdown(); err != nil {
 		logger.Fatal("服务启动失败: " + err.Error())
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/money"
 )
 
 // AccountingClient Accounting Service HTTP客户端
@@ -56,6 +57,7 @@ type TransactionInfo struct {
 	PaymentNo     string `json:"payment_no"`
 	Amount        int64  `json:"amount"`
 	Fee           int64  `json:"fee"`
+	Currency      string `json:"currency"`
 	TransactionAt string `json:"transaction_at"`
 }
 
@@ -98,6 +100,45 @@ func (c *AccountingClient) GetTransactions(ctx context.Context, merchantID uuid.
 	return result.Data.List, nil
 }
 
+// splitRecipientsResponse 分账收款方列表响应
+type splitRecipientsResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details"`
+	Data    *struct {
+		MerchantIDs []uuid.UUID `json:"merchant_ids"`
+	} `json:"data"`
+}
+
+// ListSplitRecipients 获取时间范围内有分账交易的收款方商户（子商户按自身商户ID单独结算，使用熔断器）
+func (c *AccountingClient) ListSplitRecipients(ctx context.Context, startDate, endDate time.Time) ([]uuid.UUID, error) {
+	url := fmt.Sprintf("%s/api/v1/transactions/split-recipients?start_date=%s&end_date=%s",
+		c.baseURL,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"))
+
+	resp, err := c.breaker.Do(&httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Ctx:    ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+
+	var result splitRecipientsResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "SUCCESS" {
+		return nil, fmt.Errorf("业务错误: %s %s", result.Message, result.Details)
+	}
+	if result.Data == nil {
+		return []uuid.UUID{}, nil
+	}
+	return result.Data.MerchantIDs, nil
+}
+
 // RefundSummary 退款汇总数据
 type RefundSummary struct {
 	TotalCount  int   `json:"total_count"`
@@ -156,3 +197,96 @@ func (c *AccountingClient) GetRefundSummary(ctx context.Context, merchantID uuid
 
 	return result.Data, nil
 }
+
+// CurrencyConversion 货币转换记录
+type CurrencyConversion struct {
+	ConversionNo   string        `json:"conversion_no"`
+	SourceCurrency string        `json:"source_currency"`
+	TargetCurrency string        `json:"target_currency"`
+	SourceAmount   int64         `json:"source_amount"`
+	TargetAmount   int64         `json:"target_amount"`
+	ExchangeRate   money.Decimal `json:"exchange_rate"` // 锁定报价的成交汇率
+	MidRate        money.Decimal `json:"mid_rate"`      // 报价时市场中间价
+	FXQuoteID      string        `json:"fx_quote_id"`
+	FeeAmount      int64         `json:"fee_amount"` // 换汇手续费（源货币）
+	Status         string        `json:"status"`
+}
+
+// CreateCurrencyConversionRequest 创建货币转换请求
+type CreateCurrencyConversionRequest struct {
+	MerchantID     uuid.UUID `json:"merchant_id"`
+	SourceCurrency string    `json:"source_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	SourceAmount   int64     `json:"source_amount"`
+	FeeInclusive   bool      `json:"fee_inclusive"` // source_amount 为含手续费的出账总额
+	Reason         string    `json:"reason"`
+	Notes          string    `json:"notes"`
+}
+
+// conversionResponse accounting-service 统一响应
+type conversionResponse struct {
+	Code    string              `json:"code"`
+	Message string              `json:"message"`
+	Details string              `json:"details"`
+	Data    *CurrencyConversion `json:"data"`
+}
+
+// CreateCurrencyConversion 创建货币转换（按锁定报价的汇率换算，使用熔断器）
+func (c *AccountingClient) CreateCurrencyConversion(ctx context.Context, req *CreateCurrencyConversionRequest) (*CurrencyConversion, error) {
+	result, err := c.doConversion(ctx, "POST", fmt.Sprintf("%s/api/v1/conversions", c.baseURL), req)
+	if err != nil {
+		return nil, fmt.Errorf("创建货币转换失败: %w", err)
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("创建货币转换失败: 响应数据为空")
+	}
+	return result.Data, nil
+}
+
+// ProcessCurrencyConversion 执行货币转换，源币种账户出账、目标币种账户入账（使用熔断器）
+func (c *AccountingClient) ProcessCurrencyConversion(ctx context.Context, conversionNo string) error {
+	if _, err := c.doConversion(ctx, "POST", fmt.Sprintf("%s/api/v1/conversions/%s/process", c.baseURL, conversionNo), nil); err != nil {
+		return fmt.Errorf("执行货币转换失败: %w", err)
+	}
+	return nil
+}
+
+// GetCurrencyConversion 查询货币转换（使用熔断器）
+func (c *AccountingClient) GetCurrencyConversion(ctx context.Context, conversionNo string) (*CurrencyConversion, error) {
+	result, err := c.doConversion(ctx, "GET", fmt.Sprintf("%s/api/v1/conversions/%s", c.baseURL, conversionNo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询货币转换失败: %w", err)
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("货币转换不存在: %s", conversionNo)
+	}
+	return result.Data, nil
+}
+
+func (c *AccountingClient) doConversion(ctx context.Context, method, url string, body interface{}) (*conversionResponse, error) {
+	req := &httpclient.Request{
+		Method: method,
+		URL:    url,
+		Body:   body,
+		Ctx:    ctx,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}
+
+	resp, err := c.breaker.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+
+	var result conversionResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	if result.Code != "SUCCESS" {
+		return nil, fmt.Errorf("业务错误: %s %s", result.Message, result.Details)
+	}
+
+	return &result, nil
+}
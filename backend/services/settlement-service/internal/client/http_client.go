@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/payment-platform/pkg/httpclient"
+	"github.com/payment-platform/pkg/idempotency"
 )
 
 // HTTPClient HTTP客户端封装
@@ -77,6 +78,13 @@ func (c *HTTPClient) Do(ctx context.Context, req *Request) (*Response, error) {
 		httpReq.Header.Set(key, value)
 	}
 
+	// Saga 步骤内的调用自动携带步骤幂等键，步骤重试时下游据此去重
+	if httpReq.Header.Get(idempotency.HeaderName) == "" {
+		if key := idempotency.RequestKey(ctx, req.Method, req.Path); key != "" {
+			httpReq.Header.Set(idempotency.HeaderName, key)
+		}
+	}
+
 	// 执行请求
 	httpResp, err := c.client.Do(httpReq)
 	if err != nil {
@@ -38,7 +38,8 @@ func NewWithdrawalClient(baseURL string) *WithdrawalClient {
 type CreateWithdrawalRequest struct {
 	MerchantID    uuid.UUID `json:"merchant_id"`
 	Amount        int64     `json:"amount"`
-	Type          string    `json:"type"` // settlement_auto, settlement_manual
+	Currency      string    `json:"currency"` // 出款币种
+	Type          string    `json:"type"`     // settlement_auto, settlement_manual
 	BankAccountID uuid.UUID `json:"bank_account_id"`
 	Remarks       string    `json:"remarks"`
 	CreatedBy     uuid.UUID `json:"created_by"`
@@ -95,3 +96,64 @@ func (c *WithdrawalClient) CreateWithdrawalForSettlement(ctx context.Context, wi
 
 	return result.Data.WithdrawalNo, nil
 }
+
+// BankAccountData withdrawal-service 维护的提现银行账户
+type BankAccountData struct {
+	ID         uuid.UUID `json:"id"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+	IsDefault  bool      `json:"is_default"`
+	IsVerified bool      `json:"is_verified"`
+	Status     string    `json:"status"`
+}
+
+// listBankAccountsResponse 银行账户列表响应
+type listBankAccountsResponse struct {
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Data    []*BankAccountData `json:"data"`
+}
+
+// GetDefaultBankAccount 获取商户在 withdrawal-service 中的默认提现银行账户（使用熔断器）
+//
+// 提现单的 bank_account_id 必须引用 withdrawal-service 自己的银行账户，结算账户 ID 在那里不存在。
+// 优先返回已验证的默认账户，没有默认账户时返回任一已验证的有效账户。
+func (c *WithdrawalClient) GetDefaultBankAccount(ctx context.Context, merchantID uuid.UUID) (*BankAccountData, error) {
+	url := fmt.Sprintf("%s/api/v1/bank-accounts?merchant_id=%s", c.baseURL, merchantID.String())
+
+	req := &httpclient.Request{
+		Method: "GET",
+		URL:    url,
+		Ctx:    ctx,
+	}
+
+	resp, err := c.breaker.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+
+	var result listBankAccountsResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	if result.Code != "SUCCESS" {
+		return nil, fmt.Errorf("业务错误: %s", result.Message)
+	}
+
+	var fallback *BankAccountData
+	for _, account := range result.Data {
+		if account == nil || !account.IsVerified || account.Status != "active" {
+			continue
+		}
+		if account.IsDefault {
+			return account, nil
+		}
+		if fallback == nil {
+			fallback = account
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("商户没有可用的提现银行账户")
+	}
+	return fallback, nil
+}
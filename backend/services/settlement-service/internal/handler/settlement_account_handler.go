@@ -52,17 +52,18 @@ func (h *SettlementAccountHandler) CreateAccount(c *gin.Context) {
 	}
 
 	account := &model.SettlementAccount{
-		MerchantID:    merchantID,
-		AccountType:   req.AccountType,
-		BankName:      req.BankName,
-		BankCode:      req.BankCode,
-		AccountNumber: req.AccountNumber,
-		AccountName:   req.AccountName,
-		SwiftCode:     req.SwiftCode,
-		IBAN:          req.IBAN,
-		BankAddress:   req.BankAddress,
-		Currency:      req.Currency,
-		Country:       req.Country,
+		MerchantID:     merchantID,
+		AccountType:    req.AccountType,
+		BankName:       req.BankName,
+		BankCode:       req.BankCode,
+		AccountNumber:  req.AccountNumber,
+		AccountName:    req.AccountName,
+		SwiftCode:      req.SwiftCode,
+		IBAN:           req.IBAN,
+		BankAddress:    req.BankAddress,
+		Currency:       req.Currency,
+		PayoutCurrency: req.PayoutCurrency,
+		Country:        req.Country,
 	}
 
 	if err := h.service.CreateAccount(c.Request.Context(), account); err != nil {
@@ -78,6 +79,7 @@ func (h *SettlementAccountHandler) CreateAccount(c *gin.Context) {
 		AccountNumber:  maskAccountNumber(account.AccountNumber),
 		AccountName:    account.AccountName,
 		Currency:       account.Currency,
+		PayoutCurrency: account.GetPayoutCurrency(),
 		Country:        account.Country,
 		IsDefault:      account.IsDefault,
 		Status:         account.Status,
@@ -118,6 +120,7 @@ func (h *SettlementAccountHandler) GetAccount(c *gin.Context) {
 		SwiftCode:      account.SwiftCode,
 		IBAN:           account.IBAN,
 		Currency:       account.Currency,
+		PayoutCurrency: account.GetPayoutCurrency(),
 		Country:        account.Country,
 		IsDefault:      account.IsDefault,
 		Status:         account.Status,
@@ -164,6 +167,7 @@ func (h *SettlementAccountHandler) ListAccounts(c *gin.Context) {
 			AccountNumber:  maskAccountNumber(account.AccountNumber),
 			AccountName:    account.AccountName,
 			Currency:       account.Currency,
+			PayoutCurrency: account.GetPayoutCurrency(),
 			Country:        account.Country,
 			IsDefault:      account.IsDefault,
 			Status:         account.Status,
@@ -212,6 +216,68 @@ func (h *SettlementAccountHandler) SetDefaultAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, SuccessResponse{Message: "Default account set successfully"})
 }
 
+// SetPayoutCurrency 设置结算账户出款币种
+// @Summary		设置出款币种
+// @Description	设置结算账户的出款币种，结算时其他币种余额按锁定汇率换汇为该币种
+// @Tags		Settlement Accounts
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path	string						true	"账户ID"
+// @Param		request	body	SetPayoutCurrencyRequest	true	"出款币种"
+// @Success		200		{object}	SettlementAccountResponse
+// @Failure		400		{object}	ErrorResponse
+// @Router		/settlement-accounts/{id}/payout-currency [put]
+func (h *SettlementAccountHandler) SetPayoutCurrency(c *gin.Context) {
+	merchantIDStr, exists := c.Get("merchant_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	merchantID, err := uuid.Parse(merchantIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid merchant_id"})
+		return
+	}
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid account id"})
+		return
+	}
+
+	var req SetPayoutCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	account, err := h.service.SetPayoutCurrency(c.Request.Context(), merchantID, accountID, req.PayoutCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SettlementAccountResponse{
+		ID:             account.ID.String(),
+		MerchantID:     account.MerchantID.String(),
+		AccountType:    account.AccountType,
+		BankName:       account.BankName,
+		AccountNumber:  maskAccountNumber(account.AccountNumber),
+		AccountName:    account.AccountName,
+		SwiftCode:      account.SwiftCode,
+		IBAN:           account.IBAN,
+		Currency:       account.Currency,
+		PayoutCurrency: account.GetPayoutCurrency(),
+		Country:        account.Country,
+		IsDefault:      account.IsDefault,
+		Status:         account.Status,
+		VerifiedAt:     account.VerifiedAt,
+		CreatedAt:      account.CreatedAt,
+	})
+}
+
 // DeleteAccount 删除结算账户
 // @Summary		删除结算账户
 // @Description	删除指定的结算账户
@@ -246,6 +312,7 @@ func RegisterSettlementAccountRoutes(r *gin.RouterGroup, handler *SettlementAcco
 		accounts.GET("", handler.ListAccounts)
 		accounts.GET("/:id", handler.GetAccount)
 		accounts.POST("/:id/set-default", handler.SetDefaultAccount)
+		accounts.PUT("/:id/payout-currency", handler.SetPayoutCurrency)
 		accounts.DELETE("/:id", handler.DeleteAccount)
 	}
 }
@@ -261,16 +328,21 @@ func maskAccountNumber(accountNumber string) string {
 // DTO定义
 
 type CreateAccountRequest struct {
-	AccountType   string `json:"account_type" binding:"required"`
-	BankName      string `json:"bank_name"`
-	BankCode      string `json:"bank_code"`
-	AccountNumber string `json:"account_number" binding:"required"`
-	AccountName   string `json:"account_name" binding:"required"`
-	SwiftCode     string `json:"swift_code"`
-	IBAN          string `json:"iban"`
-	BankAddress   string `json:"bank_address"`
-	Currency      string `json:"currency" binding:"required"`
-	Country       string `json:"country"`
+	AccountType    string `json:"account_type" binding:"required"`
+	BankName       string `json:"bank_name"`
+	BankCode       string `json:"bank_code"`
+	AccountNumber  string `json:"account_number" binding:"required"`
+	AccountName    string `json:"account_name" binding:"required"`
+	SwiftCode      string `json:"swift_code"`
+	IBAN           string `json:"iban"`
+	BankAddress    string `json:"bank_address"`
+	Currency       string `json:"currency" binding:"required"`
+	PayoutCurrency string `json:"payout_currency"` // 出款币种（可选，默认与账户币种相同）
+	Country        string `json:"country"`
+}
+
+type SetPayoutCurrencyRequest struct {
+	PayoutCurrency string `json:"payout_currency" binding:"required"`
 }
 
 type SettlementAccountResponse struct {
@@ -283,6 +355,7 @@ type SettlementAccountResponse struct {
 	SwiftCode      string     `json:"swift_code"`
 	IBAN           string     `json:"iban"`
 	Currency       string     `json:"currency"`
+	PayoutCurrency string     `json:"payout_currency"` // 出款币种，其他币种余额结算时换汇
 	Country        string     `json:"country"`
 	IsDefault      bool       `json:"is_default"`
 	Status         string     `json:"status"`
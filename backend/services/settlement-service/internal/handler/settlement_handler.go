@@ -49,6 +49,7 @@ type CreateSettlementRequest struct {
 	StartDate    string                     `json:"start_date" binding:"required"`
 	EndDate      string                     `json:"end_date" binding:"required"`
 	Transactions []TransactionItemRequest   `json:"transactions" binding:"required"`
+	Currency     string                     `json:"currency"` // 出款币种（可选，默认取默认结算账户的出款币种）
 }
 
 // TransactionItemRequest 交易明细请求
@@ -56,6 +57,7 @@ type TransactionItemRequest struct {
 	TransactionID string `json:"transaction_id" binding:"required"`
 	OrderNo       string `json:"order_no" binding:"required"`
 	PaymentNo     string `json:"payment_no" binding:"required"`
+	Currency      string `json:"currency"` // 交易币种（可选，默认为出款币种）
 	Amount        int64  `json:"amount" binding:"required,min=1"`
 	Fee           int64  `json:"fee" binding:"min=0"`
 	TransactionAt string `json:"transaction_at" binding:"required"`
@@ -124,6 +126,7 @@ func (h *SettlementHandler) CreateSettlement(c *gin.Context) {
 			TransactionID: txID,
 			OrderNo:       tx.OrderNo,
 			PaymentNo:     tx.PaymentNo,
+			Currency:      tx.Currency,
 			Amount:        tx.Amount,
 			Fee:           tx.Fee,
 			TransactionAt: txTime,
@@ -136,6 +139,7 @@ func (h *SettlementHandler) CreateSettlement(c *gin.Context) {
 		StartDate:    startDate,
 		EndDate:      endDate,
 		Transactions: transactions,
+		Currency:     req.Currency,
 	}
 
 	settlement, err := h.settlementService.CreateSettlement(c.Request.Context(), input)
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -11,12 +12,12 @@ import (
 type SettlementStatus string
 
 const (
-	SettlementStatusPending   SettlementStatus = "pending"   // 待审批
-	SettlementStatusApproved  SettlementStatus = "approved"  // 已审批
-	SettlementStatusRejected  SettlementStatus = "rejected"  // 已拒绝
+	SettlementStatusPending    SettlementStatus = "pending"    // 待审批
+	SettlementStatusApproved   SettlementStatus = "approved"   // 已审批
+	SettlementStatusRejected   SettlementStatus = "rejected"   // 已拒绝
 	SettlementStatusProcessing SettlementStatus = "processing" // 处理中
-	SettlementStatusCompleted SettlementStatus = "completed" // 已完成
-	SettlementStatusFailed    SettlementStatus = "failed"    // 失败
+	SettlementStatusCompleted  SettlementStatus = "completed"  // 已完成
+	SettlementStatusFailed     SettlementStatus = "failed"     // 失败
 )
 
 // SettlementCycle 结算周期
@@ -31,29 +32,30 @@ const (
 
 // Settlement 结算单
 type Settlement struct {
-	ID              uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	SettlementNo    string           `gorm:"type:varchar(64);uniqueIndex;not null" json:"settlement_no"` // 结算单号
-	MerchantID      uuid.UUID        `gorm:"type:uuid;index;not null" json:"merchant_id"`                // 商户ID
-	Cycle           SettlementCycle  `gorm:"type:varchar(20);not null" json:"cycle"`                     // 结算周期
-	StartDate       time.Time        `gorm:"not null" json:"start_date"`                                 // 开始日期
-	EndDate         time.Time        `gorm:"not null" json:"end_date"`                                   // 结束日期
-	TotalAmount     int64            `gorm:"not null;default:0" json:"total_amount"`                     // 交易总额（分）
-	TotalCount      int              `gorm:"not null;default:0" json:"total_count"`                      // 交易笔数
-	FeeAmount       int64            `gorm:"not null;default:0" json:"fee_amount"`                       // 手续费（分）
-	RefundAmount    int64            `gorm:"not null;default:0" json:"refund_amount"`                    // 退款金额（分）
-	RefundCount     int              `gorm:"not null;default:0" json:"refund_count"`                     // 退款笔数
-	SettlementAmount int64           `gorm:"not null;default:0" json:"settlement_amount"`                // 结算金额（分）
-	Status          SettlementStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // 状态
-	WithdrawalNo    string           `gorm:"type:varchar(64);index" json:"withdrawal_no"`                // 提现单号
-	ApprovedAt      *time.Time       `json:"approved_at"`                                                // 审批时间
-	ApprovedBy      *uuid.UUID       `gorm:"type:uuid" json:"approved_by"`                               // 审批人ID
-	ProcessedAt     *time.Time       `json:"processed_at"`                                               // 处理时间
-	CompletedAt     *time.Time       `json:"completed_at"`                                               // 完成时间
-	Remarks         string           `gorm:"type:text" json:"remarks"`                                   // 备注
-	ErrorMessage    string           `gorm:"type:text" json:"error_message"`                             // 错误信息
-	CreatedAt       time.Time        `json:"created_at"`
-	UpdatedAt       time.Time        `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt   `gorm:"index" json:"-"`
+	ID               uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SettlementNo     string           `gorm:"type:varchar(64);uniqueIndex;not null" json:"settlement_no"` // 结算单号
+	MerchantID       uuid.UUID        `gorm:"type:uuid;index;not null" json:"merchant_id"`                // 商户ID
+	Cycle            SettlementCycle  `gorm:"type:varchar(20);not null" json:"cycle"`                     // 结算周期
+	StartDate        time.Time        `gorm:"not null" json:"start_date"`                                 // 开始日期
+	EndDate          time.Time        `gorm:"not null" json:"end_date"`                                   // 结束日期
+	TotalAmount      int64            `gorm:"not null;default:0" json:"total_amount"`                     // 交易总额（分）
+	TotalCount       int              `gorm:"not null;default:0" json:"total_count"`                      // 交易笔数
+	FeeAmount        int64            `gorm:"not null;default:0" json:"fee_amount"`                       // 手续费（分）
+	RefundAmount     int64            `gorm:"not null;default:0" json:"refund_amount"`                    // 退款金额（分）
+	RefundCount      int              `gorm:"not null;default:0" json:"refund_count"`                     // 退款笔数
+	SettlementAmount int64            `gorm:"not null;default:0" json:"settlement_amount"`                // 结算金额（分）
+	Currency         string           `gorm:"type:varchar(10)" json:"currency"`                           // 出款币种（外币明细在执行时换汇后计入金额）
+	Status           SettlementStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`  // 状态
+	WithdrawalNo     string           `gorm:"type:varchar(64);index" json:"withdrawal_no"`                // 提现单号
+	ApprovedAt       *time.Time       `json:"approved_at"`                                                // 审批时间
+	ApprovedBy       *uuid.UUID       `gorm:"type:uuid" json:"approved_by"`                               // 审批人ID
+	ProcessedAt      *time.Time       `json:"processed_at"`                                               // 处理时间
+	CompletedAt      *time.Time       `json:"completed_at"`                                               // 完成时间
+	Remarks          string           `gorm:"type:text" json:"remarks"`                                   // 备注
+	ErrorMessage     string           `gorm:"type:text" json:"error_message"`                             // 错误信息
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt   `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
@@ -63,16 +65,25 @@ func (Settlement) TableName() string {
 
 // SettlementItem 结算明细
 type SettlementItem struct {
-	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	SettlementID  uuid.UUID `gorm:"type:uuid;index;not null" json:"settlement_id"`      // 结算单ID
-	TransactionID uuid.UUID `gorm:"type:uuid;index;not null" json:"transaction_id"`     // 交易ID
-	OrderNo       string    `gorm:"type:varchar(64);index;not null" json:"order_no"`    // 订单号
-	PaymentNo     string    `gorm:"type:varchar(64);index;not null" json:"payment_no"`  // 支付单号
-	Amount        int64     `gorm:"not null" json:"amount"`                              // 交易金额（分）
-	Fee           int64     `gorm:"not null;default:0" json:"fee"`                       // 手续费（分）
-	SettleAmount  int64     `gorm:"not null" json:"settle_amount"`                       // 结算金额（分）
-	TransactionAt time.Time `gorm:"not null" json:"transaction_at"`                     // 交易时间
-	CreatedAt     time.Time `json:"created_at"`
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SettlementID   uuid.UUID      `gorm:"type:uuid;index;not null" json:"settlement_id"`     // 结算单ID
+	TransactionID  uuid.UUID      `gorm:"type:uuid;index;not null" json:"transaction_id"`    // 交易ID
+	OrderNo        string         `gorm:"type:varchar(64);index;not null" json:"order_no"`   // 订单号
+	PaymentNo      string         `gorm:"type:varchar(64);index;not null" json:"payment_no"` // 支付单号
+	Currency       string         `gorm:"type:varchar(10)" json:"currency"`                  // 交易币种
+	Amount         int64          `gorm:"not null" json:"amount"`                            // 交易金额（分，交易币种）
+	Fee            int64          `gorm:"not null;default:0" json:"fee"`                     // 手续费（分，交易币种）
+	SettleAmount   int64          `gorm:"not null" json:"settle_amount"`                     // 结算金额（分，出款币种）
+	FXRate         *money.Decimal `gorm:"type:decimal(24,10)" json:"fx_rate,omitempty"`      // 换汇成交汇率（无需换汇时为空）
+	FXMarkupAmount int64          `gorm:"not null;default:0" json:"fx_markup_amount"`        // 换汇加点成本（分，出款币种，按中间价与成交汇率之差）
+	FXFee          int64          `gorm:"not null;default:0" json:"fx_fee"`                  // 换汇手续费分摊（分，交易币种）
+	TransactionAt  time.Time      `gorm:"not null" json:"transaction_at"`                    // 交易时间
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// NetAmount 扣除手续费后的交易币种净额
+func (i *SettlementItem) NetAmount() int64 {
+	return i.Amount - i.Fee
 }
 
 // TableName 指定表名
@@ -80,16 +91,54 @@ func (SettlementItem) TableName() string {
 	return "settlement_items"
 }
 
+// FXLegStatus 换汇分录状态
+type FXLegStatus string
+
+const (
+	FXLegStatusPending   FXLegStatus = "pending"   // 待换汇
+	FXLegStatusCompleted FXLegStatus = "completed" // 已换汇入账
+	FXLegStatusFailed    FXLegStatus = "failed"    // 换汇失败
+	FXLegStatusSkipped   FXLegStatus = "skipped"   // 净额不为正，不换汇，留在源币种账户结转
+)
+
+// SettlementFXLeg 结算换汇分录（每个交易币种一条，通过 accounting-service 货币转换入账）
+type SettlementFXLeg struct {
+	ID              uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SettlementID    uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_settlement_fx_leg" json:"settlement_id"`          // 结算单ID
+	SourceCurrency  string        `gorm:"type:varchar(10);not null;uniqueIndex:idx_settlement_fx_leg" json:"source_currency"` // 交易币种
+	TargetCurrency  string        `gorm:"type:varchar(10);not null" json:"target_currency"`                                   // 出款币种
+	ItemCount       int           `gorm:"not null;default:0" json:"item_count"`                                               // 明细笔数
+	GrossAmount     int64         `gorm:"not null;default:0" json:"gross_amount"`                                             // 交易总额（分，交易币种）
+	FeeAmount       int64         `gorm:"not null;default:0" json:"fee_amount"`                                               // 手续费（分，交易币种）
+	NetAmount       int64         `gorm:"not null;default:0" json:"net_amount"`                                               // 换汇出账金额（分，交易币种，含换汇手续费）
+	ConvertedAmount int64         `gorm:"not null;default:0" json:"converted_amount"`                                         // 换汇后金额（分，出款币种）
+	FXRate          money.Decimal `gorm:"type:decimal(24,10);default:0" json:"fx_rate"`                                       // 成交汇率
+	MidRate         money.Decimal `gorm:"type:decimal(24,10);default:0" json:"mid_rate"`                                      // 成交时市场中间价
+	FXFee           int64         `gorm:"not null;default:0" json:"fx_fee"`                                                   // 换汇手续费（分，交易币种）
+	FXQuoteID       string        `gorm:"type:varchar(64)" json:"fx_quote_id"`                                                // 锁定的换汇报价编号
+	ConversionNo    string        `gorm:"type:varchar(64);index" json:"conversion_no"`                                        // accounting-service 转换单号
+	Status          FXLegStatus   `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`                          // 状态
+	ErrorMessage    string        `gorm:"type:text" json:"error_message"`                                                     // 错误信息
+	ConvertedAt     *time.Time    `json:"converted_at"`                                                                       // 换汇时间
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SettlementFXLeg) TableName() string {
+	return "settlement_fx_legs"
+}
+
 // SettlementApproval 结算审批记录
 type SettlementApproval struct {
 	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	SettlementID uuid.UUID        `gorm:"type:uuid;index;not null" json:"settlement_id"`             // 结算单ID
-	ApproverID   uuid.UUID        `gorm:"type:uuid;not null" json:"approver_id"`                     // 审批人ID
-	ApproverName string           `gorm:"type:varchar(100);not null" json:"approver_name"`           // 审批人名称
-	Action       string           `gorm:"type:varchar(20);not null" json:"action"`                   // 操作（approve/reject）
-	Status       SettlementStatus `gorm:"type:varchar(20);not null" json:"status"`                   // 审批后状态
-	Comments     string           `gorm:"type:text" json:"comments"`                                 // 审批意见
-	ApprovedAt   time.Time        `gorm:"not null" json:"approved_at"`                               // 审批时间
+	SettlementID uuid.UUID        `gorm:"type:uuid;index;not null" json:"settlement_id"`   // 结算单ID
+	ApproverID   uuid.UUID        `gorm:"type:uuid;not null" json:"approver_id"`           // 审批人ID
+	ApproverName string           `gorm:"type:varchar(100);not null" json:"approver_name"` // 审批人名称
+	Action       string           `gorm:"type:varchar(20);not null" json:"action"`         // 操作（approve/reject）
+	Status       SettlementStatus `gorm:"type:varchar(20);not null" json:"status"`         // 审批后状态
+	Comments     string           `gorm:"type:text" json:"comments"`                       // 审批意见
+	ApprovedAt   time.Time        `gorm:"not null" json:"approved_at"`                     // 审批时间
 	CreatedAt    time.Time        `json:"created_at"`
 }
 
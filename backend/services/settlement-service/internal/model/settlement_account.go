@@ -20,6 +20,7 @@ type SettlementAccount struct {
 	IBAN               string         `gorm:"type:varchar(50)" json:"iban"`                                 // IBAN
 	BankAddress        string         `gorm:"type:varchar(500)" json:"bank_address"`                        // 银行地址
 	Currency           string         `gorm:"type:varchar(10);not null;default:'USD'" json:"currency"`      // 币种
+	PayoutCurrency     string         `gorm:"type:varchar(10)" json:"payout_currency"`                      // 出款币种（为空时按账户币种出款，其他币种余额结算时换汇）
 	Country            string         `gorm:"type:varchar(50)" json:"country"`                              // 国家
 	IsDefault          bool           `gorm:"default:false" json:"is_default"`                              // 是否默认账户
 	Status             string         `gorm:"type:varchar(20);default:'pending_verify'" json:"status"`      // pending_verify, verified, rejected, suspended
@@ -38,6 +39,14 @@ func (SettlementAccount) TableName() string {
 	return "settlement_accounts"
 }
 
+// GetPayoutCurrency 获取出款币种
+func (a *SettlementAccount) GetPayoutCurrency() string {
+	if a.PayoutCurrency != "" {
+		return a.PayoutCurrency
+	}
+	return a.Currency
+}
+
 // 结算账户类型常量
 const (
 	AccountTypeBankAccount  = "bank_account"  // 银行账户
@@ -20,6 +20,10 @@ type SettlementRepository interface {
 	CreateItem(ctx context.Context, item *model.SettlementItem) error
 	CreateItems(ctx context.Context, items []*model.SettlementItem) error
 	GetItems(ctx context.Context, settlementID uuid.UUID) ([]*model.SettlementItem, error)
+	UpdateItems(ctx context.Context, items []*model.SettlementItem) error
+	CreateFXLegs(ctx context.Context, legs []*model.SettlementFXLeg) error
+	GetFXLegs(ctx context.Context, settlementID uuid.UUID) ([]*model.SettlementFXLeg, error)
+	UpdateFXLeg(ctx context.Context, leg *model.SettlementFXLeg) error
 	CreateApproval(ctx context.Context, approval *model.SettlementApproval) error
 	GetApprovals(ctx context.Context, settlementID uuid.UUID) ([]*model.SettlementApproval, error)
 	GetPendingSettlements(ctx context.Context, merchantID uuid.UUID) ([]*model.Settlement, error)
@@ -139,6 +143,37 @@ func (r *settlementRepository) GetItems(ctx context.Context, settlementID uuid.U
 	return items, nil
 }
 
+func (r *settlementRepository) UpdateItems(ctx context.Context, items []*model.SettlementItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			if err := tx.Save(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *settlementRepository) CreateFXLegs(ctx context.Context, legs []*model.SettlementFXLeg) error {
+	if len(legs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&legs).Error
+}
+
+func (r *settlementRepository) GetFXLegs(ctx context.Context, settlementID uuid.UUID) ([]*model.SettlementFXLeg, error) {
+	var legs []*model.SettlementFXLeg
+	err := r.db.WithContext(ctx).Where("settlement_id = ?", settlementID).Order("source_currency ASC").Find(&legs).Error
+	if err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+func (r *settlementRepository) UpdateFXLeg(ctx context.Context, leg *model.SettlementFXLeg) error {
+	return r.db.WithContext(ctx).Save(leg).Error
+}
+
 func (r *settlementRepository) CreateApproval(ctx context.Context, approval *model.SettlementApproval) error {
 	return r.db.WithContext(ctx).Create(approval).Error
 }
@@ -32,6 +32,7 @@ var DefaultAutoSettlementConfig = AutoSettlementConfig{
 type AutoSettlementTask struct {
 	db                   *gorm.DB
 	settlementRepo       repository.SettlementRepository
+	accountRepo          repository.SettlementAccountRepository
 	accountingClient     *client.AccountingClient
 	merchantClient       *client.MerchantClient
 	merchantConfigClient *client.MerchantConfigClient
@@ -51,6 +52,7 @@ func NewAutoSettlementTask(
 	return &AutoSettlementTask{
 		db:                   db,
 		settlementRepo:       settlementRepo,
+		accountRepo:          repository.NewSettlementAccountRepository(db),
 		accountingClient:     accountingClient,
 		merchantClient:       merchantClient,
 		merchantConfigClient: merchantConfigClient,
@@ -69,6 +71,9 @@ func (t *AutoSettlementTask) Run(ctx context.Context) error {
 		return fmt.Errorf("查询自动结算商户失败: %w", err)
 	}
 
+	// 1.1 合并昨日有分账交易的收款方（平台子商户按自身商户ID单独结算）
+	merchants = t.appendSplitRecipients(ctx, merchants)
+
 	if len(merchants) == 0 {
 		logger.Info("没有需要自动结算的商户")
 		return nil
@@ -144,6 +149,37 @@ func (t *AutoSettlementTask) getAutoSettlementMerchants(ctx context.Context) ([]
 	return merchantIDs, nil
 }
 
+// appendSplitRecipients 合并有分账交易的收款方商户（去重）
+func (t *AutoSettlementTask) appendSplitRecipients(ctx context.Context, merchants []uuid.UUID) []uuid.UUID {
+	if t.accountingClient == nil {
+		return merchants
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	recipients, err := t.accountingClient.ListSplitRecipients(ctx, yesterday, yesterday.Add(24*time.Hour))
+	if err != nil {
+		logger.Warn("获取分账收款方列表失败（已降级）", zap.Error(err))
+		return merchants
+	}
+
+	seen := make(map[uuid.UUID]bool, len(merchants))
+	for _, merchantID := range merchants {
+		seen[merchantID] = true
+	}
+	added := 0
+	for _, merchantID := range recipients {
+		if !seen[merchantID] {
+			seen[merchantID] = true
+			merchants = append(merchants, merchantID)
+			added++
+		}
+	}
+	if added > 0 {
+		logger.Info(fmt.Sprintf("合并 %d 个分账收款方进行自动结算", added))
+	}
+	return merchants
+}
+
 // settleMerchant 为单个商户执行结算
 func (t *AutoSettlementTask) settleMerchant(ctx context.Context, merchantID uuid.UUID) error {
 	logger.Info("开始商户自动结算", zap.String("merchant_id", merchantID.String()))
@@ -178,15 +214,25 @@ func (t *AutoSettlementTask) settleMerchant(ctx context.Context, merchantID uuid
 		return nil
 	}
 
-	// 3. 计算结算金额
-	var totalAmount int64
-	var totalFee int64
+	// 3. 计算结算金额（按出款币种拆分，外币明细生成换汇分录）
+	txItems := make([]TransactionItem, 0, len(transactions))
 	for _, tx := range transactions {
-		totalAmount += tx.Amount
-		totalFee += tx.Fee
+		txItems = append(txItems, TransactionItem{
+			TransactionID: uuid.MustParse(tx.ID),
+			OrderNo:       tx.OrderNo,
+			PaymentNo:     tx.PaymentNo,
+			Currency:      tx.Currency,
+			Amount:        tx.Amount,
+			Fee:           tx.Fee,
+			TransactionAt: parseTransactionTime(tx.TransactionAt),
+		})
 	}
+	payoutCurrency := resolvePayoutCurrency(ctx, t.accountRepo, merchantID, "", txItems)
+	breakdown := buildSettlementBreakdown(payoutCurrency, txItems)
 
-	settlementAmount := totalAmount - totalFee
+	totalAmount := breakdown.TotalAmount
+	totalFee := breakdown.TotalFee
+	settlementAmount := breakdown.SettlementAmount()
 
 	// 4. FIXED TODO #2: 从accounting service获取退款数据
 	refundSummary, err := t.accountingClient.GetRefundSummary(ctx, merchantID, yesterday, today)
@@ -201,8 +247,8 @@ func (t *AutoSettlementTask) settleMerchant(ctx context.Context, merchantID uuid
 		}
 	}
 
-	// 5. 检查最小结算金额
-	if settlementAmount < t.config.MinSettlementAmount {
+	// 5. 检查最小结算金额（含外币明细时换汇后金额在执行时确定，不做检查）
+	if len(breakdown.Legs) == 0 && settlementAmount < t.config.MinSettlementAmount {
 		logger.Info("结算金额低于最小值，跳过",
 			zap.String("merchant_id", merchantID.String()),
 			zap.Int64("settlement_amount", settlementAmount),
@@ -217,6 +263,7 @@ func (t *AutoSettlementTask) settleMerchant(ctx context.Context, merchantID uuid
 		TotalAmount:      totalAmount,
 		FeeAmount:        totalFee,
 		SettlementAmount: settlementAmount,
+		Currency:         payoutCurrency,
 		TotalCount:       len(transactions),
 		Status:           model.SettlementStatusPending,
 		Cycle:            model.SettlementCycleDaily,
@@ -234,23 +281,21 @@ func (t *AutoSettlementTask) settleMerchant(ctx context.Context, merchantID uuid
 		}
 
 		// 5.2 创建结算明细
-		for _, tx := range transactions {
-			item := &model.SettlementItem{
-				SettlementID:  settlement.ID,
-				TransactionID: uuid.MustParse(tx.ID),
-				OrderNo:       tx.OrderNo,
-				PaymentNo:     tx.PaymentNo,
-				Amount:        tx.Amount,
-				Fee:           tx.Fee,
-				SettleAmount:  tx.Amount - tx.Fee,
-				TransactionAt: parseTransactionTime(tx.TransactionAt),
-			}
-
+		for _, item := range breakdown.Items {
+			item.SettlementID = settlement.ID
 			if err := t.db.WithContext(ctx).Create(item).Error; err != nil {
 				return fmt.Errorf("创建结算明细失败: %w", err)
 			}
 		}
 
+		// 5.3 创建换汇分录
+		for _, leg := range breakdown.Legs {
+			leg.SettlementID = settlement.ID
+		}
+		if err := t.settlementRepo.CreateFXLegs(ctx, breakdown.Legs); err != nil {
+			return fmt.Errorf("创建换汇分录失败: %w", err)
+		}
+
 		return nil
 	})
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/money"
 	"payment-platform/settlement-service/internal/model"
 	"payment-platform/settlement-service/internal/repository"
 )
@@ -19,6 +20,7 @@ type SettlementAccountService interface {
 	UpdateAccount(ctx context.Context, account *model.SettlementAccount) error
 	DeleteAccount(ctx context.Context, id uuid.UUID) error
 	SetDefaultAccount(ctx context.Context, merchantID, accountID uuid.UUID) error
+	SetPayoutCurrency(ctx context.Context, merchantID, accountID uuid.UUID, currency string) (*model.SettlementAccount, error)
 	VerifyAccount(ctx context.Context, id uuid.UUID, method string) error
 	RejectAccount(ctx context.Context, id uuid.UUID, reason string) error
 	ListPendingAccounts(ctx context.Context, limit, offset int) ([]*model.SettlementAccount, int64, error)
@@ -40,6 +42,13 @@ func (s *settlementAccountService) CreateAccount(ctx context.Context, account *m
 		return errors.New("invalid account type")
 	}
 
+	// 出款币种与账户币种相同时无需单独记录
+	account.Currency = money.NormalizeCurrency(account.Currency)
+	account.PayoutCurrency = money.NormalizeCurrency(account.PayoutCurrency)
+	if account.PayoutCurrency == account.Currency {
+		account.PayoutCurrency = ""
+	}
+
 	// 设置初始状态
 	account.Status = model.AccountStatusPendingVerify
 
@@ -112,6 +121,33 @@ func (s *settlementAccountService) SetDefaultAccount(ctx context.Context, mercha
 	return s.repo.SetDefault(ctx, merchantID, accountID)
 }
 
+// SetPayoutCurrency 设置结算账户出款币种，结算时其他币种余额换汇为该币种
+func (s *settlementAccountService) SetPayoutCurrency(ctx context.Context, merchantID, accountID uuid.UUID, currency string) (*model.SettlementAccount, error) {
+	account, err := s.repo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.MerchantID != merchantID {
+		return nil, errors.New("account does not belong to merchant")
+	}
+
+	currency = money.NormalizeCurrency(currency)
+	if len(currency) != 3 {
+		return nil, errors.New("invalid payout currency")
+	}
+
+	account.PayoutCurrency = currency
+	if currency == account.Currency {
+		account.PayoutCurrency = ""
+	}
+
+	if err := s.repo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
 // VerifyAccount 验证结算账户
 func (s *settlementAccountService) VerifyAccount(ctx context.Context, id uuid.UUID, method string) error {
 	account, err := s.repo.GetByID(ctx, id)
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/money"
+	"go.uber.org/zap"
+	"payment-platform/settlement-service/internal/client"
+	"payment-platform/settlement-service/internal/model"
+	"payment-platform/settlement-service/internal/repository"
+)
+
+// defaultPayoutCurrency 未配置结算账户且交易未携带币种时的出款币种
+const defaultPayoutCurrency = "CNY"
+
+// 货币转换状态（accounting-service）
+const (
+	conversionStatusPending   = "pending"
+	conversionStatusCompleted = "completed"
+)
+
+// settlementBreakdown 按出款币种拆分后的结算明细
+type settlementBreakdown struct {
+	Items       []*model.SettlementItem
+	Legs        []*model.SettlementFXLeg
+	TotalAmount int64 // 出款币种交易总额（不含待换汇明细）
+	TotalFee    int64 // 出款币种手续费（不含待换汇明细）
+}
+
+// SettlementAmount 出款币种结算金额（不含待换汇明细）
+func (b *settlementBreakdown) SettlementAmount() int64 {
+	return b.TotalAmount - b.TotalFee
+}
+
+// buildSettlementBreakdown 生成结算明细：出款币种的交易直接计入结算金额，
+// 其他币种按币种汇总为换汇分录，在执行结算时按锁定汇率换汇后再计入
+func buildSettlementBreakdown(payoutCurrency string, transactions []TransactionItem) *settlementBreakdown {
+	breakdown := &settlementBreakdown{
+		Items: make([]*model.SettlementItem, 0, len(transactions)),
+	}
+	legs := make(map[string]*model.SettlementFXLeg)
+
+	for _, tx := range transactions {
+		currency := money.NormalizeCurrency(tx.Currency)
+		if currency == "" {
+			currency = payoutCurrency
+		}
+
+		item := &model.SettlementItem{
+			TransactionID: tx.TransactionID,
+			OrderNo:       tx.OrderNo,
+			PaymentNo:     tx.PaymentNo,
+			Currency:      currency,
+			Amount:        tx.Amount,
+			Fee:           tx.Fee,
+			TransactionAt: tx.TransactionAt,
+		}
+		breakdown.Items = append(breakdown.Items, item)
+
+		if currency == payoutCurrency {
+			item.SettleAmount = item.NetAmount()
+			breakdown.TotalAmount += tx.Amount
+			breakdown.TotalFee += tx.Fee
+			continue
+		}
+
+		leg, ok := legs[currency]
+		if !ok {
+			leg = &model.SettlementFXLeg{
+				SourceCurrency: currency,
+				TargetCurrency: payoutCurrency,
+				Status:         model.FXLegStatusPending,
+			}
+			legs[currency] = leg
+			breakdown.Legs = append(breakdown.Legs, leg)
+		}
+		leg.ItemCount++
+		leg.GrossAmount += tx.Amount
+		leg.FeeAmount += tx.Fee
+		leg.NetAmount += item.NetAmount()
+	}
+
+	sort.Slice(breakdown.Legs, func(i, j int) bool {
+		return breakdown.Legs[i].SourceCurrency < breakdown.Legs[j].SourceCurrency
+	})
+
+	return breakdown
+}
+
+// resolvePayoutCurrency 确定出款币种：指定币种 > 默认结算账户出款币种 > 交易币种 > CNY
+func resolvePayoutCurrency(ctx context.Context, accountRepo repository.SettlementAccountRepository, merchantID uuid.UUID, requested string, transactions []TransactionItem) string {
+	if currency := money.NormalizeCurrency(requested); currency != "" {
+		return currency
+	}
+
+	if accountRepo != nil {
+		account, err := accountRepo.GetDefaultByMerchantID(ctx, merchantID)
+		if err == nil && account != nil {
+			if currency := money.NormalizeCurrency(account.GetPayoutCurrency()); currency != "" {
+				return currency
+			}
+		}
+	}
+
+	for _, tx := range transactions {
+		if currency := money.NormalizeCurrency(tx.Currency); currency != "" {
+			return currency
+		}
+	}
+	return defaultPayoutCurrency
+}
+
+// SettlementFXService 结算换汇服务：将外币明细按锁定汇率换汇为出款币种，
+// 换汇分录通过 accounting-service 货币转换入账，保证各币种账本平衡
+type SettlementFXService struct {
+	settlementRepo   repository.SettlementRepository
+	accountingClient *client.AccountingClient
+}
+
+// NewSettlementFXService 创建结算换汇服务
+func NewSettlementFXService(settlementRepo repository.SettlementRepository, accountingClient *client.AccountingClient) *SettlementFXService {
+	return &SettlementFXService{
+		settlementRepo:   settlementRepo,
+		accountingClient: accountingClient,
+	}
+}
+
+// ConvertSettlement 执行结算单的换汇分录并按出款币种重算结算金额
+//
+// 已完成的分录会跳过，已创建未执行的转换单会继续执行，因此重试不会重复换汇。
+func (s *SettlementFXService) ConvertSettlement(ctx context.Context, settlement *model.Settlement) error {
+	legs, err := s.settlementRepo.GetFXLegs(ctx, settlement.ID)
+	if err != nil {
+		return fmt.Errorf("查询换汇分录失败: %w", err)
+	}
+	if len(legs) == 0 {
+		return nil
+	}
+
+	items, err := s.settlementRepo.GetItems(ctx, settlement.ID)
+	if err != nil {
+		return fmt.Errorf("查询结算明细失败: %w", err)
+	}
+
+	for _, leg := range legs {
+		if leg.Status == model.FXLegStatusCompleted || leg.Status == model.FXLegStatusSkipped {
+			continue
+		}
+		// 退款冲抵后净额不为正的币种不换汇，留在源币种账户结转，明细不计入本次出款
+		if leg.NetAmount <= 0 {
+			leg.Status = model.FXLegStatusSkipped
+			leg.ErrorMessage = ""
+			if err := s.settlementRepo.UpdateFXLeg(ctx, leg); err != nil {
+				return fmt.Errorf("更新换汇分录失败: %w", err)
+			}
+			logger.Warn("结算币种净额不为正，跳过换汇",
+				zap.String("settlement_no", settlement.SettlementNo),
+				zap.String("currency", leg.SourceCurrency),
+				zap.Int64("net_amount", leg.NetAmount))
+			continue
+		}
+		if err := s.convertLeg(ctx, settlement, leg); err != nil {
+			leg.Status = model.FXLegStatusFailed
+			leg.ErrorMessage = err.Error()
+			if updateErr := s.settlementRepo.UpdateFXLeg(ctx, leg); updateErr != nil {
+				logger.Error("更新换汇分录失败",
+					zap.String("settlement_no", settlement.SettlementNo),
+					zap.String("currency", leg.SourceCurrency),
+					zap.Error(updateErr))
+			}
+			return fmt.Errorf("%s 换汇失败: %w", leg.SourceCurrency, err)
+		}
+
+		legItems := itemsInCurrency(items, leg.SourceCurrency)
+		if err := applyFXLeg(leg, legItems); err != nil {
+			return fmt.Errorf("%s 换汇分摊失败: %w", leg.SourceCurrency, err)
+		}
+		if err := s.settlementRepo.UpdateItems(ctx, legItems); err != nil {
+			return fmt.Errorf("更新结算明细失败: %w", err)
+		}
+		if err := s.settlementRepo.UpdateFXLeg(ctx, leg); err != nil {
+			return fmt.Errorf("更新换汇分录失败: %w", err)
+		}
+
+		logger.Info("结算换汇完成",
+			zap.String("settlement_no", settlement.SettlementNo),
+			zap.String("conversion_no", leg.ConversionNo),
+			zap.String("pair", leg.SourceCurrency+"/"+leg.TargetCurrency),
+			zap.Int64("net_amount", leg.NetAmount),
+			zap.Int64("converted_amount", leg.ConvertedAmount),
+			zap.String("fx_rate", leg.FXRate.String()))
+	}
+
+	if err := restateSettlementTotals(settlement, items); err != nil {
+		return err
+	}
+	return s.settlementRepo.Update(ctx, settlement)
+}
+
+// convertLeg 通过 accounting-service 创建并执行货币转换（源币种出账、出款币种入账）
+//
+// 换汇按含费模式提交：源币种出账总额（换汇金额 + 换汇手续费）等于分录净额，手续费由结算金额承担。
+func (s *SettlementFXService) convertLeg(ctx context.Context, settlement *model.Settlement, leg *model.SettlementFXLeg) error {
+	if s.accountingClient == nil {
+		return fmt.Errorf("accounting client is nil")
+	}
+
+	var conversion *client.CurrencyConversion
+	var err error
+	if leg.ConversionNo == "" {
+		conversion, err = s.accountingClient.CreateCurrencyConversion(ctx, &client.CreateCurrencyConversionRequest{
+			MerchantID:     settlement.MerchantID,
+			SourceCurrency: leg.SourceCurrency,
+			TargetCurrency: leg.TargetCurrency,
+			SourceAmount:   leg.NetAmount,
+			FeeInclusive:   true,
+			Reason:         "settlement",
+			Notes:          fmt.Sprintf("结算换汇: %s", settlement.SettlementNo),
+		})
+		if err != nil {
+			return err
+		}
+		// 先记录转换单号，执行失败重试时复用同一转换单
+		leg.ConversionNo = conversion.ConversionNo
+		if err := s.settlementRepo.UpdateFXLeg(ctx, leg); err != nil {
+			return fmt.Errorf("记录转换单号失败: %w", err)
+		}
+	} else {
+		conversion, err = s.accountingClient.GetCurrencyConversion(ctx, leg.ConversionNo)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch conversion.Status {
+	case conversionStatusPending:
+		if err := s.accountingClient.ProcessCurrencyConversion(ctx, conversion.ConversionNo); err != nil {
+			return err
+		}
+	case conversionStatusCompleted:
+	default:
+		return fmt.Errorf("货币转换 %s 状态异常: %s", conversion.ConversionNo, conversion.Status)
+	}
+
+	now := time.Now()
+	leg.ConvertedAmount = conversion.TargetAmount
+	leg.FXRate = conversion.ExchangeRate
+	leg.MidRate = conversion.MidRate
+	leg.FXFee = conversion.FeeAmount
+	leg.FXQuoteID = conversion.FXQuoteID
+	leg.Status = model.FXLegStatusCompleted
+	leg.ErrorMessage = ""
+	leg.ConvertedAt = &now
+	return nil
+}
+
+// itemsInCurrency 筛选指定交易币种的明细
+func itemsInCurrency(items []*model.SettlementItem, currency string) []*model.SettlementItem {
+	result := make([]*model.SettlementItem, 0)
+	for _, item := range items {
+		if item.Currency == currency {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// applyFXLeg 将换汇结果分摊到明细：
+// 换汇手续费按净额比例分摊，结算金额按成交汇率逐笔换算扣除手续费后的净额，
+// 舍入差额计入金额最大的明细，保证明细合计等于换汇入账金额；
+// 加点成本为按中间价与按成交汇率换算的差额
+func applyFXLeg(leg *model.SettlementFXLeg, items []*model.SettlementItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ratios := make([]int64, len(items))
+	for i, item := range items {
+		if item.NetAmount() > 0 {
+			ratios[i] = item.NetAmount()
+		}
+	}
+	fees := make([]money.Money, len(items))
+	if leg.FXFee != 0 {
+		var err error
+		fees, err = money.New(leg.FXFee, leg.SourceCurrency).Allocate(ratios...)
+		if err != nil {
+			return err
+		}
+	}
+
+	var converted int64
+	largest := 0
+	for i, item := range items {
+		item.FXFee = fees[i].Amount
+		net := money.New(item.NetAmount()-item.FXFee, leg.SourceCurrency)
+		settle, err := net.Convert(leg.FXRate, leg.TargetCurrency, money.RoundHalfEven)
+		if err != nil {
+			return err
+		}
+		atMid, err := net.Convert(leg.MidRate, leg.TargetCurrency, money.RoundHalfEven)
+		if err != nil {
+			return err
+		}
+
+		rate := leg.FXRate
+		item.FXRate = &rate
+		item.SettleAmount = settle.Amount
+		item.FXMarkupAmount = atMid.Amount - settle.Amount
+		converted += settle.Amount
+
+		if item.NetAmount() > items[largest].NetAmount() {
+			largest = i
+		}
+	}
+	items[largest].SettleAmount += leg.ConvertedAmount - converted
+	return nil
+}
+
+// restateSettlementTotals 换汇完成后按出款币种重算结算单金额
+//
+// 手续费含交易手续费与换汇手续费；跳过换汇（结转）的外币明细不计入。
+func restateSettlementTotals(settlement *model.Settlement, items []*model.SettlementItem) error {
+	var totalAmount, totalFee, settlementAmount int64
+	for _, item := range items {
+		if item.FXRate == nil {
+			if item.Currency != settlement.Currency {
+				continue
+			}
+			totalAmount += item.Amount
+			totalFee += item.Fee
+			settlementAmount += item.SettleAmount
+			continue
+		}
+
+		// 换汇明细：手续费按成交汇率换算，交易总额 = 结算金额 + 手续费，保证 总额 - 手续费 = 结算金额
+		fee, err := money.New(item.Fee+item.FXFee, item.Currency).Convert(*item.FXRate, settlement.Currency, money.RoundHalfEven)
+		if err != nil {
+			return fmt.Errorf("换算手续费失败: %w", err)
+		}
+		totalFee += fee.Amount
+		totalAmount += item.SettleAmount + fee.Amount
+		settlementAmount += item.SettleAmount
+	}
+
+	settlement.TotalAmount = totalAmount
+	settlement.FeeAmount = totalFee
+	settlement.SettlementAmount = settlementAmount
+	return nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/payment-platform/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"payment-platform/settlement-service/internal/model"
+)
+
+func TestBuildSettlementBreakdown(t *testing.T) {
+	tests := []struct {
+		name             string
+		transactions     []TransactionItem
+		wantTotalAmount  int64
+		wantTotalFee     int64
+		wantSettleAmount []int64
+		wantLegs         []model.SettlementFXLeg
+	}{
+		{
+			name: "全部为出款币种",
+			transactions: []TransactionItem{
+				{Currency: "CNY", Amount: 10000, Fee: 60},
+				{Currency: "CNY", Amount: 5000, Fee: 30},
+			},
+			wantTotalAmount:  15000,
+			wantTotalFee:     90,
+			wantSettleAmount: []int64{9940, 4970},
+		},
+		{
+			name: "外币按币种汇总为换汇分录",
+			transactions: []TransactionItem{
+				{Currency: "CNY", Amount: 10000, Fee: 60},
+				{Currency: "usd", Amount: 2000, Fee: 60},
+				{Currency: "USD", Amount: 1000, Fee: 30},
+				{Currency: "EUR", Amount: 500, Fee: 15},
+				{Currency: "", Amount: 100, Fee: 0},
+			},
+			wantTotalAmount:  10100,
+			wantTotalFee:     60,
+			wantSettleAmount: []int64{9940, 0, 0, 0, 100},
+			wantLegs: []model.SettlementFXLeg{
+				{SourceCurrency: "EUR", TargetCurrency: "CNY", ItemCount: 1, GrossAmount: 500, FeeAmount: 15, NetAmount: 485, Status: model.FXLegStatusPending},
+				{SourceCurrency: "USD", TargetCurrency: "CNY", ItemCount: 2, GrossAmount: 3000, FeeAmount: 90, NetAmount: 2910, Status: model.FXLegStatusPending},
+			},
+		},
+		{
+			name: "退款冲抵后外币净额为负",
+			transactions: []TransactionItem{
+				{Currency: "USD", Amount: 1000, Fee: 30},
+				{Currency: "USD", Amount: -2000, Fee: 0},
+			},
+			wantSettleAmount: []int64{0, 0},
+			wantLegs: []model.SettlementFXLeg{
+				{SourceCurrency: "USD", TargetCurrency: "CNY", ItemCount: 2, GrossAmount: -1000, FeeAmount: 30, NetAmount: -1030, Status: model.FXLegStatusPending},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := buildSettlementBreakdown("CNY", tt.transactions)
+
+			assert.Equal(t, tt.wantTotalAmount, breakdown.TotalAmount)
+			assert.Equal(t, tt.wantTotalFee, breakdown.TotalFee)
+			assert.Equal(t, tt.wantTotalAmount-tt.wantTotalFee, breakdown.SettlementAmount())
+
+			require.Len(t, breakdown.Items, len(tt.wantSettleAmount))
+			for i, item := range breakdown.Items {
+				assert.Equal(t, tt.wantSettleAmount[i], item.SettleAmount, "item %d", i)
+			}
+
+			require.Len(t, breakdown.Legs, len(tt.wantLegs))
+			for i, leg := range breakdown.Legs {
+				assert.Equal(t, tt.wantLegs[i], *leg)
+			}
+		})
+	}
+}
+
+func TestApplyFXLeg(t *testing.T) {
+	tests := []struct {
+		name        string
+		leg         model.SettlementFXLeg
+		items       []*model.SettlementItem
+		wantSettle  []int64
+		wantFXFee   []int64
+		wantMarkups []int64
+	}{
+		{
+			name: "手续费按净额分摊后换算",
+			// 含费换汇：2896 + 手续费 14 = 2910，2896 × 7.1 = 20561.6 -> 20562
+			leg: model.SettlementFXLeg{
+				SourceCurrency: "USD", TargetCurrency: "CNY", NetAmount: 2910,
+				ConvertedAmount: 20562, FXFee: 14,
+				FXRate: money.MustParseDecimal("7.1"), MidRate: money.MustParseDecimal("7.2"),
+			},
+			items: []*model.SettlementItem{
+				{Currency: "USD", Amount: 2000, Fee: 60},
+				{Currency: "USD", Amount: 1000, Fee: 30},
+			},
+			wantSettle:  []int64{13710, 6852},
+			wantFXFee:   []int64{9, 5},
+			wantMarkups: []int64{193, 96},
+		},
+		{
+			name: "舍入差额计入金额最大的明细",
+			leg: model.SettlementFXLeg{
+				SourceCurrency: "USD", TargetCurrency: "CNY", NetAmount: 2910,
+				ConvertedAmount: 20563, FXFee: 14,
+				FXRate: money.MustParseDecimal("7.1"), MidRate: money.MustParseDecimal("7.2"),
+			},
+			items: []*model.SettlementItem{
+				{Currency: "USD", Amount: 1000, Fee: 30},
+				{Currency: "USD", Amount: 2000, Fee: 60},
+			},
+			wantSettle:  []int64{6852, 13711},
+			wantFXFee:   []int64{5, 9},
+			wantMarkups: []int64{96, 193},
+		},
+		{
+			name: "退款明细不分摊手续费",
+			// 1433 + 手续费 7 = 1440，1433 × 7.1 = 10174.3 -> 10174
+			leg: model.SettlementFXLeg{
+				SourceCurrency: "USD", TargetCurrency: "CNY", NetAmount: 1440,
+				ConvertedAmount: 10174, FXFee: 7,
+				FXRate: money.MustParseDecimal("7.1"), MidRate: money.MustParseDecimal("7.2"),
+			},
+			items: []*model.SettlementItem{
+				{Currency: "USD", Amount: 2000, Fee: 60},
+				{Currency: "USD", Amount: -500, Fee: 0},
+			},
+			wantSettle:  []int64{13724, -3550},
+			wantFXFee:   []int64{7, 0},
+			wantMarkups: []int64{194, -50},
+		},
+		{
+			name: "跨小数位币种换算",
+			leg: model.SettlementFXLeg{
+				SourceCurrency: "JPY", TargetCurrency: "USD", NetAmount: 10000,
+				ConvertedAmount: 6700, FXFee: 0,
+				FXRate: money.MustParseDecimal("0.0067"), MidRate: money.MustParseDecimal("0.0068"),
+			},
+			items: []*model.SettlementItem{
+				{Currency: "JPY", Amount: 10000, Fee: 0},
+			},
+			wantSettle:  []int64{6700},
+			wantFXFee:   []int64{0},
+			wantMarkups: []int64{100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leg := tt.leg
+			require.NoError(t, applyFXLeg(&leg, tt.items))
+
+			var settled int64
+			for i, item := range tt.items {
+				require.NotNil(t, item.FXRate)
+				assert.Equal(t, leg.FXRate.String(), item.FXRate.String())
+				assert.Equal(t, tt.wantSettle[i], item.SettleAmount, "settle %d", i)
+				assert.Equal(t, tt.wantFXFee[i], item.FXFee, "fx fee %d", i)
+				assert.Equal(t, tt.wantMarkups[i], item.FXMarkupAmount, "markup %d", i)
+				settled += item.SettleAmount
+			}
+			assert.Equal(t, leg.ConvertedAmount, settled)
+		})
+	}
+}
+
+func TestRestateSettlementTotals(t *testing.T) {
+	rate := money.MustParseDecimal("7.1")
+
+	tests := []struct {
+		name                 string
+		items                []*model.SettlementItem
+		wantTotalAmount      int64
+		wantFeeAmount        int64
+		wantSettlementAmount int64
+	}{
+		{
+			name: "仅出款币种",
+			items: []*model.SettlementItem{
+				{Currency: "CNY", Amount: 10000, Fee: 60, SettleAmount: 9940},
+			},
+			wantTotalAmount:      10000,
+			wantFeeAmount:        60,
+			wantSettlementAmount: 9940,
+		},
+		{
+			name: "换汇明细计入交易手续费与换汇手续费",
+			items: []*model.SettlementItem{
+				{Currency: "CNY", Amount: 10000, Fee: 60, SettleAmount: 9940},
+				// (60 + 9) × 7.1 = 489.9 -> 490
+				{Currency: "USD", Amount: 2000, Fee: 60, FXFee: 9, SettleAmount: 13710, FXRate: &rate},
+				// (30 + 5) × 7.1 = 248.5 -> 248
+				{Currency: "USD", Amount: 1000, Fee: 30, FXFee: 5, SettleAmount: 6852, FXRate: &rate},
+			},
+			wantTotalAmount:      31300,
+			wantFeeAmount:        798,
+			wantSettlementAmount: 30502,
+		},
+		{
+			name: "跳过换汇的外币明细不计入",
+			items: []*model.SettlementItem{
+				{Currency: "CNY", Amount: 10000, Fee: 60, SettleAmount: 9940},
+				{Currency: "EUR", Amount: -800, Fee: 0},
+			},
+			wantTotalAmount:      10000,
+			wantFeeAmount:        60,
+			wantSettlementAmount: 9940,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement := &model.Settlement{Currency: "CNY"}
+			require.NoError(t, restateSettlementTotals(settlement, tt.items))
+
+			assert.Equal(t, tt.wantTotalAmount, settlement.TotalAmount)
+			assert.Equal(t, tt.wantFeeAmount, settlement.FeeAmount)
+			assert.Equal(t, tt.wantSettlementAmount, settlement.SettlementAmount)
+			assert.Equal(t, settlement.TotalAmount-settlement.FeeAmount, settlement.SettlementAmount)
+		})
+	}
+}
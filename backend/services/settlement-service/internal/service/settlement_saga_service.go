@@ -19,6 +19,8 @@ import (
 type SettlementSagaService struct {
 	orchestrator     *saga.SagaOrchestrator
 	settlementRepo   repository.SettlementRepository
+	accountRepo      repository.SettlementAccountRepository
+	fxService        *SettlementFXService
 	merchantClient   *client.MerchantClient
 	withdrawalClient *client.WithdrawalClient
 }
@@ -27,33 +29,32 @@ type SettlementSagaService struct {
 func NewSettlementSagaService(
 	orchestrator *saga.SagaOrchestrator,
 	settlementRepo repository.SettlementRepository,
+	accountRepo repository.SettlementAccountRepository,
+	fxService *SettlementFXService,
 	merchantClient *client.MerchantClient,
 	withdrawalClient *client.WithdrawalClient,
 ) *SettlementSagaService {
-	return &SettlementSagaService{
+	s := &SettlementSagaService{
 		orchestrator:     orchestrator,
 		settlementRepo:   settlementRepo,
+		accountRepo:      accountRepo,
+		fxService:        fxService,
 		merchantClient:   merchantClient,
 		withdrawalClient: withdrawalClient,
 	}
+	orchestrator.RegisterStepDefinitions(SagaTypeSettlement, s.recoverStepDefinitions)
+	return s
 }
 
-// ExecuteSettlementSaga 执行结算 Saga
-func (s *SettlementSagaService) ExecuteSettlementSaga(
-	ctx context.Context,
-	settlement *model.Settlement,
-) error {
-	// 1. 构建 Saga
-	sagaBuilder := s.orchestrator.NewSagaBuilder(settlement.SettlementNo, "settlement")
-	sagaBuilder.SetMetadata(map[string]interface{}{
-		"settlement_no": settlement.SettlementNo,
-		"merchant_id":   settlement.MerchantID.String(),
-		"amount":        settlement.SettlementAmount,
-		"cycle":         settlement.Cycle,
-	})
+// SagaTypeSettlement 结算 Saga 业务类型
+const SagaTypeSettlement = "settlement"
 
-	// 2. 定义步骤
-	stepDefs := []saga.StepDefinition{
+// stepDefinitions 结算 Saga 步骤定义
+//
+// 换汇步骤会修改结算币种与金额，获取账户步骤需要校验出款币种，步骤之间有数据依赖，保持顺序执行；
+// 结算账户与提现单号通过 saga.StepOutput 按步骤名称传递，恢复续跑时从已完成步骤的结果中还原。
+func (s *SettlementSagaService) stepDefinitions(settlement *model.Settlement) []saga.StepDefinition {
+	return []saga.StepDefinition{
 		{
 			Name: "UpdateSettlementProcessing",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
@@ -76,10 +77,21 @@ func (s *SettlementSagaService) ExecuteSettlementSaga(
 			MaxRetryCount: 3,
 			Timeout:       30 * time.Second,
 		},
+		{
+			Name: "ConvertCurrencies",
+			Execute: func(ctx context.Context, executeData string) (string, error) {
+				return s.executeConvertCurrencies(ctx, settlement)
+			},
+			Compensate: func(ctx context.Context, compensateData string, executeResult string) error {
+				return s.compensateConvertCurrencies(ctx, settlement)
+			},
+			MaxRetryCount: 3,
+			Timeout:       60 * time.Second,
+		},
 		{
 			Name: "CreateWithdrawal",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
-				return s.executeCreateWithdrawal(ctx, settlement, executeData)
+				return s.executeCreateWithdrawal(ctx, settlement)
 			},
 			Compensate: func(ctx context.Context, compensateData string, executeResult string) error {
 				return s.compensateCreateWithdrawal(ctx, settlement, executeResult)
@@ -90,7 +102,7 @@ func (s *SettlementSagaService) ExecuteSettlementSaga(
 		{
 			Name: "UpdateSettlementCompleted",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
-				return s.executeUpdateSettlementCompleted(ctx, settlement, executeData)
+				return s.executeUpdateSettlementCompleted(ctx, settlement, saga.StepOutput(ctx, "CreateWithdrawal"))
 			},
 			Compensate: func(ctx context.Context, compensateData string, executeResult string) error {
 				return s.compensateUpdateSettlementCompleted(ctx, settlement)
@@ -99,6 +111,37 @@ func (s *SettlementSagaService) ExecuteSettlementSaga(
 			Timeout:       10 * time.Second,
 		},
 	}
+}
+
+// recoverStepDefinitions 恢复时根据 settlement_no 重新加载结算单并重建步骤定义
+func (s *SettlementSagaService) recoverStepDefinitions(ctx context.Context, sagaInstance *saga.Saga) ([]saga.StepDefinition, error) {
+	settlement, err := s.settlementRepo.GetBySettlementNo(ctx, sagaInstance.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement: %w", err)
+	}
+	if settlement == nil {
+		return nil, fmt.Errorf("settlement not found: %s", sagaInstance.BusinessID)
+	}
+	return s.stepDefinitions(settlement), nil
+}
+
+// ExecuteSettlementSaga 执行结算 Saga
+func (s *SettlementSagaService) ExecuteSettlementSaga(
+	ctx context.Context,
+	settlement *model.Settlement,
+) error {
+	// 1. 构建 Saga
+	sagaBuilder := s.orchestrator.NewSagaBuilder(settlement.SettlementNo, SagaTypeSettlement)
+	sagaBuilder.SetMetadata(map[string]interface{}{
+		"settlement_no": settlement.SettlementNo,
+		"merchant_id":   settlement.MerchantID.String(),
+		"amount":        settlement.SettlementAmount,
+		"cycle":         settlement.Cycle,
+		"currency":      settlement.Currency,
+	})
+
+	// 2. 定义步骤
+	stepDefs := s.stepDefinitions(settlement)
 
 	// 添加步骤到构建器
 	for _, def := range stepDefs {
@@ -180,6 +223,19 @@ func (s *SettlementSagaService) executeGetMerchantAccount(ctx context.Context, s
 		zap.String("settlement_no", settlement.SettlementNo),
 		zap.String("merchant_id", settlement.MerchantID.String()))
 
+	// 优先使用本服务维护的默认结算账户（含出款币种配置）
+	if s.accountRepo != nil {
+		account, err := s.accountRepo.GetDefaultByMerchantID(ctx, settlement.MerchantID)
+		if err == nil && account != nil {
+			if settlement.Currency != "" && account.GetPayoutCurrency() != settlement.Currency {
+				return "", fmt.Errorf("payout currency changed from %s to %s, settlement must be regenerated",
+					settlement.Currency, account.GetPayoutCurrency())
+			}
+			resultBytes, _ := json.Marshal(account)
+			return string(resultBytes), nil
+		}
+	}
+
 	if s.merchantClient == nil {
 		return "", fmt.Errorf("merchant client is nil")
 	}
@@ -195,8 +251,41 @@ func (s *SettlementSagaService) executeGetMerchantAccount(ctx context.Context, s
 	return string(resultBytes), nil
 }
 
+// executeConvertCurrencies 执行结算换汇步骤（外币明细按锁定汇率换汇为出款币种）
+func (s *SettlementSagaService) executeConvertCurrencies(ctx context.Context, settlement *model.Settlement) (string, error) {
+	logger.Info("executing convert settlement currencies",
+		zap.String("settlement_no", settlement.SettlementNo),
+		zap.String("payout_currency", settlement.Currency))
+
+	if s.fxService == nil {
+		return "", nil
+	}
+
+	if err := s.fxService.ConvertSettlement(ctx, settlement); err != nil {
+		return "", fmt.Errorf("convert settlement currencies failed: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"currency":          settlement.Currency,
+		"settlement_amount": settlement.SettlementAmount,
+	}
+	resultBytes, _ := json.Marshal(result)
+	return string(resultBytes), nil
+}
+
+// compensateConvertCurrencies 补偿结算换汇步骤
+//
+// 已入账的货币转换不做反向换汇（会产生二次汇兑损益），资金保留在商户出款币种账户中，
+// 换汇分录保持已完成状态，重新执行结算时不会重复换汇。
+func (s *SettlementSagaService) compensateConvertCurrencies(ctx context.Context, settlement *model.Settlement) error {
+	logger.Warn("settlement currency conversion kept after saga rollback",
+		zap.String("settlement_no", settlement.SettlementNo),
+		zap.String("payout_currency", settlement.Currency))
+	return nil
+}
+
 // executeCreateWithdrawal 执行创建提现步骤
-func (s *SettlementSagaService) executeCreateWithdrawal(ctx context.Context, settlement *model.Settlement, accountData string) (string, error) {
+func (s *SettlementSagaService) executeCreateWithdrawal(ctx context.Context, settlement *model.Settlement) (string, error) {
 	logger.Info("executing create withdrawal",
 		zap.String("settlement_no", settlement.SettlementNo))
 
@@ -204,20 +293,19 @@ func (s *SettlementSagaService) executeCreateWithdrawal(ctx context.Context, set
 		return "", fmt.Errorf("withdrawal client is nil")
 	}
 
-	// 解析账户信息
-	var account struct {
-		ID uuid.UUID `json:"id"`
-	}
-	if err := json.Unmarshal([]byte(accountData), &account); err != nil {
-		return "", fmt.Errorf("parse account data failed: %w", err)
+	// 结算账户只用于校验出款币种，提现单必须引用 withdrawal-service 的银行账户
+	bankAccount, err := s.withdrawalClient.GetDefaultBankAccount(ctx, settlement.MerchantID)
+	if err != nil {
+		return "", fmt.Errorf("get withdrawal bank account failed: %w", err)
 	}
 
 	// 调用 withdrawal-service 创建提现
 	withdrawalReq := &client.CreateWithdrawalRequest{
 		MerchantID:    settlement.MerchantID,
 		Amount:        settlement.SettlementAmount,
+		Currency:      settlement.Currency,
 		Type:          "settlement_auto",
-		BankAccountID: account.ID,
+		BankAccountID: bankAccount.ID,
 		Remarks:       fmt.Sprintf("自动结算: %s, 周期: %s", settlement.SettlementNo, settlement.Cycle),
 		CreatedBy:     uuid.MustParse("00000000-0000-0000-0000-000000000000"), // 系统自动
 	}
@@ -233,6 +321,7 @@ func (s *SettlementSagaService) executeCreateWithdrawal(ctx context.Context, set
 	result := map[string]interface{}{
 		"withdrawal_no": withdrawalNo,
 		"amount":        settlement.SettlementAmount,
+		"currency":      settlement.Currency,
 	}
 	resultBytes, _ := json.Marshal(result)
 	return string(resultBytes), nil
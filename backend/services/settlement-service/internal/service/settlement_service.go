@@ -33,6 +33,8 @@ type SettlementService interface {
 type settlementService struct {
 	db                 *gorm.DB
 	settlementRepo     repository.SettlementRepository
+	accountRepo        repository.SettlementAccountRepository
+	fxService          *SettlementFXService
 	accountingClient   *client.AccountingClient
 	withdrawalClient   *client.WithdrawalClient
 	merchantClient     *client.MerchantClient
@@ -47,6 +49,8 @@ type settlementService struct {
 func NewSettlementService(
 	db *gorm.DB,
 	settlementRepo repository.SettlementRepository,
+	accountRepo repository.SettlementAccountRepository,
+	fxService *SettlementFXService,
 	accountingClient *client.AccountingClient,
 	withdrawalClient *client.WithdrawalClient,
 	merchantClient *client.MerchantClient,
@@ -57,6 +61,8 @@ func NewSettlementService(
 	return &settlementService{
 		db:                 db,
 		settlementRepo:     settlementRepo,
+		accountRepo:        accountRepo,
+		fxService:          fxService,
 		accountingClient:   accountingClient,
 		withdrawalClient:   withdrawalClient,
 		merchantClient:     merchantClient,
@@ -81,6 +87,7 @@ type CreateSettlementInput struct {
 	EndDate      time.Time
 	Transactions []TransactionItem
 	BatchNo      string // 批次号（可选，用于幂等性）
+	Currency     string // 出款币种（可选，默认取商户默认结算账户的出款币种）
 }
 
 // TransactionItem 交易明细
@@ -88,6 +95,7 @@ type TransactionItem struct {
 	TransactionID uuid.UUID
 	OrderNo       string
 	PaymentNo     string
+	Currency      string // 交易币种（为空时视为出款币种）
 	Amount        int64
 	Fee           int64
 	TransactionAt time.Time
@@ -147,28 +155,10 @@ func (s *settlementService) CreateSettlement(ctx context.Context, input *CreateS
 	// 生成结算单号
 	settlementNo := fmt.Sprintf("STL%s%d", input.MerchantID.String()[:8], time.Now().Unix())
 
-	// 计算结算金额
-	var totalAmount int64
-	var totalFee int64
-	var totalCount int
-	items := make([]*model.SettlementItem, 0, len(input.Transactions))
-
-	for _, tx := range input.Transactions {
-		totalAmount += tx.Amount
-		totalFee += tx.Fee
-		totalCount++
-
-		item := &model.SettlementItem{
-			TransactionID: tx.TransactionID,
-			OrderNo:       tx.OrderNo,
-			PaymentNo:     tx.PaymentNo,
-			Amount:        tx.Amount,
-			Fee:           tx.Fee,
-			SettleAmount:  tx.Amount - tx.Fee,
-			TransactionAt: tx.TransactionAt,
-		}
-		items = append(items, item)
-	}
+	// 计算结算金额（出款币种明细直接计入，其他币种生成换汇分录，执行结算时换汇）
+	payoutCurrency := resolvePayoutCurrency(ctx, s.accountRepo, input.MerchantID, input.Currency, input.Transactions)
+	breakdown := buildSettlementBreakdown(payoutCurrency, input.Transactions)
+	items := breakdown.Items
 
 	settlement := &model.Settlement{
 		SettlementNo:     settlementNo,
@@ -176,10 +166,11 @@ func (s *settlementService) CreateSettlement(ctx context.Context, input *CreateS
 		Cycle:            input.Cycle,
 		StartDate:        input.StartDate,
 		EndDate:          input.EndDate,
-		TotalAmount:      totalAmount,
-		TotalCount:       totalCount,
-		FeeAmount:        totalFee,
-		SettlementAmount: totalAmount - totalFee,
+		TotalAmount:      breakdown.TotalAmount,
+		TotalCount:       len(items),
+		FeeAmount:        breakdown.TotalFee,
+		SettlementAmount: breakdown.SettlementAmount(),
+		Currency:         payoutCurrency,
 		Status:           model.SettlementStatusPending,
 	}
 
@@ -198,6 +189,14 @@ func (s *settlementService) CreateSettlement(ctx context.Context, input *CreateS
 			return fmt.Errorf("创建结算明细失败: %w", err)
 		}
 
+		// 创建换汇分录
+		for _, leg := range breakdown.Legs {
+			leg.SettlementID = settlement.ID
+		}
+		if err := s.settlementRepo.CreateFXLegs(ctx, breakdown.Legs); err != nil {
+			return fmt.Errorf("创建换汇分录失败: %w", err)
+		}
+
 		return nil
 	})
 
@@ -230,6 +229,7 @@ func (s *settlementService) CreateSettlement(ctx context.Context, input *CreateS
 type SettlementDetail struct {
 	Settlement *model.Settlement          `json:"settlement"`
 	Items      []*model.SettlementItem    `json:"items"`
+	FXLegs     []*model.SettlementFXLeg   `json:"fx_legs"` // 换汇分录
 	Approvals  []*model.SettlementApproval `json:"approvals"`
 }
 
@@ -245,6 +245,11 @@ func (s *settlementService) GetSettlement(ctx context.Context, id uuid.UUID) (*S
 		return nil, fmt.Errorf("获取结算明细失败: %w", err)
 	}
 
+	fxLegs, err := s.settlementRepo.GetFXLegs(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取换汇分录失败: %w", err)
+	}
+
 	approvals, err := s.settlementRepo.GetApprovals(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("获取审批记录失败: %w", err)
@@ -253,6 +258,7 @@ func (s *settlementService) GetSettlement(ctx context.Context, id uuid.UUID) (*S
 	return &SettlementDetail{
 		Settlement: settlement,
 		Items:      items,
+		FXLegs:     fxLegs,
 		Approvals:  approvals,
 	}, nil
 }
@@ -401,11 +407,12 @@ func (s *settlementService) ExecuteSettlement(ctx context.Context, settlementID
 			zap.String("settlement_no", settlement.SettlementNo),
 			zap.String("settlement_id", settlementID.String()))
 
-		// 执行 Settlement Saga (4 步骤):
+		// 执行 Settlement Saga (5 步骤):
 		// 1. 更新结算单为处理中
 		// 2. 获取商户结算账户
-		// 3. 创建提现单
-		// 4. 更新结算单为完成
+		// 3. 外币明细换汇为出款币种
+		// 4. 创建提现单
+		// 5. 更新结算单为完成
 		// 任何步骤失败会自动回滚所有已完成的步骤
 		err := s.sagaService.ExecuteSettlementSaga(ctx, settlement)
 		if err != nil {
@@ -441,24 +448,35 @@ func (s *settlementService) ExecuteSettlement(ctx context.Context, settlementID
 		return fmt.Errorf("更新结算单状态失败: %w", err)
 	}
 
+	// 外币明细按锁定汇率换汇为出款币种
+	if s.fxService != nil {
+		if err := s.fxService.ConvertSettlement(ctx, settlement); err != nil {
+			settlement.Status = model.SettlementStatusFailed
+			settlement.ErrorMessage = fmt.Sprintf("结算换汇失败: %v", err)
+			s.settlementRepo.Update(ctx, settlement)
+			return fmt.Errorf("结算换汇失败: %w", err)
+		}
+	}
+
 	// 实际转账逻辑：调用 withdrawal-service 创建提现
-	if s.withdrawalClient != nil && s.merchantClient != nil {
-		// 从merchant-service获取商户默认银行账户
-		defaultAccount, err := s.merchantClient.GetDefaultSettlementAccount(ctx, settlement.MerchantID)
+	if s.withdrawalClient != nil {
+		// 从withdrawal-service获取商户默认提现银行账户
+		bankAccount, err := s.withdrawalClient.GetDefaultBankAccount(ctx, settlement.MerchantID)
 		if err != nil {
 			// ⚠️ 状态已更新为处理中，但获取账户失败，数据可能不一致
 			// 生产环境：应该使用上面的 Saga 方案自动回滚
 			settlement.Status = model.SettlementStatusFailed
-			settlement.ErrorMessage = fmt.Sprintf("获取默认结算账户失败: %v", err)
+			settlement.ErrorMessage = fmt.Sprintf("获取提现银行账户失败: %v", err)
 			s.settlementRepo.Update(ctx, settlement)
-			return fmt.Errorf("获取默认结算账户失败: %w", err)
+			return fmt.Errorf("获取提现银行账户失败: %w", err)
 		}
 
 		withdrawalReq := &client.CreateWithdrawalRequest{
 			MerchantID:    settlement.MerchantID,
 			Amount:        settlement.SettlementAmount,
+			Currency:      settlement.Currency,
 			Type:          "settlement_auto",
-			BankAccountID: defaultAccount.ID,
+			BankAccountID: bankAccount.ID,
 			Remarks:       fmt.Sprintf("自动结算: %s, 周期: %s", settlement.SettlementNo, settlement.Cycle),
 			CreatedBy:     uuid.MustParse("00000000-0000-0000-0000-000000000000"), // 系统自动
 		}
@@ -574,6 +592,7 @@ func (s *settlementService) GenerateAutoSettlement(ctx context.Context, merchant
 				TransactionID: txID,
 				OrderNo:       tx.OrderNo,
 				PaymentNo:     tx.PaymentNo,
+				Currency:      tx.Currency,
 				Amount:        tx.Amount,
 				Fee:           tx.Fee,
 				TransactionAt: transactionAt,
@@ -673,7 +692,7 @@ func (s *settlementService) publishSettlementEvent(
 		FeeAmount:        settlement.FeeAmount,
 		SettlementAmount: settlement.SettlementAmount,
 		TotalCount:       settlement.TotalCount,
-		Currency:         "CNY", // 默认人民币，实际应该从配置或商户信息获取
+		Currency:         settlementCurrency(settlement),
 		Status:           string(settlement.Status),
 		StartDate:        settlement.StartDate,
 		EndDate:          settlement.EndDate,
@@ -701,3 +720,11 @@ func (s *settlementService) publishSettlementEvent(
 		zap.String("settlement_no", settlement.SettlementNo),
 		zap.String("status", string(settlement.Status)))
 }
+
+// settlementCurrency 结算单出款币种（兼容未记录币种的历史结算单）
+func settlementCurrency(settlement *model.Settlement) string {
+	if settlement.Currency != "" {
+		return settlement.Currency
+	}
+	return defaultPayoutCurrency
+}
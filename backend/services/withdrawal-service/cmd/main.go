@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/payment-platform/pkg/config"
 	"github.com/payment-platform/pkg/configclient"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/middleware"
 	"github.com/payment-platform/pkg/saga"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -86,6 +88,8 @@ func main() {
 			&model.WithdrawalBankAccount{},
 			&model.WithdrawalApproval{},
 			&model.WithdrawalBatch{},
+			&saga.Saga{},
+			&saga.SagaStep{},
 		},
 		EnableTracing:     true,
 		EnableMetrics:     true,
@@ -147,7 +151,16 @@ func main() {
 		bankTransferClient,
 		notificationClient,
 	)
-	// Saga 服务可用于处理分布式事务，预留未来使用
+	// Saga 服务可用于处理分布式事务，预留未来使用；构造时已注册 withdrawal 步骤定义，中断的 Saga 由恢复工作器续跑
+
+	// 启动Saga恢复工作器（自动续跑中断的提现Saga、重试失败的补偿）
+	recoveryWorker := saga.NewRecoveryWorker(
+		sagaOrchestrator,
+		5*time.Minute, // 每5分钟扫描一次
+		10,            // 每次处理10个Saga
+	)
+	go recoveryWorker.Start(context.Background())
+	logger.Info("Saga Recovery Worker 已启动")
 
 	// 6. 初始化Handler
 	withdrawalHandler := handler.NewWithdrawalHandler(withdrawalService)
@@ -156,6 +169,10 @@ func main() {
 	application.Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	withdrawalHandler.RegisterRoutes(application.Router)
 
+	// 服务间调用路由（admin-bff Saga 查询与人工干预）
+	serviceAuth := middleware.ServiceAuthMiddleware(getConfig("INTERNAL_SERVICE_TOKEN", ""), "admin-bff-service")
+	saga.NewAdminHandler(sagaOrchestrator, recoveryWorker).RegisterServiceRoutes(application.Router.Group("/api/v1", serviceAuth))
+
 	logger.Info("路由注册完成")
 
 	// 8. JWT认证中间件（优先从配置中心获取）
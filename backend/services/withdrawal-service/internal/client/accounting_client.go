@@ -87,6 +87,7 @@ func (c *AccountingClient) GetAvailableBalance(ctx context.Context, merchantID u
 type DeductBalanceRequest struct {
 	MerchantID      uuid.UUID `json:"merchant_id"`
 	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency,omitempty"`
 	TransactionType string    `json:"transaction_type"`
 	RelatedNo       string    `json:"related_no"`
 	Description     string    `json:"description"`
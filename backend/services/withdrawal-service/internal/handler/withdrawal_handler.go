@@ -56,6 +56,7 @@ func (h *WithdrawalHandler) RegisterRoutes(r *gin.Engine) {
 type CreateWithdrawalRequest struct {
 	MerchantID    string               `json:"merchant_id" binding:"required"`
 	Amount        int64                `json:"amount" binding:"required,min=1"`
+	Currency      string               `json:"currency"` // 出款币种（可选，默认CNY）
 	Type          model.WithdrawalType `json:"type" binding:"required"`
 	BankAccountID string               `json:"bank_account_id" binding:"required"`
 	Remarks       string               `json:"remarks"`
@@ -106,6 +107,7 @@ func (h *WithdrawalHandler) CreateWithdrawal(c *gin.Context) {
 	input := &service.CreateWithdrawalInput{
 		MerchantID:    merchantID,
 		Amount:        req.Amount,
+		Currency:      req.Currency,
 		Type:          req.Type,
 		BankAccountID: bankAccountID,
 		Remarks:       req.Remarks,
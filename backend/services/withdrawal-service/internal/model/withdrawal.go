@@ -36,6 +36,7 @@ type Withdrawal struct {
 	Amount          int64            `gorm:"not null" json:"amount"`            // 提现金额（分）
 	Fee             int64            `gorm:"not null;default:0" json:"fee"`     // 手续费（分）
 	ActualAmount    int64            `gorm:"not null" json:"actual_amount"`     // 实际到账金额（分）
+	Currency        string           `gorm:"type:varchar(10);not null;default:'CNY'" json:"currency"` // 出款币种
 	Type            WithdrawalType   `gorm:"type:varchar(20);not null;default:'normal'" json:"type"`
 	Status          WithdrawalStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
 	BankAccountID   uuid.UUID        `gorm:"type:uuid;not null" json:"bank_account_id"`
@@ -31,86 +31,23 @@ func NewWithdrawalSagaService(
 	bankTransferClient *client.BankTransferClient,
 	notificationClient *client.NotificationClient,
 ) *WithdrawalSagaService {
-	return &WithdrawalSagaService{
+	s := &WithdrawalSagaService{
 		orchestrator:       orchestrator,
 		withdrawalRepo:     withdrawalRepo,
 		accountingClient:   accountingClient,
 		bankTransferClient: bankTransferClient,
 		notificationClient: notificationClient,
 	}
+	orchestrator.RegisterStepDefinitions(SagaTypeWithdrawal, s.recoverStepDefinitions)
+	return s
 }
 
-// ExecuteWithdrawalSaga 执行提现 Saga
-func (s *WithdrawalSagaService) ExecuteWithdrawalSaga(
-	ctx context.Context,
-	withdrawal *model.Withdrawal,
-) error {
-	// 1. 构建 Saga
-	sagaBuilder := s.orchestrator.NewSagaBuilder(withdrawal.WithdrawalNo, "withdrawal")
-	sagaBuilder.SetMetadata(map[string]interface{}{
-		"withdrawal_no": withdrawal.WithdrawalNo,
-		"merchant_id":   withdrawal.MerchantID.String(),
-		"amount":        withdrawal.Amount,
-		"actual_amount": withdrawal.ActualAmount,
-		"fee":           withdrawal.Fee,
-	})
-
-	// 2. 定义步骤（使用带超时的构建方法）
-
-	// 步骤1: 预冻结余额（30秒超时）
-	sagaBuilder.AddStepWithTimeout(
-		"PreFreezeBalance",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executePreFreezeBalance(ctx, withdrawal)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensatePreFreezeBalance(ctx, withdrawal)
-		},
-		3,
-		30*time.Second,
-	)
-
-	// 步骤2: 执行银行转账（120秒超时，银行接口较慢）
-	sagaBuilder.AddStepWithTimeout(
-		"ExecuteBankTransfer",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executeBankTransfer(ctx, withdrawal)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensateBankTransfer(ctx, withdrawal, executeResult)
-		},
-		3,
-		120*time.Second,
-	)
-
-	// 步骤3: 扣减余额（30秒超时）
-	sagaBuilder.AddStepWithTimeout(
-		"DeductBalance",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executeDeductBalance(ctx, withdrawal)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensateDeductBalance(ctx, withdrawal)
-		},
-		3,
-		30*time.Second,
-	)
-
-	// 步骤4: 更新提现状态（10秒超时）
-	sagaBuilder.AddStepWithTimeout(
-		"UpdateWithdrawalStatus",
-		func(ctx context.Context, executeData string) (string, error) {
-			return s.executeUpdateStatus(ctx, withdrawal)
-		},
-		func(ctx context.Context, compensateData string, executeResult string) error {
-			return s.compensateUpdateStatus(ctx, withdrawal)
-		},
-		3,
-		10*time.Second,
-	)
+// SagaTypeWithdrawal 提现 Saga 业务类型
+const SagaTypeWithdrawal = "withdrawal"
 
-	// 获取步骤定义（用于执行）
-	stepDefs := []saga.StepDefinition{
+// stepDefinitions 提现 Saga 步骤定义
+func (s *WithdrawalSagaService) stepDefinitions(withdrawal *model.Withdrawal) []saga.StepDefinition {
+	return []saga.StepDefinition{
 		{
 			Name: "PreFreezeBalance",
 			Execute: func(ctx context.Context, executeData string) (string, error) {
@@ -156,6 +93,40 @@ func (s *WithdrawalSagaService) ExecuteWithdrawalSaga(
 			Timeout:       10 * time.Second,
 		},
 	}
+}
+
+// recoverStepDefinitions 恢复时根据 withdrawal_no 重新加载提现单并重建步骤定义
+func (s *WithdrawalSagaService) recoverStepDefinitions(ctx context.Context, sagaInstance *saga.Saga) ([]saga.StepDefinition, error) {
+	withdrawal, err := s.withdrawalRepo.GetByWithdrawalNo(ctx, sagaInstance.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal: %w", err)
+	}
+	if withdrawal == nil {
+		return nil, fmt.Errorf("withdrawal not found: %s", sagaInstance.BusinessID)
+	}
+	return s.stepDefinitions(withdrawal), nil
+}
+
+// ExecuteWithdrawalSaga 执行提现 Saga
+func (s *WithdrawalSagaService) ExecuteWithdrawalSaga(
+	ctx context.Context,
+	withdrawal *model.Withdrawal,
+) error {
+	// 1. 构建 Saga
+	sagaBuilder := s.orchestrator.NewSagaBuilder(withdrawal.WithdrawalNo, SagaTypeWithdrawal)
+	sagaBuilder.SetMetadata(map[string]interface{}{
+		"withdrawal_no": withdrawal.WithdrawalNo,
+		"merchant_id":   withdrawal.MerchantID.String(),
+		"amount":        withdrawal.Amount,
+		"actual_amount": withdrawal.ActualAmount,
+		"fee":           withdrawal.Fee,
+	})
+
+	// 2. 定义步骤：预冻结余额（30秒）→ 银行转账（120秒，银行接口较慢）→ 扣减余额（30秒）→ 更新提现状态（10秒）
+	stepDefs := s.stepDefinitions(withdrawal)
+	for _, def := range stepDefs {
+		sagaBuilder.AddStepWithTimeout(def.Name, def.Execute, def.Compensate, def.MaxRetryCount, def.Timeout)
+	}
 
 	// 3. 构建并执行 Saga
 	sagaInstance, err := sagaBuilder.Build(ctx)
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/payment-platform/pkg/idempotent"
 	"github.com/payment-platform/pkg/logger"
+	"github.com/payment-platform/pkg/money"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -76,6 +78,7 @@ func (s *withdrawalService) SetSagaService(sagaService *WithdrawalSagaService) {
 type CreateWithdrawalInput struct {
 	MerchantID    uuid.UUID
 	Amount        int64
+	Currency      string // 出款币种（可选，默认CNY）
 	Type          model.WithdrawalType
 	BankAccountID uuid.UUID
 	Remarks       string
@@ -164,8 +167,13 @@ func (s *withdrawalService) CreateWithdrawal(ctx context.Context, input *CreateW
 		}
 	}
 
-	// 计算手续费
-	fee := s.calculateFee(input.Amount, input.Type)
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if currency == "" {
+		currency = "CNY"
+	}
+
+	// 计算手续费（按出款币种）
+	fee := s.calculateFee(input.Amount, currency, input.Type)
 	actualAmount := input.Amount - fee
 
 	// 确定审批级别
@@ -180,6 +188,7 @@ func (s *withdrawalService) CreateWithdrawal(ctx context.Context, input *CreateW
 		Amount:          input.Amount,
 		Fee:             fee,
 		ActualAmount:    actualAmount,
+		Currency:        currency,
 		Type:            input.Type,
 		Status:          model.WithdrawalStatusPending,
 		BankAccountID:   input.BankAccountID,
@@ -226,33 +235,64 @@ func (s *withdrawalService) CreateWithdrawal(ctx context.Context, input *CreateW
 	return withdrawal, nil
 }
 
-// calculateFee 计算手续费
-func (s *withdrawalService) calculateFee(amount int64, withdrawalType model.WithdrawalType) int64 {
+// withdrawalFeeLimit 提现手续费上下限（出款币种最小单位）
+type withdrawalFeeLimit struct {
+	Min int64
+	Max int64
+}
+
+// withdrawalFeeLimits 各出款币种的手续费上下限
+var withdrawalFeeLimits = map[string]withdrawalFeeLimit{
+	"CNY": {Min: 100, Max: 10000}, // 1元 ~ 100元
+	"HKD": {Min: 100, Max: 10000}, // 1港元 ~ 100港元
+	"USD": {Min: 20, Max: 1500},   // 0.2美元 ~ 15美元
+	"EUR": {Min: 20, Max: 1500},   // 0.2欧元 ~ 15欧元
+	"GBP": {Min: 20, Max: 1200},   // 0.2英镑 ~ 12英镑
+	"JPY": {Min: 20, Max: 2000},   // 20日元 ~ 2000日元
+}
+
+// feeLimitFor 获取出款币种的手续费上下限，未配置的币种按 1 ~ 100 个主单位计
+func feeLimitFor(currency string) withdrawalFeeLimit {
+	if limit, ok := withdrawalFeeLimits[currency]; ok {
+		return limit
+	}
+	unit := int64(1)
+	for i := 0; i < money.Decimals(currency); i++ {
+		unit *= 10
+	}
+	return withdrawalFeeLimit{Min: unit, Max: 100 * unit}
+}
+
+// calculateFee 计算手续费（出款币种最小单位）
+func (s *withdrawalService) calculateFee(amount int64, currency string, withdrawalType model.WithdrawalType) int64 {
 	// 基础手续费率
-	var feeRate float64
+	var feeRate money.Decimal
 	switch withdrawalType {
 	case model.WithdrawalTypeNormal:
-		feeRate = 0.001 // 0.1%
+		feeRate = money.NewDecimal(1, 3) // 0.1%
 	case model.WithdrawalTypeUrgent:
-		feeRate = 0.005 // 0.5%
+		feeRate = money.NewDecimal(5, 3) // 0.5%
 	case model.WithdrawalTypeScheduled:
-		feeRate = 0.0005 // 0.05%
+		feeRate = money.NewDecimal(5, 4) // 0.05%
 	default:
-		feeRate = 0.001
+		feeRate = money.NewDecimal(1, 3)
 	}
 
-	fee := int64(float64(amount) * feeRate)
+	limit := feeLimitFor(currency)
+
+	// 手续费四舍五入到最小单位，溢出时按最高手续费收取
+	feeMoney, err := money.New(amount, currency).Mul(feeRate, money.RoundHalfUp)
+	if err != nil {
+		return limit.Max
+	}
+	fee := feeMoney.Amount
 
-	// 最低手续费
-	minFee := int64(100) // 1元
-	if fee < minFee {
-		fee = minFee
+	if fee < limit.Min {
+		fee = limit.Min
 	}
 
-	// 最高手续费
-	maxFee := int64(10000) // 100元
-	if fee > maxFee {
-		fee = maxFee
+	if fee > limit.Max {
+		fee = limit.Max
 	}
 
 	return fee
@@ -487,7 +527,7 @@ func (s *withdrawalService) ExecuteWithdrawal(ctx context.Context, withdrawalID
 			BankAccountName: withdrawal.BankAccountName,
 			BankAccountNo:   withdrawal.BankAccountNo,
 			Amount:          withdrawal.ActualAmount,
-			Currency:        "CNY",
+			Currency:        withdrawal.Currency,
 			Remarks:         withdrawal.Remarks,
 		}
 
@@ -512,6 +552,7 @@ func (s *withdrawalService) ExecuteWithdrawal(ctx context.Context, withdrawalID
 		deductReq := &client.DeductBalanceRequest{
 			MerchantID:      withdrawal.MerchantID,
 			Amount:          withdrawal.Amount, // 扣减总金额（包含手续费）
+			Currency:        withdrawal.Currency,
 			TransactionType: "withdrawal",
 			RelatedNo:       withdrawal.WithdrawalNo,
 			Description:     fmt.Sprintf("提现: %s, 实际到账: %.2f元, 手续费: %.2f元",